@@ -1,5 +1,7 @@
 param(
     [string] $Version,
-    [string] $SourceVersion
+    [string] $SourceVersion,
+    # The base64 encoded Ed25519 public key the checksums of the releases are signed for, verified by azd upgrade
+    [string] $ReleasePublicKey
 )
-go build -ldflags="-X 'github.com/azure/azure-dev/cli/azd/internal.Version=$Version (commit $SourceVersion)'"
\ No newline at end of file
+go build -ldflags="-X 'github.com/azure/azure-dev/cli/azd/internal.Version=$Version (commit $SourceVersion)' -X 'github.com/azure/azure-dev/cli/azd/pkg/update.ReleasePublicKey=$ReleasePublicKey'"
\ No newline at end of file
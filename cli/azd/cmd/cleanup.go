@@ -0,0 +1,433 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/github"
+	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type cleanupFlags struct {
+	force      bool
+	remoteName string
+	global     *internal.GlobalCommandOptions
+}
+
+func (f *cleanupFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.BoolVar(
+		&f.force,
+		"force",
+		false,
+		"Does not require confirmation before it deletes the orphaned artifacts.",
+	)
+	local.StringVar(
+		&f.remoteName,
+		"remote-name",
+		"origin",
+		"The name of the git remote the pipelines of the project were configured to run on.",
+	)
+
+	f.global = global
+}
+
+func cleanupCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *cleanupFlags) {
+	cmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "Delete the Azure artifacts created by azd that no local environment uses anymore.",
+		//nolint:lll
+		Long: `Delete the Azure artifacts created by azd that no local environment uses anymore.
+
+The artifacts of the project are listed before they're deleted:
+
+	• The service principals of pipelines of the project pushed to its remote, ` + output.WithBackticks("--remote-name") + `, tagged by ` + output.WithBackticks("azd pipeline config") + `, whose environment no longer exists locally.
+	• The deployments of the local environments, other than their latest succeeded deployment and the deployments in progress.
+	• The federated credentials of the remaining service principals trusting branches of the GitHub repository that no longer exist.
+
+The resources provisioned for the environments aren't deleted, use ` + output.WithBackticks("azd down") + ` to delete them.`,
+	}
+
+	flags := &cleanupFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return cmd, flags
+}
+
+// The prefix of the names of the federated credentials trusting the workflows run for the pushes to a branch
+const branchFederatedCredentialPrefix = "azd-branch-"
+
+// orphanedDeployment is a deployment of an environment superseded by a more recent deployment of the environment
+type orphanedDeployment struct {
+	subscriptionId string
+	envName        string
+	name           string
+}
+
+// orphanedCredential is a federated credential of a service principal trusting a branch that no longer exists
+type orphanedCredential struct {
+	principal azcli.PipelineServicePrincipal
+	id        string
+	branch    string
+}
+
+type orphanedArtifacts struct {
+	principals  []azcli.PipelineServicePrincipal
+	deployments []orphanedDeployment
+	credentials []orphanedCredential
+}
+
+func (o *orphanedArtifacts) count() int {
+	return len(o.principals) + len(o.deployments) + len(o.credentials)
+}
+
+type cleanupAction struct {
+	flags   cleanupFlags
+	azdCtx  *azdcontext.AzdContext
+	azCli   azcli.AzCli
+	gitCli  git.GitCli
+	console input.Console
+}
+
+func newCleanupAction(
+	flags cleanupFlags,
+	azdCtx *azdcontext.AzdContext,
+	azCli azcli.AzCli,
+	gitCli git.GitCli,
+	console input.Console,
+) *cleanupAction {
+	return &cleanupAction{
+		flags:   flags,
+		azdCtx:  azdCtx,
+		azCli:   azCli,
+		gitCli:  gitCli,
+		console: console,
+	}
+}
+
+func (c *cleanupAction) Run(ctx context.Context) error {
+	prj, err := project.LoadProjectConfig(c.azdCtx.ProjectPath(), environment.Ephemeral())
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+
+	envs, err := c.azdCtx.ListEnvironments()
+	if err != nil {
+		return fmt.Errorf("listing environments: %w", err)
+	}
+
+	c.console.Message(ctx, "Finding the orphaned artifacts of the project...")
+
+	orphaned := &orphanedArtifacts{}
+	principals, err := c.azCli.ListPipelineServicePrincipals(ctx)
+	if err != nil {
+		return fmt.Errorf("listing the service principals of pipelines: %w", err)
+	}
+
+	envNames := map[string]bool{}
+	for _, env := range envs {
+		envNames[env.Name] = true
+	}
+
+	// The principals of a project created from the same template are only told apart by their repository
+	remoteUrl, err := c.gitCli.GetRemoteUrl(ctx, c.azdCtx.ProjectDirectory(), c.flags.remoteName)
+	if err != nil {
+		log.Printf("skipping service principals, the project has no remote: %v", err)
+	} else {
+		orphaned.principals = stalePrincipals(principals, prj.Name, remoteUrl, envNames)
+	}
+
+	if err := c.findDeployments(ctx, envs, orphaned); err != nil {
+		return err
+	}
+
+	if err := c.findCredentials(ctx, principals, orphaned); err != nil {
+		return err
+	}
+
+	if orphaned.count() == 0 {
+		c.console.Message(ctx, "No orphaned artifacts were found.")
+		return nil
+	}
+
+	c.printArtifacts(ctx, orphaned)
+
+	if !c.flags.force {
+		confirm, err := c.console.Confirm(ctx, input.ConsoleOptions{
+			Message:      fmt.Sprintf("Delete the %d orphaned artifacts?", orphaned.count()),
+			DefaultValue: false,
+		})
+		if err != nil {
+			return fmt.Errorf("prompting to delete the orphaned artifacts: %w", err)
+		}
+
+		if !confirm {
+			return nil
+		}
+	}
+
+	return c.deleteArtifacts(ctx, orphaned)
+}
+
+// findDeployments finds the superseded deployments of the local environments, the deployments of each subscription are
+// listed once
+func (c *cleanupAction) findDeployments(
+	ctx context.Context,
+	envs []azdcontext.EnvironmentView,
+	orphaned *orphanedArtifacts,
+) error {
+	subscriptionDeployments := map[string][]*armresources.DeploymentExtended{}
+
+	for _, view := range envs {
+		env, err := environment.GetEnvironment(c.azdCtx, view.Name)
+		if err != nil {
+			return fmt.Errorf("loading environment '%s': %w", view.Name, err)
+		}
+
+		subscriptionId := env.GetSubscriptionId()
+		if subscriptionId == "" {
+			continue
+		}
+
+		deployments, has := subscriptionDeployments[subscriptionId]
+		if !has {
+			deployments, err = c.azCli.ListSubscriptionDeployments(ctx, subscriptionId)
+			if err != nil {
+				return fmt.Errorf("listing the deployments of subscription '%s': %w", subscriptionId, err)
+			}
+			subscriptionDeployments[subscriptionId] = deployments
+		}
+
+		for _, name := range staleDeployments(view.Name, deployments) {
+			orphaned.deployments = append(orphaned.deployments, orphanedDeployment{
+				subscriptionId: subscriptionId,
+				envName:        view.Name,
+				name:           name,
+			})
+		}
+	}
+
+	return nil
+}
+
+// findCredentials finds the federated credentials of the service principals that aren't deleted trusting branches of
+// the GitHub repository of the project that no longer exist. Nothing is found when the project isn't pushed to GitHub.
+func (c *cleanupAction) findCredentials(
+	ctx context.Context,
+	principals []azcli.PipelineServicePrincipal,
+	orphaned *orphanedArtifacts,
+) error {
+	remoteUrl, err := c.gitCli.GetRemoteUrl(ctx, c.azdCtx.ProjectDirectory(), c.flags.remoteName)
+	if err != nil {
+		log.Printf("skipping federated credentials, the project has no remote: %v", err)
+		return nil
+	}
+
+	repoSlug, err := github.GetSlugForRemote(remoteUrl)
+	if err != nil {
+		log.Printf("skipping federated credentials, the remote isn't a GitHub repository: %v", err)
+		return nil
+	}
+
+	branches, err := c.gitCli.ListRemoteBranches(ctx, c.azdCtx.ProjectDirectory(), c.flags.remoteName)
+	if err != nil {
+		return fmt.Errorf("listing the branches of the repository: %w", err)
+	}
+
+	deleted := map[string]bool{}
+	for _, principal := range orphaned.principals {
+		deleted[principal.AppId] = true
+	}
+
+	for _, principal := range principals {
+		// The credentials of a deleted principal are deleted along with it
+		if deleted[principal.AppId] {
+			continue
+		}
+
+		credentials, err := c.azCli.ListFederatedCredentials(ctx, principal.AppId)
+		if err != nil {
+			return fmt.Errorf("listing the federated credentials of service principal '%s': %w", principal.Name, err)
+		}
+
+		for _, credential := range danglingCredentials(credentials, repoSlug, branches) {
+			orphaned.credentials = append(orphaned.credentials, orphanedCredential{
+				principal: principal,
+				id:        *credential.Id,
+				branch:    credentialBranch(credential, repoSlug),
+			})
+		}
+	}
+
+	return nil
+}
+
+func (c *cleanupAction) printArtifacts(ctx context.Context, orphaned *orphanedArtifacts) {
+	lines := []string{}
+	for _, principal := range orphaned.principals {
+		lines = append(lines, fmt.Sprintf(
+			"  Service principal %s of environment %s",
+			output.WithHighLightFormat(principal.Name), principal.Environment))
+	}
+	for _, deployment := range orphaned.deployments {
+		lines = append(lines, fmt.Sprintf(
+			"  Deployment %s of environment %s",
+			output.WithHighLightFormat(deployment.name), deployment.envName))
+	}
+	for _, credential := range orphaned.credentials {
+		lines = append(lines, fmt.Sprintf(
+			"  Federated credential of service principal %s for deleted branch %s",
+			output.WithHighLightFormat(credential.principal.Name), credential.branch))
+	}
+
+	c.console.Message(ctx, fmt.Sprintf("\nOrphaned artifacts:\n%s\n", strings.Join(lines, "\n")))
+}
+
+func (c *cleanupAction) deleteArtifacts(ctx context.Context, orphaned *orphanedArtifacts) error {
+	for _, principal := range orphaned.principals {
+		if err := c.azCli.DeleteServicePrincipalByClientId(ctx, principal.AppId); err != nil {
+			return fmt.Errorf("deleting service principal '%s': %w", principal.Name, err)
+		}
+	}
+
+	for _, deployment := range orphaned.deployments {
+		err := c.azCli.DeleteSubscriptionDeployment(ctx, deployment.subscriptionId, deployment.name)
+		if err != nil {
+			return fmt.Errorf("deleting deployment '%s': %w", deployment.name, err)
+		}
+	}
+
+	for _, credential := range orphaned.credentials {
+		if err := c.azCli.DeleteFederatedCredential(ctx, credential.principal.AppId, credential.id); err != nil {
+			return fmt.Errorf("deleting the federated credential for branch '%s': %w", credential.branch, err)
+		}
+	}
+
+	c.console.Message(ctx, output.WithSuccessFormat("Deleted %d orphaned artifacts.", orphaned.count()))
+	return nil
+}
+
+// stalePrincipals returns the service principals of pipelines of the project pushed to the remote whose environment
+// doesn't exist. The principals without a repository are kept, they may belong to another clone of the template.
+func stalePrincipals(
+	principals []azcli.PipelineServicePrincipal,
+	projectName string,
+	remoteUrl string,
+	envNames map[string]bool,
+) []azcli.PipelineServicePrincipal {
+	repository := repositoryKey(remoteUrl)
+
+	stale := []azcli.PipelineServicePrincipal{}
+	for _, principal := range principals {
+		if principal.Project != projectName || principal.Environment == "" || envNames[principal.Environment] {
+			continue
+		}
+
+		if principal.RepositoryUrl != "" && repositoryKey(principal.RepositoryUrl) == repository {
+			stale = append(stale, principal)
+		}
+	}
+
+	return stale
+}
+
+// azdoRepositoryUrlRegex matches the web url and the https and ssh remotes of an Azure DevOps repository, capturing the
+// organization, the project and the name of the repository
+var azdoRepositoryUrlRegex = regexp.MustCompile(
+	`^(?:https://(?:[^@/]+@)?dev\.azure\.com/([^/]+)/([^/]+)/_git/|git@ssh\.dev\.azure\.com:v3/([^/]+)/([^/]+)/)([^/]+?)/?$`)
+
+// repositoryKey returns the repository of a remote or of the web url of a repository, so the remote of a project can be
+// compared with the repository url saved in the tags of a service principal
+func repositoryKey(repositoryUrl string) string {
+	repositoryUrl = strings.TrimSpace(repositoryUrl)
+
+	if slug, err := github.GetSlugForRemote(repositoryUrl); err == nil {
+		return strings.ToLower("github.com/" + slug)
+	}
+
+	if captures := azdoRepositoryUrlRegex.FindStringSubmatch(repositoryUrl); captures != nil {
+		org, project := captures[1]+captures[3], captures[2]+captures[4]
+		key := strings.Join([]string{org, project, strings.TrimSuffix(captures[5], ".git")}, "/")
+		if unescaped, err := url.PathUnescape(key); err == nil {
+			key = unescaped
+		}
+
+		return strings.ToLower("dev.azure.com/" + key)
+	}
+
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(repositoryUrl, "/"), ".git"))
+}
+
+// staleDeployments returns the names of the deployments of the environment other than its latest succeeded deployment
+// and the deployments in progress
+func staleDeployments(envName string, deployments []*armresources.DeploymentExtended) []string {
+	latest := provisioning.LatestSucceededDeployment(envName, deployments)
+
+	stale := []string{}
+	for _, deployment := range deployments {
+		if deployment.Name == nil || deployment == latest || provisioning.IsDeploymentInProgress(deployment) {
+			continue
+		}
+
+		if provisioning.IsEnvironmentDeployment(envName, deployment) {
+			stale = append(stale, *deployment.Name)
+		}
+	}
+
+	return stale
+}
+
+// danglingCredentials returns the federated credentials created by azd trusting branches of the repository that aren't
+// among its branches
+func danglingCredentials(
+	credentials []graphsdk.FederatedIdentityCredential,
+	repoSlug string,
+	branches []string,
+) []graphsdk.FederatedIdentityCredential {
+	existing := map[string]bool{}
+	for _, branch := range branches {
+		existing[branch] = true
+	}
+
+	dangling := []graphsdk.FederatedIdentityCredential{}
+	for _, credential := range credentials {
+		if credential.Id == nil || !strings.HasPrefix(credential.Name, branchFederatedCredentialPrefix) {
+			continue
+		}
+
+		if branch := credentialBranch(credential, repoSlug); branch != "" && !existing[branch] {
+			dangling = append(dangling, credential)
+		}
+	}
+
+	return dangling
+}
+
+// credentialBranch returns the branch of the repository trusted by the federated credential, empty when the credential
+// doesn't trust a branch of the repository
+func credentialBranch(credential graphsdk.FederatedIdentityCredential, repoSlug string) string {
+	prefix := fmt.Sprintf("repo:%s:ref:refs/heads/", repoSlug)
+	if !strings.HasPrefix(credential.Subject, prefix) {
+		return ""
+	}
+
+	return strings.TrimPrefix(credential.Subject, prefix)
+}
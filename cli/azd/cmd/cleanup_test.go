@@ -0,0 +1,111 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_stalePrincipals(t *testing.T) {
+	const remoteUrl = "git@github.com:contoso/todo.git"
+	principal := func(name string, project string, env string, repositoryUrl string) azcli.PipelineServicePrincipal {
+		return azcli.PipelineServicePrincipal{
+			Name: name,
+			ServicePrincipalMetadata: azcli.ServicePrincipalMetadata{
+				Project:       project,
+				Environment:   env,
+				RepositoryUrl: repositoryUrl,
+			},
+		}
+	}
+
+	principals := []azcli.PipelineServicePrincipal{
+		principal("azd-todo-dev", "todo", "dev", "https://github.com/contoso/todo"),
+		principal("azd-todo-old", "todo", "old", "https://github.com/contoso/todo"),
+		principal("azd-other-old", "other", "old", "https://github.com/contoso/todo"),
+		principal("untagged", "todo", "", "https://github.com/contoso/todo"),
+		// A clone of the same template pushed to another repository
+		principal("azd-todo-old", "todo", "old", "https://github.com/fabrikam/todo"),
+		// Tagged before the repository was recorded
+		principal("azd-todo-older", "todo", "older", ""),
+	}
+
+	stale := stalePrincipals(principals, "todo", remoteUrl, map[string]bool{"dev": true})
+	require.Equal(t, []azcli.PipelineServicePrincipal{principals[1]}, stale)
+}
+
+func Test_repositoryKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		remoteUrl string
+		webUrl    string
+	}{
+		{"GitHubSsh", "git@github.com:Contoso/todo.git", "https://github.com/contoso/todo"},
+		{"GitHubHttps", "https://github.com/contoso/todo.git", "https://github.com/contoso/todo"},
+		{"AzdoSsh", "git@ssh.dev.azure.com:v3/org/my%20project/repo", "https://dev.azure.com/org/my%20project/_git/repo"},
+		{"AzdoHttps", "https://org@dev.azure.com/org/my%20project/_git/repo", "https://dev.azure.com/org/my%20project/_git/repo"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, repositoryKey(test.webUrl), repositoryKey(test.remoteUrl))
+		})
+	}
+
+	require.NotEqual(t, repositoryKey("https://github.com/contoso/todo"), repositoryKey("https://github.com/fabrikam/todo"))
+}
+
+func Test_staleDeployments(t *testing.T) {
+	start := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	deployment := func(name string, state armresources.ProvisioningState, age time.Duration) *armresources.DeploymentExtended {
+		return &armresources.DeploymentExtended{
+			Name: convert.RefOf(name),
+			Properties: &armresources.DeploymentPropertiesExtended{
+				ProvisioningState: convert.RefOf(state),
+				Timestamp:         convert.RefOf(start.Add(-age)),
+			},
+		}
+	}
+
+	deployments := []*armresources.DeploymentExtended{
+		deployment("dev", armresources.ProvisioningStateSucceeded, 72*time.Hour),
+		deployment("dev-0123abcd-1664600000", armresources.ProvisioningStateFailed, 48*time.Hour),
+		deployment("dev-0123abcd-1664610000", armresources.ProvisioningStateSucceeded, 24*time.Hour),
+		deployment("dev-0123abcd-1664620000", armresources.ProvisioningStateRunning, 0),
+		deployment("prod-0123abcd-1664600000", armresources.ProvisioningStateSucceeded, 96*time.Hour),
+		deployment("unrelated", armresources.ProvisioningStateSucceeded, 96*time.Hour),
+	}
+
+	require.Equal(t, []string{"dev", "dev-0123abcd-1664600000"}, staleDeployments("dev", deployments))
+	require.Empty(t, staleDeployments("prod", deployments))
+}
+
+func Test_danglingCredentials(t *testing.T) {
+	credential := func(name string, subject string) graphsdk.FederatedIdentityCredential {
+		return graphsdk.FederatedIdentityCredential{
+			Id:      convert.RefOf(name),
+			Name:    name,
+			Subject: subject,
+		}
+	}
+
+	credentials := []graphsdk.FederatedIdentityCredential{
+		credential("azd-branch-main", "repo:owner/repo:ref:refs/heads/main"),
+		credential("azd-branch-feature-login", "repo:owner/repo:ref:refs/heads/feature/login"),
+		credential("azd-pull-request", "repo:owner/repo:pull_request"),
+		credential("azd-branch-fork", "repo:other/repo:ref:refs/heads/fork"),
+		credential("custom", "repo:owner/repo:ref:refs/heads/deleted"),
+	}
+
+	dangling := danglingCredentials(credentials, "owner/repo", []string{"main"})
+	require.Equal(t, []graphsdk.FederatedIdentityCredential{credentials[1]}, dangling)
+	require.Equal(t, "feature/login", credentialBranch(dangling[0], "owner/repo"))
+}
@@ -0,0 +1,174 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/templates"
+	"github.com/spf13/cobra"
+)
+
+// The completion functions resolve their values in-process from local state only, the project and environment files and
+// the azd configuration, so completing stays fast and works offline. A failure to resolve values completes nothing.
+
+// completeEnvironmentNames completes the names of the environments of the project
+func completeEnvironmentNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	azdCtx, err := completionAzdContext(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	envs, err := azdCtx.ListEnvironments()
+	if err != nil {
+		log.Printf("completing environment names: %v", err)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, env := range envs {
+		if env.IsDefault {
+			completions = append(completions, fmt.Sprintf("%s\tdefault", env.Name))
+		} else {
+			completions = append(completions, env.Name)
+		}
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeEnvironmentNameArg completes the environment name argument of a command taking at most one argument
+func completeEnvironmentNameArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return completeEnvironmentNames(cmd, args, toComplete)
+}
+
+// completeServiceNames completes the names of the services of the project, from azure.yaml
+func completeServiceNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	azdCtx, err := completionAzdContext(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	prj, err := project.LoadProjectConfig(azdCtx.ProjectPath(), environment.Ephemeral())
+	if err != nil {
+		log.Printf("completing service names: %v", err)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	completions := make([]string, 0, len(prj.Services))
+	for name, svc := range prj.Services {
+		completions = append(completions, fmt.Sprintf("%s\t%s", name, svc.Host))
+	}
+	sort.Strings(completions)
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeServiceNameArg completes the service name argument of a command taking at most one argument
+func completeServiceNameArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return completeServiceNames(cmd, args, toComplete)
+}
+
+// completeTemplateNames completes the names of the templates azd knows of
+func completeTemplateNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	templateSet, err := templates.NewTemplateManager().ListTemplates()
+	if err != nil {
+		log.Printf("completing template names: %v", err)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	completions := make([]string, 0, len(templateSet))
+	for name, template := range templateSet {
+		completions = append(completions, fmt.Sprintf("%s\t%s", name, template.Description))
+	}
+	sort.Strings(completions)
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTemplateNameArg completes the template name argument of a command taking one argument
+func completeTemplateNameArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return completeTemplateNames(cmd, args, toComplete)
+}
+
+// completeSubscriptionIds completes the ids of the subscriptions known locally: the default subscription of the azd
+// configuration and the subscriptions of the environments of the project. Listing the subscriptions of the account
+// would require signing in and calling Azure.
+func completeSubscriptionIds(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	descriptions := map[string][]string{}
+
+	if configPath, err := config.GetUserConfigFilePath(); err == nil {
+		if azdConfig, err := config.NewManager().Load(configPath); err == nil {
+			if id, ok := azdConfig.Get("defaults.subscription"); ok {
+				if id, ok := id.(string); ok && id != "" {
+					descriptions[id] = append(descriptions[id], "default")
+				}
+			}
+		}
+	}
+
+	if azdCtx, err := completionAzdContext(cmd); err == nil {
+		envs, _ := azdCtx.ListEnvironments()
+		for _, envView := range envs {
+			env, err := environment.GetEnvironment(azdCtx, envView.Name)
+			if err != nil || env.GetSubscriptionId() == "" {
+				continue
+			}
+
+			id := env.GetSubscriptionId()
+			descriptions[id] = append(descriptions[id], envView.Name)
+		}
+	}
+
+	completions := make([]string, 0, len(descriptions))
+	for id, names := range descriptions {
+		completions = append(completions, fmt.Sprintf("%s\t%s", id, strings.Join(names, ", ")))
+	}
+	sort.Strings(completions)
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionAzdContext returns the context of the project completed for, honoring the --cwd flag, since completing
+// doesn't run the pre-run hooks of the commands
+func completionAzdContext(cmd *cobra.Command) (*azdcontext.AzdContext, error) {
+	if cwd, err := cmd.Flags().GetString("cwd"); err == nil && cwd != "" {
+		if err := os.Chdir(cwd); err != nil {
+			return nil, err
+		}
+	}
+
+	return azdcontext.NewAzdContext()
+}
+
+// registerFlagCompletion registers the completion function of a flag, the flags are all declared by the command
+func registerFlagCompletion(
+	cmd *cobra.Command,
+	flagName string,
+	completionFn func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective),
+) {
+	if err := cmd.RegisterFlagCompletionFunc(flagName, completionFn); err != nil {
+		panic(fmt.Sprintf("registering the completion of flag %s: %v", flagName, err))
+	}
+}
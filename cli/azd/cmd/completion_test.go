@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletion(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+
+	projectDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, azdcontext.ProjectFileName), []byte(`
+name: test-proj
+services:
+  web:
+    project: src/web
+    language: js
+    host: staticwebapp
+  api:
+    project: src/api
+    language: js
+    host: appservice
+`), 0600))
+
+	azdCtx, err := azdcontext.NewAzdContext()
+	require.NoError(t, err)
+	azdCtx.SetProjectDirectory(projectDir)
+	require.NoError(t, azdCtx.NewEnvironment("dev"))
+	require.NoError(t, azdCtx.NewEnvironment("prod"))
+	require.NoError(t, azdCtx.SetDefaultEnvironmentName("dev"))
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("cwd", "", "")
+	require.NoError(t, cmd.Flags().Set("cwd", projectDir))
+
+	t.Run("EnvironmentNames", func(t *testing.T) {
+		completions, _ := completeEnvironmentNames(cmd, nil, "")
+		require.Equal(t, []string{"dev\tdefault", "prod"}, completions)
+	})
+
+	t.Run("ServiceNames", func(t *testing.T) {
+		completions, _ := completeServiceNames(cmd, nil, "")
+		require.Equal(t, []string{"api\tappservice", "web\tstaticwebapp"}, completions)
+
+		completions, _ = completeServiceNameArg(cmd, []string{"api"}, "")
+		require.Empty(t, completions)
+	})
+
+	t.Run("TemplateNames", func(t *testing.T) {
+		completions, _ := completeTemplateNames(cmd, nil, "")
+		require.NotEmpty(t, completions)
+	})
+}
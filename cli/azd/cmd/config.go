@@ -7,9 +7,11 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/spf13/cobra"
 )
@@ -19,6 +21,24 @@ func configCmd(rootOptions *internal.GlobalCommandOptions) *cobra.Command {
 	root := &cobra.Command{
 		Use:   "config",
 		Short: "Manage Azure Developer CLI configuration",
+		Long: `Manage Azure Developer CLI user configuration, stored in the azd config directory (~/.azd/config.json).
+
+The following settings are used as the defaults for prompts and commands:
+
+  defaults.subscription       The Azure subscription selected by default when creating an environment
+  defaults.location           The Azure location selected by default when creating an environment
+  defaults.pipeline.provider  The pipeline provider (github or azdo) used when a project does not select one
+  telemetry.enabled           Set to false to opt out of telemetry collection
+
+The timeouts and retries of the requests sent by azd are raised on slow networks with:
+
+  http.timeout                The time allowed for each attempt of a request, i.e. 90s or 2m
+  http.maxRetries             The number of times a failed request is retried, for the arm and graph clients
+  http.clients.<client>.timeout, http.clients.<client>.maxRetries
+                              Override the values for a client: arm, graph, azdo or github. Only the arm and
+                              graph clients retry their requests, maxRetries isn't supported for azdo and github
+
+For example, 'azd config set defaults.location eastus2'.`,
 	}
 
 	root.AddCommand(BuildCmd(rootOptions, configListCmdDesign, initConfigListAction, nil))
@@ -186,6 +206,13 @@ func (a *configSetAction) Run(ctx context.Context) error {
 		return fmt.Errorf("failed setting configuration value '%s' to '%s'. %w", path, value, err)
 	}
 
+	// The policies of the clients are validated when set, the clients ignore an invalid policy
+	if path == "http" || strings.HasPrefix(path, "http.") {
+		if _, err := httputil.NewClientPolicies(azdConfig); err != nil {
+			return fmt.Errorf("failed setting configuration value '%s' to '%s'. %w", path, value, err)
+		}
+	}
+
 	userConfigFilePath, err := config.GetUserConfigFilePath()
 	if err != nil {
 		return fmt.Errorf("failed getting user config file path. %w", err)
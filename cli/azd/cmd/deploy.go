@@ -5,26 +5,36 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/ci"
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
-	"github.com/azure/azure-dev/cli/azd/pkg/spin"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/watch"
+	"github.com/sethvargo/go-retry"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
 type deployFlags struct {
 	serviceName  string
+	fromPackage  string
+	image        string
+	watch        bool
 	outputFormat *string // pointer to allow delay-initialization when used in "azd up"
 	global       *internal.GlobalCommandOptions
 }
@@ -32,6 +42,25 @@ type deployFlags struct {
 func (d *deployFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
 	d.bindWithoutOutput(local, global)
 
+	local.StringVar(
+		&d.fromPackage,
+		"from-package",
+		"",
+		"Deploys the service from a package built previously (a zip file or a directory), without building it.",
+	)
+	local.StringVar(
+		&d.image,
+		"image",
+		"",
+		"Deploys the service from a container image built previously, without building it.",
+	)
+	local.BoolVar(
+		&d.watch,
+		"watch",
+		false,
+		"Watches the source of the deployed services and redeploys each service when its files change.",
+	)
+
 	d.outputFormat = convert.RefOf("")
 	output.AddOutputFlag(
 		local,
@@ -67,12 +96,21 @@ Examples:
 	$ azd deploy
 	$ azd deploy --service api
 	$ azd deploy --service web
+	$ azd deploy --service api --from-package ./api.zip
+	$ azd deploy --service web --image myregistry.azurecr.io/web:1.0.0
+	$ azd deploy --service api --watch
 	
-After the deployment is complete, the endpoint is printed. To start the service, select the endpoint or paste it in a browser.`,
+After the deployment is complete, the endpoint is printed. To start the service, select the endpoint or paste it in a browser. In GitHub Actions and Azure Pipelines, the endpoints are also published to the summary of the run.
+
+With ` + output.WithBackticks("--watch") + `, azd keeps watching the source directories of the deployed services once they're deployed, and builds and redeploys each service when its files change, until you press Ctrl+C. Build outputs such as node_modules, bin and obj, the ` + output.WithBackticks("dist") + ` folder and the ` + output.WithBackticks("exclude") + ` patterns of the service are ignored. App Service applications only receive the changed files.
+
+Deployments run as the account you're signed in with, no service principal is required. When your account lacks the role to deploy to the SCM site of an App Service or to push to a container registry, azd offers to assign the role to your account and retries the deployment.`,
 	}
 	df := deployFlags{}
 	df.Bind(cmd.Flags(), rootOptions)
 
+	registerFlagCompletion(cmd, "service", completeServiceNames)
+
 	return cmd, &df
 }
 
@@ -111,18 +149,6 @@ type DeploymentResult struct {
 }
 
 func (d *deployAction) Run(ctx context.Context) error {
-	if err := ensureProject(d.azdCtx.ProjectPath()); err != nil {
-		return err
-	}
-
-	if err := tools.EnsureInstalled(ctx, d.azCli); err != nil {
-		return err
-	}
-
-	if err := ensureLoggedIn(ctx); err != nil {
-		return fmt.Errorf("failed to ensure login: %w", err)
-	}
-
 	env, ctx, err := loadOrInitEnvironment(ctx, &d.flags.global.EnvironmentName, d.azdCtx, d.console)
 	if err != nil {
 		return fmt.Errorf("loading environment: %w", err)
@@ -137,6 +163,19 @@ func (d *deployAction) Run(ctx context.Context) error {
 		return fmt.Errorf("service name '%s' doesn't exist", d.flags.serviceName)
 	}
 
+	packagePath, err := d.prebuiltPackage(projConfig)
+	if err != nil {
+		return err
+	}
+
+	if d.flags.watch && packagePath != "" {
+		return errors.New("--watch can't be used with --from-package or --image, the watched services are rebuilt")
+	}
+
+	if d.flags.watch && d.formatter.Kind() != output.NoneFormat {
+		return errors.New("--watch can't be used with --output json")
+	}
+
 	proj, err := projConfig.GetProject(&ctx, env)
 	if err != nil {
 		return fmt.Errorf("creating project: %w", err)
@@ -148,7 +187,12 @@ func (d *deployAction) Run(ctx context.Context) error {
 	var allTools []tools.ExternalTool
 	for _, svc := range proj.Services {
 		if d.flags.serviceName == "" || d.flags.serviceName == svc.Config.Name {
-			allTools = append(allTools, svc.RequiredExternalTools()...)
+			if packagePath != "" {
+				// The service isn't built, only the tools needed to deploy it are required
+				allTools = append(allTools, svc.Target.RequiredExternalTools()...)
+			} else {
+				allTools = append(allTools, svc.RequiredExternalTools()...)
+			}
 		}
 	}
 
@@ -156,10 +200,9 @@ func (d *deployAction) Run(ctx context.Context) error {
 		return err
 	}
 
-	interactive := d.formatter.Kind() == output.NoneFormat
-
-	var svcDeploymentResult project.ServiceDeploymentResult
 	var deploymentResults []project.ServiceDeploymentResult
+	var endpoints []ci.Endpoint
+	var deployedServices []*project.Service
 
 	for _, svc := range proj.Services {
 		// Skip this service if both cases are true:
@@ -169,60 +212,355 @@ func (d *deployAction) Run(ctx context.Context) error {
 			continue
 		}
 
-		deployAndReportProgress := func(ctx context.Context, showProgress func(string)) error {
-			result, progress := svc.Deploy(ctx, d.azdCtx)
+		svcDeploymentResult, err := d.deployService(ctx, svc, packagePath)
+		var accessErr *azcli.DataPlaneAccessError
+		if errors.As(err, &accessErr) {
+			svcDeploymentResult, err = d.assignRoleAndRetry(ctx, env, svc, packagePath, accessErr)
+		}
+		if err != nil {
+			return err
+		}
+
+		deploymentResults = append(deploymentResults, *svcDeploymentResult)
+		deployedServices = append(deployedServices, svc)
+		for _, endpoint := range svcDeploymentResult.Endpoints {
+			endpoints = append(endpoints, ci.Endpoint{Service: svc.Config.Name, Url: endpoint})
+		}
+	}
+
+	env.SetLastDeploymentTime(time.Now())
+	if err := env.Save(); err != nil {
+		return fmt.Errorf("saving environment: %w", err)
+	}
 
-			// Report any progress
-			go func() {
-				for message := range progress {
-					showProgress(fmt.Sprintf("- %s...", message))
-				}
-			}()
+	if ci.SummarySupported() {
+		publishCiSummary(d.console, d.formatter, d.writer, ci.Summary{
+			Title:       fmt.Sprintf("Deployed the environment %s", env.GetEnvName()),
+			Environment: env.GetEnvName(),
+			Details: []ci.Detail{
+				{Name: "Services", Value: fmt.Sprintf("%d deployed", len(deploymentResults))},
+			},
+			Endpoints: endpoints,
+		})
+	}
 
-			response := <-result
-			if response.Error != nil {
-				return fmt.Errorf("deploying service: %w", response.Error)
-			}
+	if d.formatter.Kind() == output.JsonFormat {
+		aggregateDeploymentResult := DeploymentResult{
+			Timestamp: time.Now(),
+			Services:  deploymentResults,
+		}
 
-			svcDeploymentResult = *response.Result
-			deploymentResults = append(deploymentResults, svcDeploymentResult)
+		if fmtErr := d.formatter.Format(aggregateDeploymentResult, d.writer, nil); fmtErr != nil {
+			return fmt.Errorf("deployment result could not be displayed: %w", fmtErr)
+		}
+	}
+
+	if d.flags.watch {
+		return d.watch(ctx, env, deployedServices)
+	}
 
+	return nil
+}
+
+// The interval the sources of the watched services are checked for changes at
+const watchInterval = time.Second
+
+// The directories of build outputs, dependencies and tools ignored when watching the source of a service
+var watchIgnoredDirectories = []string{
+	".git", ".azure", "node_modules", "bin", "obj", "target", "__pycache__", ".venv", "venv",
+}
+
+// watchIgnorePatterns returns the patterns of the files ignored when watching the source of the service: the build
+// outputs and the files left out of its package
+func watchIgnorePatterns(config *project.ServiceConfig) []string {
+	ignore := append([]string{}, watchIgnoredDirectories...)
+
+	if dist := filepath.ToSlash(filepath.Clean(config.OutputPath)); config.OutputPath != "" &&
+		!strings.HasPrefix(dist, "..") {
+		ignore = append(ignore, dist)
+	}
+
+	return append(ignore, config.Exclude...)
+}
+
+// watch redeploys each service when the files of its source change, until the user interrupts the command. A failed
+// deployment, such as a build error, is reported and the service is deployed again on its next change.
+func (d *deployAction) watch(ctx context.Context, env *environment.Environment, services []*project.Service) error {
+	watchers := make([]*watch.Watcher, len(services))
+	names := make([]string, len(services))
+	for i, svc := range services {
+		watcher, err := watch.NewWatcher(svc.Config.Path(), watchIgnorePatterns(svc.Config))
+		if err != nil {
+			return fmt.Errorf("watching service %s: %w", svc.Config.Name, err)
+		}
+
+		watchers[i] = watcher
+		names[i] = output.WithHighLightFormat(svc.Config.Name)
+	}
+
+	d.console.Message(ctx, fmt.Sprintf(
+		"Watching for changes to %s, press Ctrl+C to stop...\n", strings.Join(names, ", ")))
+
+	watchCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	for {
+		select {
+		case <-watchCtx.Done():
 			return nil
+		case <-time.After(watchInterval):
 		}
 
-		if interactive {
-			deployMsg := fmt.Sprintf("Deploying service %s...", output.WithHighLightFormat(svc.Config.Name))
-			d.console.Message(ctx, deployMsg)
+		for i, svc := range services {
+			changes, err := settledChanges(watchCtx, watchers[i])
+			if err != nil {
+				return fmt.Errorf("watching service %s: %w", svc.Config.Name, err)
+			}
+
+			if len(changes) == 0 {
+				continue
+			}
+
+			d.console.Message(ctx, fmt.Sprintf("Detected changes to %s", describeChanges(changes)))
 
-			spinner, ctx := spin.GetOrCreateSpinner(ctx, d.console.Handles().Stdout, deployMsg)
+			if _, err := d.deployService(watchCtx, svc, ""); err != nil {
+				if watchCtx.Err() != nil {
+					return nil
+				}
 
-			spinner.Start()
-			err = deployAndReportProgress(ctx, spinner.Title)
-			spinner.Stop()
+				d.console.Message(ctx, output.WithErrorFormat("%s\n", err.Error()))
+			} else {
+				env.SetLastDeploymentTime(time.Now())
+				if err := env.Save(); err != nil {
+					return fmt.Errorf("saving environment: %w", err)
+				}
+			}
 
-			if err == nil {
-				reportServiceDeploymentResultInteractive(ctx, d.console, svc, &svcDeploymentResult)
+			// The files written by the build of the service aren't changes to deploy again
+			if err := watchers[i].Reset(); err != nil {
+				return fmt.Errorf("watching service %s: %w", svc.Config.Name, err)
 			}
-		} else {
-			err = deployAndReportProgress(ctx, nil)
 		}
+	}
+}
+
+// settledChanges returns the files changed since the last check, once the files stopped changing. Editors and tools
+// often write several files in a row.
+func settledChanges(ctx context.Context, watcher *watch.Watcher) ([]string, error) {
+	changes, err := watcher.Changes()
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-time.After(watchInterval):
+		}
+
+		more, err := watcher.Changes()
 		if err != nil {
-			return err
+			return nil, err
 		}
+
+		if len(more) == 0 {
+			return changes, nil
+		}
+
+		changes = append(changes, more...)
 	}
+}
 
-	if d.formatter.Kind() == output.JsonFormat {
-		aggregateDeploymentResult := DeploymentResult{
-			Timestamp: time.Now(),
-			Services:  deploymentResults,
+// describeChanges names the first changed file and counts the others, i.e. "src/app.py and 2 other files"
+func describeChanges(changes []string) string {
+	unique := map[string]bool{}
+	for _, change := range changes {
+		unique[change] = true
+	}
+
+	switch len(unique) {
+	case 1:
+		return changes[0]
+	case 2:
+		return fmt.Sprintf("%s and 1 other file", changes[0])
+	default:
+		return fmt.Sprintf("%s and %d other files", changes[0], len(unique)-1)
+	}
+}
+
+// deployService deploys the service, reporting its progress and its result when the output is interactive
+func (d *deployAction) deployService(
+	ctx context.Context,
+	svc *project.Service,
+	packagePath string,
+) (*project.ServiceDeploymentResult, error) {
+	var svcDeploymentResult *project.ServiceDeploymentResult
+
+	deployAndReportProgress := func(ctx context.Context, showProgress func(string)) error {
+		result, progress := svc.DeployPackage(ctx, d.azdCtx, packagePath)
+
+		// Report any progress
+		go func() {
+			for message := range progress {
+				showProgress(fmt.Sprintf("- %s...", message))
+			}
+		}()
+
+		response := <-result
+		if response.Error != nil {
+			return fmt.Errorf("deploying service: %w", response.Error)
 		}
 
-		if fmtErr := d.formatter.Format(aggregateDeploymentResult, d.writer, nil); fmtErr != nil {
-			return fmt.Errorf("deployment result could not be displayed: %w", fmtErr)
+		svcDeploymentResult = response.Result
+		return nil
+	}
+
+	if d.formatter.Kind() != output.NoneFormat {
+		if err := deployAndReportProgress(ctx, nil); err != nil {
+			return nil, err
 		}
+
+		return svcDeploymentResult, nil
 	}
 
-	return nil
+	deployMsg := fmt.Sprintf("Deploying service %s...", output.WithHighLightFormat(svc.Config.Name))
+	d.console.Message(ctx, deployMsg)
+
+	spinner, ctx := input.GetOrCreateSpinner(ctx, d.console, deployMsg)
+
+	spinner.Start()
+	err := deployAndReportProgress(ctx, spinner.Title)
+	spinner.Stop()
+
+	if err != nil {
+		return nil, err
+	}
+
+	reportServiceDeploymentResultInteractive(ctx, d.console, svc, svcDeploymentResult)
+	return svcDeploymentResult, nil
+}
+
+// The retries of a deployment denied to the signed-in account while the role assigned to the account applies, which
+// takes up to a few minutes
+const (
+	roleAssignmentRetries    = 6
+	roleAssignmentRetryDelay = 30 * time.Second
+)
+
+// assignRoleAndRetry offers to assign the role the signed-in account lacks to deploy the service, on the resource it was
+// denied access to, then deploys the service again until the assignment applies. Without prompts, the error explains
+// how to assign the role.
+func (d *deployAction) assignRoleAndRetry(
+	ctx context.Context,
+	env *environment.Environment,
+	svc *project.Service,
+	packagePath string,
+	accessErr *azcli.DataPlaneAccessError,
+) (*project.ServiceDeploymentResult, error) {
+	if d.flags.global.NoPrompt {
+		return nil, accessErr
+	}
+
+	d.console.Message(ctx, output.WithWarningFormat(
+		"Your account lacks the %s role on %s, required to deploy service %s.",
+		accessErr.RoleName, accessErr.ResourceName(), svc.Config.Name))
+
+	assign, err := d.console.Confirm(ctx, input.ConsoleOptions{
+		Message:      fmt.Sprintf("Assign the %s role on %s to your account?", accessErr.RoleName, accessErr.ResourceName()),
+		DefaultValue: false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("prompting to assign role: %w", err)
+	}
+
+	if !assign {
+		return nil, accessErr
+	}
+
+	err = d.azCli.AssignRoleToSignedInUser(ctx, env.GetSubscriptionId(), accessErr.ResourceId, accessErr.RoleName)
+	if err != nil {
+		return nil, fmt.Errorf("assigning the %s role to your account: %w", accessErr.RoleName, err)
+	}
+
+	d.console.Message(ctx, fmt.Sprintf(
+		"Assigned the %s role to your account, deploying service %s again once it applies, which can take a few minutes.",
+		accessErr.RoleName, svc.Config.Name))
+
+	var result *project.ServiceDeploymentResult
+	err = retry.Do(
+		ctx,
+		retry.WithMaxRetries(roleAssignmentRetries, retry.NewConstant(roleAssignmentRetryDelay)),
+		func(ctx context.Context) error {
+			deployed, deployErr := d.deployService(ctx, svc, packagePath)
+			if errors.As(deployErr, new(*azcli.DataPlaneAccessError)) {
+				return retry.RetryableError(deployErr)
+			}
+
+			result = deployed
+			return deployErr
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// prebuiltPackage validates the --from-package and --image flags and returns the package to deploy,
+// or an empty string when the service is to be built.
+func (d *deployAction) prebuiltPackage(projConfig *project.ProjectConfig) (string, error) {
+	if d.flags.fromPackage == "" && d.flags.image == "" {
+		return "", nil
+	}
+
+	if d.flags.fromPackage != "" && d.flags.image != "" {
+		return "", errors.New("only one of --from-package and --image can be specified")
+	}
+
+	serviceName := d.flags.serviceName
+	if serviceName == "" {
+		if len(projConfig.Services) != 1 {
+			return "", errors.New("--service must be specified when deploying a package or an image")
+		}
+
+		for name := range projConfig.Services {
+			serviceName = name
+		}
+		d.flags.serviceName = serviceName
+	}
+
+	host := project.ServiceTargetKind(projConfig.Services[serviceName].Host)
+
+	if d.flags.image != "" {
+		if host != project.ContainerAppTarget {
+			return "", fmt.Errorf(
+				"service '%s' is hosted on %s, --image is only supported for %s services",
+				serviceName, host, project.ContainerAppTarget,
+			)
+		}
+
+		return d.flags.image, nil
+	}
+
+	if host != project.AppServiceTarget && host != project.AzureFunctionTarget {
+		return "", fmt.Errorf(
+			"service '%s' is hosted on %s, --from-package is only supported for %s and %s services",
+			serviceName, host, project.AppServiceTarget, project.AzureFunctionTarget,
+		)
+	}
+
+	packagePath, err := filepath.Abs(d.flags.fromPackage)
+	if err != nil {
+		return "", fmt.Errorf("resolving package path: %w", err)
+	}
+
+	if _, err := os.Stat(packagePath); err != nil {
+		return "", fmt.Errorf("reading package '%s': %w", d.flags.fromPackage, err)
+	}
+
+	return packagePath, nil
 }
 
 func reportServiceDeploymentResultInteractive(
@@ -239,5 +577,9 @@ func reportServiceDeploymentResultInteractive(
 		builder.WriteString(fmt.Sprintf(" - Endpoint: %s\n", output.WithLinkFormat(endpoint)))
 	}
 
+	if !sdr.AppSettings.IsEmpty() {
+		builder.WriteString(fmt.Sprintf(" - App settings: %s\n", sdr.AppSettings.String()))
+	}
+
 	console.Message(ctx, builder.String())
 }
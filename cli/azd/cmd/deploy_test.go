@@ -0,0 +1,25 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchIgnorePatterns(t *testing.T) {
+	ignore := watchIgnorePatterns(&project.ServiceConfig{OutputPath: "./build/", Exclude: []string{"*.map"}})
+	require.Subset(t, ignore, []string{"node_modules", "build", "*.map"})
+
+	ignore = watchIgnorePatterns(&project.ServiceConfig{OutputPath: "../shared"})
+	require.Equal(t, watchIgnoredDirectories, ignore)
+}
+
+func TestDescribeChanges(t *testing.T) {
+	require.Equal(t, "app.py", describeChanges([]string{"app.py", "app.py"}))
+	require.Equal(t, "app.py and 1 other file", describeChanges([]string{"app.py", "static/app.js"}))
+	require.Equal(t, "app.py and 2 other files", describeChanges([]string{"app.py", "a.js", "b.js", "a.js"}))
+}
@@ -0,0 +1,237 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/doctor"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/bicep"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/github"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type doctorFlags struct {
+	outputFormat string
+	global       *internal.GlobalCommandOptions
+}
+
+func (d *doctorFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	output.AddOutputFlag(local, &d.outputFormat, []output.Format{output.JsonFormat, output.NoneFormat}, output.NoneFormat)
+	d.global = global
+}
+
+func doctorCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *doctorFlags) {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose common problems with your azd installation and project.",
+		Long: `Diagnose common problems with your azd installation and project.
+
+Checks the installed tool versions, Azure login status, network access to Azure, Microsoft Graph, Azure DevOps and GitHub,
+the selected environment and the azure.yaml file, and prints a report with steps to fix any problems found.`,
+	}
+
+	flags := &doctorFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return cmd, flags
+}
+
+type doctorAction struct {
+	flags     doctorFlags
+	azCli     azcli.AzCli
+	azdCtx    *azdcontext.AzdContext
+	gitCli    git.GitCli
+	formatter output.Formatter
+	writer    io.Writer
+	console   input.Console
+}
+
+func newDoctorAction(
+	flags doctorFlags,
+	azCli azcli.AzCli,
+	azdCtx *azdcontext.AzdContext,
+	gitCli git.GitCli,
+	formatter output.Formatter,
+	writer io.Writer,
+	console input.Console,
+) *doctorAction {
+	return &doctorAction{
+		flags:     flags,
+		azCli:     azCli,
+		azdCtx:    azdCtx,
+		gitCli:    gitCli,
+		formatter: formatter,
+		writer:    writer,
+		console:   console,
+	}
+}
+
+func (d *doctorAction) Run(ctx context.Context) error {
+	env := d.loadEnvironment()
+
+	report := doctor.Run(
+		ctx,
+		doctor.ToolsCheck(d.tools(ctx, env)),
+		doctor.AuthCheck(d.azCli),
+		doctor.NetworkCheck(httputil.GetHttpClient(ctx), doctor.DefaultEndpoints),
+		doctor.EnvironmentCheck(d.azdCtx, d.flags.global.EnvironmentName),
+		doctor.ProjectCheck(d.azdCtx, env),
+	)
+
+	if d.formatter.Kind() == output.JsonFormat {
+		if err := d.formatter.Format(report, d.writer, nil); err != nil {
+			return err
+		}
+	} else {
+		d.console.Message(ctx, formatDoctorReport(report))
+	}
+
+	if report.HasFailures() {
+		return errors.New("one or more diagnostic checks failed")
+	}
+
+	return nil
+}
+
+// Loads the selected environment, when one exists, so environment references within azure.yaml can be resolved
+func (d *doctorAction) loadEnvironment() *environment.Environment {
+	name := d.flags.global.EnvironmentName
+	if name == "" {
+		defaultName, err := d.azdCtx.GetSelectedEnvironmentName()
+		if err != nil {
+			log.Printf("ignoring error reading default environment: %v", err)
+			return nil
+		}
+
+		name = defaultName
+	}
+
+	if name == "" {
+		return nil
+	}
+
+	env, err := environment.GetEnvironment(d.azdCtx, name)
+	if err != nil {
+		log.Printf("ignoring error loading environment %s: %v", name, err)
+		return nil
+	}
+
+	return env
+}
+
+// Gets the tools azd depends on along with the tools required by the services within the project
+func (d *doctorAction) tools(ctx context.Context, env *environment.Environment) []doctor.Tool {
+	doctorTools := []doctor.Tool{
+		{Tool: d.azCli, Required: true},
+		{Tool: d.gitCli, Required: true},
+		{Tool: bicep.NewBicepCli(ctx), Required: true},
+		{Tool: github.NewGitHubCli(ctx), Required: false},
+		{Tool: docker.NewDocker(ctx), Required: false},
+	}
+
+	if env == nil {
+		env = environment.Ephemeral()
+	}
+
+	prj, err := project.LoadProjectConfig(d.azdCtx.ProjectPath(), env)
+	if err != nil {
+		// Errors loading the project are reported by the project check
+		return doctorTools
+	}
+
+	projectTools := []tools.ExternalTool{}
+	for _, svc := range prj.Services {
+		frameworkService, err := svc.GetFrameworkService(ctx, env)
+		if err != nil {
+			continue
+		}
+
+		projectTools = append(projectTools, (*frameworkService).RequiredExternalTools()...)
+	}
+
+	indexes := map[string]int{}
+	for i, tool := range doctorTools {
+		indexes[tool.Tool.Name()] = i
+	}
+
+	for _, tool := range tools.Unique(projectTools) {
+		// Tools used by a service are always required, i.e. docker for containerized services
+		if i, has := indexes[tool.Name()]; has {
+			doctorTools[i].Required = true
+			continue
+		}
+
+		doctorTools = append(doctorTools, doctor.Tool{Tool: tool, Required: true})
+	}
+
+	return doctorTools
+}
+
+func formatDoctorReport(report *doctor.Report) string {
+	var builder strings.Builder
+	category := ""
+	failed := 0
+	warnings := 0
+
+	for _, result := range report.Results {
+		if result.Category != category {
+			category = result.Category
+			builder.WriteString(fmt.Sprintf("\n%s\n", output.WithHighLightFormat(category)))
+		}
+
+		var status string
+		switch result.Status {
+		case doctor.StatusPassed:
+			status = output.WithSuccessFormat("(✓) Passed ")
+		case doctor.StatusWarning:
+			status = output.WithWarningFormat("(!) Warning")
+			warnings++
+		case doctor.StatusFailed:
+			status = output.WithErrorFormat("(x) Failed ")
+			failed++
+		default:
+			status = "(-) Skipped"
+		}
+
+		builder.WriteString(fmt.Sprintf("  %s %s", status, result.Name))
+		if result.Message != "" {
+			builder.WriteString(fmt.Sprintf(": %s", result.Message))
+		}
+		builder.WriteString("\n")
+
+		if result.Remediation != "" {
+			builder.WriteString(fmt.Sprintf("      %s\n", result.Remediation))
+		}
+	}
+
+	builder.WriteString("\n")
+	switch {
+	case failed > 0:
+		builder.WriteString(output.WithErrorFormat("%d check(s) failed, %d warning(s).", failed, warnings))
+	case warnings > 0:
+		builder.WriteString(output.WithWarningFormat("All checks passed with %d warning(s).", warnings))
+	default:
+		builder.WriteString(output.WithSuccessFormat("All checks passed."))
+	}
+
+	return builder.String()
+}
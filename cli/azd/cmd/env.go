@@ -5,17 +5,25 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/azure/azure-dev/cli/azd/cmd/middleware"
 	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/commands/pipeline"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
-	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -34,30 +42,65 @@ You can find all environment configurations under the *.azure\<environment-name>
 	}
 
 	root.Flags().BoolP("help", "h", false, fmt.Sprintf("Gets help for %s.", root.Name()))
-	root.AddCommand(BuildCmd(rootOptions, envSetCmdDesign, initEnvSetAction, nil))
+	root.AddCommand(BuildCmd(rootOptions, envSetCmdDesign, initEnvSetAction,
+		&buildOptions{middleware: []middleware.Middleware{requireProject(), requireAzCli()}}))
 	root.AddCommand(BuildCmd(rootOptions, envSelectCmdDesign, initEnvSelectAction, nil))
-	root.AddCommand(BuildCmd(rootOptions, envNewCmdDesign, initEnvNewAction, nil))
+	root.AddCommand(BuildCmd(rootOptions, envSetPolicyCmdDesign, initEnvSetPolicyAction,
+		&buildOptions{middleware: []middleware.Middleware{requireProject()}}))
+	root.AddCommand(BuildCmd(rootOptions, envSetTtlCmdDesign, initEnvSetTtlAction,
+		&buildOptions{middleware: []middleware.Middleware{requireProject(), requireAzCli()}}))
+	root.AddCommand(BuildCmd(rootOptions, envNewCmdDesign, initEnvNewAction,
+		&buildOptions{middleware: []middleware.Middleware{requireProject(), requireAzCli()}}))
 	root.AddCommand(BuildCmd(rootOptions, envListCmdDesign, initEnvListAction, nil))
-	root.AddCommand(BuildCmd(rootOptions, envRefreshCmdDesign, initEnvRefreshAction, nil))
-	root.AddCommand(BuildCmd(rootOptions, envGetValuesDesign, initEnvGetValuesAction, nil))
+	root.AddCommand(BuildCmd(rootOptions, envRefreshCmdDesign, initEnvRefreshAction,
+		&buildOptions{middleware: []middleware.Middleware{requireProject(), requireAzCli(), requireLogin()}}))
+	root.AddCommand(BuildCmd(rootOptions, envGetValuesDesign, initEnvGetValuesAction,
+		&buildOptions{middleware: []middleware.Middleware{requireProject(), requireAzCli()}}))
+	root.AddCommand(BuildCmd(rootOptions, envPullConfigCmdDesign, initEnvPullConfigAction,
+		&buildOptions{middleware: []middleware.Middleware{requireProject(), requireAzCli(), requireLogin()}}))
 
 	return root
 }
 
-func envSetCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *struct{}) {
+type envSetFlags struct {
+	syncPipeline bool
+	global       *internal.GlobalCommandOptions
+}
+
+func (f *envSetFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.BoolVar(
+		&f.syncPipeline,
+		"sync-pipeline",
+		false,
+		"Also sets the value in the pipeline configured for the environment, as a GitHub secret or an Azure DevOps "+
+			"pipeline variable.",
+	)
+
+	f.global = global
+}
+
+func envSetCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *envSetFlags) {
 	cmd := &cobra.Command{
 		Use:   "set <key> <value>",
 		Short: "Set a value in the environment.",
+		Long: `Set a value in the environment.
+
+With --sync-pipeline, the value is also set in the pipeline configured for the environment by ` +
+			output.WithBackticks("azd pipeline config") + `: as a secret of the GitHub repository, or as a variable
+of the Azure DevOps pipeline, so the pipeline runs with the same configuration as the environment.`,
 	}
 	cmd.Args = cobra.ExactArgs(2)
-	return cmd, &struct{}{}
+	flags := &envSetFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return cmd, flags
 }
 
 type envSetAction struct {
 	azCli   azcli.AzCli
 	console input.Console
 	azdCtx  *azdcontext.AzdContext
-	global  *internal.GlobalCommandOptions
+	flags   envSetFlags
 	args    []string
 }
 
@@ -65,31 +108,23 @@ func newEnvSetAction(
 	azdCtx *azdcontext.AzdContext,
 	azCli azcli.AzCli,
 	console input.Console,
-	global *internal.GlobalCommandOptions,
+	flags envSetFlags,
 	args []string,
 ) *envSetAction {
 	return &envSetAction{
 		azCli:   azCli,
 		console: console,
 		azdCtx:  azdCtx,
-		global:  global,
+		flags:   flags,
 		args:    args,
 	}
 }
 
 func (e *envSetAction) Run(ctx context.Context) error {
-	if err := ensureProject(e.azdCtx.ProjectPath()); err != nil {
-		return err
-	}
-
-	if err := tools.EnsureInstalled(ctx, e.azCli); err != nil {
-		return err
-	}
-
 	//lint:ignore SA4006 // We want ctx overridden here for future changes
 	env, ctx, err := loadOrInitEnvironment( //nolint:ineffassign,staticcheck
 		ctx,
-		&e.global.EnvironmentName,
+		&e.flags.global.EnvironmentName,
 		e.azdCtx,
 		e.console,
 	)
@@ -103,27 +138,340 @@ func (e *envSetAction) Run(ctx context.Context) error {
 		return fmt.Errorf("saving environment: %w", err)
 	}
 
+	if e.flags.syncPipeline {
+		manager := pipeline.NewPipelineManager(e.azdCtx, e.flags.global, pipeline.PipelineManagerArgs{
+			PipelineRemoteName: "origin",
+		})
+		manager.Environment = env
+
+		if err := manager.SyncEnvironmentValue(ctx, e.args[0], e.args[1]); err != nil {
+			return fmt.Errorf("syncing the pipeline: %w", err)
+		}
+	}
+
+	return nil
+}
+
+type envSetPolicyFlags struct {
+	protected bool
+	local     *pflag.FlagSet
+	global    *internal.GlobalCommandOptions
+}
+
+func (f *envSetPolicyFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.BoolVar(
+		&f.protected,
+		"protected",
+		false,
+		"Require the name of the environment to be typed to confirm destructive commands.",
+	)
+
+	f.local = local
+	f.global = global
+}
+
+func envSetPolicyCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *envSetPolicyFlags) {
+	cmd := &cobra.Command{
+		Use:   "set-policy [<environment>]",
+		Short: "Set the policy of an environment.",
+		//nolint:lll
+		Long: `Set the policy of an environment.
+
+A protected environment requires its name to be typed to confirm the commands that delete its resources or overwrite its configuration: ` + output.WithBackticks("azd down") + `, ` + output.WithBackticks("azd infra delete") + `, ` + output.WithBackticks("azd pipeline config") + ` and ` + output.WithBackticks("azd provision") + ` when the provisioning plan deletes resources. Removing the protection requires the same confirmation. When no policy flag is specified, the current policy of the environment is printed.
+
+Examples:
+
+	$ azd env set-policy prod --protected
+	$ azd env set-policy prod --protected=false`,
+	}
+	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.ValidArgsFunction = completeEnvironmentNameArg
+	flags := &envSetPolicyFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return cmd, flags
+}
+
+type envSetPolicyAction struct {
+	azdCtx  *azdcontext.AzdContext
+	console input.Console
+	flags   envSetPolicyFlags
+	args    []string
+}
+
+func newEnvSetPolicyAction(
+	azdCtx *azdcontext.AzdContext,
+	console input.Console,
+	flags envSetPolicyFlags,
+	args []string,
+) *envSetPolicyAction {
+	return &envSetPolicyAction{
+		azdCtx:  azdCtx,
+		console: console,
+		flags:   flags,
+		args:    args,
+	}
+}
+
+func (e *envSetPolicyAction) Run(ctx context.Context) error {
+	name := e.flags.global.EnvironmentName
+	if len(e.args) == 1 {
+		name = e.args[0]
+	}
+
+	if name == "" {
+		selected, err := e.azdCtx.GetSelectedEnvironmentName()
+		if err != nil {
+			return fmt.Errorf("getting default environment: %w", err)
+		}
+
+		name = selected
+	}
+
+	if name == "" {
+		return errors.New("no environment is selected, specify the environment to set the policy of")
+	}
+
+	env, err := environment.GetEnvironment(e.azdCtx, name)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("environment '%s' does not exist", name)
+	} else if err != nil {
+		return fmt.Errorf("loading environment '%s': %w", name, err)
+	}
+
+	if !e.flags.local.Changed("protected") {
+		e.console.Message(ctx, fmt.Sprintf("Environment %s is %s.",
+			output.WithHighLightFormat(name), protectionDescription(env.IsProtected())))
+		return nil
+	}
+
+	if !e.flags.protected {
+		err := confirmProtectedEnvironment(ctx, e.console, e.flags.global.NoPrompt, env, "removing its protection")
+		if err != nil {
+			return err
+		}
+	}
+
+	env.SetProtected(e.flags.protected)
+	if err := env.Save(); err != nil {
+		return fmt.Errorf("saving environment: %w", err)
+	}
+
+	e.console.Message(ctx, fmt.Sprintf("Environment %s is now %s.",
+		output.WithHighLightFormat(name), protectionDescription(e.flags.protected)))
+
 	return nil
 }
 
-func envSelectCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *struct{}) {
+func protectionDescription(protected bool) string {
+	if protected {
+		return "protected"
+	}
+
+	return "not protected"
+}
+
+type envSetTtlFlags struct {
+	global *internal.GlobalCommandOptions
+}
+
+func (f *envSetTtlFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	f.global = global
+}
+
+func envSetTtlCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *envSetTtlFlags) {
 	cmd := &cobra.Command{
-		Use:   "select <environment>",
-		Short: "Set the default environment.",
+		Use:   "set-ttl <duration>",
+		Short: "Set the time to live of an environment.",
+		//nolint:lll
+		Long: `Set the time to live of an environment.
+
+The environment expires once the duration elapsed. Its resource groups are tagged with the expiry, now and after every provisioning, and deleted by the cleanup pipeline written by ` + output.WithBackticks("azd pipeline config") + `, which runs every 6 hours. The duration is a number of days, i.e. 7d, or a duration like 72h or 90m. The time to live is removed with none.
+
+Examples:
+
+	$ azd env set-ttl 72h
+	$ azd env set-ttl 7d -e sandbox
+	$ azd env set-ttl none`,
 	}
 	cmd.Args = cobra.ExactArgs(1)
-	return cmd, &struct{}{}
+	flags := &envSetTtlFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return cmd, flags
+}
+
+type envSetTtlAction struct {
+	azdCtx  *azdcontext.AzdContext
+	console input.Console
+	flags   envSetTtlFlags
+	args    []string
+}
+
+func newEnvSetTtlAction(
+	azdCtx *azdcontext.AzdContext,
+	console input.Console,
+	flags envSetTtlFlags,
+	args []string,
+) *envSetTtlAction {
+	return &envSetTtlAction{
+		azdCtx:  azdCtx,
+		console: console,
+		flags:   flags,
+		args:    args,
+	}
+}
+
+func (e *envSetTtlAction) Run(ctx context.Context) error {
+	ttl, err := parseTtl(e.args[0])
+	if err != nil {
+		return err
+	}
+
+	name := e.flags.global.EnvironmentName
+	if name == "" {
+		selected, err := e.azdCtx.GetSelectedEnvironmentName()
+		if err != nil {
+			return fmt.Errorf("getting default environment: %w", err)
+		}
+
+		name = selected
+	}
+
+	if name == "" {
+		return errors.New("no environment is selected, specify the environment to set the time to live of")
+	}
+
+	env, err := environment.GetEnvironment(e.azdCtx, name)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("environment '%s' does not exist", name)
+	} else if err != nil {
+		return fmt.Errorf("loading environment '%s': %w", name, err)
+	}
+
+	var expiresOn time.Time
+	if ttl > 0 {
+		expiresOn = time.Now().Add(ttl)
+	}
+
+	env.SetExpiresOn(expiresOn)
+	if err := env.Save(); err != nil {
+		return fmt.Errorf("saving environment: %w", err)
+	}
+
+	if expiresOn.IsZero() {
+		e.console.Message(ctx, fmt.Sprintf("Environment %s no longer expires.", output.WithHighLightFormat(name)))
+	} else {
+		e.console.Message(ctx, fmt.Sprintf("Environment %s expires on %s.",
+			output.WithHighLightFormat(name), expiresOn.Local().Format("2006-01-02 15:04")))
+	}
+
+	// The resource groups of a provisioned environment are tagged now, the others once provisioned
+	if env.GetSubscriptionId() != "" {
+		if err := ensureLoggedIn(ctx); err != nil {
+			return fmt.Errorf("logging in: %w", err)
+		}
+
+		resourceGroups, err := infra.NewAzureResourceManager(ctx).TagEnvironmentExpiry(ctx, env)
+		if err != nil {
+			return fmt.Errorf("tagging the resource groups of the environment: %w", err)
+		}
+
+		if len(resourceGroups) > 0 {
+			e.console.Message(ctx, fmt.Sprintf("Tagged the resource groups %s.", strings.Join(resourceGroups, ", ")))
+		}
+	}
+
+	if !expiresOn.IsZero() && !pipeline.HasCleanupDefinition(e.azdCtx) {
+		e.console.Message(ctx, fmt.Sprintf(
+			"Run %s to add the pipeline deleting the expired environments to the project.",
+			output.WithBackticks("azd pipeline config")))
+	}
+
+	return nil
+}
+
+// parseTtl parses a time to live: a number of days like 7d, a duration like 72h, or none and 0 for no time to live
+func parseTtl(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "none" || value == "0" {
+		return 0, nil
+	}
+
+	var ttl time.Duration
+	if strings.HasSuffix(value, "d") {
+		count, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid time to live '%s', expected a number of days like 7d: %w", value, err)
+		}
+
+		ttl = time.Duration(count) * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid time to live '%s', expected a duration like 72h or 7d: %w", value, err)
+		}
+
+		ttl = parsed
+	}
+
+	if ttl <= 0 {
+		return 0, fmt.Errorf("invalid time to live '%s', the duration must be positive", value)
+	}
+
+	return ttl, nil
+}
+
+type envSelectFlags struct {
+	isDefault bool
+}
+
+func (f *envSelectFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.BoolVar(
+		&f.isDefault,
+		"default",
+		false,
+		"Make the environment the default environment of the project.",
+	)
+}
+
+func envSelectCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *envSelectFlags) {
+	cmd := &cobra.Command{
+		Use:   "select [<environment>]",
+		Short: "Select the environment to use.",
+		//nolint:lll
+		Long: `Select the environment to use.
+
+The selected environment is used by the commands run without the ` + output.WithBackticks("--environment") + ` flag. When the environment is omitted, it is picked from the environments of the project, listed with their subscription, location and last deployment time.
+
+With the ` + output.WithBackticks("--default") + ` flag, the environment also becomes the default environment of the project, which is used when the selected environment is deleted.`,
+	}
+	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.ValidArgsFunction = completeEnvironmentNameArg
+	flags := &envSelectFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return cmd, flags
 }
 
 type envSelectAction struct {
-	azdCtx *azdcontext.AzdContext
-	args   []string
+	azdCtx  *azdcontext.AzdContext
+	console input.Console
+	flags   envSelectFlags
+	args    []string
 }
 
-func newEnvSelectAction(azdCtx *azdcontext.AzdContext, args []string) *envSelectAction {
+func newEnvSelectAction(
+	azdCtx *azdcontext.AzdContext,
+	console input.Console,
+	flags envSelectFlags,
+	args []string,
+) *envSelectAction {
 	return &envSelectAction{
-		azdCtx: azdCtx,
-		args:   args,
+		azdCtx:  azdCtx,
+		console: console,
+		flags:   flags,
+		args:    args,
 	}
 }
 
@@ -132,13 +480,98 @@ func (e *envSelectAction) Run(ctx context.Context) error {
 		return err
 	}
 
-	if err := e.azdCtx.SetDefaultEnvironmentName(e.args[0]); err != nil {
-		return fmt.Errorf("setting default environment: %w", err)
+	var name string
+	if len(e.args) == 1 {
+		name = e.args[0]
+	} else {
+		picked, err := e.pickEnvironment(ctx)
+		if err != nil {
+			return err
+		}
+
+		name = picked
+	}
+
+	if _, err := os.Stat(filepath.Join(e.azdCtx.EnvironmentDirectory(), name)); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("environment '%s' does not exist, run `azd env new %s` to create it", name, name)
+	}
+
+	if e.flags.isDefault {
+		if err := e.azdCtx.SetDefaultEnvironmentName(name); err != nil {
+			return fmt.Errorf("setting default environment: %w", err)
+		}
+	} else {
+		if err := e.azdCtx.SetSelectedEnvironmentName(name); err != nil {
+			return fmt.Errorf("selecting environment: %w", err)
+		}
 	}
 
 	return nil
 }
 
+// pickEnvironment prompts for one of the environments of the project, showing the current state of each
+func (e *envSelectAction) pickEnvironment(ctx context.Context) (string, error) {
+	envs, err := e.azdCtx.ListEnvironments()
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(envs) == 0) {
+		return "", errors.New("the project has no environments, run `azd env new` to create one")
+	} else if err != nil {
+		return "", fmt.Errorf("listing environments: %w", err)
+	}
+
+	options := make([]string, 0, len(envs))
+	var selected string
+	for _, view := range envs {
+		env, err := environment.GetEnvironment(e.azdCtx, view.Name)
+		if err != nil {
+			return "", fmt.Errorf("loading environment '%s': %w", view.Name, err)
+		}
+
+		option := environmentSummary(view, env)
+		options = append(options, option)
+
+		if view.IsSelected {
+			selected = option
+		}
+	}
+
+	index, err := e.console.Select(ctx, input.ConsoleOptions{
+		Message:      "Select an environment to use:",
+		Options:      options,
+		DefaultValue: selected,
+	})
+	if err != nil {
+		return "", fmt.Errorf("selecting environment: %w", err)
+	}
+
+	return envs[index].Name, nil
+}
+
+// environmentSummary describes the environment with its subscription, location and last deployment time, i.e.
+// "dev (default) - subscription 00000000-0000-0000-0000-000000000000, eastus2, last deployed 2022-10-01 12:00"
+func environmentSummary(view azdcontext.EnvironmentView, env *environment.Environment) string {
+	name := view.Name
+	if view.IsDefault {
+		name += " (default)"
+	}
+
+	details := []string{}
+	if subscriptionId := env.GetSubscriptionId(); subscriptionId != "" {
+		details = append(details, fmt.Sprintf("subscription %s", subscriptionId))
+	}
+
+	if location := env.GetLocation(); location != "" {
+		details = append(details, location)
+	}
+
+	if deployedAt, has := env.GetLastDeploymentTime(); has {
+		details = append(details, fmt.Sprintf("last deployed %s", deployedAt.Local().Format("2006-01-02 15:04")))
+	} else {
+		details = append(details, "never deployed")
+	}
+
+	return fmt.Sprintf("%s - %s", name, strings.Join(details, ", "))
+}
+
 func envListCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *struct{}) {
 	cmd := &cobra.Command{
 		Use:     "list",
@@ -155,13 +588,20 @@ func envListCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *s
 
 type envListAction struct {
 	azdCtx    *azdcontext.AzdContext
+	console   input.Console
 	formatter output.Formatter
 	writer    io.Writer
 }
 
-func newEnvListAction(azdCtx *azdcontext.AzdContext, formatter output.Formatter, writer io.Writer) *envListAction {
+func newEnvListAction(
+	azdCtx *azdcontext.AzdContext,
+	console input.Console,
+	formatter output.Formatter,
+	writer io.Writer,
+) *envListAction {
 	return &envListAction{
 		azdCtx:    azdCtx,
+		console:   console,
 		formatter: formatter,
 		writer:    writer,
 	}
@@ -172,41 +612,76 @@ func (e *envListAction) Run(ctx context.Context) error {
 		return err
 	}
 
-	envs, err := e.azdCtx.ListEnvironments()
+	views, err := e.azdCtx.ListEnvironments()
 
 	if err != nil {
 		return fmt.Errorf("listing environments: %w", err)
 	}
 
+	envs := make([]envListItem, 0, len(views))
+	for _, view := range views {
+		env, err := environment.GetEnvironment(e.azdCtx, view.Name)
+		if err != nil {
+			return fmt.Errorf("loading environment '%s': %w", view.Name, err)
+		}
+
+		item := envListItem{EnvironmentView: view}
+		if expiresOn, has := env.GetExpiresOn(); has {
+			item.ExpiresOn = &expiresOn
+		}
+
+		envs = append(envs, item)
+	}
+
 	if e.formatter.Kind() == output.TableFormat {
-		columns := []output.Column{
-			{
-				Heading:       "NAME",
-				ValueTemplate: "{{.Name}}",
-			},
-			{
-				Heading:       "DEFAULT",
-				ValueTemplate: "{{.IsDefault}}",
-			},
+		rows := make([][]string, 0, len(envs))
+		for _, env := range envs {
+			expiresIn := ""
+			if env.ExpiresOn != nil {
+				expiresIn = formatExpiresIn(time.Until(*env.ExpiresOn))
+			}
+
+			rows = append(rows, []string{env.Name, fmt.Sprint(env.IsDefault), fmt.Sprint(env.IsSelected), expiresIn})
 		}
 
-		err = e.formatter.Format(envs, e.writer, output.TableFormatterOptions{
-			Columns: columns,
-		})
-	} else {
-		err = e.formatter.Format(envs, e.writer, nil)
+		e.console.Table(ctx, []string{"NAME", "DEFAULT", "SELECTED", "EXPIRES IN"}, rows)
+		return nil
 	}
-	if err != nil {
-		return err
+
+	return e.formatter.Format(envs, e.writer, nil)
+}
+
+// envListItem is an environment of azd env list, with the time it expires on when it has a time to live
+type envListItem struct {
+	azdcontext.EnvironmentView
+	ExpiresOn *time.Time `json:",omitempty"`
+}
+
+// formatExpiresIn describes the time remaining before an environment expires, i.e. "2d 5h" or "45m"
+func formatExpiresIn(remaining time.Duration) string {
+	if remaining <= 0 {
+		return "expired"
 	}
 
-	return nil
+	days := int(remaining / (24 * time.Hour))
+	hours := int(remaining % (24 * time.Hour) / time.Hour)
+	minutes := int(remaining % time.Hour / time.Minute)
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
 }
 
 type envNewFlags struct {
-	subscription string
-	location     string
-	global       *internal.GlobalCommandOptions
+	subscription   string
+	location       string
+	fromDeployment string
+	global         *internal.GlobalCommandOptions
 }
 
 func (f *envNewFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
@@ -217,6 +692,13 @@ func (f *envNewFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandO
 		"Name or ID of an Azure subscription to use for the new environment",
 	)
 	local.StringVarP(&f.location, "location", "l", "", "Azure location for the new environment")
+	local.StringVar(
+		&f.fromDeployment,
+		"from-deployment",
+		"",
+		"Import the infrastructure provisioned for an environment, by the name of the environment or of one of its "+
+			"resource groups",
+	)
 
 	f.global = global
 }
@@ -228,6 +710,9 @@ func envNewCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *en
 	}
 	f := &envNewFlags{}
 	f.Bind(cmd.Flags(), global)
+
+	registerFlagCompletion(cmd, "subscription", completeSubscriptionIds)
+
 	return cmd, f
 }
 
@@ -253,12 +738,8 @@ func newEnvNewAction(
 }
 
 func (en *envNewAction) Run(ctx context.Context) error {
-	if err := ensureProject(en.azdCtx.ProjectPath()); err != nil {
-		return err
-	}
-
-	if err := tools.EnsureInstalled(ctx, en.azCli); err != nil {
-		return err
+	if en.flags.fromDeployment != "" {
+		return en.importFromDeployment(ctx)
 	}
 
 	envSpec := environmentSpec{
@@ -277,6 +758,136 @@ func (en *envNewAction) Run(ctx context.Context) error {
 	return nil
 }
 
+// importFromDeployment creates the environment of infrastructure provisioned by a previous azd run, possibly on another
+// machine. The environment is named after the environment the infrastructure was provisioned for, and its values are
+// imported from the latest succeeded deployment of that environment.
+func (en *envNewAction) importFromDeployment(ctx context.Context) error {
+	if en.flags.location != "" {
+		return errors.New("--location can't be set with --from-deployment, the location of the deployment is used")
+	}
+
+	prj, err := project.LoadProjectConfig(en.azdCtx.ProjectPath(), environment.Ephemeral())
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+
+	// The state of terraform isn't kept with the deployments of the subscription, and the infrastructure imported by the
+	// passthrough provider isn't deployed by azd
+	if prj.Infra.Provider == provisioning.Terraform || prj.Infra.Provider == provisioning.Passthrough {
+		return fmt.Errorf("--from-deployment is not supported by the %s provisioning provider", prj.Infra.Provider)
+	}
+
+	if err := ensureLoggedIn(ctx); err != nil {
+		return fmt.Errorf("logging in: %w", err)
+	}
+
+	subscriptionId, tenantId := en.flags.subscription, ""
+	if subscriptionId == "" {
+		subscriptionId, tenantId, err = promptSubscription(ctx, en.console)
+		if err != nil {
+			return err
+		}
+	}
+
+	sourceEnvName, err := en.sourceEnvironmentName(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	// The names of the resources and deployments of the infrastructure are derived from the name of the environment
+	if en.flags.global.EnvironmentName != "" && en.flags.global.EnvironmentName != sourceEnvName {
+		return fmt.Errorf(
+			"the infrastructure was provisioned for the environment %s, the new environment must have the same name",
+			sourceEnvName)
+	}
+
+	deployments, err := en.azCli.ListSubscriptionDeployments(ctx, subscriptionId)
+	if err != nil {
+		return fmt.Errorf("listing deployments: %w", err)
+	}
+
+	deployment := provisioning.LatestSucceededDeployment(sourceEnvName, deployments)
+	if deployment == nil || deployment.Location == nil {
+		return fmt.Errorf(
+			"no succeeded deployment of the environment %s found in the subscription %s", sourceEnvName, subscriptionId)
+	}
+
+	envSpec := environmentSpec{
+		environmentName: sourceEnvName,
+		subscription:    subscriptionId,
+		location:        *deployment.Location,
+	}
+	env, ctx, err := createAndInitEnvironment(ctx, &envSpec, en.azdCtx, en.console)
+	if err != nil {
+		return fmt.Errorf("creating new environment: %w", err)
+	}
+
+	if tenantId != "" {
+		env.SetTenantId(tenantId)
+	}
+
+	env.SetDeploymentName(*deployment.Name)
+	if err := env.Save(); err != nil {
+		return fmt.Errorf("saving environment: %w", err)
+	}
+
+	if err := en.azdCtx.SetDefaultEnvironmentName(envSpec.environmentName); err != nil {
+		return fmt.Errorf("saving default environment: %w", err)
+	}
+
+	prj, err = project.LoadProjectConfig(en.azdCtx.ProjectPath(), env)
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+
+	infraManager, err := provisioning.NewManager(ctx, env, prj.Path, prj.Infra, !en.flags.global.NoPrompt)
+	if err != nil {
+		return fmt.Errorf("creating provisioning manager: %w", err)
+	}
+
+	scope := infra.NewSubscriptionScope(ctx, env.GetLocation(), env.GetSubscriptionId(), env.GetDeploymentName())
+	getStateResult, err := infraManager.State(ctx, scope)
+	if err != nil {
+		return fmt.Errorf("getting deployment: %w", err)
+	}
+
+	if err := provisioning.UpdateEnvironment(env, getStateResult.State.Outputs); err != nil {
+		return err
+	}
+
+	if err := provisioning.StoreSecureOutputs(ctx, en.azCli, env, getStateResult.State.Outputs); err != nil {
+		return err
+	}
+
+	en.console.Message(ctx, fmt.Sprintf(
+		"Imported the %d outputs of the deployment %s into the environment %s.",
+		len(getStateResult.State.Outputs),
+		output.WithHighLightFormat(*deployment.Name),
+		output.WithHighLightFormat(env.GetEnvName()),
+	))
+
+	return nil
+}
+
+// sourceEnvironmentName returns the name of the environment --from-deployment refers to: the azd-env-name tag of the
+// resource group of that name, or the name itself when the subscription has no such resource group
+func (en *envNewAction) sourceEnvironmentName(ctx context.Context, subscriptionId string) (string, error) {
+	tags, err := en.azCli.GetResourceGroupTags(ctx, subscriptionId, en.flags.fromDeployment)
+	switch {
+	case errors.Is(err, azcli.ErrResourceGroupNotFound):
+		return en.flags.fromDeployment, nil
+	case err != nil:
+		return "", err
+	}
+
+	if envName := tags["azd-env-name"]; envName != "" {
+		return envName, nil
+	}
+
+	return "", fmt.Errorf(
+		"the resource group %s wasn't provisioned by azd, it has no azd-env-name tag", en.flags.fromDeployment)
+}
+
 func envRefreshCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *struct{}) {
 	cmd := &cobra.Command{
 		Use:   "refresh",
@@ -319,18 +930,6 @@ func newEnvRefreshAction(
 }
 
 func (ef *envRefreshAction) Run(ctx context.Context) error {
-	if err := ensureProject(ef.azdCtx.ProjectPath()); err != nil {
-		return err
-	}
-
-	if err := tools.EnsureInstalled(ctx, ef.azCli); err != nil {
-		return err
-	}
-
-	if err := ensureLoggedIn(ctx); err != nil {
-		return fmt.Errorf("failed to ensure login: %w", err)
-	}
-
 	env, ctx, err := loadOrInitEnvironment(ctx, &ef.global.EnvironmentName, ef.azdCtx, ef.console)
 	if err != nil {
 		return fmt.Errorf("loading environment: %w", err)
@@ -349,7 +948,12 @@ func (ef *envRefreshAction) Run(ctx context.Context) error {
 		return fmt.Errorf("creating provisioning manager: %w", err)
 	}
 
-	scope := infra.NewSubscriptionScope(ctx, env.GetLocation(), env.GetSubscriptionId(), env.GetEnvName())
+	deploymentName, err := provisioning.ResolveDeploymentName(ctx, ef.azCli, env)
+	if err != nil {
+		return err
+	}
+
+	scope := infra.NewSubscriptionScope(ctx, env.GetLocation(), env.GetSubscriptionId(), deploymentName)
 
 	getStateResult, err := infraManager.State(ctx, scope)
 	if err != nil {
@@ -360,6 +964,10 @@ func (ef *envRefreshAction) Run(ctx context.Context) error {
 		return err
 	}
 
+	if err := provisioning.StoreSecureOutputs(ctx, ef.azCli, env, getStateResult.State.Outputs); err != nil {
+		return err
+	}
+
 	ef.console.Message(ctx, "Environments setting refresh completed")
 
 	if formatter.Kind() == output.JsonFormat {
@@ -413,14 +1021,6 @@ func newEnvGetValuesAction(
 	}
 }
 func (eg *envGetValuesAction) Run(ctx context.Context) error {
-	if err := ensureProject(eg.azdCtx.ProjectPath()); err != nil {
-		return err
-	}
-
-	if err := tools.EnsureInstalled(ctx, eg.azCli); err != nil {
-		return err
-	}
-
 	//lint:ignore SA4006 // We want ctx overridden here for future changes
 	env, ctx, err := loadOrInitEnvironment( //nolint:ineffassign,staticcheck
 		ctx,
@@ -439,3 +1039,62 @@ func (eg *envGetValuesAction) Run(ctx context.Context) error {
 
 	return nil
 }
+
+func envPullConfigCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *struct{}) {
+	cmd := &cobra.Command{
+		Use:   "pull-config",
+		Short: "Pull environment values from Azure App Configuration.",
+		//nolint:lll
+		Long: `Pull environment values from Azure App Configuration.
+
+The keys listed in the ` + output.WithBackticks("appConfiguration") + ` section of the ` + output.WithBackticks(azdcontext.ProjectFileName) + ` file are pulled from the App Configuration store into the environment, using the values labeled with the name of the environment. The same keys are pushed to the store when the environment is provisioned.`,
+	}
+
+	return cmd, &struct{}{}
+}
+
+type envPullConfigAction struct {
+	azdCtx  *azdcontext.AzdContext
+	azCli   azcli.AzCli
+	console input.Console
+	global  *internal.GlobalCommandOptions
+}
+
+func newEnvPullConfigAction(
+	azdCtx *azdcontext.AzdContext,
+	azCli azcli.AzCli,
+	console input.Console,
+	global *internal.GlobalCommandOptions,
+) *envPullConfigAction {
+	return &envPullConfigAction{
+		azdCtx:  azdCtx,
+		azCli:   azCli,
+		console: console,
+		global:  global,
+	}
+}
+
+func (e *envPullConfigAction) Run(ctx context.Context) error {
+	env, ctx, err := loadOrInitEnvironment(ctx, &e.global.EnvironmentName, e.azdCtx, e.console)
+	if err != nil {
+		return fmt.Errorf("loading environment: %w", err)
+	}
+
+	prj, err := project.LoadProjectConfig(e.azdCtx.ProjectPath(), env)
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+
+	pulled, err := project.PullAppConfiguration(ctx, e.azCli, prj.AppConfiguration, env)
+	if err != nil {
+		return fmt.Errorf("pulling environment values from app configuration: %w", err)
+	}
+
+	if err := env.Save(); err != nil {
+		return fmt.Errorf("saving environment: %w", err)
+	}
+
+	e.console.Message(ctx, fmt.Sprintf("Pulled %d environment values from App Configuration.", pulled))
+
+	return nil
+}
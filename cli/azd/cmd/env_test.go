@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_envNewAction_sourceEnvironmentName(t *testing.T) {
+	setupMocks := func(mockContext *mocks.MockContext) {
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/resourcegroups/rg-dev")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armresources.ResourceGroup{
+				Name: convert.RefOf("rg-dev"),
+				Tags: map[string]*string{"azd-env-name": convert.RefOf("dev")},
+			})
+		})
+
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/resourcegroups/rg-manual")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armresources.ResourceGroup{
+				Name: convert.RefOf("rg-manual"),
+			})
+		})
+
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/resourcegroups/dev")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+		})
+	}
+
+	tests := []struct {
+		name           string
+		fromDeployment string
+		expected       string
+		expectError    bool
+	}{
+		{name: "TaggedResourceGroup", fromDeployment: "rg-dev", expected: "dev"},
+		{name: "UntaggedResourceGroup", fromDeployment: "rg-manual", expectError: true},
+		{name: "EnvironmentName", fromDeployment: "dev", expected: "dev"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			setupMocks(mockContext)
+
+			action := &envNewAction{
+				azCli: azcli.GetAzCli(*mockContext.Context),
+				flags: envNewFlags{fromDeployment: test.fromDeployment},
+			}
+
+			envName, err := action.sourceEnvironmentName(*mockContext.Context, "SUBSCRIPTION_ID")
+			if test.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expected, envName)
+		})
+	}
+}
+
+func Test_parseTtl(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    time.Duration
+		expectError bool
+	}{
+		{value: "72h", expected: 72 * time.Hour},
+		{value: "90m", expected: 90 * time.Minute},
+		{value: "7d", expected: 7 * 24 * time.Hour},
+		{value: "none", expected: 0},
+		{value: "0", expected: 0},
+		{value: "-1h", expectError: true},
+		{value: "0d", expectError: true},
+		{value: "1.5d", expectError: true},
+		{value: "soon", expectError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			ttl, err := parseTtl(test.value)
+			if test.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expected, ttl)
+		})
+	}
+}
+
+func Test_formatExpiresIn(t *testing.T) {
+	require.Equal(t, "expired", formatExpiresIn(-time.Minute))
+	require.Equal(t, "45m", formatExpiresIn(45*time.Minute))
+	require.Equal(t, "3h 20m", formatExpiresIn(3*time.Hour+20*time.Minute))
+	require.Equal(t, "2d 5h", formatExpiresIn(53*time.Hour+10*time.Minute))
+}
@@ -0,0 +1,144 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+// The size of the remote terminal when the size of the local terminal can't be determined
+const (
+	defaultTerminalWidth  = 80
+	defaultTerminalHeight = 24
+)
+
+type execFlags struct {
+	command string
+	global  *internal.GlobalCommandOptions
+}
+
+func (e *execFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(
+		&e.command,
+		"command",
+		"",
+		"The command to run in the service's runtime (when unspecified, the runtime's default shell is started).",
+	)
+
+	e.global = global
+}
+
+func execCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *execFlags) {
+	cmd := &cobra.Command{
+		Use:   "exec [<service>]",
+		Short: "Open an interactive session in the runtime of a deployed service.",
+		//nolint:lll
+		Long: `Open an interactive session in the runtime of a deployed service.
+
+The session is opened in the service's container with App Service SSH, for services hosted on App Service or Azure Functions on Linux, or with Container Apps exec, for services hosted on Container Apps. The session is authenticated with the account you are logged in with. The service can be omitted when the ` + output.WithBackticks(azdcontext.ProjectFileName) + ` file lists a single service.
+
+Examples:
+
+	$ azd exec api
+	$ azd exec web --command "ls -la /app"`,
+	}
+	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.ValidArgsFunction = completeServiceNameArg
+	flags := &execFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return cmd, flags
+}
+
+type execAction struct {
+	flags   execFlags
+	args    []string
+	azdCtx  *azdcontext.AzdContext
+	console input.Console
+}
+
+func newExecAction(
+	flags execFlags,
+	args []string,
+	azdCtx *azdcontext.AzdContext,
+	console input.Console,
+) *execAction {
+	return &execAction{
+		flags:   flags,
+		args:    args,
+		azdCtx:  azdCtx,
+		console: console,
+	}
+}
+
+func (e *execAction) Run(ctx context.Context) error {
+	env, ctx, err := loadOrInitEnvironment(ctx, &e.flags.global.EnvironmentName, e.azdCtx, e.console)
+	if err != nil {
+		return fmt.Errorf("loading environment: %w", err)
+	}
+
+	projConfig, err := project.LoadProjectConfig(e.azdCtx.ProjectPath(), env)
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+
+	svc, err := resolveService(&ctx, projConfig, env, e.args)
+	if err != nil {
+		return err
+	}
+
+	shell, ok := svc.Target.(project.RemoteShell)
+	if !ok {
+		return fmt.Errorf("opening a session in services hosted on '%s' is not supported", svc.Config.Host)
+	}
+
+	e.console.Message(ctx, fmt.Sprintf("Connecting to service %s...", output.WithHighLightFormat(svc.Config.Name)))
+
+	session, err := shell.OpenSession(ctx, e.flags.command)
+	if err != nil {
+		return fmt.Errorf("opening session: %w", err)
+	}
+	defer session.Close()
+
+	handles := e.console.Handles()
+	width, height := defaultTerminalWidth, defaultTerminalHeight
+	if stdout, ok := handles.Stdout.(*os.File); ok && isatty.IsTerminal(stdout.Fd()) {
+		if w, h, err := term.GetSize(int(stdout.Fd())); err == nil {
+			width, height = w, h
+		}
+	}
+
+	// The keys are sent to the remote terminal as they are typed, including Ctrl+C
+	if stdin, ok := handles.Stdin.(*os.File); ok && isatty.IsTerminal(stdin.Fd()) {
+		state, err := term.MakeRaw(int(stdin.Fd()))
+		if err != nil {
+			return fmt.Errorf("configuring terminal: %w", err)
+		}
+
+		defer func() {
+			if err := term.Restore(int(stdin.Fd()), state); err != nil {
+				log.Printf("failed restoring terminal: %v", err)
+			}
+		}()
+	}
+
+	if err := session.Run(handles.Stdin, handles.Stdout, handles.Stderr, width, height); err != nil {
+		return fmt.Errorf("running session: %w", err)
+	}
+
+	return nil
+}
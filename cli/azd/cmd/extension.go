@@ -0,0 +1,313 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/extensions"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+func extensionCmd(rootOptions *internal.GlobalCommandOptions) *cobra.Command {
+	root := &cobra.Command{
+		Use:     "extension",
+		Short:   "Manage azd extensions.",
+		Aliases: []string{"ext"},
+		Long: `Manage azd extensions.
+
+Extensions are external executables that add commands to azd and are notified of lifecycle events,
+such as provisioning and deployment. Commands contributed by an extension are run with 'azd <extension> <command>'.`,
+	}
+
+	root.Flags().BoolP("help", "h", false, fmt.Sprintf("Gets help for %s.", root.Name()))
+
+	root.AddCommand(BuildCmd(rootOptions, extensionInstallCmdDesign, initExtensionInstallAction, nil))
+	root.AddCommand(BuildCmd(rootOptions, extensionListCmdDesign, initExtensionListAction, nil))
+	root.AddCommand(BuildCmd(rootOptions, extensionUninstallCmdDesign, initExtensionUninstallAction, nil))
+
+	return root
+}
+
+// addExtensionCommands adds the commands contributed by the installed extensions to the root command.
+// Extensions whose name conflicts with a built in command are skipped.
+func addExtensionCommands(root *cobra.Command, rootOptions *internal.GlobalCommandOptions) {
+	manager, err := extensions.NewManager()
+	if err != nil {
+		log.Printf("failed creating extension manager: %v", err)
+		return
+	}
+
+	installed, err := manager.List()
+	if err != nil {
+		log.Printf("failed listing extensions: %v", err)
+		return
+	}
+
+	for _, extension := range installed {
+		if len(extension.Commands) == 0 {
+			continue
+		}
+
+		if existing, _, err := root.Find([]string{extension.Name}); err == nil && existing != root {
+			log.Printf("skipping extension '%s', the name conflicts with an existing command", extension.Name)
+			continue
+		}
+
+		extensionRoot := &cobra.Command{
+			Use:   extension.Name,
+			Short: extension.Description,
+		}
+
+		extensionRoot.Flags().BoolP("help", "h", false, fmt.Sprintf("Gets help for %s.", extensionRoot.Name()))
+
+		for _, command := range extension.Commands {
+			// Extension commands are not emitted in telemetry since their names are defined by third parties
+			extensionRoot.AddCommand(BuildCmd(
+				rootOptions,
+				extensionRunCmdDesign(extension, command),
+				initExtensionRunAction,
+				&buildOptions{disableTelemetry: true},
+			))
+		}
+
+		root.AddCommand(extensionRoot)
+	}
+}
+
+// azd extension install <source>
+
+func extensionInstallCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *struct{}) {
+	cmd := &cobra.Command{
+		Use:   "install <source>",
+		Short: "Install an extension.",
+		Long: `Install an extension.
+
+The source is a local directory containing an extension.json manifest, or the path to the manifest itself.
+Installing an extension that is already installed replaces the existing installation.`,
+	}
+	cmd.Args = cobra.ExactArgs(1)
+	return cmd, &struct{}{}
+}
+
+type extensionInstallAction struct {
+	manager *extensions.Manager
+	console input.Console
+	args    []string
+}
+
+func newExtensionInstallAction(
+	manager *extensions.Manager,
+	console input.Console,
+	args []string,
+) *extensionInstallAction {
+	return &extensionInstallAction{
+		manager: manager,
+		console: console,
+		args:    args,
+	}
+}
+
+// Executes the `azd extension install <source>` action
+func (a *extensionInstallAction) Run(ctx context.Context) error {
+	extension, err := a.manager.Install(a.args[0])
+	if err != nil {
+		return fmt.Errorf("installing extension: %w", err)
+	}
+
+	a.console.Message(ctx, fmt.Sprintf("Installed extension '%s' (version %s)", extension.Name, extension.Version))
+
+	return nil
+}
+
+// azd extension list
+
+func extensionListCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *struct{}) {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List installed extensions.",
+		Aliases: []string{"ls"},
+	}
+	output.AddOutputParam(
+		cmd,
+		[]output.Format{output.JsonFormat, output.TableFormat},
+		output.TableFormat,
+	)
+	return cmd, &struct{}{}
+}
+
+type extensionListAction struct {
+	manager   *extensions.Manager
+	formatter output.Formatter
+	writer    io.Writer
+}
+
+func newExtensionListAction(
+	manager *extensions.Manager,
+	formatter output.Formatter,
+	writer io.Writer,
+) *extensionListAction {
+	return &extensionListAction{
+		manager:   manager,
+		formatter: formatter,
+		writer:    writer,
+	}
+}
+
+// Executes the `azd extension list` action
+func (a *extensionListAction) Run(ctx context.Context) error {
+	installed, err := a.manager.List()
+	if err != nil {
+		return fmt.Errorf("listing extensions: %w", err)
+	}
+
+	if a.formatter.Kind() == output.TableFormat {
+		columns := []output.Column{
+			{
+				Heading:       "NAME",
+				ValueTemplate: "{{.Name}}",
+			},
+			{
+				Heading:       "VERSION",
+				ValueTemplate: "{{.Version}}",
+			},
+			{
+				Heading:       "DESCRIPTION",
+				ValueTemplate: "{{.Description}}",
+			},
+		}
+
+		err = a.formatter.Format(installed, a.writer, output.TableFormatterOptions{
+			Columns: columns,
+		})
+	} else {
+		err = a.formatter.Format(installed, a.writer, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// azd extension uninstall <name>
+
+func extensionUninstallCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *struct{}) {
+	cmd := &cobra.Command{
+		Use:   "uninstall <name>",
+		Short: "Uninstall an extension.",
+	}
+	cmd.Args = cobra.ExactArgs(1)
+	return cmd, &struct{}{}
+}
+
+type extensionUninstallAction struct {
+	manager *extensions.Manager
+	console input.Console
+	args    []string
+}
+
+func newExtensionUninstallAction(
+	manager *extensions.Manager,
+	console input.Console,
+	args []string,
+) *extensionUninstallAction {
+	return &extensionUninstallAction{
+		manager: manager,
+		console: console,
+		args:    args,
+	}
+}
+
+// Executes the `azd extension uninstall <name>` action
+func (a *extensionUninstallAction) Run(ctx context.Context) error {
+	name := a.args[0]
+
+	if err := a.manager.Uninstall(name); err != nil {
+		return fmt.Errorf("uninstalling extension: %w", err)
+	}
+
+	a.console.Message(ctx, fmt.Sprintf("Uninstalled extension '%s'", name))
+
+	return nil
+}
+
+// azd <extension> <command>
+
+type extensionRunFlags struct {
+	extension *extensions.Extension
+	command   string
+}
+
+func extensionRunCmdDesign(
+	extension *extensions.Extension,
+	command extensions.CommandManifest,
+) designBuilder[extensionRunFlags] {
+	return func(global *internal.GlobalCommandOptions) (*cobra.Command, *extensionRunFlags) {
+		cmd := &cobra.Command{
+			Use:   command.Name,
+			Short: command.Short,
+			// All arguments, including flags, are passed through to the extension
+			DisableFlagParsing: true,
+		}
+
+		return cmd, &extensionRunFlags{
+			extension: extension,
+			command:   command.Name,
+		}
+	}
+}
+
+type extensionRunAction struct {
+	flags         extensionRunFlags
+	azdCtx        *azdcontext.AzdContext
+	console       input.Console
+	commandRunner exec.CommandRunner
+	args          []string
+}
+
+func newExtensionRunAction(
+	flags extensionRunFlags,
+	azdCtx *azdcontext.AzdContext,
+	console input.Console,
+	commandRunner exec.CommandRunner,
+	args []string,
+) *extensionRunAction {
+	return &extensionRunAction{
+		flags:         flags,
+		azdCtx:        azdCtx,
+		console:       console,
+		commandRunner: commandRunner,
+		args:          args,
+	}
+}
+
+// Executes the `azd <extension> <command>` action
+func (a *extensionRunAction) Run(ctx context.Context) error {
+	// The default environment is only available when running within a project
+	environmentName, err := a.azdCtx.GetSelectedEnvironmentName()
+	if err != nil {
+		log.Printf("failed getting default environment name: %v", err)
+	}
+
+	return extensions.Invoke(ctx, a.commandRunner, a.flags.extension, extensions.InvokeArgs{
+		Method: extensions.MethodRunCommand,
+		Params: extensions.CommandParams{
+			Command:         a.flags.command,
+			Args:            a.args,
+			ProjectDir:      a.azdCtx.ProjectDirectory(),
+			EnvironmentName: environmentName,
+		},
+		Stdout: a.console.Handles().Stdout,
+		Stderr: a.console.Handles().Stderr,
+	})
+}
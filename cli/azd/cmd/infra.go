@@ -6,6 +6,7 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/azure/azure-dev/cli/azd/cmd/middleware"
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/spf13/cobra"
 )
@@ -16,7 +17,16 @@ func infraCmd(rootOptions *internal.GlobalCommandOptions) *cobra.Command {
 		Short: "Manage Azure resources.",
 	}
 	cmd.Flags().BoolP("help", "h", false, fmt.Sprintf("Gets help for %s.", cmd.Name()))
-	cmd.AddCommand(BuildCmd(rootOptions, infraCreateCmdDesign, initInfraCreateAction, nil))
-	cmd.AddCommand(BuildCmd(rootOptions, infraDeleteCmdDesign, initInfraDeleteAction, nil))
+	cmd.AddCommand(BuildCmd(rootOptions, infraCreateCmdDesign, initInfraCreateAction,
+		&buildOptions{middleware: []middleware.Middleware{
+			requireProject(), eventLog(rootOptions), requireAzCli(), requireLogin(), provisionEvents(),
+			pipelineDefinitionCheck(), notifications(rootOptions, "provision"),
+		}}))
+	cmd.AddCommand(BuildCmd(rootOptions, infraDeleteCmdDesign, initInfraDeleteAction,
+		&buildOptions{middleware: []middleware.Middleware{
+			requireProject(), eventLog(rootOptions), requireAzCli(), requireLogin(), requireProtectionConfirmation(rootOptions),
+			destroyEvents(),
+		}}))
+	cmd.AddCommand(infraParamsCmd(rootOptions))
 	return cmd
 }
@@ -2,18 +2,24 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/ci"
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/eventlog"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
-	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -21,9 +27,13 @@ import (
 )
 
 type infraCreateFlags struct {
-	noProgress   bool
-	outputFormat *string // pointer to allow delay-initialization when used in "azd up"
-	global       *internal.GlobalCommandOptions
+	noProgress     bool
+	attach         bool
+	noStateRefresh bool
+	parameters     []string
+	parametersFile string
+	outputFormat   *string // pointer to allow delay-initialization when used in "azd up"
+	global         *internal.GlobalCommandOptions
 }
 
 func (i *infraCreateFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
@@ -41,6 +51,28 @@ func (i *infraCreateFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCom
 // to the same command.
 func (i *infraCreateFlags) bindWithoutOutput(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
 	local.BoolVar(&i.noProgress, "no-progress", false, "Suppresses progress information.")
+	local.BoolVar(
+		&i.attach,
+		"attach",
+		false,
+		"Waits for the deployment of the environment in progress, started from any machine, instead of deploying.")
+	local.BoolVar(
+		&i.noStateRefresh,
+		"no-state-refresh",
+		false,
+		"Skips checking the login, the resource locks and the deployments of the subscription in progress when the "+
+			"environment is initialized, for faster iterations.")
+	local.StringArrayVar(
+		&i.parameters,
+		"parameter",
+		nil,
+		"Sets the value of a deployment parameter as <name>=<value>, overriding the parameters file. "+
+			"Can be repeated.")
+	local.StringVar(
+		&i.parametersFile,
+		"parameters-file",
+		"",
+		"A JSON file of deployment parameter values overriding the parameters file, --parameter takes precedence.")
 
 	i.global = global
 }
@@ -88,18 +120,6 @@ func newInfraCreateAction(
 }
 
 func (i *infraCreateAction) Run(ctx context.Context) error {
-	if err := ensureProject(i.azdCtx.ProjectPath()); err != nil {
-		return err
-	}
-
-	if err := tools.EnsureInstalled(ctx, i.azCli); err != nil {
-		return err
-	}
-
-	if err := ensureLoggedIn(ctx); err != nil {
-		return fmt.Errorf("failed to ensure login: %w", err)
-	}
-
 	env, ctx, err := loadOrInitEnvironment(ctx, &i.flags.global.EnvironmentName, i.azdCtx, i.console)
 	if err != nil {
 		return fmt.Errorf("loading environment: %w", err)
@@ -114,42 +134,41 @@ func (i *infraCreateAction) Run(ctx context.Context) error {
 		return err
 	}
 
-	infraManager, err := provisioning.NewManager(ctx, env, prj.Path, prj.Infra, !i.flags.global.NoPrompt)
-	if err != nil {
-		return fmt.Errorf("creating provisioning manager: %w", err)
+	// The budget is set after provisioning, its options are validated before
+	if prj.Budget != nil {
+		if err := prj.Budget.Validate(); err != nil {
+			return err
+		}
 	}
 
-	deploymentPlan, err := infraManager.Plan(ctx)
+	prj.Infra.Parameters, err = provisioning.NewParameterOverrides(i.flags.parameters, i.flags.parametersFile)
 	if err != nil {
-		return fmt.Errorf("planning deployment: %w", err)
+		return err
 	}
 
-	provisioningScope := infra.NewSubscriptionScope(ctx, env.GetLocation(), env.GetSubscriptionId(), env.GetEnvName())
-	deployResult, err := infraManager.Deploy(ctx, deploymentPlan, provisioningScope)
+	infraManager, err := provisioning.NewManager(ctx, env, prj.Path, prj.Infra, !i.flags.global.NoPrompt)
 	if err != nil {
-		return fmt.Errorf("deploying infrastructure: %w", err)
+		return fmt.Errorf("creating provisioning manager: %w", err)
 	}
 
+	deploymentPlan, err := infraManager.Plan(ctx)
 	if err != nil {
-		if i.formatter.Kind() == output.JsonFormat {
-			stateResult, err := infraManager.State(ctx, provisioningScope)
-			if err != nil {
-				return fmt.Errorf(
-					"deployment failed and the deployment result is unavailable: %w",
-					multierr.Combine(err, err),
-				)
-			}
+		return fmt.Errorf("planning deployment: %w", err)
+	}
 
-			if err := i.formatter.Format(
-				provisioning.NewEnvRefreshResultFromState(stateResult.State), i.writer, nil); err != nil {
-				return fmt.Errorf(
-					"deployment failed and the deployment result could not be displayed: %w",
-					multierr.Combine(err, err),
-				)
-			}
+	var deployResult *provisioning.DeployResult
+	var provisioningScope infra.Scope
+	if i.flags.attach {
+		deployResult, provisioningScope, err = attachToDeploymentInProgress(
+			ctx, i.console, infraManager, prj.Infra.Provider, deploymentPlan, env)
+		if err != nil {
+			return err
+		}
+	} else {
+		deployResult, provisioningScope, err = i.deploy(ctx, infraManager, prj.Infra.Provider, deploymentPlan, env)
+		if err != nil {
+			return err
 		}
-
-		return fmt.Errorf("deployment failed: %w", err)
 	}
 
 	for _, svc := range prj.Services {
@@ -160,7 +179,68 @@ func (i *infraCreateAction) Run(ctx context.Context) error {
 		}
 	}
 
+	pushed, err := project.PushAppConfiguration(ctx, i.azCli, prj.AppConfiguration, env)
+	if err != nil {
+		return fmt.Errorf("pushing environment values to app configuration: %w", err)
+	}
+
+	budgetName, err := i.setBudget(ctx, prj, env)
+	if err != nil {
+		return fmt.Errorf("setting the budget of the environment: %w", err)
+	}
+
+	developerRoles, err := i.assignDeveloperRoles(ctx, prj, env)
+	if err != nil {
+		return fmt.Errorf("assigning data plane roles to the signed-in developer: %w", err)
+	}
+
+	// The resource groups created by the provisioning expire with the environment
+	if _, ok := env.GetExpiresOn(); ok {
+		if _, err := infra.NewAzureResourceManager(ctx).TagEnvironmentExpiry(ctx, env); err != nil {
+			return fmt.Errorf("tagging the resource groups with the expiry of the environment: %w", err)
+		}
+	}
+
+	timings := resourceTimings(ctx, prj.Infra.Provider, provisioningScope)
+	if len(timings) > 0 {
+		resourceIds := make([]string, 0, len(timings))
+		for _, timing := range timings {
+			resourceIds = append(resourceIds, timing.Id)
+		}
+
+		eventlog.FromContext(ctx).Write(eventlog.Event{
+			Type:        eventlog.ResourcesEvent,
+			Name:        "provision",
+			ResourceIds: resourceIds,
+		})
+	}
+
+	if ci.SummarySupported() {
+		publishCiSummary(i.console, i.formatter, i.writer, provisionSummary(ctx, env, timings))
+	}
+
 	if i.formatter.Kind() != output.JsonFormat {
+		// The time taken by each resource is progress detail, hidden in quiet mode
+		if len(timings) > 0 && i.console.Verbosity() >= input.VerbosityNormal {
+			i.displayFinalMessage(ctx, i.console, resourceTimingsMessage(timings))
+		}
+
+		if pushed > 0 {
+			i.displayFinalMessage(ctx, i.console, fmt.Sprintf(
+				"Pushed %d environment values to App Configuration with label %s.",
+				pushed, output.WithHighLightFormat(env.GetEnvName())))
+		}
+
+		if budgetName != "" {
+			i.displayFinalMessage(ctx, i.console, budgetMessage(budgetName, prj.Budget))
+		}
+
+		for _, assignment := range developerRoles {
+			i.displayFinalMessage(ctx, i.console, fmt.Sprintf(
+				"Assigned your account the %s role on %s.",
+				assignment.RoleName, output.WithHighLightFormat(assignment.ResourceName)))
+		}
+
 		resourceGroupName, err := project.GetResourceGroupName(ctx, prj, env)
 		if err == nil { // Presentation only -- skip print if we failed to resolve the resource group
 			i.displayResourceGroupCreatedMessage(ctx, i.console, env.GetSubscriptionId(), resourceGroupName)
@@ -176,8 +256,12 @@ func (i *infraCreateAction) Run(ctx context.Context) error {
 			)
 		}
 
-		if err := i.formatter.Format(
-			provisioning.NewEnvRefreshResultFromState(stateResult.State), i.writer, nil); err != nil {
+		result := provisioning.NewEnvRefreshResultFromState(stateResult.State)
+		if len(timings) > 0 {
+			result.Timing = provisioning.NewProvisionTimingFromResourceTimings(timings)
+		}
+
+		if err := i.formatter.Format(result, i.writer, nil); err != nil {
 			return fmt.Errorf(
 				"deployment succeeded but the deployment result could not be displayed: %w",
 				multierr.Combine(err, err),
@@ -188,18 +272,395 @@ func (i *infraCreateAction) Run(ctx context.Context) error {
 	return nil
 }
 
+// deploy confirms the deployment of the plan when it is destructive or blocked by resource locks, then deploys it
+func (i *infraCreateAction) deploy(
+	ctx context.Context,
+	infraManager *provisioning.Manager,
+	provider provisioning.ProviderKind,
+	deploymentPlan *provisioning.DeploymentPlan,
+	env *environment.Environment,
+) (*provisioning.DeployResult, infra.Scope, error) {
+	// Provisioning is destructive when the plan deletes resources
+	if len(deploymentPlan.DeletedResources) > 0 {
+		operation := fmt.Sprintf("provisioning, which deletes %d resources", len(deploymentPlan.DeletedResources))
+		if err := confirmProtectedEnvironment(ctx, i.console, i.flags.global.NoPrompt, env, operation); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Read-only locks reject the deployment, delete locks reject the deletions of the plan. Without state refresh, the
+	// locks aren't listed, a deployment blocked by a lock fails instead.
+	if !i.flags.noStateRefresh {
+		unlocked, err := resolveResourceLocks(
+			ctx, i.console, i.azCli, i.flags.global.NoPrompt, env, len(deploymentPlan.DeletedResources) > 0, "provisioning")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !unlocked {
+			return nil, nil, errors.New("provisioning is blocked by resource locks, remove them and run the command again")
+		}
+	}
+
+	deployResult, provisioningScope, err := deployOrAttach(
+		ctx, i.console, i.flags.global.NoPrompt, i.flags.noStateRefresh, infraManager, provider, deploymentPlan, env)
+	if err != nil {
+		return nil, nil, fmt.Errorf("deploying infrastructure: %w", err)
+	}
+
+	return deployResult, provisioningScope, nil
+}
+
+// setBudget creates or updates the budget of the environment, when configured, on the resource groups of the environment
+func (i *infraCreateAction) setBudget(
+	ctx context.Context,
+	prj *project.ProjectConfig,
+	env *environment.Environment,
+) (string, error) {
+	if prj.Budget == nil {
+		return "", nil
+	}
+
+	resourceGroups, err := infra.NewAzureResourceManager(ctx).GetEnvironmentResourceGroups(ctx, env)
+	if err != nil {
+		return "", err
+	}
+
+	return project.SetBudget(ctx, i.azCli, prj.Budget, env, resourceGroups)
+}
+
+// assignDeveloperRoles assigns the signed-in developer the data plane roles of the resources of the environment, when
+// enabled in azure.yaml
+func (i *infraCreateAction) assignDeveloperRoles(
+	ctx context.Context,
+	prj *project.ProjectConfig,
+	env *environment.Environment,
+) ([]project.DeveloperRoleAssignment, error) {
+	if !prj.AssignDeveloperRoles {
+		return nil, nil
+	}
+
+	resourceGroups, err := infra.NewAzureResourceManager(ctx).GetEnvironmentResourceGroups(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+
+	return project.AssignDeveloperRoles(ctx, i.azCli, env, resourceGroups)
+}
+
+// budgetMessage describes the budget set on the resource groups of the environment and its alerts
+func budgetMessage(name string, options *project.BudgetOptions) string {
+	thresholds := make([]string, 0, len(options.ThresholdPercentages()))
+	for _, threshold := range options.ThresholdPercentages() {
+		thresholds = append(thresholds, fmt.Sprintf("%g%%", threshold))
+	}
+
+	return fmt.Sprintf(
+		"Set the budget %s of %g on the resource groups of the environment, alerting at %s of the amount.",
+		output.WithHighLightFormat(name), options.Amount, strings.Join(thresholds, ", "))
+}
+
+// provisionSummary describes the provisioned environment in the summary of the CI run: its subscription, location and
+// resource groups, and the resources deployed when their timings are known
+func provisionSummary(ctx context.Context, env *environment.Environment, timings []infra.ResourceTiming) ci.Summary {
+	summary := ci.Summary{
+		Title:       fmt.Sprintf("Provisioned the environment %s", env.GetEnvName()),
+		Environment: env.GetEnvName(),
+		Details: []ci.Detail{
+			{Name: "Subscription", Value: env.GetSubscriptionId()},
+			{Name: "Location", Value: env.GetLocation()},
+		},
+	}
+
+	resourceGroups, err := infra.NewAzureResourceManager(ctx).GetEnvironmentResourceGroups(ctx, env)
+	if err != nil {
+		log.Printf("failed getting the resource groups of the environment, skipping them in the summary: %v", err)
+	} else if len(resourceGroups) > 0 {
+		summary.Details = append(summary.Details, ci.Detail{
+			Name: "Resource groups", Value: strings.Join(resourceGroups, ", "),
+		})
+	}
+
+	if len(timings) > 0 {
+		summary.Details = append(summary.Details, ci.Detail{
+			Name: "Resources",
+			Value: fmt.Sprintf(
+				"%d deployed in %s", len(timings), infra.TotalDuration(timings).Round(time.Second).String()),
+		})
+	}
+
+	return summary
+}
+
+// attachToDeploymentInProgress waits for the deployment of the environment in progress, which may have been started by
+// a pipeline or from another machine, and makes it the active deployment of the environment.
+func attachToDeploymentInProgress(
+	ctx context.Context,
+	console input.Console,
+	infraManager *provisioning.Manager,
+	provider provisioning.ProviderKind,
+	plan *provisioning.DeploymentPlan,
+	env *environment.Environment,
+) (*provisioning.DeployResult, infra.Scope, error) {
+	// Only deployments to Azure Resource Manager are named and tracked
+	if provider == provisioning.Terraform || provider == provisioning.Test || provider == provisioning.Passthrough {
+		return nil, nil, fmt.Errorf("--attach is not supported by the %s provisioning provider", provider)
+	}
+
+	deploymentName, err := infraManager.FindDeploymentInProgress(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if deploymentName == "" {
+		return nil, nil, fmt.Errorf("no deployment of the environment %s is in progress", env.GetEnvName())
+	}
+
+	console.Message(ctx, fmt.Sprintf(
+		"Attaching to the deployment %s of the environment, waiting for it to complete.",
+		output.WithHighLightFormat(deploymentName),
+	))
+
+	env.SetDeploymentName(deploymentName)
+	if err := env.Save(); err != nil {
+		return nil, nil, fmt.Errorf("saving environment: %w", err)
+	}
+
+	scope := infra.NewSubscriptionScope(ctx, env.GetLocation(), env.GetSubscriptionId(), deploymentName)
+	deployResult, err := infraManager.Attach(ctx, plan, scope)
+	if err != nil {
+		return nil, nil, fmt.Errorf("deployment failed: %w", err)
+	}
+
+	return deployResult, scope, nil
+}
+
 func (ica *infraCreateAction) displayResourceGroupCreatedMessage(
 	ctx context.Context,
 	console input.Console,
 	subscriptionId string,
 	resourceGroup string,
 ) {
-	resourceGroupCreatedMessage := resourceGroupCreatedMessage(ctx, subscriptionId, resourceGroup)
+	ica.displayFinalMessage(ctx, console, resourceGroupCreatedMessage(ctx, subscriptionId, resourceGroup))
+}
+
+// displayFinalMessage displays a message once provisioning completes, or redirects it to the final output
+func (ica *infraCreateAction) displayFinalMessage(ctx context.Context, console input.Console, message string) {
 	if ica.finalOutputRedirect != nil {
-		*ica.finalOutputRedirect = append(*ica.finalOutputRedirect, resourceGroupCreatedMessage)
+		*ica.finalOutputRedirect = append(*ica.finalOutputRedirect, message)
 	} else {
-		console.Message(ctx, resourceGroupCreatedMessage)
+		console.Message(ctx, message)
+	}
+}
+
+// The choices offered when a deployment of the environment with different parameters is in progress
+const (
+	deploymentInProgressWaitChoice   = "Wait for it to complete, then provision the environment"
+	deploymentInProgressAttachChoice = "Attach to it, without provisioning the local changes"
+	deploymentInProgressCancelChoice = "Cancel"
+)
+
+// deployOrAttach starts a new deployment of the environment, named and tagged after the environment, unless a
+// deployment of the environment is in progress, started by this machine, another machine or a pipeline. A deployment in
+// progress of the same content is attached to instead of starting a duplicate, while for a deployment in progress of a
+// different content the user chooses to wait for it to complete before provisioning, to attach to it, or to cancel,
+// rather than racing it with a conflicting incremental deployment. Without state refresh, only the active deployment of
+// the environment is checked, not the deployments of the subscription. Returns the scope of the deployment.
+func deployOrAttach(
+	ctx context.Context,
+	console input.Console,
+	noPrompt bool,
+	noStateRefresh bool,
+	infraManager *provisioning.Manager,
+	provider provisioning.ProviderKind,
+	plan *provisioning.DeploymentPlan,
+	env *environment.Environment,
+) (*provisioning.DeployResult, infra.Scope, error) {
+	// Only deployments to Azure Resource Manager are named and tracked
+	if provider == provisioning.Terraform || provider == provisioning.Test || provider == provisioning.Passthrough {
+		scope := infra.NewSubscriptionScope(ctx, env.GetLocation(), env.GetSubscriptionId(), env.GetEnvName())
+		deployResult, err := infraManager.Deploy(ctx, plan, scope)
+		return deployResult, scope, err
+	}
+
+	contentHash := provisioning.DeploymentContentHash(plan)
+
+	inProgressName, err := findDeploymentInProgress(ctx, infraManager, env, noStateRefresh)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if inProgressName != "" {
+		inProgressScope := infra.NewSubscriptionScope(ctx, env.GetLocation(), env.GetSubscriptionId(), inProgressName)
+		inProgressHash, _ := provisioning.ParseDeploymentName(env.GetEnvName(), inProgressName)
+
+		choice := deploymentInProgressAttachChoice
+		if inProgressHash != contentHash {
+			choice, err = promptDeploymentInProgress(ctx, console, noPrompt, inProgressName)
+			if err != nil {
+				return nil, nil, err
+			}
+		} else {
+			console.Message(ctx, fmt.Sprintf(
+				"The deployment %s of the environment is already in progress, waiting for it to complete.",
+				output.WithHighLightFormat(inProgressName),
+			))
+		}
+
+		switch choice {
+		case deploymentInProgressAttachChoice:
+			deployResult, err := infraManager.Attach(ctx, plan, inProgressScope)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			return deployResult, inProgressScope, nil
+		case deploymentInProgressWaitChoice:
+			// The environment is provisioned whether the deployment in progress succeeds or not
+			if _, err := infraManager.Attach(ctx, plan, inProgressScope); err != nil {
+				if ctx.Err() != nil {
+					return nil, nil, err
+				}
+
+				console.Message(ctx, output.WithWarningFormat(
+					"The deployment %s didn't succeed, provisioning the environment: %v", inProgressName, err))
+			}
+		default:
+			return nil, nil, fmt.Errorf("provisioning canceled, the deployment %s of the environment is in progress", inProgressName)
+		}
+	}
+
+	deploymentName := provisioning.NewDeploymentName(env.GetEnvName(), contentHash, time.Now())
+	env.SetDeploymentName(deploymentName)
+	if err := env.Save(); err != nil {
+		return nil, nil, fmt.Errorf("saving environment: %w", err)
+	}
+
+	scope := infra.NewTaggedSubscriptionScope(
+		ctx, env.GetLocation(), env.GetSubscriptionId(), deploymentName,
+		map[string]string{provisioning.DeploymentEnvNameTag: env.GetEnvName()},
+	)
+	deployResult, err := infraManager.Deploy(ctx, plan, scope)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return deployResult, scope, nil
+}
+
+// findDeploymentInProgress returns the name of the deployment of the environment in progress, or an empty string when
+// none is. The active deployment of the environment is checked first, it may have been started by another azd process
+// since the environment was loaded, then, unless activeOnly is set, the deployments of the subscription tagged or named
+// after the environment.
+func findDeploymentInProgress(
+	ctx context.Context,
+	infraManager *provisioning.Manager,
+	env *environment.Environment,
+	activeOnly bool,
+) (string, error) {
+	activeName := env.GetDeploymentName()
+	if latest, err := environment.FromFile(env.File); err == nil {
+		activeName = latest.GetDeploymentName()
+	}
+
+	if activeName != "" {
+		activeScope := infra.NewSubscriptionScope(ctx, env.GetLocation(), env.GetSubscriptionId(), activeName)
+		active, err := activeScope.GetDeployment(ctx)
+		if err != nil && !errors.Is(err, azcli.ErrDeploymentNotFound) {
+			return "", fmt.Errorf("getting the last deployment of the environment: %w", err)
+		}
+
+		if err == nil && provisioning.IsDeploymentInProgress(active) {
+			return activeName, nil
+		}
+	}
+
+	if activeOnly {
+		return "", nil
+	}
+
+	return infraManager.FindDeploymentInProgress(ctx)
+}
+
+// promptDeploymentInProgress asks whether to wait for the deployment in progress with different parameters, to attach to
+// it or to cancel. Fails without prompting when prompts are disabled.
+func promptDeploymentInProgress(
+	ctx context.Context,
+	console input.Console,
+	noPrompt bool,
+	deploymentName string,
+) (string, error) {
+	if noPrompt {
+		return "", fmt.Errorf(
+			"the deployment %s of the environment is in progress with different parameters, wait for it to "+
+				"complete and run the command again",
+			deploymentName,
+		)
+	}
+
+	choices := []string{
+		deploymentInProgressWaitChoice,
+		deploymentInProgressAttachChoice,
+		deploymentInProgressCancelChoice,
+	}
+	choice, err := console.Select(ctx, input.ConsoleOptions{
+		Message: fmt.Sprintf(
+			"The deployment %s of the environment is in progress with different parameters:", deploymentName),
+		Options:      choices,
+		DefaultValue: deploymentInProgressWaitChoice,
+	})
+	if err != nil {
+		return "", fmt.Errorf("prompting for the deployment in progress: %w", err)
+	}
+
+	return choices[choice], nil
+}
+
+// resourceTimings returns the time taken to provision each resource, from the deployment operations.
+// Reporting the timings is best-effort, failing to get them doesn't fail the provisioning.
+func resourceTimings(
+	ctx context.Context,
+	provider provisioning.ProviderKind,
+	scope infra.Scope,
+) []infra.ResourceTiming {
+	// Only deployments to Azure Resource Manager have operations
+	if provider == provisioning.Terraform || provider == provisioning.Test || provider == provisioning.Passthrough {
+		return nil
+	}
+
+	operations, err := infra.NewAzureResourceManager(ctx).GetDeploymentResourceOperations(ctx, scope)
+	if err != nil {
+		log.Printf("failed getting deployment operations, skipping timing report: %v", err)
+		return nil
+	}
+
+	return infra.NewResourceTimings(operations)
+}
+
+// resourceTimingsMessage formats the time taken to provision each resource, flagging the slowest resources
+func resourceTimingsMessage(timings []infra.ResourceTiming) string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf(
+		"Provisioning took %s:\n",
+		output.WithHighLightFormat(infra.TotalDuration(timings).Round(time.Second).String()),
+	))
+
+	for _, timing := range timings {
+		resourceType := infra.GetResourceTypeDisplayName(timing.Type)
+		if resourceType == "" {
+			resourceType = string(timing.Type)
+		}
+
+		builder.WriteString(fmt.Sprintf(
+			" - %8s  %s: %s", timing.Duration.Round(time.Second), resourceType, timing.Name))
+		if timing.Slowest {
+			builder.WriteString(output.WithWarningFormat(" (slowest)"))
+		}
+		builder.WriteString("\n")
 	}
+
+	return builder.String()
 }
 
 func resourceGroupCreatedMessage(ctx context.Context, subscriptionId string, resourceGroup string) string {
@@ -0,0 +1,41 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_resourceTimingsMessage(t *testing.T) {
+	start := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	timings := []infra.ResourceTiming{
+		{
+			Name:      "web",
+			Type:      infra.AzureResourceTypeWebSite,
+			StartTime: start,
+			Duration:  150 * time.Second,
+			Slowest:   true,
+		},
+		{
+			Name:      "assignment",
+			Type:      "Microsoft.Authorization/roleAssignments",
+			StartTime: start,
+			Duration:  2 * time.Second,
+		},
+	}
+
+	lines := strings.Split(strings.TrimSpace(resourceTimingsMessage(timings)), "\n")
+	require.Len(t, lines, 3)
+	require.Contains(t, lines[0], "2m30s")
+	require.Contains(t, lines[1], "Web App: web")
+	require.Contains(t, lines[1], "(slowest)")
+	// Resource types without a display name are shown as is
+	require.Contains(t, lines[2], "Microsoft.Authorization/roleAssignments: assignment")
+	require.NotContains(t, lines[2], "(slowest)")
+}
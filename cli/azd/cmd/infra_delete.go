@@ -3,13 +3,18 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
-	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -18,6 +23,7 @@ import (
 type infraDeleteFlags struct {
 	forceDelete bool
 	purgeDelete bool
+	dryRun      bool
 	global      *internal.GlobalCommandOptions
 }
 
@@ -30,6 +36,7 @@ func (i *infraDeleteFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCom
 		//nolint:lll
 		"Does not require confirmation before it permanently deletes resources that are soft-deleted by default (for example, key vaults).",
 	)
+	local.BoolVar(&i.dryRun, "dry-run", false, "Lists the resources to delete, without deleting them.")
 	i.global = global
 }
 
@@ -67,18 +74,6 @@ func newInfraDeleteAction(
 }
 
 func (a *infraDeleteAction) Run(ctx context.Context) error {
-	if err := ensureProject(a.azdCtx.ProjectPath()); err != nil {
-		return err
-	}
-
-	if err := tools.EnsureInstalled(ctx, a.azCli); err != nil {
-		return err
-	}
-
-	if err := ensureLoggedIn(ctx); err != nil {
-		return fmt.Errorf("failed to ensure login: %w", err)
-	}
-
 	env, ctx, err := loadOrInitEnvironment(ctx, &a.flags.global.EnvironmentName, a.azdCtx, a.console)
 	if err != nil {
 		return fmt.Errorf("loading environment: %w", err)
@@ -89,6 +84,10 @@ func (a *infraDeleteAction) Run(ctx context.Context) error {
 		return fmt.Errorf("loading project: %w", err)
 	}
 
+	if a.flags.dryRun {
+		return a.listResources(ctx, env)
+	}
+
 	infraManager, err := provisioning.NewManager(ctx, env, prj.Path, prj.Infra, !a.flags.global.NoPrompt)
 	if err != nil {
 		return fmt.Errorf("creating provisioning manager: %w", err)
@@ -99,16 +98,37 @@ func (a *infraDeleteAction) Run(ctx context.Context) error {
 		return fmt.Errorf("planning destroy: %w", err)
 	}
 
+	unlocked, err := resolveResourceLocks(ctx, a.console, a.azCli, a.flags.global.NoPrompt, env, true, "the deletion")
+	if err != nil {
+		return err
+	}
+
+	if !unlocked {
+		a.console.Message(ctx, "The locked resource groups will be skipped.")
+	}
+
 	destroyOptions := provisioning.NewDestroyOptions(a.flags.forceDelete, a.flags.purgeDelete)
 	destroyResult, err := infraManager.Destroy(ctx, &deploymentPlan.Deployment, destroyOptions)
 	if err != nil {
 		return fmt.Errorf("destroying infrastructure: %w", err)
 	}
 
-	// Remove any outputs from the template from the environment since destroying the infrastructure
-	// invalidated them all.
-	for outputName := range destroyResult.Outputs {
-		delete(env.Values, outputName)
+	if len(destroyResult.SkippedResourceGroups) > 0 {
+		warning := fmt.Sprintf(
+			"WARNING: The following resource groups have a delete lock and were not deleted: %s\n"+
+				"Remove the locks and run the command again to delete them.",
+			strings.Join(destroyResult.SkippedResourceGroups, ", "),
+		)
+		a.console.Message(ctx, output.WithWarningFormat(warning))
+		if annotation := output.Annotation(output.AnnotationWarning, warning); annotation != "" {
+			a.console.Message(ctx, annotation)
+		}
+	} else {
+		// Remove any outputs from the template from the environment since destroying the infrastructure
+		// invalidated them all.
+		for outputName := range destroyResult.Outputs {
+			delete(env.Values, outputName)
+		}
 	}
 
 	if err := env.Save(); err != nil {
@@ -117,3 +137,54 @@ func (a *infraDeleteAction) Run(ctx context.Context) error {
 
 	return nil
 }
+
+// listResources prints the resources of the environment, grouped by resource group, without deleting them
+func (a *infraDeleteAction) listResources(ctx context.Context, env *environment.Environment) error {
+	resourceManager := infra.NewAzureResourceManager(ctx)
+	groupedResources, err := resourceManager.GetEnvironmentResources(ctx, env)
+	if err != nil {
+		return fmt.Errorf("listing resources to delete: %w", err)
+	}
+
+	if len(groupedResources) == 0 {
+		a.console.Message(ctx, fmt.Sprintf(
+			"No resource groups found for environment %s, nothing would be deleted.",
+			output.WithHighLightFormat(env.GetEnvName())))
+		return nil
+	}
+
+	resourceGroups := make([]string, 0, len(groupedResources))
+	resourceCount := 0
+	for resourceGroup, resources := range groupedResources {
+		resourceGroups = append(resourceGroups, resourceGroup)
+		resourceCount += len(resources)
+	}
+	sort.Strings(resourceGroups)
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "The following %d resource groups and %d resources would be deleted:\n",
+		len(resourceGroups), resourceCount)
+
+	for _, resourceGroup := range resourceGroups {
+		resources := groupedResources[resourceGroup]
+		sort.Slice(resources, func(i, j int) bool {
+			return resources[i].Name < resources[j].Name
+		})
+
+		fmt.Fprintf(&message, "\nResource group %s (%d resources)\n", output.WithHighLightFormat(resourceGroup), len(resources))
+
+		table := tabwriter.NewWriter(&message, 0, 0, 2, ' ', 0)
+		for _, resource := range resources {
+			fmt.Fprintf(table, "  %s\t%s\t%s\n", resource.Name, resource.Type, resource.Location)
+		}
+
+		if err := table.Flush(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(&message, "\nNo resources were deleted, run the command without --dry-run to delete them.")
+	a.console.Message(ctx, message.String())
+
+	return nil
+}
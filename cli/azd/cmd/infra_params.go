@@ -0,0 +1,152 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/middleware"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning/bicep"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/spf13/cobra"
+)
+
+func infraParamsCmd(rootOptions *internal.GlobalCommandOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "params",
+		Short: "Manage the parameters files of the infrastructure.",
+	}
+	cmd.Flags().BoolP("help", "h", false, fmt.Sprintf("Gets help for %s.", cmd.Name()))
+	cmd.AddCommand(BuildCmd(rootOptions, infraParamsInitCmdDesign, initInfraParamsInitAction,
+		&buildOptions{middleware: []middleware.Middleware{requireProject(), requireAzCli()}}))
+	return cmd
+}
+
+func infraParamsInitCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *struct{}) {
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Generate the parameters file of the infrastructure from the parameters of its template.",
+		Long: `Generate the parameters file of the infrastructure from the parameters of its template.
+
+azd compiles the Bicep template of the infrastructure and prompts for the value of each of its parameters: the
+environment variable passed to the parameter, i.e. ${AZURE_LOCATION}, a password generated once and stored in the key
+vault of the environment for a secure parameter, or the default value of the template. With --no-prompt, the first
+value is used for each parameter.
+
+The parameters file, i.e. infra/main.parameters.json, is replaced once confirmed.`,
+	}
+	cmd.Args = cobra.NoArgs
+	return cmd, &struct{}{}
+}
+
+type infraParamsInitAction struct {
+	azdCtx  *azdcontext.AzdContext
+	console input.Console
+}
+
+func newInfraParamsInitAction(azdCtx *azdcontext.AzdContext, console input.Console) *infraParamsInitAction {
+	return &infraParamsInitAction{
+		azdCtx:  azdCtx,
+		console: console,
+	}
+}
+
+func (a *infraParamsInitAction) Run(ctx context.Context) error {
+	// The parameters of the template don't depend on the environment
+	prj, err := project.LoadProjectConfig(a.azdCtx.ProjectPath(), environment.Ephemeral())
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+
+	if prj.Infra.Provider != "" && prj.Infra.Provider != provisioning.Bicep {
+		return fmt.Errorf("generating the parameters file is only supported for Bicep, not %s", prj.Infra.Provider)
+	}
+
+	provider := bicep.NewBicepProvider(ctx, environment.Ephemeral(), a.azdCtx.ProjectDirectory(), prj.Infra)
+	if _, err := os.Stat(provider.BicepParamFilePath()); err == nil {
+		return fmt.Errorf(
+			"the parameters of the template are set by %s, delete it to generate a parameters file instead",
+			filepath.Base(provider.BicepParamFilePath()))
+	}
+
+	parametersFilePath := provider.ParametersFilePath()
+	relativePath, err := filepath.Rel(a.azdCtx.ProjectDirectory(), parametersFilePath)
+	if err != nil {
+		relativePath = parametersFilePath
+	}
+
+	if _, err := os.Stat(parametersFilePath); err == nil {
+		replace, err := a.console.Confirm(ctx, input.ConsoleOptions{
+			Message:      fmt.Sprintf("Would you like to replace %s?", relativePath),
+			DefaultValue: false,
+		})
+		if err != nil {
+			return fmt.Errorf("prompting to replace the parameters file: %w", err)
+		}
+
+		if !replace {
+			return errors.New("confirmation declined")
+		}
+	}
+
+	a.console.Message(ctx, "Compiling the Bicep template.")
+	parameters, err := provider.TemplateParameters(ctx)
+	if err != nil {
+		return fmt.Errorf("reading the parameters of the template: %w", err)
+	}
+
+	names := make([]string, 0, len(parameters))
+	for name := range parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := map[string]string{}
+	for _, name := range names {
+		options := bicep.ParameterValueOptions(name, parameters[name])
+		descriptions := make([]string, len(options))
+		for index, option := range options {
+			descriptions[index] = option.Description
+		}
+
+		selected, err := a.console.Select(ctx, input.ConsoleOptions{
+			Message:      fmt.Sprintf("Select the value of the '%s' parameter (%s):", name, parameters[name].Type),
+			Options:      descriptions,
+			DefaultValue: descriptions[0],
+		})
+		if err != nil {
+			return fmt.Errorf("prompting for the value of parameter '%s': %w", name, err)
+		}
+
+		if value := options[selected].Value; value != nil {
+			values[name] = *value
+		}
+	}
+
+	content, err := bicep.NewParametersFile(values)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(parametersFilePath, content, osutil.PermissionFile); err != nil {
+		return fmt.Errorf("writing the parameters file: %w", err)
+	}
+
+	a.console.Message(ctx, fmt.Sprintf(
+		"\nWrote the %d parameters of the template to %s. The environment variables it reads are set with %s.",
+		len(values), relativePath, output.WithHighLightFormat("azd env set <name> <value>")))
+	return nil
+}
@@ -4,7 +4,6 @@
 package cmd
 
 import (
-	"bufio"
 	"context"
 	"errors"
 	"fmt"
@@ -13,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
@@ -23,7 +23,6 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
-	"github.com/azure/azure-dev/cli/azd/pkg/spin"
 	"github.com/azure/azure-dev/cli/azd/pkg/templates"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
@@ -48,12 +47,16 @@ When a template is provided, the sample code is cloned to the current directory.
 	f := &initFlags{}
 	f.Bind(cmd.Flags(), rootOptions)
 
+	registerFlagCompletion(cmd, "template", completeTemplateNames)
+	registerFlagCompletion(cmd, "subscription", completeSubscriptionIds)
+
 	return cmd, f
 }
 
 type initFlags struct {
 	template       templates.Template
 	templateBranch string
+	refresh        bool
 	subscription   string
 	location       string
 	rootOptions    *internal.GlobalCommandOptions
@@ -77,6 +80,13 @@ func (i *initFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOpt
 	)
 	local.StringVarP(&i.location, "location", "l", "", "Azure location for the new environment")
 
+	local.BoolVar(
+		&i.refresh,
+		"refresh",
+		false,
+		"Downloads the template again instead of using the copy cached by a previous init.",
+	)
+
 	i.rootOptions = global
 }
 
@@ -173,24 +183,17 @@ func (i *initAction) Run(ctx context.Context) error {
 			}
 		}
 
-		templateStagingDir, err := os.MkdirTemp("", "az-dev-template")
+		cachedTemplate, err := i.fetchTemplate(ctx, templateUrl)
 		if err != nil {
-			return fmt.Errorf("creating temp folder: %w", err)
+			return fmt.Errorf("fetching template: %w", err)
 		}
+		templateStagingDir := cachedTemplate.Path
 
-		// Attempt to remove the temporary directory we cloned the template into, but don't fail the
-		// overall operation if we can't.
-		defer func() {
-			_ = os.RemoveAll(templateStagingDir)
-		}()
-
-		spinner := spin.NewSpinner(i.console.Handles().Stdout, "Downloading template")
-		err = spinner.Run(func() error {
-			return i.gitCli.FetchCode(ctx, templateUrl, i.flags.templateBranch, templateStagingDir)
-		})
-
-		if err != nil {
-			return fmt.Errorf("fetching template: %w", err)
+		// The commit of the template is pinned in azure.yaml, for `azd template update` to diff the upstream changes
+		templateSource := project.TemplateSource{
+			Repository: templateUrl,
+			Branch:     i.flags.templateBranch,
+			Commit:     cachedTemplate.Commit,
 		}
 
 		log.Printf(
@@ -253,6 +256,14 @@ func (i *initAction) Run(ctx context.Context) error {
 		if err := copy.Copy(templateStagingDir, i.azdCtx.ProjectDirectory()); err != nil {
 			return fmt.Errorf("copying template contents: %w", err)
 		}
+
+		if _, err := os.Stat(i.azdCtx.ProjectPath()); err == nil {
+			if err := project.SaveTemplateSource(i.azdCtx.ProjectPath(), templateSource); err != nil {
+				return fmt.Errorf("recording template source: %w", err)
+			}
+		} else {
+			log.Printf("template %s has no project file, skipping recording its source", templateUrl)
+		}
 	}
 
 	envName, err := i.azdCtx.GetDefaultEnvironmentName()
@@ -286,32 +297,16 @@ func (i *initAction) Run(ctx context.Context) error {
 		return fmt.Errorf("failed to create a directory: %w", err)
 	}
 
-	//create .gitignore or open existing .gitignore file, and contains .azure
-	gitignoreFile, err := os.OpenFile(
-		filepath.Join(i.azdCtx.ProjectDirectory(), ".gitignore"),
-		os.O_APPEND|os.O_RDWR|os.O_CREATE,
-		osutil.PermissionFile,
-	)
+	// keep the environment directory and the secret files of the project out of the repository
+	_, err = git.EnsureIgnoreEntries(i.azdCtx.ProjectDirectory(), func(entries []string) (bool, error) {
+		return i.console.Confirm(ctx, input.ConsoleOptions{
+			Message: fmt.Sprintf(
+				"Add %s to %s so they aren't committed?", strings.Join(entries, ", "), git.GitIgnoreFileName),
+			DefaultValue: true,
+		})
+	})
 	if err != nil {
-		return fmt.Errorf("fail to create or open .gitignore: %w", err)
-	}
-	defer gitignoreFile.Close()
-
-	writeGitignoreFile := true
-	//bufio scanner splits on new lines by default
-	scanner := bufio.NewScanner(gitignoreFile)
-	for scanner.Scan() {
-		if azdcontext.EnvironmentDirectoryName == scanner.Text() {
-			writeGitignoreFile = false
-		}
-	}
-
-	if writeGitignoreFile {
-		newLine := osutil.GetNewLineSeparator()
-		_, err := gitignoreFile.WriteString(newLine + azdcontext.EnvironmentDirectoryName + newLine)
-		if err != nil {
-			return fmt.Errorf("fail to write '%s' in .gitignore: %w", azdcontext.EnvironmentDirectoryName, err)
-		}
+		return fmt.Errorf("updating %s: %w", git.GitIgnoreFileName, err)
 	}
 
 	envSpec := environmentSpec{
@@ -343,3 +338,51 @@ func (i *initAction) Run(ctx context.Context) error {
 
 	return nil
 }
+
+// fetchTemplate returns the latest version of the template from the template cache, downloading it when it isn't cached
+// yet, when a newer version is available or when refreshing. The cached version is used when the template repository
+// can't be reached.
+func (i *initAction) fetchTemplate(ctx context.Context, templateUrl string) (*templates.CachedTemplate, error) {
+	cachePath, err := templates.DefaultTemplateCachePath()
+	if err != nil {
+		return nil, fmt.Errorf("getting template cache path: %w", err)
+	}
+
+	cache := templates.NewTemplateCache(cachePath)
+	cached, err := cache.Get(templateUrl, i.flags.templateBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := i.gitCli.GetRemoteCommit(ctx, templateUrl, i.flags.templateBranch)
+	if err != nil {
+		if cached == nil {
+			return nil, err
+		}
+
+		log.Printf("failed getting the latest version of the template, using the cached version: %v", err)
+		i.console.Message(ctx, output.WithWarningFormat(
+			"WARNING: Unable to reach the template repository, using the copy of the template cached on %s.",
+			cached.CachedAt.Local().Format(time.RFC1123),
+		))
+		return cached, nil
+	}
+
+	if cached != nil && cached.Commit == commit && !i.flags.refresh {
+		log.Printf("using the cached version %s of template %s", commit, templateUrl)
+		return cached, nil
+	}
+
+	spinner := input.NewSpinner(i.console, "Downloading template")
+	err = spinner.Run(func() error {
+		cached, err = cache.Add(templateUrl, i.flags.templateBranch, commit, func(target string) error {
+			return i.gitCli.FetchCommit(ctx, templateUrl, commit, target)
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cached, nil
+}
@@ -11,10 +11,11 @@ import (
 	"os"
 
 	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/events"
 	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
-	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -81,10 +82,6 @@ func newLoginAction(
 }
 
 func (la *loginAction) Run(ctx context.Context) error {
-	if err := tools.EnsureInstalled(ctx, la.azCli); err != nil {
-		return err
-	}
-
 	if !la.flags.onlyCheckStatus {
 		if err := runLogin(ctx, la.flags.useDeviceCode); err != nil {
 			return fmt.Errorf("logging in: %w", err)
@@ -118,6 +115,9 @@ func (la *loginAction) Run(ctx context.Context) error {
 
 // ensureLoggedIn checks to see if the user is currently logged in. If not, the equivalent of `az login` is run.
 func ensureLoggedIn(ctx context.Context) error {
+	ctx, span := telemetry.GetTracer().Start(ctx, events.AuthLoginCheckEventName)
+	defer span.End()
+
 	azCli := azcli.GetAzCli(ctx)
 	_, err := azCli.GetAccessToken(ctx)
 	if errors.Is(err, azcli.ErrAzCliNotLoggedIn) || errors.Is(err, azcli.ErrAzCliRefreshTokenExpired) {
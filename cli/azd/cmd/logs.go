@@ -0,0 +1,138 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type logsFlags struct {
+	level  string
+	since  time.Duration
+	global *internal.GlobalCommandOptions
+}
+
+func (l *logsFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(
+		&l.level,
+		"level",
+		"trace",
+		"The minimum level of the streamed log lines: trace, debug, info, warning or error.",
+	)
+	local.DurationVar(
+		&l.since,
+		"since",
+		0,
+		//nolint:lll
+		"Only streams the log lines logged within this duration, i.e. 30m (when unspecified, all the lines returned by the host are streamed).",
+	)
+
+	l.global = global
+}
+
+func logsCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *logsFlags) {
+	cmd := &cobra.Command{
+		Use:   "logs [<service>]",
+		Short: "Stream the logs of a deployed service.",
+		//nolint:lll
+		Long: `Stream the logs of a deployed service.
+
+The logs of the service's host (App Service, Azure Functions or Container Apps) are streamed until the command is interrupted with Ctrl+C. The service can be omitted when the ` + output.WithBackticks(azdcontext.ProjectFileName) + ` file lists a single service.
+
+Lines whose level or timestamp can't be determined, such as the lines of a stack trace, are filtered along with the line before them.
+
+Examples:
+
+	$ azd logs api
+	$ azd logs web --level warning --since 30m`,
+	}
+	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.ValidArgsFunction = completeServiceNameArg
+	flags := &logsFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return cmd, flags
+}
+
+type logsAction struct {
+	flags   logsFlags
+	args    []string
+	azdCtx  *azdcontext.AzdContext
+	console input.Console
+}
+
+func newLogsAction(
+	flags logsFlags,
+	args []string,
+	azdCtx *azdcontext.AzdContext,
+	console input.Console,
+) *logsAction {
+	return &logsAction{
+		flags:   flags,
+		args:    args,
+		azdCtx:  azdCtx,
+		console: console,
+	}
+}
+
+func (l *logsAction) Run(ctx context.Context) error {
+	level, err := project.ParseLogLevel(l.flags.level)
+	if err != nil {
+		return err
+	}
+
+	if l.flags.since < 0 {
+		return errors.New("--since must be a positive duration")
+	}
+
+	env, ctx, err := loadOrInitEnvironment(ctx, &l.flags.global.EnvironmentName, l.azdCtx, l.console)
+	if err != nil {
+		return fmt.Errorf("loading environment: %w", err)
+	}
+
+	projConfig, err := project.LoadProjectConfig(l.azdCtx.ProjectPath(), env)
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+
+	svc, err := resolveService(&ctx, projConfig, env, l.args)
+	if err != nil {
+		return err
+	}
+
+	streamer, ok := svc.Target.(project.LogStreamer)
+	if !ok {
+		return fmt.Errorf("streaming the logs of services hosted on '%s' is not supported", svc.Config.Host)
+	}
+
+	l.console.Message(ctx, fmt.Sprintf(
+		"Streaming the logs of service %s, press Ctrl+C to stop...\n", output.WithHighLightFormat(svc.Config.Name)))
+
+	// The logs are streamed until the user interrupts the command
+	streamCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	err = streamer.StreamLogs(streamCtx, project.LogStreamOptions{
+		Level: level,
+		Since: l.flags.since,
+	}, l.console.Handles().Stdout)
+	if err != nil && streamCtx.Err() == nil {
+		return fmt.Errorf("streaming logs: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,284 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/middleware"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/commands/pipeline"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/eventlog"
+	"github.com/azure/azure-dev/cli/azd/pkg/extensions"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// requireProject creates a middleware that ensures an azd project exists before the command runs
+func requireProject() middleware.Middleware {
+	return middleware.MiddlewareFunc(func(ctx context.Context, options *middleware.Options, next middleware.NextFn) error {
+		azdCtx, err := newAzdContext()
+		if err != nil {
+			return err
+		}
+
+		if err := ensureProject(azdCtx.ProjectPath()); err != nil {
+			return err
+		}
+
+		return next(ctx)
+	})
+}
+
+// requireAzCli creates a middleware that ensures the az CLI is installed before the command runs
+func requireAzCli() middleware.Middleware {
+	return middleware.NewToolsMiddleware(func(ctx context.Context) []tools.ExternalTool {
+		return []tools.ExternalTool{azcli.GetAzCli(ctx)}
+	})
+}
+
+// requireLogin creates a middleware that ensures the user is logged in before the command runs. The check is skipped
+// when the command runs with `--no-state-refresh` on an initialized environment.
+func requireLogin() middleware.Middleware {
+	return middleware.MiddlewareFunc(func(ctx context.Context, options *middleware.Options, next middleware.NextFn) error {
+		if skipsStateRefresh(ctx, options) {
+			log.Println("skipping login check, the command runs without state refresh")
+			return next(ctx)
+		}
+
+		if err := ensureLoggedIn(ctx); err != nil {
+			return fmt.Errorf("failed to ensure login: %w", err)
+		}
+
+		return next(ctx)
+	})
+}
+
+// skipsStateRefresh returns true when the command runs with `--no-state-refresh` on an environment that is initialized,
+// so that its subscription, location and account aren't checked against Azure again
+func skipsStateRefresh(ctx context.Context, options *middleware.Options) bool {
+	flag := options.Cmd.Flags().Lookup("no-state-refresh")
+	if flag == nil || flag.Value.String() != "true" {
+		return false
+	}
+
+	azdCtx, err := newAzdContext()
+	if err != nil {
+		return false
+	}
+
+	name := internal.GetCommandOptions(ctx).EnvironmentName
+	if name == "" {
+		name, _ = azdCtx.GetSelectedEnvironmentName()
+	}
+
+	if name == "" {
+		return false
+	}
+
+	env, err := environment.GetEnvironment(azdCtx, name)
+	if err != nil {
+		return false
+	}
+
+	return isEnvironmentInitialized(env)
+}
+
+// requireProtectionConfirmation creates a middleware that, when the environment the command runs on is protected,
+// requires the name of the environment to be typed before the destructive command runs
+func requireProtectionConfirmation(global *internal.GlobalCommandOptions) middleware.Middleware {
+	return middleware.MiddlewareFunc(func(ctx context.Context, options *middleware.Options, next middleware.NextFn) error {
+		// Dry runs make no changes
+		if flag := options.Cmd.Flags().Lookup("dry-run"); flag != nil && flag.Value.String() == "true" {
+			return next(ctx)
+		}
+
+		azdCtx, err := newAzdContext()
+		if err != nil {
+			return err
+		}
+
+		name := global.EnvironmentName
+		if name == "" {
+			if name, err = azdCtx.GetSelectedEnvironmentName(); err != nil {
+				return fmt.Errorf("getting default environment: %w", err)
+			}
+		}
+
+		if name == "" {
+			return next(ctx)
+		}
+
+		env, err := environment.GetEnvironment(azdCtx, name)
+		if errors.Is(err, os.ErrNotExist) {
+			return next(ctx)
+		} else if err != nil {
+			return fmt.Errorf("loading environment '%s': %w", name, err)
+		}
+
+		operation := fmt.Sprintf("`%s`", options.CommandPath())
+		if err := confirmProtectedEnvironment(ctx, input.GetConsole(ctx), global.NoPrompt, env, operation); err != nil {
+			return err
+		}
+
+		return next(ctx)
+	})
+}
+
+// eventLog creates a middleware that writes the event log of the command run to the logs folder of the environment
+// the command runs on, when the environment exists
+func eventLog(global *internal.GlobalCommandOptions) middleware.Middleware {
+	return middleware.NewEventLogMiddleware(func() (string, error) {
+		azdCtx, err := newAzdContext()
+		if err != nil {
+			return "", err
+		}
+
+		name := global.EnvironmentName
+		if name == "" {
+			if name, err = azdCtx.GetSelectedEnvironmentName(); err != nil {
+				return "", fmt.Errorf("getting default environment: %w", err)
+			}
+		}
+
+		if name == "" {
+			return "", nil
+		}
+
+		envDir := filepath.Dir(azdCtx.GetEnvironmentFilePath(name))
+		if _, err := os.Stat(envDir); errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		} else if err != nil {
+			return "", err
+		}
+
+		return filepath.Join(envDir, eventlog.DirectoryName), nil
+	})
+}
+
+// notifications creates a middleware that posts a summary of the operation to the notification sinks of the project
+// once the command completes. Failing to notify a sink is reported as a warning and never fails the command.
+func notifications(global *internal.GlobalCommandOptions, operation string) middleware.Middleware {
+	return middleware.MiddlewareFunc(func(ctx context.Context, options *middleware.Options, next middleware.NextFn) error {
+		start := time.Now()
+		err := next(ctx)
+		duration := time.Since(start)
+
+		azdCtx, ctxErr := newAzdContext()
+		if ctxErr != nil {
+			return err
+		}
+
+		name := global.EnvironmentName
+		if name == "" {
+			name, _ = azdCtx.GetSelectedEnvironmentName()
+		}
+
+		if name == "" {
+			return err
+		}
+
+		env, envErr := environment.GetEnvironment(azdCtx, name)
+		if envErr != nil {
+			log.Printf("loading environment '%s', skipping notifications: %v", name, envErr)
+			return err
+		}
+
+		prj, prjErr := project.LoadProjectConfig(azdCtx.ProjectPath(), env)
+		if prjErr != nil {
+			log.Printf("loading project, skipping notifications: %v", prjErr)
+			return err
+		}
+
+		var sinks []project.NotificationOptions
+		for _, sink := range prj.Notifications {
+			if sink.Notifies(operation) {
+				sinks = append(sinks, sink)
+			}
+		}
+
+		if len(sinks) == 0 {
+			return err
+		}
+
+		// The count of resources is best effort, the environment may have no resource groups after a failure
+		resourceCount := -1
+		if resources, resErr := infra.NewAzureResourceManager(ctx).GetEnvironmentResources(ctx, env); resErr == nil {
+			resourceCount = 0
+			for _, groupResources := range resources {
+				resourceCount += len(groupResources)
+			}
+		} else {
+			log.Printf("counting resources of environment '%s': %v", name, resErr)
+		}
+
+		summary := project.NewNotificationSummary(prj.Name, name, operation, duration, resourceCount, err)
+		console := input.GetConsole(ctx)
+		for _, sink := range sinks {
+			if notifyErr := project.SendNotification(ctx, sink, summary); notifyErr != nil {
+				console.Message(ctx, output.WithWarningFormat("WARNING: Failed notifying %s sink: %v", sink.Type, notifyErr))
+			}
+		}
+
+		return err
+	})
+}
+
+// pipelineDefinitionCheck creates a middleware that warns, before the command runs, about the pipeline definitions of
+// the project generated by a version of azd preceding breaking changes of the generated definitions
+func pipelineDefinitionCheck() middleware.Middleware {
+	return middleware.MiddlewareFunc(func(ctx context.Context, options *middleware.Options, next middleware.NextFn) error {
+		azdCtx, err := newAzdContext()
+		if err != nil {
+			return next(ctx)
+		}
+
+		outdated, err := pipeline.FindOutdatedDefinitions(azdCtx)
+		if err != nil {
+			log.Printf("checking the versions of the pipeline definitions: %v", err)
+			return next(ctx)
+		}
+
+		console := input.GetConsole(ctx)
+		for _, definition := range outdated {
+			console.Message(ctx, output.WithWarningFormat("WARNING: %s", definition.Message()))
+		}
+		if len(outdated) > 0 {
+			console.Message(ctx, "")
+		}
+
+		return next(ctx)
+	})
+}
+
+// initEvents creates a middleware that raises the init lifecycle events to the installed extensions
+func initEvents() middleware.Middleware {
+	return middleware.NewExtensionEventsMiddleware(extensions.EventInitializing, extensions.EventInitialized)
+}
+
+// provisionEvents creates a middleware that raises the provision lifecycle events to the installed extensions
+func provisionEvents() middleware.Middleware {
+	return middleware.NewExtensionEventsMiddleware(extensions.EventProvisioning, extensions.EventProvisioned)
+}
+
+// deployEvents creates a middleware that raises the deploy lifecycle events to the installed extensions
+func deployEvents() middleware.Middleware {
+	return middleware.NewExtensionEventsMiddleware(extensions.EventDeploying, extensions.EventDeployed)
+}
+
+// destroyEvents creates a middleware that raises the destroy lifecycle events to the installed extensions
+func destroyEvents() middleware.Middleware {
+	return middleware.NewExtensionEventsMiddleware(extensions.EventDestroying, extensions.EventDestroyed)
+}
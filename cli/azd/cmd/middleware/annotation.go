@@ -0,0 +1,36 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+)
+
+// NewAnnotationMiddleware creates a middleware that surfaces the error of a failed command as an error annotation of
+// the run when azd runs in GitHub Actions or Azure Pipelines.
+func NewAnnotationMiddleware() Middleware {
+	return MiddlewareFunc(func(ctx context.Context, options *Options, next NextFn) error {
+		err := next(ctx)
+		if err == nil {
+			return nil
+		}
+
+		annotation := output.Annotation(output.AnnotationError, err.Error())
+		if annotation == "" {
+			return err
+		}
+
+		// stdout holds the result of the command when JSON output is enabled
+		writer := options.Cmd.OutOrStdout()
+		if isJsonOutput(options.Cmd) {
+			writer = options.Cmd.ErrOrStderr()
+		}
+		fmt.Fprintln(writer, annotation)
+
+		return err
+	})
+}
@@ -0,0 +1,46 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AnnotationMiddleware(t *testing.T) {
+	failingAction := &testAction{run: func(ctx context.Context) error {
+		return errors.New("deployment failed")
+	}}
+
+	t.Run("WritesErrorAnnotationInGitHubActions", func(t *testing.T) {
+		t.Setenv("GITHUB_ACTIONS", "true")
+		t.Setenv("TF_BUILD", "")
+		stdout := &bytes.Buffer{}
+		cmd := &cobra.Command{Use: "test"}
+		cmd.SetOut(stdout)
+
+		err := RunAction(context.Background(), &Options{Cmd: cmd}, failingAction, NewAnnotationMiddleware())
+
+		require.EqualError(t, err, "deployment failed")
+		require.Equal(t, "::error::deployment failed\n", stdout.String())
+	})
+
+	t.Run("WritesNothingOutsideOfCI", func(t *testing.T) {
+		t.Setenv("GITHUB_ACTIONS", "")
+		t.Setenv("TF_BUILD", "")
+		stdout := &bytes.Buffer{}
+		cmd := &cobra.Command{Use: "test"}
+		cmd.SetOut(stdout)
+
+		err := RunAction(context.Background(), &Options{Cmd: cmd}, failingAction, NewAnnotationMiddleware())
+
+		require.Error(t, err)
+		require.Empty(t, stdout.String())
+	})
+}
@@ -0,0 +1,28 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package middleware
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// NewDebugMiddleware creates a middleware that logs the start, duration and result of the command.
+// Log output is only written when `--debug` is enabled.
+func NewDebugMiddleware() Middleware {
+	return MiddlewareFunc(func(ctx context.Context, options *Options, next NextFn) error {
+		log.Printf("running command '%s'", options.CommandPath())
+		start := time.Now()
+
+		err := next(ctx)
+		if err != nil {
+			log.Printf("command '%s' failed after %s: %v", options.CommandPath(), time.Since(start), err)
+		} else {
+			log.Printf("command '%s' completed in %s", options.CommandPath(), time.Since(start))
+		}
+
+		return err
+	})
+}
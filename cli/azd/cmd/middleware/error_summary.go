@@ -0,0 +1,40 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
+)
+
+// NewErrorSummaryMiddleware creates a middleware that writes a JSON summary of the error of a failed command to
+// stderr when azd runs in a CI system, so the failure can be processed by the following steps of the run.
+func NewErrorSummaryMiddleware() Middleware {
+	return MiddlewareFunc(func(ctx context.Context, options *Options, next NextFn) error {
+		err := next(ctx)
+		if err == nil || !internal.IsNonInteractiveCI() {
+			return err
+		}
+
+		summary, jsonErr := json.Marshal(contracts.EventEnvelope{
+			Type:      contracts.ErrorSummaryEventDataType,
+			Timestamp: time.Now(),
+			Data: contracts.ErrorSummary{
+				Command: options.CommandPath(),
+				Message: err.Error(),
+			},
+		})
+		if jsonErr != nil {
+			panic(fmt.Sprintf("ErrorSummary: unexpected error during marshaling for a valid object: %v", jsonErr))
+		}
+		fmt.Fprintln(options.Cmd.ErrOrStderr(), string(summary))
+
+		return err
+	})
+}
@@ -0,0 +1,57 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ErrorSummaryMiddleware(t *testing.T) {
+	failingAction := &testAction{run: func(ctx context.Context) error {
+		return errors.New("deployment failed")
+	}}
+
+	t.Run("WritesSummaryInCI", func(t *testing.T) {
+		t.Setenv("CI", "true")
+		t.Setenv(internal.ForceInteractiveEnvVarName, "")
+		stderr := &bytes.Buffer{}
+		cmd := &cobra.Command{Use: "deploy"}
+		cmd.SetErr(stderr)
+
+		err := RunAction(context.Background(), &Options{Cmd: cmd}, failingAction, NewErrorSummaryMiddleware())
+		require.EqualError(t, err, "deployment failed")
+
+		var summary struct {
+			Type string
+			Data struct {
+				Command string
+				Message string
+			}
+		}
+		require.NoError(t, json.Unmarshal(stderr.Bytes(), &summary))
+		require.Equal(t, "errorSummary", summary.Type)
+		require.Equal(t, "deploy", summary.Data.Command)
+		require.Equal(t, "deployment failed", summary.Data.Message)
+	})
+
+	t.Run("WritesNothingWhenForcedInteractive", func(t *testing.T) {
+		t.Setenv("CI", "true")
+		t.Setenv(internal.ForceInteractiveEnvVarName, "true")
+		stderr := &bytes.Buffer{}
+		cmd := &cobra.Command{Use: "deploy"}
+		cmd.SetErr(stderr)
+
+		err := RunAction(context.Background(), &Options{Cmd: cmd}, failingAction, NewErrorSummaryMiddleware())
+		require.Error(t, err)
+		require.Empty(t, stderr.String())
+	})
+}
@@ -0,0 +1,45 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package middleware
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/eventlog"
+)
+
+// NewEventLogMiddleware creates a middleware that writes the event log of the command run to the folder returned by
+// logDir. The run isn't logged when logDir returns an empty folder, i.e. outside of an environment.
+func NewEventLogMiddleware(logDir func() (string, error)) Middleware {
+	return MiddlewareFunc(func(ctx context.Context, options *Options, next NextFn) error {
+		dir, err := logDir()
+		if err != nil {
+			log.Printf("resolving the event log folder, the command isn't logged: %v", err)
+			return next(ctx)
+		}
+
+		if dir == "" {
+			return next(ctx)
+		}
+
+		eventLog, err := eventlog.Create(dir, options.CommandPath())
+		if err != nil {
+			log.Printf("the command isn't logged: %v", err)
+			return next(ctx)
+		}
+		defer eventLog.Close()
+
+		log.Printf("writing the event log of the command to %s", eventLog.Path())
+
+		start := time.Now()
+		eventLog.Write(eventlog.Event{Type: eventlog.CommandStartedEvent, Name: options.CommandPath()})
+
+		err = next(eventlog.WithLog(ctx, eventLog))
+
+		eventLog.Write(eventlog.NewCommandCompletedEvent(options.CommandPath(), time.Since(start), err))
+		return err
+	})
+}
@@ -0,0 +1,68 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/eventlog"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EventLogMiddleware(t *testing.T) {
+	t.Run("WritesTheEventsOfTheRun", func(t *testing.T) {
+		dir := t.TempDir()
+		action := &testAction{run: func(ctx context.Context) error {
+			require.NotNil(t, eventlog.FromContext(ctx))
+			eventlog.FromContext(ctx).StartStep("deploy/api")(nil)
+			return errors.New("deployment failed")
+		}}
+
+		err := RunAction(
+			context.Background(),
+			&Options{Cmd: &cobra.Command{Use: "deploy"}},
+			action,
+			NewEventLogMiddleware(func() (string, error) { return dir, nil }),
+		)
+		require.EqualError(t, err, "deployment failed")
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+
+		contents, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+		require.Len(t, lines, 3)
+		require.Contains(t, lines[0], `"type":"commandStarted"`)
+		require.Contains(t, lines[1], `"name":"deploy/api"`)
+		require.Contains(t, lines[2], `"type":"commandCompleted"`)
+		require.Contains(t, lines[2], `"error":"deployment failed"`)
+	})
+
+	t.Run("SkipsRunsOutsideOfAnEnvironment", func(t *testing.T) {
+		ran := false
+		action := &testAction{run: func(ctx context.Context) error {
+			ran = true
+			require.Nil(t, eventlog.FromContext(ctx))
+			return nil
+		}}
+
+		err := RunAction(
+			context.Background(),
+			&Options{Cmd: &cobra.Command{Use: "deploy"}},
+			action,
+			NewEventLogMiddleware(func() (string, error) { return "", nil }),
+		)
+		require.NoError(t, err)
+		require.True(t, ran)
+	})
+}
@@ -0,0 +1,82 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/extensions"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+)
+
+// NewExtensionEventsMiddleware creates a middleware that notifies the installed extensions subscribed to the
+// before event prior to running the command, and the extensions subscribed to the after event once the command
+// completes successfully. An extension failing to handle an event fails the command.
+func NewExtensionEventsMiddleware(before string, after string) Middleware {
+	return MiddlewareFunc(func(ctx context.Context, options *Options, next NextFn) error {
+		manager, err := extensions.NewManager()
+		if err != nil {
+			log.Printf("failed creating extension manager, skipping extension events: %v", err)
+			return next(ctx)
+		}
+
+		if err := raiseExtensionEvent(ctx, manager, options, before); err != nil {
+			return err
+		}
+
+		if err := next(ctx); err != nil {
+			return err
+		}
+
+		return raiseExtensionEvent(ctx, manager, options, after)
+	})
+}
+
+func raiseExtensionEvent(ctx context.Context, manager *extensions.Manager, options *Options, event string) error {
+	subscribers, err := manager.Subscribers(event)
+	if err != nil {
+		return fmt.Errorf("listing extensions: %w", err)
+	}
+
+	if len(subscribers) == 0 {
+		return nil
+	}
+
+	params := extensions.EventParams{
+		Event:   event,
+		Command: options.CommandPath(),
+	}
+
+	if azdCtx, err := azdcontext.NewAzdContext(); err == nil {
+		params.ProjectDir = azdCtx.ProjectDirectory()
+		params.EnvironmentName, _ = azdCtx.GetSelectedEnvironmentName()
+	}
+
+	if flag := options.Cmd.Flags().Lookup("environment"); flag != nil && flag.Value.String() != "" {
+		params.EnvironmentName = flag.Value.String()
+	}
+
+	console := input.GetConsole(ctx)
+	commandRunner := exec.GetCommandRunner(ctx)
+
+	for _, extension := range subscribers {
+		log.Printf("raising '%s' event for extension '%s'", event, extension.Name)
+
+		err := extensions.Invoke(ctx, commandRunner, extension, extensions.InvokeArgs{
+			Method: extensions.MethodRaiseEvent,
+			Params: params,
+			Stdout: console.Handles().Stdout,
+			Stderr: console.Handles().Stderr,
+		})
+		if err != nil {
+			return fmt.Errorf("'%s' event: %w", event, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package middleware contains the cross-cutting concerns that run around the action of a CLI command.
+package middleware
+
+import (
+	"context"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/spf13/cobra"
+)
+
+// Options describes the command being run to each middleware.
+type Options struct {
+	// The cobra command being run
+	Cmd *cobra.Command
+	// The positional arguments of the command
+	Args []string
+}
+
+// CommandPath returns the full path of the command, i.e. "azd env new".
+// It does not contain user input, and is safe for telemetry emission.
+func (o *Options) CommandPath() string {
+	return o.Cmd.CommandPath()
+}
+
+// NextFn invokes the next middleware in the chain, or the action after the last middleware.
+type NextFn func(ctx context.Context) error
+
+// Middleware is the representation of a cross-cutting concern of a CLI command.
+type Middleware interface {
+	// Run executes the middleware. Implementations call next to continue running the command,
+	// or return without calling next to stop the command from running.
+	Run(ctx context.Context, options *Options, next NextFn) error
+}
+
+// MiddlewareFunc is a Middleware implementation for regular functions.
+type MiddlewareFunc func(ctx context.Context, options *Options, next NextFn) error
+
+// Run implements the Middleware interface
+func (m MiddlewareFunc) Run(ctx context.Context, options *Options, next NextFn) error {
+	return m(ctx, options, next)
+}
+
+// RunAction runs the action after each of the middleware, in the order they are specified.
+func RunAction(ctx context.Context, options *Options, action actions.Action, middleware ...Middleware) error {
+	next := NextFn(action.Run)
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		current := middleware[i]
+		inner := next
+		next = func(ctx context.Context) error {
+			return current.Run(ctx, options, inner)
+		}
+	}
+
+	return next(ctx)
+}
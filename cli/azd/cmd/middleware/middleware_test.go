@@ -0,0 +1,79 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+type testAction struct {
+	run func(ctx context.Context) error
+}
+
+func (a *testAction) Run(ctx context.Context) error {
+	return a.run(ctx)
+}
+
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return MiddlewareFunc(func(ctx context.Context, options *Options, next NextFn) error {
+		*calls = append(*calls, name)
+		return next(ctx)
+	})
+}
+
+func Test_RunAction(t *testing.T) {
+	options := &Options{Cmd: &cobra.Command{Use: "test"}}
+
+	t.Run("RunsMiddlewareInOrder", func(t *testing.T) {
+		calls := []string{}
+		action := &testAction{run: func(ctx context.Context) error {
+			calls = append(calls, "action")
+			return nil
+		}}
+
+		err := RunAction(
+			context.Background(),
+			options,
+			action,
+			recordingMiddleware("first", &calls),
+			recordingMiddleware("second", &calls),
+		)
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"first", "second", "action"}, calls)
+	})
+
+	t.Run("StopsWhenMiddlewareFails", func(t *testing.T) {
+		calls := []string{}
+		expectedErr := errors.New("not logged in")
+		action := &testAction{run: func(ctx context.Context) error {
+			calls = append(calls, "action")
+			return nil
+		}}
+
+		failing := MiddlewareFunc(func(ctx context.Context, options *Options, next NextFn) error {
+			return expectedErr
+		})
+
+		err := RunAction(context.Background(), options, action, failing, recordingMiddleware("second", &calls))
+
+		require.ErrorIs(t, err, expectedErr)
+		require.Empty(t, calls)
+	})
+
+	t.Run("ReturnsActionError", func(t *testing.T) {
+		expectedErr := errors.New("action failed")
+		action := &testAction{run: func(ctx context.Context) error {
+			return expectedErr
+		}}
+
+		err := RunAction(context.Background(), options, action)
+		require.ErrorIs(t, err, expectedErr)
+	})
+}
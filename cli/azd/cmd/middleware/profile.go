@@ -0,0 +1,79 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+)
+
+// The share of the time of the command above which a step is reported as slow
+const slowStepShare = 0.25
+
+// Steps shorter than this are never reported as slow, whatever their share of the command
+const minSlowStepDuration = time.Second
+
+// NewProfileMiddleware creates a middleware that prints the time spent in each step of the command to stderr once
+// the command completes, when profiling is enabled with `--profile`.
+func NewProfileMiddleware() Middleware {
+	return MiddlewareFunc(func(ctx context.Context, options *Options, next NextFn) error {
+		err := next(ctx)
+
+		profiler := telemetry.GetProfiler()
+		if profiler == nil {
+			return err
+		}
+
+		// The span of the command ends before this middleware resumes, the profile is complete
+		if profile := profiler.Profile(); profile != nil {
+			printProfile(options.Cmd.ErrOrStderr(), profile)
+		}
+
+		return err
+	})
+}
+
+// printProfile writes the breakdown of the command by step, flagging the steps taking a large share of the command
+func printProfile(writer io.Writer, profile *telemetry.Profile) {
+	fmt.Fprintln(writer)
+	fmt.Fprintln(writer, output.WithHighLightFormat("Profile of %s: %s", profile.Command, formatStepDuration(profile.Duration)))
+
+	if len(profile.Steps) == 0 {
+		fmt.Fprintln(writer, "  No steps recorded.")
+		return
+	}
+
+	for _, step := range profile.Steps {
+		name := strings.Repeat("  ", step.Depth) + step.Name
+		if step.Count > 1 {
+			name = fmt.Sprintf("%s (x%d)", name, step.Count)
+		}
+
+		line := fmt.Sprintf("%-50s %10s", name, formatStepDuration(step.Duration))
+		if profile.Duration > 0 {
+			share := float64(step.Duration) / float64(profile.Duration)
+			line = fmt.Sprintf("%s %5.1f%%", line, share*100)
+
+			if share >= slowStepShare && step.Duration >= minSlowStepDuration {
+				line = output.WithWarningFormat("%s  (slow)", line)
+			}
+		}
+
+		fmt.Fprintln(writer, line)
+	}
+}
+
+func formatStepDuration(duration time.Duration) string {
+	if duration < time.Second {
+		return duration.Round(time.Millisecond).String()
+	}
+
+	return duration.Round(10 * time.Millisecond).String()
+}
@@ -0,0 +1,37 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package middleware
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_printProfile(t *testing.T) {
+	buf := &bytes.Buffer{}
+	printProfile(buf, &telemetry.Profile{
+		Command:  "provision",
+		Duration: 80 * time.Second,
+		Steps: []telemetry.ProfileStep{
+			{Name: "tools.check", Count: 1, Duration: 1500 * time.Millisecond, Depth: 1},
+			{Name: "provision.deploy", Count: 1, Duration: 60 * time.Second, Depth: 1},
+			{Name: "bicep.build", Count: 2, Duration: 4 * time.Second, Depth: 2},
+		},
+	})
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 4)
+	require.Contains(t, string(lines[0]), "Profile of provision: 1m20s")
+	require.Contains(t, string(lines[1]), "  tools.check")
+	require.Contains(t, string(lines[1]), "1.5s")
+	require.NotContains(t, string(lines[1]), "(slow)")
+	require.Contains(t, string(lines[2]), "75.0%")
+	require.Contains(t, string(lines[2]), "(slow)")
+	require.Contains(t, string(lines[3]), "    bicep.build (x2)")
+	require.Contains(t, string(lines[3]), "5.0%")
+}
@@ -0,0 +1,27 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package middleware
+
+import (
+	"context"
+
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/events"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// NewTelemetryMiddleware creates a middleware that emits a span for the command.
+func NewTelemetryMiddleware() Middleware {
+	return MiddlewareFunc(func(ctx context.Context, options *Options, next NextFn) error {
+		spanCtx, span := telemetry.GetTracer().Start(ctx, events.GetCommandEventName(options.CommandPath()))
+		defer span.End()
+
+		err := next(spanCtx)
+		if err != nil {
+			span.SetStatus(codes.Error, "UnknownError")
+		}
+
+		return err
+	})
+}
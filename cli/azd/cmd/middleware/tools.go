@@ -0,0 +1,22 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package middleware
+
+import (
+	"context"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// NewToolsMiddleware creates a middleware that ensures the tools required by the command are installed
+// before the command runs. The tools are resolved from the context once the command dependencies are available.
+func NewToolsMiddleware(requiredTools func(ctx context.Context) []tools.ExternalTool) Middleware {
+	return MiddlewareFunc(func(ctx context.Context, options *Options, next NextFn) error {
+		if err := tools.EnsureInstalled(ctx, tools.Unique(requiredTools(ctx))...); err != nil {
+			return err
+		}
+
+		return next(ctx)
+	})
+}
@@ -0,0 +1,221 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type packageFlags struct {
+	serviceName string
+	outputPath  string
+	global      *internal.GlobalCommandOptions
+}
+
+func (p *packageFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(
+		&p.serviceName,
+		"service",
+		"",
+		//nolint:lll
+		"Packages a specific service (when the string is unspecified, all services that are listed in the "+azdcontext.ProjectFileName+" file are packaged).",
+	)
+	local.StringVar(
+		&p.outputPath,
+		"output-path",
+		"",
+		"The directory the packages are written to (defaults to the packages directory of the environment).",
+	)
+
+	p.global = global
+}
+
+func packageCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *packageFlags) {
+	cmd := &cobra.Command{
+		Use:   "package",
+		Short: "Package the application's code to be deployed to Azure.",
+		//nolint:lll
+		Long: `Package the application's code to be deployed to Azure.
+
+Each service is built into the output directory, along with an ` + output.WithBackticks(project.PackageManifestFileName) + ` manifest describing the packages. Services hosted on Container Apps are built into a container image, other services are packaged as a zip file.
+
+Packages can be deployed later, for example by another stage of a pipeline, with ` + output.WithBackticks("azd deploy --from-package") + ` or ` + output.WithBackticks("azd deploy --image") + `.
+
+Examples:
+
+	$ azd package
+	$ azd package --service api --output-path ./dist`,
+	}
+	flags := &packageFlags{}
+	flags.Bind(cmd.Flags(), global)
+	output.AddOutputParam(
+		cmd,
+		[]output.Format{output.JsonFormat, output.NoneFormat},
+		output.NoneFormat,
+	)
+
+	registerFlagCompletion(cmd, "service", completeServiceNames)
+
+	return cmd, flags
+}
+
+type packageAction struct {
+	flags     packageFlags
+	azdCtx    *azdcontext.AzdContext
+	console   input.Console
+	formatter output.Formatter
+	writer    io.Writer
+}
+
+func newPackageAction(
+	flags packageFlags,
+	azdCtx *azdcontext.AzdContext,
+	console input.Console,
+	formatter output.Formatter,
+	writer io.Writer,
+) *packageAction {
+	return &packageAction{
+		flags:     flags,
+		azdCtx:    azdCtx,
+		console:   console,
+		formatter: formatter,
+		writer:    writer,
+	}
+}
+
+func (p *packageAction) Run(ctx context.Context) error {
+	env, ctx, err := loadOrInitEnvironment(ctx, &p.flags.global.EnvironmentName, p.azdCtx, p.console)
+	if err != nil {
+		return fmt.Errorf("loading environment: %w", err)
+	}
+
+	proj, err := project.LoadProjectConfig(p.azdCtx.ProjectPath(), env)
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+
+	if p.flags.serviceName != "" && !proj.HasService(p.flags.serviceName) {
+		return fmt.Errorf("service name '%s' doesn't exist", p.flags.serviceName)
+	}
+
+	outputDir := p.flags.outputPath
+	if outputDir == "" {
+		outputDir = filepath.Join(p.azdCtx.EnvironmentDirectory(), env.GetEnvName(), "packages")
+	}
+
+	outputDir, err = filepath.Abs(outputDir)
+	if err != nil {
+		return fmt.Errorf("resolving output path: %w", err)
+	}
+
+	// Services are packaged in a stable order, matching the order in which they are deployed
+	var serviceNames []string
+	for name := range proj.Services {
+		if p.flags.serviceName == "" || name == p.flags.serviceName {
+			serviceNames = append(serviceNames, name)
+		}
+	}
+	sort.Strings(serviceNames)
+
+	// Packaging a service only requires the tools needed to build it, Azure isn't involved
+	frameworks := map[string]project.FrameworkService{}
+	var allTools []tools.ExternalTool
+	for _, name := range serviceNames {
+		svc := proj.Services[name]
+
+		frameworkService, err := svc.GetFrameworkService(ctx, env)
+		if err != nil {
+			return fmt.Errorf("getting framework services: %w", err)
+		}
+
+		frameworks[name] = *frameworkService
+		allTools = append(allTools, (*frameworkService).RequiredExternalTools()...)
+	}
+
+	if err := tools.EnsureInstalled(ctx, tools.Unique(allTools)...); err != nil {
+		return err
+	}
+
+	interactive := p.formatter.Kind() == output.NoneFormat
+
+	manifest := project.PackageManifest{
+		Timestamp: time.Now(),
+		Services:  []project.ServicePackage{},
+	}
+
+	for _, name := range serviceNames {
+		svc := proj.Services[name]
+		framework := frameworks[name]
+
+		packageAndReportProgress := func(ctx context.Context, showProgress func(string)) error {
+			progress := make(chan string)
+			defer close(progress)
+
+			// Report any progress
+			go func() {
+				for message := range progress {
+					if showProgress != nil {
+						showProgress(fmt.Sprintf("- %s...", message))
+					}
+				}
+			}()
+
+			servicePackage, err := project.PackageService(ctx, svc, framework, outputDir, progress)
+			if err != nil {
+				return err
+			}
+
+			manifest.Services = append(manifest.Services, *servicePackage)
+			return nil
+		}
+
+		if interactive {
+			packageMsg := fmt.Sprintf("Packaging service %s...", output.WithHighLightFormat(svc.Name))
+			spinner, ctx := input.GetOrCreateSpinner(ctx, p.console, packageMsg)
+
+			spinner.Start()
+			err = packageAndReportProgress(ctx, spinner.Title)
+			spinner.Stop()
+
+			if err == nil {
+				p.console.Message(ctx, fmt.Sprintf("Packaged service %s", output.WithHighLightFormat(svc.Name)))
+			}
+		} else {
+			err = packageAndReportProgress(ctx, nil)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := manifest.Save(outputDir); err != nil {
+		return err
+	}
+
+	if p.formatter.Kind() == output.JsonFormat {
+		if fmtErr := p.formatter.Format(manifest, p.writer, nil); fmtErr != nil {
+			return fmt.Errorf("package result could not be displayed: %w", fmtErr)
+		}
+
+		return nil
+	}
+
+	p.console.Message(ctx, fmt.Sprintf("\nPackages written to %s", output.WithLinkFormat(outputDir)))
+
+	return nil
+}
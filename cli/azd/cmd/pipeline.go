@@ -6,19 +6,26 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"sort"
 
+	"github.com/azure/azure-dev/cli/azd/cmd/middleware"
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/commands/pipeline"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
 type pipelineConfigFlags struct {
 	pipeline.PipelineManagerArgs
-	global *internal.GlobalCommandOptions
+	authType string
+	global   *internal.GlobalCommandOptions
 }
 
 func (pc *pipelineConfigFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
@@ -36,6 +43,84 @@ func (pc *pipelineConfigFlags) Bind(local *pflag.FlagSet, global *internal.Globa
 	)
 	local.StringVar(&pc.PipelineRoleName, "principal-role", "Contributor", "The role to assign to the service principal.")
 	local.StringVar(&pc.PipelineProvider, "provider", "", "The pipeline provider to use (GitHub and Azdo supported).")
+	local.StringVar(
+		&pc.AzdoProjectId,
+		"project-id",
+		"",
+		"The id of the Azure DevOps project to configure the pipeline in, instead of selecting the project.",
+	)
+	local.StringVar(
+		&pc.Branch,
+		"branch",
+		"",
+		"The branch the Azure DevOps pipeline and its build policy target, instead of the default branch of the repository.",
+	)
+	local.BoolVar(
+		&pc.Resume,
+		"resume",
+		false,
+		"Resumes an interrupted configuration without prompting, reusing the resources it created.",
+	)
+	local.BoolVar(
+		&pc.SkipPush,
+		"skip-push",
+		false,
+		"Configures the pipeline on the existing remote without any git operation, your changes aren't committed or pushed.",
+	)
+	local.BoolVar(
+		&pc.RotateCredentials,
+		"rotate-credentials",
+		false,
+		"Rotates the credential of the service principal of the configured pipeline and saves it in the pipeline.",
+	)
+	local.StringVar(
+		&pc.RotationSchedule,
+		"rotation-schedule",
+		"",
+		"Adds a pipeline rotating the credential of the service principal on the cron schedule, i.e. '0 0 1 * *'.",
+	)
+	local.StringVar(
+		&pc.ManagedIdentityClientId,
+		"managed-identity",
+		"",
+		"The client id of the managed identity of the self-hosted agent to sign in with, instead of a service principal.",
+	)
+	local.BoolVar(
+		&pc.ExistingServiceConnection,
+		"existing-service-connection",
+		false,
+		"Signs in with an existing service connection of the Azure DevOps project, instead of a service principal.",
+	)
+	local.BoolVar(
+		&pc.VariableGroup,
+		"variable-group",
+		false,
+		"Saves the values of the environment in an Azure DevOps variable group named after it, linked to the pipeline.",
+	)
+	local.StringVar(
+		&pc.authType,
+		"auth-type",
+		"",
+		"How the pipeline signs in with the service principal: clientsecret or federated.",
+	)
+	local.BoolVar(
+		&pc.InfraOnly,
+		"infra-only",
+		false,
+		"Configures a pipeline provisioning the infrastructure when the infra folder changes.",
+	)
+	local.BoolVar(
+		&pc.CodeOnly,
+		"code-only",
+		false,
+		"Configures a pipeline deploying the code when the folders of the services change.",
+	)
+	local.BoolVar(
+		&pc.PreviewEnvironments,
+		"preview-environments",
+		false,
+		"Adds a pipeline deploying each pull request to its own environment, deleted when the pull request is closed.",
+	)
 	pc.global = global
 }
 
@@ -51,7 +136,12 @@ The Azure Developer CLI template includes a GitHub Actions pipeline configuratio
 For more information, go to https://aka.ms/azure-dev/pipeline.`,
 	}
 	cmd.Flags().BoolP("help", "h", false, fmt.Sprintf("Gets help for %s.", cmd.Name()))
-	cmd.AddCommand(BuildCmd(global, pipelineConfigCmdDesign, initPipelineConfigAction, nil))
+	cmd.AddCommand(BuildCmd(global, pipelineConfigCmdDesign, initPipelineConfigAction,
+		&buildOptions{middleware: []middleware.Middleware{
+			requireProject(), eventLog(global), requireLogin(), requireProtectionConfirmation(global),
+		}}))
+	cmd.AddCommand(BuildCmd(global, pipelineListPrincipalsCmdDesign, initPipelineListPrincipalsAction,
+		&buildOptions{middleware: []middleware.Middleware{requireAzCli(), requireLogin()}}))
 	return cmd
 }
 
@@ -61,6 +151,59 @@ func pipelineConfigCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Comm
 		Short: "Create and configure your deployment pipeline by using GitHub Actions.",
 		Long: `Create and configure your deployment pipeline by using GitHub Actions.
 
+Without --provider, the provider selected previously or in azure.yaml is used. Otherwise the provider is detected from
+the host of the git remote and the .github and .azdo folders of the project.
+
+When the configuration fails, running the command again resumes it from the failed step, reusing the choices made
+previously. When a configuration was interrupted, the command prompts to resume it or to delete the resources it
+created, --resume resumes it without prompting.
+
+With --skip-push, azd doesn't initialize the repository, add a remote, commit or push: the pipeline is configured on the
+existing remote and runs when you push your changes.
+
+With --rotate-credentials, azd resets the credential of the service principal of the configured pipeline and saves the
+new credential in the pipeline, without any git operation. With --rotation-schedule, azd adds a pipeline running
+'azd pipeline config --rotate-credentials' on the cron schedule.
+
+With --managed-identity, azd doesn't create a service principal: it assigns the role to the managed identity of your
+self-hosted agent or runner hosted in Azure, and writes a pipeline signing in with 'az login --identity'.
+
+With --existing-service-connection, azd doesn't create a service principal nor a service connection: you select an
+existing Azure Resource Manager service connection of the Azure DevOps project deploying to the subscription of the
+environment, and azd writes a pipeline signing in with it. The selection is saved in the environment as
+AZURE_DEVOPS_SERVICE_CONNECTION_NAME, set it to use the connection without being prompted.
+
+With Azure DevOps, the pipeline and the build policy requiring pull requests target the default branch of the
+repository, or the current branch when the repository is empty. Use --branch to target another branch, the changes
+are still pushed to the current branch and the first run of the pipeline deploys it.
+
+With --variable-group, the non-secret values of the environment are saved in an Azure DevOps variable group named
+after the environment, instead of variables of the pipeline, and the pipeline written by azd links to the group. The
+secrets stay variables of the pipeline. Deploying another environment is a matter of changing the group of the
+pipeline, configured once per environment with 'azd pipeline config --variable-group -e <environment>'.
+
+Without --principal-name, the service principal is named azd-<project>-<environment>. Its application is tagged
+with the project, the environment and the repository of the pipeline, listed by 'azd pipeline list-principals'.
+
+With --auth-type, you choose how the pipeline signs in with the service principal: 'federated' adds federated
+credentials trusting the tokens of GitHub Actions or of the Azure DevOps service connection, without any secret, and
+'clientsecret' saves a client secret in the pipeline. The auth type is recorded in the environment and kept by the next
+configuration. It defaults to federated, except for multi-stage pipelines, Terraform and credential rotation.
+
+With --infra-only, azd writes a pipeline running 'azd provision' when the infra folder or azure.yaml change. With
+--code-only, azd writes a pipeline running 'azd deploy' when the folders of the services or azure.yaml change. Use both
+to replace a pipeline provisioning and deploying on every commit, and delete its azure-dev.yml.
+
+With --preview-environments, azd adds a GitHub workflow deploying each pull request to the environment
+<environment>-pr-<number>, created from the variables of the workflow. The workflow comments the endpoints on the pull
+request and runs 'azd down' when the pull request is closed.
+
+When the environment has a time to live, set with 'azd env set-ttl', azd also writes a pipeline running every 6 hours
+which deletes the resource groups of the expired environments.
+
+Once configured, azd prints a summary of the repository, the pipeline, the service principal and the secrets set. The
+summary is saved in the environment and displayed by 'azd show'.
+
 For more information, go to https://aka.ms/azure-dev/pipeline.`,
 	}
 
@@ -72,22 +215,25 @@ For more information, go to https://aka.ms/azure-dev/pipeline.`,
 
 // pipelineConfigAction defines the action for pipeline config command
 type pipelineConfigAction struct {
-	flags   pipelineConfigFlags
-	manager *pipeline.PipelineManager
-	azdCtx  *azdcontext.AzdContext
-	console input.Console
+	flags         pipelineConfigFlags
+	manager       *pipeline.PipelineManager
+	azdCtx        *azdcontext.AzdContext
+	console       input.Console
+	configManager config.Manager
 }
 
 func newPipelineConfigAction(
 	azdCtx *azdcontext.AzdContext,
 	console input.Console,
 	flags pipelineConfigFlags,
+	configManager config.Manager,
 ) *pipelineConfigAction {
 	pca := &pipelineConfigAction{
-		flags:   flags,
-		manager: pipeline.NewPipelineManager(azdCtx, flags.global, flags.PipelineManagerArgs),
-		azdCtx:  azdCtx,
-		console: console,
+		flags:         flags,
+		manager:       pipeline.NewPipelineManager(azdCtx, flags.global, flags.PipelineManagerArgs),
+		azdCtx:        azdCtx,
+		console:       console,
+		configManager: configManager,
 	}
 
 	return pca
@@ -95,15 +241,6 @@ func newPipelineConfigAction(
 
 // Run implements action interface
 func (p *pipelineConfigAction) Run(ctx context.Context) error {
-	if err := ensureProject(p.azdCtx.ProjectPath()); err != nil {
-		return err
-	}
-
-	// make sure az is logged in
-	if err := ensureLoggedIn(ctx); err != nil {
-		return fmt.Errorf("failed to ensure login: %w", err)
-	}
-
 	// Read or init env
 	console := input.GetConsole(ctx)
 	if console == nil {
@@ -115,10 +252,16 @@ func (p *pipelineConfigAction) Run(ctx context.Context) error {
 		return fmt.Errorf("loading environment: %w", err)
 	}
 
+	userConfig, err := getUserConfig(p.configManager)
+	if err != nil {
+		return err
+	}
+
 	// Detect the SCM and CI providers based on the project directory
 	p.manager.ScmProvider,
 		p.manager.CiProvider,
-		err = pipeline.DetectProviders(ctx, p.azdCtx, env, p.manager.PipelineProvider)
+		err = pipeline.DetectProviders(
+		ctx, p.azdCtx, env, userConfig, p.manager.PipelineRemoteName, p.manager.PipelineProvider)
 	if err != nil {
 		return err
 	}
@@ -126,5 +269,74 @@ func (p *pipelineConfigAction) Run(ctx context.Context) error {
 	// set context for manager
 	p.manager.Environment = env
 
+	authType, err := pipeline.ParseAuthType(p.flags.authType)
+	if err != nil {
+		return err
+	}
+	p.manager.PipelineAuthType = authType
+
 	return p.manager.Configure(ctx)
 }
+
+func pipelineListPrincipalsCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *struct{}) {
+	cmd := &cobra.Command{
+		Use:   "list-principals",
+		Short: "List the service principals created by azd pipeline config.",
+		Long: `List the service principals created by azd pipeline config.
+
+The applications of the service principals are tagged with the project, the environment and the repository of their
+pipeline, which are listed to find the principals to clean up.`,
+	}
+	output.AddOutputParam(
+		cmd,
+		[]output.Format{output.JsonFormat, output.TableFormat},
+		output.TableFormat,
+	)
+	return cmd, &struct{}{}
+}
+
+type pipelineListPrincipalsAction struct {
+	azCli     azcli.AzCli
+	console   input.Console
+	formatter output.Formatter
+	writer    io.Writer
+}
+
+func newPipelineListPrincipalsAction(
+	azCli azcli.AzCli,
+	console input.Console,
+	formatter output.Formatter,
+	writer io.Writer,
+) *pipelineListPrincipalsAction {
+	return &pipelineListPrincipalsAction{
+		azCli:     azCli,
+		console:   console,
+		formatter: formatter,
+		writer:    writer,
+	}
+}
+
+func (p *pipelineListPrincipalsAction) Run(ctx context.Context) error {
+	principals, err := p.azCli.ListPipelineServicePrincipals(ctx)
+	if err != nil {
+		return fmt.Errorf("listing service principals: %w", err)
+	}
+
+	sort.Slice(principals, func(i, j int) bool {
+		return principals[i].Name < principals[j].Name
+	})
+
+	if p.formatter.Kind() == output.TableFormat {
+		rows := make([][]string, 0, len(principals))
+		for _, principal := range principals {
+			rows = append(rows, []string{
+				principal.Name, principal.AppId, principal.Project, principal.Environment, principal.RepositoryUrl,
+			})
+		}
+
+		p.console.Table(ctx, []string{"NAME", "APP ID", "PROJECT", "ENVIRONMENT", "REPOSITORY"}, rows)
+		return nil
+	}
+
+	return p.formatter.Format(principals, p.writer, nil)
+}
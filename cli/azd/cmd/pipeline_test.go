@@ -18,7 +18,14 @@ func TestPipelineCmd(t *testing.T) {
 	assert.EqualValues(t, "Manage GitHub Actions pipelines.", command.Short)
 
 	childCommands := command.Commands()
-	assert.EqualValues(t, 1, len(childCommands))
+	assert.EqualValues(t, 2, len(childCommands))
+}
+
+func TestPipelineListPrincipalsCmd(t *testing.T) {
+	globalOpt := &internal.GlobalCommandOptions{}
+	command, _ := pipelineListPrincipalsCmdDesign(globalOpt)
+	assert.EqualValues(t, "list-principals", command.Use)
+	assert.NotNil(t, command.Flags().Lookup("output"))
 }
 
 func TestPipelineConfigCmd(t *testing.T) {
@@ -17,7 +17,11 @@ The command prompts you for the following:
 - Azure location: The Azure location where your resources will be deployed.
 - Azure subscription: The Azure subscription where your resources will be deployed.
 
-Depending on what Azure resources are created, running this command might take a while. To view progress, go to the Azure portal and search for the resource group that contains your environment name.`,
+Depending on what Azure resources are created, running this command might take a while. To view progress, go to the Azure portal and search for the resource group that contains your environment name.
+
+When the infrastructure is provisioned by another tool, set the ` + "`passthrough`" + ` provider in the infra section of azure.yaml: the command doesn't deploy, it imports the outputs of an existing deployment, or of an outputs file, into the environment.
+
+In GitHub Actions and Azure Pipelines, the subscription, location and resource groups of the environment are published to the summary of the run.`,
 	}
 
 	f := &infraCreateFlags{}
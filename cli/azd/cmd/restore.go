@@ -11,7 +11,6 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
-	"github.com/azure/azure-dev/cli/azd/pkg/spin"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -47,6 +46,8 @@ For the best local run and debug experience, go to https://aka.ms/azure-dev/vsco
 
 	flags := &restoreFlags{}
 	flags.Bind(cmd.Flags(), global)
+	registerFlagCompletion(cmd, "service", completeServiceNames)
+
 	return cmd, flags
 }
 
@@ -116,7 +117,7 @@ func (r *restoreAction) Run(ctx context.Context) error {
 			return fmt.Errorf("getting framework services: %w", err)
 		}
 
-		spinner := spin.NewSpinner(r.console.Handles().Stdout, installMsg)
+		spinner := input.NewSpinner(r.console, installMsg)
 		if err = spinner.Run(func() error { return (*frameworkService).InstallDependencies(ctx) }); err != nil {
 			return err
 		}
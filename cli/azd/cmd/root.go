@@ -4,27 +4,29 @@
 package cmd
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/cmd/middleware"
 	// Importing for infrastructure provider plugin registrations
 	_ "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning/bicep"
+	_ "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning/passthrough"
 	_ "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning/terraform"
 
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
-	"github.com/azure/azure-dev/cli/azd/internal/telemetry/events"
 	"github.com/azure/azure-dev/cli/azd/pkg/commands"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/spf13/cobra"
-	"go.opentelemetry.io/otel/codes"
 )
 
 func NewRootCmd() *cobra.Command {
 	prevDir := ""
+	noTelemetry := false
 	opts := &internal.GlobalCommandOptions{}
 
 	cmd := &cobra.Command{
@@ -47,6 +49,10 @@ The most common next commands are:
 
 For more information, visit the Azure Developer CLI Dev Hub: https://aka.ms/azure-dev/devhub.`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Quiet && opts.Verbose {
+				return errors.New("--quiet and --verbose can't be used together")
+			}
+
 			if opts.Cwd != "" {
 				current, err := os.Getwd()
 
@@ -65,6 +71,17 @@ For more information, visit the Azure Developer CLI Dev Hub: https://aka.ms/azur
 				opts.EnvironmentName = os.Getenv(environment.EnvNameEnvVarName)
 			}
 
+			// Nobody answers the prompts of a CI run
+			if internal.IsNonInteractiveCI() {
+				opts.NoPrompt = true
+			}
+
+			// Telemetry collection is turned off for the process by main.go, this ensures tools launched
+			// by the command are not asked to collect telemetry either.
+			if noTelemetry {
+				opts.EnableTelemetry = false
+			}
+
 			return nil
 		},
 		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
@@ -84,14 +101,41 @@ For more information, visit the Azure Developer CLI Dev Hub: https://aka.ms/azur
 	cmd.CompletionOptions.HiddenDefaultCmd = true
 	cmd.Flags().BoolP("help", "h", false, fmt.Sprintf("Gets help for %s.", cmd.Name()))
 	cmd.PersistentFlags().StringVarP(&opts.EnvironmentName, "environment", "e", "", "The name of the environment to use.")
+	registerFlagCompletion(cmd, "environment", completeEnvironmentNames)
 	cmd.PersistentFlags().StringVarP(&opts.Cwd, "cwd", "C", "", "Sets the current working directory.")
 	cmd.PersistentFlags().BoolVar(&opts.EnableDebugLogging, "debug", false, "Enables debugging and diagnostics logging.")
+	cmd.PersistentFlags().BoolVarP(
+		&opts.Quiet, "quiet", "q", false, "Hides the progress of the command, only showing its messages and errors.")
+	cmd.PersistentFlags().BoolVar(
+		&opts.Verbose, "verbose", false, "Shows the output of the tools launched by the command, i.e. az and bicep.")
 	cmd.PersistentFlags().
 		BoolVar(
 			&opts.NoPrompt,
 			"no-prompt",
 			false,
-			"Accepts the default value instead of prompting, or it fails if there is no default.")
+			"Accepts the default value instead of prompting, or it fails if there is no default. "+
+				"Enabled in CI systems, unless "+internal.ForceInteractiveEnvVarName+" is set to true.")
+	cmd.PersistentFlags().BoolVar(&noTelemetry, "no-telemetry", false, "Disables telemetry collection for the command.")
+	cmd.PersistentFlags().
+		StringVar(
+			&opts.TraceLogFile,
+			"trace-log-file",
+			"",
+			"Writes a trace of the command's operations, including timings, to the specified file.")
+	cmd.PersistentFlags().
+		StringVar(
+			&opts.TraceLogUrl,
+			"trace-log-url",
+			"",
+			"Sends a trace of the command's operations to the specified OTLP/HTTP endpoint, i.e. http://localhost:4318.")
+	cmd.PersistentFlags().BoolVar(
+		&opts.Profile, "profile", false, "Prints the time spent in each step of the command once it completes.")
+	cmd.PersistentFlags().
+		StringVar(
+			&opts.ProfileDir,
+			"profile-dir",
+			"",
+			"Writes a CPU profile, a heap profile and an execution trace of the command to the specified folder.")
 	cmd.SetHelpTemplate(
 		fmt.Sprintf("%s\nPlease let us know how we are doing: https://aka.ms/azure-dev/hats\n", cmd.HelpTemplate()),
 	)
@@ -100,21 +144,58 @@ For more information, visit the Azure Developer CLI Dev Hub: https://aka.ms/azur
 
 	cmd.AddCommand(configCmd(opts))
 	cmd.AddCommand(envCmd(opts))
+	cmd.AddCommand(extensionCmd(opts))
 	cmd.AddCommand(infraCmd(opts))
 	cmd.AddCommand(pipelineCmd(opts))
+	cmd.AddCommand(secretsCmd(opts))
 	cmd.AddCommand(telemetryCmd(opts))
 	cmd.AddCommand(templatesCmd(opts))
 
 	cmd.AddCommand(BuildCmd(opts, versionCmdDesign, initVersionAction, &buildOptions{disableTelemetry: true}))
+	cmd.AddCommand(BuildCmd(opts, upgradeCmdDesign, initUpgradeAction, nil))
 	cmd.AddCommand(BuildCmd(opts, showCmdDesign, initShowAction, nil))
-	cmd.AddCommand(BuildCmd(opts, restoreCmdDesign, initRestoreAction, nil))
-	cmd.AddCommand(BuildCmd(opts, loginCmdDesign, initLoginAction, nil))
-	cmd.AddCommand(BuildCmd(opts, monitorCmdDesign, initMonitorAction, nil))
-	cmd.AddCommand(BuildCmd(opts, downCmdDesign, initInfraDeleteAction, nil))
-	cmd.AddCommand(BuildCmd(opts, initCmdDesign, initInitAction, nil))
-	cmd.AddCommand(BuildCmd(opts, upCmdDesign, initUpAction, nil))
-	cmd.AddCommand(BuildCmd(opts, provisionCmdDesign, initInfraCreateAction, nil))
-	cmd.AddCommand(BuildCmd(opts, deployCmdDesign, initDeployAction, nil))
+	cmd.AddCommand(BuildCmd(opts, doctorCmdDesign, initDoctorAction, nil))
+	cmd.AddCommand(BuildCmd(opts, restoreCmdDesign, initRestoreAction,
+		&buildOptions{middleware: []middleware.Middleware{eventLog(opts)}}))
+	cmd.AddCommand(BuildCmd(opts, loginCmdDesign, initLoginAction,
+		&buildOptions{middleware: []middleware.Middleware{requireAzCli()}}))
+	cmd.AddCommand(BuildCmd(opts, monitorCmdDesign, initMonitorAction,
+		&buildOptions{middleware: []middleware.Middleware{requireProject(), requireAzCli(), requireLogin()}}))
+	cmd.AddCommand(BuildCmd(opts, downCmdDesign, initInfraDeleteAction,
+		&buildOptions{middleware: []middleware.Middleware{
+			requireProject(), eventLog(opts), requireAzCli(), requireLogin(), requireProtectionConfirmation(opts),
+			destroyEvents(),
+		}}))
+	cmd.AddCommand(BuildCmd(opts, initCmdDesign, initInitAction,
+		&buildOptions{middleware: []middleware.Middleware{initEvents()}}))
+	cmd.AddCommand(BuildCmd(opts, upCmdDesign, initUpAction,
+		&buildOptions{middleware: []middleware.Middleware{
+			eventLog(opts), requireAzCli(), requireLogin(), pipelineDefinitionCheck(), notifications(opts, "up"),
+		}}))
+	cmd.AddCommand(BuildCmd(opts, provisionCmdDesign, initInfraCreateAction,
+		&buildOptions{middleware: []middleware.Middleware{
+			requireProject(), eventLog(opts), requireAzCli(), requireLogin(), provisionEvents(),
+			pipelineDefinitionCheck(), notifications(opts, "provision"),
+		}}))
+	cmd.AddCommand(BuildCmd(opts, deployCmdDesign, initDeployAction,
+		&buildOptions{middleware: []middleware.Middleware{
+			requireProject(), eventLog(opts), requireAzCli(), requireLogin(), deployEvents(),
+			pipelineDefinitionCheck(), notifications(opts, "deploy"),
+		}}))
+	cmd.AddCommand(BuildCmd(opts, packageCmdDesign, initPackageAction,
+		&buildOptions{middleware: []middleware.Middleware{requireProject(), eventLog(opts)}}))
+	cmd.AddCommand(BuildCmd(opts, logsCmdDesign, initLogsAction,
+		&buildOptions{middleware: []middleware.Middleware{requireProject(), requireAzCli(), requireLogin()}}))
+	cmd.AddCommand(BuildCmd(opts, execCmdDesign, initExecAction,
+		&buildOptions{middleware: []middleware.Middleware{requireProject(), requireAzCli(), requireLogin()}}))
+	cmd.AddCommand(BuildCmd(opts, cleanupCmdDesign, initCleanupAction,
+		&buildOptions{middleware: []middleware.Middleware{requireProject(), requireAzCli(), requireLogin()}}))
+	cmd.AddCommand(BuildCmd(opts, runCmdDesign, initRunAction,
+		&buildOptions{middleware: []middleware.Middleware{requireProject()}}))
+	cmd.AddCommand(BuildCmd(opts, serveCmdDesign, initServeAction,
+		&buildOptions{middleware: []middleware.Middleware{requireProject()}}))
+
+	addExtensionCommands(cmd, opts)
 
 	return cmd
 }
@@ -128,7 +209,26 @@ type actionBuilder[F any] func(
 	args []string) (actions.Action, error)
 
 type buildOptions struct {
+	// Skips the telemetry middleware, no span is emitted for the command
 	disableTelemetry bool
+	// Middleware specific to the command, run after the default middleware and before the action
+	middleware []middleware.Middleware
+}
+
+// The middleware run for every command, before any command specific middleware
+func defaultMiddleware(buildOptions *buildOptions) []middleware.Middleware {
+	defaults := []middleware.Middleware{
+		middleware.NewDebugMiddleware(),
+		middleware.NewAnnotationMiddleware(),
+		middleware.NewErrorSummaryMiddleware(),
+		middleware.NewProfileMiddleware(),
+	}
+
+	if buildOptions == nil || !buildOptions.disableTelemetry {
+		defaults = append(defaults, middleware.NewTelemetryMiddleware())
+	}
+
+	return defaults
 }
 
 func BuildCmd[F any](
@@ -139,7 +239,12 @@ func BuildCmd[F any](
 	cmd, flags := buildDesign(opts)
 	cmd.Flags().BoolP("help", "h", false, fmt.Sprintf("Gets help for %s.", cmd.Name()))
 
-	runCmd := func(cmd *cobra.Command, ctx context.Context, args []string) error {
+	allMiddleware := defaultMiddleware(buildOptions)
+	if buildOptions != nil {
+		allMiddleware = append(allMiddleware, buildOptions.middleware...)
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		action, err := buildAction(cmd, opts, *flags, args)
 		if err != nil {
 			return err
@@ -147,38 +252,18 @@ func BuildCmd[F any](
 
 		// shim to register dependencies in context to maintain backwards compatibility
 		// to be removed long term
-		ctx, err = commands.RegisterDependenciesInCtx(ctx, cmd, opts)
+		ctx, err := commands.RegisterDependenciesInCtx(cmd.Context(), cmd, opts, config.NewManager())
 		if err != nil {
 			return err
 		}
 
-		return action.Run(ctx)
-	}
-
-	cmd.RunE = func(cmd *cobra.Command, args []string) error {
-		if buildOptions != nil && buildOptions.disableTelemetry {
-			return runCmd(cmd, cmd.Context(), args)
-		} else {
-			// Bind cmd, args. Only a different context needs to be passed.
-			runWithContext := func(ctx context.Context) error { return runCmd(cmd, ctx, args) }
-			return runCmdWithTelemetry(cmd, runWithContext)
+		middlewareOptions := &middleware.Options{
+			Cmd:  cmd,
+			Args: args,
 		}
-	}
-
-	return cmd
-}
 
-func runCmdWithTelemetry(cmd *cobra.Command, runCmd func(ctx context.Context) error) error {
-	// Note: CommandPath is constructed using the Use member on each command up to the root.
-	// It does not contain user input, and is safe for telemetry emission.
-	spanCtx, span := telemetry.GetTracer().Start(cmd.Context(), events.GetCommandEventName(cmd.CommandPath()))
-	defer span.End()
-
-	err := runCmd(spanCtx)
-	if err != nil {
-		span.SetStatus(codes.Error, "UnknownError")
+		return middleware.RunAction(ctx, middlewareOptions, action, allMiddleware...)
 	}
 
-	return err
-
+	return cmd
 }
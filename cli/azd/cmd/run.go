@@ -0,0 +1,425 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/devtunnel"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// The time the services are given to stop once interrupted, before they're killed
+const runStopTimeout = 10 * time.Second
+
+type runFlags struct {
+	global *internal.GlobalCommandOptions
+}
+
+func (r *runFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	r.global = global
+}
+
+func runCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *runFlags) {
+	cmd := &cobra.Command{
+		Use:   "run [<service>]",
+		Short: "Run the application's services locally, wired to the Azure resources of the environment.",
+		//nolint:lll
+		Long: `Run the application's services locally, wired to the Azure resources of the environment.
+
+All the services in the ` + output.WithBackticks(azdcontext.ProjectFileName) + ` file are started, or only the specified service, until you press Ctrl+C. Each service is started in its directory with the ` + output.WithBackticks("run") + ` command of the service, or else with docker compose when the service has a compose file, func start for Azure Functions, dotnet run for .NET and npm start for JavaScript and TypeScript.
+
+The services receive the values of the environment as environment variables, including the outputs of the infrastructure such as connection strings, along with the ` + output.WithBackticks("appSettings") + ` of the service and the values of the ` + output.WithBackticks("secrets") + ` it references, read from the key vault of the environment.
+
+Examples:
+
+	$ azd run
+	$ azd run api
+
+A service with a ` + output.WithBackticks("tunnel") + ` is exposed on the public URL of a dev tunnel, so the resources in Azure can call it back, such as webhooks and Event Grid subscriptions. The URL is written to the environment as ` + output.WithBackticks("SERVICE_<NAME>_TUNNEL_URL") + `, for azure.yaml and your infrastructure to reference it, and the ` + output.WithBackticks("appSettings") + ` of the services hosted on App Service and Azure Functions are updated with it. Dev tunnels require the devtunnel CLI, signed in with devtunnel user login.`,
+	}
+	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.ValidArgsFunction = completeServiceNameArg
+	flags := &runFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return cmd, flags
+}
+
+type runAction struct {
+	flags        runFlags
+	args         []string
+	azdCtx       *azdcontext.AzdContext
+	azCli        azcli.AzCli
+	devTunnelCli devtunnel.DevTunnelCli
+	console      input.Console
+}
+
+func newRunAction(
+	flags runFlags,
+	args []string,
+	azdCtx *azdcontext.AzdContext,
+	azCli azcli.AzCli,
+	devTunnelCli devtunnel.DevTunnelCli,
+	console input.Console,
+) *runAction {
+	return &runAction{
+		flags:        flags,
+		args:         args,
+		azdCtx:       azdCtx,
+		azCli:        azCli,
+		devTunnelCli: devTunnelCli,
+		console:      console,
+	}
+}
+
+// a service started locally
+type localService struct {
+	name   string
+	cmd    *exec.Cmd
+	output *prefixWriter
+}
+
+// the exit of a service started locally
+type localServiceExit struct {
+	service *localService
+	err     error
+}
+
+func (r *runAction) Run(ctx context.Context) error {
+	env, ctx, err := loadOrInitEnvironment(ctx, &r.flags.global.EnvironmentName, r.azdCtx, r.console)
+	if err != nil {
+		return fmt.Errorf("loading environment: %w", err)
+	}
+
+	projConfig, err := project.LoadProjectConfig(r.azdCtx.ProjectPath(), env)
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+
+	var names []string
+	if len(r.args) > 0 {
+		if !projConfig.HasService(r.args[0]) {
+			return fmt.Errorf("service name '%s' doesn't exist", r.args[0])
+		}
+
+		names = []string{r.args[0]}
+	} else {
+		for name := range projConfig.Services {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	if len(names) == 0 {
+		return errors.New("the project has no services to run")
+	}
+
+	// Ctrl+C interrupts the services too, they stop on their own
+	runCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	tunnels, projConfig, err := r.hostTunnels(runCtx, env, projConfig, names)
+	if err != nil {
+		return err
+	}
+	defer closeTunnels(tunnels)
+
+	// The commands and environments of all the services are resolved before starting any of them
+	var services []*localService
+	stdout := &lockedWriter{writer: r.console.Handles().Stdout}
+	for _, name := range names {
+		config := projConfig.Services[name]
+
+		commandLine, err := config.LocalRunCommand()
+		if err != nil {
+			return err
+		}
+
+		vars, err := project.LocalRunEnv(ctx, r.azCli, config, env)
+		if err != nil {
+			return err
+		}
+
+		cmd, err := shellCommand(commandLine)
+		if err != nil {
+			return err
+		}
+
+		service := &localService{
+			name:   name,
+			cmd:    cmd,
+			output: &prefixWriter{prefix: fmt.Sprintf("%s | ", name), writer: stdout},
+		}
+		cmd.Dir = config.Path()
+		cmd.Env = append(os.Environ(), vars...)
+		cmd.Stdout = service.output
+		cmd.Stderr = service.output
+
+		r.console.Message(ctx, fmt.Sprintf(
+			"Starting service %s with %s", output.WithHighLightFormat(name), output.WithBackticks(commandLine)))
+		services = append(services, service)
+	}
+
+	r.console.Message(ctx, "Press Ctrl+C to stop the services...\n")
+
+	exits := make(chan localServiceExit, len(services))
+	for i, service := range services {
+		if err := service.cmd.Start(); err != nil {
+			killServices(services[:i])
+			return fmt.Errorf("starting service %s: %w", service.name, err)
+		}
+
+		go func(service *localService) {
+			err := service.cmd.Wait()
+			service.output.Flush()
+			exits <- localServiceExit{service: service, err: err}
+		}(service)
+	}
+
+	var failed []string
+	for running := len(services); running > 0; {
+		select {
+		case exit := <-exits:
+			running--
+			if exit.err != nil {
+				failed = append(failed, exit.service.name)
+				r.console.Message(ctx, output.WithErrorFormat("Service %s exited: %v", exit.service.name, exit.err))
+			} else {
+				r.console.Message(ctx, fmt.Sprintf("Service %s exited", output.WithHighLightFormat(exit.service.name)))
+			}
+		case <-runCtx.Done():
+			r.console.Message(ctx, "\nStopping the services...")
+
+			timeout := time.After(runStopTimeout)
+			for ; running > 0; running-- {
+				select {
+				case <-exits:
+				case <-timeout:
+					killServices(services)
+					timeout = nil
+					<-exits
+				}
+			}
+
+			return nil
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("services exited with an error: %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// hostTunnels hosts the dev tunnels of the services and writes their URLs to the environment. The project is loaded
+// again when the URLs changed, since azure.yaml may reference them, and the app settings of the services hosted on App
+// Service and Azure Functions are applied again, for the resources in Azure to call the local services back.
+func (r *runAction) hostTunnels(
+	ctx context.Context,
+	env *environment.Environment,
+	projConfig *project.ProjectConfig,
+	names []string,
+) ([]*devtunnel.Tunnel, *project.ProjectConfig, error) {
+	var tunnels []*devtunnel.Tunnel
+	changed := false
+
+	for _, name := range names {
+		options := projConfig.Services[name].Tunnel
+		if options == nil {
+			continue
+		}
+
+		if err := options.Validate(); err != nil {
+			closeTunnels(tunnels)
+			return nil, nil, fmt.Errorf("service '%s': %w", name, err)
+		}
+
+		if len(tunnels) == 0 {
+			if err := tools.EnsureInstalled(ctx, r.devTunnelCli); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		tunnel, err := r.devTunnelCli.Host(ctx, options.Port, options.AllowAnonymous)
+		if err != nil {
+			closeTunnels(tunnels)
+			return nil, nil, fmt.Errorf("service '%s': %w", name, err)
+		}
+
+		tunnels = append(tunnels, tunnel)
+		r.console.Message(ctx, fmt.Sprintf(
+			"Tunneling %s to port %d of service %s",
+			output.WithLinkFormat(tunnel.Url), options.Port, output.WithHighLightFormat(name)))
+
+		if key := project.TunnelUrlEnvVarName(name); env.Values[key] != tunnel.Url {
+			env.Values[key] = tunnel.Url
+			changed = true
+		}
+	}
+
+	if !changed {
+		return tunnels, projConfig, nil
+	}
+
+	if err := env.Save(); err != nil {
+		closeTunnels(tunnels)
+		return nil, nil, fmt.Errorf("saving tunnel URLs to environment: %w", err)
+	}
+
+	projConfig, err := project.LoadProjectConfig(r.azdCtx.ProjectPath(), env)
+	if err != nil {
+		closeTunnels(tunnels)
+		return nil, nil, fmt.Errorf("loading project: %w", err)
+	}
+
+	r.updateAppSettings(ctx, env, projConfig)
+	return tunnels, projConfig, nil
+}
+
+// updateAppSettings applies the app settings of the services hosted on App Service and Azure Functions, which may
+// reference the URLs of the tunnels. The failures are reported as warnings, the services still run locally.
+func (r *runAction) updateAppSettings(
+	ctx context.Context,
+	env *environment.Environment,
+	projConfig *project.ProjectConfig,
+) {
+	hasAppSettings := false
+	for _, config := range projConfig.Services {
+		hasAppSettings = hasAppSettings || len(config.AppSettings) > 0
+	}
+
+	if !hasAppSettings {
+		return
+	}
+
+	proj, err := projConfig.GetProject(&ctx, env)
+	if err != nil {
+		r.console.Message(ctx, output.WithWarningFormat("WARNING: the app settings weren't updated: %v", err))
+		return
+	}
+
+	for _, svc := range proj.Services {
+		if len(svc.Config.AppSettings) == 0 {
+			continue
+		}
+
+		diff, err := project.ApplyAppSettings(ctx, r.azCli, svc, env)
+		if err != nil {
+			r.console.Message(ctx, output.WithWarningFormat(
+				"WARNING: the app settings of service %s weren't updated: %v", svc.Config.Name, err))
+			continue
+		}
+
+		if !diff.IsEmpty() {
+			r.console.Message(ctx, fmt.Sprintf(
+				"Updated the app settings of service %s: %s", output.WithHighLightFormat(svc.Config.Name), diff))
+		}
+	}
+}
+
+func closeTunnels(tunnels []*devtunnel.Tunnel) {
+	for _, tunnel := range tunnels {
+		tunnel.Close()
+	}
+}
+
+// killServices kills the processes of the services still running
+func killServices(services []*localService) {
+	for _, service := range services {
+		if service.cmd.Process != nil {
+			// fails for the processes already exited
+			_ = service.cmd.Process.Kill()
+		}
+	}
+}
+
+// shellCommand returns the command running the command line with the shell of the OS, which finds the programs of
+// the command line such as npm.cmd on Windows
+func shellCommand(commandLine string) (*exec.Cmd, error) {
+	if runtime.GOOS == "windows" {
+		dir := os.Getenv("SYSTEMROOT")
+		if dir == "" {
+			return nil, errors.New("environment variable 'SYSTEMROOT' has no value")
+		}
+
+		return exec.Command(filepath.Join(dir, "System32", "cmd.exe"), "/c", commandLine), nil
+	}
+
+	return exec.Command(filepath.Join("/", "bin", "sh"), "-c", commandLine), nil
+}
+
+// lockedWriter serializes the writes of the services to the same output
+type lockedWriter struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writer.Write(p)
+}
+
+// prefixWriter prefixes each line written by a service with the name of the service, i.e. "api | listening on 8080"
+type prefixWriter struct {
+	mu      sync.Mutex
+	prefix  string
+	writer  io.Writer
+	partial []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+
+		if _, err := w.writer.Write(append([]byte(w.prefix), w.partial[:i+1]...)); err != nil {
+			return 0, err
+		}
+		w.partial = w.partial[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush writes the last line of the service, when it doesn't end with a new line
+func (w *prefixWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.partial) > 0 {
+		_, _ = w.writer.Write(append(append([]byte(w.prefix), w.partial...), '\n'))
+		w.partial = nil
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer := &prefixWriter{prefix: "api | ", writer: &buf}
+
+	_, err := writer.Write([]byte("listening"))
+	require.NoError(t, err)
+	require.Empty(t, buf.String())
+
+	_, err = writer.Write([]byte(" on 8080\nready\nstop"))
+	require.NoError(t, err)
+	require.Equal(t, "api | listening on 8080\napi | ready\n", buf.String())
+
+	writer.Flush()
+	require.Equal(t, "api | listening on 8080\napi | ready\napi | stop\n", buf.String())
+}
@@ -0,0 +1,297 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/middleware"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/spf13/cobra"
+)
+
+// secretNameRegexp matches the names key vault accepts for secrets
+var secretNameRegexp = regexp.MustCompile(`^[0-9a-zA-Z-]{1,127}$`)
+
+func secretsCmd(rootOptions *internal.GlobalCommandOptions) *cobra.Command {
+	root := &cobra.Command{
+		Use:   "secrets",
+		Short: "Manage the secrets of the application.",
+		//nolint:lll
+		Long: `Manage the secrets of the application.
+
+With this command group, you can set, get and list the secrets stored in the key vault provisioned for the environment, so the secrets of your application never live in the *.env* file of the environment. The key vault is found with the ` + output.WithBackticks(environment.KeyVaultEndpointEnvVarName) + ` or ` + output.WithBackticks(environment.KeyVaultNameEnvVarName) + ` output of your infrastructure.
+
+The secrets are exposed to a service by listing them in the ` + output.WithBackticks("secrets") + ` section of the service in the ` + output.WithBackticks(azdcontext.ProjectFileName) + ` file.`,
+	}
+
+	root.Flags().BoolP("help", "h", false, fmt.Sprintf("Gets help for %s.", root.Name()))
+	root.AddCommand(BuildCmd(rootOptions, secretsSetCmdDesign, initSecretsSetAction,
+		&buildOptions{middleware: []middleware.Middleware{requireProject(), requireAzCli(), requireLogin()}}))
+	root.AddCommand(BuildCmd(rootOptions, secretsGetCmdDesign, initSecretsGetAction,
+		&buildOptions{middleware: []middleware.Middleware{requireProject(), requireAzCli(), requireLogin()}}))
+	root.AddCommand(BuildCmd(rootOptions, secretsListCmdDesign, initSecretsListAction,
+		&buildOptions{middleware: []middleware.Middleware{requireProject(), requireAzCli(), requireLogin()}}))
+
+	return root
+}
+
+func secretsSetCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *struct{}) {
+	cmd := &cobra.Command{
+		Use:   "set <name> [<value>]",
+		Short: "Set the value of a secret.",
+		//nolint:lll
+		Long: `Set the value of a secret.
+
+When the value is omitted, it is read from the standard input, which keeps it out of the history of your shell.
+
+Examples:
+
+	$ azd secrets set db-password
+	$ cat ./certificate.pem | azd secrets set tls-certificate`,
+	}
+	cmd.Args = cobra.RangeArgs(1, 2)
+	return cmd, &struct{}{}
+}
+
+type secretsSetAction struct {
+	azdCtx  *azdcontext.AzdContext
+	azCli   azcli.AzCli
+	console input.Console
+	global  *internal.GlobalCommandOptions
+	args    []string
+}
+
+func newSecretsSetAction(
+	azdCtx *azdcontext.AzdContext,
+	azCli azcli.AzCli,
+	console input.Console,
+	global *internal.GlobalCommandOptions,
+	args []string,
+) *secretsSetAction {
+	return &secretsSetAction{
+		azdCtx:  azdCtx,
+		azCli:   azCli,
+		console: console,
+		global:  global,
+		args:    args,
+	}
+}
+
+func (s *secretsSetAction) Run(ctx context.Context) error {
+	if err := ensureValidSecretName(s.args[0]); err != nil {
+		return err
+	}
+
+	vaultEndpoint, err := environmentKeyVault(ctx, s.global, s.azdCtx, s.console)
+	if err != nil {
+		return err
+	}
+
+	var value string
+	if len(s.args) == 2 {
+		value = s.args[1]
+	} else {
+		s.console.Message(ctx, fmt.Sprintf("Reading the value of secret %s from the standard input...", s.args[0]))
+
+		content, err := io.ReadAll(s.console.Handles().Stdin)
+		if err != nil {
+			return fmt.Errorf("reading secret value: %w", err)
+		}
+
+		value = strings.TrimSuffix(strings.TrimSuffix(string(content), "\n"), "\r")
+	}
+
+	if value == "" {
+		return errors.New("the value of a secret can't be empty")
+	}
+
+	if err := s.azCli.SetKeyVaultSecret(ctx, vaultEndpoint, s.args[0], value); err != nil {
+		return fmt.Errorf("setting secret '%s': %w", s.args[0], err)
+	}
+
+	return nil
+}
+
+func secretsGetCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *struct{}) {
+	cmd := &cobra.Command{
+		Use:   "get <name>",
+		Short: "Get the value of a secret.",
+	}
+	cmd.Args = cobra.ExactArgs(1)
+	return cmd, &struct{}{}
+}
+
+type secretsGetAction struct {
+	azdCtx  *azdcontext.AzdContext
+	azCli   azcli.AzCli
+	console input.Console
+	writer  io.Writer
+	global  *internal.GlobalCommandOptions
+	args    []string
+}
+
+func newSecretsGetAction(
+	azdCtx *azdcontext.AzdContext,
+	azCli azcli.AzCli,
+	console input.Console,
+	writer io.Writer,
+	global *internal.GlobalCommandOptions,
+	args []string,
+) *secretsGetAction {
+	return &secretsGetAction{
+		azdCtx:  azdCtx,
+		azCli:   azCli,
+		console: console,
+		writer:  writer,
+		global:  global,
+		args:    args,
+	}
+}
+
+func (s *secretsGetAction) Run(ctx context.Context) error {
+	if err := ensureValidSecretName(s.args[0]); err != nil {
+		return err
+	}
+
+	vaultEndpoint, err := environmentKeyVault(ctx, s.global, s.azdCtx, s.console)
+	if err != nil {
+		return err
+	}
+
+	secret, err := s.azCli.GetKeyVaultSecret(ctx, vaultEndpoint, s.args[0])
+	if errors.Is(err, azcli.ErrAzCliSecretNotFound) {
+		return fmt.Errorf("secret '%s' doesn't exist", s.args[0])
+	} else if err != nil {
+		return fmt.Errorf("getting secret '%s': %w", s.args[0], err)
+	}
+
+	fmt.Fprintln(s.writer, secret.Value)
+	return nil
+}
+
+func secretsListCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *struct{}) {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the names of the secrets.",
+		Aliases: []string{"ls"},
+	}
+	output.AddOutputParam(
+		cmd,
+		[]output.Format{output.JsonFormat, output.TableFormat},
+		output.TableFormat,
+	)
+	return cmd, &struct{}{}
+}
+
+type secretsListAction struct {
+	azdCtx    *azdcontext.AzdContext
+	azCli     azcli.AzCli
+	console   input.Console
+	formatter output.Formatter
+	writer    io.Writer
+	global    *internal.GlobalCommandOptions
+}
+
+func newSecretsListAction(
+	azdCtx *azdcontext.AzdContext,
+	azCli azcli.AzCli,
+	console input.Console,
+	formatter output.Formatter,
+	writer io.Writer,
+	global *internal.GlobalCommandOptions,
+) *secretsListAction {
+	return &secretsListAction{
+		azdCtx:    azdCtx,
+		azCli:     azCli,
+		console:   console,
+		formatter: formatter,
+		writer:    writer,
+		global:    global,
+	}
+}
+
+type secretListItem struct {
+	Name string `json:"name"`
+}
+
+func (s *secretsListAction) Run(ctx context.Context) error {
+	vaultEndpoint, err := environmentKeyVault(ctx, s.global, s.azdCtx, s.console)
+	if err != nil {
+		return err
+	}
+
+	names, err := s.azCli.ListKeyVaultSecrets(ctx, vaultEndpoint)
+	if err != nil {
+		return fmt.Errorf("listing secrets: %w", err)
+	}
+
+	sort.Strings(names)
+	secrets := make([]secretListItem, 0, len(names))
+	for _, name := range names {
+		secrets = append(secrets, secretListItem{Name: name})
+	}
+
+	if s.formatter.Kind() == output.TableFormat {
+		err = s.formatter.Format(secrets, s.writer, output.TableFormatterOptions{
+			Columns: []output.Column{
+				{
+					Heading:       "NAME",
+					ValueTemplate: "{{.Name}}",
+				},
+			},
+		})
+	} else {
+		err = s.formatter.Format(secrets, s.writer, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func ensureValidSecretName(name string) error {
+	if !secretNameRegexp.MatchString(name) {
+		return fmt.Errorf(
+			"invalid secret name '%s', the name of a secret can only contain letters, digits and hyphens", name)
+	}
+
+	return nil
+}
+
+// environmentKeyVault returns the endpoint of the key vault provisioned for the current environment
+func environmentKeyVault(
+	ctx context.Context,
+	global *internal.GlobalCommandOptions,
+	azdCtx *azdcontext.AzdContext,
+	console input.Console,
+) (string, error) {
+	//lint:ignore SA4006 // We want ctx overridden here for future changes
+	env, ctx, err := loadOrInitEnvironment(ctx, &global.EnvironmentName, azdCtx, console) //nolint:ineffassign,staticcheck
+	if err != nil {
+		return "", fmt.Errorf("loading environment: %w", err)
+	}
+
+	endpoint := env.GetKeyVaultEndpoint()
+	if endpoint == "" {
+		return "", fmt.Errorf(
+			"the environment has no key vault, ensure %s is set as an output of your infrastructure and run `azd provision`",
+			environment.KeyVaultEndpointEnvVarName,
+		)
+	}
+
+	return endpoint, nil
+}
@@ -0,0 +1,270 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/serve"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// The protocols of azd serve
+const (
+	serveProtocolGrpc    = "grpc"
+	serveProtocolJsonRpc = "json-rpc"
+)
+
+type serveFlags struct {
+	port     int
+	protocol string
+	global   *internal.GlobalCommandOptions
+}
+
+func (s *serveFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.IntVar(&s.port, "port", 0, "The local port to listen on. A free port is picked when not set.")
+	local.StringVar(
+		&s.protocol,
+		"protocol",
+		serveProtocolGrpc,
+		fmt.Sprintf("The protocol to serve the operations with: %s or %s.", serveProtocolGrpc, serveProtocolJsonRpc),
+	)
+	s.global = global
+}
+
+func serveCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *serveFlags) {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve azd operations to editors and GUI frontends over a local gRPC or JSON-RPC interface.",
+		//nolint:lll
+		Long: `Serve azd operations to editors and GUI frontends over a local gRPC or JSON-RPC interface.
+
+The server listens on 127.0.0.1 and prints a token generated for the session. Clients authenticate with the token, the operations act on the Azure resources of the signed in user. The methods are:
+
+  env/list          Lists the environments of the project.
+  provision         Provisions the Azure resources of an environment. Params: {"environment"}.
+  deploy            Deploys the application to an environment. Params: {"environment", "service"}.
+  pipeline/config   Configures the deployment pipeline of an environment. Params: {"environment", "provider"}.
+
+With the gRPC protocol, each method is a server streaming method of the azd.Serve service named in Pascal case, i.e. /azd.Serve/EnvList. The request is a google.protobuf.Struct holding the params, and the responses are google.protobuf.Struct messages: {"progress"} with each line of output, then {"result"}. Calls carry the token in the "authorization" metadata, as "Bearer <token>", and an operation is canceled by canceling its call.
+
+With the JSON-RPC protocol, the server exchanges JSON-RPC 2.0 messages, one per line. The first request of a connection is "authenticate", with params {"token"}. The output of an operation is streamed as "progress" notifications carrying the id of its request, and an operation is canceled with the "cancel" notification, with params {"id"}.
+
+Operations run without prompting.`,
+	}
+
+	flags := &serveFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return cmd, flags
+}
+
+type serveAction struct {
+	flags   serveFlags
+	azdCtx  *azdcontext.AzdContext
+	console input.Console
+}
+
+func newServeAction(flags serveFlags, azdCtx *azdcontext.AzdContext, console input.Console) *serveAction {
+	return &serveAction{
+		flags:   flags,
+		azdCtx:  azdCtx,
+		console: console,
+	}
+}
+
+// The environment an operation runs in, the default environment when not set
+type serveEnvironmentParams struct {
+	Environment string `json:"environment,omitempty"`
+}
+
+type serveDeployParams struct {
+	serveEnvironmentParams
+	Service string `json:"service,omitempty"`
+}
+
+type servePipelineConfigParams struct {
+	serveEnvironmentParams
+	Provider string `json:"provider,omitempty"`
+}
+
+type serveEnvironment struct {
+	Name       string `json:"name"`
+	IsDefault  bool   `json:"isDefault"`
+	IsSelected bool   `json:"isSelected"`
+}
+
+// The result of the operations run by a child azd process
+type serveOperationResult struct {
+	ExitCode int `json:"exitCode"`
+}
+
+func (s *serveAction) Run(ctx context.Context) error {
+	if s.flags.protocol != serveProtocolGrpc && s.flags.protocol != serveProtocolJsonRpc {
+		return fmt.Errorf(
+			"unsupported protocol '%s', the protocol must be %s or %s",
+			s.flags.protocol,
+			serveProtocolGrpc,
+			serveProtocolJsonRpc,
+		)
+	}
+
+	token, err := serve.NewToken()
+	if err != nil {
+		return err
+	}
+
+	server := serve.NewServer(token)
+	server.Handle("env/list", s.listEnvironments)
+	server.Handle("provision", func(ctx context.Context, raw json.RawMessage, progress func(string)) (any, error) {
+		var params serveEnvironmentParams
+		if err := unmarshalServeParams(raw, &params); err != nil {
+			return nil, err
+		}
+
+		return s.runAzd(ctx, progress, params.Environment, "provision")
+	})
+	server.Handle("deploy", func(ctx context.Context, raw json.RawMessage, progress func(string)) (any, error) {
+		var params serveDeployParams
+		if err := unmarshalServeParams(raw, &params); err != nil {
+			return nil, err
+		}
+
+		args := []string{"deploy"}
+		if params.Service != "" {
+			args = append(args, "--service", params.Service)
+		}
+
+		return s.runAzd(ctx, progress, params.Environment, args...)
+	})
+	server.Handle("pipeline/config", func(ctx context.Context, raw json.RawMessage, progress func(string)) (any, error) {
+		var params servePipelineConfigParams
+		if err := unmarshalServeParams(raw, &params); err != nil {
+			return nil, err
+		}
+
+		args := []string{"pipeline", "config"}
+		if params.Provider != "" {
+			args = append(args, "--provider", params.Provider)
+		}
+
+		return s.runAzd(ctx, progress, params.Environment, args...)
+	})
+
+	// Only local clients are served, and only those given the token, since any local user can connect to the port
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", s.flags.port))
+	if err != nil {
+		return fmt.Errorf("listening: %w", err)
+	}
+
+	s.console.Message(ctx, fmt.Sprintf(
+		"Serving %s on %s", s.flags.protocol, output.WithHighLightFormat(listener.Addr().String())))
+	s.console.Message(ctx, fmt.Sprintf("Token: %s", token))
+	s.console.Message(ctx, "Press Ctrl+C to stop.")
+
+	if s.flags.protocol == serveProtocolGrpc {
+		return server.ServeGrpc(ctx, listener)
+	}
+
+	return server.Serve(ctx, listener)
+}
+
+func (s *serveAction) listEnvironments(ctx context.Context, _ json.RawMessage, _ func(string)) (any, error) {
+	envs, err := s.azdCtx.ListEnvironments()
+	if err != nil {
+		return nil, fmt.Errorf("listing environments: %w", err)
+	}
+
+	result := make([]serveEnvironment, 0, len(envs))
+	for _, env := range envs {
+		result = append(result, serveEnvironment{Name: env.Name, IsDefault: env.IsDefault, IsSelected: env.IsSelected})
+	}
+
+	return result, nil
+}
+
+// runAzd runs an azd command in a child process, reporting each line of its output as progress. The operations run in
+// their own process so that concurrent requests don't share the state of a command.
+func (s *serveAction) runAzd(
+	ctx context.Context,
+	progress func(string),
+	environmentName string,
+	args ...string,
+) (any, error) {
+	azdPath, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("finding the azd executable: %w", err)
+	}
+
+	args = append(args, "--no-prompt", "--cwd", s.azdCtx.ProjectDirectory())
+	if environmentName != "" {
+		args = append(args, "--environment", environmentName)
+	}
+
+	reader, writer := io.Pipe()
+	defer reader.Close()
+
+	cmd := exec.CommandContext(ctx, azdPath, args...)
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting azd: %w", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			if line := scanner.Text(); line != "" {
+				progress(line)
+			}
+		}
+	}()
+
+	err = cmd.Wait()
+	writer.Close()
+	<-done
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && ctx.Err() == nil {
+		return nil, &serve.Error{
+			Code:    serve.OperationFailedCode,
+			Message: fmt.Sprintf("azd %s failed with exit code %d", args[0], exitErr.ExitCode()),
+		}
+	} else if ctx.Err() != nil {
+		return nil, fmt.Errorf("azd %s canceled: %w", args[0], ctx.Err())
+	} else if err != nil {
+		return nil, fmt.Errorf("running azd: %w", err)
+	}
+
+	return serveOperationResult{ExitCode: 0}, nil
+}
+
+func unmarshalServeParams(raw json.RawMessage, params any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(raw, params); err != nil {
+		return serve.NewInvalidParamsError(err)
+	}
+
+	return nil
+}
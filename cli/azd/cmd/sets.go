@@ -8,17 +8,20 @@ import (
 	"os"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/extensions"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/identity"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/templates"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/devtunnel"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
 	"github.com/google/wire"
 	"github.com/mattn/go-colorable"
@@ -67,10 +70,18 @@ func newConsoleFromOptions(
 		Stdin:  cmd.InOrStdin(),
 		Stdout: cmd.OutOrStdout(),
 		Stderr: cmd.ErrOrStderr(),
-	}, formatter)
+	}, formatter, input.NewVerbosity(rootOptions.Quiet, rootOptions.Verbose))
 }
 
 func newCommandRunnerFromConsole(console input.Console) exec.CommandRunner {
+	if console.Verbosity() == input.VerbosityVerbose {
+		return exec.NewVerboseCommandRunner(
+			console.Handles().Stdin,
+			console.Handles().Stdout,
+			console.Handles().Stderr,
+		)
+	}
+
 	return exec.NewCommandRunner(
 		console.Handles().Stdin,
 		console.Handles().Stdout,
@@ -82,12 +93,14 @@ func newAzCliFromOptions(
 	rootOptions *internal.GlobalCommandOptions,
 	cmdRun exec.CommandRunner,
 	credential azcore.TokenCredential,
+	configManager config.Manager,
 ) azcli.AzCli {
 	return azcli.NewAzCli(credential, azcli.NewAzCliArgs{
 		EnableDebug:     rootOptions.EnableDebugLogging,
 		EnableTelemetry: rootOptions.EnableTelemetry,
 		CommandRunner:   cmdRun,
 		HttpClient:      nil,
+		ClientPolicies:  httputil.LoadClientPolicies(configManager),
 	})
 }
 
@@ -101,12 +114,8 @@ func newAzdContext() (*azdcontext.AzdContext, error) {
 }
 
 func newCredential() (azcore.TokenCredential, error) {
-	credential, err := azidentity.NewAzureCLICredential(nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to obtain Azure credentials: %w", err)
-	}
-
-	return credential, nil
+	// The credential is shared with the clients created from the context, so they all use the selected tenant
+	return identity.DefaultCredential(), nil
 }
 
 var FormattedConsoleSet = wire.NewSet(
@@ -141,6 +150,11 @@ var InfraCreateCmdSet = wire.NewSet(
 	newInfraCreateAction,
 	wire.Bind(new(actions.Action), new(*infraCreateAction)))
 
+var InfraParamsInitCmdSet = wire.NewSet(
+	CommonSet,
+	newInfraParamsInitAction,
+	wire.Bind(new(actions.Action), new(*infraParamsInitAction)))
+
 var InfraDeleteCmdSet = wire.NewSet(
 	CommonSet,
 	AzCliSet,
@@ -171,10 +185,20 @@ var EnvSetCmdSet = wire.NewSet(
 	wire.Bind(new(actions.Action), new(*envSetAction)))
 
 var EnvSelectCmdSet = wire.NewSet(
-	newAzdContext,
+	CommonSet,
 	newEnvSelectAction,
 	wire.Bind(new(actions.Action), new(*envSelectAction)))
 
+var EnvSetPolicyCmdSet = wire.NewSet(
+	CommonSet,
+	newEnvSetPolicyAction,
+	wire.Bind(new(actions.Action), new(*envSetPolicyAction)))
+
+var EnvSetTtlCmdSet = wire.NewSet(
+	CommonSet,
+	newEnvSetTtlAction,
+	wire.Bind(new(actions.Action), new(*envSetTtlAction)))
+
 var EnvListCmdSet = wire.NewSet(
 	CommonSet,
 	newEnvListAction,
@@ -198,6 +222,30 @@ var EnvGetValuesCmdSet = wire.NewSet(
 	newEnvGetValuesAction,
 	wire.Bind(new(actions.Action), new(*envGetValuesAction)))
 
+var EnvPullConfigCmdSet = wire.NewSet(
+	CommonSet,
+	AzCliSet,
+	newEnvPullConfigAction,
+	wire.Bind(new(actions.Action), new(*envPullConfigAction)))
+
+var SecretsSetCmdSet = wire.NewSet(
+	CommonSet,
+	AzCliSet,
+	newSecretsSetAction,
+	wire.Bind(new(actions.Action), new(*secretsSetAction)))
+
+var SecretsGetCmdSet = wire.NewSet(
+	CommonSet,
+	AzCliSet,
+	newSecretsGetAction,
+	wire.Bind(new(actions.Action), new(*secretsGetAction)))
+
+var SecretsListCmdSet = wire.NewSet(
+	CommonSet,
+	AzCliSet,
+	newSecretsListAction,
+	wire.Bind(new(actions.Action), new(*secretsListAction)))
+
 var LoginCmdSet = wire.NewSet(
 	CommonSet,
 	AzCliSet,
@@ -216,6 +264,12 @@ var PipelineConfigCmdSet = wire.NewSet(
 	newPipelineConfigAction,
 	wire.Bind(new(actions.Action), new(*pipelineConfigAction)))
 
+var PipelineListPrincipalsCmdSet = wire.NewSet(
+	CommonSet,
+	AzCliSet,
+	newPipelineListPrincipalsAction,
+	wire.Bind(new(actions.Action), new(*pipelineListPrincipalsAction)))
+
 var RestoreCmdSet = wire.NewSet(
 	CommonSet,
 	newRestoreAction,
@@ -238,11 +292,62 @@ var TemplatesShowCmdSet = wire.NewSet(
 	templates.NewTemplateManager,
 	wire.Bind(new(actions.Action), new(templatesShowAction)))
 
+var TemplatesUpdateCmdSet = wire.NewSet(
+	CommonSet,
+	git.NewGitCliFromRunner,
+	newTemplatesUpdateAction,
+	wire.Bind(new(actions.Action), new(*templatesUpdateAction)))
+
+var DoctorCmdSet = wire.NewSet(
+	CommonSet,
+	AzCliSet,
+	git.NewGitCliFromRunner,
+	newDoctorAction,
+	wire.Bind(new(actions.Action), new(*doctorAction)))
+
+var CleanupCmdSet = wire.NewSet(
+	CommonSet,
+	AzCliSet,
+	git.NewGitCliFromRunner,
+	newCleanupAction,
+	wire.Bind(new(actions.Action), new(*cleanupAction)))
+
 var VersionCmdSet = wire.NewSet(
 	CommonSet,
 	newVersionAction,
 	wire.Bind(new(actions.Action), new(*versionAction)))
 
+var UpgradeCmdSet = wire.NewSet(
+	CommonSet,
+	newUpgradeAction,
+	wire.Bind(new(actions.Action), new(*upgradeAction)))
+
+var PackageCmdSet = wire.NewSet(
+	CommonSet,
+	newPackageAction,
+	wire.Bind(new(actions.Action), new(*packageAction)))
+
+var LogsCmdSet = wire.NewSet(
+	CommonSet,
+	newLogsAction,
+	wire.Bind(new(actions.Action), new(*logsAction)))
+
+var ExecCmdSet = wire.NewSet(
+	CommonSet,
+	newExecAction,
+	wire.Bind(new(actions.Action), new(*execAction)))
+
+var RunCmdSet = wire.NewSet(
+	CommonSet,
+	newRunAction,
+	devtunnel.NewDevTunnelCli,
+	wire.Bind(new(actions.Action), new(*runAction)))
+
+var ServeCmdSet = wire.NewSet(
+	CommonSet,
+	newServeAction,
+	wire.Bind(new(actions.Action), new(*serveAction)))
+
 var ConfigListCmdSet = wire.NewSet(
 	CommonSet,
 	newConfigListAction,
@@ -267,3 +372,26 @@ var ConfigResetCmdSet = wire.NewSet(
 	CommonSet,
 	newConfigResetAction,
 	wire.Bind(new(actions.Action), new(*configResetAction)))
+
+var ExtensionInstallCmdSet = wire.NewSet(
+	CommonSet,
+	extensions.NewManager,
+	newExtensionInstallAction,
+	wire.Bind(new(actions.Action), new(*extensionInstallAction)))
+
+var ExtensionListCmdSet = wire.NewSet(
+	CommonSet,
+	extensions.NewManager,
+	newExtensionListAction,
+	wire.Bind(new(actions.Action), new(*extensionListAction)))
+
+var ExtensionUninstallCmdSet = wire.NewSet(
+	CommonSet,
+	extensions.NewManager,
+	newExtensionUninstallAction,
+	wire.Bind(new(actions.Action), new(*extensionUninstallAction)))
+
+var ExtensionRunCmdSet = wire.NewSet(
+	CommonSet,
+	newExtensionRunAction,
+	wire.Bind(new(actions.Action), new(*extensionRunAction)))
@@ -7,9 +7,11 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/commands/pipeline"
 	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
@@ -102,6 +104,30 @@ func (s *showAction) Run(ctx context.Context) error {
 		res.Services[name] = showSvc
 	}
 
+	summary, err := pipeline.LoadSummary(s.azdCtx, env)
+	if err != nil {
+		log.Printf("ignoring error loading pipeline summary for environment %s: %v", env.GetEnvName(), err)
+	} else if summary != nil {
+		res.Pipeline = &contracts.ShowPipeline{
+			Provider:              summary.Provider,
+			ProjectUrl:            summary.ProjectUrl,
+			RepositoryUrl:         summary.RepositoryUrl,
+			PipelineUrl:           summary.PipelineUrl,
+			ServiceConnectionName: summary.ServiceConnectionName,
+			ServicePrincipalAppId: summary.ServicePrincipalAppId,
+			Secrets:               summary.Secrets,
+		}
+
+		for _, stage := range summary.Stages {
+			res.Pipeline.Stages = append(res.Pipeline.Stages, contracts.ShowPipelineStage{
+				Name:                  stage.Name,
+				Environment:           stage.Environment,
+				ServicePrincipalAppId: stage.ServicePrincipalAppId,
+				ServiceConnectionName: stage.ServiceConnectionName,
+			})
+		}
+	}
+
 	// Add information about the target of each service, if we can determine it (if the infrastructure has
 	// not been deployed, for example, we'll just not include target information)
 	resourceManager := infra.NewAzureResourceManager(ctx)
@@ -129,9 +155,65 @@ func (s *showAction) Run(ctx context.Context) error {
 			err)
 	}
 
+	if s.formatter.Kind() == output.NoneFormat {
+		s.printResult(ctx, res)
+		return nil
+	}
+
 	return s.formatter.Format(res, s.writer, nil)
 }
 
+// printResult displays the result for a person reading it, when no output format is requested
+func (s *showAction) printResult(ctx context.Context, res contracts.ShowResult) {
+	s.console.KeyValues(ctx, []input.KeyValue{{Key: "Project", Value: res.Name}})
+	s.console.Message(ctx, "")
+
+	names := make([]string, 0, len(res.Services))
+	for name := range res.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rows := make([][]string, 0, len(names))
+	for _, name := range names {
+		svc := res.Services[name]
+		resources := ""
+		if svc.Target != nil {
+			resources = strings.Join(svc.Target.ResourceIds, ", ")
+		}
+
+		rows = append(rows, []string{name, string(svc.Project.Type), svc.Project.Path, resources})
+	}
+	s.console.Table(ctx, []string{"SERVICE", "TYPE", "PATH", "RESOURCES"}, rows)
+
+	if res.Pipeline == nil {
+		return
+	}
+
+	values := []input.KeyValue{{Key: "Provider", Value: res.Pipeline.Provider}}
+	for _, value := range []input.KeyValue{
+		{Key: "Project", Value: res.Pipeline.ProjectUrl},
+		{Key: "Repository", Value: res.Pipeline.RepositoryUrl},
+		{Key: "Pipeline", Value: res.Pipeline.PipelineUrl},
+		{Key: "Service connection", Value: res.Pipeline.ServiceConnectionName},
+		{Key: "Service principal", Value: res.Pipeline.ServicePrincipalAppId},
+	} {
+		if value.Value != "" {
+			values = append(values, value)
+		}
+	}
+	for _, stage := range res.Pipeline.Stages {
+		values = append(values, input.KeyValue{
+			Key:   "Stage " + stage.Name,
+			Value: fmt.Sprintf("environment %s, service principal %s", stage.Environment, stage.ServicePrincipalAppId),
+		})
+	}
+
+	s.console.Message(ctx, "")
+	s.console.Message(ctx, output.WithHighLightFormat("Pipeline:"))
+	s.console.KeyValues(ctx, values)
+}
+
 func showTypeFromLanguage(language string) contracts.ShowType {
 	switch language {
 	case "dotnet":
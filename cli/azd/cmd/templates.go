@@ -5,14 +5,25 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
 	"github.com/azure/azure-dev/cli/azd/pkg/templates"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -25,6 +36,7 @@ func templatesCmd(rootOptions *internal.GlobalCommandOptions) *cobra.Command {
 
 	root.AddCommand(BuildCmd(rootOptions, templatesListCmdDesign, initTemplatesListAction, nil))
 	root.AddCommand(BuildCmd(rootOptions, templatesShowCmdDesign, initTemplatesShowAction, nil))
+	root.AddCommand(BuildCmd(rootOptions, templatesUpdateCmdDesign, initTemplatesUpdateAction, nil))
 	root.Flags().BoolP("help", "h", false, fmt.Sprintf("Gets help for %s.", root.Name()))
 
 	return root
@@ -119,9 +131,293 @@ func templatesShowCmdDesign(rootOptions *internal.GlobalCommandOptions) (*cobra.
 	output.AddOutputParam(cmd, []output.Format{output.JsonFormat, output.TableFormat}, output.TableFormat)
 
 	cmd.Args = cobra.ExactArgs(1)
+	cmd.ValidArgsFunction = completeTemplateNameArg
 	return cmd, &struct{}{}
 }
 
+type templatesUpdateFlags struct {
+	global *internal.GlobalCommandOptions
+}
+
+func (tu *templatesUpdateFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	tu.global = global
+}
+
+func templatesUpdateCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *templatesUpdateFlags) {
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update the infrastructure and pipelines of the project from the latest version of its template.",
+		Long: `Update the infrastructure and pipelines of the project from the latest version of its template.
+
+The upstream changes since the version of the template recorded in azure.yaml by ` +
+			output.WithBackticks("azd init --template") + ` are compared to the project. ` +
+			`The files unchanged in the project are updated, and the changes of the files changed both upstream and in the ` +
+			`project are merged with your confirmation.`,
+	}
+
+	flags := &templatesUpdateFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return cmd, flags
+}
+
+// The choices for a file changed both upstream and in the project
+const (
+	templateMergeChoice     = "Merge the template changes into the local file"
+	templateReplaceChoice   = "Replace the local file with the template version"
+	templateKeepLocalChoice = "Keep the local file"
+)
+
+type templatesUpdateAction struct {
+	flags   templatesUpdateFlags
+	azdCtx  *azdcontext.AzdContext
+	console input.Console
+	gitCli  git.GitCli
+}
+
+func newTemplatesUpdateAction(
+	flags templatesUpdateFlags,
+	azdCtx *azdcontext.AzdContext,
+	console input.Console,
+	gitCli git.GitCli,
+) *templatesUpdateAction {
+	return &templatesUpdateAction{
+		flags:   flags,
+		azdCtx:  azdCtx,
+		console: console,
+		gitCli:  gitCli,
+	}
+}
+
+func (tu *templatesUpdateAction) Run(ctx context.Context) error {
+	if err := tools.EnsureInstalled(ctx, tu.gitCli); err != nil {
+		return err
+	}
+
+	prj, err := project.LoadProjectConfig(tu.azdCtx.ProjectPath(), environment.Ephemeral())
+	if err != nil {
+		return fmt.Errorf("loading project: %w", err)
+	}
+
+	if prj.Metadata == nil || prj.Metadata.Source == nil || prj.Metadata.Source.Commit == "" {
+		return fmt.Errorf(
+			"%s doesn't record the template of the project, only projects initialized with %s can be updated",
+			azdcontext.ProjectFileName,
+			output.WithBackticks("azd init --template"),
+		)
+	}
+
+	source := *prj.Metadata.Source
+	latestCommit, err := tu.gitCli.GetRemoteCommit(ctx, source.Repository, source.Branch)
+	if err != nil {
+		return fmt.Errorf("getting the latest version of the template: %w", err)
+	}
+
+	if latestCommit == source.Commit {
+		tu.console.Message(ctx, fmt.Sprintf(
+			"The project is up to date with the template %s.", output.WithHighLightFormat(source.Repository)))
+		return nil
+	}
+
+	basePath, err := os.MkdirTemp("", "az-dev-template")
+	if err != nil {
+		return fmt.Errorf("creating temp folder: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(basePath)
+	}()
+
+	latestPath, err := os.MkdirTemp("", "az-dev-template")
+	if err != nil {
+		return fmt.Errorf("creating temp folder: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(latestPath)
+	}()
+
+	spinner := input.NewSpinner(tu.console, "Downloading template")
+	err = spinner.Run(func() error {
+		if err := tu.gitCli.FetchCommit(ctx, source.Repository, source.Commit, basePath); err != nil {
+			return err
+		}
+
+		return tu.gitCli.FetchCommit(ctx, source.Repository, latestCommit, latestPath)
+	})
+	if err != nil {
+		return fmt.Errorf("fetching template: %w", err)
+	}
+
+	projectPath := tu.azdCtx.ProjectDirectory()
+	changes, err := templates.DiffTemplate(
+		basePath, latestPath, projectPath, templates.TemplateUpdatePaths(prj.Infra.Path))
+	if err != nil {
+		return fmt.Errorf("comparing the template to the project: %w", err)
+	}
+
+	var unresolved []string
+	if len(changes) > 0 {
+		unresolved, err = tu.applyChanges(ctx, changes, basePath, latestPath, projectPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	source.Commit = latestCommit
+	if err := project.SaveTemplateSource(tu.azdCtx.ProjectPath(), source); err != nil {
+		return fmt.Errorf("recording template source: %w", err)
+	}
+
+	message := fmt.Sprintf(
+		"Updated the project to the version %s of the template %s.",
+		output.WithHighLightFormat(latestCommit),
+		output.WithHighLightFormat(source.Repository),
+	)
+	if len(unresolved) > 0 {
+		message += fmt.Sprintf(
+			"\nResolve the conflicts marked in the following files:\n - %s", strings.Join(unresolved, "\n - "))
+	}
+	tu.console.Message(ctx, message)
+
+	return nil
+}
+
+// applyChanges applies the upstream changes to the project, after confirmation. Returns the files merged with conflicts.
+func (tu *templatesUpdateAction) applyChanges(
+	ctx context.Context,
+	changes []templates.TemplateFileChange,
+	basePath string,
+	latestPath string,
+	projectPath string,
+) ([]string, error) {
+	var builder strings.Builder
+	builder.WriteString("The template changed the following files:\n")
+	for _, change := range changes {
+		builder.WriteString(fmt.Sprintf("  %-8s  %s\n", change.Status, change.Path))
+	}
+	tu.console.Message(ctx, builder.String())
+
+	var conflicts []templates.TemplateFileChange
+	var updates []templates.TemplateFileChange
+	for _, change := range changes {
+		if change.Status == templates.TemplateFileConflict {
+			conflicts = append(conflicts, change)
+		} else {
+			updates = append(updates, change)
+		}
+	}
+
+	if len(updates) > 0 {
+		apply, err := tu.console.Confirm(ctx, input.ConsoleOptions{
+			Message:      fmt.Sprintf("Apply the %d changes that don't conflict with changes of the project?", len(updates)),
+			DefaultValue: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("prompting to apply template changes: %w", err)
+		}
+
+		if apply {
+			for _, update := range updates {
+				if err := applyTemplateFile(update.Path, latestPath, projectPath); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	var unresolved []string
+	for _, conflict := range conflicts {
+		choice, err := tu.console.Select(ctx, input.ConsoleOptions{
+			Message:      fmt.Sprintf("%s was changed by the template and in the project:", conflict.Path),
+			Options:      []string{templateMergeChoice, templateReplaceChoice, templateKeepLocalChoice},
+			DefaultValue: templateMergeChoice,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("prompting to merge template changes: %w", err)
+		}
+
+		switch choice {
+		case 0:
+			conflicted, err := tu.mergeTemplateFile(ctx, conflict.Path, basePath, latestPath, projectPath)
+			if err != nil {
+				return nil, err
+			}
+
+			if conflicted {
+				unresolved = append(unresolved, conflict.Path)
+			}
+		case 1:
+			if err := applyTemplateFile(conflict.Path, latestPath, projectPath); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return unresolved, nil
+}
+
+// mergeTemplateFile merges the upstream changes of a file into the project file, a file deleted in the template or in
+// the project merges as an empty file. Returns whether the merged file has conflicts.
+func (tu *templatesUpdateAction) mergeTemplateFile(
+	ctx context.Context,
+	path string,
+	basePath string,
+	latestPath string,
+	projectPath string,
+) (bool, error) {
+	paths := map[string]string{
+		"base":   filepath.Join(basePath, path),
+		"latest": filepath.Join(latestPath, path),
+		"local":  filepath.Join(projectPath, path),
+	}
+
+	for _, filePath := range paths {
+		if _, err := os.Stat(filePath); errors.Is(err, os.ErrNotExist) {
+			if err := os.MkdirAll(filepath.Dir(filePath), osutil.PermissionDirectory); err != nil {
+				return false, fmt.Errorf("creating directory: %w", err)
+			}
+
+			if err := os.WriteFile(filePath, nil, osutil.PermissionFile); err != nil {
+				return false, fmt.Errorf("creating empty file: %w", err)
+			}
+		}
+	}
+
+	merged, conflicted, err := tu.gitCli.MergeFile(ctx, paths["local"], paths["base"], paths["latest"])
+	if err != nil {
+		return false, err
+	}
+
+	if err := os.WriteFile(paths["local"], []byte(merged), osutil.PermissionFile); err != nil {
+		return false, fmt.Errorf("writing merged file: %w", err)
+	}
+
+	return conflicted, nil
+}
+
+// applyTemplateFile replaces the project file with the template version, or deletes it when the template deleted it
+func applyTemplateFile(path string, latestPath string, projectPath string) error {
+	contents, err := os.ReadFile(filepath.Join(latestPath, path))
+	if errors.Is(err, os.ErrNotExist) {
+		if err := os.Remove(filepath.Join(projectPath, path)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("deleting %s: %w", path, err)
+		}
+
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("reading template file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(projectPath, path)), osutil.PermissionDirectory); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(projectPath, path), contents, osutil.PermissionFile); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+
+	return nil
+}
+
 func formatTemplates(
 	ctx context.Context,
 	formatter output.Formatter,
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/cmd/middleware"
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
@@ -66,26 +68,37 @@ type upAction struct {
 	infraCreate *infraCreateAction
 	deploy      *deployAction
 	console     input.Console
+	cmd         *cobra.Command
 }
 
-func newUpAction(init *initAction, infraCreate *infraCreateAction, deploy *deployAction, console input.Console) *upAction {
+func newUpAction(
+	init *initAction,
+	infraCreate *infraCreateAction,
+	deploy *deployAction,
+	console input.Console,
+	cmd *cobra.Command,
+) *upAction {
 	return &upAction{
 		init:        init,
 		infraCreate: infraCreate,
 		deploy:      deploy,
 		console:     console,
+		cmd:         cmd,
 	}
 }
 
 func (u *upAction) Run(ctx context.Context) error {
-	err := u.runInit(ctx)
+	// Each step raises the same extension lifecycle events as the command it replaces
+	middlewareOptions := &middleware.Options{Cmd: u.cmd}
+
+	err := middleware.RunAction(ctx, middlewareOptions, actions.ActionFunc(u.runInit), initEvents())
 	if err != nil {
 		return fmt.Errorf("running init: %w", err)
 	}
 
 	finalOutput := []string{}
 	u.infraCreate.finalOutputRedirect = &finalOutput
-	err = u.infraCreate.Run(ctx)
+	err = middleware.RunAction(ctx, middlewareOptions, u.infraCreate, provisionEvents())
 	if err != nil {
 		return err
 	}
@@ -93,7 +106,7 @@ func (u *upAction) Run(ctx context.Context) error {
 	// Print an additional newline to separate provision from deploy
 	u.console.Message(ctx, "")
 
-	err = u.deploy.Run(ctx)
+	err = middleware.RunAction(ctx, middlewareOptions, u.deploy, deployEvents())
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,169 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/middleware"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/update"
+	"github.com/blang/semver/v4"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type upgradeFlags struct {
+	channel string
+	global  *internal.GlobalCommandOptions
+}
+
+func (f *upgradeFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(
+		&f.channel,
+		"channel",
+		"",
+		"The release channel to upgrade from, stable or daily. The channel is saved in the azd config as "+
+			update.ChannelConfigPath+" for the next upgrades.")
+	f.global = global
+}
+
+func upgradeCmdDesign(global *internal.GlobalCommandOptions) (*cobra.Command, *upgradeFlags) {
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade azd to its latest version.",
+		Long: `Upgrade azd to its latest version.
+
+The latest release of the channel is downloaded for the platform, then it replaces the running azd. The release is
+only installed when its checksum is signed by the release key of azd and matches the download. The stable channel is
+used unless the daily channel is selected with --channel or set in the azd config.`,
+		// The command upgrading azd doesn't warn that azd is out of date
+		Annotations: map[string]string{middleware.UpdateCheckDisabledAnnotation: "true"},
+	}
+
+	flags := &upgradeFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return cmd, flags
+}
+
+type upgradeAction struct {
+	flags         upgradeFlags
+	console       input.Console
+	configManager config.Manager
+}
+
+func newUpgradeAction(flags upgradeFlags, console input.Console, configManager config.Manager) *upgradeAction {
+	return &upgradeAction{
+		flags:         flags,
+		console:       console,
+		configManager: configManager,
+	}
+}
+
+func (u *upgradeAction) Run(ctx context.Context) error {
+	channel, err := u.channel()
+	if err != nil {
+		return err
+	}
+
+	updater := update.NewUpdater(httputil.GetHttpClient(ctx))
+
+	latestVersion, err := updater.LatestVersion(ctx, channel)
+	if err != nil {
+		return err
+	}
+
+	if latestVersion != nil {
+		currentVersion, err := semver.Parse(internal.GetVersionNumber())
+		if err != nil {
+			log.Printf("failed to parse %s as a semver, upgrading", internal.GetVersionNumber())
+		} else if currentVersion.GTE(*latestVersion) {
+			u.console.Message(ctx, fmt.Sprintf("azd is up to date, version %s is the latest version.", currentVersion))
+			return nil
+		}
+	}
+
+	executablePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("finding the azd executable: %w", err)
+	}
+	if executablePath, err = filepath.EvalSymlinks(executablePath); err != nil {
+		return fmt.Errorf("finding the azd executable: %w", err)
+	}
+
+	downloadDir, err := os.MkdirTemp("", "azd-upgrade")
+	if err != nil {
+		return fmt.Errorf("creating download folder: %w", err)
+	}
+	defer os.RemoveAll(downloadDir)
+
+	u.console.Message(ctx, fmt.Sprintf("Downloading %s", output.WithLinkFormat(updater.ArchiveUrl(channel))))
+
+	binaryPath, err := updater.Download(ctx, channel, downloadDir)
+	if err != nil {
+		return err
+	}
+
+	if err := update.Replace(binaryPath, executablePath); err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return fmt.Errorf(
+				"%w. azd is installed in a folder requiring elevated permissions, run the command again as an "+
+					"administrator or with sudo", err)
+		}
+
+		return err
+	}
+
+	if latestVersion != nil {
+		u.console.Message(ctx, output.WithSuccessFormat("azd was upgraded to version %s.", latestVersion))
+	} else {
+		u.console.Message(ctx, output.WithSuccessFormat("azd was upgraded to the latest %s build.", channel))
+	}
+
+	return nil
+}
+
+// channel returns the channel of the --channel flag, saving it in the user config, or the channel of the user config
+func (u *upgradeAction) channel() (update.Channel, error) {
+	userConfig, err := getUserConfig(u.configManager)
+	if err != nil {
+		return "", err
+	}
+
+	if u.flags.channel == "" {
+		return update.ChannelFromConfig(userConfig), nil
+	}
+
+	channel, err := update.ParseChannel(u.flags.channel)
+	if err != nil {
+		return "", err
+	}
+
+	if channel != update.ChannelFromConfig(userConfig) {
+		if err := userConfig.Set(update.ChannelConfigPath, string(channel)); err != nil {
+			return "", fmt.Errorf("setting the release channel: %w", err)
+		}
+
+		userConfigFilePath, err := config.GetUserConfigFilePath()
+		if err != nil {
+			return "", fmt.Errorf("getting user config file path: %w", err)
+		}
+
+		if err := u.configManager.Save(userConfig, userConfigFilePath); err != nil {
+			return "", fmt.Errorf("saving the release channel: %w", err)
+		}
+	}
+
+	return channel, nil
+}
@@ -7,17 +7,28 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
 	"github.com/azure/azure-dev/cli/azd/pkg/azureutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/ci"
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/identity"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning/bicep"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 )
 
@@ -25,6 +36,7 @@ type Asker func(p survey.Prompt, response interface{}) error
 
 const (
 	manualSubscriptionEntryOption = "Other (enter manually)"
+	switchTenantOption            = "Switch to another tenant"
 )
 
 func invalidEnvironmentNameMsg(environmentName string) string {
@@ -60,6 +72,39 @@ type environmentSpec struct {
 	environmentName string
 	subscription    string
 	location        string
+	// The types of the resources the infrastructure of the project deploys, only the locations where all of them
+	// are available are offered
+	resourceTypes []string
+}
+
+// projectResourceTypes returns the types of the resources the Bicep infrastructure of the project deploys, or nil
+// when they can't be determined
+func projectResourceTypes(azdCtx *azdcontext.AzdContext) []string {
+	prj, err := project.LoadProjectConfig(azdCtx.ProjectPath(), environment.Ephemeral())
+	if err != nil {
+		log.Printf("failed loading project to list its resource types: %v", err)
+		return nil
+	}
+
+	if prj.Infra.Provider != "" && prj.Infra.Provider != provisioning.Bicep {
+		return nil
+	}
+
+	infraPath := prj.Infra.Path
+	if infraPath == "" {
+		infraPath = "infra"
+	}
+	if !filepath.IsAbs(infraPath) {
+		infraPath = filepath.Join(prj.Path, infraPath)
+	}
+
+	resourceTypes, err := bicep.ResourceTypes(infraPath)
+	if err != nil {
+		log.Printf("failed listing resource types of '%s': %v", infraPath, err)
+		return nil
+	}
+
+	return resourceTypes
 }
 
 // createEnvironment creates a new named environment. If an environment with this name already
@@ -90,6 +135,10 @@ func createAndInitEnvironment(
 		return nil, nil, fmt.Errorf("environment '%s' already exists", envSpec.environmentName)
 	}
 
+	if envSpec.location == "" && envSpec.resourceTypes == nil {
+		envSpec.resourceTypes = projectResourceTypes(azdCtx)
+	}
+
 	if err := ensureEnvironmentInitialized(ctx, *envSpec, env, console); err != nil {
 		return nil, nil, fmt.Errorf("initializing environment: %w", err)
 	}
@@ -107,7 +156,7 @@ func loadOrInitEnvironment(
 		// If there's a default environment, use that
 		if *environmentName == "" {
 			var err error
-			*environmentName, err = azdCtx.GetDefaultEnvironmentName()
+			*environmentName, err = azdCtx.GetSelectedEnvironmentName()
 			if err != nil {
 				return nil, false, fmt.Errorf("getting default environment: %w", err)
 			}
@@ -164,11 +213,12 @@ func loadOrInitEnvironment(
 		return nil, nil, err
 	}
 
-	if err := ensureEnvironmentInitialized(
-		ctx,
-		environmentSpec{environmentName: *environmentName},
-		env,
-		console); err != nil {
+	envSpec := environmentSpec{environmentName: *environmentName}
+	if env.GetLocation() == "" {
+		envSpec.resourceTypes = projectResourceTypes(azdCtx)
+	}
+
+	if err := ensureEnvironmentInitialized(ctx, envSpec, env, console); err != nil {
 		return nil, nil, fmt.Errorf("initializing environment: %w", err)
 	}
 
@@ -181,6 +231,122 @@ func loadOrInitEnvironment(
 	return env, telemetry.ContextWithEnvironment(ctx, env), nil
 }
 
+// confirmProtectedEnvironment requires the name of a protected environment to be typed before the operation runs on
+// it. Protected environments can't be confirmed when prompting is disabled.
+func confirmProtectedEnvironment(
+	ctx context.Context,
+	console input.Console,
+	noPrompt bool,
+	env *environment.Environment,
+	operation string,
+) error {
+	if !env.IsProtected() {
+		return nil
+	}
+
+	if noPrompt {
+		return fmt.Errorf(
+			"environment '%s' is protected, run %s without --no-prompt to confirm it", env.GetEnvName(), operation)
+	}
+
+	name, err := console.Prompt(ctx, input.ConsoleOptions{
+		Message: fmt.Sprintf(
+			"Environment '%s' is protected. Type the name of the environment to confirm %s:", env.GetEnvName(), operation),
+	})
+	if err != nil {
+		return fmt.Errorf("prompting for confirmation: %w", err)
+	}
+
+	if strings.TrimSpace(name) != env.GetEnvName() {
+		return fmt.Errorf("the name doesn't match environment '%s', %s was canceled", env.GetEnvName(), operation)
+	}
+
+	return nil
+}
+
+// resolveResourceLocks lists the management locks of the environment preventing the operation, and offers to remove
+// them when the signed-in principal is allowed to. When deleting is false, only the read-only locks prevent the
+// operation. Returns true when no lock prevents the operation anymore.
+func resolveResourceLocks(
+	ctx context.Context,
+	console input.Console,
+	azCli azcli.AzCli,
+	noPrompt bool,
+	env *environment.Environment,
+	deleting bool,
+	operation string,
+) (bool, error) {
+	resourceManager := infra.NewAzureResourceManager(ctx)
+	environmentLocks, err := resourceManager.GetEnvironmentLocks(ctx, env)
+	if err != nil {
+		return false, fmt.Errorf("getting resource locks: %w", err)
+	}
+
+	locks := []azsdk.ManagementLock{}
+	for _, lock := range environmentLocks {
+		if deleting || lock.Properties.Level == azsdk.ManagementLockLevelReadOnly {
+			locks = append(locks, lock)
+		}
+	}
+
+	if len(locks) == 0 {
+		return true, nil
+	}
+
+	console.Message(ctx, output.WithWarningFormat("The following locks prevent %s:", operation))
+	for _, lock := range locks {
+		description := fmt.Sprintf("  - %s lock %s on %s", lock.Properties.Level, output.WithHighLightFormat(lock.Name),
+			lock.Scope())
+		if owners := lock.OwnerNames(); len(owners) > 0 {
+			description += fmt.Sprintf(" (owners: %s)", strings.Join(owners, ", "))
+		}
+		if lock.Properties.Notes != "" {
+			description += fmt.Sprintf(": %s", lock.Properties.Notes)
+		}
+
+		console.Message(ctx, description)
+	}
+
+	if noPrompt {
+		return false, nil
+	}
+
+	for _, lock := range locks {
+		allowed, err := azCli.CanDeleteManagementLocks(ctx, env.GetSubscriptionId(), lock.Scope())
+		if err != nil {
+			return false, fmt.Errorf("checking permission to remove lock '%s': %w", lock.Name, err)
+		}
+
+		if !allowed {
+			console.Message(ctx, fmt.Sprintf(
+				"You don't have permission to remove lock %s, ask its owners to remove it.", lock.Name))
+			return false, nil
+		}
+	}
+
+	remove, err := console.Confirm(ctx, input.ConsoleOptions{
+		Message:      fmt.Sprintf("Remove these %d locks and continue?", len(locks)),
+		DefaultValue: false,
+	})
+	if err != nil {
+		return false, fmt.Errorf("prompting to remove locks: %w", err)
+	}
+
+	if !remove {
+		return false, nil
+	}
+
+	for _, lock := range locks {
+		if err := azCli.DeleteManagementLock(ctx, env.GetSubscriptionId(), lock.Id); err != nil {
+			return false, err
+		}
+
+		console.Message(ctx, fmt.Sprintf("Removed lock %s", output.WithHighLightFormat(lock.Name)))
+	}
+
+	return true, nil
+}
+
 // ensureEnvironmentInitialized ensures the environment is initialized, i.e. it contains values for `AZURE_ENV_NAME`,
 // `AZURE_LOCATION`, `AZURE_SUBSCRIPTION_ID` and `AZURE_PRINCIPAL_ID`.
 // It will use the values from the "environment spec" passed in, and prompt for any missing values as necessary.
@@ -195,6 +361,14 @@ func ensureEnvironmentInitialized(
 		env.Values = make(map[string]string)
 	}
 
+	if tenantId := env.GetTenantId(); tenantId != "" {
+		identity.SetTenant(ctx, tenantId)
+	}
+
+	if isEnvironmentInitialized(env) {
+		return nil
+	}
+
 	hasValue := func(key string) bool {
 		val, has := env.Values[key]
 		return has && val != ""
@@ -205,10 +379,6 @@ func ensureEnvironmentInitialized(
 	hasSubID := hasValue(environment.SubscriptionIdEnvVarName)
 	hasPrincipalID := hasValue(environment.PrincipalIdEnvVarName)
 
-	if hasEnvName && hasLocation && hasSubID && hasPrincipalID {
-		return nil
-	}
-
 	if !hasEnvName && envSpec.environmentName != "" {
 		env.SetEnvName(envSpec.environmentName)
 	}
@@ -223,46 +393,25 @@ func ensureEnvironmentInitialized(
 	if !hasSubID && envSpec.subscription != "" {
 		env.SetSubscriptionId(envSpec.subscription)
 	} else {
-		subscriptionOptions, defaultSubscription, err := getSubscriptionOptions(ctx)
+		subscriptionId, tenantId, err := promptSubscription(ctx, console)
 		if err != nil {
 			return err
 		}
 
-		var subscriptionId = ""
-		for subscriptionId == "" {
-			subscriptionSelectionIndex, err := console.Select(ctx, input.ConsoleOptions{
-				Message:      "Please select an Azure Subscription to use:",
-				Options:      subscriptionOptions,
-				DefaultValue: defaultSubscription,
-			})
-
-			if err != nil {
-				return fmt.Errorf("reading subscription id: %w", err)
-			}
-
-			subscriptionSelection := subscriptionOptions[subscriptionSelectionIndex]
+		env.SetSubscriptionId(subscriptionId)
 
-			if subscriptionSelection == manualSubscriptionEntryOption {
-				subscriptionId, err = console.Prompt(ctx, input.ConsoleOptions{
-					Message: "Enter an Azure Subscription to use:",
-				})
-
-				if err != nil {
-					return fmt.Errorf("reading subscription id: %w", err)
-				}
-			} else {
-				subscriptionId = subscriptionSelection[len(subscriptionSelection)-
-					len("(00000000-0000-0000-0000-000000000000)")+1 : len(subscriptionSelection)-1]
-			}
+		// The tenant of the subscription is kept with the environment, so the next commands request their tokens
+		// from it
+		if tenantId != "" {
+			env.SetTenantId(tenantId)
+			identity.SetTenant(ctx, tenantId)
 		}
-
-		env.SetSubscriptionId(strings.TrimSpace(subscriptionId))
 	}
 
 	if !hasLocation && envSpec.location != "" {
 		env.SetLocation(envSpec.location)
 	} else {
-		location, err := azureutil.PromptLocation(ctx, env, "Please select an Azure location to use:")
+		location, err := azureutil.PromptLocation(ctx, env, "Please select an Azure location to use:", envSpec.resourceTypes)
 		if err != nil {
 			return fmt.Errorf("prompting for location: %w", err)
 		}
@@ -284,15 +433,119 @@ func ensureEnvironmentInitialized(
 	return nil
 }
 
-func getSubscriptionOptions(ctx context.Context) ([]string, string, error) {
+// isEnvironmentInitialized returns true when the environment has values for `AZURE_ENV_NAME`, `AZURE_LOCATION`,
+// `AZURE_SUBSCRIPTION_ID` and `AZURE_PRINCIPAL_ID`, which were validated against Azure when they were set
+func isEnvironmentInitialized(env *environment.Environment) bool {
+	for _, key := range []string{
+		environment.EnvNameEnvVarName,
+		environment.LocationEnvVarName,
+		environment.SubscriptionIdEnvVarName,
+		environment.PrincipalIdEnvVarName,
+	} {
+		if env.Values[key] == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// promptSubscription prompts for the subscription to use and returns its id and the id of its tenant. When the
+// account has access to several tenants, the subscriptions of another tenant can be listed by switching to it.
+func promptSubscription(ctx context.Context, console input.Console) (string, string, error) {
+	tenants, err := azcli.GetAzCli(ctx).ListTenants(ctx)
+	if err != nil {
+		// Switching tenants is only a convenience, the subscriptions of the current tenant can still be selected
+		log.Printf("failed listing tenants: %v", err)
+		tenants = nil
+	}
+
+	for {
+		subscriptionOptions, defaultSubscription, subscriptionInfos, err := getSubscriptionOptions(ctx)
+		if err != nil {
+			return "", "", err
+		}
+
+		if len(tenants) > 1 {
+			subscriptionOptions = append(subscriptionOptions, switchTenantOption)
+		}
+
+		subscriptionSelectionIndex, err := console.Select(ctx, input.ConsoleOptions{
+			Message:      "Please select an Azure Subscription to use:",
+			Options:      subscriptionOptions,
+			DefaultValue: defaultSubscription,
+		})
+		if err != nil {
+			return "", "", fmt.Errorf("reading subscription id: %w", err)
+		}
+
+		switch subscriptionOptions[subscriptionSelectionIndex] {
+		case switchTenantOption:
+			tenantId, err := promptTenant(ctx, console, tenants)
+			if err != nil {
+				return "", "", err
+			}
+
+			identity.SetTenant(ctx, tenantId)
+		case manualSubscriptionEntryOption:
+			subscriptionId, err := console.Prompt(ctx, input.ConsoleOptions{
+				Message: "Enter an Azure Subscription to use:",
+			})
+			if err != nil {
+				return "", "", fmt.Errorf("reading subscription id: %w", err)
+			}
+
+			if strings.TrimSpace(subscriptionId) != "" {
+				return strings.TrimSpace(subscriptionId), identity.GetTenant(ctx), nil
+			}
+		default:
+			subscription := subscriptionInfos[subscriptionSelectionIndex]
+			return subscription.Id, subscription.TenantId, nil
+		}
+	}
+}
+
+// promptTenant prompts for the tenant to list the subscriptions of and returns its id
+func promptTenant(ctx context.Context, console input.Console, tenants []azcli.AzCliTenant) (string, error) {
+	currentTenantId := identity.GetTenant(ctx)
+
+	tenantOptions := make([]string, len(tenants))
+	defaultTenant := ""
+	for index, tenant := range tenants {
+		domain := tenant.DefaultDomain
+		if domain == "" {
+			domain = tenant.Id
+		}
+
+		tenantOptions[index] = fmt.Sprintf("%2d. %s (%s)", index+1, tenant.DisplayName, domain)
+		if tenant.Id == currentTenantId {
+			defaultTenant = tenantOptions[index]
+		}
+	}
+
+	tenantSelectionIndex, err := console.Select(ctx, input.ConsoleOptions{
+		Message:      "Please select the Azure tenant to list the subscriptions of:",
+		Options:      tenantOptions,
+		DefaultValue: defaultTenant,
+	})
+	if err != nil {
+		return "", fmt.Errorf("reading tenant id: %w", err)
+	}
+
+	return tenants[tenantSelectionIndex].Id, nil
+}
+
+// getSubscriptionOptions returns the options to prompt the subscriptions of the current tenant with, the default
+// option and the subscriptions listed by the options, in the same order.
+func getSubscriptionOptions(ctx context.Context) ([]string, string, []*azcli.AzCliSubscriptionInfo, error) {
 	accountManager, err := account.NewManager(config.NewManager(), azcli.GetAzCli(ctx))
 	if err != nil {
-		return nil, "", fmt.Errorf("failed creating account manager: %w", err)
+		return nil, "", nil, fmt.Errorf("failed creating account manager: %w", err)
 	}
 
 	subscriptionInfos, err := accountManager.GetSubscriptions(ctx)
 	if err != nil {
-		return nil, "", fmt.Errorf("listing accounts: %w", err)
+		return nil, "", nil, fmt.Errorf("listing accounts: %w", err)
 	}
 
 	// If `AZURE_SUBSCRIPTION_ID` is set in the environment, use it to influence
@@ -319,7 +572,7 @@ func getSubscriptionOptions(ctx context.Context) ([]string, string, error) {
 	}
 
 	subscriptionOptions[len(subscriptionOptions)-1] = manualSubscriptionEntryOption
-	return subscriptionOptions, defaultSubscription, nil
+	return subscriptionOptions, defaultSubscription, subscriptionInfos, nil
 }
 
 var (
@@ -328,6 +581,19 @@ var (
 
 // ensureProject ensures that a project file exists, using the given
 // context. If a project is missing, errNoProject is returned.
+// publishCiSummary publishes the summary of the command to the CI system azd runs in. The command succeeds even when
+// its summary can't be published.
+func publishCiSummary(console input.Console, formatter output.Formatter, writer io.Writer, summary ci.Summary) {
+	// stdout holds the result of the command when JSON output is enabled
+	if formatter.Kind() == output.JsonFormat {
+		writer = console.Handles().Stderr
+	}
+
+	if err := ci.PublishSummary(writer, summary); err != nil {
+		log.Printf("failed publishing the summary of the run, skipping: %v", err)
+	}
+}
+
 func ensureProject(path string) error {
 	_, err := os.Stat(path)
 	if errors.Is(err, os.ErrNotExist) {
@@ -338,3 +604,40 @@ func ensureProject(path string) error {
 
 	return nil
 }
+
+// resolveService returns the service named by the optional service argument of a command. The argument can be
+// omitted when the project has a single service.
+func resolveService(
+	ctx *context.Context,
+	projConfig *project.ProjectConfig,
+	env *environment.Environment,
+	args []string,
+) (*project.Service, error) {
+	serviceName := ""
+	if len(args) > 0 {
+		serviceName = args[0]
+	} else if len(projConfig.Services) == 1 {
+		for name := range projConfig.Services {
+			serviceName = name
+		}
+	} else {
+		return nil, errors.New("the service must be specified when the project has several services")
+	}
+
+	if !projConfig.HasService(serviceName) {
+		return nil, fmt.Errorf("service name '%s' doesn't exist", serviceName)
+	}
+
+	proj, err := projConfig.GetProject(ctx, env)
+	if err != nil {
+		return nil, fmt.Errorf("creating project: %w", err)
+	}
+
+	for _, svc := range proj.Services {
+		if svc.Config.Name == serviceName {
+			return svc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("service name '%s' doesn't exist", serviceName)
+}
@@ -10,11 +10,18 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/identity"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/stretchr/testify/require"
 )
@@ -111,3 +118,245 @@ func Test_promptEnvironmentName(t *testing.T) {
 		require.Equal(t, []string{"groupA", "groupB", "groupC"}, groups)
 	})
 }
+
+func Test_confirmProtectedEnvironment(t *testing.T) {
+	protectedEnv := func() *environment.Environment {
+		env := environment.EphemeralWithValues("prod", nil)
+		env.SetProtected(true)
+		return env
+	}
+
+	t.Run("NotProtected", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.Console.WhenPrompt(func(options input.ConsoleOptions) bool {
+			return true
+		}).SetError(errors.New("prompt should not be called for environments that aren't protected"))
+
+		env := environment.EphemeralWithValues("dev", nil)
+		err := confirmProtectedEnvironment(*mockContext.Context, mockContext.Console, false, env, "`azd down`")
+		require.NoError(t, err)
+	})
+
+	t.Run("Confirmed", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.Console.WhenPrompt(func(options input.ConsoleOptions) bool {
+			return strings.Contains(options.Message, "Environment 'prod' is protected")
+		}).Respond("prod")
+
+		err := confirmProtectedEnvironment(*mockContext.Context, mockContext.Console, false, protectedEnv(), "`azd down`")
+		require.NoError(t, err)
+	})
+
+	t.Run("NameMismatch", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.Console.WhenPrompt(func(options input.ConsoleOptions) bool {
+			return true
+		}).Respond("dev")
+
+		err := confirmProtectedEnvironment(*mockContext.Context, mockContext.Console, false, protectedEnv(), "`azd down`")
+		require.Error(t, err)
+	})
+
+	t.Run("NoPrompt", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+
+		err := confirmProtectedEnvironment(*mockContext.Context, mockContext.Console, true, protectedEnv(), "`azd down`")
+		require.Error(t, err)
+	})
+}
+
+func Test_isEnvironmentInitialized(t *testing.T) {
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		environment.LocationEnvVarName:       "eastus2",
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		environment.PrincipalIdEnvVarName:    "PRINCIPAL_ID",
+	})
+	require.True(t, isEnvironmentInitialized(env))
+
+	env.SetLocation("")
+	require.False(t, isEnvironmentInitialized(env))
+	require.False(t, isEnvironmentInitialized(environment.EphemeralWithValues("dev", nil)))
+}
+
+func Test_resolveResourceLocks(t *testing.T) {
+	lockId := "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-prod/providers/Microsoft.Authorization/locks/keep"
+
+	setupMocks := func(mockContext *mocks.MockContext, level string, allowedActions []string) *bool {
+		deleted := false
+
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet &&
+				strings.HasSuffix(request.URL.Path, "/providers/Microsoft.Resources/deployments/prod")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return &http.Response{
+				Request:    request,
+				Header:     http.Header{},
+				StatusCode: http.StatusNotFound,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"error":{"code":"DeploymentNotFound"}}`)),
+			}, nil
+		})
+
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/resourcegroups")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armresources.ResourceGroupListResult{
+				Value: []*armresources.ResourceGroup{
+					{
+						ID:       convert.RefOf("rg-prod"),
+						Name:     convert.RefOf("rg-prod"),
+						Type:     convert.RefOf(string(infra.AzureResourceTypeResourceGroup)),
+						Location: convert.RefOf("eastus2"),
+					},
+				},
+			})
+		})
+
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet &&
+				strings.HasSuffix(request.URL.Path, "/providers/Microsoft.Authorization/locks")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+				"value": []map[string]any{
+					{
+						"id":         lockId,
+						"name":       "keep",
+						"properties": map[string]any{"level": level, "notes": "production data"},
+						"systemData": map[string]any{"createdBy": "admin@contoso.com"},
+					},
+				},
+			})
+		})
+
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet &&
+				strings.HasSuffix(request.URL.Path, "/providers/Microsoft.Authorization/permissions")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+				"value": []map[string]any{{"actions": allowedActions, "notActions": []string{}}},
+			})
+		})
+
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodDelete && request.URL.Path == lockId
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			deleted = true
+			return &http.Response{Request: request, Header: http.Header{}, StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		})
+
+		return &deleted
+	}
+
+	env := environment.EphemeralWithValues("prod", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+	})
+
+	t.Run("Removed", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		deleted := setupMocks(mockContext, "CanNotDelete", []string{"Microsoft.Authorization/*"})
+		mockContext.Console.WhenConfirm(func(options input.ConsoleOptions) bool {
+			return strings.Contains(options.Message, "Remove these 1 locks")
+		}).Respond(true)
+
+		unlocked, err := resolveResourceLocks(
+			*mockContext.Context, mockContext.Console, azcli.GetAzCli(*mockContext.Context), false, env, true, "the deletion")
+		require.NoError(t, err)
+		require.True(t, unlocked)
+		require.True(t, *deleted)
+		require.Contains(t, strings.Join(mockContext.Console.Output(), "\n"), "admin@contoso.com")
+	})
+
+	t.Run("NotPermitted", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		deleted := setupMocks(mockContext, "CanNotDelete", []string{"*/read"})
+
+		unlocked, err := resolveResourceLocks(
+			*mockContext.Context, mockContext.Console, azcli.GetAzCli(*mockContext.Context), false, env, true, "the deletion")
+		require.NoError(t, err)
+		require.False(t, unlocked)
+		require.False(t, *deleted)
+	})
+
+	t.Run("DeleteLockAllowsProvisioning", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		setupMocks(mockContext, "CanNotDelete", []string{"*"})
+
+		unlocked, err := resolveResourceLocks(
+			*mockContext.Context, mockContext.Console, azcli.GetAzCli(*mockContext.Context), true, env, false, "provisioning")
+		require.NoError(t, err)
+		require.True(t, unlocked)
+		require.Empty(t, mockContext.Console.Output())
+	})
+
+	t.Run("NoPrompt", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		deleted := setupMocks(mockContext, "ReadOnly", []string{"*"})
+
+		unlocked, err := resolveResourceLocks(
+			*mockContext.Context, mockContext.Console, azcli.GetAzCli(*mockContext.Context), true, env, false, "provisioning")
+		require.NoError(t, err)
+		require.False(t, unlocked)
+		require.False(t, *deleted)
+	})
+}
+
+func Test_promptSubscription(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	credential := identity.NewTenantCredential(func(tenantId string) (azcore.TokenCredential, error) {
+		return &mocks.MockCredentials{
+			GetTokenFn: func(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+				return azcore.AccessToken{Token: "token-" + tenantId, ExpiresOn: time.Now().Add(time.Hour)}, nil
+			},
+		}, nil
+	})
+	ctx := identity.WithCredentials(*mockContext.Context, credential)
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Path == "/tenants"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armsubscriptions.TenantListResult{
+			Value: []*armsubscriptions.TenantIDDescription{
+				{TenantID: convert.RefOf("TENANT_B"), DisplayName: convert.RefOf("Fabrikam")},
+				{TenantID: convert.RefOf("TENANT_A"), DisplayName: convert.RefOf("Contoso")},
+			},
+		})
+	})
+
+	// The subscriptions are the ones of the tenant the token was requested from
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Path == "/subscriptions"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		subscription := &armsubscriptions.Subscription{
+			SubscriptionID: convert.RefOf("SUBSCRIPTION_A"),
+			DisplayName:    convert.RefOf("Subscription A"),
+			TenantID:       convert.RefOf("TENANT_A"),
+		}
+		if request.Header.Get("Authorization") == "Bearer token-TENANT_B" {
+			subscription = &armsubscriptions.Subscription{
+				SubscriptionID: convert.RefOf("SUBSCRIPTION_B"),
+				DisplayName:    convert.RefOf("Subscription B"),
+				TenantID:       convert.RefOf("TENANT_B"),
+			}
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armsubscriptions.SubscriptionListResult{
+			Value: []*armsubscriptions.Subscription{subscription},
+		})
+	})
+
+	// Switch to the second tenant, then select its subscription
+	mockContext.Console.WhenSelect(func(options input.ConsoleOptions) bool {
+		return strings.Contains(options.Options[0], "Subscription A")
+	}).Respond(2)
+	mockContext.Console.WhenSelect(func(options input.ConsoleOptions) bool {
+		return strings.Contains(options.Options[0], "Subscription B")
+	}).Respond(0)
+	mockContext.Console.WhenSelect(func(options input.ConsoleOptions) bool {
+		return strings.Contains(options.Message, "tenant")
+	}).Respond(1)
+
+	subscriptionId, tenantId, err := promptSubscription(ctx, mockContext.Console)
+	require.NoError(t, err)
+	require.Equal(t, "SUBSCRIPTION_B", subscriptionId)
+	require.Equal(t, "TENANT_B", tenantId)
+	require.Equal(t, "TENANT_B", identity.GetTenant(ctx))
+}
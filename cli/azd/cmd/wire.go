@@ -77,6 +77,15 @@ func initShowAction(
 	panic(wire.Build(ShowCmdSet))
 }
 
+func initDoctorAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags doctorFlags,
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(DoctorCmdSet))
+}
+
 func initVersionAction(
 	cmd *cobra.Command,
 	o *internal.GlobalCommandOptions,
@@ -86,6 +95,69 @@ func initVersionAction(
 	panic(wire.Build(VersionCmdSet))
 }
 
+func initUpgradeAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags upgradeFlags,
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(UpgradeCmdSet))
+}
+
+func initPackageAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags packageFlags,
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(PackageCmdSet))
+}
+
+func initCleanupAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags cleanupFlags,
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(CleanupCmdSet))
+}
+
+func initLogsAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags logsFlags,
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(LogsCmdSet))
+}
+
+func initExecAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags execFlags,
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(ExecCmdSet))
+}
+
+func initRunAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags runFlags,
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(RunCmdSet))
+}
+
+func initServeAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags serveFlags,
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(ServeCmdSet))
+}
+
 //#endregion Root
 
 //#region Infra
@@ -108,6 +180,15 @@ func initInfraDeleteAction(
 	panic(wire.Build(InfraDeleteCmdSet))
 }
 
+func initInfraParamsInitAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags struct{},
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(InfraParamsInitCmdSet))
+}
+
 //#endregion Infra
 
 //#region Env
@@ -115,7 +196,7 @@ func initInfraDeleteAction(
 func initEnvSetAction(
 	cmd *cobra.Command,
 	o *internal.GlobalCommandOptions,
-	flags struct{},
+	flags envSetFlags,
 	args []string,
 ) (actions.Action, error) {
 	panic(wire.Build(EnvSetCmdSet))
@@ -124,12 +205,30 @@ func initEnvSetAction(
 func initEnvSelectAction(
 	cmd *cobra.Command,
 	o *internal.GlobalCommandOptions,
-	flags struct{},
+	flags envSelectFlags,
 	args []string,
 ) (actions.Action, error) {
 	panic(wire.Build(EnvSelectCmdSet))
 }
 
+func initEnvSetPolicyAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags envSetPolicyFlags,
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(EnvSetPolicyCmdSet))
+}
+
+func initEnvSetTtlAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags envSetTtlFlags,
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(EnvSetTtlCmdSet))
+}
+
 func initEnvListAction(
 	cmd *cobra.Command,
 	o *internal.GlobalCommandOptions,
@@ -166,8 +265,48 @@ func initEnvGetValuesAction(
 	panic(wire.Build(EnvGetValuesCmdSet))
 }
 
+func initEnvPullConfigAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags struct{},
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(EnvPullConfigCmdSet))
+}
+
 //#endregion Env
 
+//#region Secrets
+
+func initSecretsSetAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags struct{},
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(SecretsSetCmdSet))
+}
+
+func initSecretsGetAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags struct{},
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(SecretsGetCmdSet))
+}
+
+func initSecretsListAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags struct{},
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(SecretsListCmdSet))
+}
+
+//#endregion Secrets
+
 //#region Pipeline
 
 func initPipelineConfigAction(
@@ -179,6 +318,15 @@ func initPipelineConfigAction(
 	panic(wire.Build(PipelineConfigCmdSet))
 }
 
+func initPipelineListPrincipalsAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags struct{},
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(PipelineListPrincipalsCmdSet))
+}
+
 //#endregion Pipeline
 
 //#region Templates
@@ -201,6 +349,15 @@ func initTemplatesShowAction(
 	panic(wire.Build(TemplatesShowCmdSet))
 }
 
+func initTemplatesUpdateAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags templatesUpdateFlags,
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(TemplatesUpdateCmdSet))
+}
+
 //#endregion Templates
 
 //#region Config
@@ -251,3 +408,43 @@ func initConfigResetAction(
 }
 
 //#endregion Config
+
+//#region Extensions
+
+func initExtensionInstallAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags struct{},
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(ExtensionInstallCmdSet))
+}
+
+func initExtensionListAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags struct{},
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(ExtensionListCmdSet))
+}
+
+func initExtensionUninstallAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags struct{},
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(ExtensionUninstallCmdSet))
+}
+
+func initExtensionRunAction(
+	cmd *cobra.Command,
+	o *internal.GlobalCommandOptions,
+	flags extensionRunFlags,
+	args []string,
+) (actions.Action, error) {
+	panic(wire.Build(ExtensionRunCmdSet))
+}
+
+//#endregion Extensions
@@ -11,14 +11,17 @@ import (
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/extensions"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/templates"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/devtunnel"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
 	"github.com/spf13/cobra"
 )
 
 import (
 	_ "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning/bicep"
+	_ "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning/passthrough"
 	_ "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning/terraform"
 )
 
@@ -40,7 +43,8 @@ func initDeployAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flag
 	if err != nil {
 		return nil, err
 	}
-	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential)
+	manager := config.NewManager()
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
 	cmdDeployAction, err := newDeployAction(flags, azdContext, azCli, console, formatter, writer)
 	if err != nil {
 		return nil, err
@@ -65,7 +69,7 @@ func initInitAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags
 	if err != nil {
 		return nil, err
 	}
-	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential)
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
 	accountManager, err := account.NewManager(manager, azCli)
 	if err != nil {
 		return nil, err
@@ -90,7 +94,8 @@ func initLoginAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags
 	if err != nil {
 		return nil, err
 	}
-	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential)
+	manager := config.NewManager()
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
 	cmdLoginAction := newLoginAction(formatter, writer, azCli, flags, console)
 	return cmdLoginAction, nil
 }
@@ -112,7 +117,7 @@ func initUpAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags up
 	if err != nil {
 		return nil, err
 	}
-	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential)
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
 	accountManager, err := account.NewManager(manager, azCli)
 	if err != nil {
 		return nil, err
@@ -130,7 +135,7 @@ func initUpAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags up
 	if err != nil {
 		return nil, err
 	}
-	cmdUpAction := newUpAction(cmdInitAction, cmdInfraCreateAction, cmdDeployAction, console)
+	cmdUpAction := newUpAction(cmdInitAction, cmdInfraCreateAction, cmdDeployAction, console, cmd)
 	return cmdUpAction, nil
 }
 
@@ -150,7 +155,8 @@ func initMonitorAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, fla
 	if err != nil {
 		return nil, err
 	}
-	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential)
+	manager := config.NewManager()
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
 	cmdMonitorAction := newMonitorAction(azdContext, azCli, console, flags)
 	return cmdMonitorAction, nil
 }
@@ -185,6 +191,52 @@ func initShowAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags
 	return cmdShowAction, nil
 }
 
+func initDoctorAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags doctorFlags, args []string) (actions.Action, error) {
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	commandRunner := newCommandRunnerFromConsole(console)
+	tokenCredential, err := newCredential()
+	if err != nil {
+		return nil, err
+	}
+	manager := config.NewManager()
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
+	azdContext, err := newAzdContext()
+	if err != nil {
+		return nil, err
+	}
+	gitCli := git.NewGitCliFromRunner(commandRunner)
+	cmdDoctorAction := newDoctorAction(flags, azCli, azdContext, gitCli, formatter, writer, console)
+	return cmdDoctorAction, nil
+}
+
+func initCleanupAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags cleanupFlags, args []string) (actions.Action, error) {
+	azdContext, err := newAzdContext()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	commandRunner := newCommandRunnerFromConsole(console)
+	tokenCredential, err := newCredential()
+	if err != nil {
+		return nil, err
+	}
+	manager := config.NewManager()
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
+	gitCli := git.NewGitCliFromRunner(commandRunner)
+	cmdCleanupAction := newCleanupAction(flags, azdContext, azCli, gitCli, console)
+	return cmdCleanupAction, nil
+}
+
 func initVersionAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags versionFlags, args []string) (actions.Action, error) {
 	formatter, err := output.GetCommandFormatter(cmd)
 	if err != nil {
@@ -196,6 +248,101 @@ func initVersionAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, fla
 	return cmdVersionAction, nil
 }
 
+func initUpgradeAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags upgradeFlags, args []string) (actions.Action, error) {
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	manager := config.NewManager()
+	cmdUpgradeAction := newUpgradeAction(flags, console, manager)
+	return cmdUpgradeAction, nil
+}
+
+func initPackageAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags packageFlags, args []string) (actions.Action, error) {
+	azdContext, err := newAzdContext()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	cmdPackageAction := newPackageAction(flags, azdContext, console, formatter, writer)
+	return cmdPackageAction, nil
+}
+
+func initLogsAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags logsFlags, args []string) (actions.Action, error) {
+	azdContext, err := newAzdContext()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	cmdLogsAction := newLogsAction(flags, args, azdContext, console)
+	return cmdLogsAction, nil
+}
+
+func initExecAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags execFlags, args []string) (actions.Action, error) {
+	azdContext, err := newAzdContext()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	cmdExecAction := newExecAction(flags, args, azdContext, console)
+	return cmdExecAction, nil
+}
+
+func initRunAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags runFlags, args []string) (actions.Action, error) {
+	azdContext, err := newAzdContext()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	commandRunner := newCommandRunnerFromConsole(console)
+	tokenCredential, err := newCredential()
+	if err != nil {
+		return nil, err
+	}
+	manager := config.NewManager()
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
+	devTunnelCli := devtunnel.NewDevTunnelCli()
+	cmdRunAction := newRunAction(flags, args, azdContext, azCli, devTunnelCli, console)
+	return cmdRunAction, nil
+}
+
+func initServeAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags serveFlags, args []string) (actions.Action, error) {
+	azdContext, err := newAzdContext()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	cmdServeAction := newServeAction(flags, azdContext, console)
+	return cmdServeAction, nil
+}
+
 func initInfraCreateAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags infraCreateFlags, args []string) (actions.Action, error) {
 	azdContext, err := newAzdContext()
 	if err != nil {
@@ -212,7 +359,8 @@ func initInfraCreateAction(cmd *cobra.Command, o *internal.GlobalCommandOptions,
 	if err != nil {
 		return nil, err
 	}
-	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential)
+	manager := config.NewManager()
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
 	cmdInfraCreateAction := newInfraCreateAction(flags, azdContext, azCli, console, formatter, writer)
 	return cmdInfraCreateAction, nil
 }
@@ -233,12 +381,28 @@ func initInfraDeleteAction(cmd *cobra.Command, o *internal.GlobalCommandOptions,
 	if err != nil {
 		return nil, err
 	}
-	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential)
+	manager := config.NewManager()
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
 	cmdInfraDeleteAction := newInfraDeleteAction(flags, azdContext, azCli, console)
 	return cmdInfraDeleteAction, nil
 }
 
-func initEnvSetAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags struct{}, args []string) (actions.Action, error) {
+func initInfraParamsInitAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags struct{}, args []string) (actions.Action, error) {
+	azdContext, err := newAzdContext()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	cmdInfraParamsInitAction := newInfraParamsInitAction(azdContext, console)
+	return cmdInfraParamsInitAction, nil
+}
+
+func initEnvSetAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags envSetFlags, args []string) (actions.Action, error) {
 	azdContext, err := newAzdContext()
 	if err != nil {
 		return nil, err
@@ -254,20 +418,57 @@ func initEnvSetAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flag
 	if err != nil {
 		return nil, err
 	}
-	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential)
-	cmdEnvSetAction := newEnvSetAction(azdContext, azCli, console, o, args)
+	manager := config.NewManager()
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
+	cmdEnvSetAction := newEnvSetAction(azdContext, azCli, console, flags, args)
 	return cmdEnvSetAction, nil
 }
 
-func initEnvSelectAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags struct{}, args []string) (actions.Action, error) {
+func initEnvSelectAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags envSelectFlags, args []string) (actions.Action, error) {
 	azdContext, err := newAzdContext()
 	if err != nil {
 		return nil, err
 	}
-	cmdEnvSelectAction := newEnvSelectAction(azdContext, args)
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	cmdEnvSelectAction := newEnvSelectAction(azdContext, console, flags, args)
 	return cmdEnvSelectAction, nil
 }
 
+func initEnvSetPolicyAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags envSetPolicyFlags, args []string) (actions.Action, error) {
+	azdContext, err := newAzdContext()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	cmdEnvSetPolicyAction := newEnvSetPolicyAction(azdContext, console, flags, args)
+	return cmdEnvSetPolicyAction, nil
+}
+
+func initEnvSetTtlAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags envSetTtlFlags, args []string) (actions.Action, error) {
+	azdContext, err := newAzdContext()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	cmdEnvSetTtlAction := newEnvSetTtlAction(azdContext, console, flags, args)
+	return cmdEnvSetTtlAction, nil
+}
+
 func initEnvListAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags struct{}, args []string) (actions.Action, error) {
 	azdContext, err := newAzdContext()
 	if err != nil {
@@ -278,7 +479,8 @@ func initEnvListAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, fla
 		return nil, err
 	}
 	writer := newWriter(cmd)
-	cmdEnvListAction := newEnvListAction(azdContext, formatter, writer)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	cmdEnvListAction := newEnvListAction(azdContext, console, formatter, writer)
 	return cmdEnvListAction, nil
 }
 
@@ -298,7 +500,8 @@ func initEnvNewAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flag
 	if err != nil {
 		return nil, err
 	}
-	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential)
+	manager := config.NewManager()
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
 	cmdEnvNewAction := newEnvNewAction(azdContext, azCli, flags, console)
 	return cmdEnvNewAction, nil
 }
@@ -319,7 +522,8 @@ func initEnvRefreshAction(cmd *cobra.Command, o *internal.GlobalCommandOptions,
 	if err != nil {
 		return nil, err
 	}
-	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential)
+	manager := config.NewManager()
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
 	cmdEnvRefreshAction := newEnvRefreshAction(azdContext, azCli, o, console, formatter, writer)
 	return cmdEnvRefreshAction, nil
 }
@@ -340,11 +544,100 @@ func initEnvGetValuesAction(cmd *cobra.Command, o *internal.GlobalCommandOptions
 	if err != nil {
 		return nil, err
 	}
-	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential)
+	manager := config.NewManager()
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
 	cmdEnvGetValuesAction := newEnvGetValuesAction(azdContext, console, formatter, writer, azCli, o)
 	return cmdEnvGetValuesAction, nil
 }
 
+func initEnvPullConfigAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags struct{}, args []string) (actions.Action, error) {
+	azdContext, err := newAzdContext()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	commandRunner := newCommandRunnerFromConsole(console)
+	tokenCredential, err := newCredential()
+	if err != nil {
+		return nil, err
+	}
+	manager := config.NewManager()
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
+	cmdEnvPullConfigAction := newEnvPullConfigAction(azdContext, azCli, console, o)
+	return cmdEnvPullConfigAction, nil
+}
+
+func initSecretsSetAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags struct{}, args []string) (actions.Action, error) {
+	azdContext, err := newAzdContext()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	commandRunner := newCommandRunnerFromConsole(console)
+	tokenCredential, err := newCredential()
+	if err != nil {
+		return nil, err
+	}
+	manager := config.NewManager()
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
+	cmdSecretsSetAction := newSecretsSetAction(azdContext, azCli, console, o, args)
+	return cmdSecretsSetAction, nil
+}
+
+func initSecretsGetAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags struct{}, args []string) (actions.Action, error) {
+	azdContext, err := newAzdContext()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	commandRunner := newCommandRunnerFromConsole(console)
+	tokenCredential, err := newCredential()
+	if err != nil {
+		return nil, err
+	}
+	manager := config.NewManager()
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
+	cmdSecretsGetAction := newSecretsGetAction(azdContext, azCli, console, writer, o, args)
+	return cmdSecretsGetAction, nil
+}
+
+func initSecretsListAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags struct{}, args []string) (actions.Action, error) {
+	azdContext, err := newAzdContext()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	commandRunner := newCommandRunnerFromConsole(console)
+	tokenCredential, err := newCredential()
+	if err != nil {
+		return nil, err
+	}
+	manager := config.NewManager()
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
+	cmdSecretsListAction := newSecretsListAction(azdContext, azCli, console, formatter, writer, o)
+	return cmdSecretsListAction, nil
+}
+
 func initPipelineConfigAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags pipelineConfigFlags, args []string) (actions.Action, error) {
 	azdContext, err := newAzdContext()
 	if err != nil {
@@ -356,10 +649,29 @@ func initPipelineConfigAction(cmd *cobra.Command, o *internal.GlobalCommandOptio
 	}
 	writer := newWriter(cmd)
 	console := newConsoleFromOptions(o, formatter, writer, cmd)
-	cmdPipelineConfigAction := newPipelineConfigAction(azdContext, console, flags)
+	manager := config.NewManager()
+	cmdPipelineConfigAction := newPipelineConfigAction(azdContext, console, flags, manager)
 	return cmdPipelineConfigAction, nil
 }
 
+func initPipelineListPrincipalsAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags struct{}, args []string) (actions.Action, error) {
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	commandRunner := newCommandRunnerFromConsole(console)
+	tokenCredential, err := newCredential()
+	if err != nil {
+		return nil, err
+	}
+	manager := config.NewManager()
+	azCli := newAzCliFromOptions(o, commandRunner, tokenCredential, manager)
+	cmdPipelineListPrincipalsAction := newPipelineListPrincipalsAction(azCli, console, formatter, writer)
+	return cmdPipelineListPrincipalsAction, nil
+}
+
 func initTemplatesListAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags templatesListFlags, args []string) (actions.Action, error) {
 	formatter, err := output.GetCommandFormatter(cmd)
 	if err != nil {
@@ -382,6 +694,23 @@ func initTemplatesShowAction(cmd *cobra.Command, o *internal.GlobalCommandOption
 	return cmdTemplatesShowAction, nil
 }
 
+func initTemplatesUpdateAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags templatesUpdateFlags, args []string) (actions.Action, error) {
+	azdContext, err := newAzdContext()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	commandRunner := newCommandRunnerFromConsole(console)
+	gitCli := git.NewGitCliFromRunner(commandRunner)
+	cmdTemplatesUpdateAction := newTemplatesUpdateAction(flags, azdContext, console, gitCli)
+	return cmdTemplatesUpdateAction, nil
+}
+
 func initConfigListAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags struct{}, args []string) (actions.Action, error) {
 	manager := config.NewManager()
 	formatter, err := output.GetCommandFormatter(cmd)
@@ -421,3 +750,63 @@ func initConfigResetAction(cmd *cobra.Command, o *internal.GlobalCommandOptions,
 	cmdConfigResetAction := newConfigResetAction(manager, args)
 	return cmdConfigResetAction, nil
 }
+
+func initExtensionInstallAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags struct{}, args []string) (actions.Action, error) {
+	manager, err := extensions.NewManager()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	cmdExtensionInstallAction := newExtensionInstallAction(manager, console, args)
+	return cmdExtensionInstallAction, nil
+}
+
+func initExtensionListAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags struct{}, args []string) (actions.Action, error) {
+	manager, err := extensions.NewManager()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	cmdExtensionListAction := newExtensionListAction(manager, formatter, writer)
+	return cmdExtensionListAction, nil
+}
+
+func initExtensionUninstallAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags struct{}, args []string) (actions.Action, error) {
+	manager, err := extensions.NewManager()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	cmdExtensionUninstallAction := newExtensionUninstallAction(manager, console, args)
+	return cmdExtensionUninstallAction, nil
+}
+
+func initExtensionRunAction(cmd *cobra.Command, o *internal.GlobalCommandOptions, flags extensionRunFlags, args []string) (actions.Action, error) {
+	azdContext, err := newAzdContext()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := output.GetCommandFormatter(cmd)
+	if err != nil {
+		return nil, err
+	}
+	writer := newWriter(cmd)
+	console := newConsoleFromOptions(o, formatter, writer, cmd)
+	commandRunner := newCommandRunnerFromConsole(console)
+	cmdExtensionRunAction := newExtensionRunAction(flags, azdContext, console, commandRunner, args)
+	return cmdExtensionRunAction, nil
+}
@@ -0,0 +1,57 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package internal
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Environment variable that keeps azd interactive when it runs in a CI system.
+const ForceInteractiveEnvVarName = "AZD_FORCE_INTERACTIVE"
+
+// CIProvider is a CI system whose UI displays the logging commands and summaries written by azd
+type CIProvider string
+
+const (
+	// azd doesn't run in a CI system, or in one without logging commands
+	CIProviderNone           CIProvider = ""
+	CIProviderGitHubActions  CIProvider = "GitHubActions"
+	CIProviderAzurePipelines CIProvider = "AzurePipelines"
+)
+
+// DetectCIProvider returns the CI system azd runs in, GitHub Actions or Azure Pipelines, or CIProviderNone otherwise.
+func DetectCIProvider() CIProvider {
+	// The CI systems set their variables to 'True' on Windows and 'true' elsewhere
+	switch {
+	case strings.EqualFold(os.Getenv(githubActionsEnvironmentVariableName), "true"):
+		return CIProviderGitHubActions
+	case strings.EqualFold(os.Getenv("TF_BUILD"), "true"):
+		return CIProviderAzurePipelines
+	default:
+		return CIProviderNone
+	}
+}
+
+// IsRunningInCI returns true when azd runs in Azure Pipelines, GitHub Actions or a CI system setting the `CI`
+// environment variable.
+func IsRunningInCI() bool {
+	if DetectCIProvider() != CIProviderNone {
+		return true
+	}
+
+	ci, err := strconv.ParseBool(os.Getenv("CI"))
+	return err == nil && ci
+}
+
+// IsNonInteractiveCI returns true when azd runs in a CI system and AZD_FORCE_INTERACTIVE isn't set to true. azd then
+// doesn't prompt, renders its progress as plain lines and writes a machine-readable summary of its errors.
+func IsNonInteractiveCI() bool {
+	if forceInteractive, err := strconv.ParseBool(os.Getenv(ForceInteractiveEnvVarName)); err == nil && forceInteractive {
+		return false
+	}
+
+	return IsRunningInCI()
+}
@@ -0,0 +1,62 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsNonInteractiveCI(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected bool
+	}{
+		{name: "Desktop", env: map[string]string{}, expected: false},
+		{name: "AzurePipelines", env: map[string]string{"TF_BUILD": "True"}, expected: true},
+		{name: "GitHubActions", env: map[string]string{"GITHUB_ACTIONS": "true"}, expected: true},
+		{name: "GenericCI", env: map[string]string{"CI": "1"}, expected: true},
+		{name: "CIDisabled", env: map[string]string{"CI": "false"}, expected: false},
+		{
+			name:     "ForceInteractive",
+			env:      map[string]string{"GITHUB_ACTIONS": "true", ForceInteractiveEnvVarName: "true"},
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, name := range []string{"TF_BUILD", "GITHUB_ACTIONS", "CI", ForceInteractiveEnvVarName} {
+				t.Setenv(name, test.env[name])
+			}
+
+			require.Equal(t, test.expected, IsNonInteractiveCI())
+		})
+	}
+}
+
+func TestDetectCIProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected CIProvider
+	}{
+		{name: "Desktop", env: map[string]string{}, expected: CIProviderNone},
+		{name: "AzurePipelines", env: map[string]string{"TF_BUILD": "True"}, expected: CIProviderAzurePipelines},
+		{name: "GitHubActions", env: map[string]string{"GITHUB_ACTIONS": "true"}, expected: CIProviderGitHubActions},
+		{name: "GenericCI", env: map[string]string{"CI": "true"}, expected: CIProviderNone},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, name := range []string{"TF_BUILD", "GITHUB_ACTIONS", "CI"} {
+				t.Setenv(name, test.env[name])
+			}
+
+			require.Equal(t, test.expected, DetectCIProvider())
+		})
+	}
+}
@@ -17,6 +17,13 @@ type GlobalCommandOptions struct {
 	// launched tools. It's enabled with `--debug`, for any command.
 	EnableDebugLogging bool
 
+	// Quiet hides the progress of the command, the spinner and the lines reporting each step, i.e. a line per
+	// provisioned resource. It's enabled with `--quiet`.
+	Quiet bool
+
+	// Verbose shows the output of the tools launched by the command, i.e. az and bicep. It's enabled with `--verbose`.
+	Verbose bool
+
 	// when true, interactive prompts should behave as if the user selected the default value.
 	// if there is no default value the prompt returns an error.
 	NoPrompt bool
@@ -26,6 +33,22 @@ type GlobalCommandOptions struct {
 	// AZURE_DEV_COLLECT_TELEMETRY is set to 'no'.
 	// Defaults to true.
 	EnableTelemetry bool
+
+	// TraceLogFile is the path of a file that spans are written to when set with `--trace-log-file`.
+	// The trace log is configured at startup, before the command runs, by main.go.
+	TraceLogFile string
+
+	// TraceLogUrl is the OTLP/HTTP endpoint that spans are sent to when set with `--trace-log-url`.
+	// The trace log is configured at startup, before the command runs, by main.go.
+	TraceLogUrl string
+
+	// Profile prints the time spent in each step of the command once it completes, when set with `--profile`.
+	// The profiler is enabled at startup, before the command runs, by main.go.
+	Profile bool
+
+	// ProfileDir is the folder a CPU profile, a heap profile and an execution trace of the process are written to
+	// when set with `--profile-dir`. The profiles are started at startup, before the command runs, by main.go.
+	ProfileDir string
 }
 
 type contextKey string
@@ -11,3 +11,51 @@ package events
 //   - cmd.init
 //   - cmd.up
 const CommandEventPrefix = "cmd."
+
+// The event emitted for a complete run of the pipeline config flow.
+const PipelineConfigEventName = "pipeline.config"
+
+// Pipeline config step event names follow the convention pipeline.config.<step name>.
+//
+// Examples:
+//   - pipeline.config.principal
+//   - pipeline.config.remote
+//   - pipeline.config.connection
+const PipelineConfigEventPrefix = PipelineConfigEventName + "."
+
+// Provisioning event names follow the convention provision.<operation>.
+//
+// Examples:
+//   - provision.plan
+//   - provision.deploy
+//   - provision.destroy
+const ProvisionEventPrefix = "provision."
+
+// The event emitted for the deployment of a single service.
+const DeployServiceEventName = "deploy.service"
+
+// The event emitted for each HTTP request sent to the Microsoft Graph.
+const GraphRequestEventName = "graph.request"
+
+// The event emitted for the check of the external tools required by a command.
+const ToolsCheckEventName = "tools.check"
+
+// The event emitted for ensuring the user is logged in before a command runs.
+const AuthLoginCheckEventName = "auth.check"
+
+// The event emitted for each compilation of a bicep module or parameters file.
+const BicepBuildEventName = "bicep.build"
+
+// The event emitted for each build of a container image.
+const DockerBuildEventName = "docker.build"
+
+// The event emitted for each push of a container image to its registry.
+const DockerPushEventName = "docker.push"
+
+// Azure DevOps event names follow the convention azdo.<operation>.
+//
+// Examples:
+//   - azdo.project.create
+//   - azdo.repository.create
+//   - azdo.pipeline.create
+const AzdoEventPrefix = "azdo."
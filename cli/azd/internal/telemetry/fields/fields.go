@@ -61,6 +61,26 @@ const (
 	TemplateIdKey = attribute.Key("project.template.id")
 )
 
+// Pipeline config fields. Available on pipeline config events.
+const (
+	// The name of the CI provider used to configure the pipeline.
+	//
+	// Example: GitHub, Azure DevOps.
+	PipelineProviderKey = attribute.Key("pipeline.provider")
+	// Whether a new SCM project was created. False when an existing project was reused.
+	PipelineProjectCreatedKey = attribute.Key("pipeline.project.created")
+	// Whether a new repository was created. False when an existing repository was reused.
+	PipelineRepositoryCreatedKey = attribute.Key("pipeline.repository.created")
+	// Whether a new CI pipeline definition was created. False when an existing definition was updated.
+	PipelineDefinitionCreatedKey = attribute.Key("pipeline.definition.created")
+	// Whether the local changes were pushed to start the CI pipeline.
+	PipelinePushedKey = attribute.Key("pipeline.pushed")
+	// The name of the pipeline config step that failed.
+	//
+	// See events.PipelineConfigEventPrefix for the set of steps.
+	PipelineFailedStepKey = attribute.Key("pipeline.failed.step")
+)
+
 // All possible enumerations of ExecutionEnvironmentKey
 const (
 	// Desktop environments
@@ -90,3 +110,27 @@ const (
 
 // The value used for ServiceNameKey
 const ServiceNameAzd = "azd"
+
+// Provisioning and deployment fields. Available on provision and deploy events.
+const (
+	// The name of the infrastructure provider.
+	//
+	// Example: bicep, terraform.
+	ProvisionProviderKey = attribute.Key("provision.provider")
+	// The host kind of the service being deployed.
+	//
+	// Example: appservice, containerapp, function.
+	DeployServiceHostKey = attribute.Key("deploy.service.host")
+	// The language of the service being deployed.
+	DeployServiceLanguageKey = attribute.Key("deploy.service.language")
+)
+
+// HTTP request fields. Available on graph request events.
+const (
+	// The HTTP request method.
+	HttpMethodKey = attribute.Key("http.method")
+	// The host name of the HTTP request. The path is not recorded to avoid capturing identifiers.
+	HttpHostKey = attribute.Key("http.host")
+	// The HTTP response status code.
+	HttpStatusCodeKey = attribute.Key("http.status_code")
+)
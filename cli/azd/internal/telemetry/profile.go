@@ -0,0 +1,233 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	rtrace "runtime/trace"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/events"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ProfileOptions configures the profiling of the command run, used to diagnose slow commands.
+// Profiling runs independently of the telemetry collection setting.
+type ProfileOptions struct {
+	// When set, the time spent in each step of the command is recorded and printed once the command completes.
+	Enabled bool
+	// When set, a CPU profile, a heap profile and an execution trace of the process are written to this folder.
+	Dir string
+}
+
+// The names of the files written to the folder of the runtime profiles
+const (
+	CpuProfileFileName  = "cpu.pprof"
+	HeapProfileFileName = "heap.pprof"
+	TraceFileName       = "trace.out"
+)
+
+// Profiler is an implementation of trace.SpanProcessor that records the spans of the command run, to break down the
+// time of the command by step.
+type Profiler struct {
+	mu    sync.Mutex
+	spans []trace.ReadOnlySpan
+}
+
+var profiler *Profiler
+
+// EnableProfiler records the spans of the command run, i.e. with `--profile`.
+// Must be called before the first call to GetTelemetrySystem to take effect.
+func EnableProfiler() {
+	profiler = &Profiler{}
+}
+
+// GetProfiler returns the profiler of the command run, nil when profiling isn't enabled.
+func GetProfiler() *Profiler {
+	return profiler
+}
+
+// OnStart is called when a span starts, the span is recorded once it ends.
+func (p *Profiler) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+}
+
+// OnEnd records the span.
+func (p *Profiler) OnEnd(s trace.ReadOnlySpan) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.spans = append(p.spans, s)
+}
+
+// Shutdown is called when the tracer provider shuts down, it performs no action.
+func (p *Profiler) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush is called to export the pending spans, it performs no action.
+func (p *Profiler) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// ProfileStep is the time spent in a step of the command, the spans of the same name aggregated
+type ProfileStep struct {
+	Name     string
+	Count    int
+	Duration time.Duration
+	// The nesting of the step in the command, 1 for the steps run by the command itself
+	Depth int
+}
+
+// Profile is the breakdown of the time of the command run by step
+type Profile struct {
+	Command  string
+	Duration time.Duration
+	// The steps of the command, ordered by the time they first started
+	Steps []ProfileStep
+}
+
+// Profile returns the breakdown of the last command run, nil when no command run was recorded.
+func (p *Profiler) Profile() *Profile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var command trace.ReadOnlySpan
+	for _, span := range p.spans {
+		if strings.HasPrefix(span.Name(), events.CommandEventPrefix) {
+			command = span
+		}
+	}
+
+	if command == nil {
+		return nil
+	}
+
+	parents := map[string]string{}
+	var spans []trace.ReadOnlySpan
+	for _, span := range p.spans {
+		if span.SpanContext().TraceID() != command.SpanContext().TraceID() || span == command {
+			continue
+		}
+
+		parents[span.SpanContext().SpanID().String()] = span.Parent().SpanID().String()
+		spans = append(spans, span)
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].StartTime().Before(spans[j].StartTime())
+	})
+
+	commandId := command.SpanContext().SpanID().String()
+	depth := func(spanId string) int {
+		depth := 1
+		for parent := parents[spanId]; parent != commandId && parent != ""; parent = parents[parent] {
+			depth++
+		}
+
+		return depth
+	}
+
+	profile := &Profile{
+		Command:  strings.TrimPrefix(command.Name(), events.CommandEventPrefix),
+		Duration: command.EndTime().Sub(command.StartTime()),
+	}
+
+	steps := map[string]int{}
+	for _, span := range spans {
+		index, has := steps[span.Name()]
+		if !has {
+			index = len(profile.Steps)
+			steps[span.Name()] = index
+			profile.Steps = append(profile.Steps, ProfileStep{
+				Name:  span.Name(),
+				Depth: depth(span.SpanContext().SpanID().String()),
+			})
+		}
+
+		profile.Steps[index].Count++
+		profile.Steps[index].Duration += span.EndTime().Sub(span.StartTime())
+	}
+
+	return profile
+}
+
+// StartRuntimeProfiles starts writing a CPU profile and an execution trace of the process to dir. The returned
+// function stops the profiles and writes a heap profile, it must be called before the process exits.
+func StartRuntimeProfiles(dir string) (func() error, error) {
+	if err := os.MkdirAll(dir, osutil.PermissionDirectory); err != nil {
+		return nil, fmt.Errorf("creating profile folder: %w", err)
+	}
+
+	cpuFile, err := os.Create(filepath.Join(dir, CpuProfileFileName))
+	if err != nil {
+		return nil, fmt.Errorf("creating CPU profile: %w", err)
+	}
+
+	if err := pprof.StartCPUProfile(cpuFile); err != nil {
+		cpuFile.Close()
+		return nil, fmt.Errorf("starting CPU profile: %w", err)
+	}
+
+	traceFile, err := os.Create(filepath.Join(dir, TraceFileName))
+	if err != nil {
+		pprof.StopCPUProfile()
+		cpuFile.Close()
+		return nil, fmt.Errorf("creating execution trace: %w", err)
+	}
+
+	if err := rtrace.Start(traceFile); err != nil {
+		pprof.StopCPUProfile()
+		cpuFile.Close()
+		traceFile.Close()
+		return nil, fmt.Errorf("starting execution trace: %w", err)
+	}
+
+	return func() error {
+		rtrace.Stop()
+		pprof.StopCPUProfile()
+
+		var errs []string
+		for _, file := range []*os.File{cpuFile, traceFile} {
+			if err := file.Close(); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+
+		if err := writeHeapProfile(filepath.Join(dir, HeapProfileFileName)); err != nil {
+			errs = append(errs, err.Error())
+		}
+
+		if len(errs) > 0 {
+			return errors.New(strings.Join(errs, "; "))
+		}
+
+		return nil
+	}, nil
+}
+
+func writeHeapProfile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating heap profile: %w", err)
+	}
+	defer file.Close()
+
+	// Collect the garbage first, so that the profile reflects the memory still in use
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(file); err != nil {
+		return fmt.Errorf("writing heap profile: %w", err)
+	}
+
+	return nil
+}
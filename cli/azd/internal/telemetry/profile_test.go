@@ -0,0 +1,66 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package telemetry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/trace"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestProfilerProfile(t *testing.T) {
+	profiler := &Profiler{}
+	tracer := trace.NewTracerProvider(trace.WithSpanProcessor(profiler)).Tracer("test")
+	startTime := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	span := func(ctx context.Context, name string, start time.Duration, duration time.Duration) context.Context {
+		ctx, span := tracer.Start(ctx, name, otelTrace.WithTimestamp(startTime.Add(start)))
+		span.End(otelTrace.WithTimestamp(startTime.Add(start + duration)))
+		return ctx
+	}
+
+	require.Nil(t, profiler.Profile())
+
+	ctx, command := tracer.Start(context.Background(), "cmd.provision", otelTrace.WithTimestamp(startTime))
+	span(ctx, "tools.check", 0, 2*time.Second)
+	deployCtx := span(ctx, "provision.deploy", 5*time.Second, 60*time.Second)
+	span(deployCtx, "bicep.build", 5*time.Second, 3*time.Second)
+	span(deployCtx, "bicep.build", 8*time.Second, 1*time.Second)
+	span(ctx, "provision.plan", 3*time.Second, 2*time.Second)
+	command.End(otelTrace.WithTimestamp(startTime.Add(70 * time.Second)))
+
+	// Spans of other traces aren't part of the profile of the command
+	span(context.Background(), "graph.request", 0, time.Second)
+
+	profile := profiler.Profile()
+	require.NotNil(t, profile)
+	require.Equal(t, "provision", profile.Command)
+	require.Equal(t, 70*time.Second, profile.Duration)
+	require.Equal(t, []ProfileStep{
+		{Name: "tools.check", Count: 1, Duration: 2 * time.Second, Depth: 1},
+		{Name: "provision.plan", Count: 1, Duration: 2 * time.Second, Depth: 1},
+		{Name: "provision.deploy", Count: 1, Duration: 60 * time.Second, Depth: 1},
+		{Name: "bicep.build", Count: 2, Duration: 4 * time.Second, Depth: 2},
+	}, profile.Steps)
+}
+
+func TestStartRuntimeProfiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profiles")
+
+	stop, err := StartRuntimeProfiles(dir)
+	require.NoError(t, err)
+	require.NoError(t, stop())
+
+	for _, name := range []string{CpuProfileFileName, HeapProfileFileName, TraceFileName} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		require.NoError(t, err)
+		require.NotZero(t, info.Size(), name)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -26,6 +27,9 @@ import (
 // the equivalent of AZURE_CORE_COLLECT_TELEMETRY
 const collectTelemetryEnvVar = "AZURE_DEV_COLLECT_TELEMETRY"
 
+// The user config path used to opt out of telemetry, i.e. `azd config set telemetry.enabled false`
+const TelemetryEnabledConfigPath = "telemetry.enabled"
+
 const telemetryItemExtension = ".trn"
 
 //nolint:lll
@@ -58,8 +62,49 @@ func getTelemetryDirectory() (string, error) {
 	return telemetryDir, nil
 }
 
+// Set when telemetry is disabled for the current process with `--no-telemetry`
+var disabledForProcess bool
+
+// DisableTelemetry opts out of telemetry for the current process, i.e. with `--no-telemetry`.
+// Must be called before the first call to GetTelemetrySystem to take effect.
+func DisableTelemetry() {
+	disabledForProcess = true
+}
+
 func IsTelemetryEnabled() bool {
-	return os.Getenv(collectTelemetryEnvVar) != "no"
+	if disabledForProcess || os.Getenv(collectTelemetryEnvVar) == "no" {
+		return false
+	}
+
+	configFilePath, err := config.GetUserConfigFilePath()
+	if err != nil {
+		return true
+	}
+
+	userConfig, err := config.NewManager().Load(configFilePath)
+	if err != nil {
+		// A missing or invalid user config never opts out of telemetry
+		return true
+	}
+
+	return !isTelemetryDisabledInConfig(userConfig)
+}
+
+// isTelemetryDisabledInConfig returns true when the user has opted out of telemetry within the azd user config
+func isTelemetryDisabledInConfig(userConfig config.Config) bool {
+	value, ok := userConfig.Get(TelemetryEnabledConfigPath)
+	if !ok {
+		return false
+	}
+
+	switch typed := value.(type) {
+	case bool:
+		return !typed
+	case string:
+		return strings.EqualFold(typed, "false") || strings.EqualFold(typed, "no")
+	default:
+		return false
+	}
 }
 
 // Returns the singleton TelemetrySystem instance.
@@ -78,9 +123,26 @@ func GetTelemetrySystem() *TelemetrySystem {
 }
 
 func initialize() (*TelemetrySystem, error) {
+	traceLogExporters, err := newTraceLogExporters(traceLogOptions)
+	if err != nil {
+		traceLogErr = err
+		log.Printf("failed to initialize trace log: %v\n", err)
+	}
+
 	if !IsTelemetryEnabled() {
 		log.Println("telemetry is disabled by user and will not be initialized.")
-		return nil, nil
+
+		if len(traceLogExporters) == 0 && profiler == nil {
+			return nil, nil
+		}
+
+		// Local trace logs and profiles are still written when the user has opted out of telemetry collection
+		tp := newTracerProvider(traceLogExporters)
+		otel.SetTracerProvider(tp)
+
+		return &TelemetrySystem{
+			tracerProvider: tp,
+		}, nil
 	}
 
 	appinsightsexporter.SetListener(func(msg string) {
@@ -110,10 +172,7 @@ func initialize() (*TelemetrySystem, error) {
 
 	exporter := NewExporter(storageQueue, config.InstrumentationKey)
 
-	tp := trace.NewTracerProvider(
-		trace.WithBatcher(exporter),
-		trace.WithResource(resource.New()),
-	)
+	tp := newTracerProvider(append([]trace.SpanExporter{exporter}, traceLogExporters...))
 	otel.SetTracerProvider(tp)
 
 	return &TelemetrySystem{
@@ -125,6 +184,23 @@ func initialize() (*TelemetrySystem, error) {
 	}, nil
 }
 
+func newTracerProvider(exporters []trace.SpanExporter) *trace.TracerProvider {
+	options := []trace.TracerProviderOption{
+		trace.WithResource(resource.New()),
+	}
+
+	for _, exporter := range exporters {
+		options = append(options, trace.WithBatcher(exporter))
+	}
+
+	// The profiler records the spans as they end, the breakdown is printed before the exporters are flushed
+	if profiler != nil {
+		options = append(options, trace.WithSpanProcessor(profiler))
+	}
+
+	return trace.NewTracerProvider(options...)
+}
+
 // Flushes all ongoing telemetry and shuts down telemetry
 func (ts *TelemetrySystem) Shutdown(ctx context.Context) error {
 	return instance.tracerProvider.Shutdown(ctx)
@@ -137,6 +213,11 @@ func (ts *TelemetrySystem) GetTelemetryQueue() Queue {
 
 // Returns true if any telemetry was emitted.
 func (ts *TelemetrySystem) EmittedAnyTelemetry() bool {
+	// The exporter is not set when only local trace logs are enabled
+	if ts.exporter == nil {
+		return false
+	}
+
 	return ts.exporter.ExportedAny()
 }
 
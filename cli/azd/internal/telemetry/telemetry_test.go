@@ -7,6 +7,7 @@ import (
 
 	"github.com/azure/azure-dev/cli/azd/internal"
 	appinsightsexporter "github.com/azure/azure-dev/cli/azd/internal/telemetry/appinsights-exporter"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/test/ostest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -81,6 +82,27 @@ func TestGetTelemetrySystem(t *testing.T) {
 	}
 }
 
+func TestIsTelemetryDisabledInConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     map[string]any
+		expected bool
+	}{
+		{"Unset", map[string]any{}, false},
+		{"StringFalse", map[string]any{"telemetry": map[string]any{"enabled": "false"}}, true},
+		{"StringNo", map[string]any{"telemetry": map[string]any{"enabled": "no"}}, true},
+		{"StringTrue", map[string]any{"telemetry": map[string]any{"enabled": "true"}}, false},
+		{"BoolFalse", map[string]any{"telemetry": map[string]any{"enabled": false}}, true},
+		{"BoolTrue", map[string]any{"telemetry": map[string]any{"enabled": true}}, false},
+		{"Invalid", map[string]any{"telemetry": map[string]any{"enabled": 1}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isTelemetryDisabledInConfig(config.NewConfig(tt.data)))
+		})
+	}
+}
+
 func TestTelemetrySystem_RunBackgroundUpload(t *testing.T) {
 	type args struct {
 		ctx                context.Context
@@ -0,0 +1,337 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// TraceLogOptions configures the local trace exporters used for performance debugging.
+// These exporters run independently of the telemetry collection setting.
+type TraceLogOptions struct {
+	// When set, spans are written as JSON lines to this file path.
+	File string
+	// When set, spans are sent to this OTLP/HTTP endpoint, i.e. http://localhost:4318
+	Url string
+}
+
+var traceLogOptions TraceLogOptions
+
+// The error creating the trace log exporters, set when telemetry is initialized
+var traceLogErr error
+
+// SetTraceLogOptions configures the local trace exporters.
+// Must be called before the first call to GetTelemetrySystem to take effect.
+func SetTraceLogOptions(options TraceLogOptions) {
+	traceLogOptions = options
+}
+
+// TraceLogError returns the error creating the exporters requested by the trace log options, if any.
+// The exporters that failed are skipped, the others and telemetry collection aren't affected.
+// Set by the first call to GetTelemetrySystem.
+func TraceLogError() error {
+	return traceLogErr
+}
+
+// newTraceLogExporters creates the span exporters requested by the trace log options. An exporter that can't be
+// created is skipped, its error is returned along with the other exporters.
+func newTraceLogExporters(options TraceLogOptions) ([]trace.SpanExporter, error) {
+	exporters := []trace.SpanExporter{}
+
+	var err error
+	if options.File != "" {
+		fileExporter, fileErr := newFileExporter(options.File)
+		if fileErr != nil {
+			err = fileErr
+		} else {
+			exporters = append(exporters, fileExporter)
+		}
+	}
+
+	if options.Url != "" {
+		exporters = append(exporters, newOtlpExporter(options.Url, http.DefaultClient))
+	}
+
+	return exporters, err
+}
+
+// traceLogSpan is the JSON representation of a span written to the trace log file
+type traceLogSpan struct {
+	Name          string            `json:"name"`
+	TraceId       string            `json:"traceId"`
+	SpanId        string            `json:"spanId"`
+	ParentSpanId  string            `json:"parentSpanId,omitempty"`
+	StartTime     time.Time         `json:"startTime"`
+	EndTime       time.Time         `json:"endTime"`
+	DurationMs    int64             `json:"durationMs"`
+	Status        string            `json:"status"`
+	StatusMessage string            `json:"statusMessage,omitempty"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
+}
+
+// fileExporter is an implementation of trace.SpanExporter that writes spans as JSON lines to a file.
+type fileExporter struct {
+	mu     sync.Mutex
+	writer io.WriteCloser
+}
+
+func newFileExporter(path string) (*fileExporter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, osutil.PermissionFile)
+	if err != nil {
+		return nil, fmt.Errorf("opening trace log file: %w", err)
+	}
+
+	return &fileExporter{writer: file}, nil
+}
+
+// ExportSpans writes the spans to the trace log file.
+func (e *fileExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	encoder := json.NewEncoder(e.writer)
+	for _, span := range spans {
+		if err := encoder.Encode(toTraceLogSpan(span)); err != nil {
+			return fmt.Errorf("writing trace log: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Shutdown closes the trace log file.
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.writer.Close()
+}
+
+func toTraceLogSpan(span trace.ReadOnlySpan) traceLogSpan {
+	logSpan := traceLogSpan{
+		Name:          span.Name(),
+		TraceId:       span.SpanContext().TraceID().String(),
+		SpanId:        span.SpanContext().SpanID().String(),
+		StartTime:     span.StartTime(),
+		EndTime:       span.EndTime(),
+		DurationMs:    span.EndTime().Sub(span.StartTime()).Milliseconds(),
+		Status:        span.Status().Code.String(),
+		StatusMessage: span.Status().Description,
+	}
+
+	if span.Parent().HasSpanID() {
+		logSpan.ParentSpanId = span.Parent().SpanID().String()
+	}
+
+	if len(span.Attributes()) > 0 {
+		logSpan.Attributes = map[string]string{}
+		for _, kv := range span.Attributes() {
+			logSpan.Attributes[string(kv.Key)] = kv.Value.Emit()
+		}
+	}
+
+	return logSpan
+}
+
+// otlpExporter is an implementation of trace.SpanExporter that sends spans to an OTLP/HTTP endpoint
+// using the JSON protobuf encoding.
+type otlpExporter struct {
+	url        string
+	httpClient *http.Client
+}
+
+func newOtlpExporter(endpoint string, httpClient *http.Client) *otlpExporter {
+	url := strings.TrimSuffix(endpoint, "/")
+	if !strings.HasSuffix(url, "/v1/traces") {
+		url = url + "/v1/traces"
+	}
+
+	return &otlpExporter{
+		url:        url,
+		httpClient: httpClient,
+	}
+}
+
+// ExportSpans sends the spans to the OTLP endpoint.
+func (e *otlpExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(toOtlpRequest(spans))
+	if err != nil {
+		return fmt.Errorf("serializing spans: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating OTLP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := e.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending spans to OTLP endpoint: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("sending spans to OTLP endpoint: unexpected status code %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// Shutdown is called to stop the exporter, it performs no action.
+func (e *otlpExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceId           string         `json:"traceId"`
+	SpanId            string         `json:"spanId"`
+	ParentSpanId      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+// toOtlpRequest groups the spans by resource, then by instrumentation scope, as expected by OTLP
+func toOtlpRequest(spans []trace.ReadOnlySpan) otlpRequest {
+	resourceSpans := []otlpResourceSpans{}
+	resourceIndexes := map[attribute.Distinct]int{}
+	scopeIndexes := map[attribute.Distinct]map[otlpScope]int{}
+
+	for _, span := range spans {
+		item := otlpSpan{
+			TraceId:           span.SpanContext().TraceID().String(),
+			SpanId:            span.SpanContext().SpanID().String(),
+			Name:              span.Name(),
+			Kind:              int(span.SpanKind()),
+			StartTimeUnixNano: strconv.FormatInt(span.StartTime().UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.EndTime().UnixNano(), 10),
+			Attributes:        toOtlpAttributes(span.Attributes()),
+			Status:            toOtlpStatus(span.Status().Code, span.Status().Description),
+		}
+
+		if span.Parent().HasSpanID() {
+			item.ParentSpanId = span.Parent().SpanID().String()
+		}
+
+		resourceKey := span.Resource().Equivalent()
+		resourceIndex, has := resourceIndexes[resourceKey]
+		if !has {
+			resourceIndex = len(resourceSpans)
+			resourceIndexes[resourceKey] = resourceIndex
+			scopeIndexes[resourceKey] = map[otlpScope]int{}
+			resourceSpans = append(resourceSpans, otlpResourceSpans{
+				Resource:   otlpResource{Attributes: toOtlpAttributes(span.Resource().Attributes())},
+				ScopeSpans: []otlpScopeSpans{},
+			})
+		}
+
+		scope := otlpScope{
+			Name:    span.InstrumentationLibrary().Name,
+			Version: span.InstrumentationLibrary().Version,
+		}
+
+		scopeSpans := &resourceSpans[resourceIndex].ScopeSpans
+		scopeIndex, has := scopeIndexes[resourceKey][scope]
+		if !has {
+			scopeIndex = len(*scopeSpans)
+			scopeIndexes[resourceKey][scope] = scopeIndex
+			*scopeSpans = append(*scopeSpans, otlpScopeSpans{Scope: scope, Spans: []otlpSpan{}})
+		}
+
+		(*scopeSpans)[scopeIndex].Spans = append((*scopeSpans)[scopeIndex].Spans, item)
+	}
+
+	return otlpRequest{ResourceSpans: resourceSpans}
+}
+
+func toOtlpStatus(code codes.Code, description string) otlpStatus {
+	// OTLP status codes: 0 = unset, 1 = ok, 2 = error
+	switch code {
+	case codes.Ok:
+		return otlpStatus{Code: 1}
+	case codes.Error:
+		return otlpStatus{Code: 2, Message: description}
+	default:
+		return otlpStatus{Code: 0}
+	}
+}
+
+func toOtlpAttributes(kvs []attribute.KeyValue) []otlpKeyValue {
+	attributes := make([]otlpKeyValue, 0, len(kvs))
+
+	for _, kv := range kvs {
+		var value map[string]any
+
+		switch kv.Value.Type() {
+		case attribute.BOOL:
+			value = map[string]any{"boolValue": kv.Value.AsBool()}
+		case attribute.INT64:
+			value = map[string]any{"intValue": strconv.FormatInt(kv.Value.AsInt64(), 10)}
+		case attribute.FLOAT64:
+			value = map[string]any{"doubleValue": kv.Value.AsFloat64()}
+		default:
+			value = map[string]any{"stringValue": kv.Value.Emit()}
+		}
+
+		attributes = append(attributes, otlpKeyValue{Key: string(kv.Key), Value: value})
+	}
+
+	return attributes
+}
@@ -0,0 +1,138 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+func testSpans() []trace.ReadOnlySpan {
+	startTime := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	traceId := otelTrace.TraceID{0x01}
+
+	return tracetest.SpanStubs{
+		{
+			Name: "provision.deploy",
+			SpanContext: otelTrace.NewSpanContext(otelTrace.SpanContextConfig{
+				TraceID: traceId,
+				SpanID:  otelTrace.SpanID{0x02},
+			}),
+			Parent: otelTrace.NewSpanContext(otelTrace.SpanContextConfig{
+				TraceID: traceId,
+				SpanID:  otelTrace.SpanID{0x01},
+			}),
+			StartTime:  startTime,
+			EndTime:    startTime.Add(90 * time.Second),
+			Attributes: []attribute.KeyValue{attribute.String("provision.provider", "bicep")},
+			Status:     trace.Status{Code: codes.Error, Description: "UnknownError"},
+		},
+	}.Snapshots()
+}
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.log")
+
+	exporter, err := newFileExporter(path)
+	require.NoError(t, err)
+	require.NoError(t, exporter.ExportSpans(context.Background(), testSpans()))
+	require.NoError(t, exporter.Shutdown(context.Background()))
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+	require.Len(t, lines, 1)
+
+	var span traceLogSpan
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &span))
+	require.Equal(t, "provision.deploy", span.Name)
+	require.Equal(t, "0200000000000000", span.SpanId)
+	require.Equal(t, "0100000000000000", span.ParentSpanId)
+	require.Equal(t, int64(90000), span.DurationMs)
+	require.Equal(t, "Error", span.Status)
+	require.Equal(t, "bicep", span.Attributes["provision.provider"])
+}
+
+func TestOtlpExporter(t *testing.T) {
+	var requestPath string
+	var request map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestPath = r.URL.Path
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &request))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	exporter := newOtlpExporter(server.URL, server.Client())
+	require.NoError(t, exporter.ExportSpans(context.Background(), testSpans()))
+
+	require.Equal(t, "/v1/traces", requestPath)
+
+	resourceSpans := request["resourceSpans"].([]any)
+	require.Len(t, resourceSpans, 1)
+	scopeSpans := resourceSpans[0].(map[string]any)["scopeSpans"].([]any)
+	span := scopeSpans[0].(map[string]any)["spans"].([]any)[0].(map[string]any)
+
+	require.Equal(t, "provision.deploy", span["name"])
+	require.Equal(t, "0100000000000000", span["parentSpanId"])
+	require.Equal(t, float64(2), span["status"].(map[string]any)["code"])
+}
+
+func TestOtlpRequestBatchesSpans(t *testing.T) {
+	spans := append(testSpans(), tracetest.SpanStubs{
+		{
+			Name: "provision.plan",
+			SpanContext: otelTrace.NewSpanContext(otelTrace.SpanContextConfig{
+				TraceID: otelTrace.TraceID{0x01},
+				SpanID:  otelTrace.SpanID{0x03},
+			}),
+		},
+	}.Snapshots()...)
+
+	request := toOtlpRequest(spans)
+	require.Len(t, request.ResourceSpans, 1)
+	require.Len(t, request.ResourceSpans[0].ScopeSpans, 1)
+
+	batch := request.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, batch, 2)
+	require.Equal(t, "provision.deploy", batch[0].Name)
+	require.Equal(t, "provision.plan", batch[1].Name)
+}
+
+func TestNewTraceLogExporters(t *testing.T) {
+	// The OTLP exporter is kept when the trace log file can't be created
+	exporters, err := newTraceLogExporters(TraceLogOptions{
+		File: filepath.Join(t.TempDir(), "missing", "trace.log"),
+		Url:  "http://localhost:4318",
+	})
+	require.Error(t, err)
+	require.Len(t, exporters, 1)
+	require.IsType(t, &otlpExporter{}, exporters[0])
+}
+
+func TestOtlpExporterFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	exporter := newOtlpExporter(server.URL+"/v1/traces", server.Client())
+	require.Error(t, exporter.ExportSpans(context.Background(), testSpans()))
+}
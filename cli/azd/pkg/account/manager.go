@@ -103,11 +103,16 @@ func (m *Manager) GetSubscriptions(ctx context.Context) ([]*azcli.AzCliSubscript
 		accounts[0].IsDefault = true
 	}
 
+	// A default subscription set in azd config takes precedence over the az CLI default
+	_, hasConfigDefault := m.config.Get(defaultSubscriptionKeyPath)
+
 	// If default subscription is set, set it in the results
 	results := []*azcli.AzCliSubscriptionInfo{}
 	for _, sub := range accounts {
 		if sub.Id == defaultSubscription.Id {
 			sub.IsDefault = true
+		} else if hasConfigDefault {
+			sub.IsDefault = false
 		}
 		results = append(results, sub)
 	}
@@ -225,39 +230,37 @@ func (m *Manager) getAllSubscriptions(ctx context.Context) ([]*azcli.AzCliSubscr
 func (m *Manager) getDefaultSubscription(ctx context.Context) (*Subscription, error) {
 	// Get the default subscription ID from azd configuration
 	configSubscriptionId, ok := m.config.Get(defaultSubscriptionKeyPath)
-	var defaultSubscription *Subscription
 
 	if ok {
 		subscriptionId := fmt.Sprint(configSubscriptionId)
 		subscription, err := m.azCli.GetAccount(ctx, subscriptionId)
-		if err != nil {
-			log.Printf("failed retrieving subscription with ID '%s'. %s", subscriptionId, err.Error())
+		if err == nil {
+			return &Subscription{
+				Id:       subscription.Id,
+				Name:     subscription.Name,
+				TenantId: subscription.TenantId,
+			}, nil
 		}
 
-		defaultSubscription = &Subscription{
-			Id:       subscription.Id,
-			Name:     subscription.Name,
-			TenantId: subscription.TenantId,
-		}
-	} else {
-		// No defaults subscription has been set in azd config
-		allSubscriptions, err := m.getAllSubscriptions(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed retrieving subscriptions for current account. %w", err)
-		}
+		// The configured default may have been set manually, i.e. `azd config set defaults.subscription <id>`
+		log.Printf("failed retrieving subscription with ID '%s', ignoring configured default. %s", subscriptionId, err.Error())
+	}
 
-		if len(allSubscriptions) == 0 {
-			return nil, errors.New("no subscriptions found for current account")
-		}
+	// No valid default subscription has been set in azd config
+	allSubscriptions, err := m.getAllSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving subscriptions for current account. %w", err)
+	}
 
-		defaultSubscription = &Subscription{
-			Id:       allSubscriptions[0].Id,
-			Name:     allSubscriptions[0].Name,
-			TenantId: allSubscriptions[0].TenantId,
-		}
+	if len(allSubscriptions) == 0 {
+		return nil, errors.New("no subscriptions found for current account")
 	}
 
-	return defaultSubscription, nil
+	return &Subscription{
+		Id:       allSubscriptions[0].Id,
+		Name:     allSubscriptions[0].Name,
+		TenantId: allSubscriptions[0].TenantId,
+	}, nil
 }
 
 // Gets the default Azure location for the specified subscription
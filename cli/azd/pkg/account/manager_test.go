@@ -51,6 +51,34 @@ func Test_GetAccountDefaults(t *testing.T) {
 		require.Equal(t, "westus", accountDefaults.DefaultLocation.Name)
 	})
 
+	t.Run("InvalidSubscriptionInAzdConfig", func(t *testing.T) {
+		subscription := Subscription{
+			Id:       "MISSING_SUBSCRIPTION",
+			Name:     "Missing Subscription",
+			TenantId: "TENANT_ID",
+		}
+
+		invalidConfig := config.NewConfig(map[string]any{
+			"defaults": map[string]any{
+				"subscription": "MISSING_SUBSCRIPTION",
+			},
+		})
+
+		mockContext := mocks.NewMockContext(context.Background())
+		setupAccountMocks(mockContext)
+		setupGetSubscriptionMock(mockContext, &subscription, errors.New("not found"))
+
+		manager, err := NewManager(
+			mockContext.ConfigManager.WithConfig(invalidConfig),
+			azcli.GetAzCli(*mockContext.Context),
+		)
+		require.NoError(t, err)
+
+		accountDefaults := manager.GetAccountDefaults(*mockContext.Context)
+
+		require.Equal(t, *allTestSubscriptions[0].SubscriptionID, accountDefaults.DefaultSubscription.Id)
+	})
+
 	t.Run("FromCodeDefaults", func(t *testing.T) {
 		emptyConfig := config.NewConfig(nil)
 
@@ -7,7 +7,15 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 	"github.com/microsoft/azure-devops-go-api/azuredevops"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/build"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/operations"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/policy"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/serviceendpoint"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/taskagent"
 )
 
 var (
@@ -21,6 +29,15 @@ var (
 	AzDoEnvironmentProjectIdName = "AZURE_DEVOPS_PROJECT_ID"
 	// Environment Configuration name used to store the project name
 	AzDoEnvironmentProjectName = "AZURE_DEVOPS_PROJECT_NAME"
+	// Environment Configuration name of the process (Basic, Agile, Scrum etc) of the projects created by azd, the
+	// process is selected by the user when not set
+	AzDoEnvironmentProjectProcessName = "AZURE_DEVOPS_PROJECT_PROCESS"
+	// Environment Configuration name of the visibility (private or public) of the projects created by azd, the
+	// visibility is selected by the user when not set
+	AzDoEnvironmentProjectVisibilityName = "AZURE_DEVOPS_PROJECT_VISIBILITY"
+	// Environment Configuration name of the existing service connection the pipeline signs in with, instead of a
+	// service connection created by azd
+	AzDoEnvironmentServiceConnectionName = "AZURE_DEVOPS_SERVICE_CONNECTION_NAME"
 	// Environment Configuration name used to store repo ID
 	AzDoEnvironmentRepoIdName = "AZURE_DEVOPS_REPOSITORY_ID"
 	// Environment Configuration name used to store the Repo Name
@@ -34,10 +51,24 @@ var (
 	AzurePipelineName = "Azure Dev Deploy"
 	// path to the azure pipeline yaml
 	AzurePipelineYamlPath = ".azdo/pipelines/azure-dev.yml"
+	// name of the azure pipeline rotating the credential of the service principal on a schedule
+	AzureRotationPipelineName = "Azure Dev Rotate Credentials"
+	// path to the yaml of the azure pipeline rotating the credential of the service principal
+	AzureRotationPipelineYamlPath = ".azdo/pipelines/azure-dev-rotate.yml"
+	// name of the azure pipeline provisioning the infrastructure for its changes, with --infra-only
+	AzureInfraPipelineName = "Azure Dev Provision"
+	// path to the yaml of the azure pipeline provisioning the infrastructure
+	AzureInfraPipelineYamlPath = ".azdo/pipelines/azure-dev-infra.yml"
+	// name of the azure pipeline deploying the code for its changes, with --code-only
+	AzureCodePipelineName = "Azure Dev Deploy Code"
+	// path to the yaml of the azure pipeline deploying the code
+	AzureCodePipelineYamlPath = ".azdo/pipelines/azure-dev-code.yml"
+	// name of the azure pipeline deleting the expired environments on a schedule
+	AzureCleanupPipelineName = "Azure Dev Cleanup"
+	// path to the yaml of the azure pipeline deleting the expired environments
+	AzureCleanupPipelineYamlPath = ".azdo/pipelines/azure-dev-cleanup.yml"
 	// target Azure Cloud
 	CloudEnvironment = "AzureCloud"
-	// default branch for pipeline and branch policy
-	DefaultBranch = "main"
 	// azure devops project description
 	AzDoProjectDescription = "Azure Developer CLI Project"
 	// name of the service connection that will be used in the AzDo project. This will store the Azure service principal
@@ -63,7 +94,77 @@ func GetConnection(
 	}
 
 	organizationUrl := fmt.Sprintf("https://%s/%s", AzDoHostName, organization)
-	connection := azuredevops.NewPatConnection(organizationUrl, personalAccessToken)
+	return newPatConnection(ctx, organizationUrl, personalAccessToken), nil
+}
+
+// newPatConnection creates a connection authenticated with personalAccessToken, applying the timeout configured by
+// the user for Azure DevOps requests
+func newPatConnection(ctx context.Context, url string, personalAccessToken string) *azuredevops.Connection {
+	connection := azuredevops.NewPatConnection(url, personalAccessToken)
+
+	if clientPolicy := httputil.GetClientPolicy(ctx, httputil.AzdoClient); clientPolicy.Timeout > 0 {
+		connection.Timeout = &clientPolicy.Timeout
+	}
+
+	return connection
+}
+
+// Clients creates the clients of the Azure DevOps APIs for an organization. The functions of the package take Clients
+// rather than a connection, so they can be tested with mock clients.
+type Clients interface {
+	Core(ctx context.Context) (core.Client, error)
+	Operations(ctx context.Context) operations.Client
+	Git(ctx context.Context) (git.Client, error)
+	Build(ctx context.Context) (build.Client, error)
+	TaskAgent(ctx context.Context) (taskagent.Client, error)
+	Policy(ctx context.Context) (policy.Client, error)
+	ServiceEndpoint(ctx context.Context) (serviceendpoint.Client, error)
+}
+
+// NewClients returns the clients of the Azure DevOps APIs connecting with the connection
+func NewClients(connection *azuredevops.Connection) Clients {
+	return &connectionClients{connection: connection}
+}
+
+// GetClients returns the clients of the Azure DevOps APIs for the organization, authenticated with the personal access
+// token, like GetConnection
+func GetClients(ctx context.Context, organization string, personalAccessToken string) (Clients, error) {
+	connection, err := GetConnection(ctx, organization, personalAccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClients(connection), nil
+}
+
+type connectionClients struct {
+	connection *azuredevops.Connection
+}
+
+func (c *connectionClients) Core(ctx context.Context) (core.Client, error) {
+	return core.NewClient(ctx, c.connection)
+}
+
+func (c *connectionClients) Operations(ctx context.Context) operations.Client {
+	return operations.NewClient(ctx, c.connection)
+}
+
+func (c *connectionClients) Git(ctx context.Context) (git.Client, error) {
+	return git.NewClient(ctx, c.connection)
+}
+
+func (c *connectionClients) Build(ctx context.Context) (build.Client, error) {
+	return build.NewClient(ctx, c.connection)
+}
+
+func (c *connectionClients) TaskAgent(ctx context.Context) (taskagent.Client, error) {
+	return taskagent.NewClient(ctx, c.connection)
+}
+
+func (c *connectionClients) Policy(ctx context.Context) (policy.Client, error) {
+	return policy.NewClient(ctx, c.connection)
+}
 
-	return connection, nil
+func (c *connectionClients) ServiceEndpoint(ctx context.Context) (serviceendpoint.Client, error) {
+	return serviceendpoint.NewClient(ctx, c.connection)
 }
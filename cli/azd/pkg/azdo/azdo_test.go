@@ -7,6 +7,13 @@ import (
 	"context"
 	"testing"
 
+	"github.com/microsoft/azure-devops-go-api/azuredevops/build"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/operations"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/policy"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/serviceendpoint"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/taskagent"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -27,3 +34,42 @@ func Test_getAzdoConnection(t *testing.T) {
 		assert.NotNil(t, connection)
 	})
 }
+
+// MockClients returns the mock clients of the test, the clients left nil aren't expected to be used
+type MockClients struct {
+	core            core.Client
+	operations      operations.Client
+	git             git.Client
+	build           build.Client
+	taskAgent       taskagent.Client
+	policy          policy.Client
+	serviceEndpoint serviceendpoint.Client
+}
+
+func (c *MockClients) Core(ctx context.Context) (core.Client, error) {
+	return c.core, nil
+}
+
+func (c *MockClients) Operations(ctx context.Context) operations.Client {
+	return c.operations
+}
+
+func (c *MockClients) Git(ctx context.Context) (git.Client, error) {
+	return c.git, nil
+}
+
+func (c *MockClients) Build(ctx context.Context) (build.Client, error) {
+	return c.build, nil
+}
+
+func (c *MockClients) TaskAgent(ctx context.Context) (taskagent.Client, error) {
+	return c.taskAgent, nil
+}
+
+func (c *MockClients) Policy(ctx context.Context) (policy.Client, error) {
+	return c.policy, nil
+}
+
+func (c *MockClients) ServiceEndpoint(ctx context.Context) (serviceendpoint.Client, error) {
+	return c.serviceEndpoint, nil
+}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
-	"github.com/microsoft/azure-devops-go-api/azuredevops"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/build"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/policy"
 )
@@ -30,16 +29,20 @@ func getBuildType(ctx context.Context, projectId *string, policyClient policy.Cl
 	return nil, fmt.Errorf("could not find 'Build' policy type in project")
 }
 
-// create the PR build policy to ensure that the pipeline runs on a new pull request
-// this also disables direct pushes to the default branch and requires changes to go through a PR.
+// create the PR build policy to ensure that the pipeline runs on a new pull request to the branch
+// this also disables direct pushes to the branch and requires changes to go through a PR.
 func CreateBuildPolicy(
 	ctx context.Context,
-	connection *azuredevops.Connection,
+	clients Clients,
 	projectId string,
 	repoId string,
+	branch string,
 	buildDefinition *build.BuildDefinition,
-	env *environment.Environment) error {
-	client, err := policy.NewClient(ctx, connection)
+	env *environment.Environment) (err error) {
+	endSpan := startSpan(ctx, "policy.create")
+	defer func() { endSpan(err) }()
+
+	client, err := clients.Policy(ctx)
 	if err != nil {
 		return err
 	}
@@ -59,7 +62,7 @@ func CreateBuildPolicy(
 
 	policySettingsScope := map[string]interface{}{
 		"repositoryId": repoId,
-		"refName":      fmt.Sprintf("refs/heads/%s", DefaultBranch),
+		"refName":      branchRef(branch),
 		"matchKind":    "Exact",
 	}
 
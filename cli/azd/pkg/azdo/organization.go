@@ -0,0 +1,96 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azdo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/accounts"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/profile"
+)
+
+// url of the service hosting the profiles and the organizations of the Azure DevOps users
+const azDoProfileServiceUrl = "https://app.vssps.visualstudio.com"
+
+// option of the organization prompt used to type the name of an organization
+const manualOrgNameEntryOption = "Other (enter manually)"
+
+// returns the names of the Azure DevOps organizations the owner of the PAT is a member of
+func listOrganizations(ctx context.Context, personalAccessToken string) (_ []string, err error) {
+	endSpan := startSpan(ctx, "organization.list")
+	defer func() { endSpan(err) }()
+
+	connection := newPatConnection(ctx, azDoProfileServiceUrl, personalAccessToken)
+
+	profileClient, err := profile.NewClient(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	accountsClient, err := accounts.NewClient(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	return getOrganizationNames(ctx, profileClient, accountsClient)
+}
+
+// returns the names of the organizations the authenticated user is a member of, sorted by name
+func getOrganizationNames(
+	ctx context.Context,
+	profileClient profile.Client,
+	accountsClient accounts.Client,
+) ([]string, error) {
+	me := "me"
+	userProfile, err := profileClient.GetProfile(ctx, profile.GetProfileArgs{Id: &me})
+	if err != nil {
+		return nil, fmt.Errorf("getting azure devops profile: %w", err)
+	}
+
+	organizations, err := accountsClient.GetAccounts(ctx, accounts.GetAccountsArgs{MemberId: userProfile.Id})
+	if err != nil {
+		return nil, fmt.Errorf("listing azure devops organizations: %w", err)
+	}
+
+	names := []string{}
+	for _, organization := range *organizations {
+		if organization.AccountName != nil {
+			names = append(names, *organization.AccountName)
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// prompts to select one of the organizations or to type the name of another organization
+func promptOrgName(ctx context.Context, console input.Console, orgNames []string) (string, error) {
+	if len(orgNames) > 0 {
+		options := append(append([]string{}, orgNames...), manualOrgNameEntryOption)
+		orgIdx, err := console.Select(ctx, input.ConsoleOptions{
+			Message: "Please choose an Azure DevOps Organization:",
+			Options: options,
+		})
+		if err != nil {
+			return "", fmt.Errorf("prompting for azdo organization: %w", err)
+		}
+
+		if options[orgIdx] != manualOrgNameEntryOption {
+			return options[orgIdx], nil
+		}
+	}
+
+	orgName, err := console.Prompt(ctx, input.ConsoleOptions{
+		Message:      "Please enter an Azure DevOps Organization Name:",
+		DefaultValue: "",
+	})
+	if err != nil {
+		return "", fmt.Errorf("asking for organization name: %w", err)
+	}
+
+	return orgName, nil
+}
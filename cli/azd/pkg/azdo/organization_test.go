@@ -0,0 +1,98 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azdo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/console"
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/accounts"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/profile"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_getOrganizationNames(t *testing.T) {
+	ctx := context.Background()
+	userId := uuid.New()
+	profileClient := MockProfileClient{profile: &profile.Profile{Id: &userId}}
+	accountsClient := MockAccountsClient{
+		accounts: []accounts.Account{
+			{AccountName: strPtr("fabrikam")},
+			{AccountName: strPtr("contoso")},
+		},
+	}
+
+	names, err := getOrganizationNames(ctx, &profileClient, &accountsClient)
+	require.NoError(t, err)
+	require.Equal(t, []string{"contoso", "fabrikam"}, names)
+	require.Equal(t, "me", *profileClient.getProfileArgs.Id)
+	require.Equal(t, userId, *accountsClient.getAccountsArgs.MemberId)
+}
+
+func Test_promptOrgName(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("selects an organization", func(t *testing.T) {
+		testConsole := console.NewMockConsole()
+		testConsole.WhenSelect(func(options input.ConsoleOptions) bool {
+			return options.Message == "Please choose an Azure DevOps Organization:"
+		}).Respond(1)
+
+		orgName, err := promptOrgName(ctx, testConsole, []string{"contoso", "fabrikam"})
+		require.NoError(t, err)
+		require.Equal(t, "fabrikam", orgName)
+	})
+
+	t.Run("enters another organization", func(t *testing.T) {
+		testConsole := console.NewMockConsole()
+		testConsole.WhenSelect(func(options input.ConsoleOptions) bool {
+			return options.Message == "Please choose an Azure DevOps Organization:"
+		}).Respond(2)
+		testConsole.WhenPrompt(func(options input.ConsoleOptions) bool {
+			return options.Message == "Please enter an Azure DevOps Organization Name:"
+		}).Respond("northwind")
+
+		orgName, err := promptOrgName(ctx, testConsole, []string{"contoso", "fabrikam"})
+		require.NoError(t, err)
+		require.Equal(t, "northwind", orgName)
+	})
+
+	t.Run("enters the organization when none are found", func(t *testing.T) {
+		testConsole := console.NewMockConsole()
+		testConsole.WhenPrompt(func(options input.ConsoleOptions) bool {
+			return options.Message == "Please enter an Azure DevOps Organization Name:"
+		}).Respond("northwind")
+
+		orgName, err := promptOrgName(ctx, testConsole, []string{})
+		require.NoError(t, err)
+		require.Equal(t, "northwind", orgName)
+	})
+}
+
+func strPtr(value string) *string {
+	return &value
+}
+
+type MockProfileClient struct {
+	profile        *profile.Profile
+	getProfileArgs profile.GetProfileArgs
+}
+
+func (c *MockProfileClient) GetProfile(ctx context.Context, args profile.GetProfileArgs) (*profile.Profile, error) {
+	c.getProfileArgs = args
+	return c.profile, nil
+}
+
+type MockAccountsClient struct {
+	accounts        []accounts.Account
+	getAccountsArgs accounts.GetAccountsArgs
+}
+
+func (c *MockAccountsClient) GetAccounts(ctx context.Context, args accounts.GetAccountsArgs) (*[]accounts.Account, error) {
+	c.getAccountsArgs = args
+	return &c.accounts, nil
+}
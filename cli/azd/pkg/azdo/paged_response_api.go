@@ -8,6 +8,7 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/build"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/core"
 )
 
 // Define an API for client methods that returns a page and a continuation token.
@@ -51,3 +52,28 @@ func getDefinitionsPager(
 		},
 	)
 }
+
+// getProjectsPager provides a pager to iterate all the pages from GetProjects.
+func getProjectsPager(
+	ctx context.Context,
+	client core.Client,
+) *runtime.Pager[*core.GetProjectsResponseValue] {
+	return runtime.NewPager(
+		runtime.PagingHandler[*core.GetProjectsResponseValue]{
+			More: func(current *core.GetProjectsResponseValue) bool {
+				return current.ContinuationToken != ""
+			},
+			Fetcher: func(
+				ctx context.Context,
+				current **core.GetProjectsResponseValue) (*core.GetProjectsResponseValue, error) {
+				args := core.GetProjectsArgs{}
+				if current != nil {
+					// not first page
+					args.ContinuationToken = &((*current).ContinuationToken)
+				}
+
+				return client.GetProjects(ctx, args)
+			},
+		},
+	)
+}
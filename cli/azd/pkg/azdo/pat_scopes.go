@@ -0,0 +1,100 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azdo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/microsoft/azure-devops-go-api/azuredevops"
+)
+
+// a scope of the PAT required to configure the pipeline
+type patScope struct {
+	// name of the scope on the page creating PATs
+	name string
+	// read-only API of the organization the scope grants access to, the API is probed to verify the scope
+	probePath string
+}
+
+// the scopes of the PAT used by `azd pipeline config`. The branch policy is configured with the Code scope.
+var requiredPatScopes = []patScope{
+	{
+		name:      "Code (Read & write)",
+		probePath: "_apis/git/repositories?api-version=6.0",
+	},
+	{
+		name:      "Build (Read & execute)",
+		probePath: "_apis/build/resourceusage?api-version=6.0-preview.2",
+	},
+	{
+		name:      "Service Connections (Read, query & manage)",
+		probePath: "_apis/serviceendpoint/types?api-version=6.0-preview.1",
+	},
+}
+
+// ValidatePatScopes verifies the PAT grants the scopes required to configure the pipeline in the organization, so a
+// PAT missing scopes is reported before anything is created
+func ValidatePatScopes(ctx context.Context, organization string, personalAccessToken string) (err error) {
+	endSpan := startSpan(ctx, "pat.validate")
+	defer func() { endSpan(err) }()
+
+	httpClient := httputil.GetHttpClient(ctx)
+	authorization := azuredevops.CreateBasicAuthHeaderValue("", personalAccessToken)
+
+	missingScopes := []string{}
+	for _, scope := range requiredPatScopes {
+		url := fmt.Sprintf("https://%s/%s/%s", AzDoHostName, organization, scope.probePath)
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("creating request: %w", err)
+		}
+
+		request.Header.Set("Authorization", authorization)
+		// Azure DevOps redirects to its sign-in page instead of failing when the PAT isn't valid
+		request.Header.Set("X-TFS-FedAuthRedirect", "Suppress")
+
+		response, err := httpClient.Do(request)
+		if err != nil {
+			return fmt.Errorf("verifying the scopes of the personal access token: %w", err)
+		}
+		response.Body.Close()
+
+		switch {
+		case response.StatusCode == http.StatusNonAuthoritativeInfo:
+			return fmt.Errorf(
+				"the personal access token is not valid for organization '%s', it may have expired. %s",
+				organization, createPatMessage(organization))
+		case response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden:
+			missingScopes = append(missingScopes, scope.name)
+		case response.StatusCode < 200 || response.StatusCode >= 300:
+			// Other failures don't tell whether the scope is granted, the scope is verified when it's used
+			log.Printf("probing scope '%s' of the PAT returned status %d", scope.name, response.StatusCode)
+		}
+	}
+
+	if len(missingScopes) > 0 {
+		return fmt.Errorf(
+			"the personal access token is missing the scopes: %s. %s",
+			strings.Join(missingScopes, ", "), createPatMessage(organization))
+	}
+
+	return nil
+}
+
+// instructions to create a PAT with the required scopes
+func createPatMessage(organization string) string {
+	scopeNames := make([]string, len(requiredPatScopes))
+	for index, scope := range requiredPatScopes {
+		scopeNames[index] = scope.name
+	}
+
+	return fmt.Sprintf(
+		"Create a personal access token with the scopes %s at https://%s/%s/_usersSettings/tokens and set it in %s",
+		strings.Join(scopeNames, ", "), AzDoHostName, organization, AzDoPatName)
+}
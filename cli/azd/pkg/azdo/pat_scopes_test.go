@@ -0,0 +1,65 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azdo
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ValidatePatScopes(t *testing.T) {
+	mockScopes := func(mockContext *mocks.MockContext, statusCodes map[string]int) {
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.URL.Host == AzDoHostName
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			statusCode := http.StatusOK
+			for path, code := range statusCodes {
+				if strings.HasSuffix(request.URL.Path, path) {
+					statusCode = code
+				}
+			}
+
+			return &http.Response{Request: request, Header: http.Header{}, StatusCode: statusCode, Body: http.NoBody}, nil
+		})
+	}
+
+	t.Run("all scopes granted", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockScopes(mockContext, map[string]int{
+			// Failures other than authorization failures don't tell whether the scope is granted
+			"/_apis/build/resourceusage": http.StatusNotFound,
+		})
+
+		err := ValidatePatScopes(*mockContext.Context, "fake_org", "fake_pat")
+		require.NoError(t, err)
+	})
+
+	t.Run("missing scopes", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockScopes(mockContext, map[string]int{
+			"/_apis/build/resourceusage":   http.StatusUnauthorized,
+			"/_apis/serviceendpoint/types": http.StatusForbidden,
+		})
+
+		err := ValidatePatScopes(*mockContext.Context, "fake_org", "fake_pat")
+		require.ErrorContains(
+			t, err, "missing the scopes: Build (Read & execute), Service Connections (Read, query & manage).")
+		require.ErrorContains(t, err, "https://dev.azure.com/fake_org/_usersSettings/tokens")
+	})
+
+	t.Run("invalid pat", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockScopes(mockContext, map[string]int{
+			"/_apis/git/repositories": http.StatusNonAuthoritativeInfo,
+		})
+
+		err := ValidatePatScopes(*mockContext.Context, "fake_org", "fake_pat")
+		require.ErrorContains(t, err, "the personal access token is not valid for organization 'fake_org'")
+	})
+}
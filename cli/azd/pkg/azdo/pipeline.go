@@ -6,11 +6,15 @@ package azdo
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strings"
 
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/fields"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
-	"github.com/microsoft/azure-devops-go-api/azuredevops"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/build"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/taskagent"
 )
@@ -24,13 +28,31 @@ func createBuildDefinitionVariable(value string, isSecret bool, allowOverride bo
 	}
 }
 
+// The pipeline variables holding a credential, they are secret
+var secretVariableNames = map[string]bool{
+	"ARM_CLIENT_SECRET": true,
+}
+
+// The pipeline variables that can be overridden when queuing a run of a pipeline allowing it. The other variables
+// must match the service connection and the credentials of the pipeline.
+var overridableVariableNames = map[string]bool{
+	"AZURE_ENV_NAME": true,
+	"AZURE_LOCATION": true,
+}
+
+// Creates the pipeline variable of the name, secret when it holds a credential. The environment name and location can
+// be overridden when queuing a run when allowOverride is set.
+func createPipelineVariable(name string, value string, allowOverride bool) build.BuildDefinitionVariable {
+	return createBuildDefinitionVariable(value, secretVariableNames[name], allowOverride && overridableVariableNames[name])
+}
+
 // returns the default agent queue. This is used to associate a Pipeline with a default agent pool queue
 func getAgentQueue(
 	ctx context.Context,
 	projectId string,
-	connection *azuredevops.Connection,
+	clients Clients,
 ) (*taskagent.TaskAgentQueue, error) {
-	client, err := taskagent.NewClient(ctx, connection)
+	client, err := clients.TaskAgent(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -79,19 +101,216 @@ func getPipelineDefinition(
 	return nil, nil
 }
 
-// create a new Azure DevOps pipeline
+// create a new Azure DevOps pipeline, signing in with the service connection
 func CreatePipeline(
 	ctx context.Context,
 	projectId string,
 	name string,
 	repoName string,
-	connection *azuredevops.Connection,
+	branch string,
+	clients Clients,
 	credentials AzureServicePrincipalCredentials,
+	serviceConnectionName string,
 	env *environment.Environment,
 	console input.Console,
-	provisioningProvider provisioning.Options) (*build.BuildDefinition, error) {
+	provisioningProvider provisioning.Options,
+	allowOverride bool) (_ *build.BuildDefinition, err error) {
+	endSpan := startSpan(ctx, "pipeline.create")
+	defer func() { endSpan(err) }()
+
+	variables := getDefinitionVariables(env, credentials, serviceConnectionName, provisioningProvider, allowOverride)
+	return createOrUpdatePipeline(ctx, projectId, name, AzurePipelineYamlPath, repoName, branch, clients, variables)
+}
+
+// create the Azure DevOps pipeline of the yaml at yamlPath, running for the changes of a part of the project, with the
+// same variables as the pipeline created by CreatePipeline
+func CreatePartitionPipeline(
+	ctx context.Context,
+	projectId string,
+	name string,
+	yamlPath string,
+	repoName string,
+	branch string,
+	clients Clients,
+	credentials AzureServicePrincipalCredentials,
+	env *environment.Environment,
+	provisioningProvider provisioning.Options,
+	allowOverride bool) (_ *build.BuildDefinition, err error) {
+	endSpan := startSpan(ctx, "pipeline.partition.create")
+	defer func() { endSpan(err) }()
+
+	variables := getDefinitionVariables(env, credentials, ServiceConnectionName, provisioningProvider, allowOverride)
+	return createOrUpdatePipeline(ctx, projectId, name, yamlPath, repoName, branch, clients, variables)
+}
+
+// create the Azure DevOps pipeline rotating the credential of the service principal on the schedule of its yaml. The
+// pipeline runs azd with the variables of the environment and of the organization.
+func CreateRotationPipeline(
+	ctx context.Context,
+	projectId string,
+	repoName string,
+	branch string,
+	clients Clients,
+	env *environment.Environment,
+	orgName string,
+	allowOverride bool) (_ *build.BuildDefinition, err error) {
+	endSpan := startSpan(ctx, "pipeline.rotation.create")
+	defer func() { endSpan(err) }()
+
+	variables := map[string]build.BuildDefinitionVariable{
+		"AZURE_LOCATION":        createPipelineVariable("AZURE_LOCATION", env.GetLocation(), allowOverride),
+		"AZURE_ENV_NAME":        createPipelineVariable("AZURE_ENV_NAME", env.GetEnvName(), allowOverride),
+		"AZURE_SUBSCRIPTION_ID": createPipelineVariable("AZURE_SUBSCRIPTION_ID", env.GetSubscriptionId(), allowOverride),
+		AzDoEnvironmentOrgName:  createPipelineVariable(AzDoEnvironmentOrgName, orgName, allowOverride),
+	}
+
+	return createOrUpdatePipeline(
+		ctx, projectId, AzureRotationPipelineName, AzureRotationPipelineYamlPath, repoName, branch, clients, &variables)
+}
+
+// create the Azure DevOps pipeline deleting the expired environments on a schedule. The pipeline lists the resource
+// groups of the subscription of the service connection, it has no variables.
+func CreateCleanupPipeline(
+	ctx context.Context,
+	projectId string,
+	repoName string,
+	branch string,
+	clients Clients) (_ *build.BuildDefinition, err error) {
+	endSpan := startSpan(ctx, "pipeline.cleanup.create")
+	defer func() { endSpan(err) }()
+
+	variables := map[string]build.BuildDefinitionVariable{}
+
+	return createOrUpdatePipeline(
+		ctx, projectId, AzureCleanupPipelineName, AzureCleanupPipelineYamlPath, repoName, branch, clients, &variables)
+}
+
+// create the Azure DevOps pipeline running on a self-hosted agent, signing in with the managed identity of the agent
+// instead of a service connection
+func CreateManagedIdentityPipeline(
+	ctx context.Context,
+	projectId string,
+	repoName string,
+	branch string,
+	clients Clients,
+	env *environment.Environment,
+	clientId string,
+	allowOverride bool) (_ *build.BuildDefinition, err error) {
+	endSpan := startSpan(ctx, "pipeline.managedIdentity.create")
+	defer func() { endSpan(err) }()
+
+	variables := map[string]build.BuildDefinitionVariable{
+		"AZURE_LOCATION":        createPipelineVariable("AZURE_LOCATION", env.GetLocation(), allowOverride),
+		"AZURE_ENV_NAME":        createPipelineVariable("AZURE_ENV_NAME", env.GetEnvName(), allowOverride),
+		"AZURE_SUBSCRIPTION_ID": createPipelineVariable("AZURE_SUBSCRIPTION_ID", env.GetSubscriptionId(), allowOverride),
+		"AZURE_CLIENT_ID":       createPipelineVariable("AZURE_CLIENT_ID", clientId, allowOverride),
+	}
+
+	return createOrUpdatePipeline(
+		ctx, projectId, AzurePipelineName, AzurePipelineYamlPath, repoName, branch, clients, &variables)
+}
+
+// PipelineStage is a stage of a multi-stage pipeline, deploying an environment with its own service principal
+type PipelineStage struct {
+	Name        string
+	Environment *environment.Environment
+	Credentials AzureServicePrincipalCredentials
+}
+
+// StageVariableName returns the name of the pipeline variable holding the value of a stage, i.e. DEV_AZURE_ENV_NAME
+func StageVariableName(stage string, name string) string {
+	prefix := strings.ToUpper(stageVariableInvalidCharsRegex.ReplaceAllString(stage, "_"))
+	return fmt.Sprintf("%s_%s", prefix, name)
+}
+
+var stageVariableInvalidCharsRegex = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// create a new Azure DevOps multi-stage pipeline, the variables of each stage are prefixed with the name of the stage
+func CreateStagesPipeline(
+	ctx context.Context,
+	projectId string,
+	name string,
+	repoName string,
+	branch string,
+	clients Clients,
+	stages []PipelineStage,
+	provisioningProvider provisioning.Options,
+	allowOverride bool) (_ *build.BuildDefinition, err error) {
+	endSpan := startSpan(ctx, "pipeline.create")
+	defer func() { endSpan(err) }()
+
+	variables := map[string]build.BuildDefinitionVariable{}
+	for _, stage := range stages {
+		stageVariables := getDefinitionVariables(
+			stage.Environment,
+			stage.Credentials,
+			StageServiceConnectionName(stage.Name),
+			provisioningProvider,
+			allowOverride)
+		for variableName, variable := range *stageVariables {
+			variables[StageVariableName(stage.Name, variableName)] = variable
+		}
+	}
+
+	return createOrUpdatePipeline(ctx, projectId, name, AzurePipelineYamlPath, repoName, branch, clients, &variables)
+}
+
+// create a new Azure DevOps pipeline reading the values of the environment from the variable group named after the
+// environment, linked to the pipeline by its yaml. The secrets are kept as variables of the pipeline.
+func CreateVariableGroupPipeline(
+	ctx context.Context,
+	projectId string,
+	name string,
+	repoName string,
+	branch string,
+	clients Clients,
+	credentials AzureServicePrincipalCredentials,
+	serviceConnectionName string,
+	env *environment.Environment,
+	provisioningProvider provisioning.Options) (_ *build.BuildDefinition, err error) {
+	endSpan := startSpan(ctx, "pipeline.variableGroup.create")
+	defer func() { endSpan(err) }()
+
+	// The variables of a variable group can't be overridden when queuing a run
+	variables := getDefinitionVariables(env, credentials, serviceConnectionName, provisioningProvider, false)
+	values, secrets := splitVariables(*variables)
+
+	if _, err := createOrUpdateVariableGroup(
+		ctx, clients, projectId, VariableGroupName(env.GetEnvName()), values); err != nil {
+		return nil, err
+	}
+
+	// The values moved to the variable group are removed from the pipeline configured before
+	return createOrUpdatePipelineDefinition(
+		ctx, projectId, name, AzurePipelineYamlPath, repoName, branch, clients, &secrets, sortedNames(values))
+}
+
+// creates the pipeline of the yaml with the variables, or updates the variables of the pipeline when it already exists
+func createOrUpdatePipeline(
+	ctx context.Context,
+	projectId string,
+	name string,
+	yamlPath string,
+	repoName string,
+	branch string,
+	clients Clients,
+	variables *map[string]build.BuildDefinitionVariable) (*build.BuildDefinition, error) {
+	return createOrUpdatePipelineDefinition(ctx, projectId, name, yamlPath, repoName, branch, clients, variables, nil)
+}
 
-	client, err := build.NewClient(ctx, connection)
+// creates the pipeline of the yaml with the variables, or updates the variables of the pipeline when it already
+// exists, without the removed variables
+func createOrUpdatePipelineDefinition(
+	ctx context.Context,
+	projectId string,
+	name string,
+	yamlPath string,
+	repoName string,
+	branch string,
+	clients Clients,
+	variables *map[string]build.BuildDefinitionVariable,
+	removed []string) (*build.BuildDefinition, error) {
+	client, err := clients.Build(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -102,11 +321,27 @@ func CreatePipeline(
 	if err != nil {
 		return nil, fmt.Errorf("creating pipeline: validate name: %w", err)
 	}
+	telemetry.SetAttributesInContext(ctx, fields.PipelineDefinitionCreatedKey.Bool(definition == nil))
 	if definition != nil {
 		// Pipeline is already created. It uses the same connection but
 		// we need to update the variables and secrets as they
-		// might have been updated
-		definition.Variables = getDefinitionVariables(env, credentials, provisioningProvider)
+		// might have been updated. The variables set with `azd env set --sync-pipeline` are kept.
+		if definition.Variables != nil {
+			for _, name := range removed {
+				delete(*definition.Variables, name)
+			}
+
+			for name, variable := range *definition.Variables {
+				if _, has := (*variables)[name]; !has {
+					(*variables)[name] = variable
+				}
+			}
+		}
+		definition.Variables = variables
+		// The pipeline targets the branch of this configuration, which can differ from the previous one
+		if definition.Repository != nil {
+			definition.Repository.DefaultBranch = convert.RefOf(branchRef(branch))
+		}
 		definition, err := client.UpdateDefinition(ctx, build.UpdateDefinitionArgs{
 			Definition:   definition,
 			Project:      &projectId,
@@ -118,13 +353,13 @@ func CreatePipeline(
 		return definition, nil
 	}
 
-	queue, err := getAgentQueue(ctx, projectId, connection)
+	queue, err := getAgentQueue(ctx, projectId, clients)
 	if err != nil {
 		return nil, err
 	}
 
 	createDefinitionArgs, err := createAzureDevPipelineArgs(
-		ctx, projectId, name, repoName, credentials, env, queue, provisioningProvider)
+		ctx, projectId, name, yamlPath, repoName, branch, variables, queue)
 	if err != nil {
 		return nil, err
 	}
@@ -140,18 +375,25 @@ func CreatePipeline(
 func getDefinitionVariables(
 	env *environment.Environment,
 	credentials AzureServicePrincipalCredentials,
-	provisioningProvider provisioning.Options) *map[string]build.BuildDefinitionVariable {
-	variables := map[string]build.BuildDefinitionVariable{
-		"AZURE_LOCATION":           createBuildDefinitionVariable(env.GetLocation(), false, false),
-		"AZURE_ENV_NAME":           createBuildDefinitionVariable(env.GetEnvName(), false, false),
-		"AZURE_SERVICE_CONNECTION": createBuildDefinitionVariable(ServiceConnectionName, false, false),
-		"AZURE_SUBSCRIPTION_ID":    createBuildDefinitionVariable(credentials.SubscriptionId, false, false),
+	serviceConnectionName string,
+	provisioningProvider provisioning.Options,
+	allowOverride bool) *map[string]build.BuildDefinitionVariable {
+	values := map[string]string{
+		"AZURE_LOCATION":           env.GetLocation(),
+		"AZURE_ENV_NAME":           env.GetEnvName(),
+		"AZURE_SERVICE_CONNECTION": serviceConnectionName,
+		"AZURE_SUBSCRIPTION_ID":    credentials.SubscriptionId,
 	}
 
 	if provisioningProvider.Provider == provisioning.Terraform {
-		variables["ARM_TENANT_ID"] = createBuildDefinitionVariable(credentials.TenantId, false, false)
-		variables["ARM_CLIENT_ID"] = createBuildDefinitionVariable(credentials.ClientId, true, false)
-		variables["ARM_CLIENT_SECRET"] = createBuildDefinitionVariable(credentials.ClientSecret, true, false)
+		values["ARM_TENANT_ID"] = credentials.TenantId
+		values["ARM_CLIENT_ID"] = credentials.ClientId
+		values["ARM_CLIENT_SECRET"] = credentials.ClientSecret
+	}
+
+	variables := map[string]build.BuildDefinitionVariable{}
+	for name, value := range values {
+		variables[name] = createPipelineVariable(name, value, allowOverride)
 	}
 	return &variables
 }
@@ -161,17 +403,17 @@ func createAzureDevPipelineArgs(
 	ctx context.Context,
 	projectId string,
 	name string,
+	yamlPath string,
 	repoName string,
-	credentials AzureServicePrincipalCredentials,
-	env *environment.Environment,
+	branch string,
+	variables *map[string]build.BuildDefinitionVariable,
 	queue *taskagent.TaskAgentQueue,
-	provisioningProvider provisioning.Options,
 ) (*build.CreateDefinitionArgs, error) {
 
 	repoType := "tfsgit"
 	buildDefinitionType := build.DefinitionType("build")
 	definitionQueueStatus := build.DefinitionQueueStatus("enabled")
-	defaultBranch := fmt.Sprintf("refs/heads/%s", DefaultBranch)
+	defaultBranch := branchRef(branch)
 	buildRepository := &build.BuildRepository{
 		Type:          &repoType,
 		Name:          &repoName,
@@ -180,7 +422,7 @@ func createAzureDevPipelineArgs(
 
 	process := map[string]interface{}{
 		"type":         2,
-		"yamlFilename": AzurePipelineYamlPath,
+		"yamlFilename": yamlPath,
 	}
 
 	agentPoolQueue := &build.AgentPoolQueue{
@@ -209,7 +451,7 @@ func createAzureDevPipelineArgs(
 		Repository:  buildRepository,
 		Process:     process,
 		Queue:       agentPoolQueue,
-		Variables:   getDefinitionVariables(env, credentials, provisioningProvider),
+		Variables:   variables,
 		Triggers:    &triggers,
 	}
 
@@ -220,13 +462,77 @@ func createAzureDevPipelineArgs(
 	return createDefinitionArgs, nil
 }
 
-// run a pipeline. This is used to invoke the deploy pipeline after a successful push of the code
+// SetPipelineVariable sets the value of a variable of the pipeline of the repository, a secret variable stays secret
+func SetPipelineVariable(
+	ctx context.Context,
+	clients Clients,
+	projectId string,
+	repoName string,
+	name string,
+	value string) (err error) {
+	endSpan := startSpan(ctx, "pipeline.variable.set")
+	defer func() { endSpan(err) }()
+
+	client, err := clients.Build(ctx)
+	if err != nil {
+		return err
+	}
+
+	pipelineName := fmt.Sprintf("%s (%s)", AzurePipelineName, repoName)
+	definition, err := getPipelineDefinition(ctx, client, &projectId, &pipelineName)
+	if err != nil {
+		return fmt.Errorf("finding pipeline: %w", err)
+	}
+	if definition == nil {
+		return fmt.Errorf("pipeline '%s' was not found in project %s", pipelineName, projectId)
+	}
+
+	setDefinitionVariable(definition, name, value)
+
+	_, err = client.UpdateDefinition(ctx, build.UpdateDefinitionArgs{
+		Definition:   definition,
+		Project:      &projectId,
+		DefinitionId: definition.Id,
+	})
+	if err != nil {
+		return fmt.Errorf("updating pipeline variable %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// sets the value of a variable of the definition, keeping whether the variable is secret and can be overridden
+func setDefinitionVariable(definition *build.BuildDefinition, name string, value string) {
+	if definition.Variables == nil {
+		definition.Variables = &map[string]build.BuildDefinitionVariable{}
+	}
+
+	isSecret := false
+	allowOverride := false
+	if variable, has := (*definition.Variables)[name]; has {
+		if variable.IsSecret != nil {
+			isSecret = *variable.IsSecret
+		}
+		if variable.AllowOverride != nil {
+			allowOverride = *variable.AllowOverride
+		}
+	}
+
+	(*definition.Variables)[name] = createBuildDefinitionVariable(value, isSecret, allowOverride)
+}
+
+// run a pipeline on the branch. This is used to invoke the deploy pipeline on the pushed branch after a successful
+// push of the code
 func QueueBuild(
 	ctx context.Context,
-	connection *azuredevops.Connection,
+	clients Clients,
 	projectId string,
-	buildDefinition *build.BuildDefinition) error {
-	client, err := build.NewClient(ctx, connection)
+	buildDefinition *build.BuildDefinition,
+	branch string) (err error) {
+	endSpan := startSpan(ctx, "build.queue")
+	defer func() { endSpan(err) }()
+
+	client, err := clients.Build(ctx)
 	if err != nil {
 		return err
 	}
@@ -235,7 +541,8 @@ func QueueBuild(
 	}
 
 	newBuild := &build.Build{
-		Definition: definitionReference,
+		Definition:   definitionReference,
+		SourceBranch: convert.RefOf(branchRef(branch)),
 	}
 	queueBuildArgs := build.QueueBuildArgs{
 		Project: &projectId,
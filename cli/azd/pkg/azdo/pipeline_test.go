@@ -0,0 +1,94 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azdo
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/build"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_setDefinitionVariable(t *testing.T) {
+	t.Run("adds a variable", func(t *testing.T) {
+		definition := &build.BuildDefinition{}
+
+		setDefinitionVariable(definition, "KEY", "value")
+
+		variable := (*definition.Variables)["KEY"]
+		require.Equal(t, "value", *variable.Value)
+		require.False(t, *variable.IsSecret)
+	})
+
+	t.Run("keeps a secret variable secret", func(t *testing.T) {
+		definition := &build.BuildDefinition{
+			Variables: &map[string]build.BuildDefinitionVariable{
+				"ARM_CLIENT_SECRET": createBuildDefinitionVariable("old", true, false),
+			},
+		}
+
+		setDefinitionVariable(definition, "ARM_CLIENT_SECRET", "new")
+
+		variable := (*definition.Variables)["ARM_CLIENT_SECRET"]
+		require.Equal(t, "new", *variable.Value)
+		require.True(t, *variable.IsSecret)
+	})
+
+	t.Run("keeps an overridable variable overridable", func(t *testing.T) {
+		definition := &build.BuildDefinition{
+			Variables: &map[string]build.BuildDefinitionVariable{
+				"AZURE_LOCATION": createBuildDefinitionVariable("eastus", false, true),
+			},
+		}
+
+		setDefinitionVariable(definition, "AZURE_LOCATION", "westus")
+
+		variable := (*definition.Variables)["AZURE_LOCATION"]
+		require.Equal(t, "westus", *variable.Value)
+		require.True(t, *variable.AllowOverride)
+	})
+}
+
+func Test_getDefinitionVariables(t *testing.T) {
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		environment.LocationEnvVarName: "eastus",
+	})
+	credentials := AzureServicePrincipalCredentials{
+		TenantId:       "TENANT_ID",
+		ClientId:       "CLIENT_ID",
+		ClientSecret:   "CLIENT_SECRET",
+		SubscriptionId: "SUBSCRIPTION_ID",
+	}
+	terraform := provisioning.Options{Provider: provisioning.Terraform}
+
+	t.Run("Classified", func(t *testing.T) {
+		variables := *getDefinitionVariables(env, credentials, ServiceConnectionName, terraform, false)
+
+		require.Equal(t, "CLIENT_ID", *variables["ARM_CLIENT_ID"].Value)
+		require.False(t, *variables["ARM_CLIENT_ID"].IsSecret)
+		require.True(t, *variables["ARM_CLIENT_SECRET"].IsSecret)
+		for name, variable := range variables {
+			require.False(t, *variable.AllowOverride, name)
+		}
+	})
+
+	t.Run("AllowOverride", func(t *testing.T) {
+		variables := *getDefinitionVariables(env, credentials, ServiceConnectionName, terraform, true)
+
+		overridable := []string{}
+		for name, variable := range variables {
+			if *variable.AllowOverride {
+				overridable = append(overridable, name)
+			}
+		}
+		require.ElementsMatch(t, []string{"AZURE_ENV_NAME", "AZURE_LOCATION"}, overridable)
+	})
+}
+
+func Test_StageVariableName(t *testing.T) {
+	require.Equal(t, "DEV_AZURE_ENV_NAME", StageVariableName("dev", "AZURE_ENV_NAME"))
+	require.Equal(t, "PROD_EU_AZURE_LOCATION", StageVariableName("prod-eu", "AZURE_LOCATION"))
+}
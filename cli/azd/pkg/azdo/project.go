@@ -5,45 +5,161 @@ package azdo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
-	"github.com/microsoft/azure-devops-go-api/azuredevops"
+	"github.com/google/uuid"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/core"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/operations"
 )
 
-// returns a process template (basic, agile etc) used in the new project creation flow
-func getProcessTemplateId(ctx context.Context, client core.Client) (string, error) {
+// newProjectOptions are the process and the visibility of a new project
+type newProjectOptions struct {
+	processTemplateId string
+	visibility        core.ProjectVisibility
+}
+
+// returns the process template (basic, agile etc) used in the new project creation flow. The process is the one named by
+// processName when set, otherwise the user selects it, the default process of the organization being preselected.
+func getProcessTemplateId(
+	ctx context.Context,
+	client core.Client,
+	processName string,
+	console input.Console,
+) (string, error) {
 	processArgs := core.GetProcessesArgs{}
 	processes, err := client.GetProcesses(ctx, processArgs)
 	if err != nil {
 		return "", err
 	}
-	process := (*processes)[0]
-	return process.Id.String(), nil
+
+	if len(*processes) == 0 {
+		return "", errors.New("the organization has no processes to create the project with")
+	}
+
+	names := make([]string, 0, len(*processes))
+	defaultProcess := (*processes)[0]
+	for _, process := range *processes {
+		if processName != "" && strings.EqualFold(*process.Name, processName) {
+			return process.Id.String(), nil
+		}
+
+		names = append(names, *process.Name)
+		if process.IsDefault != nil && *process.IsDefault {
+			defaultProcess = process
+		}
+	}
+
+	if processName != "" {
+		return "", fmt.Errorf(
+			"process '%s' set in %s not found, the processes of the organization are %s",
+			processName, AzDoEnvironmentProjectProcessName, strings.Join(names, ", "))
+	}
+
+	if console == nil || len(names) == 1 {
+		return defaultProcess.Id.String(), nil
+	}
+
+	idx, err := console.Select(ctx, input.ConsoleOptions{
+		Message:      "Select the process of the new Azure DevOps project",
+		Options:      names,
+		DefaultValue: *defaultProcess.Name,
+	})
+	if err != nil {
+		return "", fmt.Errorf("prompting for azdo process: %w", err)
+	}
+
+	return (*processes)[idx].Id.String(), nil
+}
+
+// returns the visibility of the new project, the one set in visibility when set, otherwise the user selects it. Projects
+// are private by default.
+func getProjectVisibility(
+	ctx context.Context,
+	visibility string,
+	console input.Console,
+) (core.ProjectVisibility, error) {
+	visibilities := []core.ProjectVisibility{core.ProjectVisibilityValues.Private, core.ProjectVisibilityValues.Public}
+
+	if visibility != "" {
+		for _, value := range visibilities {
+			if strings.EqualFold(string(value), visibility) {
+				return value, nil
+			}
+		}
+
+		return "", fmt.Errorf(
+			"invalid project visibility '%s' set in %s, supported values are private and public",
+			visibility, AzDoEnvironmentProjectVisibilityName)
+	}
+
+	if console == nil {
+		return core.ProjectVisibilityValues.Private, nil
+	}
+
+	idx, err := console.Select(ctx, input.ConsoleOptions{
+		Message:      "Select the visibility of the new Azure DevOps project",
+		Options:      []string{"Private", "Public"},
+		DefaultValue: "Private",
+	})
+	if err != nil {
+		return "", fmt.Errorf("prompting for azdo project visibility: %w", err)
+	}
+
+	return visibilities[idx], nil
+}
+
+// returns the process and the visibility of the new project, from the environment or selected by the user
+func getNewProjectOptions(
+	ctx context.Context,
+	clients Clients,
+	env *environment.Environment,
+	console input.Console,
+) (newProjectOptions, error) {
+	coreClient, err := clients.Core(ctx)
+	if err != nil {
+		return newProjectOptions{}, err
+	}
+
+	// Neither is required, the errors only mean they aren't set
+	processName, _ := ensureConfigExists(ctx, env, AzDoEnvironmentProjectProcessName, "azure devops project process")
+	visibilityName, _ := ensureConfigExists(
+		ctx, env, AzDoEnvironmentProjectVisibilityName, "azure devops project visibility")
+
+	processTemplateId, err := getProcessTemplateId(ctx, coreClient, processName, console)
+	if err != nil {
+		return newProjectOptions{}, fmt.Errorf("error fetching process template id %w", err)
+	}
+
+	visibility, err := getProjectVisibility(ctx, visibilityName, console)
+	if err != nil {
+		return newProjectOptions{}, err
+	}
+
+	return newProjectOptions{processTemplateId: processTemplateId, visibility: visibility}, nil
 }
 
 // creates a new Azure DevOps project
 func createProject(
 	ctx context.Context,
-	connection *azuredevops.Connection,
+	clients Clients,
 	name string,
 	description string,
+	options newProjectOptions,
 	console input.Console,
-) (*core.TeamProjectReference, error) {
-	coreClient, err := core.NewClient(ctx, connection)
-	if err != nil {
-		return nil, err
-	}
+) (_ *core.TeamProjectReference, err error) {
+	endSpan := startSpan(ctx, "project.create")
+	defer func() { endSpan(err) }()
 
-	processTemplateId, err := getProcessTemplateId(ctx, coreClient)
+	coreClient, err := clients.Core(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching process template id %w", err)
+		return nil, err
 	}
 
 	capabilities := map[string]map[string]string{
@@ -51,14 +167,14 @@ func createProject(
 			"sourceControlType": "git",
 		},
 		"processTemplate": {
-			"templateTypeId": processTemplateId,
+			"templateTypeId": options.processTemplateId,
 		},
 	}
 	args := core.QueueCreateProjectArgs{
 		ProjectToCreate: &core.TeamProject{
 			Description:  &description,
 			Name:         &name,
-			Visibility:   &core.ProjectVisibilityValues.Private,
+			Visibility:   &options.visibility,
 			Capabilities: &capabilities,
 		},
 	}
@@ -67,7 +183,7 @@ func createProject(
 		return nil, err
 	}
 
-	operationsClient := operations.NewClient(ctx, connection)
+	operationsClient := clients.Operations(ctx)
 
 	getOperationsArgs := operations.GetOperationArgs{
 		OperationId: res.Id,
@@ -95,7 +211,7 @@ func createProject(
 		time.Sleep(800 * time.Millisecond)
 	}
 
-	project, err := GetProjectByName(ctx, connection, name)
+	project, err := GetProjectByName(ctx, clients, name)
 	if err != nil {
 		return nil, err
 	}
@@ -107,7 +223,7 @@ func createProject(
 func GetProjectFromNew(
 	ctx context.Context,
 	repoPath string,
-	connection *azuredevops.Connection,
+	clients Clients,
 	env *environment.Environment,
 	console input.Console,
 ) (string, string, error) {
@@ -115,6 +231,11 @@ func GetProjectFromNew(
 	currentFolderName := filepath.Base(repoPath)
 	var projectDescription string = AzDoProjectDescription
 
+	options, err := getNewProjectOptions(ctx, clients, env, console)
+	if err != nil {
+		return "", "", err
+	}
+
 	for {
 		name, err := console.Prompt(ctx, input.ConsoleOptions{
 			Message:      "Enter the name for your new Azure DevOps Project OR Hit enter to use this name:",
@@ -124,7 +245,7 @@ func GetProjectFromNew(
 			return "", "", fmt.Errorf("asking for new project name: %w", err)
 		}
 		var message string = ""
-		newProject, err := createProject(ctx, connection, name, projectDescription, console)
+		newProject, err := createProject(ctx, clients, name, projectDescription, options, console)
 		if err != nil {
 			message = err.Error()
 		}
@@ -150,24 +271,47 @@ func GetProjectFromNew(
 	return *project.Name, project.Id.String(), nil
 }
 
+// queues the deletion of an azdo project, the repositories within the project are deleted with it
+func DeleteProject(
+	ctx context.Context,
+	clients Clients,
+	projectId string,
+) (err error) {
+	endSpan := startSpan(ctx, "project.delete")
+	defer func() { endSpan(err) }()
+
+	id, err := uuid.Parse(projectId)
+	if err != nil {
+		return fmt.Errorf("parsing project id '%s': %w", projectId, err)
+	}
+
+	coreClient, err := clients.Core(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = coreClient.QueueDeleteProject(ctx, core.QueueDeleteProjectArgs{
+		ProjectId: &id,
+	})
+	return err
+}
+
 // return an azdo project by name
 func GetProjectByName(
 	ctx context.Context,
-	connection *azuredevops.Connection,
+	clients Clients,
 	name string,
 ) (*core.TeamProjectReference, error) {
-	coreClient, err := core.NewClient(ctx, connection)
+	coreClient, err := clients.Core(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	args := core.GetProjectsArgs{}
-	getProjectsResponse, err := coreClient.GetProjects(ctx, args)
+	projects, err := listProjects(ctx, coreClient)
 	if err != nil {
 		return nil, err
 	}
 
-	projects := getProjectsResponse.Value
 	for _, project := range projects {
 		if *project.Name == name {
 			return &project, nil
@@ -177,24 +321,63 @@ func GetProjectByName(
 	return nil, fmt.Errorf("azure devops project %s not found", name)
 }
 
+// return an azdo project by id, without listing the projects of the organization
+func GetProjectById(
+	ctx context.Context,
+	clients Clients,
+	id string,
+) (*core.TeamProject, error) {
+	coreClient, err := clients.Core(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := coreClient.GetProject(ctx, core.GetProjectArgs{ProjectId: &id})
+	if err != nil {
+		return nil, fmt.Errorf("getting azure devops project %s: %w", id, err)
+	}
+
+	return project, nil
+}
+
+// returns all the projects of the organization, GetProjects only returns a page of the projects
+func listProjects(ctx context.Context, client core.Client) ([]core.TeamProjectReference, error) {
+	var projects []core.TeamProjectReference
+
+	projectsPager := getProjectsPager(ctx, client)
+	for projectsPager.More() {
+		page, err := projectsPager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("getting next page of projects: %w", err)
+		}
+
+		projects = append(projects, page.Value...)
+	}
+
+	return projects, nil
+}
+
 // prompt the user to select form a list of existing Azure DevOps projects
 func GetProjectFromExisting(
 	ctx context.Context,
-	connection *azuredevops.Connection,
+	clients Clients,
 	console input.Console,
 ) (string, string, error) {
-	coreClient, err := core.NewClient(ctx, connection)
+	coreClient, err := clients.Core(ctx)
 	if err != nil {
 		return "", "", err
 	}
 
-	args := core.GetProjectsArgs{}
-	getProjectsResponse, err := coreClient.GetProjects(ctx, args)
+	projects, err := listProjects(ctx, coreClient)
 	if err != nil {
 		return "", "", err
 	}
 
-	projects := getProjectsResponse.Value
+	// Sorted so that the project is easily found in the prompt of an organization with many projects
+	sort.Slice(projects, func(i, j int) bool {
+		return strings.ToLower(*projects[i].Name) < strings.ToLower(*projects[j].Name)
+	})
+
 	projectsList := make([]core.TeamProjectReference, len(projects))
 	options := make([]string, len(projects))
 	for idx, project := range projects {
@@ -0,0 +1,197 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azdo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/console"
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/operations"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_listProjects(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns the projects of all the pages", func(t *testing.T) {
+		coreClient := &MockCoreClient{pages: [][]string{{"project1", "project2"}, {"project3"}, {"project4"}}}
+
+		projects, err := listProjects(ctx, coreClient)
+		require.NoError(t, err)
+
+		names := []string{}
+		for _, project := range projects {
+			names = append(names, *project.Name)
+		}
+		require.Equal(t, []string{"project1", "project2", "project3", "project4"}, names)
+		require.Equal(t, []string{"", "1", "2"}, coreClient.continuationTokens)
+	})
+
+	t.Run("returns no projects", func(t *testing.T) {
+		coreClient := &MockCoreClient{pages: [][]string{{}}}
+
+		projects, err := listProjects(ctx, coreClient)
+		require.NoError(t, err)
+		require.Empty(t, projects)
+	})
+}
+
+func Test_createProject(t *testing.T) {
+	ctx := context.Background()
+
+	coreClient := &MockCoreClient{pages: [][]string{{"project1", "project2"}}}
+	clients := &MockClients{core: coreClient, operations: &MockOperationsClient{}}
+
+	options := newProjectOptions{
+		processTemplateId: processTemplateId.String(),
+		visibility:        core.ProjectVisibilityValues.Public,
+	}
+	project, err := createProject(ctx, clients, "project2", "description", options, nil)
+	require.NoError(t, err)
+	require.Equal(t, "project2", *project.Name)
+
+	created := coreClient.queueCreateProjectArgs.ProjectToCreate
+	require.Equal(t, "project2", *created.Name)
+	require.Equal(t, core.ProjectVisibilityValues.Public, *created.Visibility)
+	require.Equal(t, "git", (*created.Capabilities)["versioncontrol"]["sourceControlType"])
+	require.Equal(t, processTemplateId.String(), (*created.Capabilities)["processTemplate"]["templateTypeId"])
+}
+
+func Test_getProcessTemplateId(t *testing.T) {
+	ctx := context.Background()
+	coreClient := &MockCoreClient{}
+
+	t.Run("returns the configured process", func(t *testing.T) {
+		id, err := getProcessTemplateId(ctx, coreClient, "scrum", nil)
+		require.NoError(t, err)
+		require.Equal(t, scrumProcessId.String(), id)
+	})
+
+	t.Run("fails when the configured process doesn't exist", func(t *testing.T) {
+		_, err := getProcessTemplateId(ctx, coreClient, "Kanban", nil)
+		require.ErrorContains(t, err, "CMMI, Agile, Scrum")
+	})
+
+	t.Run("returns the default process without console", func(t *testing.T) {
+		id, err := getProcessTemplateId(ctx, coreClient, "", nil)
+		require.NoError(t, err)
+		require.Equal(t, processTemplateId.String(), id)
+	})
+
+	t.Run("selects a process", func(t *testing.T) {
+		testConsole := console.NewMockConsole()
+		var defaultValue any
+		testConsole.WhenSelect(func(options input.ConsoleOptions) bool {
+			defaultValue = options.DefaultValue
+			return options.Message == "Select the process of the new Azure DevOps project"
+		}).Respond(2)
+
+		id, err := getProcessTemplateId(ctx, coreClient, "", testConsole)
+		require.NoError(t, err)
+		require.Equal(t, scrumProcessId.String(), id)
+		require.Equal(t, "Agile", defaultValue)
+	})
+}
+
+func Test_getProjectVisibility(t *testing.T) {
+	ctx := context.Background()
+
+	visibility, err := getProjectVisibility(ctx, "Public", nil)
+	require.NoError(t, err)
+	require.Equal(t, core.ProjectVisibilityValues.Public, visibility)
+
+	_, err = getProjectVisibility(ctx, "internal", nil)
+	require.Error(t, err)
+
+	visibility, err = getProjectVisibility(ctx, "", nil)
+	require.NoError(t, err)
+	require.Equal(t, core.ProjectVisibilityValues.Private, visibility)
+
+	testConsole := console.NewMockConsole()
+	testConsole.WhenSelect(func(options input.ConsoleOptions) bool {
+		return options.Message == "Select the visibility of the new Azure DevOps project"
+	}).Respond(1)
+
+	visibility, err = getProjectVisibility(ctx, "", testConsole)
+	require.NoError(t, err)
+	require.Equal(t, core.ProjectVisibilityValues.Public, visibility)
+}
+
+var (
+	processTemplateId = uuid.MustParse("adcc42ab-9882-485e-a3ed-7678f01f66bc")
+	cmmiProcessId     = uuid.MustParse("27450541-8e31-4150-9947-dc59f998fc01")
+	scrumProcessId    = uuid.MustParse("6b724908-ef14-45cf-84f8-768b5384da45")
+)
+
+// MockCoreClient implements the project creation and GetProjects of the core client, returning a page of projects
+// per call
+type MockCoreClient struct {
+	core.Client
+	pages                  [][]string
+	continuationTokens     []string
+	queueCreateProjectArgs core.QueueCreateProjectArgs
+}
+
+func (c *MockCoreClient) GetProcesses(ctx context.Context, args core.GetProcessesArgs) (*[]core.Process, error) {
+	// The default process isn't the first one of the organization
+	isDefault := true
+	return &[]core.Process{
+		{Id: &cmmiProcessId, Name: strPtr("CMMI")},
+		{Id: &processTemplateId, Name: strPtr("Agile"), IsDefault: &isDefault},
+		{Id: &scrumProcessId, Name: strPtr("Scrum")},
+	}, nil
+}
+
+func (c *MockCoreClient) QueueCreateProject(
+	ctx context.Context,
+	args core.QueueCreateProjectArgs,
+) (*operations.OperationReference, error) {
+	c.queueCreateProjectArgs = args
+	operationId := uuid.New()
+	return &operations.OperationReference{Id: &operationId}, nil
+}
+
+func (c *MockCoreClient) GetProjects(
+	ctx context.Context,
+	args core.GetProjectsArgs,
+) (*core.GetProjectsResponseValue, error) {
+	page := 0
+	token := ""
+	if args.ContinuationToken != nil {
+		token = *args.ContinuationToken
+		if _, err := fmt.Sscan(token, &page); err != nil {
+			return nil, err
+		}
+	}
+	c.continuationTokens = append(c.continuationTokens, token)
+
+	response := &core.GetProjectsResponseValue{}
+	for _, name := range c.pages[page] {
+		name := name
+		response.Value = append(response.Value, core.TeamProjectReference{Name: &name})
+	}
+
+	if page+1 < len(c.pages) {
+		response.ContinuationToken = fmt.Sprint(page + 1)
+	}
+
+	return response, nil
+}
+
+// MockOperationsClient implements GetOperation of the operations client, the operations always succeed
+type MockOperationsClient struct {
+	operations.Client
+}
+
+func (c *MockOperationsClient) GetOperation(
+	ctx context.Context,
+	args operations.GetOperationArgs,
+) (*operations.Operation, error) {
+	return &operations.Operation{Id: args.OperationId, Status: &operations.OperationStatusValues.Succeeded}, nil
+}
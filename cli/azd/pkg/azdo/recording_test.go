@@ -0,0 +1,51 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azdo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/recording"
+	"github.com/stretchr/testify/require"
+)
+
+// The organization and project the recordings are replayed with, the organization and project recorded from are
+// replaced with them
+const (
+	recordedOrganization = "azd-recording"
+	recordedProject      = "azd-recording"
+)
+
+// Test_repositoryLifecycle replays the creation and the deletion of a repository in an existing project. To record it
+// again, run it with AZD_TEST_RECORD=1, AZURE_DEVOPS_ORG_NAME and AZURE_DEVOPS_EXT_PAT of an organization having a
+// project named azd-recording.
+func Test_repositoryLifecycle(t *testing.T) {
+	organization, pat := recordedOrganization, "fake-pat"
+	if os.Getenv(recording.RecordModeEnvVar) != "" {
+		organization, pat = os.Getenv(AzDoEnvironmentOrgName), os.Getenv(AzDoPatName)
+	}
+
+	mockContext := mocks.NewMockContext(context.Background())
+	recording.Start(t, mockContext, filepath.Join("testdata", "recordings", t.Name()+".json"), map[string]string{
+		recordedOrganization: organization,
+	})
+
+	ctx := *mockContext.Context
+	clients, err := GetClients(ctx, organization, pat)
+	require.NoError(t, err)
+
+	project, err := GetProjectByName(ctx, clients, recordedProject)
+	require.NoError(t, err)
+
+	repo, err := CreateRepository(ctx, project.Id.String(), "azd-recording-repo", clients)
+	require.NoError(t, err)
+	require.Equal(t, "azd-recording-repo", *repo.Name)
+	require.Equal(t, project.Id.String(), repo.Project.Id.String())
+
+	require.NoError(t, DeleteRepository(ctx, project.Id.String(), repo.Id.String(), clients))
+}
@@ -6,9 +6,12 @@ package azdo
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
-	"github.com/microsoft/azure-devops-go-api/azuredevops"
+	"github.com/google/uuid"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
 )
 
@@ -17,9 +20,12 @@ func CreateRepository(
 	ctx context.Context,
 	projectId string,
 	repoName string,
-	connection *azuredevops.Connection,
-) (*git.GitRepository, error) {
-	gitClient, err := git.NewClient(ctx, connection)
+	clients Clients,
+) (_ *git.GitRepository, err error) {
+	endSpan := startSpan(ctx, "repository.create")
+	defer func() { endSpan(err) }()
+
+	gitClient, err := clients.Git(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -39,14 +45,117 @@ func CreateRepository(
 	return repo, nil
 }
 
+// the interval between the checks of the status of a repository import
+var importStatusPollInterval = 5 * time.Second
+
+// populates a repository of the project with the branches and the history of the git repository at sourceUrl, the
+// source repository must be readable without credentials
+func ImportRepository(
+	ctx context.Context,
+	projectId string,
+	repoId string,
+	sourceUrl string,
+	clients Clients,
+) (err error) {
+	endSpan := startSpan(ctx, "repository.import")
+	defer func() { endSpan(err) }()
+
+	gitClient, err := clients.Git(ctx)
+	if err != nil {
+		return err
+	}
+
+	return importRepository(ctx, gitClient, projectId, repoId, sourceUrl)
+}
+
+func importRepository(
+	ctx context.Context,
+	gitClient git.Client,
+	projectId string,
+	repoId string,
+	sourceUrl string,
+) error {
+	importRequest, err := gitClient.CreateImportRequest(ctx, git.CreateImportRequestArgs{
+		ImportRequest: &git.GitImportRequest{
+			Parameters: &git.GitImportRequestParameters{
+				GitSource: &git.GitImportGitSource{Url: &sourceUrl},
+			},
+		},
+		Project:      &projectId,
+		RepositoryId: &repoId,
+	})
+	if err != nil {
+		return fmt.Errorf("requesting import of %s: %w", sourceUrl, err)
+	}
+
+	for {
+		status := git.GitAsyncOperationStatusValues.Queued
+		if importRequest.Status != nil {
+			status = *importRequest.Status
+		}
+
+		switch status {
+		case git.GitAsyncOperationStatusValues.Completed:
+			return nil
+		case git.GitAsyncOperationStatusValues.Failed, git.GitAsyncOperationStatusValues.Abandoned:
+			reason := string(status)
+			if importRequest.DetailedStatus != nil && importRequest.DetailedStatus.ErrorMessage != nil {
+				reason = *importRequest.DetailedStatus.ErrorMessage
+			}
+			return fmt.Errorf("importing %s: %s", sourceUrl, reason)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(importStatusPollInterval):
+		}
+
+		importRequest, err = gitClient.GetImportRequest(ctx, git.GetImportRequestArgs{
+			Project:         &projectId,
+			RepositoryId:    &repoId,
+			ImportRequestId: importRequest.ImportRequestId,
+		})
+		if err != nil {
+			return fmt.Errorf("getting status of the import of %s: %w", sourceUrl, err)
+		}
+	}
+}
+
+// deletes a repository from the project
+func DeleteRepository(
+	ctx context.Context,
+	projectId string,
+	repoId string,
+	clients Clients,
+) (err error) {
+	endSpan := startSpan(ctx, "repository.delete")
+	defer func() { endSpan(err) }()
+
+	id, err := uuid.Parse(repoId)
+	if err != nil {
+		return fmt.Errorf("parsing repository id '%s': %w", repoId, err)
+	}
+
+	gitClient, err := clients.Git(ctx)
+	if err != nil {
+		return err
+	}
+
+	return gitClient.DeleteRepository(ctx, git.DeleteRepositoryArgs{
+		RepositoryId: &id,
+		Project:      &projectId,
+	})
+}
+
 // returns a default repo from a newly created AzDo project.
 // this relies on the fact that new projects automatically get a repo named the same as the project
 func GetDefaultGitRepositoriesInProject(
 	ctx context.Context,
 	projectName string,
-	connection *azuredevops.Connection,
+	clients Clients,
 ) (*git.GitRepository, error) {
-	gitClient, err := git.NewClient(ctx, connection)
+	gitClient, err := clients.Git(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -79,10 +188,10 @@ func GetGitRepositoriesInProject(
 	ctx context.Context,
 	projectName string,
 	orgName string,
-	connection *azuredevops.Connection,
+	clients Clients,
 	console input.Console,
 ) (*git.GitRepository, error) {
-	gitClient, err := git.NewClient(ctx, connection)
+	gitClient, err := clients.Git(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -105,6 +214,10 @@ func GetGitRepositoriesInProject(
 	for idx, repo := range repos {
 		options[idx] = *repo.Name
 	}
+	sort.Slice(options, func(i, j int) bool {
+		return strings.ToLower(options[i]) < strings.ToLower(options[j])
+	})
+
 	repoIdx, err := console.Select(ctx, input.ConsoleOptions{
 		Message: "Please choose an existing Azure DevOps Repository",
 		Options: options,
@@ -128,9 +241,9 @@ func GetGitRepository(
 	ctx context.Context,
 	projectName string,
 	repoName string,
-	connection *azuredevops.Connection,
+	clients Clients,
 ) (*git.GitRepository, error) {
-	gitClient, err := git.NewClient(ctx, connection)
+	gitClient, err := clients.Git(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -140,3 +253,30 @@ func GetGitRepository(
 		Project:      &projectName,
 	})
 }
+
+// GetRepositoryDefaultBranch returns the name of the default branch of the repository, empty when the repository has
+// no branch yet
+func GetRepositoryDefaultBranch(
+	ctx context.Context,
+	projectName string,
+	repoName string,
+	clients Clients,
+) (string, error) {
+	repo, err := GetGitRepository(ctx, projectName, repoName, clients)
+	if err != nil {
+		return "", err
+	}
+
+	if repo.DefaultBranch == nil {
+		return "", nil
+	}
+
+	return strings.TrimPrefix(*repo.DefaultBranch, branchRefPrefix), nil
+}
+
+const branchRefPrefix = "refs/heads/"
+
+// branchRef returns the full git ref of the branch, i.e. refs/heads/main
+func branchRef(branch string) string {
+	return branchRefPrefix + branch
+}
@@ -0,0 +1,109 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azdo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_importRepository(t *testing.T) {
+	ctx := context.Background()
+	importStatusPollInterval = 0
+
+	t.Run("waits for the import to complete", func(t *testing.T) {
+		gitClient := MockGitClient{
+			statuses: []git.GitAsyncOperationStatus{
+				git.GitAsyncOperationStatusValues.Queued,
+				git.GitAsyncOperationStatusValues.InProgress,
+				git.GitAsyncOperationStatusValues.Completed,
+			},
+		}
+
+		err := importRepository(ctx, &gitClient, "project1", "repo1", "https://github.com/Azure-Samples/todo.git")
+		require.NoError(t, err)
+		require.Equal(t, "https://github.com/Azure-Samples/todo.git",
+			*gitClient.createImportRequestArgs.ImportRequest.Parameters.GitSource.Url)
+		require.Equal(t, "repo1", *gitClient.createImportRequestArgs.RepositoryId)
+		require.Equal(t, 2, gitClient.getImportRequestCalls)
+	})
+
+	t.Run("reports the failure of the import", func(t *testing.T) {
+		gitClient := MockGitClient{
+			statuses: []git.GitAsyncOperationStatus{
+				git.GitAsyncOperationStatusValues.InProgress,
+				git.GitAsyncOperationStatusValues.Failed,
+			},
+			errorMessage: "repository not found",
+		}
+
+		err := importRepository(ctx, &gitClient, "project1", "repo1", "https://github.com/Azure-Samples/todo.git")
+		require.EqualError(t, err, "importing https://github.com/Azure-Samples/todo.git: repository not found")
+	})
+}
+
+func Test_GetRepositoryDefaultBranch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns the name of the default branch", func(t *testing.T) {
+		gitClient := &MockGitClient{repository: &git.GitRepository{DefaultBranch: convert.RefOf("refs/heads/develop")}}
+
+		branch, err := GetRepositoryDefaultBranch(ctx, "project1", "repo1", &MockClients{git: gitClient})
+		require.NoError(t, err)
+		require.Equal(t, "develop", branch)
+	})
+
+	t.Run("returns no branch for an empty repository", func(t *testing.T) {
+		gitClient := &MockGitClient{repository: &git.GitRepository{}}
+
+		branch, err := GetRepositoryDefaultBranch(ctx, "project1", "repo1", &MockClients{git: gitClient})
+		require.NoError(t, err)
+		require.Empty(t, branch)
+	})
+}
+
+// MockGitClient implements the import requests of the git client and returns the repository, the other methods aren't
+// implemented
+type MockGitClient struct {
+	git.Client
+	repository              *git.GitRepository
+	statuses                []git.GitAsyncOperationStatus
+	errorMessage            string
+	createImportRequestArgs git.CreateImportRequestArgs
+	getImportRequestCalls   int
+}
+
+func (c *MockGitClient) CreateImportRequest(
+	ctx context.Context,
+	args git.CreateImportRequestArgs,
+) (*git.GitImportRequest, error) {
+	c.createImportRequestArgs = args
+	return c.importRequest(), nil
+}
+
+func (c *MockGitClient) GetImportRequest(
+	ctx context.Context,
+	args git.GetImportRequestArgs,
+) (*git.GitImportRequest, error) {
+	c.getImportRequestCalls++
+	return c.importRequest(), nil
+}
+
+func (c *MockGitClient) importRequest() *git.GitImportRequest {
+	id := 1
+	status := c.statuses[c.getImportRequestCalls]
+	return &git.GitImportRequest{
+		ImportRequestId: &id,
+		Status:          &status,
+		DetailedStatus:  &git.GitImportStatusDetail{ErrorMessage: &c.errorMessage},
+	}
+}
+
+func (c *MockGitClient) GetRepository(ctx context.Context, args git.GetRepositoryArgs) (*git.GitRepository, error) {
+	return c.repository, nil
+}
@@ -6,11 +6,13 @@ package azdo
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
-	"github.com/microsoft/azure-devops-go-api/azuredevops"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/build"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/serviceendpoint"
 )
@@ -20,8 +22,8 @@ func authorizeServiceConnectionToAllPipelines(
 	ctx context.Context,
 	projectId string,
 	endpoint *serviceendpoint.ServiceEndpoint,
-	connection *azuredevops.Connection) error {
-	buildClient, err := build.NewClient(ctx, connection)
+	clients Clients) error {
+	buildClient, err := clients.Build(ctx)
 	if err != nil {
 		return err
 	}
@@ -76,82 +78,289 @@ func serviceConnectionExists(ctx context.Context,
 	return nil, nil
 }
 
+// returns the names of the ready azure rm service connections of the project deploying to the subscription, sorted
+func listAzureRMServiceConnections(
+	ctx context.Context,
+	client serviceendpoint.Client,
+	projectId string,
+	subscriptionId string,
+) ([]string, error) {
+	endpoints, err := client.GetServiceEndpoints(ctx, serviceendpoint.GetServiceEndpointsArgs{
+		Project: &projectId,
+		Type:    convert.RefOf("azurerm"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing service connections: %w", err)
+	}
+
+	names := []string{}
+	for _, endpoint := range *endpoints {
+		if endpoint.Name == nil || endpoint.IsReady == nil || !*endpoint.IsReady || endpoint.Data == nil {
+			continue
+		}
+
+		if strings.EqualFold((*endpoint.Data)["subscriptionId"], subscriptionId) {
+			names = append(names, *endpoint.Name)
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// SelectServiceConnection selects an existing azure rm service connection of the project, deploying to the
+// subscription of the environment, for the pipeline to sign in with instead of a connection created by azd. The
+// connection named by AZURE_DEVOPS_SERVICE_CONNECTION_NAME is used when set, otherwise the user selects it and the
+// selection is saved in the environment.
+func SelectServiceConnection(
+	ctx context.Context,
+	clients Clients,
+	projectId string,
+	env *environment.Environment,
+	console input.Console) (_ string, err error) {
+	endSpan := startSpan(ctx, "connection.select")
+	defer func() { endSpan(err) }()
+
+	client, err := clients.ServiceEndpoint(ctx)
+	if err != nil {
+		return "", fmt.Errorf("creating new azdo client: %w", err)
+	}
+
+	names, err := listAzureRMServiceConnections(ctx, client, projectId, env.GetSubscriptionId())
+	if err != nil {
+		return "", err
+	}
+
+	// The errors only mean the connection isn't configured
+	if name, _ := ensureConfigExists(
+		ctx, env, AzDoEnvironmentServiceConnectionName, "azure devops service connection name"); name != "" {
+		for _, existing := range names {
+			if existing == name {
+				return name, nil
+			}
+		}
+
+		return "", fmt.Errorf(
+			"service connection '%s' set in %s isn't a ready Azure Resource Manager connection of the project "+
+				"for subscription %s", name, AzDoEnvironmentServiceConnectionName, env.GetSubscriptionId())
+	}
+
+	if len(names) == 0 {
+		return "", fmt.Errorf(
+			"the project has no ready Azure Resource Manager service connection for subscription %s",
+			env.GetSubscriptionId())
+	}
+
+	idx, err := console.Select(ctx, input.ConsoleOptions{
+		Message: "Select the service connection the pipeline signs in with",
+		Options: names,
+	})
+	if err != nil {
+		return "", fmt.Errorf("prompting for service connection: %w", err)
+	}
+
+	if err := saveEnvironmentConfig(AzDoEnvironmentServiceConnectionName, names[idx], env); err != nil {
+		return "", fmt.Errorf("saving service connection name to environment: %w", err)
+	}
+
+	return names[idx], nil
+}
+
 // create a new service connection that will be used in the deployment pipeline
 func CreateServiceConnection(
 	ctx context.Context,
-	connection *azuredevops.Connection,
+	clients Clients,
 	projectId string,
 	azdEnvironment environment.Environment,
 	credentials AzureServicePrincipalCredentials,
-	console input.Console) error {
+	console input.Console) (err error) {
+	endSpan := startSpan(ctx, "connection.create")
+	defer func() { endSpan(err) }()
+
+	_, err = createServiceConnection(
+		ctx, clients, projectId, ServiceConnectionName, servicePrincipalEndpoint(credentials), console)
+	return err
+}
+
+// The scheme of the service connections signing in with the tokens of Azure DevOps, trusted by a federated credential
+// of the service principal, instead of a client secret
+const workloadIdentityFederationScheme = "WorkloadIdentityFederation"
 
-	client, err := serviceendpoint.NewClient(ctx, connection)
+// FederatedServiceConnection is the issuer and subject of the tokens a service connection signs in with, the federated
+// credential of the service principal of the connection trusts them
+type FederatedServiceConnection struct {
+	Issuer  string
+	Subject string
+}
+
+// create or update the service connection used by the deployment pipeline, signing in with workload identity
+// federation. An existing connection signing in with a client secret is replaced.
+func CreateFederatedServiceConnection(
+	ctx context.Context,
+	clients Clients,
+	organization string,
+	projectId string,
+	projectName string,
+	credentials AzureServicePrincipalCredentials,
+	console input.Console) (federated *FederatedServiceConnection, err error) {
+	endSpan := startSpan(ctx, "connection.create")
+	defer func() { endSpan(err) }()
+
+	endpoint, err := createServiceConnection(
+		ctx, clients, projectId, ServiceConnectionName, federatedEndpoint(credentials), console)
 	if err != nil {
-		return fmt.Errorf("creating new azdo client: %w", err)
+		return nil, err
 	}
 
-	foundServiceConnection, err := serviceConnectionExists(ctx, &client, &projectId, &ServiceConnectionName)
+	// Azure DevOps returns the issuer and subject of the connection, they are only computed when it doesn't
+	federated = &FederatedServiceConnection{
+		Issuer:  "https://app.vstoken.visualstudio.com",
+		Subject: fmt.Sprintf("sc://%s/%s/%s", organization, projectName, ServiceConnectionName),
+	}
+	if endpoint != nil && endpoint.Authorization != nil && endpoint.Authorization.Parameters != nil {
+		parameters := *endpoint.Authorization.Parameters
+		if issuer := parameters["workloadIdentityFederationIssuer"]; issuer != "" {
+			federated.Issuer = issuer
+		}
+		if subject := parameters["workloadIdentityFederationSubject"]; subject != "" {
+			federated.Subject = subject
+		}
+	}
+
+	return federated, nil
+}
+
+// StageServiceConnectionName returns the name of the service connection of a stage of a multi-stage pipeline
+func StageServiceConnectionName(stage string) string {
+	return fmt.Sprintf("%s-%s", ServiceConnectionName, stage)
+}
+
+// create or update the service connection used by a stage of a multi-stage pipeline
+func CreateStageServiceConnection(
+	ctx context.Context,
+	clients Clients,
+	projectId string,
+	stage string,
+	credentials AzureServicePrincipalCredentials,
+	console input.Console) (err error) {
+	endSpan := startSpan(ctx, "connection.create")
+	defer func() { endSpan(err) }()
+
+	_, err = createServiceConnection(
+		ctx, clients, projectId, StageServiceConnectionName(stage), servicePrincipalEndpoint(credentials), console)
+	return err
+}
+
+// create or update the service connection with the endpoint, returns the created or updated connection
+func createServiceConnection(
+	ctx context.Context,
+	clients Clients,
+	projectId string,
+	name string,
+	serviceEndpoint *serviceendpoint.ServiceEndpoint,
+	console input.Console) (*serviceendpoint.ServiceEndpoint, error) {
+	client, err := clients.ServiceEndpoint(ctx)
 	if err != nil {
-		return fmt.Errorf("creating service connection: looking for existing connection: %w", err)
+		return nil, fmt.Errorf("creating new azdo client: %w", err)
 	}
 
-	// endpoint contains the Azure credentials
-	createServiceEndpointArgs, err := createAzureRMServiceEndPointArgs(ctx, &projectId, credentials)
+	foundServiceConnection, err := serviceConnectionExists(ctx, &client, &projectId, &name)
 	if err != nil {
-		return fmt.Errorf("creating Azure DevOps endpoint: %w", err)
+		return nil, fmt.Errorf("creating service connection: looking for existing connection: %w", err)
+	}
+
+	serviceEndpoint.Name = &name
+	createServiceEndpointArgs := serviceendpoint.CreateServiceEndpointArgs{
+		Project:  &projectId,
+		Endpoint: serviceEndpoint,
+	}
+
+	// The authentication scheme of a connection isn't updated in place, the connection is replaced
+	if foundServiceConnection != nil && !sameScheme(foundServiceConnection, serviceEndpoint) {
+		console.Message(
+			ctx,
+			output.WithWarningFormat("Service Connection %s signs in differently. Replacing it", name),
+		)
+		err := client.DeleteServiceEndpoint(ctx, serviceendpoint.DeleteServiceEndpointArgs{
+			Project:    &projectId,
+			EndpointId: foundServiceConnection.Id,
+			Deep:       convert.RefOf(false),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("deleting service connection: %w", err)
+		}
+		foundServiceConnection = nil
 	}
 
 	// if a service connection exists, skip creating a new Service connection. But update the current connection only
 	if foundServiceConnection != nil {
 		console.Message(
 			ctx,
-			output.WithWarningFormat("Service Connection %s already exists. Updating endpoint", ServiceConnectionName),
+			output.WithWarningFormat("Service Connection %s already exists. Updating endpoint", name),
 		)
 		// After updating the endpoint with credentials, we no longer need it
-		_, err := client.UpdateServiceEndpoint(ctx, serviceendpoint.UpdateServiceEndpointArgs{
+		endpoint, err := client.UpdateServiceEndpoint(ctx, serviceendpoint.UpdateServiceEndpointArgs{
 			Endpoint:   createServiceEndpointArgs.Endpoint,
 			Project:    createServiceEndpointArgs.Project,
 			EndpointId: foundServiceConnection.Id,
 		})
 		if err != nil {
-			return fmt.Errorf("updating service connection: %w", err)
+			return nil, fmt.Errorf("updating service connection: %w", err)
 		}
-		return nil
+		return endpoint, nil
 	}
 
 	// Service connection not found. Creating a new one and authorizing.
 	endpoint, err := client.CreateServiceEndpoint(ctx, createServiceEndpointArgs)
 	if err != nil {
-		return fmt.Errorf("Creating new service connection: %w", err)
+		return nil, fmt.Errorf("Creating new service connection: %w", err)
 	}
 
-	err = authorizeServiceConnectionToAllPipelines(ctx, projectId, endpoint, connection)
+	err = authorizeServiceConnectionToAllPipelines(ctx, projectId, endpoint, clients)
 	if err != nil {
-		return fmt.Errorf("authorizing service connection: %w", err)
+		return nil, fmt.Errorf("authorizing service connection: %w", err)
 	}
 
-	return nil
+	return endpoint, nil
 }
 
-// creates input parameter needed to create the azure rm service connection
-func createAzureRMServiceEndPointArgs(
-	ctx context.Context,
-	projectId *string,
-	credentials AzureServicePrincipalCredentials,
-) (serviceendpoint.CreateServiceEndpointArgs, error) {
-	endpointType := "azurerm"
-	endpointOwner := "library"
-	endpointUrl := "https://management.azure.com/"
-	endpointName := ServiceConnectionName
-	endpointIsShared := false
-	endpointScheme := "ServicePrincipal"
+// sameScheme returns true when both endpoints sign in with the same authentication scheme
+func sameScheme(existing *serviceendpoint.ServiceEndpoint, endpoint *serviceendpoint.ServiceEndpoint) bool {
+	if existing.Authorization == nil || existing.Authorization.Scheme == nil {
+		return true
+	}
+
+	return strings.EqualFold(*existing.Authorization.Scheme, *endpoint.Authorization.Scheme)
+}
 
-	endpointAuthorizationParameters := map[string]string{
+// the azure rm endpoint signing in with the client secret of the service principal
+func servicePrincipalEndpoint(credentials AzureServicePrincipalCredentials) *serviceendpoint.ServiceEndpoint {
+	return azureRMServiceEndpoint("ServicePrincipal", map[string]string{
 		"serviceprincipalid":  credentials.ClientId,
 		"serviceprincipalkey": credentials.ClientSecret,
 		"authenticationType":  "spnKey",
 		"tenantid":            credentials.TenantId,
-	}
+	}, credentials)
+}
+
+// the azure rm endpoint signing in with the tokens of Azure DevOps, without a secret
+func federatedEndpoint(credentials AzureServicePrincipalCredentials) *serviceendpoint.ServiceEndpoint {
+	return azureRMServiceEndpoint(workloadIdentityFederationScheme, map[string]string{
+		"serviceprincipalid": credentials.ClientId,
+		"tenantid":           credentials.TenantId,
+	}, credentials)
+}
+
+// creates the azure rm endpoint of a service connection, signing in with the scheme and its authorization parameters
+func azureRMServiceEndpoint(
+	scheme string,
+	authorizationParameters map[string]string,
+	credentials AzureServicePrincipalCredentials,
+) *serviceendpoint.ServiceEndpoint {
+	endpointType := "azurerm"
+	endpointOwner := "library"
+	endpointUrl := "https://management.azure.com/"
+	endpointIsShared := false
 
 	endpointData := map[string]string{
 		"environment":      CloudEnvironment,
@@ -162,22 +371,15 @@ func createAzureRMServiceEndPointArgs(
 	}
 
 	endpointAuthorization := serviceendpoint.EndpointAuthorization{
-		Scheme:     &endpointScheme,
-		Parameters: &endpointAuthorizationParameters,
+		Scheme:     &scheme,
+		Parameters: &authorizationParameters,
 	}
-	serviceEndpoint := &serviceendpoint.ServiceEndpoint{
+	return &serviceendpoint.ServiceEndpoint{
 		Type:          &endpointType,
 		Owner:         &endpointOwner,
 		Url:           &endpointUrl,
-		Name:          &endpointName,
 		IsShared:      &endpointIsShared,
 		Authorization: &endpointAuthorization,
 		Data:          &endpointData,
 	}
-
-	createServiceEndpointArgs := serviceendpoint.CreateServiceEndpointArgs{
-		Project:  projectId,
-		Endpoint: serviceEndpoint,
-	}
-	return createServiceEndpointArgs, nil
 }
@@ -0,0 +1,229 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azdo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/console"
+	"github.com/google/uuid"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/build"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/serviceendpoint"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_federatedEndpoint(t *testing.T) {
+	credentials := AzureServicePrincipalCredentials{
+		TenantId:       "TENANT_ID",
+		ClientId:       "CLIENT_ID",
+		SubscriptionId: "SUBSCRIPTION_ID",
+	}
+
+	endpoint := federatedEndpoint(credentials)
+	require.Equal(t, "WorkloadIdentityFederation", *endpoint.Authorization.Scheme)
+	require.Equal(t, map[string]string{
+		"serviceprincipalid": "CLIENT_ID",
+		"tenantid":           "TENANT_ID",
+	}, *endpoint.Authorization.Parameters)
+	require.Equal(t, "SUBSCRIPTION_ID", (*endpoint.Data)["subscriptionId"])
+
+	require.False(t, sameScheme(servicePrincipalEndpoint(credentials), endpoint))
+	require.True(t, sameScheme(federatedEndpoint(credentials), endpoint))
+}
+
+func Test_createServiceConnection(t *testing.T) {
+	ctx := context.Background()
+	credentials := AzureServicePrincipalCredentials{
+		TenantId:       "TENANT_ID",
+		ClientId:       "CLIENT_ID",
+		ClientSecret:   "CLIENT_SECRET",
+		SubscriptionId: "SUBSCRIPTION_ID",
+	}
+
+	t.Run("creates and authorizes a new connection", func(t *testing.T) {
+		endpointClient := &MockServiceEndpointClient{}
+		buildClient := &MockBuildClient{}
+		clients := &MockClients{serviceEndpoint: endpointClient, build: buildClient}
+
+		endpoint, err := createServiceConnection(
+			ctx, clients, "project1", ServiceConnectionName, servicePrincipalEndpoint(credentials), console.NewMockConsole())
+		require.NoError(t, err)
+		require.Equal(t, ServiceConnectionName, *endpoint.Name)
+		require.Equal(t, 1, endpointClient.created)
+		require.Equal(t, endpoint.Id.String(), *(*buildClient.authorizeArgs.Resources)[0].Id)
+	})
+
+	t.Run("updates an existing connection", func(t *testing.T) {
+		existing := servicePrincipalEndpoint(credentials)
+		existing.Id = convert.RefOf(uuid.New())
+		existing.Name = convert.RefOf(ServiceConnectionName)
+		existing.IsReady = convert.RefOf(true)
+		endpointClient := &MockServiceEndpointClient{existing: existing}
+		clients := &MockClients{serviceEndpoint: endpointClient}
+
+		_, err := createServiceConnection(
+			ctx, clients, "project1", ServiceConnectionName, servicePrincipalEndpoint(credentials), console.NewMockConsole())
+		require.NoError(t, err)
+		require.Equal(t, 1, endpointClient.updated)
+		require.Equal(t, 0, endpointClient.created)
+	})
+
+	t.Run("replaces a connection signing in differently", func(t *testing.T) {
+		existing := servicePrincipalEndpoint(credentials)
+		existing.Id = convert.RefOf(uuid.New())
+		existing.Name = convert.RefOf(ServiceConnectionName)
+		existing.IsReady = convert.RefOf(true)
+		endpointClient := &MockServiceEndpointClient{existing: existing}
+		clients := &MockClients{serviceEndpoint: endpointClient, build: &MockBuildClient{}}
+
+		_, err := createServiceConnection(
+			ctx, clients, "project1", ServiceConnectionName, federatedEndpoint(credentials), console.NewMockConsole())
+		require.NoError(t, err)
+		require.Equal(t, 1, endpointClient.deleted)
+		require.Equal(t, 1, endpointClient.created)
+	})
+}
+
+func Test_SelectServiceConnection(t *testing.T) {
+	ctx := context.Background()
+	endpoint := func(name string, subscriptionId string, ready bool) serviceendpoint.ServiceEndpoint {
+		return serviceendpoint.ServiceEndpoint{
+			Name:    convert.RefOf(name),
+			IsReady: convert.RefOf(ready),
+			Data:    &map[string]string{"subscriptionId": subscriptionId},
+		}
+	}
+	endpointClient := &MockServiceEndpointClient{listed: []serviceendpoint.ServiceEndpoint{
+		endpoint("prod", "SUBSCRIPTION_ID", true),
+		endpoint("other", "OTHER_SUBSCRIPTION_ID", true),
+		endpoint("failed", "SUBSCRIPTION_ID", false),
+		endpoint("dev", "subscription_id", true),
+	}}
+	clients := &MockClients{serviceEndpoint: endpointClient}
+
+	t.Run("selects a connection of the subscription", func(t *testing.T) {
+		env := environment.EphemeralWithValues("dev", map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		})
+		var options []string
+		testConsole := console.NewMockConsole()
+		testConsole.WhenSelect(func(consoleOptions input.ConsoleOptions) bool {
+			options = consoleOptions.Options
+			return consoleOptions.Message == "Select the service connection the pipeline signs in with"
+		}).Respond(1)
+
+		name, err := SelectServiceConnection(ctx, clients, "PROJECT_ID", env, testConsole)
+		require.NoError(t, err)
+		require.Equal(t, "prod", name)
+		require.Equal(t, []string{"dev", "prod"}, options)
+		require.Equal(t, "prod", env.Values[AzDoEnvironmentServiceConnectionName])
+		require.Equal(t, "azurerm", *endpointClient.getServiceEndpointsArgs.Type)
+	})
+
+	t.Run("uses the configured connection", func(t *testing.T) {
+		env := environment.EphemeralWithValues("dev", map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+			AzDoEnvironmentServiceConnectionName: "dev",
+		})
+
+		name, err := SelectServiceConnection(ctx, clients, "PROJECT_ID", env, console.NewMockConsole())
+		require.NoError(t, err)
+		require.Equal(t, "dev", name)
+	})
+
+	t.Run("fails when the configured connection isn't ready", func(t *testing.T) {
+		env := environment.EphemeralWithValues("dev", map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+			AzDoEnvironmentServiceConnectionName: "failed",
+		})
+
+		_, err := SelectServiceConnection(ctx, clients, "PROJECT_ID", env, console.NewMockConsole())
+		require.ErrorContains(t, err, "'failed'")
+	})
+
+	t.Run("fails without connection of the subscription", func(t *testing.T) {
+		env := environment.EphemeralWithValues("dev", map[string]string{
+			environment.SubscriptionIdEnvVarName: "NEW_SUBSCRIPTION_ID",
+		})
+
+		_, err := SelectServiceConnection(ctx, clients, "PROJECT_ID", env, console.NewMockConsole())
+		require.ErrorContains(t, err, "no ready Azure Resource Manager service connection")
+	})
+}
+
+// MockServiceEndpointClient implements the service endpoint client for a project with at most one existing endpoint,
+// GetServiceEndpoints lists the listed endpoints
+type MockServiceEndpointClient struct {
+	serviceendpoint.Client
+	existing                *serviceendpoint.ServiceEndpoint
+	listed                  []serviceendpoint.ServiceEndpoint
+	getServiceEndpointsArgs serviceendpoint.GetServiceEndpointsArgs
+	created                 int
+	updated                 int
+	deleted                 int
+}
+
+func (c *MockServiceEndpointClient) GetServiceEndpoints(
+	ctx context.Context,
+	args serviceendpoint.GetServiceEndpointsArgs,
+) (*[]serviceendpoint.ServiceEndpoint, error) {
+	c.getServiceEndpointsArgs = args
+	return &c.listed, nil
+}
+
+func (c *MockServiceEndpointClient) GetServiceEndpointsByNames(
+	ctx context.Context,
+	args serviceendpoint.GetServiceEndpointsByNamesArgs,
+) (*[]serviceendpoint.ServiceEndpoint, error) {
+	endpoints := []serviceendpoint.ServiceEndpoint{}
+	if c.existing != nil {
+		endpoints = append(endpoints, *c.existing)
+	}
+
+	return &endpoints, nil
+}
+
+func (c *MockServiceEndpointClient) CreateServiceEndpoint(
+	ctx context.Context,
+	args serviceendpoint.CreateServiceEndpointArgs,
+) (*serviceendpoint.ServiceEndpoint, error) {
+	c.created++
+	endpoint := *args.Endpoint
+	endpoint.Id = convert.RefOf(uuid.New())
+	return &endpoint, nil
+}
+
+func (c *MockServiceEndpointClient) UpdateServiceEndpoint(
+	ctx context.Context,
+	args serviceendpoint.UpdateServiceEndpointArgs,
+) (*serviceendpoint.ServiceEndpoint, error) {
+	c.updated++
+	return args.Endpoint, nil
+}
+
+func (c *MockServiceEndpointClient) DeleteServiceEndpoint(
+	ctx context.Context,
+	args serviceendpoint.DeleteServiceEndpointArgs,
+) error {
+	c.deleted++
+	return nil
+}
+
+// MockBuildClient implements AuthorizeProjectResources of the build client
+type MockBuildClient struct {
+	build.Client
+	authorizeArgs build.AuthorizeProjectResourcesArgs
+}
+
+func (c *MockBuildClient) AuthorizeProjectResources(
+	ctx context.Context,
+	args build.AuthorizeProjectResourcesArgs,
+) (*[]build.DefinitionResourceReference, error) {
+	c.authorizeArgs = args
+	return args.Resources, nil
+}
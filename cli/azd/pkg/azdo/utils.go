@@ -6,11 +6,15 @@ package azdo
 import (
 	"context"
 	"fmt"
+	"log"
 	"os"
 
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/events"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // helper method to verify that a configuration exists in the .env file or in system environment variables
@@ -54,16 +58,23 @@ func EnsurePatExists(ctx context.Context, env *environment.Environment, console
 	return value, nil
 }
 
-// helper method to ensure an Azure DevOps organization name exists either in .env or system environment variables
+// helper method to ensure an Azure DevOps organization name exists either in .env or system environment variables.
+// When a PAT is available, the organizations of its owner are offered for selection.
 func EnsureOrgNameExists(ctx context.Context, env *environment.Environment, console input.Console) (string, error) {
 	value, err := ensureConfigExists(ctx, env, AzDoEnvironmentOrgName, "azure devops organization name")
 	if err != nil {
-		orgName, err := console.Prompt(ctx, input.ConsoleOptions{
-			Message:      "Please enter an Azure DevOps Organization Name:",
-			DefaultValue: "",
-		})
+		orgNames := []string{}
+		if pat, err := ensureConfigExists(ctx, env, AzDoPatName, "azure devops personal access token"); err == nil {
+			orgNames, err = listOrganizations(ctx, pat)
+			if err != nil {
+				// The name of the organization can still be typed
+				log.Printf("failed listing azure devops organizations: %v", err)
+			}
+		}
+
+		orgName, err := promptOrgName(ctx, console, orgNames)
 		if err != nil {
-			return "", fmt.Errorf("asking for new project name: %w", err)
+			return "", err
 		}
 
 		err = saveEnvironmentConfig(AzDoEnvironmentOrgName, orgName, env)
@@ -86,3 +97,16 @@ func saveEnvironmentConfig(key string, value string, env *environment.Environmen
 	}
 	return nil
 }
+
+// starts a span that traces an Azure DevOps operation.
+// The returned function ends the span and must be invoked with the result of the operation.
+func startSpan(ctx context.Context, operation string) func(err error) {
+	_, span := telemetry.GetTracer().Start(ctx, events.AzdoEventPrefix+operation)
+
+	return func(err error) {
+		if err != nil {
+			span.SetStatus(codes.Error, "UnknownError")
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azdo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/build"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/taskagent"
+)
+
+// VariableGroupName returns the name of the variable group holding the values of the environment
+func VariableGroupName(envName string) string {
+	return envName
+}
+
+// splitVariables splits the variables of a pipeline between the values of the variable group, and the secrets kept as
+// variables of the pipeline
+func splitVariables(
+	variables map[string]build.BuildDefinitionVariable,
+) (map[string]string, map[string]build.BuildDefinitionVariable) {
+	values := map[string]string{}
+	secrets := map[string]build.BuildDefinitionVariable{}
+
+	for name, variable := range variables {
+		if variable.IsSecret != nil && *variable.IsSecret {
+			secrets[name] = variable
+			continue
+		}
+
+		values[name] = convert.ToValueWithDefault(variable.Value, "")
+	}
+
+	return values, secrets
+}
+
+// create or update the variable group of the project with the values, replacing all its variables, and authorize it
+// to be used in all pipelines
+func createOrUpdateVariableGroup(
+	ctx context.Context,
+	clients Clients,
+	projectId string,
+	name string,
+	values map[string]string,
+) (_ *taskagent.VariableGroup, err error) {
+	endSpan := startSpan(ctx, "variableGroup.create")
+	defer func() { endSpan(err) }()
+
+	client, err := clients.TaskAgent(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := client.GetVariableGroups(ctx, taskagent.GetVariableGroupsArgs{
+		Project:   &projectId,
+		GroupName: &name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("looking for variable group %s: %w", name, err)
+	}
+
+	variables := map[string]interface{}{}
+	for variableName, value := range values {
+		value := value
+		variables[variableName] = taskagent.VariableValue{Value: &value}
+	}
+
+	parameters := &taskagent.VariableGroupParameters{
+		Name:        &name,
+		Description: convert.RefOf(fmt.Sprintf("The values of the azd environment %s", name)),
+		Type:        convert.RefOf("Vsts"),
+		Variables:   &variables,
+	}
+
+	var group *taskagent.VariableGroup
+	if existing != nil && len(*existing) > 0 {
+		group, err = client.UpdateVariableGroup(ctx, taskagent.UpdateVariableGroupArgs{
+			Group:   parameters,
+			Project: &projectId,
+			GroupId: (*existing)[0].Id,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("updating variable group %s: %w", name, err)
+		}
+	} else {
+		group, err = client.AddVariableGroup(ctx, taskagent.AddVariableGroupArgs{
+			Group:   parameters,
+			Project: &projectId,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("creating variable group %s: %w", name, err)
+		}
+	}
+
+	if err := authorizeVariableGroupToAllPipelines(ctx, projectId, *group.Id, clients); err != nil {
+		return nil, fmt.Errorf("authorizing variable group %s: %w", name, err)
+	}
+
+	return group, nil
+}
+
+// authorize a variable group to be used in all pipelines
+func authorizeVariableGroupToAllPipelines(ctx context.Context, projectId string, groupId int, clients Clients) error {
+	buildClient, err := clients.Build(ctx)
+	if err != nil {
+		return err
+	}
+
+	resources := []build.DefinitionResourceReference{
+		{
+			Type:       convert.RefOf("variablegroup"),
+			Authorized: convert.RefOf(true),
+			Id:         convert.RefOf(strconv.Itoa(groupId)),
+		}}
+
+	_, err = buildClient.AuthorizeProjectResources(ctx, build.AuthorizeProjectResourcesArgs{
+		Project:   &projectId,
+		Resources: &resources,
+	})
+	return err
+}
+
+// sortedNames returns the names of the values, sorted
+func sortedNames(values map[string]string) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,109 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azdo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/build"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/taskagent"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_splitVariables(t *testing.T) {
+	values, secrets := splitVariables(map[string]build.BuildDefinitionVariable{
+		"AZURE_ENV_NAME":    createBuildDefinitionVariable("dev", false, false),
+		"ARM_CLIENT_ID":     createBuildDefinitionVariable("CLIENT_ID", false, false),
+		"ARM_CLIENT_SECRET": createBuildDefinitionVariable("SECRET", true, false),
+	})
+
+	require.Equal(t, map[string]string{"AZURE_ENV_NAME": "dev", "ARM_CLIENT_ID": "CLIENT_ID"}, values)
+	require.Len(t, secrets, 1)
+	require.Equal(t, "SECRET", *secrets["ARM_CLIENT_SECRET"].Value)
+}
+
+func Test_createOrUpdateVariableGroup(t *testing.T) {
+	values := map[string]string{"AZURE_ENV_NAME": "dev", "AZURE_LOCATION": "eastus2"}
+
+	t.Run("CreatesGroup", func(t *testing.T) {
+		taskAgentClient := &MockTaskAgentClient{}
+		buildClient := &MockBuildClient{}
+		clients := &MockClients{taskAgent: taskAgentClient, build: buildClient}
+
+		group, err := createOrUpdateVariableGroup(context.Background(), clients, "PROJECT_ID", "dev", values)
+		require.NoError(t, err)
+		require.Equal(t, 1, taskAgentClient.added)
+		require.Equal(t, 0, taskAgentClient.updated)
+		require.Equal(t, "dev", *group.Name)
+		location := (*group.Variables)["AZURE_LOCATION"].(taskagent.VariableValue)
+		require.Equal(t, "eastus2", *location.Value)
+
+		// The group is authorized for all the pipelines of the project
+		resources := *buildClient.authorizeArgs.Resources
+		require.Len(t, resources, 1)
+		require.Equal(t, "variablegroup", *resources[0].Type)
+		require.Equal(t, "7", *resources[0].Id)
+		require.True(t, *resources[0].Authorized)
+	})
+
+	t.Run("UpdatesExistingGroup", func(t *testing.T) {
+		taskAgentClient := &MockTaskAgentClient{
+			existing: &taskagent.VariableGroup{Id: convert.RefOf(3), Name: convert.RefOf("dev")},
+		}
+		buildClient := &MockBuildClient{}
+		clients := &MockClients{taskAgent: taskAgentClient, build: buildClient}
+
+		_, err := createOrUpdateVariableGroup(context.Background(), clients, "PROJECT_ID", "dev", values)
+		require.NoError(t, err)
+		require.Equal(t, 0, taskAgentClient.added)
+		require.Equal(t, 1, taskAgentClient.updated)
+		require.Equal(t, 3, *taskAgentClient.updateArgs.GroupId)
+		require.Equal(t, "3", *(*buildClient.authorizeArgs.Resources)[0].Id)
+	})
+}
+
+// MockTaskAgentClient implements the variable groups of the task agent client for a project with at most one existing
+// group. The added group gets the id 7.
+type MockTaskAgentClient struct {
+	taskagent.Client
+	existing   *taskagent.VariableGroup
+	updateArgs taskagent.UpdateVariableGroupArgs
+	added      int
+	updated    int
+}
+
+func (c *MockTaskAgentClient) GetVariableGroups(
+	ctx context.Context,
+	args taskagent.GetVariableGroupsArgs,
+) (*[]taskagent.VariableGroup, error) {
+	groups := []taskagent.VariableGroup{}
+	if c.existing != nil && *c.existing.Name == *args.GroupName {
+		groups = append(groups, *c.existing)
+	}
+
+	return &groups, nil
+}
+
+func (c *MockTaskAgentClient) AddVariableGroup(
+	ctx context.Context,
+	args taskagent.AddVariableGroupArgs,
+) (*taskagent.VariableGroup, error) {
+	c.added++
+	return variableGroupOf(7, args.Group), nil
+}
+
+func (c *MockTaskAgentClient) UpdateVariableGroup(
+	ctx context.Context,
+	args taskagent.UpdateVariableGroupArgs,
+) (*taskagent.VariableGroup, error) {
+	c.updated++
+	c.updateArgs = args
+	return variableGroupOf(*args.GroupId, args.Group), nil
+}
+
+func variableGroupOf(id int, parameters *taskagent.VariableGroupParameters) *taskagent.VariableGroup {
+	return &taskagent.VariableGroup{Id: &id, Name: parameters.Name, Variables: parameters.Variables}
+}
@@ -0,0 +1,150 @@
+package azsdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// The App Configuration data plane API version
+const appConfigApiVersion = "1.0"
+
+// AppConfigKeyValue is a key-value of an App Configuration store
+type AppConfigKeyValue struct {
+	Key   string  `json:"key"`
+	Label *string `json:"label"`
+	Value string  `json:"value"`
+}
+
+type appConfigKeyValuesResponse struct {
+	Items    []AppConfigKeyValue `json:"items"`
+	NextLink string              `json:"@nextLink"`
+}
+
+// AppConfigClient reads and writes the key-values of an App Configuration store
+// More info can be found at the following:
+// https://learn.microsoft.com/azure/azure-app-configuration/rest-api-key-value
+type AppConfigClient struct {
+	endpoint string
+	pipeline runtime.Pipeline
+}
+
+// Creates a new AppConfigClient instance for the store at the endpoint, i.e. https://mystore.azconfig.io
+func NewAppConfigClient(
+	endpoint string,
+	credential azcore.TokenCredential,
+	options *azcore.ClientOptions,
+) (*AppConfigClient, error) {
+	if options == nil {
+		options = &azcore.ClientOptions{}
+	}
+
+	location, err := url.Parse(endpoint)
+	if err != nil || location.Host == "" {
+		return nil, fmt.Errorf("invalid App Configuration endpoint '%s'", endpoint)
+	}
+
+	// The tokens are requested for the domain of the store, i.e. https://azconfig.io for https://mystore.azconfig.io
+	domain := location.Host
+	if index := strings.Index(domain, "."); index >= 0 {
+		domain = domain[index+1:]
+	}
+
+	pipeline := runtime.NewPipeline("app-config", "1.0.0", runtime.PipelineOptions{
+		PerRetry: []policy.Policy{
+			runtime.NewBearerTokenPolicy(credential, []string{fmt.Sprintf("https://%s/.default", domain)}, nil),
+		},
+	}, options)
+
+	return &AppConfigClient{
+		endpoint: fmt.Sprintf("https://%s", location.Host),
+		pipeline: pipeline,
+	}, nil
+}
+
+// SetKeyValue creates or replaces the value of the key with the label
+func (c *AppConfigClient) SetKeyValue(ctx context.Context, key string, label string, value string) error {
+	request, err := runtime.NewRequest(
+		ctx,
+		http.MethodPut,
+		fmt.Sprintf(
+			"%s/kv/%s?label=%s&api-version=%s",
+			c.endpoint,
+			url.PathEscape(key),
+			url.QueryEscape(label),
+			appConfigApiVersion,
+		),
+	)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	if err := runtime.MarshalAsJSON(request, AppConfigKeyValue{Key: key, Label: &label, Value: value}); err != nil {
+		return fmt.Errorf("creating request body: %w", err)
+	}
+
+	request.Raw().Header.Set("Content-Type", "application/vnd.microsoft.appconfig.kv+json")
+
+	response, err := c.pipeline.Do(request)
+	if err != nil {
+		return httputil.HandleRequestError(response, err)
+	}
+
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return runtime.NewResponseError(response)
+	}
+
+	return nil
+}
+
+// ListKeyValues returns the key-values with the label
+func (c *AppConfigClient) ListKeyValues(ctx context.Context, label string) ([]AppConfigKeyValue, error) {
+	keyValues := []AppConfigKeyValue{}
+
+	endpoint := fmt.Sprintf("%s/kv?label=%s&api-version=%s", c.endpoint, url.QueryEscape(label), appConfigApiVersion)
+	for endpoint != "" {
+		request, err := runtime.NewRequest(ctx, http.MethodGet, endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+
+		response, err := c.pipeline.Do(request)
+		if err != nil {
+			return nil, httputil.HandleRequestError(response, err)
+		}
+
+		page, err := readKeyValuesPage(response)
+		if err != nil {
+			return nil, err
+		}
+
+		keyValues = append(keyValues, page.Items...)
+
+		// The link to the next page is relative to the endpoint of the store
+		endpoint = ""
+		if page.NextLink != "" {
+			endpoint = c.endpoint + page.NextLink
+		}
+	}
+
+	return keyValues, nil
+}
+
+func readKeyValuesPage(response *http.Response) (*appConfigKeyValuesResponse, error) {
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return nil, runtime.NewResponseError(response)
+	}
+
+	return httputil.ReadRawResponse[appConfigKeyValuesResponse](response)
+}
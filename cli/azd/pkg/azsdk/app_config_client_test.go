@@ -0,0 +1,86 @@
+package azsdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppConfigSetKeyValue(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	var body AppConfigKeyValue
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && request.URL.Path == "/kv/API_URL"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, body)
+	})
+
+	client := newTestAppConfigClient(t, mockContext)
+	err := client.SetKeyValue(*mockContext.Context, "API_URL", "dev", "https://api.contoso.com")
+	require.NoError(t, err)
+
+	require.Equal(t, AppConfigKeyValue{
+		Key:   "API_URL",
+		Label: convert.RefOf("dev"),
+		Value: "https://api.contoso.com",
+	}, body)
+}
+
+func TestAppConfigListKeyValues(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && !strings.Contains(request.URL.RawQuery, "after=")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		require.Equal(t, "dev", request.URL.Query().Get("label"))
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, appConfigKeyValuesResponse{
+			Items:    []AppConfigKeyValue{{Key: "API_URL", Value: "https://api.contoso.com"}},
+			NextLink: "/kv?label=dev&api-version=1.0&after=API_URL",
+		})
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.Contains(request.URL.RawQuery, "after=")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, appConfigKeyValuesResponse{
+			Items: []AppConfigKeyValue{{Key: "WEB_URL", Value: "https://www.contoso.com"}},
+		})
+	})
+
+	client := newTestAppConfigClient(t, mockContext)
+	keyValues, err := client.ListKeyValues(*mockContext.Context, "dev")
+	require.NoError(t, err)
+
+	require.Equal(t, []AppConfigKeyValue{
+		{Key: "API_URL", Value: "https://api.contoso.com"},
+		{Key: "WEB_URL", Value: "https://www.contoso.com"},
+	}, keyValues)
+}
+
+func TestNewAppConfigClientInvalidEndpoint(t *testing.T) {
+	_, err := NewAppConfigClient("mystore", &mocks.MockCredentials{}, nil)
+	require.Error(t, err)
+}
+
+func newTestAppConfigClient(t *testing.T, mockContext *mocks.MockContext) *AppConfigClient {
+	options := NewClientOptionsBuilder().
+		WithTransport(mockContext.HttpClient).
+		BuildCoreClientOptions()
+
+	client, err := NewAppConfigClient("https://mystore.azconfig.io", &mocks.MockCredentials{}, options)
+	require.NoError(t, err)
+
+	return client
+}
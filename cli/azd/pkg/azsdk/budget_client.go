@@ -0,0 +1,156 @@
+package azsdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	armruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// The API version of the consumption budgets API
+const budgetsApiVersion = "2021-10-01"
+
+// Budget is a cost budget of a subscription, alerting its contacts when the cost reaches the thresholds of its
+// notifications
+type Budget struct {
+	Id         string           `json:"id,omitempty"`
+	Name       string           `json:"name,omitempty"`
+	ETag       string           `json:"eTag,omitempty"`
+	Properties BudgetProperties `json:"properties"`
+}
+
+type BudgetProperties struct {
+	Category      string                        `json:"category"`
+	Amount        float64                       `json:"amount"`
+	TimeGrain     string                        `json:"timeGrain"`
+	TimePeriod    BudgetTimePeriod              `json:"timePeriod"`
+	Filter        *BudgetFilter                 `json:"filter,omitempty"`
+	Notifications map[string]BudgetNotification `json:"notifications,omitempty"`
+}
+
+// BudgetTimePeriod is the period the budget applies to. The start date is the first day of a month.
+type BudgetTimePeriod struct {
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate,omitempty"`
+}
+
+// BudgetFilter restricts the costs counted by the budget, i.e. to the costs of resource groups
+type BudgetFilter struct {
+	Dimensions *BudgetComparisonExpression `json:"dimensions,omitempty"`
+}
+
+type BudgetComparisonExpression struct {
+	Name     string   `json:"name"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values"`
+}
+
+// BudgetNotification alerts contacts, by email or through action groups, when the cost reaches a percentage of the
+// amount of the budget
+type BudgetNotification struct {
+	Enabled       bool     `json:"enabled"`
+	Operator      string   `json:"operator"`
+	Threshold     float64  `json:"threshold"`
+	ThresholdType string   `json:"thresholdType,omitempty"`
+	ContactEmails []string `json:"contactEmails,omitempty"`
+	ContactGroups []string `json:"contactGroups,omitempty"`
+}
+
+// BudgetClient gets and sets the budgets of a subscription
+// More info can be found at the following:
+// https://learn.microsoft.com/rest/api/consumption/budgets
+type BudgetClient struct {
+	subscriptionId string
+	endpoint       string
+	pipeline       runtime.Pipeline
+}
+
+// Creates a new BudgetClient instance
+func NewBudgetClient(
+	subscriptionId string,
+	credential azcore.TokenCredential,
+	options *arm.ClientOptions,
+) (*BudgetClient, error) {
+	if options == nil {
+		options = &arm.ClientOptions{}
+	}
+
+	pipeline, err := armruntime.NewPipeline("budget", "1.0.0", credential, runtime.PipelineOptions{}, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating HTTP pipeline: %w", err)
+	}
+
+	endpoint := cloud.AzurePublic.Services[cloud.ResourceManager].Endpoint
+	if configuration, has := options.Cloud.Services[cloud.ResourceManager]; has {
+		endpoint = configuration.Endpoint
+	}
+
+	return &BudgetClient{
+		subscriptionId: subscriptionId,
+		endpoint:       strings.TrimSuffix(endpoint, "/"),
+		pipeline:       pipeline,
+	}, nil
+}
+
+// GetBudget returns the budget of the subscription with the name, or nil when the subscription has no such budget
+func (c *BudgetClient) GetBudget(ctx context.Context, name string) (*Budget, error) {
+	request, err := runtime.NewRequest(ctx, http.MethodGet, c.budgetEndpoint(name))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	response, err := c.pipeline.Do(request)
+	if err != nil {
+		return nil, httputil.HandleRequestError(response, err)
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		response.Body.Close()
+		return nil, nil
+	}
+
+	return readArmResponse[Budget](response)
+}
+
+// CreateOrUpdateBudget creates the budget of the subscription with the name, or replaces it when it exists
+func (c *BudgetClient) CreateOrUpdateBudget(ctx context.Context, name string, budget Budget) (*Budget, error) {
+	request, err := runtime.NewRequest(ctx, http.MethodPut, c.budgetEndpoint(name))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	if err := runtime.MarshalAsJSON(request, budget); err != nil {
+		return nil, fmt.Errorf("creating request body: %w", err)
+	}
+
+	response, err := c.pipeline.Do(request)
+	if err != nil {
+		return nil, httputil.HandleRequestError(response, err)
+	}
+
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusOK, http.StatusCreated) {
+		return nil, runtime.NewResponseError(response)
+	}
+
+	return httputil.ReadRawResponse[Budget](response)
+}
+
+func (c *BudgetClient) budgetEndpoint(name string) string {
+	return fmt.Sprintf(
+		"%s/subscriptions/%s/providers/Microsoft.Consumption/budgets/%s?api-version=%s",
+		c.endpoint,
+		url.PathEscape(c.subscriptionId),
+		url.PathEscape(name),
+		budgetsApiVersion,
+	)
+}
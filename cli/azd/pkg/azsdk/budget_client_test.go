@@ -0,0 +1,69 @@
+package azsdk
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBudgetNotFound(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet &&
+			strings.HasSuffix(request.URL.Path, "/providers/Microsoft.Consumption/budgets/azd-dev")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+	})
+
+	client := newTestBudgetClient(t, mockContext)
+	budget, err := client.GetBudget(*mockContext.Context, "azd-dev")
+	require.NoError(t, err)
+	require.Nil(t, budget)
+}
+
+func TestCreateOrUpdateBudget(t *testing.T) {
+	var sent Budget
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut &&
+			strings.HasSuffix(request.URL.Path, "/providers/Microsoft.Consumption/budgets/azd-dev")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(request.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(body, &sent); err != nil {
+			return nil, err
+		}
+
+		response := sent
+		response.Name = "azd-dev"
+		return mocks.CreateHttpResponseWithBody(request, http.StatusCreated, response)
+	})
+
+	client := newTestBudgetClient(t, mockContext)
+	budget, err := client.CreateOrUpdateBudget(*mockContext.Context, "azd-dev", Budget{
+		Properties: BudgetProperties{Category: "Cost", Amount: 100, TimeGrain: "Monthly"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "azd-dev", budget.Name)
+	require.Equal(t, 100.0, sent.Properties.Amount)
+}
+
+func newTestBudgetClient(t *testing.T, mockContext *mocks.MockContext) *BudgetClient {
+	options := NewClientOptionsBuilder().
+		WithTransport(mockContext.HttpClient).
+		BuildArmClientOptions()
+
+	client, err := NewBudgetClient("SUBSCRIPTION_ID", &mocks.MockCredentials{}, options)
+	require.NoError(t, err)
+
+	return client
+}
@@ -0,0 +1,104 @@
+package azsdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	armruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// The API versions of the purge APIs of the CDN endpoints and Front Door Standard/Premium endpoints, and of the
+// Front Door (classic) profiles
+const (
+	cdnApiVersion       = "2023-05-01"
+	frontDoorApiVersion = "2019-05-01"
+)
+
+// The interval the status of a purge is polled at
+const purgeStatusInterval = 5 * time.Second
+
+// CachePurgeClient purges the content cached by Azure Front Door and CDN endpoints
+// More info can be found at the following:
+// https://learn.microsoft.com/rest/api/cdn/endpoints/purge-content
+// https://learn.microsoft.com/rest/api/frontdoorservice/afd-endpoints/purge-content
+// https://learn.microsoft.com/rest/api/frontdoorservice/frontdoor/endpoints/purge-content
+type CachePurgeClient struct {
+	endpoint string
+	pipeline runtime.Pipeline
+}
+
+type purgeParameters struct {
+	ContentPaths []string `json:"contentPaths"`
+}
+
+// Creates a new CachePurgeClient instance
+func NewCachePurgeClient(credential azcore.TokenCredential, options *arm.ClientOptions) (*CachePurgeClient, error) {
+	if options == nil {
+		options = &arm.ClientOptions{}
+	}
+
+	pipeline, err := armruntime.NewPipeline("cache-purge", "1.0.0", credential, runtime.PipelineOptions{}, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating HTTP pipeline: %w", err)
+	}
+
+	endpoint := cloud.AzurePublic.Services[cloud.ResourceManager].Endpoint
+	if configuration, has := options.Cloud.Services[cloud.ResourceManager]; has {
+		endpoint = configuration.Endpoint
+	}
+
+	return &CachePurgeClient{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		pipeline: pipeline,
+	}, nil
+}
+
+// PurgeContent purges the paths from the cache of the endpoint, by its resource id, and waits for the purge to complete
+func (c *CachePurgeClient) PurgeContent(ctx context.Context, endpointId string, contentPaths []string) error {
+	apiVersion := cdnApiVersion
+	if strings.Contains(strings.ToLower(endpointId), "/providers/microsoft.network/frontdoors/") {
+		apiVersion = frontDoorApiVersion
+	}
+
+	request, err := runtime.NewRequest(
+		ctx, http.MethodPost, fmt.Sprintf("%s%s/purge?api-version=%s", c.endpoint, endpointId, apiVersion))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	if err := runtime.MarshalAsJSON(request, purgeParameters{ContentPaths: contentPaths}); err != nil {
+		return fmt.Errorf("creating request body: %w", err)
+	}
+
+	response, err := c.pipeline.Do(request)
+	if err != nil {
+		return httputil.HandleRequestError(response, err)
+	}
+
+	if runtime.HasStatusCode(response, http.StatusOK) {
+		response.Body.Close()
+		return nil
+	}
+
+	if !runtime.HasStatusCode(response, http.StatusAccepted) {
+		defer response.Body.Close()
+		return runtime.NewResponseError(response)
+	}
+
+	// The purge is a long running operation, completed once the content is purged from every edge node
+	poller, err := runtime.NewPoller[struct{}](response, c.pipeline, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: purgeStatusInterval})
+	return err
+}
@@ -0,0 +1,90 @@
+package azsdk
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeContent(t *testing.T) {
+	tests := []struct {
+		name       string
+		endpointId string
+		apiVersion string
+	}{
+		{
+			name: "FrontDoorEndpoint",
+			endpointId: "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev/providers/Microsoft.Cdn/profiles/afd-dev" +
+				"/afdEndpoints/web-dev",
+			apiVersion: cdnApiVersion,
+		},
+		{
+			name:       "FrontDoorClassic",
+			endpointId: "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev/providers/Microsoft.Network/frontDoors/fd-dev",
+			apiVersion: frontDoorApiVersion,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var sent purgeParameters
+			var apiVersion string
+			mockContext := mocks.NewMockContext(context.Background())
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return request.Method == http.MethodPost && request.URL.Path == test.endpointId+"/purge"
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				apiVersion = request.URL.Query().Get("api-version")
+				body, err := io.ReadAll(request.Body)
+				if err != nil {
+					return nil, err
+				}
+
+				if err := json.Unmarshal(body, &sent); err != nil {
+					return nil, err
+				}
+
+				return mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+			})
+
+			client := newTestCachePurgeClient(t, mockContext)
+			err := client.PurgeContent(*mockContext.Context, test.endpointId, []string{"/*"})
+			require.NoError(t, err)
+			require.Equal(t, test.apiVersion, apiVersion)
+			require.Equal(t, []string{"/*"}, sent.ContentPaths)
+		})
+	}
+}
+
+func TestPurgeContentFailed(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/purge")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+	})
+
+	client := newTestCachePurgeClient(t, mockContext)
+	err := client.PurgeContent(
+		*mockContext.Context,
+		"/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev/providers/Microsoft.Cdn/profiles/cdn-dev/endpoints/web",
+		[]string{"/*"},
+	)
+	require.Error(t, err)
+}
+
+func newTestCachePurgeClient(t *testing.T, mockContext *mocks.MockContext) *CachePurgeClient {
+	options := NewClientOptionsBuilder().
+		WithTransport(mockContext.HttpClient).
+		BuildArmClientOptions()
+
+	client, err := NewCachePurgeClient(&mocks.MockCredentials{}, options)
+	require.NoError(t, err)
+
+	return client
+}
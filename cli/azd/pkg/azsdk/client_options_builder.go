@@ -4,12 +4,14 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 )
 
 type ClientOptionsBuilder struct {
 	transport        policy.Transporter
 	perCallPolicies  []policy.Policy
 	perRetryPolicies []policy.Policy
+	retry            policy.RetryOptions
 }
 
 func NewClientOptionsBuilder() *ClientOptionsBuilder {
@@ -34,6 +36,29 @@ func (b *ClientOptionsBuilder) WithPerRetryPolicy(policy policy.Policy) *ClientO
 	return b
 }
 
+// Sets the retry options of the HTTP pipeline, the SDK defaults are used when not set
+func (b *ClientOptionsBuilder) WithRetryOptions(retry policy.RetryOptions) *ClientOptionsBuilder {
+	b.retry = retry
+	return b
+}
+
+// Sets the retry options of the HTTP pipeline from the policy of an outbound client configured by the user
+func (b *ClientOptionsBuilder) WithClientPolicy(clientPolicy httputil.ClientPolicy) *ClientOptionsBuilder {
+	retry := policy.RetryOptions{
+		TryTimeout: clientPolicy.Timeout,
+	}
+
+	if clientPolicy.MaxRetries != nil {
+		// The SDK uses its default for 0, and -1 disables retries
+		retry.MaxRetries = int32(*clientPolicy.MaxRetries)
+		if retry.MaxRetries == 0 {
+			retry.MaxRetries = -1
+		}
+	}
+
+	return b.WithRetryOptions(retry)
+}
+
 // Builds the az core client options for data plane operations
 // These options include the underlying transport to be used.
 func (b *ClientOptionsBuilder) BuildCoreClientOptions() *azcore.ClientOptions {
@@ -44,6 +69,7 @@ func (b *ClientOptionsBuilder) BuildCoreClientOptions() *azcore.ClientOptions {
 		PerCallPolicies: b.perCallPolicies,
 		// Per retry policies to inject into HTTP pipeline
 		PerRetryPolicies: b.perRetryPolicies,
+		Retry:            b.retry,
 	}
 }
 
@@ -58,6 +84,7 @@ func (b *ClientOptionsBuilder) BuildArmClientOptions() *arm.ClientOptions {
 			PerCallPolicies: b.perCallPolicies,
 			// Per retry policies to inject into HTTP pipeline
 			PerRetryPolicies: b.perRetryPolicies,
+			Retry:            b.retry,
 		},
 	}
 }
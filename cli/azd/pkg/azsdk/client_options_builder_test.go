@@ -4,8 +4,10 @@ import (
 	"context"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/stretchr/testify/require"
 )
@@ -74,6 +76,34 @@ func TestCreateCoreOptions(t *testing.T) {
 	})
 }
 
+func TestWithClientPolicy(t *testing.T) {
+	five := 5
+	zero := 0
+
+	tests := []struct {
+		name         string
+		clientPolicy httputil.ClientPolicy
+		expected     policy.RetryOptions
+	}{
+		{"NotConfigured", httputil.ClientPolicy{}, policy.RetryOptions{}},
+		{
+			"Configured",
+			httputil.ClientPolicy{Timeout: 2 * time.Minute, MaxRetries: &five},
+			policy.RetryOptions{TryTimeout: 2 * time.Minute, MaxRetries: 5},
+		},
+		{"NoRetries", httputil.ClientPolicy{MaxRetries: &zero}, policy.RetryOptions{MaxRetries: -1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			builder := NewClientOptionsBuilder().WithClientPolicy(test.clientPolicy)
+
+			require.Equal(t, test.expected, builder.BuildArmClientOptions().Retry)
+			require.Equal(t, test.expected, builder.BuildCoreClientOptions().Retry)
+		})
+	}
+}
+
 type testPerCallPolicy struct {
 }
 
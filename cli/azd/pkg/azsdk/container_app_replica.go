@@ -0,0 +1,142 @@
+package azsdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// The container apps API version exposing the event stream endpoint and auth token of an application
+const containerAppsApiVersion = "2022-10-01"
+
+type containerAppResponse struct {
+	Properties struct {
+		EventStreamEndpoint string `json:"eventStreamEndpoint"`
+		LatestRevisionName  string `json:"latestRevisionName"`
+		Template            struct {
+			Containers []struct {
+				Name string `json:"name"`
+			} `json:"containers"`
+		} `json:"template"`
+	} `json:"properties"`
+}
+
+type containerAppAuthTokenResponse struct {
+	Properties struct {
+		Token string `json:"token"`
+	} `json:"properties"`
+}
+
+type containerAppReplicasResponse struct {
+	Value []struct {
+		Name string `json:"name"`
+	} `json:"value"`
+}
+
+// containerAppReplica is a container of a running replica of a Container Apps application
+type containerAppReplica struct {
+	// The endpoint of the container in the container apps proxy, the base of its log stream and exec endpoints
+	endpoint string
+	// The token authenticating the requests to the container apps proxy
+	token string
+}
+
+// resolveContainerAppReplica returns the first container of the first replica of the latest revision of the application
+func resolveContainerAppReplica(
+	ctx context.Context,
+	pipeline runtime.Pipeline,
+	armEndpoint string,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+) (*containerAppReplica, error) {
+	appPath := fmt.Sprintf(
+		"/subscriptions/%s/resourceGroups/%s/providers/Microsoft.App/containerApps/%s",
+		url.PathEscape(subscriptionId),
+		url.PathEscape(resourceGroup),
+		url.PathEscape(appName),
+	)
+
+	containerApp, err := armRequest[containerAppResponse](ctx, pipeline, armEndpoint, http.MethodGet, appPath)
+	if err != nil {
+		return nil, fmt.Errorf("getting container app: %w", err)
+	}
+
+	properties := containerApp.Properties
+	if properties.EventStreamEndpoint == "" || properties.LatestRevisionName == "" ||
+		len(properties.Template.Containers) == 0 {
+		return nil, fmt.Errorf("container app '%s' has no running revision", appName)
+	}
+
+	replicas, err := armRequest[containerAppReplicasResponse](
+		ctx,
+		pipeline,
+		armEndpoint,
+		http.MethodGet,
+		fmt.Sprintf("%s/revisions/%s/replicas", appPath, url.PathEscape(properties.LatestRevisionName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("getting container app replicas: %w", err)
+	}
+
+	if len(replicas.Value) == 0 {
+		return nil, fmt.Errorf("revision '%s' of container app '%s' has no replicas", properties.LatestRevisionName, appName)
+	}
+
+	authToken, err := armRequest[containerAppAuthTokenResponse](
+		ctx, pipeline, armEndpoint, http.MethodPost, appPath+"/getAuthToken")
+	if err != nil {
+		return nil, fmt.Errorf("getting container app auth token: %w", err)
+	}
+
+	// The event stream endpoint of the application is the base of the endpoints of its containers, i.e.
+	// https://<region>.azurecontainerapps.dev/subscriptions/<id>/resourceGroups/<group>/containerApps/<app>/eventstream
+	baseEndpoint := strings.TrimSuffix(properties.EventStreamEndpoint, "/eventstream")
+
+	return &containerAppReplica{
+		endpoint: fmt.Sprintf(
+			"%s/revisions/%s/replicas/%s/containers/%s",
+			baseEndpoint,
+			url.PathEscape(properties.LatestRevisionName),
+			url.PathEscape(replicas.Value[0].Name),
+			url.PathEscape(properties.Template.Containers[0].Name),
+		),
+		token: authToken.Properties.Token,
+	}, nil
+}
+
+// armRequest sends a request to the Azure Resource Manager API at the specified path and reads the response
+func armRequest[T any](
+	ctx context.Context,
+	pipeline runtime.Pipeline,
+	armEndpoint string,
+	method string,
+	path string,
+) (*T, error) {
+	request, err := runtime.NewRequest(
+		ctx,
+		method,
+		fmt.Sprintf("%s%s?api-version=%s", armEndpoint, path, containerAppsApiVersion),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := pipeline.Do(request)
+	if err != nil {
+		return nil, httputil.HandleRequestError(response, err)
+	}
+
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return nil, runtime.NewResponseError(response)
+	}
+
+	return httputil.ReadRawResponse[T](response)
+}
@@ -0,0 +1,108 @@
+package azsdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	armruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// The API version of the Cosmos DB SQL role assignments API
+const cosmosRoleAssignmentsApiVersion = "2023-04-15"
+
+// The id of the built-in Cosmos DB Built-in Data Contributor role of the SQL API, relative to the account
+const CosmosDataContributorRoleId = "00000000-0000-0000-0000-000000000002"
+
+// CosmosSqlRoleAssignment grants a principal the data plane access of a SQL role definition of a Cosmos DB account. The
+// data plane roles of Cosmos DB aren't Azure RBAC roles, they're assigned on the account.
+type CosmosSqlRoleAssignment struct {
+	Id         string                            `json:"id,omitempty"`
+	Name       string                            `json:"name,omitempty"`
+	Properties CosmosSqlRoleAssignmentProperties `json:"properties"`
+}
+
+type CosmosSqlRoleAssignmentProperties struct {
+	RoleDefinitionId string `json:"roleDefinitionId"`
+	Scope            string `json:"scope"`
+	PrincipalId      string `json:"principalId"`
+}
+
+// CosmosRoleAssignmentClient assigns the SQL roles of Cosmos DB accounts
+// More info can be found at the following:
+// https://learn.microsoft.com/azure/cosmos-db/how-to-setup-rbac
+type CosmosRoleAssignmentClient struct {
+	endpoint string
+	pipeline runtime.Pipeline
+}
+
+// Creates a new CosmosRoleAssignmentClient instance
+func NewCosmosRoleAssignmentClient(
+	credential azcore.TokenCredential,
+	options *arm.ClientOptions,
+) (*CosmosRoleAssignmentClient, error) {
+	if options == nil {
+		options = &arm.ClientOptions{}
+	}
+
+	pipeline, err := armruntime.NewPipeline(
+		"cosmos-role-assignment", "1.0.0", credential, runtime.PipelineOptions{}, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating HTTP pipeline: %w", err)
+	}
+
+	endpoint := cloud.AzurePublic.Services[cloud.ResourceManager].Endpoint
+	if configuration, has := options.Cloud.Services[cloud.ResourceManager]; has {
+		endpoint = configuration.Endpoint
+	}
+
+	return &CosmosRoleAssignmentClient{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		pipeline: pipeline,
+	}, nil
+}
+
+// CreateOrUpdateSqlRoleAssignment assigns the SQL role of the account with the id of the assignment, or replaces the
+// assignment when it exists. The assignment completes asynchronously, it isn't awaited.
+func (c *CosmosRoleAssignmentClient) CreateOrUpdateSqlRoleAssignment(
+	ctx context.Context,
+	accountId string,
+	assignmentId string,
+	assignment CosmosSqlRoleAssignment,
+) error {
+	endpoint := fmt.Sprintf(
+		"%s%s/sqlRoleAssignments/%s?api-version=%s",
+		c.endpoint,
+		accountId,
+		assignmentId,
+		cosmosRoleAssignmentsApiVersion,
+	)
+
+	request, err := runtime.NewRequest(ctx, http.MethodPut, endpoint)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	if err := runtime.MarshalAsJSON(request, assignment); err != nil {
+		return fmt.Errorf("creating request body: %w", err)
+	}
+
+	response, err := c.pipeline.Do(request)
+	if err != nil {
+		return httputil.HandleRequestError(response, err)
+	}
+
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusOK, http.StatusCreated, http.StatusAccepted) {
+		return runtime.NewResponseError(response)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package azsdk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	armruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// LogStreamClient streams the logs of App Service, Azure Functions and Container Apps applications
+// More info can be found at the following:
+// https://github.com/projectkudu/kudu/wiki/Diagnostic-Log-Stream
+// https://learn.microsoft.com/azure/container-apps/log-streaming
+type LogStreamClient struct {
+	subscriptionId string
+	endpoint       string
+	// Authenticates the requests with the Azure credential
+	pipeline runtime.Pipeline
+	// Doesn't authenticate the requests, for endpoints with their own authentication
+	anonymousPipeline runtime.Pipeline
+}
+
+// Creates a new LogStreamClient instance
+func NewLogStreamClient(
+	subscriptionId string,
+	credential azcore.TokenCredential,
+	options *arm.ClientOptions,
+) (*LogStreamClient, error) {
+	if options == nil {
+		options = &arm.ClientOptions{}
+	}
+
+	// We do not have a Resource provider to register
+	options.DisableRPRegistration = true
+
+	pipeline, err := armruntime.NewPipeline("log-stream", "1.0.0", credential, runtime.PipelineOptions{}, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating HTTP pipeline: %w", err)
+	}
+
+	endpoint := cloud.AzurePublic.Services[cloud.ResourceManager].Endpoint
+	if configuration, has := options.Cloud.Services[cloud.ResourceManager]; has {
+		endpoint = configuration.Endpoint
+	}
+
+	return &LogStreamClient{
+		subscriptionId:    subscriptionId,
+		endpoint:          strings.TrimSuffix(endpoint, "/"),
+		pipeline:          pipeline,
+		anonymousPipeline: runtime.NewPipeline("log-stream", "1.0.0", runtime.PipelineOptions{}, &options.ClientOptions),
+	}, nil
+}
+
+// StreamAppServiceLogs streams the logs of the App Service or Azure Functions application until the context is
+// cancelled. The caller is responsible for closing the returned stream.
+func (c *LogStreamClient) StreamAppServiceLogs(ctx context.Context, appName string) (io.ReadCloser, error) {
+	endpoint := fmt.Sprintf("https://%s.scm.azurewebsites.net/api/logstream", appName)
+	return c.stream(ctx, c.pipeline, endpoint)
+}
+
+// StreamContainerAppLogs streams the logs of the first container of the latest revision of the Container Apps
+// application, starting with the specified number of previous lines, until the context is cancelled.
+// The caller is responsible for closing the returned stream.
+func (c *LogStreamClient) StreamContainerAppLogs(
+	ctx context.Context,
+	resourceGroup string,
+	appName string,
+	tailLines int,
+) (io.ReadCloser, error) {
+	replica, err := resolveContainerAppReplica(ctx, c.pipeline, c.endpoint, c.subscriptionId, resourceGroup, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/logstream?follow=true&output=text&tailLines=%s", replica.endpoint, strconv.Itoa(tailLines))
+
+	return c.stream(ctx, c.anonymousPipeline, endpoint, func(request *policy.Request) {
+		request.Raw().Header.Set("Authorization", "Bearer "+replica.token)
+	})
+}
+
+func (c *LogStreamClient) stream(
+	ctx context.Context,
+	pipeline runtime.Pipeline,
+	endpoint string,
+	configure ...func(request *policy.Request),
+) (io.ReadCloser, error) {
+	request, err := runtime.NewRequest(ctx, http.MethodGet, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("creating log stream request: %w", err)
+	}
+
+	// The logs are read as they are streamed, not once the response completes
+	runtime.SkipBodyDownload(request)
+	for _, configure := range configure {
+		configure(request)
+	}
+
+	response, err := pipeline.Do(request)
+	if err != nil {
+		return nil, httputil.HandleRequestError(response, err)
+	}
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		defer response.Body.Close()
+		return nil, runtime.NewResponseError(response)
+	}
+
+	return response.Body, nil
+}
@@ -0,0 +1,248 @@
+package azsdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	armruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// The API versions of the management locks and permissions APIs
+const (
+	managementLocksApiVersion = "2020-05-01"
+	permissionsApiVersion     = "2015-07-01"
+)
+
+// The segment separating the scope of a lock from its name in the id of the lock
+const managementLockIdSegment = "/providers/Microsoft.Authorization/locks/"
+
+// The levels of a management lock
+const (
+	ManagementLockLevelCanNotDelete = "CanNotDelete"
+	ManagementLockLevelReadOnly     = "ReadOnly"
+)
+
+// ManagementLock is a lock preventing the deletion or the update of the resources of its scope
+type ManagementLock struct {
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		Level  string `json:"level"`
+		Notes  string `json:"notes"`
+		Owners []struct {
+			ApplicationId string `json:"applicationId"`
+		} `json:"owners"`
+	} `json:"properties"`
+	SystemData struct {
+		CreatedBy string `json:"createdBy"`
+	} `json:"systemData"`
+}
+
+// Scope returns the id of the subscription, resource group or resource the lock applies to
+func (l *ManagementLock) Scope() string {
+	index := strings.Index(strings.ToLower(l.Id), strings.ToLower(managementLockIdSegment))
+	if index < 0 {
+		return l.Id
+	}
+
+	return l.Id[:index]
+}
+
+// OwnerNames returns who created the lock and the applications owning the lock
+func (l *ManagementLock) OwnerNames() []string {
+	owners := []string{}
+	if l.SystemData.CreatedBy != "" {
+		owners = append(owners, l.SystemData.CreatedBy)
+	}
+
+	for _, owner := range l.Properties.Owners {
+		if owner.ApplicationId != "" {
+			owners = append(owners, owner.ApplicationId)
+		}
+	}
+
+	return owners
+}
+
+type managementLocksResponse struct {
+	Value    []ManagementLock `json:"value"`
+	NextLink string           `json:"nextLink"`
+}
+
+type permissionsResponse struct {
+	Value []struct {
+		Actions    []string `json:"actions"`
+		NotActions []string `json:"notActions"`
+	} `json:"value"`
+}
+
+// ManagementLockClient lists and deletes the management locks of a subscription
+// More info can be found at the following:
+// https://learn.microsoft.com/azure/azure-resource-manager/management/lock-resources
+type ManagementLockClient struct {
+	subscriptionId string
+	endpoint       string
+	pipeline       runtime.Pipeline
+}
+
+// Creates a new ManagementLockClient instance
+func NewManagementLockClient(
+	subscriptionId string,
+	credential azcore.TokenCredential,
+	options *arm.ClientOptions,
+) (*ManagementLockClient, error) {
+	if options == nil {
+		options = &arm.ClientOptions{}
+	}
+
+	pipeline, err := armruntime.NewPipeline("management-lock", "1.0.0", credential, runtime.PipelineOptions{}, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating HTTP pipeline: %w", err)
+	}
+
+	endpoint := cloud.AzurePublic.Services[cloud.ResourceManager].Endpoint
+	if configuration, has := options.Cloud.Services[cloud.ResourceManager]; has {
+		endpoint = configuration.Endpoint
+	}
+
+	return &ManagementLockClient{
+		subscriptionId: subscriptionId,
+		endpoint:       strings.TrimSuffix(endpoint, "/"),
+		pipeline:       pipeline,
+	}, nil
+}
+
+// ListLocks returns the locks of the subscription, its resource groups and its resources
+func (c *ManagementLockClient) ListLocks(ctx context.Context) ([]ManagementLock, error) {
+	locks := []ManagementLock{}
+
+	endpoint := fmt.Sprintf(
+		"%s/subscriptions/%s/providers/Microsoft.Authorization/locks?api-version=%s",
+		c.endpoint,
+		url.PathEscape(c.subscriptionId),
+		managementLocksApiVersion,
+	)
+	for endpoint != "" {
+		request, err := runtime.NewRequest(ctx, http.MethodGet, endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+
+		response, err := c.pipeline.Do(request)
+		if err != nil {
+			return nil, httputil.HandleRequestError(response, err)
+		}
+
+		page, err := readArmResponse[managementLocksResponse](response)
+		if err != nil {
+			return nil, err
+		}
+
+		locks = append(locks, page.Value...)
+		endpoint = page.NextLink
+	}
+
+	return locks, nil
+}
+
+// DeleteLock deletes the lock with the id
+func (c *ManagementLockClient) DeleteLock(ctx context.Context, lockId string) error {
+	request, err := runtime.NewRequest(
+		ctx,
+		http.MethodDelete,
+		fmt.Sprintf("%s%s?api-version=%s", c.endpoint, lockId, managementLocksApiVersion),
+	)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	response, err := c.pipeline.Do(request)
+	if err != nil {
+		return httputil.HandleRequestError(response, err)
+	}
+
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusOK, http.StatusNoContent) {
+		return runtime.NewResponseError(response)
+	}
+
+	return nil
+}
+
+// CanDeleteLocks returns true when the signed-in principal is allowed to delete the locks of the scope
+func (c *ManagementLockClient) CanDeleteLocks(ctx context.Context, scope string) (bool, error) {
+	request, err := runtime.NewRequest(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf(
+			"%s%s/providers/Microsoft.Authorization/permissions?api-version=%s",
+			c.endpoint,
+			scope,
+			permissionsApiVersion,
+		),
+	)
+	if err != nil {
+		return false, fmt.Errorf("creating request: %w", err)
+	}
+
+	response, err := c.pipeline.Do(request)
+	if err != nil {
+		return false, httputil.HandleRequestError(response, err)
+	}
+
+	permissions, err := readArmResponse[permissionsResponse](response)
+	if err != nil {
+		return false, err
+	}
+
+	const deleteLockAction = "Microsoft.Authorization/locks/delete"
+
+	// The not actions of a permission only exclude the actions of the same permission
+	allowed := false
+	for _, permission := range permissions.Value {
+		granted := false
+		for _, action := range permission.Actions {
+			if matchesAction(action, deleteLockAction) {
+				granted = true
+			}
+		}
+
+		for _, notAction := range permission.NotActions {
+			if matchesAction(notAction, deleteLockAction) {
+				granted = false
+			}
+		}
+
+		allowed = allowed || granted
+	}
+
+	return allowed, nil
+}
+
+// matchesAction returns true when the pattern of a role definition, i.e. Microsoft.Authorization/*/Delete, matches the
+// action. The wildcards of the pattern match any characters and the actions are case insensitive.
+func matchesAction(pattern string, action string) bool {
+	expression := "(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	matched, err := regexp.MatchString(expression, action)
+	return err == nil && matched
+}
+
+func readArmResponse[T any](response *http.Response) (*T, error) {
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return nil, runtime.NewResponseError(response)
+	}
+
+	return httputil.ReadRawResponse[T](response)
+}
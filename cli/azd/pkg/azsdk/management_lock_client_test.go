@@ -0,0 +1,58 @@
+package azsdk
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManagementLockScope(t *testing.T) {
+	lock := ManagementLock{
+		Id: "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg/providers/microsoft.authorization/locks/keep",
+	}
+
+	require.Equal(t, "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg", lock.Scope())
+}
+
+func TestCanDeleteLocks(t *testing.T) {
+	tests := []struct {
+		name       string
+		actions    []string
+		notActions []string
+		expected   bool
+	}{
+		{name: "Owner", actions: []string{"*"}, expected: true},
+		{name: "Reader", actions: []string{"*/read"}, expected: false},
+		{name: "Contributor", actions: []string{"*"}, notActions: []string{"Microsoft.Authorization/*/Delete"}},
+		{name: "LockAdministrator", actions: []string{"Microsoft.Authorization/locks/*"}, expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return request.Method == http.MethodGet &&
+					strings.HasSuffix(request.URL.Path, "/rg/providers/Microsoft.Authorization/permissions")
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+					"value": []map[string]any{{"actions": test.actions, "notActions": test.notActions}},
+				})
+			})
+
+			options := NewClientOptionsBuilder().
+				WithTransport(mockContext.HttpClient).
+				BuildArmClientOptions()
+
+			client, err := NewManagementLockClient("SUBSCRIPTION_ID", &mocks.MockCredentials{}, options)
+			require.NoError(t, err)
+
+			allowed, err := client.CanDeleteLocks(*mockContext.Context, "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg")
+			require.NoError(t, err)
+			require.Equal(t, test.expected, allowed)
+		})
+	}
+}
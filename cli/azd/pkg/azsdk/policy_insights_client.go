@@ -0,0 +1,128 @@
+package azsdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	armruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// The API version of the policy restrictions API
+const policyRestrictionsApiVersion = "2022-03-01"
+
+// The result of the evaluation of a resource that doesn't comply with a policy
+const PolicyEvaluationResultNonCompliant = "NonCompliant"
+
+// PolicyEvaluation is the evaluation of a resource against a policy assigned to its scope
+type PolicyEvaluation struct {
+	PolicyInfo struct {
+		PolicyDefinitionId          string `json:"policyDefinitionId"`
+		PolicyDefinitionDisplayName string `json:"policyDefinitionDisplayName"`
+		PolicyDefinitionEffect      string `json:"policyDefinitionEffect"`
+		PolicyAssignmentId          string `json:"policyAssignmentId"`
+		PolicyAssignmentDisplayName string `json:"policyAssignmentDisplayName"`
+	} `json:"policyInfo"`
+	EvaluationResult  string `json:"evaluationResult"`
+	EvaluationDetails struct {
+		Reason string `json:"reason"`
+	} `json:"evaluationDetails"`
+}
+
+type checkPolicyRestrictionsRequest struct {
+	ResourceDetails struct {
+		ResourceContent any    `json:"resourceContent"`
+		ApiVersion      string `json:"apiVersion,omitempty"`
+	} `json:"resourceDetails"`
+}
+
+type checkPolicyRestrictionsResponse struct {
+	ContentEvaluationResult struct {
+		PolicyEvaluations []PolicyEvaluation `json:"policyEvaluations"`
+	} `json:"contentEvaluationResult"`
+}
+
+// PolicyInsightsClient evaluates resources against the Azure Policies assigned to a subscription
+// More info can be found at the following:
+// https://learn.microsoft.com/rest/api/policy/policy-restrictions/check-at-subscription-scope
+type PolicyInsightsClient struct {
+	subscriptionId string
+	endpoint       string
+	pipeline       runtime.Pipeline
+}
+
+// Creates a new PolicyInsightsClient instance
+func NewPolicyInsightsClient(
+	subscriptionId string,
+	credential azcore.TokenCredential,
+	options *arm.ClientOptions,
+) (*PolicyInsightsClient, error) {
+	if options == nil {
+		options = &arm.ClientOptions{}
+	}
+
+	pipeline, err := armruntime.NewPipeline("policy-insights", "1.0.0", credential, runtime.PipelineOptions{}, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating HTTP pipeline: %w", err)
+	}
+
+	endpoint := cloud.AzurePublic.Services[cloud.ResourceManager].Endpoint
+	if configuration, has := options.Cloud.Services[cloud.ResourceManager]; has {
+		endpoint = configuration.Endpoint
+	}
+
+	return &PolicyInsightsClient{
+		subscriptionId: subscriptionId,
+		endpoint:       strings.TrimSuffix(endpoint, "/"),
+		pipeline:       pipeline,
+	}, nil
+}
+
+// CheckPolicyRestrictions evaluates the content of a resource, as it would be sent to ARM with the API version,
+// against the policies assigned to the subscription and returns the evaluations of the policies
+func (c *PolicyInsightsClient) CheckPolicyRestrictions(
+	ctx context.Context,
+	resourceContent any,
+	apiVersion string,
+) ([]PolicyEvaluation, error) {
+	request, err := runtime.NewRequest(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf(
+			"%s/subscriptions/%s/providers/Microsoft.PolicyInsights/checkPolicyRestrictions?api-version=%s",
+			c.endpoint,
+			url.PathEscape(c.subscriptionId),
+			policyRestrictionsApiVersion,
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	body := checkPolicyRestrictionsRequest{}
+	body.ResourceDetails.ResourceContent = resourceContent
+	body.ResourceDetails.ApiVersion = apiVersion
+
+	if err := runtime.MarshalAsJSON(request, body); err != nil {
+		return nil, fmt.Errorf("creating request body: %w", err)
+	}
+
+	response, err := c.pipeline.Do(request)
+	if err != nil {
+		return nil, httputil.HandleRequestError(response, err)
+	}
+
+	result, err := readArmResponse[checkPolicyRestrictionsResponse](response)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.ContentEvaluationResult.PolicyEvaluations, nil
+}
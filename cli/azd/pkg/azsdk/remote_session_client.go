@@ -0,0 +1,299 @@
+package azsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	armruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/websocket"
+)
+
+// The credentials of the SSH server of the App Service containers, reachable only through the authenticated tunnel
+// https://learn.microsoft.com/azure/app-service/configure-linux-open-ssh-session
+const (
+	appServiceSshUser     = "root"
+	appServiceSshPassword = "Docker!"
+)
+
+// The channels prefixing the messages of a Container Apps exec session
+const (
+	containerAppChannelStdin  byte = 0
+	containerAppChannelStdout byte = 1
+	containerAppChannelStderr byte = 2
+	containerAppChannelError  byte = 3
+	containerAppChannelResize byte = 4
+)
+
+// The command started in Container Apps containers when none is specified
+const defaultContainerAppCommand = "/bin/sh"
+
+// RemoteSession is an interactive session in the runtime of a deployed application
+type RemoteSession interface {
+	// Run attaches the streams to the session until the remote command exits.
+	// width and height are the size, in characters, of the terminal the streams are attached to.
+	Run(stdin io.Reader, stdout io.Writer, stderr io.Writer, width int, height int) error
+	Close() error
+}
+
+// RemoteSessionClient opens interactive sessions in App Service, Azure Functions and Container Apps applications
+// More info can be found at the following:
+// https://learn.microsoft.com/azure/app-service/configure-linux-open-ssh-session
+// https://learn.microsoft.com/azure/container-apps/container-console
+type RemoteSessionClient struct {
+	subscriptionId string
+	endpoint       string
+	credential     azcore.TokenCredential
+	scope          string
+	pipeline       runtime.Pipeline
+}
+
+// Creates a new RemoteSessionClient instance
+func NewRemoteSessionClient(
+	subscriptionId string,
+	credential azcore.TokenCredential,
+	options *arm.ClientOptions,
+) (*RemoteSessionClient, error) {
+	if options == nil {
+		options = &arm.ClientOptions{}
+	}
+
+	// We do not have a Resource provider to register
+	options.DisableRPRegistration = true
+
+	pipeline, err := armruntime.NewPipeline("remote-session", "1.0.0", credential, runtime.PipelineOptions{}, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating HTTP pipeline: %w", err)
+	}
+
+	configuration := cloud.AzurePublic.Services[cloud.ResourceManager]
+	if cloudConfiguration, has := options.Cloud.Services[cloud.ResourceManager]; has {
+		configuration = cloudConfiguration
+	}
+
+	scope := configuration.Audience
+	if !strings.HasSuffix(scope, "/.default") {
+		scope += "/.default"
+	}
+
+	return &RemoteSessionClient{
+		subscriptionId: subscriptionId,
+		endpoint:       strings.TrimSuffix(configuration.Endpoint, "/"),
+		credential:     credential,
+		scope:          scope,
+		pipeline:       pipeline,
+	}, nil
+}
+
+// OpenAppServiceSession opens an SSH session in the container of the App Service or Azure Functions application,
+// running the command or, when the command is empty, the default shell. The SSH server is reached through the
+// tunnel of the SCM site of the application, authenticated with the Azure credential.
+func (c *RemoteSessionClient) OpenAppServiceSession(
+	ctx context.Context,
+	appName string,
+	command string,
+) (RemoteSession, error) {
+	token, err := c.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{c.scope}})
+	if err != nil {
+		return nil, fmt.Errorf("getting access token: %w", err)
+	}
+
+	conn, err := dialWebSocket(
+		fmt.Sprintf("wss://%s.scm.azurewebsites.net/AppServiceTunnel/Tunnel.ashx", appName),
+		token.Token,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("opening tunnel: %w", err)
+	}
+
+	sshConn, channels, requests, err := ssh.NewClientConn(conn, conn.RemoteAddr().String(), &ssh.ClientConfig{
+		User: appServiceSshUser,
+		Auth: []ssh.AuthMethod{ssh.Password(appServiceSshPassword)},
+		// The tunnel already authenticates the SCM site with TLS, and the host key of the container changes
+		// every time it is started
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("connecting to SSH server, SSH must be enabled in the container: %w", err)
+	}
+
+	return &appServiceSession{
+		client:  ssh.NewClient(sshConn, channels, requests),
+		command: command,
+	}, nil
+}
+
+// OpenContainerAppSession opens a session in the first container of the latest revision of the Container Apps
+// application, running the command or, when the command is empty, /bin/sh.
+func (c *RemoteSessionClient) OpenContainerAppSession(
+	ctx context.Context,
+	resourceGroup string,
+	appName string,
+	command string,
+) (RemoteSession, error) {
+	replica, err := resolveContainerAppReplica(ctx, c.pipeline, c.endpoint, c.subscriptionId, resourceGroup, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	if command == "" {
+		command = defaultContainerAppCommand
+	}
+
+	endpoint := fmt.Sprintf(
+		"%s/exec?command=%s",
+		strings.Replace(replica.endpoint, "https://", "wss://", 1),
+		url.QueryEscape(command),
+	)
+
+	conn, err := dialWebSocket(endpoint, replica.token)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to container: %w", err)
+	}
+
+	return &containerAppSession{conn: conn}, nil
+}
+
+func dialWebSocket(endpoint string, token string) (*websocket.Conn, error) {
+	location, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := websocket.NewConfig(endpoint, "https://"+location.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	config.Header.Set("Authorization", "Bearer "+token)
+
+	conn, err := websocket.DialConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.PayloadType = websocket.BinaryFrame
+	return conn, nil
+}
+
+type appServiceSession struct {
+	client  *ssh.Client
+	command string
+}
+
+func (s *appServiceSession) Run(stdin io.Reader, stdout io.Writer, stderr io.Writer, width int, height int) error {
+	session, err := s.client.NewSession()
+	if err != nil {
+		return fmt.Errorf("creating SSH session: %w", err)
+	}
+	defer session.Close()
+
+	if err := session.RequestPty("xterm", height, width, ssh.TerminalModes{ssh.ECHO: 1}); err != nil {
+		return fmt.Errorf("requesting terminal: %w", err)
+	}
+
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	if s.command == "" {
+		err = session.Shell()
+	} else {
+		err = session.Start(s.command)
+	}
+	if err != nil {
+		return fmt.Errorf("starting remote command: %w", err)
+	}
+
+	return session.Wait()
+}
+
+func (s *appServiceSession) Close() error {
+	return s.client.Close()
+}
+
+type containerAppSession struct {
+	conn *websocket.Conn
+}
+
+func (s *containerAppSession) Run(stdin io.Reader, stdout io.Writer, stderr io.Writer, width int, height int) error {
+	size, err := json.Marshal(struct {
+		Width  int
+		Height int
+	}{width, height})
+	if err != nil {
+		return err
+	}
+
+	if err := s.send(containerAppChannelResize, size); err != nil {
+		return fmt.Errorf("resizing terminal: %w", err)
+	}
+
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := stdin.Read(buf)
+			if n > 0 {
+				if sendErr := s.send(containerAppChannelStdin, buf[:n]); sendErr != nil {
+					return
+				}
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		var message []byte
+		if err := websocket.Message.Receive(s.conn, &message); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return fmt.Errorf("reading from container: %w", err)
+		}
+
+		if len(message) == 0 {
+			continue
+		}
+
+		switch message[0] {
+		case containerAppChannelStdout:
+			_, err = stdout.Write(message[1:])
+		case containerAppChannelStderr:
+			_, err = stderr.Write(message[1:])
+		case containerAppChannelError:
+			return fmt.Errorf("remote command failed: %s", strings.TrimSpace(string(message[1:])))
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// send sends the data on the channel, the messages sent by the client are prefixed with the stdin channel
+func (s *containerAppSession) send(channel byte, data []byte) error {
+	message := make([]byte, 0, len(data)+2)
+	message = append(message, containerAppChannelStdin, channel)
+	message = append(message, data...)
+
+	return websocket.Message.Send(s.conn, message)
+}
+
+func (s *containerAppSession) Close() error {
+	return s.conn.Close()
+}
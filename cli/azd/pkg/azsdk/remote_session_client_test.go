@@ -0,0 +1,63 @@
+package azsdk
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func TestContainerAppSession(t *testing.T) {
+	received := make(chan []byte, 2)
+
+	// Replies to the input of the client on the output channels, then ends the session
+	server := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		for i := 0; i < 2; i++ {
+			var message []byte
+			if err := websocket.Message.Receive(conn, &message); err != nil {
+				return
+			}
+			received <- message
+		}
+
+		_ = websocket.Message.Send(conn, append([]byte{containerAppChannelStdout}, "hello\n"...))
+		_ = websocket.Message.Send(conn, append([]byte{containerAppChannelStderr}, "warning\n"...))
+	}))
+	defer server.Close()
+
+	conn, err := dialWebSocket(strings.Replace(server.URL, "http://", "ws://", 1), "TOKEN")
+	require.NoError(t, err)
+
+	session := &containerAppSession{conn: conn}
+	defer session.Close()
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	err = session.Run(strings.NewReader("ls\n"), stdout, stderr, 120, 40)
+	require.NoError(t, err)
+
+	require.Equal(t, "hello\n", stdout.String())
+	require.Equal(t, "warning\n", stderr.String())
+
+	require.Equal(t, append([]byte{0, containerAppChannelResize}, `{"Width":120,"Height":40}`...), <-received)
+	require.Equal(t, append([]byte{0, containerAppChannelStdin}, "ls\n"...), <-received)
+}
+
+func TestContainerAppSessionError(t *testing.T) {
+	server := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		_ = websocket.Message.Send(conn, append([]byte{containerAppChannelError}, "command not found\n"...))
+	}))
+	defer server.Close()
+
+	conn, err := dialWebSocket(strings.Replace(server.URL, "http://", "ws://", 1), "TOKEN")
+	require.NoError(t, err)
+
+	session := &containerAppSession{conn: conn}
+	defer session.Close()
+
+	err = session.Run(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}, 80, 24)
+	require.EqualError(t, err, "remote command failed: command not found")
+}
@@ -0,0 +1,47 @@
+package azsdk
+
+import (
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/fields"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type tracingPolicy struct {
+	spanName string
+}
+
+// Policy to emit a trace span for every HTTP request sent through the pipeline.
+// Only the method, host & status code are recorded, the path & query may contain identifiers.
+func NewTracingPolicy(spanName string) policy.Policy {
+	return &tracingPolicy{
+		spanName: spanName,
+	}
+}
+
+// Wraps the underlying request within a span
+func (p *tracingPolicy) Do(req *policy.Request) (*http.Response, error) {
+	rawRequest := req.Raw()
+	_, span := telemetry.GetTracer().Start(rawRequest.Context(), p.spanName)
+	defer span.End()
+
+	span.SetAttributes(
+		fields.HttpMethodKey.String(rawRequest.Method),
+		fields.HttpHostKey.String(rawRequest.URL.Host),
+	)
+
+	response, err := req.Next()
+	if err != nil {
+		span.SetStatus(codes.Error, "UnknownError")
+		return response, err
+	}
+
+	span.SetAttributes(fields.HttpStatusCodeKey.Int(response.StatusCode))
+	if response.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(response.StatusCode))
+	}
+
+	return response, nil
+}
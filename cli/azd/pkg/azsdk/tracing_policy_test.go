@@ -0,0 +1,48 @@
+package azsdk
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/fields"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingPolicy(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(spanRecorder)))
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return true
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+	})
+
+	clientOptions := NewClientOptionsBuilder().
+		WithTransport(mockContext.HttpClient).
+		WithPerCallPolicy(NewTracingPolicy("test.request")).
+		BuildCoreClientOptions()
+
+	pipeline := runtime.NewPipeline("test", "1.0.0", runtime.PipelineOptions{}, clientOptions)
+	req, err := runtime.NewRequest(*mockContext.Context, http.MethodGet, "https://graph.microsoft.com/v1.0/me")
+	require.NoError(t, err)
+
+	_, err = pipeline.Do(req)
+	require.NoError(t, err)
+
+	spans := spanRecorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "test.request", spans[0].Name())
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+	require.Contains(t, spans[0].Attributes(), fields.HttpMethodKey.String(http.MethodGet))
+	require.Contains(t, spans[0].Attributes(), fields.HttpHostKey.String("graph.microsoft.com"))
+	require.Contains(t, spans[0].Attributes(), fields.HttpStatusCodeKey.Int(http.StatusNotFound))
+}
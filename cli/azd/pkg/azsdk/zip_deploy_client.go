@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -87,6 +88,28 @@ func (c *ZipDeployClient) BeginDeploy(
 		return nil, err
 	}
 
+	return c.beginDeployment(request)
+}
+
+// Begins a OneDeploy deployment of a zip file and returns a poller to check for status. Unless clean is set, the files
+// of the application missing from the zip file are kept, so the zip file can only hold the changed files.
+// https://github.com/projectkudu/kudu/wiki/Deploying-from-a-zip-file-or-url
+func (c *ZipDeployClient) BeginPublish(
+	ctx context.Context,
+	appName string,
+	zipFile io.Reader,
+	clean bool,
+) (*runtime.Poller[*DeployResponse], error) {
+	request, err := c.createPublishRequest(ctx, appName, zipFile, clean)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.beginDeployment(request)
+}
+
+// Sends the request starting a deployment and returns a poller to check for its status
+func (c *ZipDeployClient) beginDeployment(request *policy.Request) (*runtime.Poller[*DeployResponse], error) {
 	response, err := c.pipeline.Do(request)
 	if err != nil {
 		return nil, httputil.HandleRequestError(response, err)
@@ -125,6 +148,60 @@ func (c *ZipDeployClient) Deploy(ctx context.Context, appName string, zipFile io
 	return response, nil
 }
 
+// Publishes the specified application zip to the azure app service with OneDeploy and waits for completion
+func (c *ZipDeployClient) Publish(
+	ctx context.Context,
+	appName string,
+	zipFile io.Reader,
+	clean bool,
+) (*DeployResponse, error) {
+	poller, err := c.BeginPublish(ctx, appName, zipFile, clean)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{
+		Frequency: deployStatusInterval,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Gets the status of the latest deployment of the application, nil when the application was never deployed
+func (c *ZipDeployClient) GetLatestDeployment(ctx context.Context, appName string) (*DeployStatus, error) {
+	endpoint := fmt.Sprintf("https://%s.scm.azurewebsites.net/api/deployments/latest", appName)
+	req, err := runtime.NewRequest(ctx, http.MethodGet, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("creating latest deployment request: %w", err)
+	}
+
+	req.Raw().Header.Set("Accept", "application/json")
+
+	response, err := c.pipeline.Do(req)
+	if err != nil {
+		return nil, httputil.HandleRequestError(response, err)
+	}
+
+	if runtime.HasStatusCode(response, http.StatusNotFound) {
+		response.Body.Close()
+		return nil, nil
+	}
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return nil, runtime.NewResponseError(response)
+	}
+
+	status, err := httputil.ReadRawResponse[DeployStatus](response)
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
 // Creates the HTTP request for the zip deployment operation
 func (c *ZipDeployClient) createDeployRequest(
 	ctx context.Context,
@@ -148,6 +225,32 @@ func (c *ZipDeployClient) createDeployRequest(
 	return req, nil
 }
 
+// Creates the HTTP request for the OneDeploy deployment of a zip file
+func (c *ZipDeployClient) createPublishRequest(
+	ctx context.Context,
+	appName string,
+	zipFile io.Reader,
+	clean bool,
+) (*policy.Request, error) {
+	endpoint := fmt.Sprintf("https://%s.scm.azurewebsites.net/api/publish", appName)
+	req, err := runtime.NewRequest(ctx, http.MethodPost, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("creating publish request: %w", err)
+	}
+
+	rawRequest := req.Raw()
+	rawRequest.Body = io.NopCloser(zipFile)
+	query := rawRequest.URL.Query()
+	query.Set("type", "zip")
+	query.Set("clean", strconv.FormatBool(clean))
+	query.Set("async", "true")
+	rawRequest.Header.Set("Content-Type", "application/octet-stream")
+	rawRequest.Header.Set("Accept", "application/json")
+	rawRequest.URL.RawQuery = query.Encode()
+
+	return req, nil
+}
+
 // Implementation of a Go SDK polling handler for async zip deploy operations
 type deployPollingHandler struct {
 	pipeline runtime.Pipeline
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"net/http"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
@@ -83,6 +84,76 @@ func TestZipDeploy(t *testing.T) {
 	})
 }
 
+func TestPublish(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	var query url.Values
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.Contains(request.URL.Path, "/api/publish")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		query = request.URL.Query()
+		response, _ := mocks.CreateEmptyHttpResponse(request, http.StatusAccepted)
+		response.Header.Set("Location", "http://myapp.scm.azurewebsites.net/deployments/latest")
+
+		return response, nil
+	})
+	registerPollingMocks(mockContext)
+
+	options := NewClientOptionsBuilder().
+		WithTransport(mockContext.HttpClient).
+		BuildArmClientOptions()
+
+	client, err := NewZipDeployClient("SUBSCRIPTION_ID", &mocks.MockCredentials{}, options)
+	require.NoError(t, err)
+
+	poller, err := client.BeginPublish(*mockContext.Context, "APP_NAME", bytes.NewBuffer([]byte{}), false)
+	require.NoError(t, err)
+
+	response, err := poller.PollUntilDone(*mockContext.Context, &runtime.PollUntilDoneOptions{
+		Frequency: 250 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	require.True(t, response.Complete)
+	require.Equal(t, "zip", query.Get("type"))
+	require.Equal(t, "false", query.Get("clean"))
+	require.Equal(t, "true", query.Get("async"))
+}
+
+func TestGetLatestDeployment(t *testing.T) {
+	t.Run("Deployed", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && request.URL.Path == "/api/deployments/latest"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, DeployStatus{Id: "ID", Complete: true})
+		})
+
+		client, err := NewZipDeployClient("SUBSCRIPTION_ID", &mocks.MockCredentials{},
+			NewClientOptionsBuilder().WithTransport(mockContext.HttpClient).BuildArmClientOptions())
+		require.NoError(t, err)
+
+		status, err := client.GetLatestDeployment(*mockContext.Context, "APP_NAME")
+		require.NoError(t, err)
+		require.Equal(t, "ID", status.Id)
+	})
+
+	t.Run("NeverDeployed", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && request.URL.Path == "/api/deployments/latest"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+		})
+
+		client, err := NewZipDeployClient("SUBSCRIPTION_ID", &mocks.MockCredentials{},
+			NewClientOptionsBuilder().WithTransport(mockContext.HttpClient).BuildArmClientOptions())
+		require.NoError(t, err)
+
+		status, err := client.GetLatestDeployment(*mockContext.Context, "APP_NAME")
+		require.NoError(t, err)
+		require.Nil(t, status)
+	})
+}
+
 func registerConflictMocks(mockContext *mocks.MockContext) {
 	// Original call to start the deployment operation
 	mockContext.HttpClient.When(func(request *http.Request) bool {
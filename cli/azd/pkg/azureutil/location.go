@@ -6,6 +6,7 @@ package azureutil
 import (
 	"context"
 	"fmt"
+	"log"
 	"os"
 	"sort"
 	"strings"
@@ -25,8 +26,14 @@ func (s Locs) Less(i, j int) bool {
 }
 func (s Locs) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
 
-// PromptLocation asks the user to select a location from a list of supported azure location
-func PromptLocation(ctx context.Context, env *environment.Environment, message string) (string, error) {
+// PromptLocation asks the user to select a location from a list of supported azure location. When resource types are
+// specified, i.e. Microsoft.Web/sites, only the locations where all of them are available are listed.
+func PromptLocation(
+	ctx context.Context,
+	env *environment.Environment,
+	message string,
+	resourceTypes []string,
+) (string, error) {
 	accountManager, err := account.NewManager(config.NewManager(), azcli.GetAzCli(ctx))
 	if err != nil {
 		return "", fmt.Errorf("failed creating account manager: %w", err)
@@ -38,6 +45,7 @@ func PromptLocation(ctx context.Context, env *environment.Environment, message s
 		return "", fmt.Errorf("listing locations: %w", err)
 	}
 
+	locations = filterLocationsByResourceTypes(ctx, env.GetSubscriptionId(), locations, resourceTypes)
 	sort.Sort(Locs(locations))
 
 	// Allow the environment variable `AZURE_LOCATION` to control the default value for the location
@@ -55,6 +63,9 @@ func PromptLocation(ctx context.Context, env *environment.Environment, message s
 	locationOptions := make([]string, len(locations))
 	for index, location := range locations {
 		locationOptions[index] = fmt.Sprintf("%2d. %s (%s)", index+1, location.RegionalDisplayName, location.Name)
+		if location.PairedRegionDisplayName != "" {
+			locationOptions[index] += fmt.Sprintf(", paired with %s", location.PairedRegionDisplayName)
+		}
 
 		if strings.EqualFold(defaultLocation, location.Name) ||
 			strings.EqualFold(defaultLocation, location.DisplayName) {
@@ -74,3 +85,54 @@ func PromptLocation(ctx context.Context, env *environment.Environment, message s
 
 	return locations[selectedIndex].Name, nil
 }
+
+// filterLocationsByResourceTypes returns the locations where all the resource types are available. The resource types
+// whose availability can't be determined are ignored, and all the locations are returned when none matches.
+func filterLocationsByResourceTypes(
+	ctx context.Context,
+	subscriptionId string,
+	locations []azcli.AzCliLocation,
+	resourceTypes []string,
+) []azcli.AzCliLocation {
+	azCli := azcli.GetAzCli(ctx)
+	filtered := locations
+
+	for _, resourceType := range resourceTypes {
+		typeLocations, err := azCli.GetResourceTypeLocations(ctx, subscriptionId, resourceType)
+		if err != nil {
+			log.Printf("failed getting locations of resource type '%s': %v", resourceType, err)
+			continue
+		}
+
+		// The resource types available globally have no locations
+		available := map[string]bool{}
+		for _, location := range typeLocations {
+			available[normalizeLocationName(location)] = true
+		}
+
+		if len(available) == 0 || available["global"] {
+			continue
+		}
+
+		matching := []azcli.AzCliLocation{}
+		for _, location := range filtered {
+			if available[normalizeLocationName(location.Name)] || available[normalizeLocationName(location.DisplayName)] {
+				matching = append(matching, location)
+			}
+		}
+
+		filtered = matching
+	}
+
+	if len(filtered) == 0 {
+		log.Printf("no location supports all the resource types %v, listing all locations", resourceTypes)
+		return locations
+	}
+
+	return filtered
+}
+
+// normalizeLocationName returns the name of a location from its display name, i.e. eastus for East US
+func normalizeLocationName(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
@@ -0,0 +1,79 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azureutil
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_filterLocationsByResourceTypes(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	// Static web apps are only available in a few locations, resource groups are available everywhere
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/providers/Microsoft.Web")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armresources.Provider{
+			ResourceTypes: []*armresources.ProviderResourceType{
+				{ResourceType: to.Ptr("sites"), Locations: []*string{to.Ptr("East US"), to.Ptr("West Europe")}},
+				{ResourceType: to.Ptr("staticSites"), Locations: []*string{to.Ptr("West Europe")}},
+			},
+		})
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/providers/Microsoft.Resources")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armresources.Provider{
+			ResourceTypes: []*armresources.ProviderResourceType{
+				{ResourceType: to.Ptr("resourceGroups"), Locations: []*string{}},
+			},
+		})
+	})
+
+	locations := []azcli.AzCliLocation{
+		{Name: "eastus", DisplayName: "East US"},
+		{Name: "westeurope", DisplayName: "West Europe"},
+		{Name: "japaneast", DisplayName: "Japan East"},
+	}
+
+	t.Run("Filtered", func(t *testing.T) {
+		filtered := filterLocationsByResourceTypes(*mockContext.Context, "SUBSCRIPTION_ID", locations, []string{
+			"Microsoft.Resources/resourceGroups",
+			"Microsoft.Web/sites",
+		})
+		require.Equal(t, []string{"eastus", "westeurope"}, locationNames(filtered))
+
+		filtered = filterLocationsByResourceTypes(*mockContext.Context, "SUBSCRIPTION_ID", locations, []string{
+			"Microsoft.Web/sites",
+			"Microsoft.Web/staticSites",
+		})
+		require.Equal(t, []string{"westeurope"}, locationNames(filtered))
+	})
+
+	t.Run("UnknownResourceType", func(t *testing.T) {
+		filtered := filterLocationsByResourceTypes(*mockContext.Context, "SUBSCRIPTION_ID", locations, []string{
+			"Microsoft.Web/unknown",
+		})
+		require.Equal(t, locations, filtered)
+	})
+}
+
+func locationNames(locations []azcli.AzCliLocation) []string {
+	names := []string{}
+	for _, location := range locations {
+		names = append(names, location.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,115 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package ci publishes the results of the azd commands run by a pipeline to the UI of the CI system.
+package ci
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+)
+
+// Summary is the result of an azd command, published to the summary of the CI run
+type Summary struct {
+	// the heading of the summary, i.e. "Provisioned the environment dev"
+	Title string
+	// the name of the environment the command ran for, tagged on the Azure Pipelines build
+	Environment string
+	// the facts of the run in order, i.e. the subscription and the resource groups
+	Details []Detail
+	// the endpoints of the deployed services
+	Endpoints []Endpoint
+}
+
+// Detail is a fact of the run of a command, listed in its summary
+type Detail struct {
+	Name  string
+	Value string
+}
+
+// Endpoint is an endpoint of a deployed service
+type Endpoint struct {
+	Service string
+	Url     string
+}
+
+// escapes the text of the cells of a markdown table
+var markdownTableEscaper = strings.NewReplacer("|", "\\|", "\r", " ", "\n", " ")
+
+// Markdown renders the summary as GitHub flavored markdown, understood by both CI systems
+func (s Summary) Markdown() string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("### %s\n", s.Title))
+
+	if len(s.Details) > 0 {
+		builder.WriteString("\n")
+		for _, detail := range s.Details {
+			builder.WriteString(fmt.Sprintf("- **%s**: %s\n", detail.Name, detail.Value))
+		}
+	}
+
+	if len(s.Endpoints) > 0 {
+		builder.WriteString("\n| Service | Endpoint |\n")
+		builder.WriteString("| --- | --- |\n")
+		for _, endpoint := range s.Endpoints {
+			builder.WriteString(fmt.Sprintf("| %s | %s |\n",
+				markdownTableEscaper.Replace(endpoint.Service), markdownTableEscaper.Replace(endpoint.Url)))
+		}
+	}
+
+	builder.WriteString("\n")
+	return builder.String()
+}
+
+// SummarySupported returns true when azd runs in GitHub Actions or Azure Pipelines, which display the published
+// summaries. The commands skip gathering their summary otherwise.
+func SummarySupported() bool {
+	return internal.DetectCIProvider() != internal.CIProviderNone
+}
+
+// PublishSummary appends the summary to the summary of the GitHub Actions job, or attaches it to the summary of the
+// Azure Pipelines build and tags the build with the environment. The logging commands of Azure Pipelines are written
+// to writer. Nothing is published outside of these CI systems.
+func PublishSummary(writer io.Writer, summary Summary) error {
+	switch internal.DetectCIProvider() {
+	case internal.CIProviderGitHubActions:
+		// https://docs.github.com/actions/using-workflows/workflow-commands-for-github-actions#adding-a-job-summary
+		path := os.Getenv("GITHUB_STEP_SUMMARY")
+		if path == "" {
+			return nil
+		}
+
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("opening the job summary: %w", err)
+		}
+		defer file.Close()
+
+		if _, err := file.WriteString(summary.Markdown()); err != nil {
+			return fmt.Errorf("writing the job summary: %w", err)
+		}
+	case internal.CIProviderAzurePipelines:
+		// https://learn.microsoft.com/azure/devops/pipelines/scripts/logging-commands
+		file, err := os.CreateTemp(os.Getenv("AGENT_TEMPDIRECTORY"), "azd-summary-*.md")
+		if err != nil {
+			return fmt.Errorf("creating the build summary: %w", err)
+		}
+		defer file.Close()
+
+		if _, err := file.WriteString(summary.Markdown()); err != nil {
+			return fmt.Errorf("writing the build summary: %w", err)
+		}
+
+		fmt.Fprintf(writer, "##vso[task.uploadsummary]%s\n", file.Name())
+		if summary.Environment != "" {
+			fmt.Fprintf(writer, "##vso[build.addbuildtag]azd-env-%s\n", summary.Environment)
+		}
+	}
+
+	return nil
+}
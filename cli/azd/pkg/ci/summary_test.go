@@ -0,0 +1,89 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package ci
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testSummary = Summary{
+	Title:       "Deployed the environment dev",
+	Environment: "dev",
+	Details:     []Detail{{Name: "Services", Value: "2 deployed"}},
+	Endpoints: []Endpoint{
+		{Service: "web", Url: "https://web.azurewebsites.net/"},
+		{Service: "api", Url: "https://api.azurewebsites.net/?a|b"},
+	},
+}
+
+func TestSummaryMarkdown(t *testing.T) {
+	require.Equal(t, `### Deployed the environment dev
+
+- **Services**: 2 deployed
+
+| Service | Endpoint |
+| --- | --- |
+| web | https://web.azurewebsites.net/ |
+| api | https://api.azurewebsites.net/?a\|b |
+
+`, testSummary.Markdown())
+
+	require.Equal(t, "### Provisioned the environment dev\n\n", Summary{Title: "Provisioned the environment dev"}.Markdown())
+}
+
+func TestPublishSummary(t *testing.T) {
+	t.Run("Local", func(t *testing.T) {
+		t.Setenv("GITHUB_ACTIONS", "")
+		t.Setenv("TF_BUILD", "")
+
+		var buf bytes.Buffer
+		require.False(t, SummarySupported())
+		require.NoError(t, PublishSummary(&buf, testSummary))
+		require.Empty(t, buf.String())
+	})
+
+	t.Run("GitHubActions", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "step_summary")
+		t.Setenv("GITHUB_ACTIONS", "true")
+		t.Setenv("TF_BUILD", "")
+		t.Setenv("GITHUB_STEP_SUMMARY", path)
+
+		var buf bytes.Buffer
+		require.True(t, SummarySupported())
+		require.NoError(t, PublishSummary(&buf, testSummary))
+		require.NoError(t, PublishSummary(&buf, testSummary))
+		require.Empty(t, buf.String())
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, testSummary.Markdown()+testSummary.Markdown(), string(content))
+	})
+
+	t.Run("AzurePipelines", func(t *testing.T) {
+		folder := t.TempDir()
+		t.Setenv("GITHUB_ACTIONS", "")
+		t.Setenv("TF_BUILD", "True")
+		t.Setenv("AGENT_TEMPDIRECTORY", folder)
+
+		var buf bytes.Buffer
+		require.True(t, SummarySupported())
+		require.NoError(t, PublishSummary(&buf, testSummary))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 2)
+		path := strings.TrimPrefix(lines[0], "##vso[task.uploadsummary]")
+		require.Equal(t, folder, filepath.Dir(path))
+		require.Equal(t, "##vso[build.addbuildtag]azd-env-dev", lines[1])
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, testSummary.Markdown(), string(content))
+	})
+}
@@ -4,9 +4,10 @@ import (
 	"context"
 	"os"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 	"github.com/azure/azure-dev/cli/azd/pkg/identity"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
@@ -24,25 +25,33 @@ func RegisterDependenciesInCtx(
 	ctx context.Context,
 	cmd *cobra.Command,
 	rootOptions *internal.GlobalCommandOptions,
+	configManager config.Manager,
 ) (context.Context, error) {
 	// Set the global options in the go context
 	ctx = internal.WithCommandOptions(ctx, *rootOptions)
 	ctx = tools.WithInstalledCheckCache(ctx)
 
+	// The policies of the outbound clients are loaded once, for all the clients created by the command
+	clientPolicies := httputil.LoadClientPolicies(configManager)
+	ctx = httputil.WithClientPolicies(ctx, clientPolicies)
+
+	verbosity := input.NewVerbosity(rootOptions.Quiet, rootOptions.Verbose)
+
 	runner := exec.NewCommandRunner(cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr())
+	if verbosity == input.VerbosityVerbose {
+		runner = exec.NewVerboseCommandRunner(cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr())
+	}
 	ctx = exec.WithCommandRunner(ctx, runner)
 
 	// Set default credentials used for operations against azure data/control planes
-	credentials, err := azidentity.NewAzureCLICredential(nil)
-	if err != nil {
-		panic("failed creating azure cli credential")
-	}
+	credentials := identity.DefaultCredential()
 	ctx = identity.WithCredentials(ctx, credentials)
 
 	azCliArgs := azcli.NewAzCliArgs{
 		EnableDebug:     rootOptions.EnableDebugLogging,
 		EnableTelemetry: rootOptions.EnableTelemetry,
 		CommandRunner:   runner,
+		ClientPolicies:  clientPolicies,
 	}
 
 	// Create and set the AzCli that will be used for the command
@@ -87,7 +96,7 @@ func RegisterDependenciesInCtx(
 		Stdin:  cmd.InOrStdin(),
 		Stdout: cmd.OutOrStdout(),
 		Stderr: cmd.ErrOrStderr(),
-	}, formatter)
+	}, formatter, verbosity)
 	ctx = input.WithConsole(ctx, console)
 
 	return ctx, nil
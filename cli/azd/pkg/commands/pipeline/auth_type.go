@@ -0,0 +1,178 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
+)
+
+// AuthType is how the pipeline signs in to Azure with its service principal
+type AuthType string
+
+const (
+	// AuthTypeClientSecret signs in with a client secret of the service principal, saved in the pipeline
+	AuthTypeClientSecret AuthType = "clientsecret"
+	// AuthTypeFederated signs in with the tokens of the CI provider, trusted by federated credentials of the service
+	// principal, without any secret
+	AuthTypeFederated AuthType = "federated"
+)
+
+// The key of the environment recording the auth type of the configured pipeline
+const authTypePersistedKey = "AZD_PIPELINE_AUTH_TYPE"
+
+// ParseAuthType returns the auth type of the value of --auth-type, an empty value is the default auth type
+func ParseAuthType(value string) (AuthType, error) {
+	switch authType := AuthType(strings.ToLower(strings.TrimSpace(value))); authType {
+	case "", AuthTypeClientSecret, AuthTypeFederated:
+		return authType, nil
+	default:
+		return "", fmt.Errorf(
+			"invalid auth type '%s', the supported values are %s and %s", value, AuthTypeClientSecret, AuthTypeFederated)
+	}
+}
+
+// resolveAuthType returns the auth type of the pipeline: the requested auth type, the auth type recorded by a previous
+// configuration, or federated credentials when the pipeline supports them. federatedUnsupported describes why the
+// pipeline doesn't support federated credentials, empty when it does.
+func resolveAuthType(requested AuthType, persisted string, federatedUnsupported string) (AuthType, error) {
+	if requested == AuthTypeFederated && federatedUnsupported != "" {
+		return "", fmt.Errorf(
+			"%s can't sign in with federated credentials, use --auth-type %s", federatedUnsupported, AuthTypeClientSecret)
+	}
+
+	if requested != "" {
+		return requested, nil
+	}
+
+	if recorded, err := ParseAuthType(persisted); err == nil && recorded != "" {
+		if recorded != AuthTypeFederated || federatedUnsupported == "" {
+			return recorded, nil
+		}
+	}
+
+	if federatedUnsupported == "" {
+		return AuthTypeFederated, nil
+	}
+
+	return AuthTypeClientSecret, nil
+}
+
+const (
+	// The issuer of the tokens of GitHub Actions
+	gitHubActionsIssuer = "https://token.actions.githubusercontent.com"
+	// The audience of the tokens exchanged for Azure AD tokens
+	federatedTokenAudience = "api://AzureADTokenExchange"
+)
+
+// The characters not allowed in the name of a federated credential
+var federatedCredentialNameRegex = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// gitHubFederatedCredentials returns the federated credentials trusting the workflows of the repository run for the
+// pushes to the branch and for pull requests
+func gitHubFederatedCredentials(repoSlug string, branch string) []graphsdk.FederatedIdentityCredential {
+	branchName := federatedCredentialNameRegex.ReplaceAllString(branch, "-")
+	if len(branchName) > 100 {
+		branchName = branchName[:100]
+	}
+
+	description := fmt.Sprintf("Created by azd pipeline config for %s", repoSlug)
+	return []graphsdk.FederatedIdentityCredential{
+		{
+			Name:        "azd-branch-" + branchName,
+			Issuer:      gitHubActionsIssuer,
+			Subject:     fmt.Sprintf("repo:%s:ref:refs/heads/%s", repoSlug, branch),
+			Description: &description,
+			Audiences:   []string{federatedTokenAudience},
+		},
+		{
+			Name:        "azd-pull-request",
+			Issuer:      gitHubActionsIssuer,
+			Subject:     fmt.Sprintf("repo:%s:pull_request", repoSlug),
+			Description: &description,
+			Audiences:   []string{federatedTokenAudience},
+		},
+	}
+}
+
+// The values the workflow signing in with federated credentials reads the service principal from
+var federatedIdentityVariables = []string{
+	"AZURE_CLIENT_ID",
+	environment.TenantIdEnvVarName,
+	environment.SubscriptionIdEnvVarName,
+}
+
+// The GitHub workflow signing in with the federated credentials of the service principal
+const gitHubFederatedWorkflowTemplate = `# Generated by azd pipeline config --auth-type federated
+# Signs in to Azure with the tokens of GitHub Actions, trusted by the federated credentials of the service principal.
+on:
+  workflow_dispatch:
+  push:
+    branches:
+      - main
+      - master
+  pull_request:
+    branches:
+      - main
+      - master
+
+permissions:
+  id-token: write
+  contents: read
+
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    container:
+      image: mcr.microsoft.com/azure-dev-cli-apps:latest
+    env:
+[[- range .Variables ]]
+      [[ . ]]: [[ github . ]]
+[[- end ]]
+[[- range .EnvironmentVariables ]]
+      [[ . ]]: [[ github . ]]
+[[- end ]]
+    steps:
+      - name: Checkout
+        uses: actions/checkout@v2
+
+      - name: Log in with Azure (Federated Credentials)
+        uses: azure/login@v1
+        with:
+          client-id: ${{ env.AZURE_CLIENT_ID }}
+          tenant-id: ${{ env.AZURE_TENANT_ID }}
+          subscription-id: ${{ env.AZURE_SUBSCRIPTION_ID }}
+
+      - name: Azure Dev Provision
+        run: azd provision --no-prompt
+
+      - name: Azure Dev Deploy
+        run: azd deploy --no-prompt
+`
+
+// gitHubFederatedWorkflow returns the GitHub workflow signing in with federated credentials
+func gitHubFederatedWorkflow() ([]byte, error) {
+	// The subscription is a variable of both the principal and the environment, it's only set once
+	environmentVariables := []string{}
+	for _, name := range managedIdentityVariables {
+		if name != environment.SubscriptionIdEnvVarName {
+			environmentVariables = append(environmentVariables, name)
+		}
+	}
+
+	data := struct {
+		Variables            []string
+		EnvironmentVariables []string
+	}{
+		Variables:            federatedIdentityVariables,
+		EnvironmentVariables: environmentVariables,
+	}
+
+	return executeDefinitionTemplate(
+		"github federated workflow", gitHubFederatedWorkflowTemplate, gitHubTemplateFuncs, data)
+}
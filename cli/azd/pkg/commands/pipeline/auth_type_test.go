@@ -0,0 +1,128 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_ParseAuthType(t *testing.T) {
+	authType, err := ParseAuthType("Federated")
+	require.NoError(t, err)
+	require.Equal(t, AuthTypeFederated, authType)
+
+	authType, err = ParseAuthType("")
+	require.NoError(t, err)
+	require.Equal(t, AuthType(""), authType)
+
+	_, err = ParseAuthType("password")
+	require.ErrorContains(t, err, "invalid auth type 'password'")
+}
+
+func Test_resolveAuthType(t *testing.T) {
+	tests := []struct {
+		name                 string
+		requested            AuthType
+		persisted            string
+		federatedUnsupported string
+		expected             AuthType
+		expectedErr          string
+	}{
+		{name: "DefaultFederated", expected: AuthTypeFederated},
+		{name: "DefaultUnsupported", federatedUnsupported: "a multi-stage pipeline", expected: AuthTypeClientSecret},
+		{name: "Persisted", persisted: "clientsecret", expected: AuthTypeClientSecret},
+		{
+			name:                 "PersistedUnsupported",
+			persisted:            "federated",
+			federatedUnsupported: "a pipeline rotating its credential",
+			expected:             AuthTypeClientSecret,
+		},
+		{name: "Requested", requested: AuthTypeFederated, persisted: "clientsecret", expected: AuthTypeFederated},
+		{
+			name:                 "RequestedUnsupported",
+			requested:            AuthTypeFederated,
+			federatedUnsupported: "a pipeline provisioning with Terraform",
+			expectedErr:          "a pipeline provisioning with Terraform can't sign in with federated credentials",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			authType, err := resolveAuthType(test.requested, test.persisted, test.federatedUnsupported)
+			if test.expectedErr != "" {
+				require.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expected, authType)
+		})
+	}
+}
+
+func Test_gitHubFederatedCredentials(t *testing.T) {
+	credentials := gitHubFederatedCredentials("owner/repo", "feature/login")
+	require.Len(t, credentials, 2)
+
+	require.Equal(t, "azd-branch-feature-login", credentials[0].Name)
+	require.Equal(t, "repo:owner/repo:ref:refs/heads/feature/login", credentials[0].Subject)
+	require.Equal(t, "https://token.actions.githubusercontent.com", credentials[0].Issuer)
+	require.Equal(t, []string{"api://AzureADTokenExchange"}, credentials[0].Audiences)
+
+	require.Equal(t, "azd-pull-request", credentials[1].Name)
+	require.Equal(t, "repo:owner/repo:pull_request", credentials[1].Subject)
+}
+
+func Test_gitHubFederatedWorkflow(t *testing.T) {
+	content, err := gitHubFederatedWorkflow()
+	require.NoError(t, err)
+
+	var workflow struct {
+		Permissions map[string]string `yaml:"permissions"`
+		Jobs        map[string]struct {
+			Env   map[string]string `yaml:"env"`
+			Steps []struct {
+				Uses string            `yaml:"uses"`
+				With map[string]string `yaml:"with"`
+			} `yaml:"steps"`
+		} `yaml:"jobs"`
+	}
+	require.NoError(t, yaml.Unmarshal(content, &workflow))
+
+	require.Equal(t, "write", workflow.Permissions["id-token"])
+
+	job := workflow.Jobs["build"]
+	require.Equal(t, map[string]string{
+		"AZURE_CLIENT_ID":       "${{ vars.AZURE_CLIENT_ID }}",
+		"AZURE_TENANT_ID":       "${{ vars.AZURE_TENANT_ID }}",
+		"AZURE_SUBSCRIPTION_ID": "${{ vars.AZURE_SUBSCRIPTION_ID }}",
+		"AZURE_ENV_NAME":        "${{ vars.AZURE_ENV_NAME }}",
+		"AZURE_LOCATION":        "${{ vars.AZURE_LOCATION }}",
+	}, job.Env)
+	require.Equal(t, "azure/login@v1", job.Steps[1].Uses)
+	require.Equal(t, "${{ env.AZURE_CLIENT_ID }}", job.Steps[1].With["client-id"])
+	require.NotContains(t, job.Steps[1].With, "creds")
+}
+
+func Test_Configure_rotateFederatedCredentials(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azdContext := &azdcontext.AzdContext{}
+	azdContext.SetProjectDirectory(t.TempDir())
+	manager := NewPipelineManager(azdContext, nil, PipelineManagerArgs{RotateCredentials: true})
+	manager.ScmProvider = &GitHubScmProvider{}
+	manager.CiProvider = &GitHubCiProvider{}
+	manager.Environment = environment.EphemeralWithValues("test", map[string]string{
+		authTypePersistedKey: string(AuthTypeFederated),
+	})
+
+	err := manager.Configure(*mockContext.Context)
+	require.ErrorContains(t, err, "the pipeline signs in with federated credentials, it has no credential to rotate")
+}
@@ -0,0 +1,121 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+)
+
+// The variables of the environment azd runs in, set in the steps of the Azure DevOps pipeline generated by azd
+var azdoPipelineVariables = []string{
+	environment.SubscriptionIdEnvVarName,
+	environment.EnvNameEnvVarName,
+	environment.LocationEnvVarName,
+}
+
+// The variables of the service principal Terraform signs in with, only set in the provision step
+var azdoTerraformVariables = []string{"ARM_TENANT_ID", "ARM_CLIENT_ID", "ARM_CLIENT_SECRET"}
+
+// azdoPipelineOptions are the options of the Azure DevOps pipeline generated by azd, instead of the definition of the
+// template
+type azdoPipelineOptions struct {
+	// The flags of azd pipeline config the pipeline is generated for
+	Flags []string
+	// The service connection the steps sign in with
+	ServiceConnection string
+	// The variable group holding the values of the environment, none when empty
+	VariableGroup string
+	// The provisioning provider of the project
+	ProvisioningProvider provisioning.Options
+}
+
+// the data of the template of the Azure DevOps pipeline generated by azd
+type azdoPipelineTemplateData struct {
+	Flags              string
+	ServiceConnection  string
+	VariableGroup      string
+	ProvisionVariables []string
+	DeployVariables    []string
+}
+
+// The Azure DevOps pipeline generated by azd, provisioning and deploying like the pipeline of the templates
+const azdoPipelineTemplate = `# Generated by azd pipeline config [[ .Flags ]]
+# Signs in with the service connection [[ .ServiceConnection ]] of the project.
+trigger:
+  - main
+  - master
+
+pool:
+  vmImage: ubuntu-latest
+
+container: mcr.microsoft.com/azure-dev-cli-apps:latest
+[[- if .VariableGroup ]]
+
+# The values of the azd environment, switch the group to deploy another environment
+variables:
+  - group: [[ .VariableGroup ]]
+[[- end ]]
+
+steps:
+  - task: AzureCLI@2
+    displayName: Azure Dev Provision
+    inputs:
+      azureSubscription: [[ .ServiceConnection ]]
+      scriptType: bash
+      scriptLocation: inlineScript
+      inlineScript: |
+        azd provision --no-prompt
+    env:
+[[- range .ProvisionVariables ]]
+      [[ . ]]: $([[ . ]])
+[[- end ]]
+
+  - task: AzureCLI@2
+    displayName: Azure Dev Deploy
+    inputs:
+      azureSubscription: [[ .ServiceConnection ]]
+      scriptType: bash
+      scriptLocation: inlineScript
+      inlineScript: |
+        azd deploy --no-prompt
+    env:
+[[- range .DeployVariables ]]
+      [[ . ]]: $([[ . ]])
+[[- end ]]
+`
+
+// azdoPipeline returns the Azure DevOps pipeline generated by azd with the options.
+// The definition uses [[ ]] as delimiters, the expressions of Azure DevOps use parentheses.
+func azdoPipeline(options azdoPipelineOptions) ([]byte, error) {
+	const name = "azure devops pipeline"
+	tmpl, err := template.New(name).Delims("[[", "]]").Parse(azdoPipelineTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s template: %w", name, err)
+	}
+
+	data := azdoPipelineTemplateData{
+		Flags:              strings.Join(options.Flags, " "),
+		ServiceConnection:  options.ServiceConnection,
+		VariableGroup:      options.VariableGroup,
+		ProvisionVariables: azdoPipelineVariables,
+		DeployVariables:    azdoPipelineVariables,
+	}
+
+	if options.ProvisioningProvider.Provider == provisioning.Terraform {
+		data.ProvisionVariables = append(append([]string{}, azdoPipelineVariables...), azdoTerraformVariables...)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("executing %s template: %w", name, err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,80 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_azdoPipeline(t *testing.T) {
+	type azdoPipelineDefinition struct {
+		Variables []struct {
+			Group string `yaml:"group"`
+		} `yaml:"variables"`
+		Steps []struct {
+			DisplayName string            `yaml:"displayName"`
+			Inputs      map[string]string `yaml:"inputs"`
+			Env         map[string]string `yaml:"env"`
+		} `yaml:"steps"`
+	}
+
+	environmentVariables := map[string]string{
+		"AZURE_SUBSCRIPTION_ID": "$(AZURE_SUBSCRIPTION_ID)",
+		"AZURE_ENV_NAME":        "$(AZURE_ENV_NAME)",
+		"AZURE_LOCATION":        "$(AZURE_LOCATION)",
+	}
+
+	t.Run("ExistingServiceConnection", func(t *testing.T) {
+		content, err := azdoPipeline(azdoPipelineOptions{
+			Flags:             []string{"--existing-service-connection"},
+			ServiceConnection: "governed-prod",
+		})
+		require.NoError(t, err)
+
+		var pipeline azdoPipelineDefinition
+		require.NoError(t, yaml.Unmarshal(content, &pipeline))
+
+		require.Empty(t, pipeline.Variables)
+		require.Len(t, pipeline.Steps, 2)
+		for _, step := range pipeline.Steps {
+			require.Equal(t, "governed-prod", step.Inputs["azureSubscription"])
+			require.Equal(t, environmentVariables, step.Env)
+		}
+		require.Equal(t, "azd deploy --no-prompt\n", pipeline.Steps[1].Inputs["inlineScript"])
+	})
+
+	t.Run("VariableGroup", func(t *testing.T) {
+		content, err := azdoPipeline(azdoPipelineOptions{
+			Flags:                []string{"--variable-group"},
+			ServiceConnection:    "azconnection",
+			VariableGroup:        "dev",
+			ProvisioningProvider: provisioning.Options{Provider: provisioning.Terraform},
+		})
+		require.NoError(t, err)
+		require.Contains(t, string(content), "# Generated by azd pipeline config --variable-group\n")
+
+		var pipeline azdoPipelineDefinition
+		require.NoError(t, yaml.Unmarshal(content, &pipeline))
+
+		require.Len(t, pipeline.Variables, 1)
+		require.Equal(t, "dev", pipeline.Variables[0].Group)
+		require.Len(t, pipeline.Steps, 2)
+
+		// Only the provision step signs Terraform in
+		provisionVariables := map[string]string{
+			"ARM_TENANT_ID":     "$(ARM_TENANT_ID)",
+			"ARM_CLIENT_ID":     "$(ARM_CLIENT_ID)",
+			"ARM_CLIENT_SECRET": "$(ARM_CLIENT_SECRET)",
+		}
+		for name, value := range environmentVariables {
+			provisionVariables[name] = value
+		}
+		require.Equal(t, provisionVariables, pipeline.Steps[0].Env)
+		require.Equal(t, environmentVariables, pipeline.Steps[1].Env)
+	})
+}
@@ -8,18 +8,23 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/fields"
 	"github.com/azure/azure-dev/cli/azd/pkg/azdo"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
-	"github.com/microsoft/azure-devops-go-api/azuredevops"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/build"
 	azdoGit "github.com/microsoft/azure-devops-go-api/azuredevops/git"
 )
@@ -27,10 +32,13 @@ import (
 // AzdoScmProvider implements ScmProvider using Azure DevOps as the provider
 // for source control manager.
 type AzdoScmProvider struct {
-	repoDetails    *AzdoRepositoryDetails
-	Env            *environment.Environment
-	AzdContext     *azdcontext.AzdContext
-	azdoConnection *azuredevops.Connection
+	repoDetails *AzdoRepositoryDetails
+	Env         *environment.Environment
+	AzdContext  *azdcontext.AzdContext
+	// The clients of the organization, created from the PAT of the environment when nil
+	azdoClients azdo.Clients
+	// The id of the project selected with --project-id, the project is selected by the user when empty
+	projectId string
 }
 
 // AzdoRepositoryDetails provides extra state needed for the AzDo provider.
@@ -44,6 +52,7 @@ type AzdoRepositoryDetails struct {
 	repoWebUrl      string
 	remoteUrl       string
 	sshUrl          string
+	branch          string
 	buildDefinition *build.BuildDefinition
 }
 
@@ -58,13 +67,17 @@ func (p *AzdoScmProvider) requiredTools(_ context.Context) []tools.ExternalTool
 // preConfigureCheck check the current state of external tools and any
 // other dependency to be as expected for execution.
 func (p *AzdoScmProvider) preConfigureCheck(ctx context.Context, console input.Console) error {
-	_, err := azdo.EnsurePatExists(ctx, p.Env, console)
+	pat, err := azdo.EnsurePatExists(ctx, p.Env, console)
 	if err != nil {
 		return err
 	}
 
-	_, err = azdo.EnsureOrgNameExists(ctx, p.Env, console)
-	return err
+	org, err := azdo.EnsureOrgNameExists(ctx, p.Env, console)
+	if err != nil {
+		return err
+	}
+
+	return azdo.ValidatePatScopes(ctx, org, pat)
 }
 
 // helper function to save configuration values to .env file
@@ -93,6 +106,10 @@ func (p *AzdoScmProvider) StoreRepoDetails(ctx context.Context, repo *azdoGit.Gi
 	repoDetails.sshUrl = *repo.SshUrl
 	repoDetails.repoId = repo.Id.String()
 
+	checkpoint(ctx, func(progress *progress) {
+		progress.AzdoRepositoryName = repoDetails.repoName
+	})
+
 	err := p.saveEnvironmentConfig(azdo.AzDoEnvironmentRepoIdName, p.repoDetails.repoId)
 	if err != nil {
 		return fmt.Errorf("error saving repo id to environment %w", err)
@@ -113,11 +130,90 @@ func (p *AzdoScmProvider) StoreRepoDetails(ctx context.Context, repo *azdoGit.Gi
 
 // prompts the user for a new AzDo Git repo and creates the repo
 func (p *AzdoScmProvider) createNewGitRepositoryFromInput(ctx context.Context, console input.Console) (string, error) {
-	connection, err := p.getAzdoConnection(ctx)
+	clients, err := p.getAzdoClients(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	repo, err := p.createGitRepositoryFromInput(ctx, console, clients)
+	if err != nil {
+		return "", err
+	}
+
+	err = p.StoreRepoDetails(ctx, repo)
+	if err != nil {
+		return "", err
+
+	}
+	return *repo.RemoteUrl, nil
+}
+
+// prompts the user for the url of an existing git repo and creates a new AzDo Git repo populated by importing it
+func (p *AzdoScmProvider) importGitRepositoryFromInput(
+	ctx context.Context,
+	repoPath string,
+	console input.Console,
+) (string, error) {
+	sourceUrl, err := console.Prompt(ctx, input.ConsoleOptions{
+		Message:      "Enter the url of the repository to import (it must be readable without credentials):",
+		DefaultValue: p.defaultImportSourceUrl(ctx, repoPath),
+	})
+	if err != nil {
+		return "", fmt.Errorf("asking for the repository to import: %w", err)
+	}
+
+	sourceUrl = strings.TrimSpace(sourceUrl)
+	if sourceUrl == "" {
+		return "", errors.New("the url of the repository to import is required")
+	}
+
+	clients, err := p.getAzdoClients(ctx)
 	if err != nil {
 		return "", err
 	}
 
+	repo, err := p.createGitRepositoryFromInput(ctx, console, clients)
+	if err != nil {
+		return "", err
+	}
+
+	console.Message(ctx, fmt.Sprintf("Importing %s into %s, this may take a few minutes...", sourceUrl, *repo.Name))
+	err = azdo.ImportRepository(ctx, p.repoDetails.projectId, repo.Id.String(), sourceUrl, clients)
+	if err != nil {
+		return "", err
+	}
+
+	err = p.StoreRepoDetails(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+	return *repo.RemoteUrl, nil
+}
+
+// returns the url of the origin remote of the local repo when it's not an AzDo repo, ssh urls are converted to https
+// urls as repositories are only imported over https
+func (p *AzdoScmProvider) defaultImportSourceUrl(ctx context.Context, repoPath string) string {
+	remoteUrl, err := git.NewGitCli(ctx).GetRemoteUrl(ctx, repoPath, "origin")
+	if err != nil || isAzDoRemote(remoteUrl) == nil {
+		return ""
+	}
+
+	if captures := sshRemoteUrlRegex.FindStringSubmatch(remoteUrl); captures != nil {
+		return fmt.Sprintf("https://%s/%s", captures[1], captures[2])
+	}
+
+	return remoteUrl
+}
+
+// defines the structure of an ssh git remote of any host, i.e. git@github.com:owner/repo.git
+var sshRemoteUrlRegex = regexp.MustCompile(`^[^@/]+@([^:/]+):(.+)$`)
+
+// prompts the user for the name of a new AzDo Git repo and creates the repo
+func (p *AzdoScmProvider) createGitRepositoryFromInput(
+	ctx context.Context,
+	console input.Console,
+	clients azdo.Clients,
+) (*azdoGit.GitRepository, error) {
 	var repo *azdoGit.GitRepository
 	for {
 		name, err := console.Prompt(ctx, input.ConsoleOptions{
@@ -125,11 +221,11 @@ func (p *AzdoScmProvider) createNewGitRepositoryFromInput(ctx context.Context, c
 			DefaultValue: p.repoDetails.projectName,
 		})
 		if err != nil {
-			return "", fmt.Errorf("asking for new project name: %w", err)
+			return nil, fmt.Errorf("asking for new project name: %w", err)
 		}
 
 		var message string
-		newRepo, err := azdo.CreateRepository(ctx, p.repoDetails.projectId, name, connection)
+		newRepo, err := azdo.CreateRepository(ctx, p.repoDetails.projectId, name, clients)
 		if err != nil {
 			message = err.Error()
 		}
@@ -142,19 +238,22 @@ func (p *AzdoScmProvider) createNewGitRepositoryFromInput(ctx context.Context, c
 					"See https://aka.ms/azure-dev/azdo-repo-naming\n", name))
 			continue // try again
 		} else if err != nil {
-			return "", fmt.Errorf("creating repository: %w", err)
+			return nil, fmt.Errorf("creating repository: %w", err)
 		} else {
 			repo = newRepo
 			break
 		}
 	}
 
-	err = p.StoreRepoDetails(ctx, repo)
-	if err != nil {
-		return "", err
+	recordCreated(ctx, createdResource{
+		Kind:         resourceAzdoRepository,
+		Name:         *repo.Name,
+		Id:           repo.Id.String(),
+		Organization: p.repoDetails.orgName,
+		ProjectId:    p.repoDetails.projectId,
+	})
 
-	}
-	return *repo.RemoteUrl, nil
+	return repo, nil
 }
 
 // verifies that a repo exists or prompts the user to select from a list of existing AzDo repos
@@ -163,12 +262,12 @@ func (p *AzdoScmProvider) ensureGitRepositoryExists(ctx context.Context, console
 		return p.repoDetails.remoteUrl, nil
 	}
 
-	connection, err := p.getAzdoConnection(ctx)
+	clients, err := p.getAzdoClients(ctx)
 	if err != nil {
 		return "", err
 	}
 
-	repo, err := azdo.GetGitRepositoriesInProject(ctx, p.repoDetails.projectName, p.repoDetails.orgName, connection, console)
+	repo, err := azdo.GetGitRepositoriesInProject(ctx, p.repoDetails.projectName, p.repoDetails.orgName, clients, console)
 	if err != nil {
 		return "", err
 	}
@@ -191,32 +290,33 @@ func (p *AzdoScmProvider) getRepoDetails() *AzdoRepositoryDetails {
 	return p.repoDetails
 }
 
-// helper function to return an azuredevops.Connection for use with AzDo Go SDK
-func (p *AzdoScmProvider) getAzdoConnection(ctx context.Context) (*azuredevops.Connection, error) {
+// helper function to return the clients of the AzDo Go SDK
+func (p *AzdoScmProvider) getAzdoClients(ctx context.Context) (azdo.Clients, error) {
 	console := input.GetConsole(ctx)
-	if p.azdoConnection != nil {
-		return p.azdoConnection, nil
+	if p.azdoClients != nil {
+		return p.azdoClients, nil
 	}
 
-	org, err := azdo.EnsureOrgNameExists(ctx, p.Env, console)
+	// The PAT is ensured first, it's used to list the organizations to select from
+	pat, err := azdo.EnsurePatExists(ctx, p.Env, console)
 	if err != nil {
 		return nil, err
 	}
 
-	repoDetails := p.getRepoDetails()
-	repoDetails.orgName = org
-
-	pat, err := azdo.EnsurePatExists(ctx, p.Env, console)
+	org, err := azdo.EnsureOrgNameExists(ctx, p.Env, console)
 	if err != nil {
 		return nil, err
 	}
 
-	connection, err := azdo.GetConnection(ctx, org, pat)
+	repoDetails := p.getRepoDetails()
+	repoDetails.orgName = org
+
+	clients, err := azdo.GetClients(ctx, org, pat)
 	if err != nil {
 		return nil, err
 	}
 
-	return connection, nil
+	return clients, nil
 }
 
 // returns an existing project or prompts the user to either select a project or a create a new AzDo project
@@ -224,6 +324,29 @@ func (p *AzdoScmProvider) ensureProjectExists(ctx context.Context, console input
 	if p.repoDetails != nil && p.repoDetails.projectName != "" {
 		return p.repoDetails.projectName, p.repoDetails.projectId, false, nil
 	}
+
+	if p.projectId != "" {
+		clients, err := p.getAzdoClients(ctx)
+		if err != nil {
+			return "", "", false, err
+		}
+
+		project, err := azdo.GetProjectById(ctx, clients, p.projectId)
+		if err != nil {
+			return "", "", false, err
+		}
+
+		console.Message(ctx, fmt.Sprintf("Using Azure DevOps project %s", *project.Name))
+		return *project.Name, project.Id.String(), false, nil
+	}
+
+	if resumed := resumedProgress(ctx); resumed != nil && resumed.AzdoProjectId != "" {
+		console.Message(ctx, fmt.Sprintf("Using Azure DevOps project %s from the previous configuration", resumed.AzdoProjectName))
+		created := resumedResource(ctx, resourceAzdoProject)
+		newProject := created != nil && created.Id == resumed.AzdoProjectId
+		return resumed.AzdoProjectName, resumed.AzdoProjectId, newProject, nil
+	}
+
 	idx, err := console.Select(ctx, input.ConsoleOptions{
 		Message: "How would you like to configure your project?",
 		Options: []string{
@@ -236,7 +359,7 @@ func (p *AzdoScmProvider) ensureProjectExists(ctx context.Context, console input
 		return "", "", false, fmt.Errorf("prompting for azdo project type: %w", err)
 	}
 
-	connection, err := p.getAzdoConnection(ctx)
+	clients, err := p.getAzdoClients(ctx)
 	if err != nil {
 		return "", "", false, err
 	}
@@ -247,7 +370,7 @@ func (p *AzdoScmProvider) ensureProjectExists(ctx context.Context, console input
 	switch idx {
 	// Select from an existing AzDo project
 	case 0:
-		projectName, projectId, err = azdo.GetProjectFromExisting(ctx, connection, console)
+		projectName, projectId, err = azdo.GetProjectFromExisting(ctx, clients, console)
 		if err != nil {
 			return "", "", false, err
 		}
@@ -256,7 +379,7 @@ func (p *AzdoScmProvider) ensureProjectExists(ctx context.Context, console input
 		projectName, projectId, err = azdo.GetProjectFromNew(
 			ctx,
 			p.AzdContext.ProjectDirectory(),
-			connection,
+			clients,
 			p.Env,
 			console,
 		)
@@ -264,9 +387,22 @@ func (p *AzdoScmProvider) ensureProjectExists(ctx context.Context, console input
 		if err != nil {
 			return "", "", false, err
 		}
+
+		recordCreated(ctx, createdResource{
+			Kind:         resourceAzdoProject,
+			Name:         projectName,
+			Id:           projectId,
+			Organization: p.repoDetails.orgName,
+		})
 	default:
 		panic(fmt.Sprintf("unexpected selection index %d", idx))
 	}
+
+	checkpoint(ctx, func(progress *progress) {
+		progress.AzdoProjectName = projectName
+		progress.AzdoProjectId = projectId
+	})
+
 	return projectName, projectId, newProject, nil
 }
 
@@ -281,6 +417,7 @@ func (p *AzdoScmProvider) configureGitRemote(
 	if err != nil {
 		return "", err
 	}
+	setConfigAttributes(ctx, fields.PipelineProjectCreatedKey.Bool(newProject))
 
 	repoDetails := p.getRepoDetails()
 	repoDetails.projectName = projectName
@@ -298,7 +435,7 @@ func (p *AzdoScmProvider) configureGitRemote(
 	var remoteUrl string
 
 	if !newProject {
-		remoteUrl, err = p.promptForAzdoRepository(ctx, console)
+		remoteUrl, err = p.promptForAzdoRepository(ctx, repoPath, console)
 		if err != nil {
 			return "", err
 		}
@@ -307,14 +444,10 @@ func (p *AzdoScmProvider) configureGitRemote(
 		if err != nil {
 			return "", err
 		}
+		// new projects come with a newly created default repository
+		setConfigAttributes(ctx, fields.PipelineRepositoryCreatedKey.Bool(true))
 	}
 
-	branch, err := p.getCurrentGitBranch(ctx, repoPath)
-	if err != nil {
-		return "", err
-	}
-	azdo.DefaultBranch = branch
-
 	return remoteUrl, nil
 }
 
@@ -327,6 +460,49 @@ func (p *AzdoScmProvider) getCurrentGitBranch(ctx context.Context, repoPath stri
 	return branch, nil
 }
 
+// resolveBranch sets the branch the pipeline and the build policy target: the branch of --branch, else the default
+// branch of the repository, else the current branch, which becomes the default branch of an empty repository once
+// pushed
+func (p *AzdoScmProvider) resolveBranch(
+	ctx context.Context,
+	repoDetails *gitRepositoryDetails,
+	branch string,
+	console input.Console,
+) error {
+	details := repoDetails.details.(*AzdoRepositoryDetails)
+
+	if branch == "" {
+		clients, err := p.getAzdoClients(ctx)
+		if err != nil {
+			return err
+		}
+
+		branch, err = azdo.GetRepositoryDefaultBranch(ctx, details.projectName, details.repoName, clients)
+		if err != nil {
+			return fmt.Errorf("getting the default branch of repository %s: %w", details.repoName, err)
+		}
+	}
+
+	if branch == "" {
+		currentBranch, err := p.getCurrentGitBranch(ctx, repoDetails.gitProjectPath)
+		if err != nil {
+			return fmt.Errorf("getting current branch: %w", err)
+		}
+
+		branch = currentBranch
+	}
+
+	// In a detached HEAD, there's no current branch
+	if branch == "" {
+		return errors.New("the branch of the pipeline can't be detected, set it with --branch")
+	}
+
+	console.Message(ctx, fmt.Sprintf("The pipeline and its build policy target the branch %s.\n",
+		output.WithHighLightFormat(branch)))
+	details.branch = branch
+	return nil
+}
+
 // returns the git remote for a newly created repo that is part of a newly created AzDo project
 func (p *AzdoScmProvider) getDefaultRepoRemote(
 	ctx context.Context,
@@ -334,11 +510,11 @@ func (p *AzdoScmProvider) getDefaultRepoRemote(
 	projectId string,
 	console input.Console,
 ) (string, error) {
-	connection, err := p.getAzdoConnection(ctx)
+	clients, err := p.getAzdoClients(ctx)
 	if err != nil {
 		return "", err
 	}
-	repo, err := azdo.GetDefaultGitRepositoriesInProject(ctx, projectName, connection)
+	repo, err := azdo.GetDefaultGitRepositoriesInProject(ctx, projectName, clients)
 	if err != nil {
 		return "", err
 	}
@@ -355,7 +531,16 @@ func (p *AzdoScmProvider) getDefaultRepoRemote(
 }
 
 // prompt the user to select azdo repo or create a new one
-func (p *AzdoScmProvider) promptForAzdoRepository(ctx context.Context, console input.Console) (string, error) {
+func (p *AzdoScmProvider) promptForAzdoRepository(
+	ctx context.Context,
+	repoPath string,
+	console input.Console,
+) (string, error) {
+	if resumed := resumedProgress(ctx); resumed != nil && resumed.AzdoRepositoryName != "" &&
+		resumed.AzdoProjectId == p.repoDetails.projectId {
+		return p.getResumedRepoRemote(ctx, resumed.AzdoRepositoryName, console)
+	}
+
 	var remoteUrl string
 	// There are a few ways to configure the remote so offer a choice to the user.
 	idx, err := console.Select(ctx, input.ConsoleOptions{
@@ -363,6 +548,7 @@ func (p *AzdoScmProvider) promptForAzdoRepository(ctx context.Context, console i
 		Options: []string{
 			"Select an existing Azure DevOps Repository",
 			"Create a new private Azure DevOps Repository",
+			"Import an existing Git repository into a new private Azure DevOps Repository",
 		},
 		DefaultValue: "Create a new private Azure DevOps Repository",
 	})
@@ -378,6 +564,7 @@ func (p *AzdoScmProvider) promptForAzdoRepository(ctx context.Context, console i
 		if err != nil {
 			return "", err
 		}
+		setConfigAttributes(ctx, fields.PipelineRepositoryCreatedKey.Bool(false))
 
 	// Create a new project
 	case 1:
@@ -385,6 +572,15 @@ func (p *AzdoScmProvider) promptForAzdoRepository(ctx context.Context, console i
 		if err != nil {
 			return "", err
 		}
+		setConfigAttributes(ctx, fields.PipelineRepositoryCreatedKey.Bool(true))
+
+	// Create a new repository populated from an existing one, so only the local changes are pushed
+	case 2:
+		remoteUrl, err = p.importGitRepositoryFromInput(ctx, repoPath, console)
+		if err != nil {
+			return "", err
+		}
+		setConfigAttributes(ctx, fields.PipelineRepositoryCreatedKey.Bool(true))
 
 	default:
 		panic(fmt.Sprintf("unexpected selection index %d", idx))
@@ -393,6 +589,35 @@ func (p *AzdoScmProvider) promptForAzdoRepository(ctx context.Context, console i
 	return remoteUrl, nil
 }
 
+// returns the remote of the repository selected or created by the configuration being resumed
+func (p *AzdoScmProvider) getResumedRepoRemote(
+	ctx context.Context,
+	repoName string,
+	console input.Console,
+) (string, error) {
+	console.Message(ctx, fmt.Sprintf("Using Azure DevOps repository %s from the previous configuration", repoName))
+
+	clients, err := p.getAzdoClients(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	repo, err := azdo.GetGitRepository(ctx, p.repoDetails.projectName, repoName, clients)
+	if err != nil {
+		return "", fmt.Errorf("getting repository %s: %w", repoName, err)
+	}
+
+	err = p.StoreRepoDetails(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+
+	created := resumedResource(ctx, resourceAzdoRepository)
+	setConfigAttributes(ctx, fields.PipelineRepositoryCreatedKey.Bool(created != nil && created.Name == repoName))
+
+	return *repo.RemoteUrl, nil
+}
+
 // defines the structure of an ssl git remote
 var azdoRemoteGitUrlRegex = regexp.MustCompile(`^git@ssh.dev.azure\.com:(.*?)(?:\.git)?$`)
 
@@ -447,6 +672,9 @@ func (p *AzdoScmProvider) gitRepoDetails(ctx context.Context, remoteUrl string)
 	if repoDetails.projectName == "" {
 		repoDetails.projectName = p.Env.Values[azdo.AzDoEnvironmentProjectName]
 	}
+	if repoDetails.projectId == "" {
+		repoDetails.projectId = p.projectId
+	}
 	if repoDetails.projectId == "" {
 		repoDetails.projectId = p.Env.Values[azdo.AzDoEnvironmentProjectIdName]
 	}
@@ -477,12 +705,12 @@ func (p *AzdoScmProvider) gitRepoDetails(ctx context.Context, remoteUrl string)
 		repoDetails.repoName = parts[1]
 		p.Env.Values[azdo.AzDoEnvironmentRepoName] = repoDetails.repoName
 
-		connection, err := p.getAzdoConnection(ctx)
+		clients, err := p.getAzdoClients(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("Getting azdo connection: %w", err)
 		}
 
-		repo, err := azdo.GetGitRepository(ctx, repoDetails.projectName, repoDetails.repoName, connection)
+		repo, err := azdo.GetGitRepository(ctx, repoDetails.projectName, repoDetails.repoName, clients)
 		if err != nil {
 			return nil, fmt.Errorf("Looking for repository: %w", err)
 		}
@@ -491,11 +719,13 @@ func (p *AzdoScmProvider) gitRepoDetails(ctx context.Context, remoteUrl string)
 		repoDetails.repoWebUrl = *repo.WebUrl
 		p.Env.Values[azdo.AzDoEnvironmentRepoWebUrl] = repoDetails.repoWebUrl
 
-		proj, err := azdo.GetProjectByName(ctx, connection, repoDetails.projectName)
-		if err != nil {
-			return nil, fmt.Errorf("Looking for project: %w", err)
+		if repoDetails.projectId == "" {
+			proj, err := azdo.GetProjectByName(ctx, clients, repoDetails.projectName)
+			if err != nil {
+				return nil, fmt.Errorf("Looking for project: %w", err)
+			}
+			repoDetails.projectId = proj.Id.String()
 		}
-		repoDetails.projectId = proj.Id.String()
 		p.Env.Values[azdo.AzDoEnvironmentProjectIdName] = repoDetails.projectId
 
 		_ = p.Env.Save() // best effort to persist in the env
@@ -518,7 +748,7 @@ func (p *AzdoScmProvider) preventGitPush(
 	return false, nil
 }
 
-// hook function that fires after a git push
+// hook function that fires after a git push, or after the configuration when the push is skipped
 // allows the provider to perform certain tasks after push including
 // cleanup on the remote url, creating the build policy for PRs and queuing an initial deployment
 func (p *AzdoScmProvider) postGitPush(
@@ -532,16 +762,17 @@ func (p *AzdoScmProvider) postGitPush(
 		console.Message(ctx, output.WithSuccessFormat(azdo.AzdoConfigSuccessMessage, p.repoDetails.repoWebUrl))
 	}
 
-	connection, err := p.getAzdoConnection(ctx)
+	clients, err := p.getAzdoClients(ctx)
 	if err != nil {
 		return err
 	}
 
 	err = azdo.CreateBuildPolicy(
 		ctx,
-		connection,
+		clients,
 		p.repoDetails.projectId,
 		p.repoDetails.repoId,
+		p.repoDetails.branch,
 		p.repoDetails.buildDefinition,
 		p.Env,
 	)
@@ -549,11 +780,23 @@ func (p *AzdoScmProvider) postGitPush(
 		return err
 	}
 
-	err = azdo.QueueBuild(ctx, connection, p.repoDetails.projectId, p.repoDetails.buildDefinition)
+	// Without a push, the pipeline may not be in the repo yet, it runs on the next push
+	if !gitRepo.pushStatus {
+		return nil
+	}
+
+	// The first run deploys the pushed branch, the pipeline may not be in the branch it targets yet
+	err = azdo.QueueBuild(ctx, clients, p.repoDetails.projectId, p.repoDetails.buildDefinition, branchName)
 	if err != nil {
 		return err
 	}
 
+	if branchName != p.repoDetails.branch {
+		console.Message(ctx, fmt.Sprintf(
+			"The changes were pushed to the branch %s, open a pull request to %s to add the pipeline to it.\n",
+			branchName, p.repoDetails.branch))
+	}
+
 	return nil
 }
 
@@ -562,6 +805,18 @@ type AzdoCiProvider struct {
 	Env         *environment.Environment
 	AzdContext  *azdcontext.AzdContext
 	credentials *azdo.AzureServicePrincipalCredentials
+	// the credentials of the stages of a multi-stage pipeline, by stage name
+	stageCredentials map[string]*azdo.AzureServicePrincipalCredentials
+	// whether the environment name and location of the pipeline can be overridden when queuing a run
+	allowOverride bool
+	// The clients of the organization, created from the PAT of the environment when nil
+	azdoClients azdo.Clients
+	// the existing service connection the pipeline signs in with, empty when it signs in with the connection created
+	// by azd
+	existingServiceConnection string
+	// whether the values of the environment are saved in a variable group named after it, linked to the pipeline,
+	// instead of variables of the pipeline
+	variableGroup bool
 }
 
 // ***  subareaProvider implementation ******
@@ -605,19 +860,11 @@ func (p *AzdoCiProvider) configureConnection(
 
 	p.credentials = azureCredentials
 	details := repoDetails.details.(*AzdoRepositoryDetails)
-	org, err := azdo.EnsureOrgNameExists(ctx, p.Env, console)
-	if err != nil {
-		return err
-	}
-	pat, err := azdo.EnsurePatExists(ctx, p.Env, console)
-	if err != nil {
-		return err
-	}
-	connection, err := azdo.GetConnection(ctx, org, pat)
+	clients, err := p.getClients(ctx, console)
 	if err != nil {
 		return err
 	}
-	err = azdo.CreateServiceConnection(ctx, connection, details.projectId, *p.Env, *p.credentials, console)
+	err = azdo.CreateServiceConnection(ctx, clients, details.projectId, *p.Env, *p.credentials, console)
 	if err != nil {
 		return err
 	}
@@ -642,28 +889,354 @@ func (p *AzdoCiProvider) configurePipeline(
 	details := repoDetails.details.(*AzdoRepositoryDetails)
 	console := input.GetConsole(ctx)
 
-	org, err := azdo.EnsureOrgNameExists(ctx, p.Env, console)
+	clients, err := p.getClients(ctx, console)
 	if err != nil {
 		return err
 	}
-	pat, err := azdo.EnsurePatExists(ctx, p.Env, console)
+	var buildDefinition *build.BuildDefinition
+	if p.variableGroup {
+		buildDefinition, err = azdo.CreateVariableGroupPipeline(
+			ctx,
+			details.projectId,
+			azdo.AzurePipelineName,
+			details.repoName,
+			details.branch,
+			clients,
+			*p.credentials,
+			p.serviceConnectionName(),
+			p.Env,
+			provisioningProvider,
+		)
+	} else {
+		buildDefinition, err = azdo.CreatePipeline(
+			ctx,
+			details.projectId,
+			azdo.AzurePipelineName,
+			details.repoName,
+			details.branch,
+			clients,
+			*p.credentials,
+			p.serviceConnectionName(),
+			p.Env,
+			console,
+			provisioningProvider,
+			p.allowOverride,
+		)
+	}
+	if err != nil {
+		return err
+	}
+	details.buildDefinition = buildDefinition
+	return nil
+}
+
+// setPipelineVariable sets the value as a variable of the Azdo pipeline
+func (p *AzdoCiProvider) setPipelineVariable(
+	ctx context.Context,
+	repoDetails *gitRepositoryDetails,
+	name string,
+	value string,
+) error {
+	details := repoDetails.details.(*AzdoRepositoryDetails)
+	console := input.GetConsole(ctx)
+
+	clients, err := p.getClients(ctx, console)
+	if err != nil {
+		return err
+	}
+
+	return azdo.SetPipelineVariable(ctx, clients, details.projectId, details.repoName, name, value)
+}
+
+// configureStageConnection creates or updates the service connection of the stage with its credential
+func (p *AzdoCiProvider) configureStageConnection(
+	ctx context.Context,
+	repoDetails *gitRepositoryDetails,
+	provisioningProvider provisioning.Options,
+	stage *pipelineStage,
+	console input.Console) error {
+
+	azureCredentials, err := parseCredentials(ctx, stage.credentials)
+	if err != nil {
+		return err
+	}
+
+	if p.stageCredentials == nil {
+		p.stageCredentials = map[string]*azdo.AzureServicePrincipalCredentials{}
+	}
+	p.stageCredentials[stage.name] = azureCredentials
+
+	details := repoDetails.details.(*AzdoRepositoryDetails)
+	clients, err := p.getClients(ctx, console)
 	if err != nil {
 		return err
 	}
-	connection, err := azdo.GetConnection(ctx, org, pat)
+
+	return azdo.CreateStageServiceConnection(ctx, clients, details.projectId, stage.name, *azureCredentials, console)
+}
+
+// stagesDefinition returns the Azure DevOps pipeline deploying the stages in order
+func (p *AzdoCiProvider) stagesDefinition(
+	stages []*pipelineStage,
+	provisioningProvider provisioning.Options,
+) (string, []byte, error) {
+	pipeline, err := azdoStagesPipeline(stages, provisioningProvider)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return filepath.FromSlash(azdo.AzurePipelineYamlPath), pipeline, nil
+}
+
+// configureStagesPipeline creates the Azdo pipeline with the variables of each stage
+func (p *AzdoCiProvider) configureStagesPipeline(
+	ctx context.Context,
+	repoDetails *gitRepositoryDetails,
+	provisioningProvider provisioning.Options,
+	stages []*pipelineStage,
+) error {
+	details := repoDetails.details.(*AzdoRepositoryDetails)
+	clients, err := p.getClients(ctx, input.GetConsole(ctx))
 	if err != nil {
 		return err
 	}
-	buildDefinition, err := azdo.CreatePipeline(
+
+	pipelineStages := make([]azdo.PipelineStage, len(stages))
+	for index, stage := range stages {
+		credentials, has := p.stageCredentials[stage.name]
+		if !has {
+			return fmt.Errorf("the connection of pipeline stage '%s' isn't configured", stage.name)
+		}
+
+		pipelineStages[index] = azdo.PipelineStage{
+			Name:        stage.name,
+			Environment: stage.env,
+			Credentials: *credentials,
+		}
+	}
+
+	buildDefinition, err := azdo.CreateStagesPipeline(
 		ctx,
 		details.projectId,
 		azdo.AzurePipelineName,
 		details.repoName,
-		connection,
+		details.branch,
+		clients,
+		pipelineStages,
+		provisioningProvider,
+		p.allowOverride,
+	)
+	if err != nil {
+		return err
+	}
+	details.buildDefinition = buildDefinition
+	return nil
+}
+
+// rotationDefinition returns the Azure DevOps pipeline rotating the credential on the schedule
+func (p *AzdoCiProvider) rotationDefinition(schedule string, principalName string) (string, []byte, error) {
+	pipeline, err := azdoRotationPipeline(schedule, principalName)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return filepath.FromSlash(azdo.AzureRotationPipelineYamlPath), pipeline, nil
+}
+
+// configureRotationPipeline creates the Azdo pipeline rotating the credential, the PAT it runs with is a secret
+// variable added by the user
+func (p *AzdoCiProvider) configureRotationPipeline(ctx context.Context, repoDetails *gitRepositoryDetails) error {
+	details := repoDetails.details.(*AzdoRepositoryDetails)
+	console := input.GetConsole(ctx)
+	clients, err := p.getClients(ctx, console)
+	if err != nil {
+		return err
+	}
+
+	_, err = azdo.CreateRotationPipeline(
+		ctx, details.projectId, details.repoName, details.branch, clients, p.Env, details.orgName, p.allowOverride)
+	if err != nil {
+		return err
+	}
+
+	console.Message(ctx, fmt.Sprintf(
+		"Add the %s secret variable to the pipeline %s, with a personal access token allowed to manage the service "+
+			"connections and the pipelines of the project, for the pipeline to save the rotated credential.\n",
+		output.WithHighLightFormat("%s", azdo.AzDoPatName), azdo.AzureRotationPipelineName))
+
+	return nil
+}
+
+// cleanupDefinition returns the Azure DevOps pipeline deleting the expired environments on a schedule, signing in
+// with the service connection whatever the auth type
+func (p *AzdoCiProvider) cleanupDefinition(authType AuthType) (string, []byte, error) {
+	pipeline, err := azdoCleanupPipeline()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return filepath.FromSlash(azdo.AzureCleanupPipelineYamlPath), pipeline, nil
+}
+
+// configureCleanupPipeline creates the Azdo pipeline deleting the expired environments
+func (p *AzdoCiProvider) configureCleanupPipeline(ctx context.Context, repoDetails *gitRepositoryDetails) error {
+	details := repoDetails.details.(*AzdoRepositoryDetails)
+	clients, err := p.getClients(ctx, input.GetConsole(ctx))
+	if err != nil {
+		return err
+	}
+
+	_, err = azdo.CreateCleanupPipeline(ctx, details.projectId, details.repoName, details.branch, clients)
+	return err
+}
+
+// serviceConnectionName returns the name of the service connection the pipeline signs in with
+func (p *AzdoCiProvider) serviceConnectionName() string {
+	if p.existingServiceConnection != "" {
+		return p.existingServiceConnection
+	}
+
+	return azdo.ServiceConnectionName
+}
+
+// selectExistingConnection selects the existing service connection of the project the pipeline signs in with, the
+// pipeline deploys to the subscription of the environment
+func (p *AzdoCiProvider) selectExistingConnection(
+	ctx context.Context,
+	repoDetails *gitRepositoryDetails,
+	console input.Console,
+) error {
+	details := repoDetails.details.(*AzdoRepositoryDetails)
+	clients, err := p.getClients(ctx, console)
+	if err != nil {
+		return err
+	}
+
+	name, err := azdo.SelectServiceConnection(ctx, clients, details.projectId, p.Env, console)
+	if err != nil {
+		return err
+	}
+
+	console.Message(ctx, fmt.Sprintf("Using the existing service connection %s.\n", output.WithHighLightFormat(name)))
+	p.existingServiceConnection = name
+	p.credentials = &azdo.AzureServicePrincipalCredentials{SubscriptionId: p.Env.GetSubscriptionId()}
+	return nil
+}
+
+// pipelineDefinition returns the Azure DevOps pipeline generated by azd, signing in with the existing service
+// connection or reading the values of the environment from its variable group
+func (p *AzdoCiProvider) pipelineDefinition(provisioningProvider provisioning.Options) (string, []byte, error) {
+	options := azdoPipelineOptions{
+		ServiceConnection:    p.serviceConnectionName(),
+		ProvisioningProvider: provisioningProvider,
+	}
+
+	if p.existingServiceConnection != "" {
+		options.Flags = append(options.Flags, "--existing-service-connection")
+	}
+
+	if p.variableGroup {
+		options.Flags = append(options.Flags, "--variable-group")
+		options.VariableGroup = azdo.VariableGroupName(p.Env.GetEnvName())
+	}
+
+	pipeline, err := azdoPipeline(options)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return filepath.FromSlash(azdo.AzurePipelineYamlPath), pipeline, nil
+}
+
+// managedIdentityDefinition returns the Azure DevOps pipeline signing in with the managed identity of the agent
+func (p *AzdoCiProvider) managedIdentityDefinition() (string, []byte, error) {
+	pipeline, err := azdoManagedIdentityPipeline()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return filepath.FromSlash(azdo.AzurePipelineYamlPath), pipeline, nil
+}
+
+// configureManagedIdentityConnection is a no-op for Azdo, the agent signs in without a service connection and the
+// client id is set in the variables of the pipeline by configureManagedIdentityPipeline
+func (p *AzdoCiProvider) configureManagedIdentityConnection(
+	ctx context.Context,
+	azdEnvironment *environment.Environment,
+	repoDetails *gitRepositoryDetails,
+	clientId string,
+	console input.Console) error {
+	return nil
+}
+
+// configureManagedIdentityPipeline creates the Azdo pipeline with the client id of the managed identity
+func (p *AzdoCiProvider) configureManagedIdentityPipeline(
+	ctx context.Context,
+	repoDetails *gitRepositoryDetails,
+	clientId string,
+) error {
+	details := repoDetails.details.(*AzdoRepositoryDetails)
+	clients, err := p.getClients(ctx, input.GetConsole(ctx))
+	if err != nil {
+		return err
+	}
+
+	buildDefinition, err := azdo.CreateManagedIdentityPipeline(
+		ctx, details.projectId, details.repoName, details.branch, clients, p.Env, clientId, p.allowOverride)
+	if err != nil {
+		return err
+	}
+	details.buildDefinition = buildDefinition
+	return nil
+}
+
+// federatedDefinition returns an empty path, the Azdo pipeline signs in with the service connection whatever its
+// auth type
+func (p *AzdoCiProvider) federatedDefinition() (string, []byte, error) {
+	return "", nil, nil
+}
+
+// partitionDefinition returns the Azure DevOps pipeline of the partition
+func (p *AzdoCiProvider) partitionDefinition(
+	partition *pipelinePartition,
+	provisioningProvider provisioning.Options,
+	authType AuthType,
+) (string, []byte, error) {
+	pipeline, err := azdoPartitionPipeline(partition, provisioningProvider, authType)
+	if err != nil {
+		return "", nil, err
+	}
+
+	_, yamlPath := azdoPartitionPipelineName(partition)
+	return filepath.FromSlash(yamlPath), pipeline, nil
+}
+
+// configurePartitionPipeline creates the Azdo pipeline of the partition, with the same variables as configurePipeline
+func (p *AzdoCiProvider) configurePartitionPipeline(
+	ctx context.Context,
+	repoDetails *gitRepositoryDetails,
+	provisioningProvider provisioning.Options,
+	partition *pipelinePartition,
+) error {
+	details := repoDetails.details.(*AzdoRepositoryDetails)
+	clients, err := p.getClients(ctx, input.GetConsole(ctx))
+	if err != nil {
+		return err
+	}
+
+	name, yamlPath := azdoPartitionPipelineName(partition)
+	buildDefinition, err := azdo.CreatePartitionPipeline(
+		ctx,
+		details.projectId,
+		name,
+		yamlPath,
+		details.repoName,
+		details.branch,
+		clients,
 		*p.credentials,
 		p.Env,
-		console,
 		provisioningProvider,
+		p.allowOverride,
 	)
 	if err != nil {
 		return err
@@ -671,3 +1244,114 @@ func (p *AzdoCiProvider) configurePipeline(
 	details.buildDefinition = buildDefinition
 	return nil
 }
+
+// azdoPartitionPipelineName returns the name and the yaml path of the Azdo pipeline of the partition
+func azdoPartitionPipelineName(partition *pipelinePartition) (string, string) {
+	if partition.name == partitionInfra {
+		return azdo.AzureInfraPipelineName, azdo.AzureInfraPipelineYamlPath
+	}
+
+	return azdo.AzureCodePipelineName, azdo.AzureCodePipelineYamlPath
+}
+
+// previewDefinition returns errPreviewUnsupported, Azure DevOps doesn't run pipelines when pull requests are closed
+func (p *AzdoCiProvider) previewDefinition(
+	provisioningProvider provisioning.Options,
+	authType AuthType,
+) (string, []byte, error) {
+	return "", nil, errPreviewUnsupported
+}
+
+// configureFederatedConnection creates the service connection signing in with workload identity federation, and adds
+// the federated credential trusting the tokens of the connection to the service principal
+func (p *AzdoCiProvider) configureFederatedConnection(
+	ctx context.Context,
+	azdEnvironment *environment.Environment,
+	repoDetails *gitRepositoryDetails,
+	credentials json.RawMessage,
+	console input.Console) error {
+
+	azureCredentials, err := parseCredentials(ctx, credentials)
+	if err != nil {
+		return err
+	}
+
+	p.credentials = azureCredentials
+	details := repoDetails.details.(*AzdoRepositoryDetails)
+	clients, err := p.getClients(ctx, console)
+	if err != nil {
+		return err
+	}
+
+	federated, err := azdo.CreateFederatedServiceConnection(
+		ctx, clients, details.orgName, details.projectId, details.projectName, *azureCredentials, console)
+	if err != nil {
+		return err
+	}
+
+	description := fmt.Sprintf("Created by azd pipeline config for %s/%s", details.orgName, details.projectName)
+	credential := graphsdk.FederatedIdentityCredential{
+		Name:        "azd-azdo-" + azdo.ServiceConnectionName,
+		Issuer:      federated.Issuer,
+		Subject:     federated.Subject,
+		Description: &description,
+		Audiences:   []string{federatedTokenAudience},
+	}
+
+	console.Message(ctx, fmt.Sprintf("Adding federated credential %s (%s).\n", credential.Name, credential.Subject))
+	if err := azcli.GetAzCli(ctx).CreateOrUpdateFederatedCredential(ctx, azureCredentials.ClientId, credential); err != nil {
+		return fmt.Errorf("failed adding federated credential: %w", err)
+	}
+
+	return nil
+}
+
+// getClients returns the clients of the organization, with the PAT of the environment
+func (p *AzdoCiProvider) getClients(ctx context.Context, console input.Console) (azdo.Clients, error) {
+	if p.azdoClients != nil {
+		return p.azdoClients, nil
+	}
+
+	org, err := azdo.EnsureOrgNameExists(ctx, p.Env, console)
+	if err != nil {
+		return nil, err
+	}
+	pat, err := azdo.EnsurePatExists(ctx, p.Env, console)
+	if err != nil {
+		return nil, err
+	}
+
+	return azdo.GetClients(ctx, org, pat)
+}
+
+// summarize describes the Azure DevOps project, repository and pipeline, along with the variables of the pipeline
+func (p *AzdoCiProvider) summarize(repoDetails *gitRepositoryDetails, summary *Summary) {
+	details := repoDetails.details.(*AzdoRepositoryDetails)
+	projectUrl := fmt.Sprintf("https://%s/%s/%s", azdo.AzDoHostName, details.orgName, url.PathEscape(details.projectName))
+
+	summary.ProjectUrl = projectUrl
+	summary.RepositoryUrl = details.repoWebUrl
+	if len(summary.Stages) == 0 && summary.ManagedIdentityClientId == "" {
+		summary.ServiceConnectionName = p.serviceConnectionName()
+	}
+	if p.variableGroup {
+		summary.VariableGroup = azdo.VariableGroupName(p.Env.GetEnvName())
+	}
+	for index := range summary.Stages {
+		summary.Stages[index].ServiceConnectionName = azdo.StageServiceConnectionName(summary.Stages[index].Name)
+	}
+	summary.Secrets = []string{}
+
+	if definition := details.buildDefinition; definition != nil {
+		if definition.Id != nil {
+			summary.PipelineUrl = fmt.Sprintf("%s/_build?definitionId=%d", projectUrl, *definition.Id)
+		}
+
+		if definition.Variables != nil {
+			for name := range *definition.Variables {
+				summary.Secrets = append(summary.Secrets, name)
+			}
+			sort.Strings(summary.Secrets)
+		}
+	}
+}
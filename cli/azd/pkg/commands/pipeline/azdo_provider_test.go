@@ -5,12 +5,16 @@ package pipeline
 
 import (
 	"context"
+	"net/http"
 	"path"
+	"strings"
 	"testing"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/azdo"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/console"
 	"github.com/azure/azure-dev/cli/azd/test/ostest"
 	"github.com/stretchr/testify/require"
@@ -86,10 +90,11 @@ func Test_azdo_provider_preConfigureCheck(t *testing.T) {
 		t.Setenv(azdo.AzDoEnvironmentOrgName, "testOrg")
 		t.Setenv(azdo.AzDoPatName, testPat)
 		testConsole := console.NewMockConsole()
-		ctx := context.Background()
+		mockContext := mocks.NewMockContext(context.Background())
+		mockPatScopes(mockContext, http.StatusOK)
 
 		// act
-		e := provider.preConfigureCheck(ctx, testConsole)
+		e := provider.preConfigureCheck(*mockContext.Context, testConsole)
 
 		// assert
 		require.NoError(t, e)
@@ -105,10 +110,11 @@ func Test_azdo_provider_preConfigureCheck(t *testing.T) {
 		testConsole.WhenPrompt(func(options input.ConsoleOptions) bool {
 			return options.Message == "Personal Access Token (PAT):"
 		}).Respond(testPat)
-		ctx := context.Background()
+		mockContext := mocks.NewMockContext(context.Background())
+		mockPatScopes(mockContext, http.StatusOK)
 
 		// act
-		e := provider.preConfigureCheck(ctx, testConsole)
+		e := provider.preConfigureCheck(*mockContext.Context, testConsole)
 
 		// assert
 		require.Nil(t, e)
@@ -116,6 +122,55 @@ func Test_azdo_provider_preConfigureCheck(t *testing.T) {
 		require.EqualValues(t, "", provider.Env.Values[azdo.AzDoPatName])
 	})
 
+	t.Run("returns an error if the pat is missing scopes", func(t *testing.T) {
+		// arrange
+		provider := getEmptyAzdoScmProviderTestHarness()
+		t.Setenv(azdo.AzDoEnvironmentOrgName, "testOrg")
+		t.Setenv(azdo.AzDoPatName, "12345")
+		testConsole := console.NewMockConsole()
+		mockContext := mocks.NewMockContext(context.Background())
+		mockPatScopes(mockContext, http.StatusUnauthorized)
+
+		// act
+		e := provider.preConfigureCheck(*mockContext.Context, testConsole)
+
+		// assert
+		require.ErrorContains(t, e, "the personal access token is missing the scopes")
+	})
+}
+
+func mockPatScopes(mockContext *mocks.MockContext, statusCode int) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.URL.Host == azdo.AzDoHostName
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return &http.Response{Request: request, Header: http.Header{}, StatusCode: statusCode, Body: http.NoBody}, nil
+	})
+}
+
+func Test_azdo_provider_defaultImportSourceUrl(t *testing.T) {
+	tests := []struct {
+		name      string
+		originUrl string
+		expected  string
+	}{
+		{"https remote", "https://github.com/Azure-Samples/todo-nodejs-mongo.git",
+			"https://github.com/Azure-Samples/todo-nodejs-mongo.git"},
+		{"ssh remote", "git@github.com:Azure-Samples/todo-nodejs-mongo.git",
+			"https://github.com/Azure-Samples/todo-nodejs-mongo.git"},
+		{"azdo remote", "git@ssh.dev.azure.com:v3/fake_org/project1/repo1", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+				return strings.Contains(command, "remote get-url origin")
+			}).Respond(exec.NewRunResult(0, test.originUrl+"\n", ""))
+
+			provider := getEmptyAzdoScmProviderTestHarness()
+			require.Equal(t, test.expected, provider.defaultImportSourceUrl(*mockContext.Context, t.TempDir()))
+		})
+	}
 }
 
 func Test_saveEnvironmentConfig(t *testing.T) {
@@ -0,0 +1,151 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azdo"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+)
+
+// The file name of the definition of the pipeline deleting the expired environments, for both providers
+const cleanupDefinitionFileName = "azure-dev-cleanup.yml"
+
+// The cron schedule of the pipeline deleting the expired environments, every 6 hours
+const cleanupSchedule = "0 */6 * * *"
+
+// HasCleanupDefinition returns true when the project has the definition of a pipeline deleting the expired
+// environments, for either provider
+func HasCleanupDefinition(azdCtx *azdcontext.AzdContext) bool {
+	for _, folder := range definitionFolders {
+		if _, err := os.Stat(filepath.Join(azdCtx.ProjectDirectory(), folder, cleanupDefinitionFileName)); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// the data of the templates of the definitions deleting the expired environments
+type cleanupTemplateData struct {
+	Schedule  string
+	Tag       string
+	Federated bool
+	// the service connection the Azure DevOps pipeline signs in with
+	ServiceConnection string
+}
+
+func newCleanupTemplateData(authType AuthType) cleanupTemplateData {
+	return cleanupTemplateData{
+		Schedule:          cleanupSchedule,
+		Tag:               infra.EnvironmentExpiresOnTag,
+		Federated:         authType == AuthTypeFederated,
+		ServiceConnection: azdo.ServiceConnectionName,
+	}
+}
+
+// The script deleting the resource groups whose expiry is past. The expiry is an RFC 3339 UTC time, comparing the
+// strings compares the times.
+const cleanupScriptTemplate = `[[ define "cleanup" ]]
+            now=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+            az group list --tag [[ .Tag ]] --query "[].[name, tags.\"[[ .Tag ]]\"]" --output tsv |
+              while read -r group expiresOn; do
+                if [ "$expiresOn" \< "$now" ]; then
+                  echo "Deleting the resource group $group, expired on $expiresOn"
+                  az group delete --name "$group" --yes --no-wait
+                fi
+              done
+[[- end ]]`
+
+// The GitHub workflow deleting the resource groups of the expired environments on a schedule
+const gitHubCleanupWorkflowTemplate = cleanupScriptTemplate + `# Generated by azd pipeline config
+# Deletes the resource groups of the environments whose TTL expired, set with azd env set-ttl.
+on:
+  workflow_dispatch:
+  schedule:
+    - cron: '[[ .Schedule ]]'
+[[- if .Federated ]]
+
+permissions:
+  id-token: write
+  contents: read
+[[- end ]]
+
+jobs:
+  cleanup:
+    runs-on: ubuntu-latest
+    steps:
+[[- if .Federated ]]
+      - name: Log in with Azure (Federated Credentials)
+        uses: azure/login@v1
+        with:
+          client-id: ${{ vars.AZURE_CLIENT_ID }}
+          tenant-id: ${{ vars.AZURE_TENANT_ID }}
+          subscription-id: ${{ vars.AZURE_SUBSCRIPTION_ID }}
+[[- else ]]
+      - name: Log in with Azure
+        uses: azure/login@v1
+        with:
+          creds: ${{ secrets.AZURE_CREDENTIALS }}
+[[- end ]]
+
+      - name: Delete the expired environments
+        shell: bash
+        run: |
+[[- template "cleanup" . ]]
+`
+
+// The Azure DevOps pipeline deleting the resource groups of the expired environments on a schedule, signing in with
+// the service connection whatever its auth type
+const azdoCleanupPipelineTemplate = cleanupScriptTemplate + `# Generated by azd pipeline config
+# Deletes the resource groups of the environments whose TTL expired, set with azd env set-ttl.
+trigger: none
+
+schedules:
+  - cron: '[[ .Schedule ]]'
+    displayName: Delete the expired environments
+    branches:
+      include:
+        - main
+        - master
+    always: true
+
+pool:
+  vmImage: ubuntu-latest
+
+jobs:
+  - job: Cleanup
+    steps:
+      - task: AzureCLI@2
+        displayName: Delete the expired environments
+        inputs:
+          azureSubscription: [[ .ServiceConnection ]]
+          scriptType: bash
+          scriptLocation: inlineScript
+          inlineScript: |
+[[- template "cleanup" . ]]
+`
+
+// gitHubCleanupWorkflow returns the GitHub workflow deleting the expired environments, signing in with the auth type
+func gitHubCleanupWorkflow(authType AuthType) ([]byte, error) {
+	return executeDefinitionTemplate(
+		"github cleanup workflow",
+		gitHubCleanupWorkflowTemplate,
+		gitHubTemplateFuncs,
+		newCleanupTemplateData(authType),
+	)
+}
+
+// azdoCleanupPipeline returns the Azure DevOps pipeline deleting the expired environments
+func azdoCleanupPipeline() ([]byte, error) {
+	return executeDefinitionTemplate(
+		"azure devops cleanup pipeline",
+		azdoCleanupPipelineTemplate,
+		gitHubTemplateFuncs,
+		newCleanupTemplateData(AuthTypeClientSecret),
+	)
+}
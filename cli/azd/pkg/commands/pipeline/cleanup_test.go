@@ -0,0 +1,97 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_gitHubCleanupWorkflow(t *testing.T) {
+	type workflowYaml struct {
+		On struct {
+			Schedule []struct {
+				Cron string `yaml:"cron"`
+			} `yaml:"schedule"`
+		} `yaml:"on"`
+		Permissions map[string]string `yaml:"permissions"`
+		Jobs        map[string]struct {
+			Steps []struct {
+				Shell string            `yaml:"shell"`
+				Run   string            `yaml:"run"`
+				With  map[string]string `yaml:"with"`
+			} `yaml:"steps"`
+		} `yaml:"jobs"`
+	}
+
+	t.Run("ClientSecret", func(t *testing.T) {
+		content, err := gitHubCleanupWorkflow(AuthTypeClientSecret)
+		require.NoError(t, err)
+
+		var workflow workflowYaml
+		require.NoError(t, yaml.Unmarshal(content, &workflow))
+
+		require.Equal(t, cleanupSchedule, workflow.On.Schedule[0].Cron)
+		require.Nil(t, workflow.Permissions)
+		steps := workflow.Jobs["cleanup"].Steps
+		require.Equal(t, "${{ secrets.AZURE_CREDENTIALS }}", steps[0].With["creds"])
+		require.Equal(t, "bash", steps[1].Shell)
+		require.Contains(t, steps[1].Run, `az group list --tag azd-env-expires-on`)
+		require.Contains(t, steps[1].Run, `if [ "$expiresOn" \< "$now" ]; then`)
+		require.Contains(t, steps[1].Run, `az group delete --name "$group" --yes --no-wait`)
+	})
+
+	t.Run("Federated", func(t *testing.T) {
+		content, err := gitHubCleanupWorkflow(AuthTypeFederated)
+		require.NoError(t, err)
+
+		var workflow workflowYaml
+		require.NoError(t, yaml.Unmarshal(content, &workflow))
+
+		require.Equal(t, "write", workflow.Permissions["id-token"])
+		require.Equal(t, "${{ vars.AZURE_CLIENT_ID }}", workflow.Jobs["cleanup"].Steps[0].With["client-id"])
+	})
+}
+
+func Test_azdoCleanupPipeline(t *testing.T) {
+	content, err := azdoCleanupPipeline()
+	require.NoError(t, err)
+
+	var pipeline struct {
+		Trigger   string `yaml:"trigger"`
+		Schedules []struct {
+			Cron string `yaml:"cron"`
+		} `yaml:"schedules"`
+		Jobs []struct {
+			Steps []struct {
+				Task   string            `yaml:"task"`
+				Inputs map[string]string `yaml:"inputs"`
+			} `yaml:"steps"`
+		} `yaml:"jobs"`
+	}
+	require.NoError(t, yaml.Unmarshal(content, &pipeline))
+
+	require.Equal(t, "none", pipeline.Trigger)
+	require.Equal(t, cleanupSchedule, pipeline.Schedules[0].Cron)
+	step := pipeline.Jobs[0].Steps[0]
+	require.Equal(t, "AzureCLI@2", step.Task)
+	require.Equal(t, "azconnection", step.Inputs["azureSubscription"])
+	require.Contains(t, step.Inputs["inlineScript"], "az group delete")
+}
+
+func Test_HasCleanupDefinition(t *testing.T) {
+	azdCtx := &azdcontext.AzdContext{}
+	azdCtx.SetProjectDirectory(t.TempDir())
+	require.False(t, HasCleanupDefinition(azdCtx))
+
+	folder := filepath.Join(azdCtx.ProjectDirectory(), azdoDefinitionFolder)
+	require.NoError(t, os.MkdirAll(folder, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, cleanupDefinitionFileName), nil, 0600))
+	require.True(t, HasCleanupDefinition(azdCtx))
+}
@@ -0,0 +1,219 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/blang/semver/v4"
+)
+
+// The first line of the definitions generated by azd, followed by the options they were generated with
+const generatedHeaderPrefix = "# Generated by azd"
+
+// The comments stamping a generated definition with the versions it was generated from
+const (
+	azdVersionStampPrefix      = "# azd-version: "
+	templateVersionStampPrefix = "# azd-template: "
+)
+
+// The version of the dev builds of azd, which aren't released and can't be compared
+const devVersion = "0.0.0-dev.0"
+
+// definitionChange is a change of the generated definitions requiring the definitions generated by the previous
+// versions of azd to be generated again
+type definitionChange struct {
+	// The first version of azd generating the changed definitions
+	version semver.Version
+	// What the definitions generated by the previous versions miss
+	description string
+	// The folder of the definitions of the provider changed, the definitions of every provider when empty
+	folder string
+}
+
+// The breaking changes of the generated definitions, in version order
+var definitionChanges = []definitionChange{
+	{
+		version: semver.MustParse("0.3.0-beta.6"),
+		description: "the GitHub workflows read the non-sensitive values of the pipeline from repository variables " +
+			"instead of secrets",
+		folder: gitHubDefinitionFolder,
+	},
+}
+
+// OutdatedDefinition is a pipeline definition generated by a version of azd preceding breaking changes of the
+// generated definitions
+type OutdatedDefinition struct {
+	// The path of the definition, relative to the project directory
+	Path string
+	// The version of azd the definition was generated by, empty when the definition isn't stamped with its version
+	GeneratedBy string
+	// The command the definition was generated by, i.e. azd pipeline config --rotation-schedule
+	Command string
+	// The changes the definition predates
+	Changes []string
+}
+
+// Message describes why the definition must be generated again and how
+func (d OutdatedDefinition) Message() string {
+	generatedBy := "an earlier version of azd"
+	if d.GeneratedBy != "" {
+		generatedBy = "azd " + d.GeneratedBy
+	}
+
+	return fmt.Sprintf(
+		"%s was generated by %s and predates changes of the generated pipelines: %s. Run '%s' to generate it again.",
+		d.Path, generatedBy, strings.Join(d.Changes, "; "), d.Command)
+}
+
+// stampDefinition adds the versions of azd and of the template of the project after the header of a generated
+// definition, so later versions of azd can detect the definitions predating their changes
+func stampDefinition(definition []byte, templateVersion string) []byte {
+	header, rest, found := bytes.Cut(definition, []byte("\n"))
+	if !found || !bytes.HasPrefix(header, []byte(generatedHeaderPrefix)) {
+		return definition
+	}
+
+	var stamped bytes.Buffer
+	stamped.Write(header)
+	stamped.WriteString("\n" + azdVersionStampPrefix + internal.GetVersionNumber() + "\n")
+	if templateVersion != "" {
+		stamped.WriteString(templateVersionStampPrefix + templateVersion + "\n")
+	}
+	stamped.Write(rest)
+
+	return stamped.Bytes()
+}
+
+// unstampedDefinition returns the definition without its version stamps, to compare definitions generated by
+// different versions
+func unstampedDefinition(definition []byte) []byte {
+	var unstamped bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(definition))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, azdVersionStampPrefix) || strings.HasPrefix(line, templateVersionStampPrefix) {
+			continue
+		}
+
+		unstamped.WriteString(line + "\n")
+	}
+
+	return unstamped.Bytes()
+}
+
+// templateVersion returns the version of the template of the project, empty when the project isn't from a template
+func templateVersion(prj *project.ProjectConfig) string {
+	if prj.Metadata == nil {
+		return ""
+	}
+
+	if prj.Metadata.Source != nil && prj.Metadata.Source.Commit != "" {
+		return fmt.Sprintf("%s@%s", prj.Metadata.Source.Repository, prj.Metadata.Source.Commit)
+	}
+
+	return prj.Metadata.Template
+}
+
+// The folders of the project the providers write the pipeline definitions to
+var (
+	gitHubDefinitionFolder = filepath.Join(githubFolder, "workflows")
+	azdoDefinitionFolder   = filepath.Join(azdoFolder, "pipelines")
+	definitionFolders      = []string{gitHubDefinitionFolder, azdoDefinitionFolder}
+)
+
+// FindOutdatedDefinitions returns the pipeline definitions of the project generated by azd before breaking changes of
+// the generated definitions. The definitions written by hand, or by the template of the project, aren't checked.
+func FindOutdatedDefinitions(azdCtx *azdcontext.AzdContext) ([]OutdatedDefinition, error) {
+	var outdated []OutdatedDefinition
+	for _, folder := range definitionFolders {
+		entries, err := os.ReadDir(filepath.Join(azdCtx.ProjectDirectory(), folder))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("listing pipeline definitions: %w", err)
+		}
+
+		for _, entry := range entries {
+			extension := filepath.Ext(entry.Name())
+			if entry.IsDir() || (extension != ".yml" && extension != ".yaml") {
+				continue
+			}
+
+			relativePath := filepath.Join(folder, entry.Name())
+			definition, err := os.ReadFile(filepath.Join(azdCtx.ProjectDirectory(), relativePath))
+			if err != nil {
+				return nil, fmt.Errorf("reading pipeline definition: %w", err)
+			}
+
+			if check, ok := checkDefinition(relativePath, definition); ok {
+				outdated = append(outdated, check)
+			}
+		}
+	}
+
+	return outdated, nil
+}
+
+// checkDefinition returns the changes a definition generated by azd predates, ok is false when the definition is up
+// to date or wasn't generated by azd
+func checkDefinition(relativePath string, definition []byte) (OutdatedDefinition, bool) {
+	header, _, _ := bytes.Cut(definition, []byte("\n"))
+	if !bytes.HasPrefix(header, []byte(generatedHeaderPrefix)) {
+		return OutdatedDefinition{}, false
+	}
+
+	result := OutdatedDefinition{
+		Path:    relativePath,
+		Command: generatedCommand(string(header)),
+	}
+
+	// Definitions generated before they were stamped predate every change
+	var generatedBy *semver.Version
+	scanner := bufio.NewScanner(bytes.NewReader(definition))
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, azdVersionStampPrefix) {
+			result.GeneratedBy = strings.TrimSpace(strings.TrimPrefix(line, azdVersionStampPrefix))
+			if result.GeneratedBy == devVersion {
+				return OutdatedDefinition{}, false
+			}
+
+			if parsed, err := semver.Parse(result.GeneratedBy); err == nil {
+				generatedBy = &parsed
+			}
+			break
+		}
+	}
+
+	for _, change := range definitionChanges {
+		if change.folder != "" && filepath.Dir(relativePath) != change.folder {
+			continue
+		}
+
+		if generatedBy == nil || generatedBy.LT(change.version) {
+			result.Changes = append(result.Changes, change.description)
+		}
+	}
+
+	return result, len(result.Changes) > 0
+}
+
+// generatedCommand returns the command of the header of a generated definition, i.e. azd pipeline config
+// --rotation-schedule
+func generatedCommand(header string) string {
+	command := strings.TrimSpace(strings.TrimPrefix(header, "# Generated by "))
+	if index := strings.Index(command, " from "); index >= 0 {
+		command = command[:index]
+	}
+
+	return command
+}
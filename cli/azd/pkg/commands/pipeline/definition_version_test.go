@@ -0,0 +1,103 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/stretchr/testify/require"
+)
+
+const testDefinition = "# Generated by azd pipeline config --rotation-schedule\nname: Rotate\non:\n  workflow_dispatch:\n"
+
+func TestStampDefinition(t *testing.T) {
+	stamped := stampDefinition([]byte(testDefinition), "todo-python-mongo@0.0.1-beta")
+	require.Equal(t,
+		"# Generated by azd pipeline config --rotation-schedule\n"+
+			"# azd-version: "+internal.GetVersionNumber()+"\n"+
+			"# azd-template: todo-python-mongo@0.0.1-beta\n"+
+			"name: Rotate\non:\n  workflow_dispatch:\n",
+		string(stamped))
+	require.Equal(t, testDefinition, string(unstampedDefinition(stamped)))
+
+	// Definitions written by hand aren't stamped
+	require.Equal(t, "name: Build\n", string(stampDefinition([]byte("name: Build\n"), "")))
+}
+
+func TestTemplateVersion(t *testing.T) {
+	require.Equal(t, "", templateVersion(&project.ProjectConfig{}))
+	require.Equal(t, "todo@1.0", templateVersion(&project.ProjectConfig{
+		Metadata: &project.ProjectMetadata{Template: "todo@1.0"},
+	}))
+	require.Equal(t, "https://github.com/contoso/todo@abc123", templateVersion(&project.ProjectConfig{
+		Metadata: &project.ProjectMetadata{
+			Template: "todo@1.0",
+			Source:   &project.TemplateSource{Repository: "https://github.com/contoso/todo", Commit: "abc123"},
+		},
+	}))
+}
+
+func TestCheckDefinition(t *testing.T) {
+	gitHubPath := filepath.Join(gitHubDefinitionFolder, "azure-dev-rotate.yml")
+	azdoPath := filepath.Join(azdoDefinitionFolder, "azure-dev-rotate.yml")
+	stamp := func(version string) string {
+		return "# Generated by azd pipeline config --rotation-schedule\n# azd-version: " + version + "\nname: Rotate\n"
+	}
+
+	t.Run("Unstamped", func(t *testing.T) {
+		outdated, ok := checkDefinition(gitHubPath, []byte(testDefinition))
+		require.True(t, ok)
+		require.Equal(t, "", outdated.GeneratedBy)
+		require.Equal(t, "azd pipeline config --rotation-schedule", outdated.Command)
+		require.Len(t, outdated.Changes, 1)
+		require.Contains(t, outdated.Message(), "generated by an earlier version of azd")
+	})
+
+	t.Run("StampedBeforeChange", func(t *testing.T) {
+		outdated, ok := checkDefinition(gitHubPath, []byte(stamp("0.3.0-beta.5")))
+		require.True(t, ok)
+		require.Equal(t, "0.3.0-beta.5", outdated.GeneratedBy)
+	})
+
+	t.Run("StampedAfterChange", func(t *testing.T) {
+		_, ok := checkDefinition(gitHubPath, []byte(stamp("0.3.0")))
+		require.False(t, ok)
+	})
+
+	t.Run("DevBuild", func(t *testing.T) {
+		_, ok := checkDefinition(gitHubPath, []byte(stamp(devVersion)))
+		require.False(t, ok)
+	})
+
+	t.Run("OtherProvider", func(t *testing.T) {
+		_, ok := checkDefinition(azdoPath, []byte(testDefinition))
+		require.False(t, ok)
+	})
+
+	t.Run("NotGenerated", func(t *testing.T) {
+		_, ok := checkDefinition(gitHubPath, []byte("name: Build\n"))
+		require.False(t, ok)
+	})
+}
+
+func TestFindOutdatedDefinitions(t *testing.T) {
+	projectDir := t.TempDir()
+	workflows := filepath.Join(projectDir, gitHubDefinitionFolder)
+	require.NoError(t, os.MkdirAll(workflows, osutil.PermissionDirectory))
+	require.NoError(t, os.WriteFile(filepath.Join(workflows, "azure-dev-rotate.yml"), []byte(testDefinition), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(workflows, "build.yml"), []byte("name: Build\n"), 0600))
+
+	azdCtx := &azdcontext.AzdContext{}
+	azdCtx.SetProjectDirectory(projectDir)
+	outdated, err := FindOutdatedDefinitions(azdCtx)
+	require.NoError(t, err)
+	require.Len(t, outdated, 1)
+	require.Equal(t, filepath.Join(gitHubDefinitionFolder, "azure-dev-rotate.yml"), outdated[0].Path)
+}
@@ -12,13 +12,16 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"text/template"
 
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/fields"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	githubRemote "github.com/azure/azure-dev/cli/azd/pkg/github"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/github"
 )
@@ -105,6 +108,8 @@ func (p *GitHubScmProvider) configureGitRemote(
 		panic(fmt.Sprintf("unexpected selection index %d", idx))
 	}
 
+	setConfigAttributes(ctx, fields.PipelineRepositoryCreatedKey.Bool(p.newGitHubRepoCreated))
+
 	return remoteUrl, nil
 }
 
@@ -131,10 +136,62 @@ func (p *GitHubScmProvider) gitRepoDetails(ctx context.Context, remoteUrl string
 		return nil, ErrRemoteHostIsNotGitHub
 	}
 	slugParts := strings.Split(slug, "/")
-	return &gitRepositoryDetails{
+	repoDetails := &gitRepositoryDetails{
 		owner:    slugParts[0],
 		repoName: slugParts[1],
-	}, nil
+	}
+
+	// A repository created by azd is never a fork
+	if p.newGitHubRepoCreated {
+		return repoDetails, nil
+	}
+
+	if err := selectForkRepository(ctx, github.NewGitHubCli(ctx), repoDetails); err != nil {
+		return nil, err
+	}
+
+	return repoDetails, nil
+}
+
+// selectForkRepository asks whether to configure the pipeline on the fork or on its upstream repository when the
+// remote is a fork. Secrets set on a fork aren't available to the workflow runs of the upstream repository.
+func selectForkRepository(ctx context.Context, ghCli github.GitHubCli, repoDetails *gitRepositoryDetails) error {
+	slug := repoDetails.owner + "/" + repoDetails.repoName
+	repo, err := ghCli.ViewRepository(ctx, slug)
+	if err != nil {
+		return fmt.Errorf("fetching repository info: %w", err)
+	}
+
+	if !repo.IsFork || repo.Parent == nil {
+		return nil
+	}
+
+	console := input.GetConsole(ctx)
+	upstreamSlug := repo.Parent.NameWithOwner()
+	idx, err := console.Select(ctx, input.ConsoleOptions{
+		Message: fmt.Sprintf(
+			"The repository %s is a fork of %s. Which repository would you like to configure the pipeline on?",
+			slug, upstreamSlug),
+		Options: []string{
+			fmt.Sprintf("The fork (%s)", slug),
+			fmt.Sprintf("The upstream repository (%s)", upstreamSlug),
+		},
+		DefaultValue: fmt.Sprintf("The fork (%s)", slug),
+	})
+	if err != nil {
+		return fmt.Errorf("prompting for fork repository: %w", err)
+	}
+
+	if idx == 1 {
+		repoDetails.owner = repo.Parent.Owner.Login
+		repoDetails.repoName = repo.Parent.Name
+		console.Message(ctx, fmt.Sprintf(
+			"The pipeline is configured on %s. Changes pushed to the fork %s run the pipeline once they are merged "+
+				"into %s with a pull request.\n",
+			upstreamSlug, slug, upstreamSlug))
+	}
+
+	return nil
 }
 
 // preventGitPush validate if GitHub actions are disabled and won't work before pushing
@@ -281,6 +338,8 @@ func notifyWhenGitHubActionsAreDisabled(
 // GitHubCiProvider implements a CiProvider using GitHub to manage CI pipelines as
 // GitHub actions.
 type GitHubCiProvider struct {
+	// names of the secrets and variables set by configureConnection
+	secrets []string
 }
 
 // ***  subareaProvider implementation ******
@@ -318,11 +377,46 @@ func (p *GitHubCiProvider) configureConnection(
 
 	repoSlug := repoDetails.owner + "/" + repoDetails.repoName
 	console.Message(ctx, fmt.Sprintf("Configuring repository %s.\n", repoSlug))
-	console.Message(ctx, "Setting AZURE_CREDENTIALS GitHub repo secret.\n")
 
 	ghCli := github.NewGitHubCli(ctx)
+	p.secrets = []string{}
+	err := setConnectionSecrets(
+		ctx,
+		azdEnvironment,
+		infraOptions,
+		credentials,
+		console,
+		"GitHub repo",
+		func(name string, value string) error {
+			return p.setValue(ctx, ghCli, repoSlug, name, value)
+		})
+	if err != nil {
+		return err
+	}
+
+	console.Message(ctx, fmt.Sprintf(
+		`GitHub Action secrets and variables are now configured.
+		See your .github/workflows folder for details on which actions will be enabled.
+		You can view the GitHub Actions here: https://github.com/%s/actions`, repoSlug))
+
+	return nil
+}
+
+// setConnectionSecrets sets the secrets and variables the pipeline signs in to Azure and runs azd with, from the
+// credentials and the environment. scope describes where they're set to the user, i.e. "GitHub repo".
+func setConnectionSecrets(
+	ctx context.Context,
+	azdEnvironment *environment.Environment,
+	infraOptions provisioning.Options,
+	credentials json.RawMessage,
+	console input.Console,
+	scope string,
+	setSecret func(name string, value string) error,
+) error {
+	console.Message(ctx, fmt.Sprintf("Setting AZURE_CREDENTIALS %s %s.\n", scope, gitHubValueKind("AZURE_CREDENTIALS")))
+
 	// set azure credential for pipelines can log in to Azure
-	if err := ghCli.SetSecret(ctx, repoSlug, "AZURE_CREDENTIALS", string(credentials)); err != nil {
+	if err := setSecret("AZURE_CREDENTIALS", string(credentials)); err != nil {
 		return fmt.Errorf("failed setting AZURE_CREDENTIALS secret: %w", err)
 	}
 
@@ -337,13 +431,13 @@ func (p *GitHubCiProvider) configureConnection(
 		if e := json.Unmarshal(credentials, &values); e != nil {
 			return fmt.Errorf("setting terraform env var credentials: %w", e)
 		}
-		if err := ghCli.SetSecret(ctx, repoSlug, "ARM_TENANT_ID", values.Tenant); err != nil {
+		if err := setSecret("ARM_TENANT_ID", values.Tenant); err != nil {
 			return fmt.Errorf("setting terraform env var credentials:: %w", err)
 		}
-		if err := ghCli.SetSecret(ctx, repoSlug, "ARM_CLIENT_ID", values.ClientId); err != nil {
+		if err := setSecret("ARM_CLIENT_ID", values.ClientId); err != nil {
 			return fmt.Errorf("setting terraform env var credentials:: %w", err)
 		}
-		if err := ghCli.SetSecret(ctx, repoSlug, "ARM_CLIENT_SECRET", values.ClientSecret); err != nil {
+		if err := setSecret("ARM_CLIENT_SECRET", values.ClientSecret); err != nil {
 			return fmt.Errorf("setting terraform env var credentials:: %w", err)
 		}
 
@@ -352,7 +446,11 @@ func (p *GitHubCiProvider) configureConnection(
 		for _, key := range remoteStateKeys {
 			value, ok := azdEnvironment.Values[key]
 			if !ok || strings.TrimSpace(value) == "" {
-				console.Message(ctx, output.WithWarningFormat("WARNING: Terraform Remote State configuration is invalid!"))
+				warning := "WARNING: Terraform Remote State configuration is invalid!"
+				console.Message(ctx, output.WithWarningFormat(warning))
+				if annotation := output.Annotation(output.AnnotationWarning, warning); annotation != "" {
+					console.Message(ctx, annotation)
+				}
 				console.Message(
 					ctx,
 					fmt.Sprintf(
@@ -364,7 +462,7 @@ func (p *GitHubCiProvider) configureConnection(
 				return errors.New("terraform remote state is not correctly configured")
 			}
 			// env var was found
-			if err := ghCli.SetSecret(ctx, repoSlug, key, value); err != nil {
+			if err := setSecret(key, value); err != nil {
 				return fmt.Errorf("setting terraform remote state variables: %w", err)
 			}
 		}
@@ -376,18 +474,78 @@ func (p *GitHubCiProvider) configureConnection(
 		environment.EnvNameEnvVarName,
 		environment.LocationEnvVarName,
 		environment.SubscriptionIdEnvVarName} {
-		console.Message(ctx, fmt.Sprintf("Setting %s GitHub repo secret.\n", envName))
+		console.Message(ctx, fmt.Sprintf("Setting %s %s %s.\n", envName, scope, gitHubValueKind(envName)))
 
-		if err := ghCli.SetSecret(ctx, repoSlug, envName, azdEnvironment.Values[envName]); err != nil {
+		if err := setSecret(envName, azdEnvironment.Values[envName]); err != nil {
 			return fmt.Errorf("failed setting %s secret: %w", envName, err)
 		}
 	}
 
-	console.Message(ctx, fmt.Sprintf(
-		`GitHub Action secrets are now configured.
-		See your .github/workflows folder for details on which actions will be enabled.
-		You can view the GitHub Actions here: https://github.com/%s/actions`, repoSlug))
+	return nil
+}
+
+// The values set in the GitHub pipeline which aren't sensitive, they're set as variables of the repository so they're
+// visible and diffable in its settings. Any other value, i.e. AZURE_CREDENTIALS or a value synced from the environment,
+// is set as a secret.
+var gitHubVariableNames = map[string]bool{
+	environment.EnvNameEnvVarName:        true,
+	environment.LocationEnvVarName:       true,
+	environment.SubscriptionIdEnvVarName: true,
+	environment.TenantIdEnvVarName:       true,
+	"AZURE_CLIENT_ID":                    true,
+	"ARM_TENANT_ID":                      true,
+	"ARM_CLIENT_ID":                      true,
+	"RS_RESOURCE_GROUP":                  true,
+	"RS_STORAGE_ACCOUNT":                 true,
+	"RS_CONTAINER_NAME":                  true,
+}
+
+// isGitHubSecret returns true when the value is set as a secret of the repository, false for a variable
+func isGitHubSecret(name string) bool {
+	return !gitHubVariableNames[name]
+}
 
+// gitHubValueKind describes how the value is set in the repository, as a secret or a variable
+func gitHubValueKind(name string) string {
+	if isGitHubSecret(name) {
+		return "secret"
+	}
+
+	return "variable"
+}
+
+// gitHubExpression returns the expression of a workflow reading the secret or the variable of the repository
+func gitHubExpression(name string) string {
+	if isGitHubSecret(name) {
+		return fmt.Sprintf("${{ secrets.%s }}", name)
+	}
+
+	return fmt.Sprintf("${{ vars.%s }}", name)
+}
+
+// The functions of the templates of the GitHub workflows, i.e. [[ github "AZURE_ENV_NAME" ]]
+var gitHubTemplateFuncs = template.FuncMap{
+	"github": gitHubExpression,
+}
+
+// setValue sets the value as a secret or a variable of the repository, see isGitHubSecret, and records its name for
+// the summary of the configuration
+func (p *GitHubCiProvider) setValue(
+	ctx context.Context,
+	ghCli github.GitHubCli,
+	repoSlug string,
+	name string,
+	value string,
+) error {
+	if isGitHubSecret(name) {
+		if err := ghCli.SetSecret(ctx, repoSlug, name, value); err != nil {
+			return err
+		}
+	} else if err := ghCli.SetVariable(ctx, repoSlug, name, value); err != nil {
+		return err
+	}
+
+	p.secrets = append(p.secrets, name)
 	return nil
 }
 
@@ -401,6 +559,285 @@ func (p *GitHubCiProvider) configurePipeline(
 	return nil
 }
 
+// setPipelineVariable sets the value as a secret or a variable of the repository, like the values set by
+// configureConnection
+func (p *GitHubCiProvider) setPipelineVariable(
+	ctx context.Context,
+	repoDetails *gitRepositoryDetails,
+	name string,
+	value string,
+) error {
+	repoSlug := repoDetails.owner + "/" + repoDetails.repoName
+	ghCli := github.NewGitHubCli(ctx)
+	if isGitHubSecret(name) {
+		if err := ghCli.SetSecret(ctx, repoSlug, name, value); err != nil {
+			return fmt.Errorf("failed setting %s secret: %w", name, err)
+		}
+	} else if err := ghCli.SetVariable(ctx, repoSlug, name, value); err != nil {
+		return fmt.Errorf("failed setting %s variable: %w", name, err)
+	}
+
+	return nil
+}
+
+// configureStageConnection creates the GitHub environment of the stage and sets the values of the stage in that
+// environment, the job of the stage reads them by running in the environment
+func (p *GitHubCiProvider) configureStageConnection(
+	ctx context.Context,
+	repoDetails *gitRepositoryDetails,
+	infraOptions provisioning.Options,
+	stage *pipelineStage,
+	console input.Console) error {
+
+	repoSlug := repoDetails.owner + "/" + repoDetails.repoName
+	console.Message(ctx, fmt.Sprintf("Configuring environment %s of repository %s.\n", stage.name, repoSlug))
+
+	ghCli := github.NewGitHubCli(ctx)
+	if err := ghCli.CreateEnvironment(ctx, repoSlug, stage.name); err != nil {
+		return err
+	}
+
+	return setConnectionSecrets(
+		ctx,
+		stage.env,
+		infraOptions,
+		stage.credentials,
+		console,
+		fmt.Sprintf("GitHub environment %s", stage.name),
+		func(name string, value string) error {
+			if isGitHubSecret(name) {
+				if err := ghCli.SetEnvironmentSecret(ctx, repoSlug, stage.name, name, value); err != nil {
+					return err
+				}
+			} else if err := ghCli.SetEnvironmentVariable(ctx, repoSlug, stage.name, name, value); err != nil {
+				return err
+			}
+
+			p.secrets = append(p.secrets, fmt.Sprintf("%s/%s", stage.name, name))
+			return nil
+		})
+}
+
+// stagesDefinition returns the GitHub workflow deploying the stages in order
+func (p *GitHubCiProvider) stagesDefinition(
+	stages []*pipelineStage,
+	provisioningProvider provisioning.Options,
+) (string, []byte, error) {
+	workflow, err := gitHubStagesWorkflow(stages, provisioningProvider)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return filepath.Join(githubFolder, "workflows", "azure-dev.yml"), workflow, nil
+}
+
+// configureStagesPipeline is a no-op for GitHub, like configurePipeline
+func (p *GitHubCiProvider) configureStagesPipeline(
+	ctx context.Context,
+	repoDetails *gitRepositoryDetails,
+	provisioningProvider provisioning.Options,
+	stages []*pipelineStage,
+) error {
+	return nil
+}
+
+// rotationDefinition returns the GitHub workflow rotating the credential on the schedule
+func (p *GitHubCiProvider) rotationDefinition(schedule string, principalName string) (string, []byte, error) {
+	workflow, err := gitHubRotationWorkflow(schedule, principalName)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return filepath.Join(githubFolder, "workflows", "azure-dev-rotate.yml"), workflow, nil
+}
+
+// configureRotationPipeline only tells the user about the secret of the token the workflow sets the secrets with,
+// the workflow itself is created by pushing its file
+func (p *GitHubCiProvider) configureRotationPipeline(ctx context.Context, repoDetails *gitRepositoryDetails) error {
+	input.GetConsole(ctx).Message(ctx, fmt.Sprintf(
+		"Add the %s secret to the repository %s/%s, with a token allowed to set the secrets of the repository, "+
+			"for the workflow to save the rotated credential.\n",
+		output.WithHighLightFormat("%s", gitHubRotationTokenSecretName), repoDetails.owner, repoDetails.repoName))
+
+	return nil
+}
+
+// cleanupDefinition returns the GitHub workflow deleting the expired environments on a schedule
+func (p *GitHubCiProvider) cleanupDefinition(authType AuthType) (string, []byte, error) {
+	workflow, err := gitHubCleanupWorkflow(authType)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return filepath.Join(gitHubDefinitionFolder, cleanupDefinitionFileName), workflow, nil
+}
+
+// configureCleanupPipeline is a no-op for GitHub, like configurePipeline
+func (p *GitHubCiProvider) configureCleanupPipeline(ctx context.Context, repoDetails *gitRepositoryDetails) error {
+	return nil
+}
+
+// managedIdentityDefinition returns the GitHub workflow signing in with the managed identity of the runner
+func (p *GitHubCiProvider) managedIdentityDefinition() (string, []byte, error) {
+	workflow, err := gitHubManagedIdentityWorkflow()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return filepath.Join(githubFolder, "workflows", "azure-dev.yml"), workflow, nil
+}
+
+// configureManagedIdentityConnection sets the client id of the managed identity and the environment as variables of
+// the repository, there is no credential to set
+func (p *GitHubCiProvider) configureManagedIdentityConnection(
+	ctx context.Context,
+	azdEnvironment *environment.Environment,
+	repoDetails *gitRepositoryDetails,
+	clientId string,
+	console input.Console) error {
+
+	repoSlug := repoDetails.owner + "/" + repoDetails.repoName
+	console.Message(ctx, fmt.Sprintf("Configuring repository %s.\n", repoSlug))
+
+	ghCli := github.NewGitHubCli(ctx)
+	p.secrets = []string{}
+
+	values := map[string]string{managedIdentityClientIdVariable: clientId}
+	for _, name := range managedIdentityVariables {
+		values[name] = azdEnvironment.Values[name]
+	}
+
+	for _, name := range append([]string{managedIdentityClientIdVariable}, managedIdentityVariables...) {
+		console.Message(ctx, fmt.Sprintf("Setting %s GitHub repo %s.\n", name, gitHubValueKind(name)))
+		if err := p.setValue(ctx, ghCli, repoSlug, name, values[name]); err != nil {
+			return fmt.Errorf("failed setting %s %s: %w", name, gitHubValueKind(name), err)
+		}
+	}
+
+	return nil
+}
+
+// configureManagedIdentityPipeline is a no-op for GitHub, like configurePipeline
+func (p *GitHubCiProvider) configureManagedIdentityPipeline(
+	ctx context.Context,
+	repoDetails *gitRepositoryDetails,
+	clientId string,
+) error {
+	return nil
+}
+
+// federatedDefinition returns the GitHub workflow signing in with the federated credentials of the service principal
+func (p *GitHubCiProvider) federatedDefinition() (string, []byte, error) {
+	workflow, err := gitHubFederatedWorkflow()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return filepath.Join(githubFolder, "workflows", "azure-dev.yml"), workflow, nil
+}
+
+// partitionDefinition returns the GitHub workflow of the partition
+func (p *GitHubCiProvider) partitionDefinition(
+	partition *pipelinePartition,
+	provisioningProvider provisioning.Options,
+	authType AuthType,
+) (string, []byte, error) {
+	workflow, err := gitHubPartitionWorkflow(partition, provisioningProvider, authType)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return filepath.Join(githubFolder, "workflows", fmt.Sprintf("azure-dev-%s.yml", partition.name)), workflow, nil
+}
+
+// configurePartitionPipeline is a no-op for GitHub, like configurePipeline
+func (p *GitHubCiProvider) configurePartitionPipeline(
+	ctx context.Context,
+	repoDetails *gitRepositoryDetails,
+	provisioningProvider provisioning.Options,
+	partition *pipelinePartition,
+) error {
+	return nil
+}
+
+// previewDefinition returns the GitHub workflow deploying the pull requests to preview environments
+func (p *GitHubCiProvider) previewDefinition(
+	provisioningProvider provisioning.Options,
+	authType AuthType,
+) (string, []byte, error) {
+	workflow, err := gitHubPreviewWorkflow(provisioningProvider, authType)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return filepath.Join(githubFolder, "workflows", "azure-dev-preview.yml"), workflow, nil
+}
+
+// configureFederatedConnection adds the federated credentials trusting the workflows of the repository, run for the
+// current branch and for pull requests, and sets the service principal and the environment as variables of the
+// repository, there is no credential to set
+func (p *GitHubCiProvider) configureFederatedConnection(
+	ctx context.Context,
+	azdEnvironment *environment.Environment,
+	repoDetails *gitRepositoryDetails,
+	credentials json.RawMessage,
+	console input.Console) error {
+
+	repoSlug := repoDetails.owner + "/" + repoDetails.repoName
+	console.Message(ctx, fmt.Sprintf("Configuring repository %s.\n", repoSlug))
+
+	azureCredentials := azcli.AzureCredentials{}
+	if err := json.Unmarshal(credentials, &azureCredentials); err != nil {
+		return fmt.Errorf("reading the service principal of the credentials: %w", err)
+	}
+
+	branch, err := git.NewGitCli(ctx).GetCurrentBranch(ctx, repoDetails.gitProjectPath)
+	if err != nil {
+		return fmt.Errorf("getting current branch: %w", err)
+	}
+
+	azCli := azcli.GetAzCli(ctx)
+	for _, credential := range gitHubFederatedCredentials(repoSlug, branch) {
+		console.Message(ctx, fmt.Sprintf("Adding federated credential %s (%s).\n", credential.Name, credential.Subject))
+		if err := azCli.CreateOrUpdateFederatedCredential(ctx, azureCredentials.ClientId, credential); err != nil {
+			return fmt.Errorf("failed adding federated credential: %w", err)
+		}
+	}
+
+	ghCli := github.NewGitHubCli(ctx)
+	p.secrets = []string{}
+
+	values := map[string]string{
+		"AZURE_CLIENT_ID":                    azureCredentials.ClientId,
+		environment.TenantIdEnvVarName:       azureCredentials.TenantId,
+		environment.SubscriptionIdEnvVarName: azureCredentials.SubscriptionId,
+	}
+	names := append([]string{}, federatedIdentityVariables...)
+	for _, name := range managedIdentityVariables {
+		if _, has := values[name]; !has {
+			values[name] = azdEnvironment.Values[name]
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		console.Message(ctx, fmt.Sprintf("Setting %s GitHub repo %s.\n", name, gitHubValueKind(name)))
+		if err := p.setValue(ctx, ghCli, repoSlug, name, values[name]); err != nil {
+			return fmt.Errorf("failed setting %s %s: %w", name, gitHubValueKind(name), err)
+		}
+	}
+
+	return nil
+}
+
+// summarize describes the GitHub repository, its actions and the values set by configureConnection
+func (p *GitHubCiProvider) summarize(repoDetails *gitRepositoryDetails, summary *Summary) {
+	repoUrl := fmt.Sprintf("https://github.com/%s/%s", repoDetails.owner, repoDetails.repoName)
+	summary.RepositoryUrl = repoUrl
+	summary.PipelineUrl = repoUrl + "/actions"
+	summary.Secrets = p.secrets
+}
+
 // ensureGitHubLogin ensures the user is logged into the GitHub CLI. If not, it prompt the user
 // if they would like to log in and if so runs `gh auth login` interactively.
 func ensureGitHubLogin(ctx context.Context, hostname string, console input.Console) error {
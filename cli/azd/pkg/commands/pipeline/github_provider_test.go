@@ -5,24 +5,31 @@ package pipeline
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/stretchr/testify/require"
 )
 
 func Test_gitHub_provider_getRepoDetails(t *testing.T) {
 	t.Run("https", func(t *testing.T) {
 		provider := &GitHubScmProvider{}
-		ctx := context.Background()
-		details, e := provider.gitRepoDetails(ctx, "https://github.com/Azure/azure-dev.git")
+		mockContext := mocks.NewMockContext(context.Background())
+		mockViewRepository(mockContext, `{"nameWithOwner":"Azure/azure-dev","isFork":false,"parent":null}`)
+		details, e := provider.gitRepoDetails(*mockContext.Context, "https://github.com/Azure/azure-dev.git")
 		require.NoError(t, e)
 		require.Equal(t, "Azure", details.owner)
 		require.Equal(t, "azure-dev", details.repoName)
 	})
 	t.Run("ssh", func(t *testing.T) {
 		provider := &GitHubScmProvider{}
-		ctx := context.Background()
-		details, e := provider.gitRepoDetails(ctx, "git@github.com:Azure/azure-dev.git")
+		mockContext := mocks.NewMockContext(context.Background())
+		mockViewRepository(mockContext, `{"nameWithOwner":"Azure/azure-dev","isFork":false,"parent":null}`)
+		details, e := provider.gitRepoDetails(*mockContext.Context, "git@github.com:Azure/azure-dev.git")
 		require.NoError(t, e)
 		require.EqualValues(t, "Azure", details.owner)
 		require.EqualValues(t, "azure-dev", details.repoName)
@@ -34,4 +41,100 @@ func Test_gitHub_provider_getRepoDetails(t *testing.T) {
 		require.Error(t, e, ErrRemoteHostIsNotGitHub)
 		require.EqualValues(t, (*gitRepositoryDetails)(nil), details)
 	})
+	t.Run("new repository", func(t *testing.T) {
+		// The repository isn't looked up, the command runner would panic otherwise
+		provider := &GitHubScmProvider{newGitHubRepoCreated: true}
+		details, e := provider.gitRepoDetails(context.Background(), "https://github.com/Azure/azure-dev.git")
+		require.NoError(t, e)
+		require.Equal(t, "Azure", details.owner)
+		require.Equal(t, "azure-dev", details.repoName)
+	})
+}
+
+func Test_gitHub_provider_getRepoDetails_fork(t *testing.T) {
+	forkJson := `{
+		"nameWithOwner": "user/azure-dev",
+		"isFork": true,
+		"parent": {"name": "azure-dev", "owner": {"login": "Azure"}}
+	}`
+
+	tests := []struct {
+		name          string
+		selection     int
+		expectedOwner string
+	}{
+		{name: "fork", selection: 0, expectedOwner: "user"},
+		{name: "upstream", selection: 1, expectedOwner: "Azure"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider := &GitHubScmProvider{}
+			mockContext := mocks.NewMockContext(context.Background())
+			mockViewRepository(mockContext, forkJson)
+			mockContext.Console.WhenSelect(func(options input.ConsoleOptions) bool {
+				return strings.Contains(options.Message, "is a fork of Azure/azure-dev")
+			}).Respond(test.selection)
+
+			details, e := provider.gitRepoDetails(*mockContext.Context, "https://github.com/user/azure-dev.git")
+			require.NoError(t, e)
+			require.Equal(t, test.expectedOwner, details.owner)
+			require.Equal(t, "azure-dev", details.repoName)
+		})
+	}
+}
+
+func mockViewRepository(mockContext *mocks.MockContext, repoJson string) {
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "gh repo view")
+	}).Respond(exec.NewRunResult(0, repoJson, ""))
+}
+
+func Test_gitHub_provider_setPipelineVariable(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	var secretArgs []string
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "secret set")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		secretArgs = args.Args
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	provider := &GitHubCiProvider{}
+	err := provider.setPipelineVariable(
+		*mockContext.Context, &gitRepositoryDetails{owner: "Azure", repoName: "azure-dev"}, "KEY", "value")
+	require.NoError(t, err)
+	require.Equal(t, []string{"-R", "Azure/azure-dev", "secret", "set", "KEY", "--body", "value"}, secretArgs)
+}
+
+func Test_gitHub_provider_setPipelineVariable_variable(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	commands := []string{}
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "gh api --method PATCH")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(1, "", "gh: Not Found (HTTP 404)"), errors.New("exit code: 1")
+	})
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "gh api --method POST")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		commands = append(commands, strings.Join(args.Args, " "))
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	provider := &GitHubCiProvider{}
+	err := provider.setPipelineVariable(
+		*mockContext.Context, &gitRepositoryDetails{owner: "Azure", repoName: "azure-dev"}, "AZURE_LOCATION", "eastus")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"api --method POST /repos/Azure/azure-dev/actions/variables -f name=AZURE_LOCATION -f value=eastus",
+	}, commands)
+}
+
+func Test_gitHubExpression(t *testing.T) {
+	require.Equal(t, "${{ vars.AZURE_ENV_NAME }}", gitHubExpression("AZURE_ENV_NAME"))
+	require.Equal(t, "${{ vars.RS_STORAGE_ACCOUNT }}", gitHubExpression("RS_STORAGE_ACCOUNT"))
+	require.Equal(t, "${{ secrets.AZURE_CREDENTIALS }}", gitHubExpression("AZURE_CREDENTIALS"))
+	require.Equal(t, "${{ secrets.ARM_CLIENT_SECRET }}", gitHubExpression("ARM_CLIENT_SECRET"))
+	require.Equal(t, "${{ secrets.MY_API_KEY }}", gitHubExpression("MY_API_KEY"))
 }
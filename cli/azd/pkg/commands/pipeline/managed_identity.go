@@ -0,0 +1,117 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+)
+
+// The variable holding the client id of the managed identity the pipeline agent signs in with
+const managedIdentityClientIdVariable = "AZURE_CLIENT_ID"
+
+// The variables of the environment azd runs in, set with the client id of the managed identity in the pipeline
+var managedIdentityVariables = []string{
+	environment.EnvNameEnvVarName,
+	environment.LocationEnvVarName,
+	environment.SubscriptionIdEnvVarName,
+}
+
+// the data of the templates of the definitions signing in with the managed identity of the agent
+type managedIdentityTemplateData struct {
+	ClientIdVariable string
+	Variables        []string
+}
+
+func newManagedIdentityTemplateData() managedIdentityTemplateData {
+	return managedIdentityTemplateData{
+		ClientIdVariable: managedIdentityClientIdVariable,
+		Variables:        managedIdentityVariables,
+	}
+}
+
+// The GitHub workflow running on a self-hosted runner, signing in with the managed identity of the runner
+const gitHubManagedIdentityWorkflowTemplate = `# Generated by azd pipeline config --managed-identity
+# Runs on a self-hosted runner hosted in Azure, with az and azd installed, signing in with the managed identity of the
+# runner.
+on:
+  workflow_dispatch:
+  push:
+    branches:
+      - main
+      - master
+
+jobs:
+  build:
+    runs-on: self-hosted
+    steps:
+      - name: Checkout
+        uses: actions/checkout@v2
+
+      - name: Log in with the managed identity of the runner
+        run: az login --identity --username [[ github .ClientIdVariable ]]
+
+      - name: Azure Dev Provision
+        run: azd provision --no-prompt
+        env:
+[[- range .Variables ]]
+          [[ . ]]: [[ github . ]]
+[[- end ]]
+
+      - name: Azure Dev Deploy
+        run: azd deploy --no-prompt
+        env:
+[[- range .Variables ]]
+          [[ . ]]: [[ github . ]]
+[[- end ]]
+`
+
+// The Azure DevOps pipeline running on a self-hosted agent, signing in with the managed identity of the agent
+const azdoManagedIdentityPipelineTemplate = `# Generated by azd pipeline config --managed-identity
+# Runs on a self-hosted agent of the Default pool hosted in Azure, with az and azd installed, signing in with the
+# managed identity of the agent.
+trigger:
+  - main
+  - master
+
+pool:
+  name: Default
+
+steps:
+  - bash: az login --identity --username $([[ .ClientIdVariable ]])
+    displayName: Log in with the managed identity of the agent
+
+  - bash: azd provision --no-prompt
+    displayName: Azure Dev Provision
+    env:
+[[- range .Variables ]]
+      [[ . ]]: $([[ . ]])
+[[- end ]]
+
+  - bash: azd deploy --no-prompt
+    displayName: Azure Dev Deploy
+    env:
+[[- range .Variables ]]
+      [[ . ]]: $([[ . ]])
+[[- end ]]
+`
+
+// gitHubManagedIdentityWorkflow returns the GitHub workflow signing in with the managed identity of the runner
+func gitHubManagedIdentityWorkflow() ([]byte, error) {
+	return executeDefinitionTemplate(
+		"github managed identity workflow",
+		gitHubManagedIdentityWorkflowTemplate,
+		gitHubTemplateFuncs,
+		newManagedIdentityTemplateData(),
+	)
+}
+
+// azdoManagedIdentityPipeline returns the Azure DevOps pipeline signing in with the managed identity of the agent
+func azdoManagedIdentityPipeline() ([]byte, error) {
+	return executeDefinitionTemplate(
+		"azure devops managed identity pipeline",
+		azdoManagedIdentityPipelineTemplate,
+		gitHubTemplateFuncs,
+		newManagedIdentityTemplateData(),
+	)
+}
@@ -0,0 +1,99 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_gitHubManagedIdentityWorkflow(t *testing.T) {
+	content, err := gitHubManagedIdentityWorkflow()
+	require.NoError(t, err)
+
+	var workflow struct {
+		Jobs map[string]struct {
+			RunsOn string `yaml:"runs-on"`
+			Steps  []struct {
+				Run string            `yaml:"run"`
+				Env map[string]string `yaml:"env"`
+			} `yaml:"steps"`
+		} `yaml:"jobs"`
+	}
+	require.NoError(t, yaml.Unmarshal(content, &workflow))
+
+	job := workflow.Jobs["build"]
+	require.Equal(t, "self-hosted", job.RunsOn)
+	require.Equal(t, "az login --identity --username ${{ vars.AZURE_CLIENT_ID }}", job.Steps[1].Run)
+	require.Equal(t, "azd provision --no-prompt", job.Steps[2].Run)
+	require.Equal(t, map[string]string{
+		"AZURE_ENV_NAME":        "${{ vars.AZURE_ENV_NAME }}",
+		"AZURE_LOCATION":        "${{ vars.AZURE_LOCATION }}",
+		"AZURE_SUBSCRIPTION_ID": "${{ vars.AZURE_SUBSCRIPTION_ID }}",
+	}, job.Steps[2].Env)
+}
+
+func Test_azdoManagedIdentityPipeline(t *testing.T) {
+	content, err := azdoManagedIdentityPipeline()
+	require.NoError(t, err)
+
+	var pipeline struct {
+		Pool struct {
+			Name string `yaml:"name"`
+		} `yaml:"pool"`
+		Steps []struct {
+			Bash string            `yaml:"bash"`
+			Env  map[string]string `yaml:"env"`
+		} `yaml:"steps"`
+	}
+	require.NoError(t, yaml.Unmarshal(content, &pipeline))
+
+	require.Equal(t, "Default", pipeline.Pool.Name)
+	require.Equal(t, "az login --identity --username $(AZURE_CLIENT_ID)", pipeline.Steps[0].Bash)
+	require.Equal(t, "azd deploy --no-prompt", pipeline.Steps[2].Bash)
+	require.Equal(t, "$(AZURE_ENV_NAME)", pipeline.Steps[2].Env["AZURE_ENV_NAME"])
+}
+
+func Test_gitHub_provider_configureManagedIdentityConnection(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	commands := []string{}
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.HasPrefix(command, "gh")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		commands = append(commands, strings.Join(args.Args, " "))
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	env := environment.EphemeralWithValues("app", map[string]string{
+		environment.LocationEnvVarName:       "eastus",
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+	})
+
+	provider := &GitHubCiProvider{}
+	err := provider.configureManagedIdentityConnection(
+		*mockContext.Context,
+		env,
+		&gitRepositoryDetails{owner: "Azure", repoName: "azure-dev"},
+		"CLIENT_ID",
+		mockContext.Console,
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"api --method PATCH /repos/Azure/azure-dev/actions/variables/AZURE_CLIENT_ID -f name=AZURE_CLIENT_ID -f value=CLIENT_ID",
+		"api --method PATCH /repos/Azure/azure-dev/actions/variables/AZURE_ENV_NAME -f name=AZURE_ENV_NAME -f value=app",
+		"api --method PATCH /repos/Azure/azure-dev/actions/variables/AZURE_LOCATION -f name=AZURE_LOCATION -f value=eastus",
+		"api --method PATCH /repos/Azure/azure-dev/actions/variables/AZURE_SUBSCRIPTION_ID " +
+			"-f name=AZURE_SUBSCRIPTION_ID -f value=SUBSCRIPTION_ID",
+	}, commands)
+	require.Equal(t, []string{
+		"AZURE_CLIENT_ID", "AZURE_ENV_NAME", "AZURE_LOCATION", "AZURE_SUBSCRIPTION_ID",
+	}, provider.secrets)
+}
@@ -0,0 +1,306 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azdo"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+)
+
+// The names of the partitions of a partitioned pipeline, the suffixes of their definition files
+const (
+	partitionInfra = "infra"
+	partitionCode  = "code"
+)
+
+// The project file, its changes run the pipelines of every partition
+const projectFileName = "azure.yaml"
+
+// pipelinePartition is a part of the project with its own pipeline, run for the changes of the paths of the part
+// instead of for every change of the repository
+type pipelinePartition struct {
+	name string
+	// the flag of azd pipeline config generating the definition of the partition
+	flag string
+	// what the pipeline does, commented at the top of its definition
+	description string
+	// the folders of the partition, relative to the project directory and separated with '/'. The pipeline runs for
+	// every change when empty.
+	paths []string
+	steps []partitionStep
+}
+
+// partitionStep is an azd command run by the pipeline of a partition
+type partitionStep struct {
+	DisplayName string
+	Command     string
+}
+
+// loadPartitions returns the partitions of the pipeline selected by --infra-only and --code-only, nil when the
+// pipeline isn't partitioned. The infrastructure runs for the changes of the infra folder, the code for the changes of
+// the folders of the services.
+func loadPartitions(infraOnly bool, codeOnly bool, prj *project.ProjectConfig) []*pipelinePartition {
+	var partitions []*pipelinePartition
+
+	if infraOnly {
+		infraPath := prj.Infra.Path
+		if infraPath == "" {
+			infraPath = azdcontext.InfraDirectoryName
+		}
+
+		partitions = append(partitions, &pipelinePartition{
+			name:        partitionInfra,
+			flag:        "--infra-only",
+			description: "Provisions the infrastructure of the project when the infrastructure changes.",
+			paths:       partitionPaths([]string{infraPath}),
+			steps:       []partitionStep{{DisplayName: "Azure Dev Provision", Command: "provision"}},
+		})
+	}
+
+	if codeOnly {
+		servicePaths := []string{}
+		for _, service := range prj.Services {
+			servicePaths = append(servicePaths, service.RelativePath)
+		}
+		if len(servicePaths) == 0 {
+			servicePaths = append(servicePaths, "src")
+		}
+
+		partitions = append(partitions, &pipelinePartition{
+			name:        partitionCode,
+			flag:        "--code-only",
+			description: "Deploys the services of the project to the provisioned infrastructure when their code changes.",
+			paths:       partitionPaths(servicePaths),
+			// The outputs of the last provisioning are loaded first, the code is deployed to the provisioned resources
+			steps: []partitionStep{
+				{DisplayName: "Refresh the environment", Command: "env refresh"},
+				{DisplayName: "Azure Dev Deploy", Command: "deploy"},
+			},
+		})
+	}
+
+	return partitions
+}
+
+// partitionPaths returns the sorted folders without duplicates, separated with '/'. Returns nil when a folder is the
+// project directory, every change of the project is a change of the partition.
+func partitionPaths(folders []string) []string {
+	unique := map[string]bool{}
+	for _, folder := range folders {
+		cleaned := path.Clean(filepath.ToSlash(strings.TrimSpace(folder)))
+		cleaned = strings.TrimPrefix(cleaned, "./")
+		if cleaned == "." || cleaned == "" {
+			return nil
+		}
+
+		unique[strings.TrimSuffix(cleaned, "/")] = true
+	}
+
+	paths := make([]string, 0, len(unique))
+	for folder := range unique {
+		paths = append(paths, folder)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
+// the data of the templates of the definitions of the partitions
+type partitionTemplateData struct {
+	Flag        string
+	Partition   string
+	Description string
+	// the path filters of the trigger, empty when the pipeline runs for every change
+	Paths     []string
+	Steps     []partitionStep
+	Federated bool
+	// the names of the variables of the environment azd runs in
+	Variables []string
+	// the service connection the Azure DevOps pipeline signs in with
+	ServiceConnection string
+}
+
+func newPartitionTemplateData(
+	partition *pipelinePartition,
+	provisioningProvider provisioning.Options,
+	authType AuthType,
+	terraformVariables []string,
+	pathFilter func(folder string) string,
+) partitionTemplateData {
+	data := partitionTemplateData{
+		Flag:              partition.flag,
+		Partition:         partition.name,
+		Description:       partition.description,
+		Steps:             partition.steps,
+		Federated:         authType == AuthTypeFederated,
+		ServiceConnection: azdo.ServiceConnectionName,
+	}
+
+	if len(partition.paths) > 0 {
+		for _, folder := range partition.paths {
+			data.Paths = append(data.Paths, pathFilter(folder))
+		}
+		data.Paths = append(data.Paths, projectFileName)
+	}
+
+	// The subscription is a variable of both the principal and the environment, it's only set once
+	if data.Federated {
+		data.Variables = append(data.Variables, federatedIdentityVariables...)
+	}
+	for _, name := range managedIdentityVariables {
+		if !data.Federated || name != environment.SubscriptionIdEnvVarName {
+			data.Variables = append(data.Variables, name)
+		}
+	}
+
+	// Refreshing the environment reads the Terraform state, the variables are set for both partitions
+	if provisioningProvider.Provider == provisioning.Terraform {
+		data.Variables = append(data.Variables, terraformVariables...)
+	}
+
+	return data
+}
+
+// The GitHub workflow of a partition, run for the pushes changing the paths of the partition
+const gitHubPartitionWorkflowTemplate = `# Generated by azd pipeline config [[ .Flag ]]
+# [[ .Description ]]
+on:
+  workflow_dispatch:
+  push:
+    branches:
+      - main
+      - master
+[[- if .Paths ]]
+    paths:
+[[- range .Paths ]]
+      - '[[ . ]]'
+[[- end ]]
+[[- end ]]
+[[- if .Federated ]]
+
+permissions:
+  id-token: write
+  contents: read
+[[- end ]]
+
+jobs:
+  [[ .Partition ]]:
+    runs-on: ubuntu-latest
+    container:
+      image: mcr.microsoft.com/azure-dev-cli-apps:latest
+    env:
+[[- range .Variables ]]
+      [[ . ]]: [[ github . ]]
+[[- end ]]
+    steps:
+      - name: Checkout
+        uses: actions/checkout@v2
+[[ if .Federated ]]
+      - name: Log in with Azure (Federated Credentials)
+        uses: azure/login@v1
+        with:
+          client-id: ${{ env.AZURE_CLIENT_ID }}
+          tenant-id: ${{ env.AZURE_TENANT_ID }}
+          subscription-id: ${{ env.AZURE_SUBSCRIPTION_ID }}
+[[- else ]]
+      - name: Log in with Azure
+        uses: azure/login@v1
+        with:
+          creds: ${{ secrets.AZURE_CREDENTIALS }}
+[[- end ]]
+[[- range .Steps ]]
+
+      - name: [[ .DisplayName ]]
+        run: azd [[ .Command ]] --no-prompt
+[[- end ]]
+`
+
+// The Azure DevOps pipeline of a partition, run for the pushes changing the paths of the partition. The pipeline signs
+// in with the service connection whatever its auth type.
+const azdoPartitionPipelineTemplate = `# Generated by azd pipeline config [[ .Flag ]]
+# [[ .Description ]]
+trigger:
+  branches:
+    include:
+      - main
+      - master
+[[- if .Paths ]]
+  paths:
+    include:
+[[- range .Paths ]]
+      - [[ . ]]
+[[- end ]]
+[[- end ]]
+
+pool:
+  vmImage: ubuntu-latest
+
+jobs:
+  - job: [[ .Partition ]]
+    container: mcr.microsoft.com/azure-dev-cli-apps:latest
+    steps:
+[[- range .Steps ]]
+      - task: AzureCLI@2
+        displayName: [[ .DisplayName ]]
+        inputs:
+          azureSubscription: [[ $.ServiceConnection ]]
+          scriptType: bash
+          scriptLocation: inlineScript
+          inlineScript: |
+            azd [[ .Command ]] --no-prompt
+        env:
+[[- range $.Variables ]]
+          [[ . ]]: $([[ . ]])
+[[- end ]]
+[[- end ]]
+`
+
+// gitHubPartitionWorkflow returns the GitHub workflow of the partition
+func gitHubPartitionWorkflow(
+	partition *pipelinePartition,
+	provisioningProvider provisioning.Options,
+	authType AuthType,
+) ([]byte, error) {
+	data := newPartitionTemplateData(
+		partition,
+		provisioningProvider,
+		authType,
+		[]string{
+			"ARM_TENANT_ID", "ARM_CLIENT_ID", "ARM_CLIENT_SECRET",
+			"RS_RESOURCE_GROUP", "RS_STORAGE_ACCOUNT", "RS_CONTAINER_NAME",
+		},
+		// GitHub filters the paths with globs
+		func(folder string) string { return folder + "/**" },
+	)
+
+	return executeDefinitionTemplate(
+		"github partition workflow", gitHubPartitionWorkflowTemplate, gitHubTemplateFuncs, data)
+}
+
+// azdoPartitionPipeline returns the Azure DevOps pipeline of the partition
+func azdoPartitionPipeline(
+	partition *pipelinePartition,
+	provisioningProvider provisioning.Options,
+	authType AuthType,
+) ([]byte, error) {
+	data := newPartitionTemplateData(
+		partition,
+		provisioningProvider,
+		authType,
+		[]string{"ARM_TENANT_ID", "ARM_CLIENT_ID", "ARM_CLIENT_SECRET"},
+		// Azure DevOps filters the paths by prefix
+		func(folder string) string { return folder },
+	)
+
+	return executeDefinitionTemplate(
+		"azure devops partition pipeline", azdoPartitionPipelineTemplate, gitHubTemplateFuncs, data)
+}
@@ -0,0 +1,158 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_loadPartitions(t *testing.T) {
+	prj := &project.ProjectConfig{
+		Services: map[string]*project.ServiceConfig{
+			"web": {RelativePath: "./src/web"},
+			"api": {RelativePath: "src/api/"},
+		},
+	}
+
+	require.Nil(t, loadPartitions(false, false, prj))
+
+	partitions := loadPartitions(true, true, prj)
+	require.Len(t, partitions, 2)
+	require.Equal(t, partitionInfra, partitions[0].name)
+	require.Equal(t, []string{"infra"}, partitions[0].paths)
+	require.Equal(t, partitionCode, partitions[1].name)
+	require.Equal(t, []string{"src/api", "src/web"}, partitions[1].paths)
+
+	t.Run("CustomInfraPath", func(t *testing.T) {
+		prj := &project.ProjectConfig{Infra: provisioning.Options{Path: "deploy/bicep"}}
+		partitions := loadPartitions(true, false, prj)
+		require.Len(t, partitions, 1)
+		require.Equal(t, []string{"deploy/bicep"}, partitions[0].paths)
+	})
+
+	t.Run("NoServices", func(t *testing.T) {
+		partitions := loadPartitions(false, true, &project.ProjectConfig{})
+		require.Equal(t, []string{"src"}, partitions[0].paths)
+	})
+
+	t.Run("ServiceAtProjectRoot", func(t *testing.T) {
+		prj := &project.ProjectConfig{
+			Services: map[string]*project.ServiceConfig{"web": {RelativePath: "."}},
+		}
+		partitions := loadPartitions(false, true, prj)
+		require.Nil(t, partitions[0].paths)
+	})
+}
+
+type gitHubPartitionWorkflowYaml struct {
+	On struct {
+		Push struct {
+			Paths []string `yaml:"paths"`
+		} `yaml:"push"`
+	} `yaml:"on"`
+	Permissions map[string]string `yaml:"permissions"`
+	Jobs        map[string]struct {
+		Env   map[string]string `yaml:"env"`
+		Steps []struct {
+			Run  string            `yaml:"run"`
+			With map[string]string `yaml:"with"`
+		} `yaml:"steps"`
+	} `yaml:"jobs"`
+}
+
+func Test_gitHubPartitionWorkflow(t *testing.T) {
+	prj := &project.ProjectConfig{
+		Services: map[string]*project.ServiceConfig{"api": {RelativePath: "src/api"}},
+	}
+	partitions := loadPartitions(true, true, prj)
+
+	t.Run("Infra", func(t *testing.T) {
+		content, err := gitHubPartitionWorkflow(partitions[0], provisioning.Options{}, AuthTypeClientSecret)
+		require.NoError(t, err)
+
+		var workflow gitHubPartitionWorkflowYaml
+		require.NoError(t, yaml.Unmarshal(content, &workflow))
+
+		require.Equal(t, []string{"infra/**", "azure.yaml"}, workflow.On.Push.Paths)
+		require.Nil(t, workflow.Permissions)
+		job := workflow.Jobs["infra"]
+		require.Equal(t, "${{ secrets.AZURE_CREDENTIALS }}", job.Steps[1].With["creds"])
+		require.Equal(t, "azd provision --no-prompt", job.Steps[2].Run)
+		require.Len(t, job.Steps, 3)
+		require.Equal(t, map[string]string{
+			"AZURE_ENV_NAME":        "${{ vars.AZURE_ENV_NAME }}",
+			"AZURE_LOCATION":        "${{ vars.AZURE_LOCATION }}",
+			"AZURE_SUBSCRIPTION_ID": "${{ vars.AZURE_SUBSCRIPTION_ID }}",
+		}, job.Env)
+	})
+
+	t.Run("CodeFederated", func(t *testing.T) {
+		content, err := gitHubPartitionWorkflow(partitions[1], provisioning.Options{}, AuthTypeFederated)
+		require.NoError(t, err)
+
+		var workflow gitHubPartitionWorkflowYaml
+		require.NoError(t, yaml.Unmarshal(content, &workflow))
+
+		require.Equal(t, []string{"src/api/**", "azure.yaml"}, workflow.On.Push.Paths)
+		require.Equal(t, "write", workflow.Permissions["id-token"])
+		job := workflow.Jobs["code"]
+		require.Equal(t, "${{ env.AZURE_CLIENT_ID }}", job.Steps[1].With["client-id"])
+		require.Equal(t, "azd env refresh --no-prompt", job.Steps[2].Run)
+		require.Equal(t, "azd deploy --no-prompt", job.Steps[3].Run)
+		require.Equal(t, "${{ vars.AZURE_CLIENT_ID }}", job.Env["AZURE_CLIENT_ID"])
+		require.Equal(t, "${{ vars.AZURE_ENV_NAME }}", job.Env["AZURE_ENV_NAME"])
+	})
+
+	t.Run("Terraform", func(t *testing.T) {
+		content, err := gitHubPartitionWorkflow(
+			partitions[1], provisioning.Options{Provider: provisioning.Terraform}, AuthTypeClientSecret)
+		require.NoError(t, err)
+
+		var workflow gitHubPartitionWorkflowYaml
+		require.NoError(t, yaml.Unmarshal(content, &workflow))
+		require.Equal(t, "${{ secrets.ARM_CLIENT_SECRET }}", workflow.Jobs["code"].Env["ARM_CLIENT_SECRET"])
+	})
+}
+
+func Test_azdoPartitionPipeline(t *testing.T) {
+	partitions := loadPartitions(false, true, &project.ProjectConfig{})
+	content, err := azdoPartitionPipeline(partitions[0], provisioning.Options{}, AuthTypeFederated)
+	require.NoError(t, err)
+
+	var pipeline struct {
+		Trigger struct {
+			Branches struct {
+				Include []string `yaml:"include"`
+			} `yaml:"branches"`
+			Paths struct {
+				Include []string `yaml:"include"`
+			} `yaml:"paths"`
+		} `yaml:"trigger"`
+		Jobs []struct {
+			Job   string `yaml:"job"`
+			Steps []struct {
+				DisplayName string            `yaml:"displayName"`
+				Inputs      map[string]string `yaml:"inputs"`
+				Env         map[string]string `yaml:"env"`
+			} `yaml:"steps"`
+		} `yaml:"jobs"`
+	}
+	require.NoError(t, yaml.Unmarshal(content, &pipeline))
+
+	require.Equal(t, []string{"main", "master"}, pipeline.Trigger.Branches.Include)
+	require.Equal(t, []string{"src", "azure.yaml"}, pipeline.Trigger.Paths.Include)
+	require.Equal(t, "code", pipeline.Jobs[0].Job)
+	require.Len(t, pipeline.Jobs[0].Steps, 2)
+
+	deploy := pipeline.Jobs[0].Steps[1]
+	require.Equal(t, "Azure Dev Deploy", deploy.DisplayName)
+	require.Equal(t, "azconnection", deploy.Inputs["azureSubscription"])
+	require.Equal(t, "azd deploy --no-prompt\n", deploy.Inputs["inlineScript"])
+	require.Equal(t, "$(AZURE_ENV_NAME)", deploy.Env["AZURE_ENV_NAME"])
+}
@@ -7,16 +7,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"os"
 	"path"
 	"strings"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
 )
 
 // subareaProvider defines the base behavior from any pipeline provider
@@ -64,7 +67,8 @@ type ScmProvider interface {
 		remoteName string,
 		branchName string,
 		console input.Console) (bool, error)
-	//Hook function to allow SCM providers to handle scenarios after the git push is complete
+	//Hook function to allow SCM providers to handle scenarios after the git push is complete. With --skip-push, it's
+	//invoked without a push and gitRepo.pushStatus is false.
 	postGitPush(ctx context.Context,
 		gitRepo *gitRepositoryDetails,
 		remoteName string,
@@ -91,6 +95,80 @@ type CiProvider interface {
 		provisioningProvider provisioning.Options,
 		credential json.RawMessage,
 		console input.Console) error
+	// setPipelineVariable sets the value of an environment variable in the configured pipeline
+	setPipelineVariable(ctx context.Context, repoDetails *gitRepositoryDetails, name string, value string) error
+	// configureStageConnection sets up the connection of a stage of a multi-stage pipeline to Azure, using the
+	// credential and the environment of the stage
+	configureStageConnection(
+		ctx context.Context,
+		gitRepo *gitRepositoryDetails,
+		provisioningProvider provisioning.Options,
+		stage *pipelineStage,
+		console input.Console) error
+	// stagesDefinition returns the path, relative to the project directory, and the content of the definition of the
+	// multi-stage pipeline
+	stagesDefinition(stages []*pipelineStage, provisioningProvider provisioning.Options) (string, []byte, error)
+	// configureStagesPipeline set up or create the multi-stage CI pipeline
+	configureStagesPipeline(
+		ctx context.Context,
+		repoDetails *gitRepositoryDetails,
+		provisioningProvider provisioning.Options,
+		stages []*pipelineStage,
+	) error
+	// rotationDefinition returns the path, relative to the project directory, and the content of the definition of the
+	// pipeline rotating the credential of the principal on the cron schedule
+	rotationDefinition(schedule string, principalName string) (string, []byte, error)
+	// configureRotationPipeline set up or create the CI pipeline rotating the credential
+	configureRotationPipeline(ctx context.Context, repoDetails *gitRepositoryDetails) error
+	// managedIdentityDefinition returns the path, relative to the project directory, and the content of the definition
+	// of the pipeline signing in with the managed identity of its self-hosted agent
+	managedIdentityDefinition() (string, []byte, error)
+	// configureManagedIdentityConnection sets the client id of the managed identity of the agent, and the environment
+	// azd runs in, in the pipeline
+	configureManagedIdentityConnection(
+		ctx context.Context,
+		azdEnvironment *environment.Environment,
+		gitRepo *gitRepositoryDetails,
+		clientId string,
+		console input.Console) error
+	// configureManagedIdentityPipeline set up or create the CI pipeline signing in with the managed identity
+	configureManagedIdentityPipeline(ctx context.Context, repoDetails *gitRepositoryDetails, clientId string) error
+	// federatedDefinition returns the path, relative to the project directory, and the content of the definition of the
+	// pipeline signing in with federated credentials, an empty path when the definition signs in the same way with
+	// either auth type
+	federatedDefinition() (string, []byte, error)
+	// configureFederatedConnection sets up the connection from the pipeline to Azure, signing in with the tokens of the
+	// provider, and adds the federated credentials trusting them to the service principal of the credential
+	configureFederatedConnection(
+		ctx context.Context,
+		azdEnvironment *environment.Environment,
+		gitRepo *gitRepositoryDetails,
+		credential json.RawMessage,
+		console input.Console) error
+	// partitionDefinition returns the path, relative to the project directory, and the content of the definition of the
+	// pipeline of the partition, signing in with the auth type
+	partitionDefinition(
+		partition *pipelinePartition,
+		provisioningProvider provisioning.Options,
+		authType AuthType,
+	) (string, []byte, error)
+	// configurePartitionPipeline set up or create the CI pipeline of the partition
+	configurePartitionPipeline(
+		ctx context.Context,
+		repoDetails *gitRepositoryDetails,
+		provisioningProvider provisioning.Options,
+		partition *pipelinePartition,
+	) error
+	// previewDefinition returns the path, relative to the project directory, and the content of the definition of the
+	// pipeline deploying each pull request to its own environment, signing in with the auth type
+	previewDefinition(provisioningProvider provisioning.Options, authType AuthType) (string, []byte, error)
+	// cleanupDefinition returns the path, relative to the project directory, and the content of the definition of the
+	// pipeline deleting the expired environments on a schedule, signing in with the auth type
+	cleanupDefinition(authType AuthType) (string, []byte, error)
+	// configureCleanupPipeline set up or create the CI pipeline deleting the expired environments
+	configureCleanupPipeline(ctx context.Context, repoDetails *gitRepositoryDetails) error
+	// summarize describes the configured pipeline, its repository and the secrets set for it in the summary
+	summarize(repoDetails *gitRepositoryDetails, summary *Summary)
 }
 
 func folderExists(folderPath string) bool {
@@ -108,16 +186,23 @@ const (
 	envPersistedKey string = "AZD_PIPELINE_PROVIDER"
 )
 
+// The user config path of the pipeline provider used when a project does not select one,
+// i.e. `azd config set defaults.pipeline.provider azdo`
+const DefaultProviderConfigPath = "defaults.pipeline.provider"
+
 // DetectProviders get azd context from the context and pulls the project directory from it.
 // Depending on the project directory, returns pipeline scm and ci providers based on:
 //   - if .github folder is found and .azdo folder is missing: GitHub scm and ci as provider
 //   - if .azdo folder is found and .github folder is missing: Azdo scm and ci as provider
-//   - both .github and .azdo folders found: GitHub scm and ci as provider
+//   - both .github and .azdo folders found: the provider hosting the git remote, confirmed by the user, or GitHub scm
+//     and ci as provider when the remote isn't hosted by GitHub or Azure DevOps
 //   - overrideProvider set to github (regardless of folders): GitHub scm and ci as provider
 //   - overrideProvider set to azdo (regardless of folders): Azdo scm and ci as provider
 //   - none of the folders found: return error
 //   - no azd context in the ctx: return error
 //   - overrideProvider set to neither github or azdo: return error
+//   - no provider selected by the project or a previous run: the user default provider from azd config is used
+//   - the git remote is hosted by a provider without its folder: the user is told the provider of the folder is used
 //   - Note: The provider is persisted in the environment so the next time the function is run
 //     the same provider is used directly, unless the overrideProvider is used to change
 //     the last used configuration
@@ -125,6 +210,8 @@ func DetectProviders(
 	ctx context.Context,
 	azdContext *azdcontext.AzdContext,
 	env *environment.Environment,
+	userConfig config.Config,
+	remoteName string,
 	overrideProvider string) (ScmProvider, CiProvider, error) {
 	projectDir := azdContext.ProjectDirectory()
 
@@ -163,6 +250,17 @@ func DetectProviders(
 			overrideWith = prj.Pipeline.Provider
 		}
 
+		if overrideWith == "" {
+			overrideWith = userDefaultProvider(userConfig)
+		}
+
+		if overrideWith == "" {
+			overrideWith, err = detectProviderFromRemote(
+				ctx, projectDir, remoteName, hasGitHubFolder, hasAzDevOpsFolder, input.GetConsole(ctx))
+			if err != nil {
+				return nil, nil, err
+			}
+		}
 	}
 
 	// Check override errors for missing folder
@@ -198,6 +296,87 @@ func DetectProviders(
 	return &GitHubScmProvider{}, &GitHubCiProvider{}, nil
 }
 
+// display names of the providers
+var providerDisplayNames = map[string]string{
+	gitHubLabel: "GitHub",
+	azdoLabel:   "Azure DevOps",
+}
+
+// folders of the pipeline definitions of the providers
+var providerFolders = map[string]string{
+	gitHubLabel: githubFolder,
+	azdoLabel:   azdoFolder,
+}
+
+// detectProviderFromRemote returns the provider hosting the git remote of the project when the project has the folder
+// of that provider, confirmed by the user when the project has the folders of both providers. An empty string is
+// returned when the provider can't be detected.
+func detectProviderFromRemote(
+	ctx context.Context,
+	projectDir string,
+	remoteName string,
+	hasGitHubFolder bool,
+	hasAzDevOpsFolder bool,
+	console input.Console,
+) (string, error) {
+	remoteUrl, err := git.NewGitCli(ctx).GetRemoteUrl(ctx, projectDir, remoteName)
+	if err != nil {
+		log.Printf("detecting pipeline provider from remote '%s': %v", remoteName, err)
+		return "", nil
+	}
+
+	remoteProvider, otherProvider, hasRemoteFolder := "", "", false
+	if isAzDoRemote(remoteUrl) == nil {
+		remoteProvider, otherProvider, hasRemoteFolder = azdoLabel, gitHubLabel, hasAzDevOpsFolder
+	} else if gitHubRemoteGitUrlRegex.MatchString(remoteUrl) || gitHubRemoteHttpsUrlRegex.MatchString(remoteUrl) {
+		remoteProvider, otherProvider, hasRemoteFolder = gitHubLabel, azdoLabel, hasGitHubFolder
+	} else {
+		log.Printf("remote '%s' is not hosted by a known pipeline provider: %s", remoteName, remoteUrl)
+		return "", nil
+	}
+
+	if !hasRemoteFolder {
+		console.Message(ctx, fmt.Sprintf(
+			"The remote '%s' is hosted by %s, but the project has no %s folder. Using %s instead.",
+			remoteName,
+			providerDisplayNames[remoteProvider],
+			providerFolders[remoteProvider],
+			providerDisplayNames[otherProvider]))
+		return otherProvider, nil
+	}
+
+	// Only the provider of the remote can be used
+	if !hasGitHubFolder || !hasAzDevOpsFolder {
+		return remoteProvider, nil
+	}
+
+	useRemoteProvider, err := console.Confirm(ctx, input.ConsoleOptions{
+		Message: fmt.Sprintf(
+			"The remote '%s' is hosted by %s. Would you like to use %s as the pipeline provider?",
+			remoteName, providerDisplayNames[remoteProvider], providerDisplayNames[remoteProvider]),
+		DefaultValue: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("prompting for pipeline provider: %w", err)
+	}
+
+	if !useRemoteProvider {
+		return otherProvider, nil
+	}
+
+	return remoteProvider, nil
+}
+
+// userDefaultProvider returns the default pipeline provider from the azd user config, if any
+func userDefaultProvider(userConfig config.Config) string {
+	value, ok := userConfig.Get(DefaultProviderConfigPath)
+	if !ok {
+		return ""
+	}
+
+	return strings.ToLower(strings.TrimSpace(fmt.Sprint(value)))
+}
+
 func savePipelineProviderToEnv(provider string, env *environment.Environment) error {
 	env.Values[envPersistedKey] = provider
 	err := env.Save()
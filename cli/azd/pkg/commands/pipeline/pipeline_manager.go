@@ -4,21 +4,47 @@
 package pipeline
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
-	"time"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/events"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/fields"
+	"github.com/azure/azure-dev/cli/azd/pkg/azdo"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/eventlog"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
-	"github.com/sethvargo/go-retry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Names of the pipeline config steps, used for telemetry events.
+const (
+	stepPreConfigure = "preconfigure"
+	stepPrincipal    = "principal"
+	stepRemote       = "remote"
+	stepConnection   = "connection"
+	stepPipeline     = "pipeline"
+	stepPush         = "push"
 )
 
 type PipelineManagerArgs struct {
@@ -26,6 +52,105 @@ type PipelineManagerArgs struct {
 	PipelineRemoteName           string
 	PipelineRoleName             string
 	PipelineProvider             string
+	// Resume reuses the resources created by a previously interrupted configuration
+	Resume bool
+	// SkipPush configures the pipeline on the existing remote without any git operation, the changes are pushed by
+	// the user
+	SkipPush bool
+	// AzdoProjectId is the id of the Azure DevOps project of the pipeline, which is used instead of selecting a project
+	AzdoProjectId string
+	// RotateCredentials resets the credential of the service principal of the configured pipeline and saves the new
+	// credential in the pipeline, without pushing any change
+	RotateCredentials bool
+	// RotationSchedule is the cron schedule of the pipeline rotating the credential, which is added when set
+	RotationSchedule string
+	// ManagedIdentityClientId is the client id of the managed identity of the self-hosted agent of the pipeline, which
+	// signs in with the identity instead of a service principal
+	ManagedIdentityClientId string
+	// ExistingServiceConnection signs in with an existing service connection of the Azure DevOps project, selected by
+	// the user, instead of a service principal and a service connection created by azd
+	ExistingServiceConnection bool
+	// VariableGroup saves the values of the environment in an Azure DevOps variable group named after the environment,
+	// linked to the pipeline, instead of variables of the pipeline
+	VariableGroup bool
+	// Branch is the branch the Azure DevOps pipeline and its build policy target, which defaults to the default branch
+	// of the repository, or to the current branch for an empty repository
+	Branch string
+	// PipelineAuthType is how the pipeline signs in with its service principal, which defaults to the auth type of the
+	// configured pipeline, or to federated credentials when the pipeline supports them
+	PipelineAuthType AuthType
+	// InfraOnly configures a pipeline provisioning the infrastructure for the changes of the infra folder, instead of a
+	// pipeline provisioning and deploying for every change
+	InfraOnly bool
+	// CodeOnly configures a pipeline deploying the code for the changes of the folders of the services, instead of a
+	// pipeline provisioning and deploying for every change
+	CodeOnly bool
+	// PreviewEnvironments adds a pipeline deploying each pull request to its own environment, deleted when the pull
+	// request is closed
+	PreviewEnvironments bool
+}
+
+// validate returns an error when the arguments can't be used together, or with the provider of the pipeline
+func (args PipelineManagerArgs) validate(isAzdo bool) error {
+	// The options of the Azure DevOps provider only
+	azdoOnly := []struct {
+		flag string
+		set  bool
+	}{
+		{"--project-id", args.AzdoProjectId != ""},
+		{"--branch", args.Branch != ""},
+		{"--existing-service-connection", args.ExistingServiceConnection},
+		{"--variable-group", args.VariableGroup},
+	}
+	for _, option := range azdoOnly {
+		if option.set && !isAzdo {
+			return fmt.Errorf("%s is only supported by the Azure DevOps pipeline provider", option.flag)
+		}
+	}
+
+	rotates := args.RotateCredentials || args.RotationSchedule != ""
+	partitioned := args.InfraOnly || args.CodeOnly
+
+	switch {
+	case args.RotateCredentials && args.RotationSchedule != "":
+		return errors.New("--rotate-credentials and --rotation-schedule can't be used together")
+	case args.ManagedIdentityClientId != "" && args.PipelineServicePrincipalName != "":
+		return errors.New("--managed-identity and --principal-name can't be used together")
+	case args.ManagedIdentityClientId != "" && rotates:
+		return errors.New("a managed identity has no credential to rotate")
+	case args.ManagedIdentityClientId != "" && args.PipelineAuthType != "":
+		return errors.New("--managed-identity and --auth-type can't be used together")
+	case args.ExistingServiceConnection && args.ManagedIdentityClientId != "":
+		return errors.New("--existing-service-connection and --managed-identity can't be used together")
+	case args.ExistingServiceConnection && args.PipelineServicePrincipalName != "":
+		return errors.New("--existing-service-connection and --principal-name can't be used together")
+	case args.ExistingServiceConnection && args.PipelineAuthType != "":
+		return errors.New("--existing-service-connection and --auth-type can't be used together")
+	case args.ExistingServiceConnection && rotates:
+		return errors.New("the credential of an existing service connection isn't rotated by azd")
+	case args.ExistingServiceConnection && partitioned:
+		return errors.New("--infra-only and --code-only can't be used with --existing-service-connection")
+	case args.VariableGroup && args.ManagedIdentityClientId != "":
+		return errors.New("--variable-group and --managed-identity can't be used together")
+	case args.VariableGroup && partitioned:
+		return errors.New("--infra-only and --code-only can't be used with --variable-group")
+	case partitioned && args.ManagedIdentityClientId != "":
+		return errors.New("--infra-only and --code-only can't be used with --managed-identity")
+	case partitioned && args.RotateCredentials:
+		return errors.New("--infra-only and --code-only can't be used with --rotate-credentials")
+	case args.PreviewEnvironments && args.ManagedIdentityClientId != "":
+		return errors.New("--preview-environments can't be used with --managed-identity")
+	case args.PreviewEnvironments && args.RotateCredentials:
+		return errors.New("--preview-environments can't be used with --rotate-credentials")
+	case args.PreviewEnvironments && isAzdo:
+		return errPreviewUnsupported
+	}
+
+	if args.RotationSchedule != "" {
+		return validateRotationSchedule(args.RotationSchedule)
+	}
+
+	return nil
 }
 
 // PipelineManager takes care of setting up the scm and pipeline.
@@ -37,6 +162,10 @@ type PipelineManager struct {
 	RootOptions *internal.GlobalCommandOptions
 	Environment *environment.Environment
 	PipelineManagerArgs
+	// the auth type the pipeline is configured with, resolved from PipelineAuthType
+	authType AuthType
+	// the version of the template of the project, stamped on the generated definitions
+	templateVersion string
 }
 
 func NewPipelineManager(
@@ -100,6 +229,11 @@ func (i *PipelineManager) getGitRepoDetails(ctx context.Context) (*gitRepository
 	for {
 		repoRemoteDetails, err := i.ensureRemote(ctx, repoPath, i.PipelineRemoteName)
 		switch {
+		case i.SkipPush && (errors.Is(err, git.ErrNotRepository) || errors.Is(err, git.ErrNoSuchRemote)):
+			// The repository and its remote aren't created when git operations are skipped
+			return nil, fmt.Errorf(
+				"with --skip-push, the project must be a git repository with a remote named \"%s\": %w",
+				i.PipelineRemoteName, err)
 		case errors.Is(err, git.ErrNotRepository):
 			// Offer the user a chance to init a new repository if one does not exist.
 			initRepo, err := console.Confirm(ctx, input.ConsoleOptions{
@@ -157,6 +291,44 @@ func (i *PipelineManager) getGitRepoDetails(ctx context.Context) (*gitRepository
 	}
 }
 
+// SyncEnvironmentValue sets the value of an environment variable in the pipeline configured for the environment, as a
+// GitHub secret or an Azdo pipeline variable, so the pipeline runs with the same configuration as the environment.
+func (manager *PipelineManager) SyncEnvironmentValue(ctx context.Context, name string, value string) error {
+	// The provider is persisted in the environment by `azd pipeline config`
+	if _, has := manager.Environment.Values[envPersistedKey]; !has {
+		return fmt.Errorf(
+			"no pipeline is configured for environment '%s', run `azd pipeline config` first",
+			manager.Environment.GetEnvName())
+	}
+
+	// The persisted provider takes precedence over the default provider of the user config
+	scmProvider, ciProvider, err := DetectProviders(
+		ctx, manager.AzdCtx, manager.Environment, config.NewConfig(nil), manager.PipelineRemoteName, "")
+	if err != nil {
+		return err
+	}
+	manager.ScmProvider = scmProvider
+	manager.CiProvider = ciProvider
+
+	if err := tools.EnsureInstalled(ctx, manager.requiredTools(ctx)...); err != nil {
+		return err
+	}
+	if err := manager.preConfigureCheck(ctx); err != nil {
+		return err
+	}
+
+	gitRepoInfo, err := manager.ensureRemote(ctx, manager.AzdCtx.ProjectDirectory(), manager.PipelineRemoteName)
+	if err != nil {
+		return fmt.Errorf("getting the repository of the pipeline: %w", err)
+	}
+
+	if err := manager.CiProvider.setPipelineVariable(ctx, gitRepoInfo, name, value); err != nil {
+		return fmt.Errorf("setting %s in the pipeline: %w", name, err)
+	}
+
+	return nil
+}
+
 // validateDependencyInjection panic if the manager did not received all the
 // mandatory dependencies to work
 func validateDependencyInjection(ctx context.Context, manager *PipelineManager) {
@@ -179,26 +351,116 @@ func (i *PipelineManager) pushGitRepo(ctx context.Context, currentBranch string)
 	gitCli := git.NewGitCli(ctx)
 	console := input.GetConsole(ctx)
 
+	added, err := git.EnsureIgnoreEntries(i.AzdCtx.ProjectDirectory(), func(entries []string) (bool, error) {
+		return console.Confirm(ctx, input.ConsoleOptions{
+			Message: fmt.Sprintf(
+				"Add %s to %s so they aren't pushed?", strings.Join(entries, ", "), git.GitIgnoreFileName),
+			DefaultValue: true,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("updating %s: %w", git.GitIgnoreFileName, err)
+	}
+	if len(added) > 0 {
+		console.Message(ctx, fmt.Sprintf("Added %s to %s", strings.Join(added, ", "), git.GitIgnoreFileName))
+	}
+
 	if err := gitCli.AddFile(ctx, i.AzdCtx.ProjectDirectory(), "."); err != nil {
 		return fmt.Errorf("adding files: %w", err)
 	}
 
+	// a safety net for the files committed before they were ignored, or the entries declined above
+	tracked, err := git.TrackedSecretFiles(ctx, gitCli, i.AzdCtx.ProjectDirectory())
+	if err != nil {
+		return fmt.Errorf("checking for environment files: %w", err)
+	}
+	if len(tracked) > 0 {
+		return fmt.Errorf(
+			"refusing to push environment and secret files tracked by the repository: %s. Remove them from the "+
+				"repository with 'git rm --cached <file>' and add them to %s, then run the command again",
+			strings.Join(tracked, ", "), git.GitIgnoreFileName)
+	}
+
 	if err := gitCli.Commit(ctx, i.AzdCtx.ProjectDirectory(), "Configure Azure Developer Pipeline"); err != nil {
 		return fmt.Errorf("commit changes: %w", err)
 	}
 
 	console.Message(ctx, "Pushing changes")
 
-	// If user has a git credential manager with some cached credentials
-	// and the credentials are rotated, the push operation will fail and the credential manager would remove the cache
-	// Then, on the next intent to push code, there should be a prompt for credentials.
-	// Due to this, we use retry here, so we can run the second intent to prompt for credentials one more time
-	return retry.Do(ctx, retry.WithMaxRetries(3, retry.NewConstant(100*time.Millisecond)), func(ctx context.Context) error {
-		if err := gitCli.PushUpstream(ctx, i.AzdCtx.ProjectDirectory(), i.PipelineRemoteName, currentBranch); err != nil {
-			return retry.RetryableError(fmt.Errorf("pushing changes: %w", err))
+	for {
+		err := gitCli.PushUpstream(ctx, i.AzdCtx.ProjectDirectory(), i.PipelineRemoteName, currentBranch)
+		if err == nil {
+			return nil
 		}
-		return nil
+
+		if !errors.Is(err, git.ErrAuthenticationFailed) {
+			return fmt.Errorf("pushing changes: %w", err)
+		}
+
+		// git doesn't prompt for credentials, the user signs in to the remote, i.e. with their git credential
+		// manager, before retrying
+		console.Message(ctx, output.WithWarningFormat(
+			"Git failed to authenticate to the remote '%s'. Sign in to it or refresh its credentials, then retry.",
+			i.PipelineRemoteName))
+
+		retryPush, promptErr := console.Confirm(ctx, input.ConsoleOptions{
+			Message:      "Retry pushing the changes?",
+			DefaultValue: false,
+		})
+		if promptErr != nil {
+			return fmt.Errorf("prompting to retry pushing changes: %w", promptErr)
+		}
+
+		if !retryPush {
+			return fmt.Errorf("pushing changes: %w", err)
+		}
+	}
+}
+
+// runStep runs a single pipeline config step within its own telemetry span.
+// When the step fails, the step name is recorded on the parent span to identify where the flow stopped.
+// The outcome of the step is checkpointed in the progress of the configuration.
+func runStep(ctx context.Context, step string, stepFn func(ctx context.Context) error) error {
+	stepCtx, span := telemetry.GetTracer().Start(ctx, events.PipelineConfigEventPrefix+step)
+	defer span.End()
+
+	completeStep := eventlog.FromContext(ctx).StartStep("pipeline/" + step)
+	err := stepFn(stepCtx)
+	completeStep(err)
+	if err != nil {
+		span.SetStatus(codes.Error, "UnknownError")
+		telemetry.SetAttributesInContext(ctx, fields.PipelineFailedStepKey.String(step))
+	}
+
+	checkpoint(ctx, func(progress *progress) {
+		if err != nil {
+			progress.FailedStep = step
+			return
+		}
+
+		progress.FailedStep = ""
+		for _, completed := range progress.CompletedSteps {
+			if completed == step {
+				return
+			}
+		}
+		progress.CompletedSteps = append(progress.CompletedSteps, step)
 	})
+
+	return err
+}
+
+type configSpanContextKey struct{}
+
+// setConfigAttributes sets the attributes on the pipeline.config span of the configuration, rather than on the span of
+// the step running in ctx
+func setConfigAttributes(ctx context.Context, attributes ...attribute.KeyValue) {
+	span, has := ctx.Value(configSpanContextKey{}).(trace.Span)
+	if !has {
+		span = trace.SpanFromContext(ctx)
+	}
+
+	span.SetAttributes(attributes...)
 }
 
 // Configure is the main function from the pipeline manager which takes care
@@ -207,81 +469,513 @@ func (manager *PipelineManager) Configure(ctx context.Context) error {
 	// check that scm and ci providers are set
 	validateDependencyInjection(ctx, manager)
 
+	ctx, span := telemetry.GetTracer().Start(ctx, events.PipelineConfigEventName)
+	defer span.End()
+	span.SetAttributes(fields.PipelineProviderKey.String(manager.CiProvider.name()))
+	ctx = context.WithValue(ctx, configSpanContextKey{}, trace.SpanFromContext(ctx))
+
+	_, isAzdo := manager.CiProvider.(*AzdoCiProvider)
+	if err := manager.PipelineManagerArgs.validate(isAzdo); err != nil {
+		return err
+	}
+
+	if azdoScmProvider, ok := manager.ScmProvider.(*AzdoScmProvider); ok && manager.AzdoProjectId != "" {
+		azdoScmProvider.projectId = manager.AzdoProjectId
+	}
+
+	if manager.RotateCredentials && manager.PipelineAuthType != AuthTypeClientSecret &&
+		manager.Environment != nil && manager.Environment.Values[authTypePersistedKey] == string(AuthTypeFederated) {
+		return errors.New(
+			"the pipeline signs in with federated credentials, it has no credential to rotate. " +
+				"Use --auth-type clientsecret to sign in with a client secret instead")
+	}
+
+	err := manager.configureWithTransaction(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, "UnknownError")
+	}
+
+	return err
+}
+
+// configureWithTransaction runs the configuration while recording the resources it creates and its progress.
+// When the user interrupts the configuration, they are offered to delete the created resources or to keep them
+// and resume the configuration later. A configuration that fails is resumed when run again.
+func (manager *PipelineManager) configureWithTransaction(ctx context.Context) error {
+	console := input.GetConsole(ctx)
+
+	tx, err := manager.beginTransaction(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Interrupting the configuration cancels the context instead of terminating azd,
+	// which leaves the chance to clean up the resources created so far.
+	configureCtx, stop := signal.NotifyContext(withTransaction(ctx, tx), os.Interrupt)
+	err = manager.configure(configureCtx)
+	interrupted := err != nil && isInterrupted(configureCtx, err)
+	// Restore the default behavior, interrupting the clean up terminates azd
+	stop()
+
+	switch {
+	case interrupted:
+		return manager.handleInterrupt(ctx, tx, err)
+	case err != nil:
+		if !tx.isEmpty() {
+			console.Message(ctx, "Run 'azd pipeline config' again to resume the configuration from the failed step.\n")
+		}
+
+		return err
+	default:
+		if err := tx.complete(); err != nil {
+			log.Printf("failed completing pipeline transaction: %v", err)
+		}
+
+		return nil
+	}
+}
+
+const resumeMessage = "Run 'azd pipeline config --resume' to resume the configuration with the resources created so far.\n"
+
+// beginTransaction loads the resources and progress recorded by a previous configuration, if any.
+// A configuration that failed is resumed. Without --resume, the user is prompted to resume an interrupted
+// configuration or to delete the resources it created, so they aren't left behind by a new configuration.
+// When resuming, the recorded resources and choices are reused.
+func (manager *PipelineManager) beginTransaction(ctx context.Context) (*transaction, error) {
+	console := input.GetConsole(ctx)
+
+	tx, err := loadTransaction(transactionPath(manager.AzdCtx, manager.Environment))
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.isEmpty() {
+		if manager.Resume {
+			console.Message(ctx, "No previous pipeline configuration found, starting a new configuration.\n")
+		}
+
+		return tx, nil
+	}
+
+	if tx.Interrupted && !manager.Resume && len(tx.Resources) > 0 {
+		console.Message(ctx, "A previous pipeline configuration was interrupted after creating:")
+		for _, resource := range tx.Resources {
+			console.Message(ctx, fmt.Sprintf("  - %s", resource.String()))
+		}
+
+		resumeOption := "Resume the configuration with them"
+		idx, err := console.Select(ctx, input.ConsoleOptions{
+			Message:      "What would you like to do with the created resources?",
+			Options:      []string{resumeOption, "Delete them and start over"},
+			DefaultValue: resumeOption,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("prompting for the resources of the interrupted configuration: %w", err)
+		}
+
+		if idx == 1 {
+			if err := rollback(ctx, tx, manager.Environment, azcli.GetAzCli(ctx), console); err != nil {
+				return nil, fmt.Errorf("deleting the created resources: %w", err)
+			}
+
+			console.Message(ctx, "The created resources were deleted.\n")
+			return tx, nil
+		}
+	}
+
+	tx.resume = true
+	tx.Interrupted = false
+
+	if tx.Progress.FailedStep != "" {
+		console.Message(ctx, fmt.Sprintf(
+			"Resuming the previous pipeline configuration, which failed at the %s step.\n"+
+				"Delete %s to start over instead.\n",
+			tx.Progress.FailedStep,
+			tx.path,
+		))
+	} else {
+		console.Message(ctx, "Resuming the previous pipeline configuration.\n")
+	}
+
+	if manager.PipelineServicePrincipalName == "" {
+		if tx.Progress.PrincipalName != "" {
+			manager.PipelineServicePrincipalName = tx.Progress.PrincipalName
+		} else if principal := tx.find(resourceServicePrincipal); principal != nil {
+			manager.PipelineServicePrincipalName = principal.Name
+		}
+	}
+
+	return tx, nil
+}
+
+// handleInterrupt prompts the user to delete the resources created by the interrupted configuration,
+// or to keep them so the configuration can be resumed. The configuration error is always returned.
+func (manager *PipelineManager) handleInterrupt(ctx context.Context, tx *transaction, err error) error {
+	if len(tx.Resources) == 0 {
+		return err
+	}
+
+	console := input.GetConsole(ctx)
+	console.Message(ctx, "\nThe pipeline configuration was interrupted after creating:")
+	for _, resource := range tx.Resources {
+		console.Message(ctx, fmt.Sprintf("  - %s", resource.String()))
+	}
+
+	keepOption := "Keep them to resume the configuration later"
+	idx, promptErr := console.Select(ctx, input.ConsoleOptions{
+		Message:      "What would you like to do with the created resources?",
+		Options:      []string{keepOption, "Delete them"},
+		DefaultValue: keepOption,
+	})
+	if promptErr != nil || idx == 0 {
+		tx.Interrupted = true
+		if saveErr := tx.save(); saveErr != nil {
+			log.Printf("failed saving pipeline transaction: %v", saveErr)
+		}
+
+		console.Message(ctx, resumeMessage)
+		return err
+	}
+
+	if rollbackErr := rollback(ctx, tx, manager.Environment, azcli.GetAzCli(ctx), console); rollbackErr != nil {
+		return fmt.Errorf("%w, deleting the created resources: %v", err, rollbackErr)
+	}
+
+	console.Message(ctx, "The created resources were deleted.\n")
+	return err
+}
+
+func (manager *PipelineManager) configure(ctx context.Context) error {
 	// after previous check, we know we can get the input console from the context
 	inputConsole := input.GetConsole(ctx)
 
 	// check all required tools are installed
 	azCli := azcli.GetAzCli(ctx)
-	requiredTools := manager.requiredTools(ctx)
-	requiredTools = append(requiredTools, azCli)
-	if err := tools.EnsureInstalled(ctx, requiredTools...); err != nil {
+	err := runStep(ctx, stepPreConfigure, func(ctx context.Context) error {
+		requiredTools := manager.requiredTools(ctx)
+		requiredTools = append(requiredTools, azCli)
+		if err := tools.EnsureInstalled(ctx, requiredTools...); err != nil {
+			return err
+		}
+
+		// run pre-config validations. manager will check az cli is logged in and
+		// will invoke the per-provider validations.
+		return manager.preConfigureCheck(ctx)
+	})
+	if err != nil {
 		return err
 	}
 
-	// run pre-config validations. manager will check az cli is logged in and
-	// will invoke the per-provider validations.
-	if errorsFromPreConfig := manager.preConfigureCheck(ctx); errorsFromPreConfig != nil {
-		return errorsFromPreConfig
+	// *********** Create or update Azure Principal ***********
+	if manager.RotateCredentials && manager.PipelineServicePrincipalName == "" {
+		// The credential of the principal of the configured pipeline is rotated
+		principalName, err := manager.configuredPrincipalName()
+		if err != nil {
+			return err
+		}
+
+		manager.PipelineServicePrincipalName = principalName
 	}
 
-	// *********** Create or update Azure Principal ***********
-	if manager.PipelineServicePrincipalName == "" {
-		// This format matches what the `az` cli uses when a name is not provided, with the prefix
-		// changed from "az-cli" to "az-dev"
-		manager.PipelineServicePrincipalName = fmt.Sprintf("az-dev-%s", time.Now().UTC().Format("01-02-2006-15-04-05"))
+	// Figure out what is the expected provider to use for provisioning
+	prj, err := project.LoadProjectConfig(manager.AzdCtx.ProjectPath(), manager.Environment)
+	if err != nil {
+		return fmt.Errorf("finding provisioning provider: %w", err)
 	}
+	manager.templateVersion = templateVersion(prj)
 
-	inputConsole.Message(
-		ctx,
-		fmt.Sprintf("Creating or updating service principal %s.\n", manager.PipelineServicePrincipalName),
-	)
+	if manager.PipelineServicePrincipalName == "" && manager.ManagedIdentityClientId == "" &&
+		!manager.ExistingServiceConnection {
+		manager.PipelineServicePrincipalName = defaultPrincipalName(prj.Name, manager.Environment.GetEnvName())
+	}
 
-	credentials, err := azCli.CreateOrUpdateServicePrincipal(
-		ctx,
-		manager.Environment.GetSubscriptionId(),
-		manager.PipelineServicePrincipalName,
-		manager.PipelineRoleName)
+	// The generated name is kept so resuming the configuration reuses the same principal
+	checkpoint(ctx, func(progress *progress) {
+		progress.PrincipalName = manager.PipelineServicePrincipalName
+	})
+
+	if azdoCiProvider, ok := manager.CiProvider.(*AzdoCiProvider); ok {
+		azdoCiProvider.allowOverride = prj.Pipeline.AllowOverride
+		azdoCiProvider.variableGroup = manager.VariableGroup
+	}
+
+	// A multi-stage pipeline deploys the environment of each stage, with a service principal per stage
+	stages, err := loadStages(manager.AzdCtx, prj.Pipeline.Stages, manager.PipelineServicePrincipalName)
 	if err != nil {
-		return fmt.Errorf("failed to create or update service principal: %w", err)
+		return err
 	}
 
-	// Get git repo details
-	gitRepoInfo, err := manager.getGitRepoDetails(ctx)
+	// The infrastructure and the code can each have their own pipeline, run for their changes
+	partitions := loadPartitions(manager.InfraOnly, manager.CodeOnly, prj)
+	if len(partitions) > 0 && len(stages) > 0 {
+		return errors.New("a multi-stage pipeline can't be split with --infra-only or --code-only")
+	}
+
+	if manager.PreviewEnvironments && len(stages) > 0 {
+		return errors.New("a multi-stage pipeline can't deploy preview environments")
+	}
+
+	if manager.ManagedIdentityClientId != "" {
+		switch {
+		case len(stages) > 0:
+			return errors.New("a multi-stage pipeline can't sign in with a managed identity")
+		case prj.Infra.Provider == provisioning.Terraform:
+			return errors.New("a pipeline provisioning with Terraform can't sign in with a managed identity")
+		}
+	}
+
+	if manager.ExistingServiceConnection {
+		switch {
+		case len(stages) > 0:
+			return errors.New("a multi-stage pipeline can't sign in with an existing service connection")
+		case prj.Infra.Provider == provisioning.Terraform:
+			return errors.New("a pipeline provisioning with Terraform can't sign in with an existing service connection")
+		}
+
+		// The pipeline deleting the expired environments signs in with the service connection created by azd
+		if _, ok := manager.Environment.GetExpiresOn(); ok {
+			return errors.New("the pipeline of an environment with a time to live can't sign in with an existing " +
+				"service connection")
+		}
+	}
+
+	if manager.VariableGroup {
+		switch {
+		case len(stages) > 0:
+			return errors.New("a multi-stage pipeline can't read the values of its environments from a variable group")
+		case prj.Pipeline.AllowOverride:
+			return errors.New("the values of a variable group can't be overridden when queuing a run, " +
+				"remove allowOverride from the pipeline of azure.yaml to use --variable-group")
+		}
+	}
+
+	if manager.ManagedIdentityClientId == "" && !manager.ExistingServiceConnection {
+		manager.authType, err = resolveAuthType(
+			manager.PipelineAuthType,
+			manager.Environment.Values[authTypePersistedKey],
+			manager.federatedUnsupported(prj.Infra, stages))
+		if err != nil {
+			return err
+		}
+	}
+
+	var credentials json.RawMessage
+	err = runStep(ctx, stepPrincipal, func(ctx context.Context) error {
+		if manager.ManagedIdentityClientId != "" {
+			return manager.assignManagedIdentityRole(ctx)
+		}
+
+		// The principal of the existing service connection is managed outside of azd
+		if manager.ExistingServiceConnection {
+			return nil
+		}
+
+		if len(stages) == 0 {
+			credentials, err = manager.ensureServicePrincipal(
+				ctx, manager.PipelineServicePrincipalName, manager.Environment.GetSubscriptionId())
+			return err
+		}
+
+		for _, stage := range stages {
+			stage.credentials, err = manager.ensureServicePrincipal(
+				ctx, stage.principalName, stage.env.GetSubscriptionId())
+			if err != nil {
+				return fmt.Errorf("pipeline stage '%s': %w", stage.name, err)
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("ensuring git remote: %w", err)
+		return err
 	}
 
-	// Figure out what is the expected provider to use for provisioning
-	prj, err := project.LoadProjectConfig(manager.AzdCtx.ProjectPath(), manager.Environment)
+	// Get git repo details
+	var gitRepoInfo *gitRepositoryDetails
+	err = runStep(ctx, stepRemote, func(ctx context.Context) error {
+		gitRepoInfo, err = manager.getGitRepoDetails(ctx)
+		if err != nil {
+			return fmt.Errorf("ensuring git remote: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("finding provisioning provider: %w", err)
+		return err
 	}
 
-	err = manager.CiProvider.configureConnection(
-		ctx,
-		manager.Environment,
-		gitRepoInfo,
-		prj.Infra,
-		credentials,
-		inputConsole)
+	if manager.ManagedIdentityClientId == "" && !manager.ExistingServiceConnection {
+		if err := manager.tagServicePrincipals(ctx, prj.Name, gitRepoInfo, stages); err != nil {
+			return err
+		}
+	}
+
+	// The branch is resolved on every run, the pipeline and its build policy target it
+	if azdoScmProvider, ok := manager.ScmProvider.(*AzdoScmProvider); ok {
+		if err := azdoScmProvider.resolveBranch(ctx, gitRepoInfo, manager.Branch, inputConsole); err != nil {
+			return err
+		}
+	}
+
+	// Selecting the connection creates nothing, it's repeated when resuming, without prompt once saved in the environment
+	if manager.ExistingServiceConnection {
+		azdoCiProvider := manager.CiProvider.(*AzdoCiProvider)
+		if err := azdoCiProvider.selectExistingConnection(ctx, gitRepoInfo, inputConsole); err != nil {
+			return err
+		}
+	}
+
+	err = runStep(ctx, stepConnection, func(ctx context.Context) error {
+		if manager.ManagedIdentityClientId != "" {
+			return manager.CiProvider.configureManagedIdentityConnection(
+				ctx, manager.Environment, gitRepoInfo, manager.ManagedIdentityClientId, inputConsole)
+		}
+
+		if manager.ExistingServiceConnection {
+			return nil
+		}
+
+		if manager.authType == AuthTypeFederated {
+			return manager.CiProvider.configureFederatedConnection(
+				ctx, manager.Environment, gitRepoInfo, credentials, inputConsole)
+		}
+
+		if len(stages) == 0 {
+			return manager.CiProvider.configureConnection(
+				ctx,
+				manager.Environment,
+				gitRepoInfo,
+				prj.Infra,
+				credentials,
+				inputConsole)
+		}
+
+		for _, stage := range stages {
+			err := manager.CiProvider.configureStageConnection(ctx, gitRepoInfo, prj.Infra, stage, inputConsole)
+			if err != nil {
+				return fmt.Errorf("pipeline stage '%s': %w", stage.name, err)
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
+	if err := manager.saveAuthType(); err != nil {
+		return err
+	}
+
 	// config pipeline handles setting or creating the provider pipeline to be used
-	err = manager.CiProvider.configurePipeline(ctx, gitRepoInfo, prj.Infra)
+	err = runStep(ctx, stepPipeline, func(ctx context.Context) error {
+		if manager.ManagedIdentityClientId != "" {
+			if err := manager.writeManagedIdentityDefinition(ctx); err != nil {
+				return err
+			}
+
+			return manager.CiProvider.configureManagedIdentityPipeline(ctx, gitRepoInfo, manager.ManagedIdentityClientId)
+		}
+
+		if manager.ExistingServiceConnection || manager.VariableGroup {
+			if err := manager.writeAzdoDefinition(ctx, prj.Infra); err != nil {
+				return err
+			}
+		} else if manager.authType == AuthTypeFederated && len(partitions) == 0 {
+			if err := manager.writeFederatedDefinition(ctx); err != nil {
+				return err
+			}
+		}
+
+		if manager.RotationSchedule != "" {
+			if len(stages) > 0 {
+				return errors.New("the credentials of a multi-stage pipeline can't be rotated on a schedule")
+			}
+
+			if err := manager.writeRotationDefinition(ctx); err != nil {
+				return err
+			}
+
+			if err := manager.CiProvider.configureRotationPipeline(ctx, gitRepoInfo); err != nil {
+				return fmt.Errorf("configuring the credential rotation pipeline: %w", err)
+			}
+		}
+
+		// The environments with a TTL are deleted by the cleanup pipeline once expired
+		if _, ok := manager.Environment.GetExpiresOn(); ok {
+			if err := manager.writeCleanupDefinition(ctx); err != nil {
+				return err
+			}
+
+			if err := manager.CiProvider.configureCleanupPipeline(ctx, gitRepoInfo); err != nil {
+				return fmt.Errorf("configuring the cleanup pipeline: %w", err)
+			}
+		}
+
+		if manager.PreviewEnvironments {
+			if err := manager.writePreviewDefinition(ctx, prj.Infra); err != nil {
+				return err
+			}
+		}
+
+		for _, partition := range partitions {
+			if err := manager.writePartitionDefinition(ctx, partition, prj.Infra); err != nil {
+				return err
+			}
+
+			if err := manager.CiProvider.configurePartitionPipeline(ctx, gitRepoInfo, prj.Infra, partition); err != nil {
+				return fmt.Errorf("configuring the pipeline of the %s: %w", partition.name, err)
+			}
+		}
+
+		if len(partitions) > 0 {
+			return nil
+		}
+
+		if len(stages) == 0 {
+			return manager.CiProvider.configurePipeline(ctx, gitRepoInfo, prj.Infra)
+		}
+
+		// The definition is kept as is when rotating, only the credentials of the stages change
+		if !manager.RotateCredentials {
+			if err := manager.writeStagesDefinition(ctx, stages, prj.Infra); err != nil {
+				return err
+			}
+		}
+
+		return manager.CiProvider.configureStagesPipeline(ctx, gitRepoInfo, prj.Infra, stages)
+	})
 	if err != nil {
 		return err
 	}
 
+	// Rotating the credential doesn't change the repository, nothing is pushed
+	if manager.RotateCredentials {
+		inputConsole.Message(ctx, fmt.Sprintf(
+			"Rotated the credential of the service principal %s.\n", manager.PipelineServicePrincipalName))
+		manager.summarize(ctx, gitRepoInfo, credentials, stages)
+		return nil
+	}
+
 	// The CI pipeline should be set-up and ready at this point.
 	// azd offers to push changes to the scm to start a new pipeline run
-	doPush, err := inputConsole.Confirm(ctx, input.ConsoleOptions{
-		Message:      "Would you like to commit and push your local changes to start the configured CI pipeline?",
-		DefaultValue: true,
-	})
-	if err != nil {
-		return fmt.Errorf("prompting to push: %w", err)
+	var doPush bool
+	if manager.SkipPush {
+		doPush = false
+	} else if resumed := resumedProgress(ctx); resumed != nil && resumed.Push != nil {
+		doPush = *resumed.Push
+	} else {
+		doPush, err = inputConsole.Confirm(ctx, input.ConsoleOptions{
+			Message:      "Would you like to commit and push your local changes to start the configured CI pipeline?",
+			DefaultValue: true,
+		})
+		if err != nil {
+			return fmt.Errorf("prompting to push: %w", err)
+		}
+
+		checkpoint(ctx, func(progress *progress) {
+			progress.Push = &doPush
+		})
 	}
 
 	currentBranch, err := git.NewGitCli(ctx).GetCurrentBranch(ctx, manager.AzdCtx.ProjectDirectory())
@@ -289,46 +983,426 @@ func (manager *PipelineManager) Configure(ctx context.Context) error {
 		return fmt.Errorf("getting current branch: %w", err)
 	}
 
-	// scm provider can prevent from pushing changes and/or use the
-	// interactive console for setting up any missing details.
-	// For example, GitHub provider would check if GH-actions are disabled.
-	if doPush {
-		preventPush, err := manager.ScmProvider.preventGitPush(
-			ctx,
-			gitRepoInfo,
-			manager.PipelineRemoteName,
-			currentBranch,
-			inputConsole)
-		if err != nil {
-			return fmt.Errorf("check git push prevent: %w", err)
+	err = runStep(ctx, stepPush, func(ctx context.Context) error {
+		// scm provider can prevent from pushing changes and/or use the
+		// interactive console for setting up any missing details.
+		// For example, GitHub provider would check if GH-actions are disabled.
+		if doPush {
+			preventPush, err := manager.ScmProvider.preventGitPush(
+				ctx,
+				gitRepoInfo,
+				manager.PipelineRemoteName,
+				currentBranch,
+				inputConsole)
+			if err != nil {
+				return fmt.Errorf("check git push prevent: %w", err)
+			}
+			// revert user's choice when prevent git push returns true
+			doPush = !preventPush
+		}
+
+		telemetry.SetAttributesInContext(ctx, fields.PipelinePushedKey.Bool(doPush))
+
+		if doPush {
+			err = manager.pushGitRepo(ctx, currentBranch)
+			if err != nil {
+				return fmt.Errorf("git push: %w", err)
+			}
+
+			gitRepoInfo.pushStatus = true
+			err = manager.ScmProvider.postGitPush(
+				ctx,
+				gitRepoInfo,
+				manager.PipelineRemoteName,
+				currentBranch,
+				inputConsole)
+			if err != nil {
+				return fmt.Errorf("post git push hook: %w", err)
+			}
+		} else {
+			if manager.SkipPush {
+				// The provider still configures the remote, i.e. the branch policies, for the pushes of the user
+				err = manager.ScmProvider.postGitPush(
+					ctx,
+					gitRepoInfo,
+					manager.PipelineRemoteName,
+					currentBranch,
+					inputConsole)
+				if err != nil {
+					return fmt.Errorf("post git push hook: %w", err)
+				}
+			}
+
+			inputConsole.Message(ctx,
+				fmt.Sprintf(
+					"To fully enable pipeline you need to push this repo to the upstream using 'git push --set-upstream %s %s'.\n",
+					manager.PipelineRemoteName,
+					currentBranch))
 		}
-		// revert user's choice when prevent git push returns true
-		doPush = !preventPush
+
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	if doPush {
-		err = manager.pushGitRepo(ctx, currentBranch)
-		if err != nil {
-			return fmt.Errorf("git push: %w", err)
+	manager.summarize(ctx, gitRepoInfo, credentials, stages)
+	return nil
+}
+
+// configuredPrincipalName returns the name of the service principal of the pipeline configured for the environment
+func (manager *PipelineManager) configuredPrincipalName() (string, error) {
+	summary, err := LoadSummary(manager.AzdCtx, manager.Environment)
+	if err != nil {
+		return "", err
+	}
+
+	if summary == nil {
+		return "", errRotationNotConfigured
+	}
+
+	// The principals of the stages are named after the principal of the pipeline, see loadStages
+	if len(summary.Stages) > 0 {
+		stage := summary.Stages[0]
+		return strings.TrimSuffix(stage.ServicePrincipalName, "-"+stage.Name), nil
+	}
+
+	if summary.ServicePrincipalName == "" {
+		return "", errRotationNotConfigured
+	}
+
+	return summary.ServicePrincipalName, nil
+}
+
+// assignManagedIdentityRole assigns the role of the manager in the subscription to the managed identity of the agent
+func (manager *PipelineManager) assignManagedIdentityRole(ctx context.Context) error {
+	input.GetConsole(ctx).Message(ctx, fmt.Sprintf(
+		"Assigning the role %s to managed identity %s.\n", manager.PipelineRoleName, manager.ManagedIdentityClientId))
+
+	err := azcli.GetAzCli(ctx).AssignManagedIdentityRole(
+		ctx, manager.Environment.GetSubscriptionId(), manager.ManagedIdentityClientId, manager.PipelineRoleName)
+	if err != nil {
+		return fmt.Errorf("failed assigning a role to the managed identity: %w", err)
+	}
+
+	return nil
+}
+
+// servicePrincipalAppId returns the client id of the credentials of a service principal
+func servicePrincipalAppId(credentials json.RawMessage) string {
+	azureCredentials := azcli.AzureCredentials{}
+	if err := json.Unmarshal(credentials, &azureCredentials); err != nil {
+		log.Printf("reading the client id of the service principal: %v", err)
+	}
+
+	return azureCredentials.ClientId
+}
+
+// ensureServicePrincipal creates or updates the service principal with the role of the manager in the subscription,
+// returns the credentials of the principal
+func (manager *PipelineManager) ensureServicePrincipal(
+	ctx context.Context,
+	principalName string,
+	subscriptionId string,
+) (json.RawMessage, error) {
+	input.GetConsole(ctx).Message(ctx, fmt.Sprintf("Creating or updating service principal %s.\n", principalName))
+
+	azCli := azcli.GetAzCli(ctx)
+	exists, err := azCli.ServicePrincipalExists(ctx, principalName)
+	if err != nil {
+		return nil, fmt.Errorf("failed checking for existing service principal: %w", err)
+	}
+
+	// Recorded before creating it, a principal partially created when interrupted is deleted on rollback
+	if !exists {
+		recordCreated(ctx, createdResource{
+			Kind: resourceServicePrincipal,
+			Name: principalName,
+		})
+	}
+
+	var credentials json.RawMessage
+	if manager.authType == AuthTypeFederated {
+		credentials, err = azCli.CreateOrUpdateFederatedServicePrincipal(
+			ctx, subscriptionId, principalName, manager.PipelineRoleName)
+	} else {
+		credentials, err = azCli.CreateOrUpdateServicePrincipal(ctx, subscriptionId, principalName, manager.PipelineRoleName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create or update service principal: %w", err)
+	}
+
+	return credentials, nil
+}
+
+// writeStagesDefinition writes the definition of the multi-stage pipeline to the project, the definition is committed
+// and pushed with the changes of the project. Replacing a different definition is confirmed by the user.
+func (manager *PipelineManager) writeStagesDefinition(
+	ctx context.Context,
+	stages []*pipelineStage,
+	provisioningProvider provisioning.Options,
+) error {
+	relativePath, definition, err := manager.CiProvider.stagesDefinition(stages, provisioningProvider)
+	if err != nil {
+		return fmt.Errorf("creating the definition of the pipeline stages: %w", err)
+	}
+
+	return manager.writeDefinition(
+		ctx,
+		relativePath,
+		definition,
+		fmt.Sprintf("the pipeline deploying the stages %s", strings.Join(stageNames(stages), ", ")))
+}
+
+// writeRotationDefinition writes the definition of the pipeline rotating the credential of the service principal on
+// the schedule of the manager, like writeStagesDefinition
+func (manager *PipelineManager) writeRotationDefinition(ctx context.Context) error {
+	relativePath, definition, err := manager.CiProvider.rotationDefinition(
+		manager.RotationSchedule, manager.PipelineServicePrincipalName)
+	if err != nil {
+		return fmt.Errorf("creating the definition of the credential rotation pipeline: %w", err)
+	}
+
+	return manager.writeDefinition(
+		ctx,
+		relativePath,
+		definition,
+		fmt.Sprintf("the pipeline rotating the credential on the schedule '%s'", manager.RotationSchedule))
+}
+
+// writeManagedIdentityDefinition writes the definition of the pipeline signing in with the managed identity of its
+// agent, like writeStagesDefinition
+func (manager *PipelineManager) writeManagedIdentityDefinition(ctx context.Context) error {
+	relativePath, definition, err := manager.CiProvider.managedIdentityDefinition()
+	if err != nil {
+		return fmt.Errorf("creating the definition of the pipeline: %w", err)
+	}
+
+	return manager.writeDefinition(
+		ctx, relativePath, definition, "the pipeline signing in with the managed identity of its self-hosted agent")
+}
+
+// writeAzdoDefinition writes the definition of the Azure DevOps pipeline signing in with the existing service
+// connection, or reading the values of the environment from its variable group, like writeStagesDefinition
+func (manager *PipelineManager) writeAzdoDefinition(ctx context.Context, infraOptions provisioning.Options) error {
+	relativePath, definition, err := manager.CiProvider.(*AzdoCiProvider).pipelineDefinition(infraOptions)
+	if err != nil {
+		return fmt.Errorf("creating the definition of the pipeline: %w", err)
+	}
+
+	description := "the pipeline signing in with an existing service connection"
+	if manager.VariableGroup {
+		description = fmt.Sprintf(
+			"the pipeline reading the values of the environment from the variable group %s",
+			azdo.VariableGroupName(manager.Environment.GetEnvName()))
+	}
+
+	return manager.writeDefinition(ctx, relativePath, definition, description)
+}
+
+// writeFederatedDefinition writes the definition of the pipeline signing in with federated credentials, like
+// writeStagesDefinition. Nothing is written when the definition of the provider doesn't depend on the auth type.
+func (manager *PipelineManager) writeFederatedDefinition(ctx context.Context) error {
+	relativePath, definition, err := manager.CiProvider.federatedDefinition()
+	if err != nil {
+		return fmt.Errorf("creating the definition of the pipeline: %w", err)
+	}
+
+	if relativePath == "" {
+		return nil
+	}
+
+	return manager.writeDefinition(
+		ctx, relativePath, definition, "the pipeline signing in with the federated credentials of the service principal")
+}
+
+// writePartitionDefinition writes the definition of the pipeline of the partition, like writeStagesDefinition. A
+// pipeline provisioning and deploying for every change, in the same folder, is kept and reported.
+func (manager *PipelineManager) writePartitionDefinition(
+	ctx context.Context,
+	partition *pipelinePartition,
+	provisioningProvider provisioning.Options,
+) error {
+	relativePath, definition, err := manager.CiProvider.partitionDefinition(
+		partition, provisioningProvider, manager.authType)
+	if err != nil {
+		return fmt.Errorf("creating the definition of the pipeline of the %s: %w", partition.name, err)
+	}
+
+	combinedPath := filepath.Join(filepath.Dir(relativePath), "azure-dev.yml")
+	if _, err := os.Stat(filepath.Join(manager.AzdCtx.ProjectDirectory(), combinedPath)); err == nil {
+		input.GetConsole(ctx).Message(ctx, output.WithWarningFormat(
+			"%s still provisions and deploys for every change, delete it to only run the pipelines of the "+
+				"infrastructure and the code.", combinedPath))
+	}
+
+	description := "the pipeline provisioning the infrastructure for its changes"
+	if partition.name == partitionCode {
+		description = "the pipeline deploying the code for its changes"
+	}
+
+	return manager.writeDefinition(ctx, relativePath, definition, description)
+}
+
+// writePreviewDefinition writes the definition of the pipeline deploying each pull request to its own environment, like
+// writeStagesDefinition
+func (manager *PipelineManager) writePreviewDefinition(
+	ctx context.Context,
+	provisioningProvider provisioning.Options,
+) error {
+	relativePath, definition, err := manager.CiProvider.previewDefinition(provisioningProvider, manager.authType)
+	if err != nil {
+		return fmt.Errorf("creating the definition of the preview environments pipeline: %w", err)
+	}
+
+	return manager.writeDefinition(
+		ctx, relativePath, definition, "the pipeline deploying each pull request to a preview environment")
+}
+
+// writeCleanupDefinition writes the definition of the pipeline deleting the expired environments, like
+// writeStagesDefinition
+func (manager *PipelineManager) writeCleanupDefinition(ctx context.Context) error {
+	relativePath, definition, err := manager.CiProvider.cleanupDefinition(manager.authType)
+	if err != nil {
+		return fmt.Errorf("creating the definition of the cleanup pipeline: %w", err)
+	}
+
+	return manager.writeDefinition(
+		ctx, relativePath, definition, "the pipeline deleting the expired environments on a schedule")
+}
+
+// federatedUnsupported describes why the pipeline can't sign in with federated credentials, empty when it can
+func (manager *PipelineManager) federatedUnsupported(
+	infraOptions provisioning.Options,
+	stages []*pipelineStage,
+) string {
+	switch {
+	case len(stages) > 0:
+		return "a multi-stage pipeline"
+	case infraOptions.Provider == provisioning.Terraform:
+		return "a pipeline provisioning with Terraform"
+	case manager.RotateCredentials || manager.RotationSchedule != "":
+		return "a pipeline rotating its credential"
+	default:
+		return ""
+	}
+}
+
+// saveAuthType records the auth type of the configured pipeline in the environment, the next configuration keeps it
+func (manager *PipelineManager) saveAuthType() error {
+	if manager.authType == "" {
+		return nil
+	}
+
+	manager.Environment.Values[authTypePersistedKey] = string(manager.authType)
+	if err := manager.Environment.Save(); err != nil {
+		return fmt.Errorf("saving the auth type of the pipeline: %w", err)
+	}
+
+	return nil
+}
+
+// writeDefinition writes the definition of a pipeline, described by description, to relativePath in the project.
+// Replacing a different definition is confirmed by the user.
+func (manager *PipelineManager) writeDefinition(
+	ctx context.Context,
+	relativePath string,
+	definition []byte,
+	description string,
+) error {
+	console := input.GetConsole(ctx)
+
+	definitionPath := filepath.Join(manager.AzdCtx.ProjectDirectory(), relativePath)
+	existing, err := os.ReadFile(definitionPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading pipeline definition: %w", err)
+	}
+
+	definition = stampDefinition(definition, manager.templateVersion)
+	if bytes.Equal(existing, definition) {
+		return nil
+	}
+
+	// The definition only differs by the versions it was generated from, it's updated without confirmation
+	regenerated := err == nil && bytes.Equal(unstampedDefinition(existing), unstampedDefinition(definition))
+
+	if err == nil && !regenerated {
+		if outdated, ok := checkDefinition(relativePath, existing); ok {
+			console.Message(ctx, output.WithWarningFormat(
+				"%s predates changes of the generated pipelines: %s.", relativePath, strings.Join(outdated.Changes, "; ")))
 		}
 
-		gitRepoInfo.pushStatus = true
-		err = manager.ScmProvider.postGitPush(
-			ctx,
-			gitRepoInfo,
-			manager.PipelineRemoteName,
-			currentBranch,
-			inputConsole)
+		replace, err := console.Confirm(ctx, input.ConsoleOptions{
+			Message:      fmt.Sprintf("Would you like to replace %s with %s?", relativePath, description),
+			DefaultValue: true,
+		})
 		if err != nil {
-			return fmt.Errorf("post git push hook: %w", err)
+			return fmt.Errorf("prompting to replace the pipeline definition: %w", err)
 		}
-	} else {
-		inputConsole.Message(ctx,
-			fmt.Sprintf(
-				"To fully enable pipeline you need to push this repo to the upstream using 'git push --set-upstream %s %s'.\n",
-				manager.PipelineRemoteName,
-				currentBranch))
+
+		if !replace {
+			console.Message(ctx, fmt.Sprintf("Keeping %s, update it to be %s.\n", relativePath, description))
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(definitionPath), osutil.PermissionDirectory); err != nil {
+		return fmt.Errorf("creating pipeline definition directory: %w", err)
+	}
+
+	if err := os.WriteFile(definitionPath, definition, osutil.PermissionFile); err != nil {
+		return fmt.Errorf("writing pipeline definition: %w", err)
+	}
+
+	if !regenerated {
+		console.Message(ctx, fmt.Sprintf("Wrote %s to %s.\n", description, relativePath))
 	}
 
 	return nil
 }
+
+func stageNames(stages []*pipelineStage) []string {
+	names := make([]string, len(stages))
+	for index, stage := range stages {
+		names[index] = stage.name
+	}
+
+	return names
+}
+
+// summarize prints the summary of the configured pipeline and saves it in the environment directory
+func (manager *PipelineManager) summarize(
+	ctx context.Context,
+	gitRepoInfo *gitRepositoryDetails,
+	credentials json.RawMessage,
+	stages []*pipelineStage,
+) {
+	summary := &Summary{
+		Provider: manager.CiProvider.name(),
+		AuthType: string(manager.authType),
+	}
+
+	if manager.ManagedIdentityClientId != "" {
+		summary.ManagedIdentityClientId = manager.ManagedIdentityClientId
+	} else if len(stages) == 0 && !manager.ExistingServiceConnection {
+		summary.ServicePrincipalName = manager.PipelineServicePrincipalName
+		summary.ServicePrincipalAppId = servicePrincipalAppId(credentials)
+	}
+
+	for _, stage := range stages {
+		summary.Stages = append(summary.Stages, StageSummary{
+			Name:                  stage.name,
+			Environment:           stage.env.GetEnvName(),
+			ServicePrincipalName:  stage.principalName,
+			ServicePrincipalAppId: servicePrincipalAppId(stage.credentials),
+		})
+	}
+
+	manager.CiProvider.summarize(gitRepoInfo, summary)
+	summary.print(ctx, input.GetConsole(ctx))
+
+	// The summary is informational, azd show doesn't display the pipeline when it can't be saved
+	if err := summary.save(summaryPath(manager.AzdCtx, manager.Environment)); err != nil {
+		log.Printf("failed saving pipeline summary: %v", err)
+	}
+}
@@ -5,27 +5,49 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path"
+	"strings"
 	"testing"
 
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/events"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/fields"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/console"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 func Test_detectProviders(t *testing.T) {
 	tempDir := t.TempDir()
-	ctx := context.Background()
+	mockContext := mocks.NewMockContext(context.Background())
+	// The providers are detected from the folders when the project has no remote
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "remote get-url origin")
+	}).Respond(exec.NewRunResult(2, "", "error: No such remote 'origin'"))
+	ctx := *mockContext.Context
 
 	azdContext := &azdcontext.AzdContext{}
 	azdContext.SetProjectDirectory(tempDir)
 
+	// The providers are detected without a default provider in the user config
+	userConfig := config.NewConfig(nil)
+
 	t.Run("no folders error", func(t *testing.T) {
-		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, &environment.Environment{}, "")
+		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, &environment.Environment{}, userConfig, "origin", "")
 		assert.Nil(t, scmProvider)
 		assert.Nil(t, ciProvider)
 		assert.EqualError(
@@ -40,7 +62,7 @@ func Test_detectProviders(t *testing.T) {
 		err := os.Mkdir(ghFolder, osutil.PermissionDirectory)
 		assert.NoError(t, err)
 
-		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, &environment.Environment{}, "")
+		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, &environment.Environment{}, userConfig, "origin", "")
 		assert.Nil(t, scmProvider)
 		assert.Nil(t, ciProvider)
 		assert.ErrorContains(
@@ -65,7 +87,7 @@ func Test_detectProviders(t *testing.T) {
 		}
 		// set a console for ctx
 		ctx = input.WithConsole(ctx, console.NewMockConsole())
-		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, env, "")
+		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, env, userConfig, "origin", "")
 		assert.Nil(t, scmProvider)
 		assert.Nil(t, ciProvider)
 		assert.EqualError(t, err, ".azdo folder is missing. Can't use selected provider.")
@@ -83,7 +105,7 @@ func Test_detectProviders(t *testing.T) {
 			Values: envValues,
 		}
 
-		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, env, "")
+		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, env, userConfig, "origin", "")
 		assert.IsType(t, &AzdoScmProvider{}, scmProvider)
 		assert.IsType(t, &AzdoCiProvider{}, ciProvider)
 		assert.NoError(t, err)
@@ -100,7 +122,7 @@ func Test_detectProviders(t *testing.T) {
 		env := &environment.Environment{
 			Values: envValues,
 		}
-		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, env, "")
+		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, env, userConfig, "origin", "")
 		assert.Nil(t, scmProvider)
 		assert.Nil(t, ciProvider)
 		assert.EqualError(t, err, ".github folder is missing. Can't use selected provider.")
@@ -118,7 +140,7 @@ func Test_detectProviders(t *testing.T) {
 			Values: envValues,
 		}
 
-		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, env, "")
+		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, env, userConfig, "origin", "")
 		assert.IsType(t, &GitHubScmProvider{}, scmProvider)
 		assert.IsType(t, &GitHubCiProvider{}, ciProvider)
 		assert.NoError(t, err)
@@ -135,6 +157,8 @@ func Test_detectProviders(t *testing.T) {
 			ctx,
 			azdContext,
 			&environment.Environment{Values: map[string]string{}},
+			userConfig,
+			"origin",
 			"other",
 		)
 		assert.Nil(t, scmProvider)
@@ -155,7 +179,7 @@ func Test_detectProviders(t *testing.T) {
 			Values: envValues,
 		}
 
-		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, env, "")
+		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, env, userConfig, "origin", "")
 		assert.Nil(t, scmProvider)
 		assert.Nil(t, ciProvider)
 		assert.EqualError(t, err, "other is not a known pipeline provider.")
@@ -171,7 +195,7 @@ func Test_detectProviders(t *testing.T) {
 		_, err = projectFile.WriteString("pipeline:\n\r  provider: other")
 		assert.NoError(t, err)
 
-		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, &environment.Environment{}, "")
+		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, &environment.Environment{}, userConfig, "origin", "")
 		assert.Nil(t, scmProvider)
 		assert.Nil(t, ciProvider)
 		assert.EqualError(t, err, "other is not a known pipeline provider.")
@@ -197,7 +221,7 @@ func Test_detectProviders(t *testing.T) {
 			Values: envValues,
 		}
 
-		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, env, "")
+		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, env, userConfig, "origin", "")
 		assert.Nil(t, scmProvider)
 		assert.Nil(t, ciProvider)
 		assert.EqualError(t, err, "fromYaml is not a known pipeline provider.")
@@ -223,7 +247,7 @@ func Test_detectProviders(t *testing.T) {
 			Values: envValues,
 		}
 
-		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, env, "arg")
+		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, env, userConfig, "origin", "arg")
 		assert.Nil(t, scmProvider)
 		assert.Nil(t, ciProvider)
 		assert.EqualError(t, err, "arg is not a known pipeline provider.")
@@ -244,6 +268,8 @@ func Test_detectProviders(t *testing.T) {
 			ctx,
 			azdContext,
 			&environment.Environment{Values: map[string]string{}},
+			userConfig,
+			"origin",
 			"",
 		)
 		assert.IsType(t, &GitHubScmProvider{}, scmProvider)
@@ -261,6 +287,8 @@ func Test_detectProviders(t *testing.T) {
 			ctx,
 			azdContext,
 			&environment.Environment{Values: map[string]string{}},
+			userConfig,
+			"origin",
 			"",
 		)
 		assert.IsType(t, &AzdoScmProvider{}, scmProvider)
@@ -281,6 +309,8 @@ func Test_detectProviders(t *testing.T) {
 			ctx,
 			azdContext,
 			&environment.Environment{Values: map[string]string{}},
+			userConfig,
+			"origin",
 			"",
 		)
 		assert.IsType(t, &GitHubScmProvider{}, scmProvider)
@@ -290,6 +320,32 @@ func Test_detectProviders(t *testing.T) {
 		os.Remove(ghFolder)
 		os.Remove(azdoFolder)
 	})
+	t.Run("both folders and user default provider", func(t *testing.T) {
+		ghFolder := path.Join(tempDir, githubFolder)
+		err := os.Mkdir(ghFolder, osutil.PermissionDirectory)
+		assert.NoError(t, err)
+		azdoFolder := path.Join(tempDir, azdoFolder)
+		err = os.Mkdir(azdoFolder, osutil.PermissionDirectory)
+		assert.NoError(t, err)
+
+		azdoUserConfig := config.NewConfig(nil)
+		assert.NoError(t, azdoUserConfig.Set(DefaultProviderConfigPath, azdoLabel))
+
+		scmProvider, ciProvider, err := DetectProviders(
+			ctx,
+			azdContext,
+			&environment.Environment{Values: map[string]string{}},
+			azdoUserConfig,
+			"origin",
+			"",
+		)
+		assert.IsType(t, &AzdoScmProvider{}, scmProvider)
+		assert.IsType(t, &AzdoCiProvider{}, ciProvider)
+		assert.NoError(t, err)
+
+		os.Remove(ghFolder)
+		os.Remove(azdoFolder)
+	})
 	t.Run("persist selection on environment", func(t *testing.T) {
 		azdoFolder := path.Join(tempDir, azdoFolder)
 		err = os.Mkdir(azdoFolder, osutil.PermissionDirectory)
@@ -297,7 +353,7 @@ func Test_detectProviders(t *testing.T) {
 
 		env := &environment.Environment{Values: map[string]string{}}
 
-		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, env, azdoLabel)
+		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, env, userConfig, "origin", azdoLabel)
 		assert.IsType(t, &AzdoScmProvider{}, scmProvider)
 		assert.IsType(t, &AzdoCiProvider{}, ciProvider)
 		assert.NoError(t, err)
@@ -307,7 +363,7 @@ func Test_detectProviders(t *testing.T) {
 		assert.Equal(t, azdoLabel, envValue)
 
 		// Calling function again with same env and without override arg should use the persisted
-		scmProvider, ciProvider, err = DetectProviders(ctx, azdContext, env, "")
+		scmProvider, ciProvider, err = DetectProviders(ctx, azdContext, env, userConfig, "origin", "")
 		assert.IsType(t, &AzdoScmProvider{}, scmProvider)
 		assert.IsType(t, &AzdoCiProvider{}, ciProvider)
 		assert.NoError(t, err)
@@ -327,13 +383,13 @@ func Test_detectProviders(t *testing.T) {
 
 		env := &environment.Environment{Values: map[string]string{}}
 
-		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, env, azdoLabel)
+		scmProvider, ciProvider, err := DetectProviders(ctx, azdContext, env, userConfig, "origin", azdoLabel)
 		assert.IsType(t, &AzdoScmProvider{}, scmProvider)
 		assert.IsType(t, &AzdoCiProvider{}, ciProvider)
 		assert.NoError(t, err)
 
 		// Calling function again with same env and without override arg should use the persisted
-		scmProvider, ciProvider, err = DetectProviders(ctx, azdContext, env, "")
+		scmProvider, ciProvider, err = DetectProviders(ctx, azdContext, env, userConfig, "origin", "")
 		assert.IsType(t, &AzdoScmProvider{}, scmProvider)
 		assert.IsType(t, &AzdoCiProvider{}, ciProvider)
 		assert.NoError(t, err)
@@ -344,7 +400,7 @@ func Test_detectProviders(t *testing.T) {
 
 		// Calling function again with same env and without override arg should detect yaml change and override
 		// persisted
-		scmProvider, ciProvider, err = DetectProviders(ctx, azdContext, env, "")
+		scmProvider, ciProvider, err = DetectProviders(ctx, azdContext, env, userConfig, "origin", "")
 		assert.IsType(t, &GitHubScmProvider{}, scmProvider)
 		assert.IsType(t, &GitHubCiProvider{}, ciProvider)
 		assert.NoError(t, err)
@@ -355,13 +411,13 @@ func Test_detectProviders(t *testing.T) {
 		assert.Equal(t, gitHubLabel, envValue)
 
 		// Call again to check persisted(github) after one change (and yaml is still present)
-		scmProvider, ciProvider, err = DetectProviders(ctx, azdContext, env, "")
+		scmProvider, ciProvider, err = DetectProviders(ctx, azdContext, env, userConfig, "origin", "")
 		assert.IsType(t, &GitHubScmProvider{}, scmProvider)
 		assert.IsType(t, &GitHubCiProvider{}, ciProvider)
 		assert.NoError(t, err)
 
 		// Check argument override having yaml(github) config and persisted config(github)
-		scmProvider, ciProvider, err = DetectProviders(ctx, azdContext, env, azdoLabel)
+		scmProvider, ciProvider, err = DetectProviders(ctx, azdContext, env, userConfig, "origin", azdoLabel)
 		assert.IsType(t, &AzdoScmProvider{}, scmProvider)
 		assert.IsType(t, &AzdoCiProvider{}, ciProvider)
 		assert.NoError(t, err)
@@ -373,7 +429,7 @@ func Test_detectProviders(t *testing.T) {
 
 		// persisted = azdo (per last run) and yaml = github, should return github
 		// as yaml overrides a persisted run
-		scmProvider, ciProvider, err = DetectProviders(ctx, azdContext, env, "")
+		scmProvider, ciProvider, err = DetectProviders(ctx, azdContext, env, userConfig, "origin", "")
 		assert.IsType(t, &GitHubScmProvider{}, scmProvider)
 		assert.IsType(t, &GitHubCiProvider{}, ciProvider)
 		assert.NoError(t, err)
@@ -387,3 +443,384 @@ func Test_detectProviders(t *testing.T) {
 	})
 
 }
+
+func Test_userDefaultProvider(t *testing.T) {
+	assert.Equal(t, "", userDefaultProvider(config.NewConfig(nil)))
+
+	userConfig := config.NewConfig(nil)
+	assert.NoError(t, userConfig.Set(DefaultProviderConfigPath, " AzDo "))
+	assert.Equal(t, azdoLabel, userDefaultProvider(userConfig))
+}
+
+func Test_runStep(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	originalProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(spanRecorder)))
+	defer otel.SetTracerProvider(originalProvider)
+
+	ctx, parentSpan := telemetry.GetTracer().Start(context.Background(), events.PipelineConfigEventName)
+
+	err := runStep(ctx, stepPrincipal, func(ctx context.Context) error {
+		return nil
+	})
+	assert.NoError(t, err)
+
+	err = runStep(ctx, stepRemote, func(ctx context.Context) error {
+		return errors.New("no remote")
+	})
+	assert.EqualError(t, err, "no remote")
+	parentSpan.End()
+
+	spans := spanRecorder.Ended()
+	assert.Len(t, spans, 3)
+
+	assert.Equal(t, events.PipelineConfigEventPrefix+stepPrincipal, spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.Equal(t, events.PipelineConfigEventPrefix+stepRemote, spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+
+	assert.Equal(t, events.PipelineConfigEventName, spans[2].Name())
+	assert.Contains(t, spans[2].Attributes(), fields.PipelineFailedStepKey.String(stepRemote))
+}
+
+func Test_setConfigAttributes(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	originalProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(trace.NewTracerProvider(trace.WithSpanProcessor(spanRecorder)))
+	defer otel.SetTracerProvider(originalProvider)
+
+	ctx, configSpan := telemetry.GetTracer().Start(context.Background(), events.PipelineConfigEventName)
+	ctx = context.WithValue(ctx, configSpanContextKey{}, oteltrace.SpanFromContext(ctx))
+
+	err := runStep(ctx, stepRemote, func(ctx context.Context) error {
+		setConfigAttributes(ctx, fields.PipelineRepositoryCreatedKey.Bool(true))
+		return nil
+	})
+	assert.NoError(t, err)
+	configSpan.End()
+
+	spans := spanRecorder.Ended()
+	assert.Len(t, spans, 2)
+
+	// The attribute is set on the pipeline.config span, not on the span of the step
+	assert.Empty(t, spans[0].Attributes())
+	assert.Equal(t, events.PipelineConfigEventName, spans[1].Name())
+	assert.Contains(t, spans[1].Attributes(), fields.PipelineRepositoryCreatedKey.Bool(true))
+}
+
+func Test_getGitRepoDetails_skipPush(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "remote get-url origin")
+	}).Respond(exec.NewRunResult(2, "", "error: No such remote 'origin'"))
+
+	azdContext := &azdcontext.AzdContext{}
+	azdContext.SetProjectDirectory(t.TempDir())
+	manager := NewPipelineManager(azdContext, nil, PipelineManagerArgs{PipelineRemoteName: "origin", SkipPush: true})
+	manager.ScmProvider = &AzdoScmProvider{}
+
+	// The remote isn't added, no confirmation is registered on the console
+	_, err := manager.getGitRepoDetails(*mockContext.Context)
+	assert.ErrorIs(t, err, git.ErrNoSuchRemote)
+	assert.ErrorContains(t, err, "with --skip-push")
+}
+
+func Test_PipelineManagerArgs_validate(t *testing.T) {
+	const clientId = "00000000-0000-0000-0000-000000000000"
+
+	tests := []struct {
+		name        string
+		args        PipelineManagerArgs
+		isAzdo      bool
+		expectedErr string
+	}{
+		{"Valid", PipelineManagerArgs{InfraOnly: true}, false, ""},
+		{"ValidAzdo", PipelineManagerArgs{VariableGroup: true, Branch: "develop"}, true, ""},
+		{
+			"ProjectIdRequiresAzdo", PipelineManagerArgs{AzdoProjectId: "12345"}, false,
+			"--project-id is only supported by the Azure DevOps pipeline provider",
+		},
+		{
+			"BranchRequiresAzdo", PipelineManagerArgs{Branch: "develop"}, false,
+			"--branch is only supported by the Azure DevOps pipeline provider",
+		},
+		{
+			"ExistingServiceConnectionRequiresAzdo", PipelineManagerArgs{ExistingServiceConnection: true}, false,
+			"--existing-service-connection is only supported by the Azure DevOps pipeline provider",
+		},
+		{
+			"VariableGroupRequiresAzdo", PipelineManagerArgs{VariableGroup: true}, false,
+			"--variable-group is only supported by the Azure DevOps pipeline provider",
+		},
+		{
+			"RotateAndSchedule", PipelineManagerArgs{RotateCredentials: true, RotationSchedule: "0 0 1 * *"}, false,
+			"--rotate-credentials and --rotation-schedule can't be used together",
+		},
+		{
+			"InvalidRotationSchedule", PipelineManagerArgs{RotationSchedule: "monthly"}, false,
+			"invalid rotation schedule 'monthly'",
+		},
+		{
+			"ManagedIdentityAndPrincipalName",
+			PipelineManagerArgs{ManagedIdentityClientId: clientId, PipelineServicePrincipalName: "sp"}, false,
+			"--managed-identity and --principal-name can't be used together",
+		},
+		{
+			"ManagedIdentityAndRotation", PipelineManagerArgs{ManagedIdentityClientId: clientId, RotateCredentials: true},
+			false, "a managed identity has no credential to rotate",
+		},
+		{
+			"ManagedIdentityAndSchedule",
+			PipelineManagerArgs{ManagedIdentityClientId: clientId, RotationSchedule: "0 0 1 * *"}, false,
+			"a managed identity has no credential to rotate",
+		},
+		{
+			"ManagedIdentityAndAuthType",
+			PipelineManagerArgs{ManagedIdentityClientId: clientId, PipelineAuthType: AuthTypeFederated}, false,
+			"--managed-identity and --auth-type can't be used together",
+		},
+		{
+			"ExistingServiceConnectionAndManagedIdentity",
+			PipelineManagerArgs{ExistingServiceConnection: true, ManagedIdentityClientId: clientId}, true,
+			"--existing-service-connection and --managed-identity can't be used together",
+		},
+		{
+			"ExistingServiceConnectionAndPrincipalName",
+			PipelineManagerArgs{ExistingServiceConnection: true, PipelineServicePrincipalName: "sp"}, true,
+			"--existing-service-connection and --principal-name can't be used together",
+		},
+		{
+			"ExistingServiceConnectionAndAuthType",
+			PipelineManagerArgs{ExistingServiceConnection: true, PipelineAuthType: AuthTypeClientSecret}, true,
+			"--existing-service-connection and --auth-type can't be used together",
+		},
+		{
+			"ExistingServiceConnectionAndRotation",
+			PipelineManagerArgs{ExistingServiceConnection: true, RotateCredentials: true}, true,
+			"the credential of an existing service connection isn't rotated by azd",
+		},
+		{
+			"ExistingServiceConnectionAndInfraOnly",
+			PipelineManagerArgs{ExistingServiceConnection: true, InfraOnly: true}, true,
+			"--infra-only and --code-only can't be used with --existing-service-connection",
+		},
+		{
+			"VariableGroupAndManagedIdentity",
+			PipelineManagerArgs{VariableGroup: true, ManagedIdentityClientId: clientId}, true,
+			"--variable-group and --managed-identity can't be used together",
+		},
+		{
+			"VariableGroupAndCodeOnly", PipelineManagerArgs{VariableGroup: true, CodeOnly: true}, true,
+			"--infra-only and --code-only can't be used with --variable-group",
+		},
+		{
+			"InfraOnlyAndManagedIdentity", PipelineManagerArgs{InfraOnly: true, ManagedIdentityClientId: clientId}, false,
+			"--infra-only and --code-only can't be used with --managed-identity",
+		},
+		{
+			"CodeOnlyAndRotation", PipelineManagerArgs{CodeOnly: true, RotateCredentials: true}, false,
+			"--infra-only and --code-only can't be used with --rotate-credentials",
+		},
+		{
+			"PreviewAndManagedIdentity",
+			PipelineManagerArgs{PreviewEnvironments: true, ManagedIdentityClientId: clientId}, false,
+			"--preview-environments can't be used with --managed-identity",
+		},
+		{
+			"PreviewAndRotation", PipelineManagerArgs{PreviewEnvironments: true, RotateCredentials: true}, false,
+			"--preview-environments can't be used with --rotate-credentials",
+		},
+		{"PreviewRequiresGitHub", PipelineManagerArgs{PreviewEnvironments: true}, true, errPreviewUnsupported.Error()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.args.validate(test.isAzdo)
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, test.expectedErr)
+			}
+		})
+	}
+
+	t.Run("ValidatedByConfigure", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		azdContext := &azdcontext.AzdContext{}
+		azdContext.SetProjectDirectory(t.TempDir())
+		manager := NewPipelineManager(azdContext, nil, PipelineManagerArgs{Branch: "develop"})
+		manager.ScmProvider = &GitHubScmProvider{}
+		manager.CiProvider = &GitHubCiProvider{}
+
+		err := manager.Configure(*mockContext.Context)
+		assert.EqualError(t, err, "--branch is only supported by the Azure DevOps pipeline provider")
+	})
+}
+
+func Test_SyncEnvironmentValue_notConfigured(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azdContext := &azdcontext.AzdContext{}
+	azdContext.SetProjectDirectory(t.TempDir())
+	manager := NewPipelineManager(azdContext, nil, PipelineManagerArgs{PipelineRemoteName: "origin"})
+	manager.Environment = environment.EphemeralWithValues("test", nil)
+
+	err := manager.SyncEnvironmentValue(*mockContext.Context, "KEY", "value")
+	assert.ErrorContains(t, err, "no pipeline is configured for environment 'test'")
+}
+
+func Test_detectProviderFromRemote(t *testing.T) {
+	const azdoRemote = "https://fake_org@dev.azure.com/fake_org/fake_project/_git/fake_repo"
+	const gitHubRemote = "https://github.com/Azure/azure-dev.git"
+
+	tests := []struct {
+		name              string
+		remoteUrl         string
+		hasGitHubFolder   bool
+		hasAzDevOpsFolder bool
+		confirm           bool
+		expected          string
+	}{
+		{name: "azdo remote", remoteUrl: azdoRemote, hasAzDevOpsFolder: true, expected: azdoLabel},
+		{name: "github remote", remoteUrl: gitHubRemote, hasGitHubFolder: true, expected: gitHubLabel},
+		{
+			name:              "azdo remote with both folders confirmed",
+			remoteUrl:         azdoRemote,
+			hasGitHubFolder:   true,
+			hasAzDevOpsFolder: true,
+			confirm:           true,
+			expected:          azdoLabel,
+		},
+		{
+			name:              "azdo remote with both folders declined",
+			remoteUrl:         azdoRemote,
+			hasGitHubFolder:   true,
+			hasAzDevOpsFolder: true,
+			confirm:           false,
+			expected:          gitHubLabel,
+		},
+		{name: "azdo remote without azdo folder", remoteUrl: azdoRemote, hasGitHubFolder: true, expected: gitHubLabel},
+		{
+			name:              "unknown host",
+			remoteUrl:         "https://gitlab.com/owner/repo.git",
+			hasGitHubFolder:   true,
+			hasAzDevOpsFolder: true,
+			expected:          "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+				return strings.Contains(command, "remote get-url origin")
+			}).Respond(exec.NewRunResult(0, test.remoteUrl+"\n", ""))
+			mockContext.Console.WhenConfirm(func(options input.ConsoleOptions) bool {
+				return strings.Contains(options.Message, "Would you like to use")
+			}).Respond(test.confirm)
+
+			provider, err := detectProviderFromRemote(
+				*mockContext.Context,
+				t.TempDir(),
+				"origin",
+				test.hasGitHubFolder,
+				test.hasAzDevOpsFolder,
+				mockContext.Console)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, provider)
+		})
+	}
+}
+
+func Test_pushGitRepo_authenticationFailure(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, " add .") || strings.Contains(command, " commit ") ||
+			strings.Contains(command, " ls-files ")
+	}).Respond(exec.NewRunResult(0, "", ""))
+
+	pushes := 0
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, " push ")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		pushes++
+		assert.Contains(t, args.Env, "GIT_TERMINAL_PROMPT=0")
+
+		if pushes == 1 {
+			return exec.NewRunResult(
+					128, "", "fatal: could not read Username for 'https://github.com': terminal prompts disabled"),
+				errors.New("exit status 128")
+		}
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	mockContext.Console.WhenConfirm(func(options input.ConsoleOptions) bool {
+		return options.Message == "Retry pushing the changes?"
+	}).Respond(true)
+
+	azdContext := &azdcontext.AzdContext{}
+	azdContext.SetProjectDirectory(t.TempDir())
+	manager := NewPipelineManager(azdContext, nil, PipelineManagerArgs{PipelineRemoteName: "origin"})
+
+	// The user is prompted to sign in and the push is retried
+	err := manager.pushGitRepo(*mockContext.Context, "main")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, pushes)
+}
+
+func Test_pushGitRepo_secretFiles(t *testing.T) {
+	setup := func(t *testing.T, trackedFiles string) (*mocks.MockContext, *PipelineManager, *bool) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, " add .") || strings.Contains(command, " commit ")
+		}).Respond(exec.NewRunResult(0, "", ""))
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, " ls-files ")
+		}).Respond(exec.NewRunResult(0, trackedFiles, ""))
+
+		pushed := false
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, " push ")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			pushed = true
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+		projectDir := t.TempDir()
+		assert.NoError(t, os.MkdirAll(path.Join(projectDir, "src", "api"), osutil.PermissionDirectory))
+		assert.NoError(t, os.WriteFile(path.Join(projectDir, "src", "api", ".env"), nil, osutil.PermissionFile))
+
+		azdContext := &azdcontext.AzdContext{}
+		azdContext.SetProjectDirectory(projectDir)
+		manager := NewPipelineManager(azdContext, nil, PipelineManagerArgs{PipelineRemoteName: "origin"})
+
+		return mockContext, manager, &pushed
+	}
+
+	t.Run("IgnoresSecretFiles", func(t *testing.T) {
+		mockContext, manager, pushed := setup(t, "")
+		mockContext.Console.WhenConfirm(func(options input.ConsoleOptions) bool {
+			return strings.Contains(options.Message, ".env")
+		}).Respond(true)
+
+		assert.NoError(t, manager.pushGitRepo(*mockContext.Context, "main"))
+		assert.True(t, *pushed)
+
+		gitignore, err := os.ReadFile(path.Join(manager.AzdCtx.ProjectDirectory(), ".gitignore"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(gitignore), ".azure")
+		assert.Contains(t, string(gitignore), ".env")
+	})
+
+	t.Run("RefusesTrackedFiles", func(t *testing.T) {
+		mockContext, manager, pushed := setup(t, "src/api/.env\n")
+		mockContext.Console.WhenConfirm(func(options input.ConsoleOptions) bool {
+			return strings.Contains(options.Message, ".env")
+		}).Respond(false)
+
+		err := manager.pushGitRepo(*mockContext.Context, "main")
+		assert.ErrorContains(t, err, "src/api/.env")
+		assert.False(t, *pushed)
+	})
+}
@@ -0,0 +1,166 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"text/template"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+)
+
+// errPreviewUnsupported is returned when preview environments are configured for a provider without pipelines run
+// when pull requests are closed, the preview environments wouldn't be deleted
+var errPreviewUnsupported = errors.New(
+	"preview environments are only supported by GitHub Actions, Azure DevOps doesn't run pipelines when a pull request " +
+		"is closed")
+
+// the data of the template of the workflow deploying the preview environments
+type previewTemplateData struct {
+	Federated bool
+	// the names of the variables of the environment azd runs in, except the name of the environment
+	Variables []string
+}
+
+// The GitHub workflow deploying each pull request to its own environment. The environments are created by azd from the
+// variables of the workflow, their name is the name of the environment of the pipeline suffixed with the number of the
+// pull request.
+const gitHubPreviewWorkflowTemplate = `# Generated by azd pipeline config --preview-environments
+# Deploys each pull request to its own environment, named after the environment of the pipeline and the number of the
+# pull request, and deletes the environment when the pull request is closed.
+on:
+  pull_request:
+    types: [opened, synchronize, reopened, closed]
+    branches:
+      - main
+      - master
+
+permissions:
+[[- if .Federated ]]
+  id-token: write
+[[- end ]]
+  contents: read
+  pull-requests: write
+
+concurrency:
+  group: azd-preview-${{ github.event.number }}
+
+env:
+  AZURE_ENV_NAME: ${{ vars.AZURE_ENV_NAME }}-pr-${{ github.event.number }}
+[[- range .Variables ]]
+  [[ . ]]: [[ github . ]]
+[[- end ]]
+
+jobs:
+  deploy:
+    if: github.event.action != 'closed'
+    runs-on: ubuntu-latest
+    container:
+      image: mcr.microsoft.com/azure-dev-cli-apps:latest
+    steps:
+      - name: Checkout
+        uses: actions/checkout@v2
+[[ template "login" . ]]
+
+      - name: Azure Dev Provision
+        run: azd provision --no-prompt
+
+      - name: Azure Dev Deploy
+        run: azd deploy --no-prompt --output json > azd-deploy.json
+
+      - name: Comment the endpoints
+        uses: actions/github-script@v6
+        with:
+          script: |
+            const fs = require('fs');
+            // The result of the deployment is the last object of the output, after the progress messages
+            const lines = fs.readFileSync('azd-deploy.json', 'utf8').split('\n');
+            const result = JSON.parse(lines.slice(lines.lastIndexOf('{')).join('\n'));
+            const endpoints = result.services.flatMap(service => service.endpoints || []);
+            const marker = '<!-- azd-preview-environment -->';
+            const body = [
+              marker,
+              'Deployed the preview environment **' + process.env.AZURE_ENV_NAME + '**:',
+              ...endpoints.map(endpoint => '- ' + endpoint),
+            ].join('\n');
+            const { data: comments } = await github.rest.issues.listComments({
+              ...context.repo,
+              issue_number: context.issue.number,
+            });
+            const existing = comments.find(comment => comment.body.startsWith(marker));
+            if (existing) {
+              await github.rest.issues.updateComment({ ...context.repo, comment_id: existing.id, body });
+            } else {
+              await github.rest.issues.createComment({ ...context.repo, issue_number: context.issue.number, body });
+            }
+
+  teardown:
+    if: github.event.action == 'closed'
+    runs-on: ubuntu-latest
+    container:
+      image: mcr.microsoft.com/azure-dev-cli-apps:latest
+    steps:
+      - name: Checkout
+        uses: actions/checkout@v2
+[[ template "login" . ]]
+
+      - name: Azure Dev Down
+        run: azd down --force --purge --no-prompt
+[[- define "login" ]]
+[[- if .Federated ]]
+      - name: Log in with Azure (Federated Credentials)
+        uses: azure/login@v1
+        with:
+          client-id: ${{ env.AZURE_CLIENT_ID }}
+          tenant-id: ${{ env.AZURE_TENANT_ID }}
+          subscription-id: ${{ env.AZURE_SUBSCRIPTION_ID }}
+[[- else ]]
+      - name: Log in with Azure
+        uses: azure/login@v1
+        with:
+          creds: ${{ secrets.AZURE_CREDENTIALS }}
+[[- end ]]
+[[- end ]]
+`
+
+// gitHubPreviewWorkflow returns the GitHub workflow deploying the pull requests to preview environments, signing in
+// with the auth type
+func gitHubPreviewWorkflow(provisioningProvider provisioning.Options, authType AuthType) ([]byte, error) {
+	tmpl, err := template.New("github preview workflow").Delims("[[", "]]").
+		Funcs(gitHubTemplateFuncs).
+		Parse(gitHubPreviewWorkflowTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("parsing github preview workflow template: %w", err)
+	}
+
+	data := previewTemplateData{Federated: authType == AuthTypeFederated}
+
+	// The subscription is a variable of both the principal and the environment, it's only set once
+	if data.Federated {
+		data.Variables = append(data.Variables, federatedIdentityVariables...)
+	}
+	for _, name := range managedIdentityVariables {
+		if name == environment.EnvNameEnvVarName || (data.Federated && name == environment.SubscriptionIdEnvVarName) {
+			continue
+		}
+		data.Variables = append(data.Variables, name)
+	}
+
+	// Terraform reads and writes the state of the preview environments in the remote state
+	if provisioningProvider.Provider == provisioning.Terraform {
+		data.Variables = append(data.Variables,
+			"ARM_TENANT_ID", "ARM_CLIENT_ID", "ARM_CLIENT_SECRET",
+			"RS_RESOURCE_GROUP", "RS_STORAGE_ACCOUNT", "RS_CONTAINER_NAME")
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("executing github preview workflow template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
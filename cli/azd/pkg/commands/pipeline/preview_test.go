@@ -0,0 +1,87 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type gitHubPreviewWorkflowYaml struct {
+	On struct {
+		PullRequest struct {
+			Types []string `yaml:"types"`
+		} `yaml:"pull_request"`
+	} `yaml:"on"`
+	Permissions map[string]string `yaml:"permissions"`
+	Env         map[string]string `yaml:"env"`
+	Jobs        map[string]struct {
+		If    string `yaml:"if"`
+		Steps []struct {
+			Name string            `yaml:"name"`
+			Run  string            `yaml:"run"`
+			Uses string            `yaml:"uses"`
+			With map[string]string `yaml:"with"`
+		} `yaml:"steps"`
+	} `yaml:"jobs"`
+}
+
+func Test_gitHubPreviewWorkflow(t *testing.T) {
+	t.Run("ClientSecret", func(t *testing.T) {
+		content, err := gitHubPreviewWorkflow(provisioning.Options{}, AuthTypeClientSecret)
+		require.NoError(t, err)
+
+		var workflow gitHubPreviewWorkflowYaml
+		require.NoError(t, yaml.Unmarshal(content, &workflow))
+
+		require.Contains(t, workflow.On.PullRequest.Types, "closed")
+		require.Equal(t, map[string]string{"contents": "read", "pull-requests": "write"}, workflow.Permissions)
+		require.Equal(t, map[string]string{
+			"AZURE_ENV_NAME":        "${{ vars.AZURE_ENV_NAME }}-pr-${{ github.event.number }}",
+			"AZURE_LOCATION":        "${{ vars.AZURE_LOCATION }}",
+			"AZURE_SUBSCRIPTION_ID": "${{ vars.AZURE_SUBSCRIPTION_ID }}",
+		}, workflow.Env)
+
+		deploy := workflow.Jobs["deploy"]
+		require.Equal(t, "github.event.action != 'closed'", deploy.If)
+		require.Equal(t, "${{ secrets.AZURE_CREDENTIALS }}", deploy.Steps[1].With["creds"])
+		require.Equal(t, "azd provision --no-prompt", deploy.Steps[2].Run)
+		require.Equal(t, "azd deploy --no-prompt --output json > azd-deploy.json", deploy.Steps[3].Run)
+		require.Equal(t, "actions/github-script@v6", deploy.Steps[4].Uses)
+		require.Contains(t, deploy.Steps[4].With["script"], "createComment")
+
+		teardown := workflow.Jobs["teardown"]
+		require.Equal(t, "github.event.action == 'closed'", teardown.If)
+		require.Equal(t, "${{ secrets.AZURE_CREDENTIALS }}", teardown.Steps[1].With["creds"])
+		require.Equal(t, "azd down --force --purge --no-prompt", teardown.Steps[2].Run)
+	})
+
+	t.Run("Federated", func(t *testing.T) {
+		content, err := gitHubPreviewWorkflow(provisioning.Options{}, AuthTypeFederated)
+		require.NoError(t, err)
+
+		var workflow gitHubPreviewWorkflowYaml
+		require.NoError(t, yaml.Unmarshal(content, &workflow))
+
+		require.Equal(t, "write", workflow.Permissions["id-token"])
+		require.Equal(t, "${{ vars.AZURE_CLIENT_ID }}", workflow.Env["AZURE_CLIENT_ID"])
+		require.Equal(t, "${{ vars.AZURE_SUBSCRIPTION_ID }}", workflow.Env["AZURE_SUBSCRIPTION_ID"])
+		for _, job := range []string{"deploy", "teardown"} {
+			require.Equal(t, "${{ env.AZURE_CLIENT_ID }}", workflow.Jobs[job].Steps[1].With["client-id"])
+		}
+	})
+
+	t.Run("Terraform", func(t *testing.T) {
+		content, err := gitHubPreviewWorkflow(provisioning.Options{Provider: provisioning.Terraform}, AuthTypeClientSecret)
+		require.NoError(t, err)
+
+		var workflow gitHubPreviewWorkflowYaml
+		require.NoError(t, yaml.Unmarshal(content, &workflow))
+		require.Equal(t, "${{ secrets.ARM_CLIENT_SECRET }}", workflow.Env["ARM_CLIENT_SECRET"])
+		require.Equal(t, "${{ vars.RS_STORAGE_ACCOUNT }}", workflow.Env["RS_STORAGE_ACCOUNT"])
+	})
+}
@@ -0,0 +1,68 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// The characters replaced in the default name of the service principal
+var principalNameInvalidCharsRegex = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// defaultPrincipalName returns the name of the service principal of the pipeline when --principal-name isn't set,
+// azd-<project>-<environment>, so the principals created for a project can be found and cleaned up later
+func defaultPrincipalName(projectName string, envName string) string {
+	sanitize := func(value string) string {
+		return strings.Trim(principalNameInvalidCharsRegex.ReplaceAllString(strings.ToLower(value), "-"), "-")
+	}
+
+	parts := []string{"azd"}
+	for _, part := range []string{sanitize(projectName), sanitize(envName)} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, "-")
+}
+
+// tagServicePrincipals saves the project, the environment and the repository of the pipeline in the tags and notes of
+// the applications of the service principals of the pipeline, listed by `azd pipeline list-principals`
+func (manager *PipelineManager) tagServicePrincipals(
+	ctx context.Context,
+	projectName string,
+	repoDetails *gitRepositoryDetails,
+	stages []*pipelineStage,
+) error {
+	// The provider knows the web url of the repository, it's part of its summary
+	repository := &Summary{}
+	manager.CiProvider.summarize(repoDetails, repository)
+
+	principals := map[string]string{}
+	if len(stages) == 0 {
+		principals[manager.PipelineServicePrincipalName] = manager.Environment.GetEnvName()
+	}
+	for _, stage := range stages {
+		principals[stage.principalName] = stage.env.GetEnvName()
+	}
+
+	azCli := azcli.GetAzCli(ctx)
+	for principalName, envName := range principals {
+		err := azCli.UpdateServicePrincipalMetadata(ctx, principalName, azcli.ServicePrincipalMetadata{
+			Project:       projectName,
+			Environment:   envName,
+			RepositoryUrl: repository.RepositoryUrl,
+		})
+		if err != nil {
+			return fmt.Errorf("tagging service principal %s: %w", principalName, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_defaultPrincipalName(t *testing.T) {
+	require.Equal(t, "azd-todo-app-dev", defaultPrincipalName("todo-app", "dev"))
+	require.Equal(t, "azd-my-app-prod-eu", defaultPrincipalName("My App", "Prod_EU"))
+	require.Equal(t, "azd-dev", defaultPrincipalName("", "dev"))
+}
@@ -0,0 +1,156 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azdo"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+)
+
+// The secret holding the token the GitHub workflow rotating the credential sets the repository secrets with. The
+// token of the workflow run isn't allowed to set secrets, so the secret is created by the user.
+const gitHubRotationTokenSecretName = "AZD_ROTATION_GITHUB_TOKEN"
+
+// A cron schedule of 5 fields: minute, hour, day of month, month and day of week
+var cronScheduleRegex = regexp.MustCompile(`^\S+( \S+){4}$`)
+
+// validateRotationSchedule returns an error when schedule isn't a cron schedule
+func validateRotationSchedule(schedule string) error {
+	if !cronScheduleRegex.MatchString(strings.TrimSpace(schedule)) {
+		return fmt.Errorf(
+			"invalid rotation schedule '%s', the schedule is a cron expression of 5 fields, i.e. '0 0 1 * *' "+
+				"for the first day of every month",
+			schedule)
+	}
+
+	return nil
+}
+
+// the data of the templates of the definitions rotating the credential
+type rotationTemplateData struct {
+	Schedule      string
+	PrincipalName string
+	// the names of the variables of the environment azd runs in
+	Variables []string
+	// the secret holding the token azd sets the GitHub secrets with
+	TokenSecret string
+	// the service connection the Azure DevOps pipeline signs in with
+	ServiceConnection string
+}
+
+func newRotationTemplateData(schedule string, principalName string) rotationTemplateData {
+	return rotationTemplateData{
+		Schedule:      strings.TrimSpace(schedule),
+		PrincipalName: principalName,
+		Variables: []string{
+			environment.EnvNameEnvVarName,
+			environment.LocationEnvVarName,
+			environment.SubscriptionIdEnvVarName,
+		},
+		TokenSecret:       gitHubRotationTokenSecretName,
+		ServiceConnection: azdo.ServiceConnectionName,
+	}
+}
+
+// The GitHub workflow rotating the credential on a schedule, signing in with the credential being rotated
+const gitHubRotationWorkflowTemplate = `# Generated by azd pipeline config --rotation-schedule
+# Rotates the credential of the service principal of the pipeline and sets the new credential in the secrets of the
+# repository. The [[ .TokenSecret ]] secret holds a token allowed to set the secrets of the repository.
+on:
+  workflow_dispatch:
+  schedule:
+    - cron: '[[ .Schedule ]]'
+
+jobs:
+  rotate:
+    runs-on: ubuntu-latest
+    container:
+      image: mcr.microsoft.com/azure-dev-cli-apps:latest
+    steps:
+      - name: Checkout
+        uses: actions/checkout@v2
+
+      - name: Log in with Azure
+        uses: azure/login@v1
+        with:
+          creds: ${{ secrets.AZURE_CREDENTIALS }}
+
+      - name: Rotate the credential
+        run: azd pipeline config --rotate-credentials --provider github --principal-name [[ .PrincipalName ]] --no-prompt
+        env:
+[[- range .Variables ]]
+          [[ . ]]: [[ github . ]]
+[[- end ]]
+          GH_TOKEN: ${{ secrets.[[ .TokenSecret ]] }}
+`
+
+// The Azure DevOps pipeline rotating the credential on a schedule, signing in with the service connection whose
+// credential is rotated
+const azdoRotationPipelineTemplate = `# Generated by azd pipeline config --rotation-schedule
+# Rotates the credential of the service principal of the pipeline and sets the new credential in the service
+# connection and the variables of the pipeline. The AZURE_DEVOPS_EXT_PAT secret variable of the pipeline holds a
+# personal access token allowed to manage the service connections and the pipelines of the project.
+trigger: none
+
+schedules:
+  - cron: '[[ .Schedule ]]'
+    displayName: Rotate the credential
+    branches:
+      include:
+        - main
+        - master
+    always: true
+
+pool:
+  vmImage: ubuntu-latest
+
+jobs:
+  - job: Rotate
+    container: mcr.microsoft.com/azure-dev-cli-apps:latest
+    steps:
+      - task: AzureCLI@2
+        displayName: Rotate the credential
+        inputs:
+          azureSubscription: [[ .ServiceConnection ]]
+          scriptType: bash
+          scriptLocation: inlineScript
+          inlineScript: |
+            azd pipeline config --rotate-credentials --provider azdo --principal-name [[ .PrincipalName ]] --no-prompt
+        env:
+[[- range .Variables ]]
+          [[ . ]]: $([[ . ]])
+[[- end ]]
+          AZURE_DEVOPS_ORG_NAME: $(AZURE_DEVOPS_ORG_NAME)
+          AZURE_DEVOPS_EXT_PAT: $(AZURE_DEVOPS_EXT_PAT)
+`
+
+// gitHubRotationWorkflow returns the GitHub workflow rotating the credential of the principal on the schedule
+func gitHubRotationWorkflow(schedule string, principalName string) ([]byte, error) {
+	return executeDefinitionTemplate(
+		"github rotation workflow",
+		gitHubRotationWorkflowTemplate,
+		gitHubTemplateFuncs,
+		newRotationTemplateData(schedule, principalName),
+	)
+}
+
+// azdoRotationPipeline returns the Azure DevOps pipeline rotating the credential of the principal on the schedule
+func azdoRotationPipeline(schedule string, principalName string) ([]byte, error) {
+	return executeDefinitionTemplate(
+		"azure devops rotation pipeline",
+		azdoRotationPipelineTemplate,
+		gitHubTemplateFuncs,
+		newRotationTemplateData(schedule, principalName),
+	)
+}
+
+// errRotationNotConfigured is returned when the credential is rotated for an environment without a pipeline
+var errRotationNotConfigured = errors.New(
+	"no pipeline is configured for the environment, run `azd pipeline config` first or set the service principal " +
+		"with --principal-name")
@@ -0,0 +1,106 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_validateRotationSchedule(t *testing.T) {
+	require.NoError(t, validateRotationSchedule("0 0 1 * *"))
+	require.NoError(t, validateRotationSchedule("*/30 2 * * 1-5"))
+	require.Error(t, validateRotationSchedule("monthly"))
+	require.Error(t, validateRotationSchedule("0 0 1 *"))
+	require.Error(t, validateRotationSchedule("0 0 1 * * *"))
+}
+
+func Test_gitHubRotationWorkflow(t *testing.T) {
+	content, err := gitHubRotationWorkflow("0 0 1 * *", "az-dev-principal")
+	require.NoError(t, err)
+
+	var workflow struct {
+		On struct {
+			Schedule []struct {
+				Cron string `yaml:"cron"`
+			} `yaml:"schedule"`
+		} `yaml:"on"`
+		Jobs map[string]struct {
+			Steps []struct {
+				Run string            `yaml:"run"`
+				Env map[string]string `yaml:"env"`
+			} `yaml:"steps"`
+		} `yaml:"jobs"`
+	}
+	require.NoError(t, yaml.Unmarshal(content, &workflow))
+
+	require.Equal(t, "0 0 1 * *", workflow.On.Schedule[0].Cron)
+	rotate := workflow.Jobs["rotate"].Steps[2]
+	require.Equal(t,
+		"azd pipeline config --rotate-credentials --provider github --principal-name az-dev-principal --no-prompt",
+		rotate.Run)
+	require.Equal(t, map[string]string{
+		"AZURE_ENV_NAME":        "${{ vars.AZURE_ENV_NAME }}",
+		"AZURE_LOCATION":        "${{ vars.AZURE_LOCATION }}",
+		"AZURE_SUBSCRIPTION_ID": "${{ vars.AZURE_SUBSCRIPTION_ID }}",
+		"GH_TOKEN":              "${{ secrets.AZD_ROTATION_GITHUB_TOKEN }}",
+	}, rotate.Env)
+}
+
+func Test_azdoRotationPipeline(t *testing.T) {
+	content, err := azdoRotationPipeline("0 0 1 * *", "az-dev-principal")
+	require.NoError(t, err)
+
+	var pipeline struct {
+		Trigger   string `yaml:"trigger"`
+		Schedules []struct {
+			Cron   string `yaml:"cron"`
+			Always bool   `yaml:"always"`
+		} `yaml:"schedules"`
+		Jobs []struct {
+			Steps []struct {
+				Inputs map[string]string `yaml:"inputs"`
+				Env    map[string]string `yaml:"env"`
+			} `yaml:"steps"`
+		} `yaml:"jobs"`
+	}
+	require.NoError(t, yaml.Unmarshal(content, &pipeline))
+
+	require.Equal(t, "none", pipeline.Trigger)
+	require.Equal(t, "0 0 1 * *", pipeline.Schedules[0].Cron)
+	require.True(t, pipeline.Schedules[0].Always)
+
+	step := pipeline.Jobs[0].Steps[0]
+	require.Equal(t, "azconnection", step.Inputs["azureSubscription"])
+	require.Contains(t, step.Inputs["inlineScript"], "--rotate-credentials --provider azdo --principal-name az-dev-principal")
+	require.Equal(t, "$(AZURE_DEVOPS_EXT_PAT)", step.Env["AZURE_DEVOPS_EXT_PAT"])
+	require.Equal(t, "$(AZURE_ENV_NAME)", step.Env["AZURE_ENV_NAME"])
+}
+
+func Test_configuredPrincipalName(t *testing.T) {
+	azdCtx := &azdcontext.AzdContext{}
+	azdCtx.SetProjectDirectory(t.TempDir())
+	env := environment.EphemeralWithValues("test", nil)
+	manager := NewPipelineManager(azdCtx, nil, PipelineManagerArgs{})
+	manager.Environment = env
+
+	_, err := manager.configuredPrincipalName()
+	require.ErrorIs(t, err, errRotationNotConfigured)
+
+	require.NoError(t, (&Summary{ServicePrincipalName: "az-dev-principal"}).save(summaryPath(azdCtx, env)))
+	principalName, err := manager.configuredPrincipalName()
+	require.NoError(t, err)
+	require.Equal(t, "az-dev-principal", principalName)
+
+	// The principals of the stages are named after the principal of the pipeline
+	summary := &Summary{Stages: []StageSummary{{Name: "prod", ServicePrincipalName: "az-dev-principal-prod"}}}
+	require.NoError(t, summary.save(summaryPath(azdCtx, env)))
+	principalName, err = manager.configuredPrincipalName()
+	require.NoError(t, err)
+	require.Equal(t, "az-dev-principal", principalName)
+}
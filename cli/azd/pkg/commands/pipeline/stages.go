@@ -0,0 +1,288 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azdo"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+)
+
+// pipelineStage is a stage of a multi-stage pipeline, deploying an azd environment into the subscription of that
+// environment with its own service principal
+type pipelineStage struct {
+	name string
+	env  *environment.Environment
+	// the service principal of the stage, created with a role in the subscription of the environment
+	principalName string
+	credentials   json.RawMessage
+}
+
+// The names of the stages are used as job ids and environment names of the providers
+var stageNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// loadStages loads the environments of the stages of the pipeline. The service principal of each stage is named after
+// principalName and the stage. Returns nil when the pipeline has no stages.
+func loadStages(
+	azdCtx *azdcontext.AzdContext,
+	options []project.PipelineStageOptions,
+	principalName string,
+) ([]*pipelineStage, error) {
+	stages := []*pipelineStage{}
+	names := map[string]bool{}
+
+	for _, option := range options {
+		if !stageNameRegex.MatchString(option.Name) {
+			return nil, fmt.Errorf(
+				"invalid pipeline stage name '%s', the name must start with a letter and contain only letters, "+
+					"digits, '-' and '_'",
+				option.Name)
+		}
+		if names[strings.ToLower(option.Name)] {
+			return nil, fmt.Errorf("the pipeline stage '%s' is defined more than once", option.Name)
+		}
+		names[strings.ToLower(option.Name)] = true
+
+		envName := option.Environment
+		if envName == "" {
+			envName = option.Name
+		}
+
+		env, err := environment.GetEnvironment(azdCtx, envName)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf(
+				"the environment '%s' of pipeline stage '%s' doesn't exist, create it with 'azd env new %s'",
+				envName, option.Name, envName)
+		} else if err != nil {
+			return nil, fmt.Errorf("loading the environment of pipeline stage '%s': %w", option.Name, err)
+		}
+
+		if env.GetSubscriptionId() == "" || env.GetLocation() == "" {
+			return nil, fmt.Errorf(
+				"the environment '%s' of pipeline stage '%s' must have a subscription and a location, "+
+					"set them with 'azd env set %s <value> -e %s'",
+				envName, option.Name, environment.SubscriptionIdEnvVarName, envName)
+		}
+
+		stages = append(stages, &pipelineStage{
+			name:          option.Name,
+			env:           env,
+			principalName: fmt.Sprintf("%s-%s", principalName, option.Name),
+		})
+	}
+
+	if len(stages) == 0 {
+		return nil, nil
+	}
+
+	return stages, nil
+}
+
+// stageTemplateData is a stage of the template of a multi-stage pipeline definition
+type stageTemplateData struct {
+	Name string
+	// the id of the job or stage, and of the stage it depends on
+	Id        string
+	DependsOn string
+	// the service connection of the stage, only used by Azure DevOps
+	ServiceConnection string
+}
+
+// the data of the templates of the multi-stage pipeline definitions
+type stagesTemplateData struct {
+	Stages []stageTemplateData
+	// the names of the variables set for the provision and the deploy steps
+	ProvisionVariables []string
+	DeployVariables    []string
+}
+
+func newStagesTemplateData(
+	stages []*pipelineStage,
+	provisioningProvider provisioning.Options,
+	stageId func(name string) string,
+	terraformVariables []string,
+) stagesTemplateData {
+	data := stagesTemplateData{
+		ProvisionVariables: []string{
+			environment.EnvNameEnvVarName,
+			environment.LocationEnvVarName,
+			environment.SubscriptionIdEnvVarName,
+		},
+		DeployVariables: []string{
+			environment.EnvNameEnvVarName,
+			environment.LocationEnvVarName,
+			environment.SubscriptionIdEnvVarName,
+		},
+	}
+
+	if provisioningProvider.Provider == provisioning.Terraform {
+		data.ProvisionVariables = append(data.ProvisionVariables, terraformVariables...)
+	}
+
+	for index, stage := range stages {
+		stageData := stageTemplateData{
+			Name:              stage.name,
+			Id:                stageId(stage.name),
+			ServiceConnection: azdo.StageServiceConnectionName(stage.name),
+		}
+		if index > 0 {
+			stageData.DependsOn = stageId(stages[index-1].name)
+		}
+
+		data.Stages = append(data.Stages, stageData)
+	}
+
+	return data
+}
+
+// executeDefinitionTemplate executes the template of a pipeline definition. The definitions use [[ ]] as delimiters, the
+// expressions of the providers use curly braces.
+func executeDefinitionTemplate(name string, text string, funcs template.FuncMap, data any) ([]byte, error) {
+	tmpl, err := template.New(name).Delims("[[", "]]").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s template: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("executing %s template: %w", name, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// The GitHub workflow deploying the stages in order, each job reads the secrets of the GitHub environment of its stage
+const gitHubStagesWorkflowTemplate = `# Generated by azd pipeline config from the pipeline stages of azure.yaml
+on:
+  workflow_dispatch:
+  push:
+    branches:
+      - main
+      - master
+
+jobs:
+[[- range .Stages ]]
+  [[ .Id ]]:
+[[- if .DependsOn ]]
+    needs: [[ .DependsOn ]]
+[[- end ]]
+    runs-on: ubuntu-latest
+    environment: [[ .Name ]]
+    container:
+      image: mcr.microsoft.com/azure-dev-cli-apps:latest
+    steps:
+      - name: Checkout
+        uses: actions/checkout@v2
+
+      - name: Log in with Azure
+        uses: azure/login@v1
+        with:
+          creds: ${{ secrets.AZURE_CREDENTIALS }}
+
+      - name: Azure Dev Provision
+        run: azd provision --no-prompt
+        env:
+[[- range $.ProvisionVariables ]]
+          [[ . ]]: [[ github . ]]
+[[- end ]]
+
+      - name: Azure Dev Deploy
+        run: azd deploy --no-prompt
+        env:
+[[- range $.DeployVariables ]]
+          [[ . ]]: [[ github . ]]
+[[- end ]]
+[[ end -]]
+`
+
+// The Azure DevOps pipeline deploying the stages in order, each stage uses its service connection and the variables
+// prefixed with its name
+const azdoStagesPipelineTemplate = `# Generated by azd pipeline config from the pipeline stages of azure.yaml
+trigger:
+  - main
+  - master
+
+pool:
+  vmImage: ubuntu-latest
+
+stages:
+[[- range $stage := .Stages ]]
+  - stage: [[ $stage.Id ]]
+[[- if $stage.DependsOn ]]
+    dependsOn: [[ $stage.DependsOn ]]
+[[- end ]]
+    jobs:
+      - job: Deploy
+        container: mcr.microsoft.com/azure-dev-cli-apps:latest
+        steps:
+          - task: AzureCLI@2
+            displayName: Azure Dev Provision
+            inputs:
+              azureSubscription: [[ $stage.ServiceConnection ]]
+              scriptType: bash
+              scriptLocation: inlineScript
+              inlineScript: |
+                azd provision --no-prompt
+            env:
+[[- range $.ProvisionVariables ]]
+              [[ . ]]: $([[ variable $stage.Name . ]])
+[[- end ]]
+          - task: AzureCLI@2
+            displayName: Azure Dev Deploy
+            inputs:
+              azureSubscription: [[ $stage.ServiceConnection ]]
+              scriptType: bash
+              scriptLocation: inlineScript
+              inlineScript: |
+                azd deploy --no-prompt
+            env:
+[[- range $.DeployVariables ]]
+              [[ . ]]: $([[ variable $stage.Name . ]])
+[[- end ]]
+[[ end -]]
+`
+
+// gitHubStagesWorkflow returns the GitHub workflow deploying the stages
+func gitHubStagesWorkflow(stages []*pipelineStage, provisioningProvider provisioning.Options) ([]byte, error) {
+	data := newStagesTemplateData(
+		stages,
+		provisioningProvider,
+		func(name string) string { return name },
+		[]string{
+			"ARM_TENANT_ID", "ARM_CLIENT_ID", "ARM_CLIENT_SECRET",
+			"RS_RESOURCE_GROUP", "RS_STORAGE_ACCOUNT", "RS_CONTAINER_NAME",
+		},
+	)
+
+	return executeDefinitionTemplate("github workflow", gitHubStagesWorkflowTemplate, gitHubTemplateFuncs, data)
+}
+
+// azdoStagesPipeline returns the Azure DevOps pipeline deploying the stages
+func azdoStagesPipeline(stages []*pipelineStage, provisioningProvider provisioning.Options) ([]byte, error) {
+	data := newStagesTemplateData(
+		stages,
+		provisioningProvider,
+		// The names of Azure DevOps stages can only contain letters, digits and '_'
+		func(name string) string { return strings.ReplaceAll(name, "-", "_") },
+		[]string{"ARM_TENANT_ID", "ARM_CLIENT_ID", "ARM_CLIENT_SECRET"},
+	)
+
+	return executeDefinitionTemplate(
+		"azure devops pipeline",
+		azdoStagesPipelineTemplate,
+		template.FuncMap{"variable": azdo.StageVariableName},
+		data,
+	)
+}
@@ -0,0 +1,282 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// creates the environment in the project of azdCtx
+func saveStageEnvironment(t *testing.T, azdCtx *azdcontext.AzdContext, name string, values map[string]string) {
+	env := environment.EmptyWithFile(azdCtx.GetEnvironmentFilePath(name))
+	env.Values[environment.EnvNameEnvVarName] = name
+	for key, value := range values {
+		env.Values[key] = value
+	}
+	require.NoError(t, env.Save())
+}
+
+func Test_loadStages(t *testing.T) {
+	azdCtx := &azdcontext.AzdContext{}
+	azdCtx.SetProjectDirectory(t.TempDir())
+	saveStageEnvironment(t, azdCtx, "dev", map[string]string{
+		environment.SubscriptionIdEnvVarName: "DEV_SUBSCRIPTION",
+		environment.LocationEnvVarName:       "westus",
+	})
+	saveStageEnvironment(t, azdCtx, "app-prod", map[string]string{
+		environment.SubscriptionIdEnvVarName: "PROD_SUBSCRIPTION",
+		environment.LocationEnvVarName:       "eastus",
+	})
+	saveStageEnvironment(t, azdCtx, "no-subscription", map[string]string{
+		environment.LocationEnvVarName: "eastus",
+	})
+
+	t.Run("NoStages", func(t *testing.T) {
+		stages, err := loadStages(azdCtx, nil, "az-dev-principal")
+		require.NoError(t, err)
+		require.Nil(t, stages)
+	})
+
+	t.Run("Stages", func(t *testing.T) {
+		stages, err := loadStages(azdCtx, []project.PipelineStageOptions{
+			{Name: "dev"},
+			{Name: "prod", Environment: "app-prod"},
+		}, "az-dev-principal")
+		require.NoError(t, err)
+		require.Len(t, stages, 2)
+
+		require.Equal(t, "dev", stages[0].name)
+		require.Equal(t, "DEV_SUBSCRIPTION", stages[0].env.GetSubscriptionId())
+		require.Equal(t, "az-dev-principal-dev", stages[0].principalName)
+
+		require.Equal(t, "prod", stages[1].name)
+		require.Equal(t, "PROD_SUBSCRIPTION", stages[1].env.GetSubscriptionId())
+		require.Equal(t, "az-dev-principal-prod", stages[1].principalName)
+	})
+
+	errorTests := []struct {
+		name     string
+		stages   []project.PipelineStageOptions
+		expected string
+	}{
+		{
+			name:     "MissingEnvironment",
+			stages:   []project.PipelineStageOptions{{Name: "test"}},
+			expected: "create it with 'azd env new test'",
+		},
+		{
+			name:     "MissingSubscription",
+			stages:   []project.PipelineStageOptions{{Name: "test", Environment: "no-subscription"}},
+			expected: "must have a subscription and a location",
+		},
+		{
+			name:     "InvalidName",
+			stages:   []project.PipelineStageOptions{{Name: "dev stage", Environment: "dev"}},
+			expected: "invalid pipeline stage name 'dev stage'",
+		},
+		{
+			name:     "DuplicateName",
+			stages:   []project.PipelineStageOptions{{Name: "dev"}, {Name: "Dev", Environment: "dev"}},
+			expected: "the pipeline stage 'Dev' is defined more than once",
+		},
+	}
+
+	for _, test := range errorTests {
+		t.Run(test.name, func(t *testing.T) {
+			stages, err := loadStages(azdCtx, test.stages, "az-dev-principal")
+			require.Nil(t, stages)
+			require.ErrorContains(t, err, test.expected)
+		})
+	}
+}
+
+func testStages() []*pipelineStage {
+	return []*pipelineStage{
+		{name: "dev", env: environment.EphemeralWithValues("app-dev", nil)},
+		{name: "prod-eu", env: environment.EphemeralWithValues("app-prod", nil)},
+	}
+}
+
+func Test_gitHubStagesWorkflow(t *testing.T) {
+	t.Run("Bicep", func(t *testing.T) {
+		content, err := gitHubStagesWorkflow(testStages(), provisioning.Options{Provider: provisioning.Bicep})
+		require.NoError(t, err)
+
+		var workflow struct {
+			Jobs map[string]struct {
+				Needs       string `yaml:"needs"`
+				Environment string `yaml:"environment"`
+				Steps       []struct {
+					Name string            `yaml:"name"`
+					Env  map[string]string `yaml:"env"`
+				} `yaml:"steps"`
+			} `yaml:"jobs"`
+		}
+		require.NoError(t, yaml.Unmarshal(content, &workflow))
+		require.Len(t, workflow.Jobs, 2)
+
+		dev := workflow.Jobs["dev"]
+		require.Equal(t, "", dev.Needs)
+		require.Equal(t, "dev", dev.Environment)
+
+		prod := workflow.Jobs["prod-eu"]
+		require.Equal(t, "dev", prod.Needs)
+		require.Equal(t, "prod-eu", prod.Environment)
+		require.Equal(t, "Azure Dev Provision", prod.Steps[2].Name)
+		require.Equal(t, map[string]string{
+			"AZURE_ENV_NAME":        "${{ vars.AZURE_ENV_NAME }}",
+			"AZURE_LOCATION":        "${{ vars.AZURE_LOCATION }}",
+			"AZURE_SUBSCRIPTION_ID": "${{ vars.AZURE_SUBSCRIPTION_ID }}",
+		}, prod.Steps[2].Env)
+	})
+
+	t.Run("Terraform", func(t *testing.T) {
+		content, err := gitHubStagesWorkflow(testStages(), provisioning.Options{Provider: provisioning.Terraform})
+		require.NoError(t, err)
+		require.Contains(t, string(content), "ARM_CLIENT_SECRET: ${{ secrets.ARM_CLIENT_SECRET }}")
+		require.Contains(t, string(content), "RS_CONTAINER_NAME: ${{ vars.RS_CONTAINER_NAME }}")
+	})
+}
+
+func Test_azdoStagesPipeline(t *testing.T) {
+	content, err := azdoStagesPipeline(testStages(), provisioning.Options{Provider: provisioning.Bicep})
+	require.NoError(t, err)
+
+	var pipeline struct {
+		Stages []struct {
+			Stage     string `yaml:"stage"`
+			DependsOn string `yaml:"dependsOn"`
+			Jobs      []struct {
+				Steps []struct {
+					Inputs map[string]string `yaml:"inputs"`
+					Env    map[string]string `yaml:"env"`
+				} `yaml:"steps"`
+			} `yaml:"jobs"`
+		} `yaml:"stages"`
+	}
+	require.NoError(t, yaml.Unmarshal(content, &pipeline))
+	require.Len(t, pipeline.Stages, 2)
+
+	require.Equal(t, "dev", pipeline.Stages[0].Stage)
+	require.Equal(t, "", pipeline.Stages[0].DependsOn)
+
+	prod := pipeline.Stages[1]
+	require.Equal(t, "prod_eu", prod.Stage)
+	require.Equal(t, "dev", prod.DependsOn)
+	require.Equal(t, "azconnection-prod-eu", prod.Jobs[0].Steps[0].Inputs["azureSubscription"])
+	require.Equal(t, map[string]string{
+		"AZURE_ENV_NAME":        "$(PROD_EU_AZURE_ENV_NAME)",
+		"AZURE_LOCATION":        "$(PROD_EU_AZURE_LOCATION)",
+		"AZURE_SUBSCRIPTION_ID": "$(PROD_EU_AZURE_SUBSCRIPTION_ID)",
+	}, prod.Jobs[0].Steps[1].Env)
+}
+
+func Test_writeStagesDefinition(t *testing.T) {
+	newManager := func(t *testing.T) *PipelineManager {
+		azdCtx := &azdcontext.AzdContext{}
+		azdCtx.SetProjectDirectory(t.TempDir())
+
+		return &PipelineManager{
+			CiProvider: &GitHubCiProvider{},
+			AzdCtx:     azdCtx,
+		}
+	}
+	workflowPath := func(manager *PipelineManager) string {
+		return filepath.Join(manager.AzdCtx.ProjectDirectory(), ".github", "workflows", "azure-dev.yml")
+	}
+
+	t.Run("NewDefinition", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		manager := newManager(t)
+
+		err := manager.writeStagesDefinition(*mockContext.Context, testStages(), provisioning.Options{})
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(workflowPath(manager))
+		require.NoError(t, err)
+		require.Contains(t, string(content), "environment: prod-eu")
+	})
+
+	for _, replace := range []bool{true, false} {
+		name := "KeepDefinition"
+		if replace {
+			name = "ReplaceDefinition"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			mockContext.Console.WhenConfirm(func(options input.ConsoleOptions) bool {
+				return strings.Contains(options.Message, "replace")
+			}).Respond(replace)
+
+			manager := newManager(t)
+			require.NoError(t, os.MkdirAll(filepath.Dir(workflowPath(manager)), 0755))
+			require.NoError(t, os.WriteFile(workflowPath(manager), []byte("on: push"), 0600))
+
+			err := manager.writeStagesDefinition(*mockContext.Context, testStages(), provisioning.Options{})
+			require.NoError(t, err)
+
+			content, err := os.ReadFile(workflowPath(manager))
+			require.NoError(t, err)
+			require.Equal(t, replace, string(content) != "on: push")
+		})
+	}
+}
+
+func Test_gitHub_provider_configureStageConnection(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	commands := []string{}
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.HasPrefix(command, "gh")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		commands = append(commands, strings.Join(args.Args, " "))
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	stage := &pipelineStage{
+		name: "prod",
+		env: environment.EphemeralWithValues("app-prod", map[string]string{
+			environment.LocationEnvVarName:       "eastus",
+			environment.SubscriptionIdEnvVarName: "PROD_SUBSCRIPTION",
+		}),
+		credentials: []byte(`{"clientId":"CLIENT_ID"}`),
+	}
+
+	provider := &GitHubCiProvider{}
+	err := provider.configureStageConnection(
+		*mockContext.Context,
+		&gitRepositoryDetails{owner: "Azure", repoName: "azure-dev"},
+		provisioning.Options{},
+		stage,
+		mockContext.Console,
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"api --method PUT /repos/Azure/azure-dev/environments/prod",
+		`-R Azure/azure-dev secret set AZURE_CREDENTIALS --env prod --body {"clientId":"CLIENT_ID"}`,
+		"api --method PATCH /repos/Azure/azure-dev/environments/prod/variables/AZURE_ENV_NAME " +
+			"-f name=AZURE_ENV_NAME -f value=app-prod",
+		"api --method PATCH /repos/Azure/azure-dev/environments/prod/variables/AZURE_LOCATION " +
+			"-f name=AZURE_LOCATION -f value=eastus",
+		"api --method PATCH /repos/Azure/azure-dev/environments/prod/variables/AZURE_SUBSCRIPTION_ID " +
+			"-f name=AZURE_SUBSCRIPTION_ID -f value=PROD_SUBSCRIPTION",
+	}, commands)
+	require.Equal(t, []string{
+		"prod/AZURE_CREDENTIALS", "prod/AZURE_ENV_NAME", "prod/AZURE_LOCATION", "prod/AZURE_SUBSCRIPTION_ID",
+	}, provider.secrets)
+}
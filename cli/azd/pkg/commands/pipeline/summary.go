@@ -0,0 +1,142 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+)
+
+// The name of the file, within the environment directory, describing the pipeline configured by `azd pipeline config`
+const summaryFileName = "pipeline.json"
+
+// Summary describes the pipeline configured by `azd pipeline config`.
+// It's printed once the configuration completes and saved in the environment directory, so `azd show` can display it.
+type Summary struct {
+	// The display name of the CI provider
+	Provider string `json:"provider"`
+	// The web url of the Azure DevOps project, empty for GitHub
+	ProjectUrl    string `json:"projectUrl,omitempty"`
+	RepositoryUrl string `json:"repositoryUrl"`
+	PipelineUrl   string `json:"pipelineUrl"`
+	// The name of the Azure DevOps service connection, empty for GitHub
+	ServiceConnectionName string `json:"serviceConnectionName,omitempty"`
+	// The name of the Azure DevOps variable group holding the values of the environment, empty when they are
+	// variables of the pipeline
+	VariableGroup         string `json:"variableGroup,omitempty"`
+	ServicePrincipalName  string `json:"servicePrincipalName"`
+	ServicePrincipalAppId string `json:"servicePrincipalAppId"`
+	// How the pipeline signs in with the service principal, clientsecret or federated, empty for a managed identity
+	AuthType string `json:"authType,omitempty"`
+	// The client id of the managed identity of the self-hosted agent, which is used instead of a service principal
+	ManagedIdentityClientId string `json:"managedIdentityClientId,omitempty"`
+	// The names of the secrets and variables set in the pipeline
+	Secrets []string `json:"secrets"`
+	// The stages of a multi-stage pipeline, each with its own service principal
+	Stages []StageSummary `json:"stages,omitempty"`
+}
+
+// StageSummary describes a stage of a multi-stage pipeline
+type StageSummary struct {
+	Name string `json:"name"`
+	// The azd environment deployed by the stage
+	Environment           string `json:"environment"`
+	ServicePrincipalName  string `json:"servicePrincipalName"`
+	ServicePrincipalAppId string `json:"servicePrincipalAppId"`
+	// The name of the Azure DevOps service connection of the stage, empty for GitHub
+	ServiceConnectionName string `json:"serviceConnectionName,omitempty"`
+}
+
+func summaryPath(azdCtx *azdcontext.AzdContext, env *environment.Environment) string {
+	return filepath.Join(azdCtx.EnvironmentDirectory(), env.GetEnvName(), summaryFileName)
+}
+
+// LoadSummary reads the summary of the pipeline configured for the environment,
+// returns nil when no pipeline was configured
+func LoadSummary(azdCtx *azdcontext.AzdContext, env *environment.Environment) (*Summary, error) {
+	path := summaryPath(azdCtx, env)
+	summaryJson, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading pipeline summary: %w", err)
+	}
+
+	summary := &Summary{}
+	if err := json.Unmarshal(summaryJson, summary); err != nil {
+		return nil, fmt.Errorf("parsing pipeline summary '%s': %w", path, err)
+	}
+
+	return summary, nil
+}
+
+func (s *Summary) save(path string) error {
+	summaryJson, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serializing pipeline summary: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory); err != nil {
+		return fmt.Errorf("creating environment directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, summaryJson, osutil.PermissionFile); err != nil {
+		return fmt.Errorf("writing pipeline summary: %w", err)
+	}
+
+	return nil
+}
+
+// print displays the summary, one line per configured value
+func (s *Summary) print(ctx context.Context, console input.Console) {
+	var values []input.KeyValue
+	addValue := func(label string, value string) {
+		if value != "" {
+			values = append(values, input.KeyValue{Key: label, Value: value})
+		}
+	}
+
+	addValue("Provider", s.Provider)
+	if s.ProjectUrl != "" {
+		addValue("Project", output.WithLinkFormat(s.ProjectUrl))
+	}
+	if s.RepositoryUrl != "" {
+		addValue("Repository", output.WithLinkFormat(s.RepositoryUrl))
+	}
+	if s.PipelineUrl != "" {
+		addValue("Pipeline", output.WithLinkFormat(s.PipelineUrl))
+	}
+	addValue("Service connection", s.ServiceConnectionName)
+	addValue("Variable group", s.VariableGroup)
+	if s.ServicePrincipalName != "" {
+		addValue("Service principal", fmt.Sprintf("%s (appId: %s)", s.ServicePrincipalName, s.ServicePrincipalAppId))
+	}
+	addValue("Auth type", s.AuthType)
+	addValue("Managed identity", s.ManagedIdentityClientId)
+	for _, stage := range s.Stages {
+		description := fmt.Sprintf(
+			"environment %s, service principal %s (appId: %s)",
+			stage.Environment, stage.ServicePrincipalName, stage.ServicePrincipalAppId)
+		if stage.ServiceConnectionName != "" {
+			description += fmt.Sprintf(", service connection %s", stage.ServiceConnectionName)
+		}
+		addValue("Stage "+stage.Name, description)
+	}
+	addValue("Secrets", strings.Join(s.Secrets, ", "))
+
+	console.Message(ctx, output.WithHighLightFormat("Pipeline configuration summary:"))
+	console.KeyValues(ctx, values)
+	console.Message(ctx, "")
+}
@@ -0,0 +1,110 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/build"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LoadSummary(t *testing.T) {
+	azdCtx := &azdcontext.AzdContext{}
+	azdCtx.SetProjectDirectory(t.TempDir())
+	env := environment.EphemeralWithValues("test", nil)
+
+	// No pipeline was configured for the environment
+	summary, err := LoadSummary(azdCtx, env)
+	require.NoError(t, err)
+	require.Nil(t, summary)
+
+	expected := &Summary{
+		Provider:              "GitHub",
+		RepositoryUrl:         "https://github.com/owner/repo",
+		PipelineUrl:           "https://github.com/owner/repo/actions",
+		ServicePrincipalName:  "az-dev-principal",
+		ServicePrincipalAppId: "CLIENT_ID",
+		Secrets:               []string{"AZURE_CREDENTIALS"},
+	}
+	require.NoError(t, expected.save(summaryPath(azdCtx, env)))
+
+	summary, err = LoadSummary(azdCtx, env)
+	require.NoError(t, err)
+	require.Equal(t, expected, summary)
+}
+
+func Test_PipelineManager_summarize(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azdCtx := &azdcontext.AzdContext{}
+	azdCtx.SetProjectDirectory(t.TempDir())
+	env := environment.EphemeralWithValues("test", nil)
+
+	manager := &PipelineManager{
+		CiProvider:          &GitHubCiProvider{secrets: []string{"AZURE_CREDENTIALS", "AZURE_ENV_NAME"}},
+		AzdCtx:              azdCtx,
+		Environment:         env,
+		PipelineManagerArgs: PipelineManagerArgs{PipelineServicePrincipalName: "az-dev-principal"},
+	}
+
+	manager.summarize(
+		*mockContext.Context,
+		&gitRepositoryDetails{owner: "owner", repoName: "repo"},
+		[]byte(`{"clientId":"CLIENT_ID","clientSecret":"SECRET"}`),
+		nil,
+	)
+
+	summary, err := LoadSummary(azdCtx, env)
+	require.NoError(t, err)
+	require.Equal(t, &Summary{
+		Provider:              "GitHub",
+		RepositoryUrl:         "https://github.com/owner/repo",
+		PipelineUrl:           "https://github.com/owner/repo/actions",
+		ServicePrincipalName:  "az-dev-principal",
+		ServicePrincipalAppId: "CLIENT_ID",
+		Secrets:               []string{"AZURE_CREDENTIALS", "AZURE_ENV_NAME"},
+	}, summary)
+
+	printed := strings.Join(mockContext.Console.Output(), "\n")
+	require.Contains(t, printed, "https://github.com/owner/repo/actions")
+	require.Contains(t, printed, "az-dev-principal (appId: CLIENT_ID)")
+	require.Contains(t, printed, "AZURE_CREDENTIALS, AZURE_ENV_NAME")
+	// The secret itself is never displayed
+	require.NotContains(t, printed, "SECRET\"")
+	require.NotContains(t, printed, "Service connection")
+}
+
+func Test_AzdoCiProvider_summarize(t *testing.T) {
+	provider := &AzdoCiProvider{}
+	summary := &Summary{}
+
+	provider.summarize(&gitRepositoryDetails{
+		details: &AzdoRepositoryDetails{
+			orgName:     "org",
+			projectName: "my project",
+			repoWebUrl:  "https://dev.azure.com/org/my%20project/_git/repo",
+			buildDefinition: &build.BuildDefinition{
+				Id: convert.RefOf(12),
+				Variables: &map[string]build.BuildDefinitionVariable{
+					"AZURE_LOCATION": {},
+					"AZURE_ENV_NAME": {},
+				},
+			},
+		},
+	}, summary)
+
+	require.Equal(t, &Summary{
+		ProjectUrl:            "https://dev.azure.com/org/my%20project",
+		RepositoryUrl:         "https://dev.azure.com/org/my%20project/_git/repo",
+		PipelineUrl:           "https://dev.azure.com/org/my%20project/_build?definitionId=12",
+		ServiceConnectionName: "azconnection",
+		Secrets:               []string{"AZURE_ENV_NAME", "AZURE_LOCATION"},
+	}, summary)
+}
@@ -0,0 +1,299 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/azure/azure-dev/cli/azd/pkg/azdo"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// The name of the file, within the environment directory, recording the resources created by `azd pipeline config`
+const transactionFileName = "pipeline-transaction.json"
+
+// The kinds of resources created while configuring a pipeline
+const (
+	resourceServicePrincipal = "servicePrincipal"
+	resourceAzdoProject      = "azdoProject"
+	resourceAzdoRepository   = "azdoRepository"
+)
+
+// createdResource is a resource created while configuring a pipeline
+type createdResource struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	Id   string `json:"id,omitempty"`
+	// The Azure DevOps organization and project the resource belongs to
+	Organization string `json:"organization,omitempty"`
+	ProjectId    string `json:"projectId,omitempty"`
+}
+
+// String describes the resource to the user
+func (r createdResource) String() string {
+	switch r.Kind {
+	case resourceServicePrincipal:
+		return fmt.Sprintf("service principal %s", r.Name)
+	case resourceAzdoProject:
+		return fmt.Sprintf("Azure DevOps project %s", r.Name)
+	case resourceAzdoRepository:
+		return fmt.Sprintf("Azure DevOps repository %s", r.Name)
+	default:
+		return fmt.Sprintf("%s %s", r.Kind, r.Name)
+	}
+}
+
+// progress is the checkpoint of a configuration, holding the choices made as the steps complete.
+// A resumed configuration reuses these choices instead of prompting for them again.
+type progress struct {
+	CompletedSteps []string `json:"completedSteps,omitempty"`
+	// The step the configuration failed at
+	FailedStep    string `json:"failedStep,omitempty"`
+	PrincipalName string `json:"principalName,omitempty"`
+	// The Azure DevOps project and repository, either selected or created
+	AzdoProjectName    string `json:"azdoProjectName,omitempty"`
+	AzdoProjectId      string `json:"azdoProjectId,omitempty"`
+	AzdoRepositoryName string `json:"azdoRepositoryName,omitempty"`
+	// The answer to the prompt for pushing the changes, nil until the user answers it
+	Push *bool `json:"push,omitempty"`
+}
+
+// transaction records the resources created by `azd pipeline config` as they are created, so they can be deleted
+// when the configuration is interrupted, along with the progress of the configuration.
+// A configuration that failed is resumed from its progress when run again, while an interrupted configuration is
+// only resumed with `azd pipeline config --resume`. The transaction is removed once the configuration completes.
+type transaction struct {
+	Resources []createdResource `json:"resources"`
+	Progress  progress          `json:"progress"`
+	// true when the user interrupted the configuration and chose to keep the created resources
+	Interrupted bool `json:"interrupted,omitempty"`
+
+	path string
+	// true when resuming a previously interrupted configuration
+	resume bool
+}
+
+func transactionPath(azdCtx *azdcontext.AzdContext, env *environment.Environment) string {
+	return filepath.Join(azdCtx.EnvironmentDirectory(), env.GetEnvName(), transactionFileName)
+}
+
+// loadTransaction reads the transaction at the specified path, returns an empty transaction when none exists
+func loadTransaction(path string) (*transaction, error) {
+	tx := &transaction{
+		Resources: []createdResource{},
+		path:      path,
+	}
+
+	txJson, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return tx, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading pipeline transaction: %w", err)
+	}
+
+	if err := json.Unmarshal(txJson, tx); err != nil {
+		return nil, fmt.Errorf("parsing pipeline transaction '%s': %w", path, err)
+	}
+
+	return tx, nil
+}
+
+// isEmpty returns true when no resources nor progress were recorded
+func (tx *transaction) isEmpty() bool {
+	return len(tx.Resources) == 0 && len(tx.Progress.CompletedSteps) == 0 && tx.Progress.PrincipalName == ""
+}
+
+// record adds the resource to the transaction and saves it
+func (tx *transaction) record(resource createdResource) error {
+	tx.Resources = append(tx.Resources, resource)
+	return tx.save()
+}
+
+// find returns the last recorded resource of the specified kind, or nil when none was recorded
+func (tx *transaction) find(kind string) *createdResource {
+	for i := len(tx.Resources) - 1; i >= 0; i-- {
+		if tx.Resources[i].Kind == kind {
+			return &tx.Resources[i]
+		}
+	}
+
+	return nil
+}
+
+func (tx *transaction) save() error {
+	txJson, err := json.MarshalIndent(tx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serializing pipeline transaction: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(tx.path), osutil.PermissionDirectory); err != nil {
+		return fmt.Errorf("creating environment directory: %w", err)
+	}
+
+	if err := os.WriteFile(tx.path, txJson, osutil.PermissionFile); err != nil {
+		return fmt.Errorf("writing pipeline transaction: %w", err)
+	}
+
+	return nil
+}
+
+// complete removes the transaction, the recorded resources are no longer tracked
+func (tx *transaction) complete() error {
+	tx.Resources = []createdResource{}
+	tx.Progress = progress{}
+
+	if err := os.Remove(tx.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing pipeline transaction: %w", err)
+	}
+
+	return nil
+}
+
+type transactionContextKey struct{}
+
+func withTransaction(ctx context.Context, tx *transaction) context.Context {
+	return context.WithValue(ctx, transactionContextKey{}, tx)
+}
+
+func getTransaction(ctx context.Context) *transaction {
+	tx, _ := ctx.Value(transactionContextKey{}).(*transaction)
+	return tx
+}
+
+// recordCreated records a newly created resource in the transaction of the context, if any.
+// Failing to record the resource doesn't fail the configuration, the resource is only not tracked.
+func recordCreated(ctx context.Context, resource createdResource) {
+	tx := getTransaction(ctx)
+	if tx == nil {
+		return
+	}
+
+	if err := tx.record(resource); err != nil {
+		log.Printf("failed recording created %s '%s': %v", resource.Kind, resource.Name, err)
+	}
+}
+
+// resumedResource returns the resource of the specified kind created by the interrupted configuration being
+// resumed, or nil when not resuming or when no such resource was created.
+func resumedResource(ctx context.Context, kind string) *createdResource {
+	tx := getTransaction(ctx)
+	if tx == nil || !tx.resume {
+		return nil
+	}
+
+	return tx.find(kind)
+}
+
+// resumedProgress returns the progress of the configuration being resumed, or nil when not resuming
+func resumedProgress(ctx context.Context) *progress {
+	tx := getTransaction(ctx)
+	if tx == nil || !tx.resume {
+		return nil
+	}
+
+	return &tx.Progress
+}
+
+// checkpoint updates the progress in the transaction of the context, if any, and saves it.
+// Like recording resources, failing to save the progress doesn't fail the configuration.
+func checkpoint(ctx context.Context, update func(progress *progress)) {
+	tx := getTransaction(ctx)
+	if tx == nil {
+		return
+	}
+
+	update(&tx.Progress)
+	if err := tx.save(); err != nil {
+		log.Printf("failed saving pipeline config progress: %v", err)
+	}
+}
+
+// isInterrupted returns true when the configuration failed because the user interrupted it,
+// either by cancelling a prompt or by sending an interrupt signal.
+func isInterrupted(ctx context.Context, err error) bool {
+	return errors.Is(err, terminal.InterruptErr) || errors.Is(ctx.Err(), context.Canceled)
+}
+
+// rollback deletes the resources recorded in the transaction, most recently created first.
+// Resources that fail to be deleted are kept in the transaction so the rollback can be retried.
+func rollback(
+	ctx context.Context,
+	tx *transaction,
+	env *environment.Environment,
+	azCli azcli.AzCli,
+	console input.Console,
+) error {
+	var azdoClients azdo.Clients
+	getClients := func(organization string) (azdo.Clients, error) {
+		if azdoClients != nil {
+			return azdoClients, nil
+		}
+
+		pat, err := azdo.EnsurePatExists(ctx, env, console)
+		if err != nil {
+			return nil, err
+		}
+
+		azdoClients, err = azdo.GetClients(ctx, organization, pat)
+		return azdoClients, err
+	}
+
+	failed := []createdResource{}
+	for i := len(tx.Resources) - 1; i >= 0; i-- {
+		resource := tx.Resources[i]
+
+		var err error
+		switch resource.Kind {
+		case resourceServicePrincipal:
+			console.Message(ctx, fmt.Sprintf("Deleting %s", resource.String()))
+			err = azCli.DeleteServicePrincipal(ctx, resource.Name)
+		case resourceAzdoProject:
+			console.Message(ctx, fmt.Sprintf("Deleting %s", resource.String()))
+			var clients azdo.Clients
+			if clients, err = getClients(resource.Organization); err == nil {
+				err = azdo.DeleteProject(ctx, clients, resource.Id)
+			}
+		case resourceAzdoRepository:
+			if project := tx.find(resourceAzdoProject); project != nil && project.Id == resource.ProjectId {
+				// The repository was created within a project that is deleted along with it
+				continue
+			}
+
+			console.Message(ctx, fmt.Sprintf("Deleting %s", resource.String()))
+			var clients azdo.Clients
+			if clients, err = getClients(resource.Organization); err == nil {
+				err = azdo.DeleteRepository(ctx, resource.ProjectId, resource.Id, clients)
+			}
+		default:
+			log.Printf("skipping unknown pipeline resource kind '%s'", resource.Kind)
+		}
+
+		if err != nil {
+			console.Message(ctx, fmt.Sprintf("failed deleting %s: %v", resource.String(), err))
+			failed = append([]createdResource{resource}, failed...)
+		}
+	}
+
+	if len(failed) > 0 {
+		tx.Resources = failed
+		if err := tx.save(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("%d resources could not be deleted, they are recorded in %s", len(failed), tx.path)
+	}
+
+	return tx.complete()
+}
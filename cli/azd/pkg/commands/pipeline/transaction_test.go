@@ -0,0 +1,296 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	graphsdk_mocks "github.com/azure/azure-dev/cli/azd/test/mocks/graphsdk"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_transaction(t *testing.T) {
+	t.Run("NoTransaction", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "env", transactionFileName)
+
+		tx, err := loadTransaction(path)
+		require.NoError(t, err)
+		require.Empty(t, tx.Resources)
+		require.Nil(t, tx.find(resourceServicePrincipal))
+
+		// Completing a transaction that was never saved is a no-op
+		require.NoError(t, tx.complete())
+	})
+
+	t.Run("RecordAndComplete", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "env", transactionFileName)
+
+		tx, err := loadTransaction(path)
+		require.NoError(t, err)
+
+		ctx := withTransaction(context.Background(), tx)
+		recordCreated(ctx, createdResource{Kind: resourceServicePrincipal, Name: "az-dev-principal"})
+		recordCreated(ctx, createdResource{Kind: resourceAzdoProject, Name: "project", Id: "PROJECT_ID"})
+
+		loaded, err := loadTransaction(path)
+		require.NoError(t, err)
+		require.Equal(t, tx.Resources, loaded.Resources)
+		require.Equal(t, "PROJECT_ID", loaded.find(resourceAzdoProject).Id)
+
+		require.NoError(t, loaded.complete())
+		_, err = os.Stat(path)
+		require.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("ResumedResource", func(t *testing.T) {
+		tx := &transaction{
+			Resources: []createdResource{{Kind: resourceAzdoProject, Name: "project"}},
+			path:      filepath.Join(t.TempDir(), transactionFileName),
+		}
+		ctx := withTransaction(context.Background(), tx)
+
+		// Recorded resources are only reused when resuming
+		require.Nil(t, resumedResource(ctx, resourceAzdoProject))
+
+		tx.resume = true
+		require.Equal(t, "project", resumedResource(ctx, resourceAzdoProject).Name)
+		require.Nil(t, resumedResource(ctx, resourceAzdoRepository))
+		require.Nil(t, resumedResource(context.Background(), resourceAzdoProject))
+	})
+}
+
+func Test_checkpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), transactionFileName)
+	tx, err := loadTransaction(path)
+	require.NoError(t, err)
+	ctx := withTransaction(context.Background(), tx)
+
+	err = runStep(ctx, stepPrincipal, func(ctx context.Context) error {
+		checkpoint(ctx, func(progress *progress) {
+			progress.PrincipalName = "az-dev-principal"
+		})
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = runStep(ctx, stepRemote, func(ctx context.Context) error {
+		return errors.New("failed")
+	})
+	require.Error(t, err)
+
+	loaded, err := loadTransaction(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{stepPrincipal}, loaded.Progress.CompletedSteps)
+	require.Equal(t, stepRemote, loaded.Progress.FailedStep)
+	require.Equal(t, "az-dev-principal", loaded.Progress.PrincipalName)
+
+	// Nothing is recorded without a transaction
+	err = runStep(context.Background(), stepRemote, func(ctx context.Context) error {
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func Test_beginTransaction(t *testing.T) {
+	newManager := func(t *testing.T, tx *transaction, resume bool) *PipelineManager {
+		azdCtx := &azdcontext.AzdContext{}
+		azdCtx.SetProjectDirectory(t.TempDir())
+		env := environment.EphemeralWithValues("test", nil)
+
+		if tx != nil {
+			tx.path = transactionPath(azdCtx, env)
+			require.NoError(t, tx.save())
+		}
+
+		return &PipelineManager{
+			AzdCtx:              azdCtx,
+			Environment:         env,
+			PipelineManagerArgs: PipelineManagerArgs{Resume: resume},
+		}
+	}
+
+	t.Run("FailedConfiguration", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		manager := newManager(t, &transaction{
+			Progress: progress{
+				CompletedSteps: []string{stepPreConfigure, stepPrincipal},
+				FailedStep:     stepRemote,
+				PrincipalName:  "az-dev-principal",
+			},
+		}, false)
+
+		tx, err := manager.beginTransaction(*mockContext.Context)
+		require.NoError(t, err)
+		require.True(t, tx.resume)
+		require.Equal(t, "az-dev-principal", manager.PipelineServicePrincipalName)
+	})
+
+	t.Run("InterruptedConfiguration", func(t *testing.T) {
+		interrupted := func() *transaction {
+			return &transaction{
+				Resources:   []createdResource{{Kind: resourceServicePrincipal, Name: "az-dev-principal"}},
+				Progress:    progress{PrincipalName: "az-dev-principal"},
+				Interrupted: true,
+			}
+		}
+		whenPrompted := func(mockContext *mocks.MockContext, option int) {
+			mockContext.Console.WhenSelect(func(options input.ConsoleOptions) bool {
+				return options.Message == "What would you like to do with the created resources?"
+			}).Respond(option)
+		}
+
+		t.Run("Resume", func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			whenPrompted(mockContext, 0)
+			manager := newManager(t, interrupted(), false)
+
+			tx, err := manager.beginTransaction(*mockContext.Context)
+			require.NoError(t, err)
+			require.True(t, tx.resume)
+			require.Equal(t, "az-dev-principal", manager.PipelineServicePrincipalName)
+		})
+
+		t.Run("Delete", func(t *testing.T) {
+			application := graphsdk.Application{
+				Id:          convert.RefOf("UNIQUE_ID"),
+				AppId:       convert.RefOf("APP_ID"),
+				DisplayName: "az-dev-principal",
+			}
+
+			mockContext := mocks.NewMockContext(context.Background())
+			whenPrompted(mockContext, 1)
+			graphsdk_mocks.RegisterApplicationListMock(mockContext, http.StatusOK, []graphsdk.Application{application})
+			graphsdk_mocks.RegisterApplicationDeleteMock(mockContext, http.StatusNoContent, *application.Id)
+			manager := newManager(t, interrupted(), false)
+
+			tx, err := manager.beginTransaction(*mockContext.Context)
+			require.NoError(t, err)
+			require.False(t, tx.resume)
+			require.True(t, tx.isEmpty())
+			require.Empty(t, manager.PipelineServicePrincipalName)
+		})
+
+		t.Run("DeleteFailure", func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			whenPrompted(mockContext, 1)
+			graphsdk_mocks.RegisterApplicationListMock(mockContext, http.StatusForbidden, []graphsdk.Application{})
+			manager := newManager(t, interrupted(), false)
+
+			// The configuration doesn't start over while the resources are still recorded
+			_, err := manager.beginTransaction(*mockContext.Context)
+			require.Error(t, err)
+
+			loaded, err := loadTransaction(transactionPath(manager.AzdCtx, manager.Environment))
+			require.NoError(t, err)
+			require.Len(t, loaded.Resources, 1)
+		})
+	})
+
+	t.Run("ResumeInterruptedConfiguration", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		manager := newManager(t, &transaction{
+			Resources:   []createdResource{{Kind: resourceServicePrincipal, Name: "az-dev-principal"}},
+			Interrupted: true,
+		}, true)
+
+		tx, err := manager.beginTransaction(*mockContext.Context)
+		require.NoError(t, err)
+		require.True(t, tx.resume)
+		require.False(t, tx.Interrupted)
+		require.Equal(t, "az-dev-principal", manager.PipelineServicePrincipalName)
+	})
+
+	t.Run("NoPreviousConfiguration", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		manager := newManager(t, nil, true)
+
+		tx, err := manager.beginTransaction(*mockContext.Context)
+		require.NoError(t, err)
+		require.False(t, tx.resume)
+		require.True(t, tx.isEmpty())
+	})
+}
+
+func Test_isInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	require.False(t, isInterrupted(ctx, errors.New("failed")))
+	require.True(t, isInterrupted(ctx, fmt.Errorf("prompting: %w", terminal.InterruptErr)))
+
+	cancel()
+	require.True(t, isInterrupted(ctx, errors.New("failed")))
+}
+
+func Test_rollback(t *testing.T) {
+	application := graphsdk.Application{
+		Id:          convert.RefOf("UNIQUE_ID"),
+		AppId:       convert.RefOf("APP_ID"),
+		DisplayName: "az-dev-principal",
+	}
+
+	newTransaction := func(t *testing.T) *transaction {
+		tx := &transaction{
+			Resources: []createdResource{{Kind: resourceServicePrincipal, Name: application.DisplayName}},
+			path:      filepath.Join(t.TempDir(), transactionFileName),
+		}
+		require.NoError(t, tx.save())
+
+		return tx
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterApplicationListMock(mockContext, http.StatusOK, []graphsdk.Application{application})
+		graphsdk_mocks.RegisterApplicationDeleteMock(mockContext, http.StatusNoContent, *application.Id)
+
+		tx := newTransaction(t)
+		err := rollback(
+			*mockContext.Context,
+			tx,
+			environment.Ephemeral(),
+			azcli.GetAzCli(*mockContext.Context),
+			mockContext.Console,
+		)
+		require.NoError(t, err)
+		require.Empty(t, tx.Resources)
+
+		_, err = os.Stat(tx.path)
+		require.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterApplicationListMock(mockContext, http.StatusOK, []graphsdk.Application{application})
+		graphsdk_mocks.RegisterApplicationDeleteMock(mockContext, http.StatusForbidden, *application.Id)
+
+		tx := newTransaction(t)
+		err := rollback(
+			*mockContext.Context,
+			tx,
+			environment.Ephemeral(),
+			azcli.GetAzCli(*mockContext.Context),
+			mockContext.Console,
+		)
+		require.Error(t, err)
+
+		// The resource that failed to be deleted is still recorded
+		loaded, err := loadTransaction(tx.path)
+		require.NoError(t, err)
+		require.Len(t, loaded.Resources, 1)
+	})
+}
@@ -0,0 +1,176 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package containerregistry
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+)
+
+// AuthMode is how azd authenticates with a container registry
+type AuthMode string
+
+const (
+	// Authenticates with an ACR token, falling back to the admin user credentials when the token can't be obtained
+	AuthModeDefault AuthMode = ""
+	// Exchanges the Azure credential of azd for an ACR refresh token
+	AuthModeToken AuthMode = "token"
+	// Authenticates with the admin user credentials of the registry, which must be enabled
+	AuthModeAdmin AuthMode = "admin"
+	// Doesn't authenticate, for registries allowing anonymous pulls
+	AuthModeAnonymous AuthMode = "anonymous"
+)
+
+// The username docker authenticates with when the password is an ACR refresh token
+const tokenUsername = "00000000-0000-0000-0000-000000000000"
+
+// RegistryAuth provides the credentials docker authenticates with to a container registry. Credentials are
+// obtained for each operation, azd doesn't rely on the user being logged into the registry with `docker login`.
+type RegistryAuth struct {
+	azCli      azcli.AzCli
+	httpClient httputil.HttpClient
+}
+
+func NewRegistryAuth(azCli azcli.AzCli, httpClient httputil.HttpClient) *RegistryAuth {
+	return &RegistryAuth{
+		azCli:      azCli,
+		httpClient: httpClient,
+	}
+}
+
+type tokenExchangeResponse struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// ParseAuthMode parses the registry auth mode of a service configuration
+func ParseAuthMode(value string) (AuthMode, error) {
+	switch mode := AuthMode(strings.ToLower(value)); mode {
+	case AuthModeDefault, AuthModeToken, AuthModeAdmin, AuthModeAnonymous:
+		return mode, nil
+	default:
+		return "", fmt.Errorf(
+			"unsupported registry auth mode '%s', the supported modes are %s, %s and %s",
+			value, AuthModeToken, AuthModeAdmin, AuthModeAnonymous,
+		)
+	}
+}
+
+// Credentials returns the credentials to authenticate with the registry using the specified mode,
+// or nil when the registry is accessed anonymously.
+func (r *RegistryAuth) Credentials(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+	mode AuthMode,
+) (*docker.RegistryCredentials, error) {
+	switch mode {
+	case AuthModeAnonymous:
+		return nil, nil
+	case AuthModeToken:
+		return r.tokenCredentials(ctx, loginServer)
+	case AuthModeAdmin:
+		return r.azCli.GetContainerRegistryAdminCredentials(ctx, subscriptionId, loginServer)
+	case AuthModeDefault:
+		credentials, err := r.tokenCredentials(ctx, loginServer)
+		if err == nil {
+			return credentials, nil
+		}
+
+		log.Printf("failed getting token for registry %s, using the admin user credentials: %v", loginServer, err)
+		return r.azCli.GetContainerRegistryAdminCredentials(ctx, subscriptionId, loginServer)
+	default:
+		return nil, fmt.Errorf("unsupported registry auth mode '%s'", mode)
+	}
+}
+
+// PushAccessError converts the error of a push the registry denied to the signed-in account to a
+// azcli.DataPlaneAccessError, for the AcrPush role to be assigned to the account. Other errors, and the errors of pushes
+// authenticated with the admin user or anonymously, are returned as is.
+func (r *RegistryAuth) PushAccessError(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+	mode AuthMode,
+	err error,
+) error {
+	if mode == AuthModeAdmin || mode == AuthModeAnonymous {
+		return err
+	}
+
+	// docker reports "denied: requested access to the resource is denied" or "unauthorized: authentication required"
+	message := strings.ToLower(err.Error())
+	if !strings.Contains(message, "denied:") && !strings.Contains(message, "unauthorized:") {
+		return err
+	}
+
+	registries, listErr := r.azCli.GetContainerRegistries(ctx, subscriptionId)
+	if listErr != nil {
+		log.Printf("failed listing container registries, can't resolve registry %s: %v", loginServer, listErr)
+		return err
+	}
+
+	registryName := strings.Split(loginServer, ".")[0]
+	for _, registry := range registries {
+		if registry.Name != nil && registry.ID != nil && strings.EqualFold(*registry.Name, registryName) {
+			return &azcli.DataPlaneAccessError{ResourceId: *registry.ID, RoleName: azcli.AcrPushRole, Err: err}
+		}
+	}
+
+	return err
+}
+
+// tokenCredentials exchanges the Azure access token of azd for an ACR refresh token
+// https://github.com/Azure/acr/blob/main/docs/AAD-OAuth.md
+func (r *RegistryAuth) tokenCredentials(ctx context.Context, loginServer string) (*docker.RegistryCredentials, error) {
+	accessToken, err := r.azCli.GetAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":   {"access_token"},
+		"service":      {loginServer},
+		"access_token": {accessToken.AccessToken},
+	}
+
+	request, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("https://%s/oauth2/exchange", loginServer),
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating token exchange request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := r.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("exchanging token for registry '%s': %w", loginServer, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("exchanging token for registry '%s': status code %d", loginServer, response.StatusCode)
+	}
+
+	exchange, err := httputil.ReadRawResponse[tokenExchangeResponse](response)
+	if err != nil {
+		return nil, fmt.Errorf("reading token exchange response: %w", err)
+	}
+
+	return &docker.RegistryCredentials{
+		LoginServer: loginServer,
+		Username:    tokenUsername,
+		Password:    exchange.RefreshToken,
+	}, nil
+}
@@ -0,0 +1,177 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package containerregistry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const loginServer = "myregistry.azurecr.io"
+
+func Test_Credentials(t *testing.T) {
+	t.Run("Token", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		registerTokenExchangeMock(mockContext, http.StatusOK)
+
+		credentials, err := newRegistryAuth(mockContext).
+			Credentials(*mockContext.Context, "SUBSCRIPTION_ID", loginServer, AuthModeToken)
+		require.NoError(t, err)
+		require.Equal(t, loginServer, credentials.LoginServer)
+		require.Equal(t, tokenUsername, credentials.Username)
+		require.Equal(t, "REFRESH_TOKEN", credentials.Password)
+	})
+
+	t.Run("TokenFailure", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		registerTokenExchangeMock(mockContext, http.StatusUnauthorized)
+
+		credentials, err := newRegistryAuth(mockContext).
+			Credentials(*mockContext.Context, "SUBSCRIPTION_ID", loginServer, AuthModeToken)
+		require.Error(t, err)
+		require.Nil(t, credentials)
+	})
+
+	t.Run("DefaultFallsBackToAdmin", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		registerTokenExchangeMock(mockContext, http.StatusUnauthorized)
+		registerAdminCredentialsMocks(mockContext)
+
+		credentials, err := newRegistryAuth(mockContext).
+			Credentials(*mockContext.Context, "SUBSCRIPTION_ID", loginServer, AuthModeDefault)
+		require.NoError(t, err)
+		require.Equal(t, "ADMIN_USERNAME", credentials.Username)
+		require.Equal(t, "ADMIN_PASSWORD", credentials.Password)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		registerAdminCredentialsMocks(mockContext)
+
+		credentials, err := newRegistryAuth(mockContext).
+			Credentials(*mockContext.Context, "SUBSCRIPTION_ID", loginServer, AuthModeAdmin)
+		require.NoError(t, err)
+		require.Equal(t, loginServer, credentials.LoginServer)
+		require.Equal(t, "ADMIN_USERNAME", credentials.Username)
+	})
+
+	t.Run("Anonymous", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+
+		credentials, err := newRegistryAuth(mockContext).
+			Credentials(*mockContext.Context, "SUBSCRIPTION_ID", loginServer, AuthModeAnonymous)
+		require.NoError(t, err)
+		require.Nil(t, credentials)
+	})
+}
+
+func Test_PushAccessError(t *testing.T) {
+	denied := errors.New("pushing image: denied: requested access to the resource is denied: exit code: 1")
+
+	t.Run("Denied", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		registerAdminCredentialsMocks(mockContext)
+
+		err := newRegistryAuth(mockContext).
+			PushAccessError(*mockContext.Context, "SUBSCRIPTION_ID", loginServer, AuthModeToken, denied)
+
+		var accessErr *azcli.DataPlaneAccessError
+		require.ErrorAs(t, err, &accessErr)
+		require.Equal(t, azcli.AcrPushRole, accessErr.RoleName)
+		require.Equal(t, "myregistry", accessErr.ResourceName())
+		require.ErrorIs(t, err, denied)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+
+		err := newRegistryAuth(mockContext).
+			PushAccessError(*mockContext.Context, "SUBSCRIPTION_ID", loginServer, AuthModeAdmin, denied)
+		require.Equal(t, denied, err)
+	})
+
+	t.Run("OtherError", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		other := errors.New("pushing image: connection reset by peer")
+
+		err := newRegistryAuth(mockContext).
+			PushAccessError(*mockContext.Context, "SUBSCRIPTION_ID", loginServer, AuthModeDefault, other)
+		require.Equal(t, other, err)
+	})
+}
+
+func Test_ParseAuthMode(t *testing.T) {
+	mode, err := ParseAuthMode("")
+	require.NoError(t, err)
+	require.Equal(t, AuthModeDefault, mode)
+
+	mode, err = ParseAuthMode("Admin")
+	require.NoError(t, err)
+	require.Equal(t, AuthModeAdmin, mode)
+
+	_, err = ParseAuthMode("password")
+	require.Error(t, err)
+}
+
+func newRegistryAuth(mockContext *mocks.MockContext) *RegistryAuth {
+	return NewRegistryAuth(azcli.GetAzCli(*mockContext.Context), mockContext.HttpClient)
+}
+
+func registerTokenExchangeMock(mockContext *mocks.MockContext, statusCode int) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && request.URL.Host == loginServer &&
+			request.URL.Path == "/oauth2/exchange"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		if err := request.ParseForm(); err != nil {
+			return nil, err
+		}
+
+		if statusCode != http.StatusOK || request.Form.Get("service") != loginServer {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusUnauthorized)
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, statusCode, tokenExchangeResponse{
+			RefreshToken: "REFRESH_TOKEN",
+		})
+	})
+}
+
+func registerAdminCredentialsMocks(mockContext *mocks.MockContext) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet &&
+			strings.HasSuffix(request.URL.Path, "/providers/Microsoft.ContainerRegistry/registries")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armcontainerregistry.RegistryListResult{
+			Value: []*armcontainerregistry.Registry{
+				{
+					ID: convert.RefOf(
+						"/subscriptions/SUBSCRIPTION_ID/resourceGroups/RESOURCE_GROUP" +
+							"/providers/Microsoft.ContainerRegistry/registries/myregistry",
+					),
+					Name: convert.RefOf("myregistry"),
+				},
+			},
+		})
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/listCredentials")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armcontainerregistry.RegistryListCredentialsResult{
+			Username: convert.RefOf("ADMIN_USERNAME"),
+			Passwords: []*armcontainerregistry.RegistryPassword{
+				{Value: convert.RefOf("ADMIN_PASSWORD")},
+			},
+		})
+	})
+}
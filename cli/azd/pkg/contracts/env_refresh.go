@@ -2,10 +2,12 @@
 // Licensed under the MIT License.
 package contracts
 
-// EnvRefreshResult is the contract for the output of `azd env refresh`.
+// EnvRefreshResult is the contract for the output of `azd env refresh`, and of `azd provision`.
 type EnvRefreshResult struct {
 	Outputs   map[string]EnvRefreshOutputParameter `json:"outputs"`
 	Resources []EnvRefreshResource                 `json:"resources"`
+	// The time taken to provision the resources, only reported by `azd provision`
+	Timing *ProvisionTiming `json:"timing,omitempty"`
 }
 
 // EvnRefreshOutputType are the values for the "type" property of an output.
@@ -24,6 +26,8 @@ const (
 type EnvRefreshOutputParameter struct {
 	Type  EnvRefreshOutputType `json:"type"`
 	Value any                  `json:"value"`
+	// Whether the output is secure, the value of a secure output is masked
+	Secure bool `json:"secure,omitempty"`
 }
 
 // EnvRefreshResource is the contract for a resource in the "resources" array
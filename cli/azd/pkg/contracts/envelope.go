@@ -9,6 +9,7 @@ type EventDataType string
 
 const (
 	ConsoleMessageEventDataType EventDataType = "consoleMessage"
+	ErrorSummaryEventDataType   EventDataType = "errorSummary"
 )
 
 type EventEnvelope struct {
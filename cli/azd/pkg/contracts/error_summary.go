@@ -0,0 +1,11 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package contracts
+
+// ErrorSummary is the contract for the error of a failed command, written to stderr when azd runs in a CI system
+type ErrorSummary struct {
+	// The command that failed, i.e. "azd deploy"
+	Command string `json:"command"`
+	Message string `json:"message"`
+}
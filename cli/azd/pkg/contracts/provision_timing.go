@@ -0,0 +1,23 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+package contracts
+
+import "time"
+
+// ProvisionTiming is the contract for the time taken to provision the resources, reported by `azd provision`
+type ProvisionTiming struct {
+	TotalSeconds float64 `json:"totalSeconds"`
+	// The resources, slowest first
+	Resources []ProvisionResourceTiming `json:"resources"`
+}
+
+// ProvisionResourceTiming is the contract for a resource in the "resources" array of a ProvisionTiming
+type ProvisionResourceTiming struct {
+	Id              string    `json:"id"`
+	Name            string    `json:"name"`
+	Type            string    `json:"type"`
+	StartTime       time.Time `json:"startTime"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	// true when the resource is one of the slowest resources to provision
+	Slowest bool `json:"slowest"`
+}
@@ -15,6 +15,8 @@ const (
 type ShowResult struct {
 	Name     string                 `json:"name"`
 	Services map[string]ShowService `json:"services"`
+	// Pipeline contains information about the pipeline configured by `azd pipeline config`, if any
+	Pipeline *ShowPipeline `json:"pipeline,omitempty"`
 }
 
 // ShowService is the contract for a service returned by `azd show`
@@ -40,3 +42,32 @@ type ShowServiceProject struct {
 type ShowTargetArm struct {
 	ResourceIds []string `json:"resourceIds"`
 }
+
+// ShowPipeline is the contract for the pipeline configured for the environment, as returned by `azd show`
+type ShowPipeline struct {
+	// The CI provider running the pipeline, i.e. GitHub or Azure DevOps
+	Provider string `json:"provider"`
+	// The url of the Azure DevOps project, only set for Azure DevOps
+	ProjectUrl    string `json:"projectUrl,omitempty"`
+	RepositoryUrl string `json:"repositoryUrl"`
+	PipelineUrl   string `json:"pipelineUrl"`
+	// The name of the Azure DevOps service connection, only set for Azure DevOps
+	ServiceConnectionName string `json:"serviceConnectionName,omitempty"`
+	// The application id of the service principal the pipeline signs in to Azure with
+	ServicePrincipalAppId string `json:"servicePrincipalAppId"`
+	// The names of the secrets and variables set in the pipeline
+	Secrets []string `json:"secrets"`
+	// The stages of a multi-stage pipeline
+	Stages []ShowPipelineStage `json:"stages,omitempty"`
+}
+
+// ShowPipelineStage is the contract for a stage of a multi-stage pipeline, as returned by `azd show`
+type ShowPipelineStage struct {
+	Name string `json:"name"`
+	// The azd environment deployed by the stage
+	Environment string `json:"environment"`
+	// The application id of the service principal the stage signs in to Azure with
+	ServicePrincipalAppId string `json:"servicePrincipalAppId"`
+	// The name of the Azure DevOps service connection of the stage, only set for Azure DevOps
+	ServiceConnectionName string `json:"serviceConnectionName,omitempty"`
+}
@@ -0,0 +1,338 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// An external tool checked by the doctor
+type Tool struct {
+	Tool tools.ExternalTool
+	// When false, a missing tool is reported as a warning instead of a failure
+	Required bool
+}
+
+// Checks that each of the tools is installed and meets the minimum supported version
+func ToolsCheck(toolsToCheck []Tool) Check {
+	return func(ctx context.Context) []Result {
+		results := []Result{}
+
+		for _, tool := range toolsToCheck {
+			result := Result{
+				Category: CategoryTools,
+				Name:     tool.Tool.Name(),
+				Status:   StatusPassed,
+			}
+
+			missingStatus := StatusWarning
+			if tool.Required {
+				missingStatus = StatusFailed
+			}
+
+			has, err := tool.Tool.CheckInstalled(ctx)
+			var errSemver *tools.ErrSemver
+			switch {
+			case errors.As(err, &errSemver):
+				result.Status = missingStatus
+				result.Message = err.Error()
+				result.Remediation = fmt.Sprintf("Update %s, see %s", tool.Tool.Name(), tool.Tool.InstallUrl())
+			case err != nil:
+				result.Status = missingStatus
+				result.Message = fmt.Sprintf("checking installation: %s", err.Error())
+				result.Remediation = fmt.Sprintf("Reinstall %s, see %s", tool.Tool.Name(), tool.Tool.InstallUrl())
+			case !has:
+				result.Status = missingStatus
+				result.Message = "not installed"
+				result.Remediation = fmt.Sprintf("Install %s, see %s", tool.Tool.Name(), tool.Tool.InstallUrl())
+			}
+
+			results = append(results, result)
+		}
+
+		return results
+	}
+}
+
+// Checks that the user is logged in to Azure
+func AuthCheck(azCli azcli.AzCli) Check {
+	return func(ctx context.Context) []Result {
+		result := Result{
+			Category: CategoryAuth,
+			Name:     "Azure",
+			Status:   StatusPassed,
+			Message:  "logged in",
+		}
+
+		token, err := azCli.GetAccessToken(ctx)
+		switch {
+		case errors.Is(err, azcli.ErrAzCliNotLoggedIn):
+			result.Status = StatusFailed
+			result.Message = "not logged in"
+			result.Remediation = "Run `azd login`"
+		case errors.Is(err, azcli.ErrAzCliRefreshTokenExpired):
+			result.Status = StatusFailed
+			result.Message = "login expired"
+			result.Remediation = "Run `azd login`"
+		case err != nil:
+			result.Status = StatusFailed
+			result.Message = fmt.Sprintf("fetching access token: %s", err.Error())
+			result.Remediation = "Run `azd login`"
+		case token != nil && token.ExpiresOn != nil:
+			result.Message = fmt.Sprintf("logged in, token expires on %s", token.ExpiresOn.Format(time.RFC3339))
+		}
+
+		return []Result{result}
+	}
+}
+
+// A remote endpoint that azd depends on
+type Endpoint struct {
+	Name string
+	Url  string
+}
+
+// The endpoints checked for network reachability by default
+var DefaultEndpoints = []Endpoint{
+	{Name: "Azure Resource Manager", Url: "https://management.azure.com/"},
+	{Name: "Microsoft Graph", Url: "https://graph.microsoft.com/"},
+	{Name: "Azure DevOps", Url: "https://dev.azure.com/"},
+	{Name: "GitHub", Url: "https://github.com/"},
+}
+
+// The maximum time to wait for a response from each endpoint
+const endpointTimeout = 10 * time.Second
+
+// Checks that each of the endpoints can be reached.
+// Any HTTP response, including error status codes, is treated as reachable.
+func NetworkCheck(httpClient httputil.HttpClient, endpoints []Endpoint) Check {
+	return func(ctx context.Context) []Result {
+		results := []Result{}
+
+		for _, endpoint := range endpoints {
+			result := Result{
+				Category: CategoryNetwork,
+				Name:     endpoint.Name,
+				Status:   StatusPassed,
+				Message:  fmt.Sprintf("%s is reachable", endpoint.Url),
+			}
+
+			if err := checkEndpoint(ctx, httpClient, endpoint.Url); err != nil {
+				result.Status = StatusFailed
+				result.Message = fmt.Sprintf("%s is not reachable: %s", endpoint.Url, err.Error())
+				result.Remediation = "Check your network connection, firewall and proxy (HTTPS_PROXY) settings"
+			}
+
+			results = append(results, result)
+		}
+
+		return results
+	}
+}
+
+func checkEndpoint(ctx context.Context, httpClient httputil.HttpClient, url string) error {
+	ctx, cancel := context.WithTimeout(ctx, endpointTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return nil
+}
+
+// The environment values that are expected to be set in every azd environment
+var requiredEnvironmentValues = []string{
+	environment.EnvNameEnvVarName,
+	environment.LocationEnvVarName,
+	environment.SubscriptionIdEnvVarName,
+}
+
+// Checks that the named environment, or the default environment when name is empty, can be loaded
+// and contains the values azd requires.
+func EnvironmentCheck(azdCtx *azdcontext.AzdContext, name string) Check {
+	return func(ctx context.Context) []Result {
+		result := Result{
+			Category: CategoryEnvironment,
+			Name:     "Environment",
+			Status:   StatusPassed,
+		}
+
+		if !hasProject(azdCtx) {
+			result.Status = StatusSkipped
+			result.Message = "no project found"
+			return []Result{result}
+		}
+
+		if name == "" {
+			defaultName, err := azdCtx.GetSelectedEnvironmentName()
+			if err != nil {
+				result.Status = StatusFailed
+				result.Message = err.Error()
+				result.Remediation = "Run `azd env select <name>` to select a valid environment"
+				return []Result{result}
+			}
+
+			name = defaultName
+		}
+
+		if name == "" {
+			result.Status = StatusWarning
+			result.Message = "no environment selected"
+			result.Remediation = "Run `azd env new` to create an environment or `azd env select <name>` to select one"
+			return []Result{result}
+		}
+
+		result.Name = name
+
+		envFilePath := azdCtx.GetEnvironmentFilePath(name)
+		if _, err := os.Stat(envFilePath); err != nil {
+			result.Status = StatusFailed
+			result.Message = fmt.Sprintf("environment file %s not found", envFilePath)
+			result.Remediation = "Run `azd env new` to create the environment or `azd env refresh` to restore it"
+			return []Result{result}
+		}
+
+		env, err := environment.FromFile(envFilePath)
+		if err != nil {
+			result.Status = StatusFailed
+			result.Message = err.Error()
+			result.Remediation = fmt.Sprintf("Fix or remove the invalid lines in %s", envFilePath)
+			return []Result{result}
+		}
+
+		missing := []string{}
+		for _, key := range requiredEnvironmentValues {
+			if env.Values[key] == "" {
+				missing = append(missing, key)
+			}
+		}
+
+		if len(missing) > 0 {
+			result.Status = StatusWarning
+			result.Message = fmt.Sprintf("missing values: %v", missing)
+			result.Remediation = "Run `azd env set <key> <value>` or `azd provision` to set the missing values"
+			return []Result{result}
+		}
+
+		result.Message = envFilePath
+		return []Result{result}
+	}
+}
+
+// The published JSON schema for azure.yaml
+const projectSchemaUrl = "https://raw.githubusercontent.com/Azure/azure-dev/main/schemas/v1.0/azure.yaml.json"
+
+// Checks that azure.yaml can be parsed and that each service it defines is valid
+func ProjectCheck(azdCtx *azdcontext.AzdContext, env *environment.Environment) Check {
+	return func(ctx context.Context) []Result {
+		result := Result{
+			Category: CategoryProject,
+			Name:     azdcontext.ProjectFileName,
+			Status:   StatusPassed,
+		}
+
+		if !hasProject(azdCtx) {
+			result.Status = StatusSkipped
+			result.Message = "no project found"
+			return []Result{result}
+		}
+
+		if env == nil {
+			env = environment.Ephemeral()
+		}
+
+		prj, err := project.LoadProjectConfig(azdCtx.ProjectPath(), env)
+		if err != nil {
+			result.Status = StatusFailed
+			result.Message = err.Error()
+			result.Remediation = fmt.Sprintf("Validate azure.yaml against the schema at %s", projectSchemaUrl)
+			return []Result{result}
+		}
+
+		result.Message = azdCtx.ProjectPath()
+		results := []Result{result}
+
+		// The services are checked in the order of their names, the results are the same on every run
+		names := make([]string, 0, len(prj.Services))
+		for name := range prj.Services {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			results = append(results, checkService(ctx, name, prj.Services[name], env))
+		}
+
+		return results
+	}
+}
+
+func checkService(
+	ctx context.Context,
+	name string,
+	svc *project.ServiceConfig,
+	env *environment.Environment,
+) Result {
+	result := Result{
+		Category: CategoryProject,
+		Name:     fmt.Sprintf("service %s", name),
+		Status:   StatusPassed,
+	}
+
+	if _, err := os.Stat(svc.Path()); err != nil {
+		result.Status = StatusFailed
+		result.Message = fmt.Sprintf("project path %s not found", svc.Path())
+		result.Remediation = fmt.Sprintf("Update the 'project' property of service '%s' in azure.yaml", name)
+		return result
+	}
+
+	switch project.ServiceTargetKind(svc.Host) {
+	case "", project.AppServiceTarget, project.ContainerAppTarget, project.AzureFunctionTarget, project.StaticWebAppTarget:
+	default:
+		result.Status = StatusFailed
+		result.Message = fmt.Sprintf("unsupported host '%s'", svc.Host)
+		result.Remediation = fmt.Sprintf("Update the 'host' property of service '%s' in azure.yaml", name)
+		return result
+	}
+
+	if _, err := svc.GetFrameworkService(ctx, env); err != nil {
+		result.Status = StatusFailed
+		result.Message = err.Error()
+		result.Remediation = fmt.Sprintf("Update the 'language' property of service '%s' in azure.yaml", name)
+		return result
+	}
+
+	result.Message = svc.Path()
+	return result
+}
+
+func hasProject(azdCtx *azdcontext.AzdContext) bool {
+	if azdCtx == nil {
+		return false
+	}
+
+	_, err := os.Stat(azdCtx.ProjectPath())
+	return err == nil
+}
@@ -0,0 +1,69 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package doctor provides diagnostics for the local azd installation and the current project.
+package doctor
+
+import (
+	"context"
+)
+
+// The outcome of a single diagnostic check
+type Status string
+
+const (
+	StatusPassed  Status = "passed"
+	StatusWarning Status = "warning"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+)
+
+// The categories of diagnostic checks
+const (
+	CategoryTools       = "Tools"
+	CategoryAuth        = "Authentication"
+	CategoryNetwork     = "Network"
+	CategoryEnvironment = "Environment"
+	CategoryProject     = "Project"
+)
+
+// The result of a single diagnostic check
+type Result struct {
+	Category    string `json:"category"`
+	Name        string `json:"name"`
+	Status      Status `json:"status"`
+	Message     string `json:"message,omitempty"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// The collection of results produced by a diagnostic run
+type Report struct {
+	Results []Result `json:"results"`
+}
+
+// Returns true when any diagnostic check failed
+func (r *Report) HasFailures() bool {
+	for _, result := range r.Results {
+		if result.Status == StatusFailed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// A diagnostic check that produces one or more results
+type Check func(ctx context.Context) []Result
+
+// Runs each of the checks in order and collects the results into a report
+func Run(ctx context.Context, checks ...Check) *Report {
+	report := &Report{
+		Results: []Result{},
+	}
+
+	for _, check := range checks {
+		report.Results = append(report.Results, check(ctx)...)
+	}
+
+	return report
+}
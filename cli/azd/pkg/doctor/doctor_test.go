@@ -0,0 +1,204 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTool struct {
+	name      string
+	installed bool
+	err       error
+}
+
+func (t *mockTool) CheckInstalled(ctx context.Context) (bool, error) {
+	return t.installed, t.err
+}
+
+func (t *mockTool) InstallUrl() string {
+	return "https://example.com/install"
+}
+
+func (t *mockTool) Name() string {
+	return t.name
+}
+
+func TestToolsCheck(t *testing.T) {
+	report := Run(context.Background(), ToolsCheck([]Tool{
+		{Tool: &mockTool{name: "installed", installed: true}, Required: true},
+		{Tool: &mockTool{name: "missing"}, Required: true},
+		{Tool: &mockTool{name: "optional"}, Required: false},
+		{
+			Tool: &mockTool{
+				name: "outdated",
+				err: &tools.ErrSemver{
+					ToolName:    "outdated",
+					VersionInfo: tools.VersionInfo{MinimumVersion: semver.MustParse("1.0.0")},
+				},
+			},
+			Required: true,
+		},
+	}))
+
+	require.Len(t, report.Results, 4)
+	require.Equal(t, StatusPassed, report.Results[0].Status)
+	require.Equal(t, StatusFailed, report.Results[1].Status)
+	require.Contains(t, report.Results[1].Remediation, "https://example.com/install")
+	require.Equal(t, StatusWarning, report.Results[2].Status)
+	require.Equal(t, StatusFailed, report.Results[3].Status)
+	require.True(t, report.HasFailures())
+}
+
+func TestAuthCheck(t *testing.T) {
+	t.Run("LoggedIn", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		azCli := azcli.NewAzCli(&mocks.MockCredentials{}, azcli.NewAzCliArgs{CommandRunner: mockContext.CommandRunner})
+		results := AuthCheck(azCli)(*mockContext.Context)
+
+		require.Len(t, results, 1)
+		require.Equal(t, StatusPassed, results[0].Status)
+	})
+
+	t.Run("NotLoggedIn", func(t *testing.T) {
+		credential := &mocks.MockCredentials{
+			GetTokenFn: func(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+				return azcore.AccessToken{}, errors.New("Please run 'az login' to setup account.")
+			},
+		}
+		mockContext := mocks.NewMockContext(context.Background())
+		azCli := azcli.NewAzCli(credential, azcli.NewAzCliArgs{CommandRunner: mockContext.CommandRunner})
+		results := AuthCheck(azCli)(*mockContext.Context)
+
+		require.Len(t, results, 1)
+		require.Equal(t, StatusFailed, results[0].Status)
+		require.Contains(t, results[0].Remediation, "azd login")
+	})
+}
+
+func TestNetworkCheck(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.URL.Host == "reachable.example.com"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateEmptyHttpResponse(request, http.StatusUnauthorized)
+	})
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.URL.Host == "unreachable.example.com"
+	}).SetError(errors.New("dial tcp: lookup unreachable.example.com: no such host"))
+
+	results := NetworkCheck(mockContext.HttpClient, []Endpoint{
+		{Name: "Reachable", Url: "https://reachable.example.com/"},
+		{Name: "Unreachable", Url: "https://unreachable.example.com/"},
+	})(*mockContext.Context)
+
+	require.Len(t, results, 2)
+	require.Equal(t, StatusPassed, results[0].Status)
+	require.Equal(t, StatusFailed, results[1].Status)
+}
+
+func TestEnvironmentCheck(t *testing.T) {
+	dir := t.TempDir()
+	azdCtx := &azdcontext.AzdContext{}
+	azdCtx.SetProjectDirectory(dir)
+
+	t.Run("NoProject", func(t *testing.T) {
+		results := EnvironmentCheck(azdCtx, "")(context.Background())
+		require.Equal(t, StatusSkipped, results[0].Status)
+	})
+
+	err := os.WriteFile(azdCtx.ProjectPath(), []byte("name: test\n"), 0600)
+	require.NoError(t, err)
+
+	t.Run("NoEnvironment", func(t *testing.T) {
+		results := EnvironmentCheck(azdCtx, "")(context.Background())
+		require.Equal(t, StatusWarning, results[0].Status)
+	})
+
+	t.Run("MissingValues", func(t *testing.T) {
+		require.NoError(t, azdCtx.NewEnvironment("dev"))
+		err := os.WriteFile(azdCtx.GetEnvironmentFilePath("dev"), []byte("AZURE_ENV_NAME=dev\n"), 0600)
+		require.NoError(t, err)
+
+		results := EnvironmentCheck(azdCtx, "dev")(context.Background())
+		require.Equal(t, StatusWarning, results[0].Status)
+		require.Contains(t, results[0].Message, "AZURE_LOCATION")
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		contents := "AZURE_ENV_NAME=dev\nAZURE_LOCATION=eastus2\nAZURE_SUBSCRIPTION_ID=SUBSCRIPTION_ID\n"
+		err := os.WriteFile(azdCtx.GetEnvironmentFilePath("dev"), []byte(contents), 0600)
+		require.NoError(t, err)
+
+		results := EnvironmentCheck(azdCtx, "dev")(context.Background())
+		require.Equal(t, StatusPassed, results[0].Status)
+	})
+}
+
+func TestProjectCheck(t *testing.T) {
+	dir := t.TempDir()
+	azdCtx := &azdcontext.AzdContext{}
+	azdCtx.SetProjectDirectory(dir)
+	mockContext := mocks.NewMockContext(context.Background())
+
+	t.Run("Invalid", func(t *testing.T) {
+		err := os.WriteFile(azdCtx.ProjectPath(), []byte("name: [test\n"), 0600)
+		require.NoError(t, err)
+
+		results := ProjectCheck(azdCtx, nil)(*mockContext.Context)
+		require.Len(t, results, 1)
+		require.Equal(t, StatusFailed, results[0].Status)
+	})
+
+	t.Run("Services", func(t *testing.T) {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "api"), 0755))
+
+		contents := `name: test
+services:
+  api:
+    project: ./api
+    language: js
+    host: appservice
+  web:
+    project: ./web
+    language: js
+    host: appservice
+  worker:
+    project: ./api
+    language: js
+    host: unknown
+`
+		err := os.WriteFile(azdCtx.ProjectPath(), []byte(contents), 0600)
+		require.NoError(t, err)
+
+		results := ProjectCheck(azdCtx, nil)(*mockContext.Context)
+		require.Len(t, results, 4)
+
+		statuses := map[string]Status{}
+		names := []string{}
+		for _, result := range results {
+			statuses[result.Name] = result.Status
+			names = append(names, result.Name)
+		}
+
+		// The services are reported in the order of their names
+		require.Equal(t, []string{azdcontext.ProjectFileName, "service api", "service web", "service worker"}, names)
+
+		require.Equal(t, StatusPassed, statuses[azdcontext.ProjectFileName])
+		require.Equal(t, StatusPassed, statuses["service api"])
+		require.Equal(t, StatusFailed, statuses["service web"])
+		require.Equal(t, StatusFailed, statuses["service worker"])
+	})
+}
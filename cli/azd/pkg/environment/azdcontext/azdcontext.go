@@ -24,6 +24,7 @@ type AzdContext struct {
 type EnvironmentView struct {
 	Name       string
 	IsDefault  bool
+	IsSelected bool
 	DotEnvPath string
 }
 
@@ -69,6 +70,11 @@ func (c *AzdContext) ListEnvironments() ([]EnvironmentView, error) {
 		return nil, err
 	}
 
+	selectedEnv, err := c.GetSelectedEnvironmentName()
+	if err != nil {
+		return nil, err
+	}
+
 	ents, err := os.ReadDir(c.EnvironmentDirectory())
 	if err != nil {
 		return nil, fmt.Errorf("listing entries: %w", err)
@@ -80,6 +86,7 @@ func (c *AzdContext) ListEnvironments() ([]EnvironmentView, error) {
 			ev := EnvironmentView{
 				Name:       ent.Name(),
 				IsDefault:  ent.Name() == defaultEnv,
+				IsSelected: ent.Name() == selectedEnv,
 				DotEnvPath: c.GetEnvironmentFilePath(ent.Name()),
 			}
 			envs = append(envs, ev)
@@ -92,32 +99,83 @@ func (c *AzdContext) ListEnvironments() ([]EnvironmentView, error) {
 	return envs, nil
 }
 
-// GetDefaultEnvironmentName returns the name of the default environment. Returns
+// GetDefaultEnvironmentName returns the name of the default environment of the project. Returns
 // an empty string if a default environment has not been set.
 func (c *AzdContext) GetDefaultEnvironmentName() (string, error) {
+	config, err := c.readConfigFile()
+	if err != nil {
+		return "", err
+	}
+
+	return config.DefaultEnvironment, nil
+}
+
+// GetSelectedEnvironmentName returns the name of the environment commands use when none is specified: the
+// environment selected with `azd env select`, or the default environment when the selected environment doesn't
+// exist anymore. Returns an empty string if neither has been set.
+func (c *AzdContext) GetSelectedEnvironmentName() (string, error) {
+	config, err := c.readConfigFile()
+	if err != nil {
+		return "", err
+	}
+
+	if config.SelectedEnvironment != "" {
+		if _, err := os.Stat(filepath.Join(c.EnvironmentDirectory(), config.SelectedEnvironment)); err == nil {
+			return config.SelectedEnvironment, nil
+		}
+	}
+
+	return config.DefaultEnvironment, nil
+}
+
+// SetDefaultEnvironmentName sets the default environment of the project, which also becomes the selected
+// environment.
+func (c *AzdContext) SetDefaultEnvironmentName(name string) error {
+	return c.writeConfigFile(configFile{
+		Version:            ConfigFileVersion,
+		DefaultEnvironment: name,
+	})
+}
+
+// SetSelectedEnvironmentName selects the environment commands use when none is specified, leaving the default
+// environment of the project unchanged.
+func (c *AzdContext) SetSelectedEnvironmentName(name string) error {
+	config, err := c.readConfigFile()
+	if err != nil {
+		return err
+	}
+
+	config.Version = ConfigFileVersion
+	config.SelectedEnvironment = name
+	if config.DefaultEnvironment == "" || config.DefaultEnvironment == name {
+		config.DefaultEnvironment = name
+		config.SelectedEnvironment = ""
+	}
+
+	return c.writeConfigFile(config)
+}
+
+func (c *AzdContext) readConfigFile() (configFile, error) {
 	path := filepath.Join(c.EnvironmentDirectory(), ConfigFileName)
 	file, err := os.ReadFile(path)
 	switch {
 	case errors.Is(err, os.ErrNotExist):
-		return "", nil
+		return configFile{}, nil
 	case err != nil:
-		return "", fmt.Errorf("reading config file: %w", err)
+		return configFile{}, fmt.Errorf("reading config file: %w", err)
 	}
 
 	var config configFile
 	if err := json.Unmarshal(file, &config); err != nil {
-		return "", fmt.Errorf("deserializing config file: %w", err)
+		return configFile{}, fmt.Errorf("deserializing config file: %w", err)
 	}
 
-	return config.DefaultEnvironment, nil
+	return config, nil
 }
 
-func (c *AzdContext) SetDefaultEnvironmentName(name string) error {
+func (c *AzdContext) writeConfigFile(config configFile) error {
 	path := filepath.Join(c.EnvironmentDirectory(), ConfigFileName)
-	bytes, err := json.Marshal(configFile{
-		Version:            ConfigFileVersion,
-		DefaultEnvironment: name,
-	})
+	bytes, err := json.Marshal(config)
 	if err != nil {
 		return fmt.Errorf("serializing config file: %w", err)
 	}
@@ -188,6 +246,7 @@ func NewAzdContext() (*AzdContext, error) {
 }
 
 type configFile struct {
-	Version            int    `json:"version"`
-	DefaultEnvironment string `json:"defaultEnvironment"`
+	Version             int    `json:"version"`
+	DefaultEnvironment  string `json:"defaultEnvironment"`
+	SelectedEnvironment string `json:"selectedEnvironment,omitempty"`
 }
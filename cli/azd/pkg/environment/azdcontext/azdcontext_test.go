@@ -0,0 +1,48 @@
+package azdcontext
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectedEnvironment(t *testing.T) {
+	azdCtx := &AzdContext{projectDirectory: t.TempDir()}
+	for _, name := range []string{"dev", "prod"} {
+		require.NoError(t, azdCtx.NewEnvironment(name))
+	}
+
+	require.NoError(t, azdCtx.SetDefaultEnvironmentName("prod"))
+
+	t.Run("SelectKeepsDefault", func(t *testing.T) {
+		require.NoError(t, azdCtx.SetSelectedEnvironmentName("dev"))
+
+		requireEnvironments(t, azdCtx, "prod", "dev")
+	})
+
+	t.Run("SelectedDeleted", func(t *testing.T) {
+		require.NoError(t, os.RemoveAll(filepath.Join(azdCtx.EnvironmentDirectory(), "dev")))
+
+		requireEnvironments(t, azdCtx, "prod", "prod")
+	})
+
+	t.Run("SetDefaultSelects", func(t *testing.T) {
+		require.NoError(t, azdCtx.NewEnvironment("test"))
+		require.NoError(t, azdCtx.SetSelectedEnvironmentName("test"))
+		require.NoError(t, azdCtx.SetDefaultEnvironmentName("prod"))
+
+		requireEnvironments(t, azdCtx, "prod", "prod")
+	})
+}
+
+func requireEnvironments(t *testing.T, azdCtx *AzdContext, defaultName string, selectedName string) {
+	name, err := azdCtx.GetDefaultEnvironmentName()
+	require.NoError(t, err)
+	require.Equal(t, defaultName, name)
+
+	name, err = azdCtx.GetSelectedEnvironmentName()
+	require.NoError(t, err)
+	require.Equal(t, selectedName, name)
+}
@@ -8,6 +8,9 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
@@ -36,6 +39,34 @@ const ContainerRegistryEndpointEnvVarName = "AZURE_CONTAINER_REGISTRY_ENDPOINT"
 // ResourceGroupEnvVarName is the name of the azure resource group that should be used for deployments
 const ResourceGroupEnvVarName = "AZURE_RESOURCE_GROUP"
 
+// KeyVaultEndpointEnvVarName is the name of the key used to store the endpoint of the key vault the secrets of the
+// application are stored in.
+const KeyVaultEndpointEnvVarName = "AZURE_KEY_VAULT_ENDPOINT"
+
+// KeyVaultNameEnvVarName is the name of the key used to store the name of the key vault the secrets of the application
+// are stored in, when its endpoint isn't stored.
+const KeyVaultNameEnvVarName = "AZURE_KEY_VAULT_NAME"
+
+// AppConfigurationEndpointEnvVarName is the name of the key used to store the endpoint of the App Configuration store the
+// environment is synced with.
+const AppConfigurationEndpointEnvVarName = "AZURE_APP_CONFIGURATION_ENDPOINT"
+
+// LastDeploymentTimeEnvVarName is the name of the key used to store the time, in RFC 3339 format, the services of the
+// environment were last deployed at.
+const LastDeploymentTimeEnvVarName = "AZURE_LAST_DEPLOYMENT_TIME"
+
+// ProtectedEnvVarName is the name of the key used to store whether the environment is protected, in which case
+// destructive commands require the name of the environment to be typed to confirm them.
+const ProtectedEnvVarName = "AZURE_ENV_PROTECTED"
+
+// ExpiresOnEnvVarName is the name of the key used to store the time, in RFC 3339 format, the environment expires on.
+// The resource groups of an expired environment are deleted by the cleanup pipeline of the project.
+const ExpiresOnEnvVarName = "AZURE_ENV_EXPIRES_ON"
+
+// DeploymentNameEnvVarName is the name of the key used to store the name of the last ARM deployment started for the
+// environment, which may still be in progress.
+const DeploymentNameEnvVarName = "AZURE_DEPLOYMENT_NAME"
+
 type Environment struct {
 	// Values is a map of setting names to values.
 	Values map[string]string
@@ -147,6 +178,10 @@ func (e *Environment) SetSubscriptionId(id string) {
 	e.Values[SubscriptionIdEnvVarName] = id
 }
 
+func (e *Environment) SetTenantId(id string) {
+	e.Values[TenantIdEnvVarName] = id
+}
+
 func (e *Environment) GetLocation() string {
 	return e.Values[LocationEnvVarName]
 }
@@ -162,3 +197,79 @@ func (e *Environment) SetPrincipalId(principalID string) {
 func (e *Environment) GetPrincipalId() string {
 	return e.Values[PrincipalIdEnvVarName]
 }
+
+// GetLastDeploymentTime returns the time the services of the environment were last deployed at, and false when they
+// have never been deployed.
+func (e *Environment) GetLastDeploymentTime() (time.Time, bool) {
+	deployedAt, err := time.Parse(time.RFC3339, e.Values[LastDeploymentTimeEnvVarName])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return deployedAt, true
+}
+
+func (e *Environment) SetLastDeploymentTime(deployedAt time.Time) {
+	e.Values[LastDeploymentTimeEnvVarName] = deployedAt.UTC().Format(time.RFC3339)
+}
+
+// IsProtected returns true when destructive commands require the name of the environment to be typed to confirm them
+func (e *Environment) IsProtected() bool {
+	protected, err := strconv.ParseBool(e.Values[ProtectedEnvVarName])
+	return err == nil && protected
+}
+
+func (e *Environment) SetProtected(protected bool) {
+	if protected {
+		e.Values[ProtectedEnvVarName] = "true"
+	} else {
+		delete(e.Values, ProtectedEnvVarName)
+	}
+}
+
+// GetExpiresOn returns the time the environment expires on, and false when it doesn't expire
+func (e *Environment) GetExpiresOn() (time.Time, bool) {
+	expiresOn, err := time.Parse(time.RFC3339, e.Values[ExpiresOnEnvVarName])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return expiresOn, true
+}
+
+// SetExpiresOn sets the time the environment expires on, the zero time removes the expiry
+func (e *Environment) SetExpiresOn(expiresOn time.Time) {
+	if expiresOn.IsZero() {
+		delete(e.Values, ExpiresOnEnvVarName)
+	} else {
+		e.Values[ExpiresOnEnvVarName] = expiresOn.UTC().Format(time.RFC3339)
+	}
+}
+
+// GetKeyVaultEndpoint returns the endpoint of the key vault provisioned for the environment, without a trailing slash,
+// or an empty string when the environment has no key vault.
+func (e *Environment) GetKeyVaultEndpoint() string {
+	if endpoint := e.Values[KeyVaultEndpointEnvVarName]; endpoint != "" {
+		return strings.TrimSuffix(endpoint, "/")
+	}
+
+	if name := e.Values[KeyVaultNameEnvVarName]; name != "" {
+		return fmt.Sprintf("https://%s.vault.azure.net", name)
+	}
+
+	return ""
+}
+
+// GetDeploymentName returns the name of the last ARM deployment started for the environment. The deployments started
+// before the name was stored are named after the environment.
+func (e *Environment) GetDeploymentName() string {
+	if name := e.Values[DeploymentNameEnvVarName]; name != "" {
+		return name
+	}
+
+	return e.GetEnvName()
+}
+
+func (e *Environment) SetDeploymentName(name string) {
+	e.Values[DeploymentNameEnvVarName] = name
+}
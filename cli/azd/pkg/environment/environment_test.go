@@ -5,6 +5,7 @@ package environment
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -19,3 +20,69 @@ func TestIsValidEnvironmentName(t *testing.T) {
 	assert.False(t, IsValidEnvironmentName("no spaces"))
 	assert.False(t, IsValidEnvironmentName("12345678901234567890123456789012345678901234567890123456789012345"))
 }
+
+func TestGetKeyVaultEndpoint(t *testing.T) {
+	env := Ephemeral()
+	assert.Equal(t, "", env.GetKeyVaultEndpoint())
+
+	env.Values[KeyVaultNameEnvVarName] = "myvault"
+	assert.Equal(t, "https://myvault.vault.azure.net", env.GetKeyVaultEndpoint())
+
+	env.Values[KeyVaultEndpointEnvVarName] = "https://othervault.vault.azure.net/"
+	assert.Equal(t, "https://othervault.vault.azure.net", env.GetKeyVaultEndpoint())
+}
+
+func TestLastDeploymentTime(t *testing.T) {
+	env := Ephemeral()
+	_, has := env.GetLastDeploymentTime()
+	assert.False(t, has)
+
+	deployedAt := time.Date(2022, 10, 1, 12, 30, 0, 0, time.UTC)
+	env.SetLastDeploymentTime(deployedAt)
+	assert.Equal(t, "2022-10-01T12:30:00Z", env.Values[LastDeploymentTimeEnvVarName])
+
+	got, has := env.GetLastDeploymentTime()
+	assert.True(t, has)
+	assert.True(t, deployedAt.Equal(got))
+}
+
+func TestExpiresOn(t *testing.T) {
+	env := Ephemeral()
+	_, has := env.GetExpiresOn()
+	assert.False(t, has)
+
+	expiresOn := time.Date(2022, 10, 4, 12, 30, 0, 0, time.FixedZone("PDT", -7*60*60))
+	env.SetExpiresOn(expiresOn)
+	assert.Equal(t, "2022-10-04T19:30:00Z", env.Values[ExpiresOnEnvVarName])
+
+	got, has := env.GetExpiresOn()
+	assert.True(t, has)
+	assert.True(t, expiresOn.Equal(got))
+
+	env.SetExpiresOn(time.Time{})
+	assert.NotContains(t, env.Values, ExpiresOnEnvVarName)
+}
+
+func TestProtected(t *testing.T) {
+	env := Ephemeral()
+	assert.False(t, env.IsProtected())
+
+	env.SetProtected(true)
+	assert.True(t, env.IsProtected())
+	assert.Equal(t, "true", env.Values[ProtectedEnvVarName])
+
+	env.SetProtected(false)
+	assert.False(t, env.IsProtected())
+	assert.NotContains(t, env.Values, ProtectedEnvVarName)
+
+	env.Values[ProtectedEnvVarName] = "not-a-bool"
+	assert.False(t, env.IsProtected())
+}
+
+func TestDeploymentName(t *testing.T) {
+	env := EphemeralWithValues("dev", nil)
+	assert.Equal(t, "dev", env.GetDeploymentName())
+
+	env.SetDeploymentName("dev-0123abcd-1664627400")
+	assert.Equal(t, "dev-0123abcd-1664627400", env.GetDeploymentName())
+}
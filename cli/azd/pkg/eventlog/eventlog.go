@@ -0,0 +1,212 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package eventlog writes the machine-readable log of the events of a command run, so that a failure can be debugged
+// after the fact and the log attached to an issue. Each run writes its own JSON lines file, and only the most recent
+// files are kept.
+package eventlog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+)
+
+// DirectoryName is the name of the folder of the event logs, within the folder of an environment
+const DirectoryName = "logs"
+
+// The number of event log files kept in the folder, the oldest files are deleted first
+const maxLogFiles = 20
+
+const logFileExtension = ".jsonl"
+
+type EventType string
+
+const (
+	CommandStartedEvent   EventType = "commandStarted"
+	CommandCompletedEvent EventType = "commandCompleted"
+	StepCompletedEvent    EventType = "stepCompleted"
+	ResourcesEvent        EventType = "resources"
+)
+
+// Event is a line of an event log
+type Event struct {
+	Type      EventType `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	// The command or the step of the event, i.e. "azd provision" or "provision/deploy"
+	Name       string `json:"name"`
+	DurationMs *int64 `json:"durationMs,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// The ids of the Azure resources the step acted on
+	ResourceIds []string `json:"resourceIds,omitempty"`
+}
+
+// Log is the event log of a command run. The methods of a nil *Log do nothing, so events are written without checking
+// whether the command run is logged.
+type Log struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// Create creates the event log of a run of command in dir, deleting the oldest logs of the folder
+func Create(dir string, command string) (*Log, error) {
+	if err := os.MkdirAll(dir, osutil.PermissionDirectory); err != nil {
+		return nil, fmt.Errorf("creating event log folder: %w", err)
+	}
+
+	path := filepath.Join(dir, logFileName(command, time.Now()))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, osutil.PermissionFile)
+	if err != nil {
+		return nil, fmt.Errorf("creating event log: %w", err)
+	}
+
+	if err := rotate(dir, maxLogFiles); err != nil {
+		log.Printf("rotating event logs: %v", err)
+	}
+
+	return &Log{
+		path:    path,
+		file:    file,
+		encoder: json.NewEncoder(file),
+	}, nil
+}
+
+// Path returns the path to the file of the log
+func (l *Log) Path() string {
+	if l == nil {
+		return ""
+	}
+
+	return l.path
+}
+
+// Write appends an event to the log, failures to write are only traced since the log doesn't affect the command
+func (l *Log) Write(event Event) {
+	if l == nil {
+		return
+	}
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.encoder.Encode(event); err != nil {
+		log.Printf("writing event log: %v", err)
+	}
+}
+
+// StartStep starts timing a step, the returned function writes the outcome of the step once it completes
+func (l *Log) StartStep(name string) func(err error, resourceIds ...string) {
+	start := time.Now()
+
+	return func(err error, resourceIds ...string) {
+		event := Event{
+			Type:        StepCompletedEvent,
+			Name:        name,
+			DurationMs:  durationMs(time.Since(start)),
+			ResourceIds: resourceIds,
+		}
+		if err != nil {
+			event.Error = err.Error()
+		}
+
+		l.Write(event)
+	}
+}
+
+// NewCommandCompletedEvent creates the event of the completion of a command run that took duration
+func NewCommandCompletedEvent(command string, duration time.Duration, err error) Event {
+	event := Event{
+		Type:       CommandCompletedEvent,
+		Name:       command,
+		DurationMs: durationMs(duration),
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+
+	return event
+}
+
+// Close closes the file of the log
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.file.Close()
+}
+
+type contextKey string
+
+const logContextKey contextKey = "event_log"
+
+// WithLog sets the event log of the command run in context and returns the new context
+func WithLog(ctx context.Context, eventLog *Log) context.Context {
+	return context.WithValue(ctx, logContextKey, eventLog)
+}
+
+// FromContext returns the event log of the command run, or nil when the run isn't logged
+func FromContext(ctx context.Context) *Log {
+	eventLog, _ := ctx.Value(logContextKey).(*Log)
+	return eventLog
+}
+
+func durationMs(duration time.Duration) *int64 {
+	ms := duration.Milliseconds()
+	return &ms
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9-]+`)
+
+// The log files sort by the time of their run, i.e. 20230102T150405.000Z-azd-provision.jsonl
+func logFileName(command string, now time.Time) string {
+	name := strings.Trim(unsafeFileNameChars.ReplaceAllString(command, "-"), "-")
+	return fmt.Sprintf("%s-%s%s", now.UTC().Format("20060102T150405.000Z"), name, logFileExtension)
+}
+
+// Deletes the oldest log files of dir, keeping at most max files
+func rotate(dir string, max int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var logFiles []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == logFileExtension {
+			logFiles = append(logFiles, entry.Name())
+		}
+	}
+
+	if len(logFiles) <= max {
+		return nil
+	}
+
+	sort.Strings(logFiles)
+	for _, name := range logFiles[:len(logFiles)-max] {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package eventlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readEvents(t *testing.T, path string) []Event {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func TestLog(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), DirectoryName)
+
+	eventLog, err := Create(dir, "azd provision")
+	require.NoError(t, err)
+	require.Equal(t, dir, filepath.Dir(eventLog.Path()))
+	require.Regexp(t, `^\d{8}T\d{6}\.\d{3}Z-azd-provision\.jsonl$`, filepath.Base(eventLog.Path()))
+
+	ctx := WithLog(context.Background(), eventLog)
+	FromContext(ctx).Write(Event{Type: CommandStartedEvent, Name: "azd provision"})
+	FromContext(ctx).StartStep("provision/plan")(nil)
+	FromContext(ctx).StartStep("provision/deploy")(errors.New("deployment failed"), "/subscriptions/sub/resourceGroups/rg")
+	require.NoError(t, eventLog.Close())
+
+	events := readEvents(t, eventLog.Path())
+	require.Len(t, events, 3)
+
+	require.Equal(t, CommandStartedEvent, events[0].Type)
+	require.False(t, events[0].Timestamp.IsZero())
+
+	require.Equal(t, StepCompletedEvent, events[1].Type)
+	require.Equal(t, "provision/plan", events[1].Name)
+	require.NotNil(t, events[1].DurationMs)
+	require.Empty(t, events[1].Error)
+
+	require.Equal(t, "provision/deploy", events[2].Name)
+	require.Equal(t, "deployment failed", events[2].Error)
+	require.Equal(t, []string{"/subscriptions/sub/resourceGroups/rg"}, events[2].ResourceIds)
+}
+
+func TestLogNotLogged(t *testing.T) {
+	// Writing events when the run isn't logged does nothing
+	eventLog := FromContext(context.Background())
+	require.Nil(t, eventLog)
+
+	eventLog.Write(Event{Type: CommandStartedEvent})
+	eventLog.StartStep("deploy/api")(nil)
+	require.NoError(t, eventLog.Close())
+}
+
+func TestRotate(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	for i := 0; i < 5; i++ {
+		name := logFileName("azd deploy", start.Add(time.Duration(i)*time.Minute))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0600))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0600))
+
+	require.NoError(t, rotate(dir, 3))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	// The oldest logs are deleted, other files are kept
+	require.Equal(t, []string{
+		fmt.Sprintf("20230102T150605.000Z-azd-deploy%s", logFileExtension),
+		fmt.Sprintf("20230102T150705.000Z-azd-deploy%s", logFileExtension),
+		fmt.Sprintf("20230102T150805.000Z-azd-deploy%s", logFileExtension),
+		"notes.txt",
+	}, names)
+}
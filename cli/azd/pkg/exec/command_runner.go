@@ -29,6 +29,17 @@ func NewCommandRunner(stdin io.Reader, stdout io.Writer, stderr io.Writer) Comma
 	}
 }
 
+// NewVerboseCommandRunner creates a CommandRunner that also copies the standard error of the commands it runs to
+// stderr, showing the diagnostics of the tools launched by azd, i.e. the warnings of bicep
+func NewVerboseCommandRunner(stdin io.Reader, stdout io.Writer, stderr io.Writer) CommandRunner {
+	return &commandRunner{
+		stdin:      stdin,
+		stdout:     stdout,
+		stderr:     stderr,
+		echoStderr: true,
+	}
+}
+
 type contextKey string
 
 const (
@@ -58,6 +69,8 @@ type commandRunner struct {
 	stdin  io.Reader
 	stdout io.Writer
 	stderr io.Writer
+	// When set, the standard error of the commands is copied to stderr
+	echoStderr bool
 }
 
 // Run runs the command specified in 'args'.
@@ -94,8 +107,18 @@ func (r *commandRunner) Run(ctx context.Context, args RunArgs) (RunResult, error
 		cmd.Stdout = &stdout
 		cmd.Stderr = &stderr
 
+		if args.Stdin != nil {
+			cmd.Stdin = args.Stdin
+		}
+
+		if args.Stdout != nil {
+			cmd.Stdout = io.MultiWriter(args.Stdout, &stdout)
+		}
+
 		if args.Stderr != nil {
 			cmd.Stderr = io.MultiWriter(args.Stderr, &stderr)
+		} else if r.echoStderr {
+			cmd.Stderr = io.MultiWriter(r.stderr, &stderr)
 		}
 	}
 
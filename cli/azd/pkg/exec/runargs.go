@@ -11,6 +11,14 @@ type RunArgs struct {
 	Cwd  string
 	Env  []string
 
+	// Stdin, when set, is used as the standard input of the command.
+	Stdin io.Reader
+
+	// Stdout will receive a copy of the text written to Stdout by
+	// the command.
+	// NOTE: RunResult.Stdout will still contain stdout output.
+	Stdout io.Writer
+
 	// Stderr will receive a copy of the text written to Stderr by
 	// the command.
 	// NOTE: RunResult.Stderr will still contain stderr output.
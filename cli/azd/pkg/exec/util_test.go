@@ -153,6 +153,31 @@ func TestRunCapturingStderr(t *testing.T) {
 	require.Equal(t, res.Stderr, myStderr.String())
 }
 
+func TestRunVerboseEchoesStderr(t *testing.T) {
+	runnerStderr := &bytes.Buffer{}
+
+	runner := NewVerboseCommandRunner(os.Stdin, os.Stdout, runnerStderr)
+	res, _ := runner.Run(context.Background(), RunArgs{
+		Cmd:  "go",
+		Args: []string{"--help"},
+	})
+
+	require.NotEmpty(t, res.Stderr)
+	require.Equal(t, res.Stderr, runnerStderr.String())
+
+	// The stderr of the command is only copied to the writer of the run when set
+	runnerStderr.Reset()
+	myStderr := &bytes.Buffer{}
+	_, _ = runner.Run(context.Background(), RunArgs{
+		Cmd:    "go",
+		Args:   []string{"--help"},
+		Stderr: myStderr,
+	})
+
+	require.NotEmpty(t, myStderr.String())
+	require.Empty(t, runnerStderr.String())
+}
+
 func TestRunEnrichError(t *testing.T) {
 	runner := NewCommandRunner(os.Stdin, os.Stdout, os.Stderr)
 	_, err := runner.Run(context.Background(), RunArgs{
@@ -0,0 +1,265 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package extensions implements support for azd extensions.
+//
+// An extension is a directory containing an extension.json manifest and an executable. Extensions contribute
+// commands, exposed as `azd <extension> <command>`, and may subscribe to lifecycle events raised by azd commands.
+//
+// azd communicates with an extension using JSON-RPC 2.0 over stdio. For each invocation azd starts the
+// extension executable, writes a single request as a line of JSON to its stdin and reads newline delimited
+// JSON-RPC messages from its stdout. While handling the request, the extension may send console/message
+// notifications which are displayed to the user. The invocation completes once the extension writes the
+// response to the request and exits. Anything written to stderr is passed through to the user.
+package extensions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/otiai10/copy"
+)
+
+// ErrNotInstalled is returned when the requested extension isn't installed
+var ErrNotInstalled = errors.New("extension not installed")
+
+// Extension is an installed extension
+type Extension struct {
+	Manifest
+	// The directory the extension is installed to
+	Path string `json:"path"`
+}
+
+// ExecutablePath returns the full path of the extension executable
+func (e *Extension) ExecutablePath() string {
+	return filepath.Join(e.Path, e.Executable)
+}
+
+// Manager installs, lists and invokes extensions
+type Manager struct {
+	// The directory extensions are installed to, each extension within a directory of the same name
+	root string
+}
+
+// NewManager creates a new Manager for the extensions installed within the azd user config directory
+func NewManager() (*Manager, error) {
+	configDir, err := config.GetUserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("getting user config directory: %w", err)
+	}
+
+	return &Manager{
+		root: filepath.Join(configDir, "extensions"),
+	}, nil
+}
+
+// List returns the installed extensions, sorted by name.
+// Extensions with an invalid manifest are skipped.
+func (m *Manager) List() ([]*Extension, error) {
+	entries, err := os.ReadDir(m.root)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*Extension{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading extensions directory: %w", err)
+	}
+
+	installed := []*Extension{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		extension, err := m.load(entry.Name())
+		if err != nil {
+			log.Printf("skipping extension '%s': %v", entry.Name(), err)
+			continue
+		}
+
+		installed = append(installed, extension)
+	}
+
+	sort.Slice(installed, func(i, j int) bool {
+		return installed[i].Name < installed[j].Name
+	})
+
+	return installed, nil
+}
+
+// Get returns the installed extension with the specified name
+func (m *Manager) Get(name string) (*Extension, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("'%s': %w", name, ErrNotInstalled)
+	}
+
+	if _, err := os.Stat(filepath.Join(m.root, name)); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("'%s': %w", name, ErrNotInstalled)
+	}
+
+	return m.load(name)
+}
+
+// Install installs the extension from the source, a local directory containing an extension.json manifest or
+// the path to the manifest itself. An existing installation of the same extension is replaced.
+func (m *Manager) Install(source string) (*Extension, error) {
+	sourceDir, err := filepath.Abs(source)
+	if err != nil {
+		return nil, fmt.Errorf("resolving extension source: %w", err)
+	}
+
+	if filepath.Base(sourceDir) == ManifestFileName {
+		sourceDir = filepath.Dir(sourceDir)
+	}
+
+	info, err := os.Stat(sourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading extension source: %w", err)
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("extension source '%s' must be a directory or an %s file", source, ManifestFileName)
+	}
+
+	manifest, err := LoadManifest(sourceDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(filepath.Join(sourceDir, manifest.Executable)); err != nil {
+		return nil, fmt.Errorf("extension '%s' executable '%s' not found: %w", manifest.Name, manifest.Executable, err)
+	}
+
+	targetDir := filepath.Join(m.root, manifest.Name)
+	if err := os.RemoveAll(targetDir); err != nil {
+		return nil, fmt.Errorf("removing previous installation of extension '%s': %w", manifest.Name, err)
+	}
+
+	if err := copy.Copy(sourceDir, targetDir); err != nil {
+		return nil, fmt.Errorf("copying extension '%s': %w", manifest.Name, err)
+	}
+
+	return &Extension{
+		Manifest: *manifest,
+		Path:     targetDir,
+	}, nil
+}
+
+// Uninstall removes the installed extension with the specified name
+func (m *Manager) Uninstall(name string) error {
+	extension, err := m.Get(name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(extension.Path); err != nil {
+		return fmt.Errorf("removing extension '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+// Subscribers returns the installed extensions subscribed to the specified lifecycle event
+func (m *Manager) Subscribers(event string) ([]*Extension, error) {
+	installed, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	subscribers := []*Extension{}
+	for _, extension := range installed {
+		if extension.HasEvent(event) {
+			subscribers = append(subscribers, extension)
+		}
+	}
+
+	return subscribers, nil
+}
+
+func (m *Manager) load(name string) (*Extension, error) {
+	path := filepath.Join(m.root, name)
+
+	manifest, err := LoadManifest(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest.Name != name {
+		return nil, fmt.Errorf("extension manifest name '%s' does not match directory '%s'", manifest.Name, name)
+	}
+
+	return &Extension{
+		Manifest: *manifest,
+		Path:     path,
+	}, nil
+}
+
+// InvokeArgs are the arguments used when invoking an extension
+type InvokeArgs struct {
+	// The JSON-RPC method to invoke
+	Method string
+	// The params of the JSON-RPC request
+	Params any
+	// Receives the console messages sent by the extension
+	Stdout io.Writer
+	// Receives the text written to stderr by the extension
+	Stderr io.Writer
+}
+
+// Invoke starts the extension, sends it a single request and waits for the response.
+// An error is returned when the extension responds with an error or exits without responding.
+func Invoke(
+	ctx context.Context,
+	commandRunner exec.CommandRunner,
+	extension *Extension,
+	args InvokeArgs,
+) error {
+	const requestId = 1
+
+	request, err := encodeRequest(requestId, args.Method, args.Params)
+	if err != nil {
+		return err
+	}
+
+	runArgs := exec.NewRunArgs(extension.ExecutablePath()).
+		WithEnv([]string{"AZD_EXTENSION_PROTOCOL=jsonrpc"})
+
+	runArgs.Stdin = bytes.NewReader(request)
+	runArgs.Stderr = args.Stderr
+
+	var messages *messageWriter
+	if args.Stdout != nil {
+		messages = newMessageWriter(args.Stdout)
+		runArgs.Stdout = messages
+	}
+
+	res, runErr := commandRunner.Run(ctx, runArgs)
+
+	if messages != nil {
+		messages.Flush()
+	}
+
+	response, err := findResponse(res.Stdout, requestId)
+	if err != nil {
+		if runErr != nil {
+			return fmt.Errorf("running extension '%s': %w", extension.Name, runErr)
+		}
+
+		return fmt.Errorf("running extension '%s': %w", extension.Name, err)
+	}
+
+	if response.Error != nil {
+		return fmt.Errorf("extension '%s' failed: %w", extension.Name, response.Error)
+	}
+
+	if runErr != nil {
+		return fmt.Errorf("running extension '%s': %w", extension.Name, runErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package extensions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func writeExtension(t *testing.T, manifest Manifest) string {
+	dir := t.TempDir()
+
+	manifestJson, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ManifestFileName), manifestJson, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, manifest.Executable), []byte("#!/bin/sh\n"), 0700))
+
+	return dir
+}
+
+func testManifest(name string) Manifest {
+	return Manifest{
+		Name:       name,
+		Version:    "1.0.0",
+		Executable: "run.sh",
+		Commands:   []CommandManifest{{Name: "hello", Short: "Says hello"}},
+		Events:     []string{EventDeployed},
+	}
+}
+
+func Test_Manifest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(m *Manifest)
+		wantErr bool
+	}{
+		{name: "Valid", mutate: func(m *Manifest) {}},
+		{name: "InvalidName", mutate: func(m *Manifest) { m.Name = "My Extension" }, wantErr: true},
+		{name: "MissingVersion", mutate: func(m *Manifest) { m.Version = "" }, wantErr: true},
+		{name: "MissingExecutable", mutate: func(m *Manifest) { m.Executable = "" }, wantErr: true},
+		{name: "ExecutableOutsideDirectory", mutate: func(m *Manifest) { m.Executable = "../run.sh" }, wantErr: true},
+		{name: "InvalidCommand", mutate: func(m *Manifest) { m.Commands[0].Name = "Hello!" }, wantErr: true},
+		{
+			name: "DuplicateCommand",
+			mutate: func(m *Manifest) {
+				m.Commands = append(m.Commands, CommandManifest{Name: "hello"})
+			},
+			wantErr: true,
+		},
+		{name: "UnknownEvent", mutate: func(m *Manifest) { m.Events = []string{"built"} }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := testManifest("sample")
+			tt.mutate(&manifest)
+
+			err := manifest.Validate()
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_Manager_InstallListUninstall(t *testing.T) {
+	manager := &Manager{root: t.TempDir()}
+
+	extensions, err := manager.List()
+	require.NoError(t, err)
+	require.Empty(t, extensions)
+
+	source := writeExtension(t, testManifest("sample"))
+
+	installed, err := manager.Install(filepath.Join(source, ManifestFileName))
+	require.NoError(t, err)
+	require.Equal(t, "sample", installed.Name)
+	require.FileExists(t, installed.ExecutablePath())
+
+	// Installing again replaces the existing installation
+	upgraded := testManifest("sample")
+	upgraded.Version = "2.0.0"
+	_, err = manager.Install(writeExtension(t, upgraded))
+	require.NoError(t, err)
+
+	extensions, err = manager.List()
+	require.NoError(t, err)
+	require.Len(t, extensions, 1)
+	require.Equal(t, "2.0.0", extensions[0].Version)
+
+	subscribers, err := manager.Subscribers(EventDeployed)
+	require.NoError(t, err)
+	require.Len(t, subscribers, 1)
+
+	subscribers, err = manager.Subscribers(EventProvisioned)
+	require.NoError(t, err)
+	require.Empty(t, subscribers)
+
+	require.NoError(t, manager.Uninstall("sample"))
+
+	_, err = manager.Get("sample")
+	require.ErrorIs(t, err, ErrNotInstalled)
+	require.ErrorIs(t, manager.Uninstall("sample"), ErrNotInstalled)
+}
+
+func Test_Manager_InstallMissingExecutable(t *testing.T) {
+	manager := &Manager{root: t.TempDir()}
+	source := writeExtension(t, testManifest("sample"))
+	require.NoError(t, os.Remove(filepath.Join(source, "run.sh")))
+
+	_, err := manager.Install(source)
+	require.Error(t, err)
+}
+
+func Test_Invoke(t *testing.T) {
+	extension := &Extension{Manifest: testManifest("sample"), Path: t.TempDir()}
+	params := CommandParams{Command: "hello", Args: []string{"--name", "azd"}}
+
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		var request rpcRequest
+
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return args.Cmd == extension.ExecutablePath()
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			requestJson, err := io.ReadAll(args.Stdin)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(requestJson, &request))
+
+			output := strings.Join([]string{
+				`{"jsonrpc":"2.0","method":"console/message","params":{"message":"Hello, azd"}}`,
+				`{"jsonrpc":"2.0","id":1,"result":{}}`,
+			}, "\n")
+
+			_, err = args.Stdout.Write([]byte(output))
+			require.NoError(t, err)
+
+			return exec.NewRunResult(0, output, ""), nil
+		})
+
+		stdout := &bytes.Buffer{}
+		err := Invoke(*mockContext.Context, mockContext.CommandRunner, extension, InvokeArgs{
+			Method: MethodRunCommand,
+			Params: params,
+			Stdout: stdout,
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, MethodRunCommand, request.Method)
+		require.Equal(t, "Hello, azd\n", stdout.String())
+	})
+
+	t.Run("ErrorResponse", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return args.Cmd == extension.ExecutablePath()
+		}).Respond(exec.NewRunResult(0, `{"jsonrpc":"2.0","id":1,"error":{"code":1,"message":"unknown name"}}`, ""))
+
+		err := Invoke(*mockContext.Context, mockContext.CommandRunner, extension, InvokeArgs{
+			Method: MethodRunCommand,
+			Params: params,
+		})
+
+		var rpcErr *RpcError
+		require.ErrorAs(t, err, &rpcErr)
+		require.Equal(t, "unknown name", rpcErr.Message)
+	})
+
+	t.Run("NoResponse", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return args.Cmd == extension.ExecutablePath()
+		}).Respond(exec.NewRunResult(0, "not a response", ""))
+
+		err := Invoke(*mockContext.Context, mockContext.CommandRunner, extension, InvokeArgs{
+			Method: MethodRunCommand,
+			Params: params,
+		})
+
+		require.Error(t, err)
+	})
+}
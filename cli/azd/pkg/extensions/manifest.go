@@ -0,0 +1,152 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package extensions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// The name of the manifest file that describes an extension
+const ManifestFileName = "extension.json"
+
+// The lifecycle events an extension can subscribe to
+const (
+	EventInitializing = "initializing"
+	EventInitialized  = "initialized"
+	EventProvisioning = "provisioning"
+	EventProvisioned  = "provisioned"
+	EventDeploying    = "deploying"
+	EventDeployed     = "deployed"
+	EventDestroying   = "destroying"
+	EventDestroyed    = "destroyed"
+)
+
+var knownEvents = map[string]struct{}{
+	EventInitializing: {},
+	EventInitialized:  {},
+	EventProvisioning: {},
+	EventProvisioned:  {},
+	EventDeploying:    {},
+	EventDeployed:     {},
+	EventDestroying:   {},
+	EventDestroyed:    {},
+}
+
+// Extension and command names are used as azd sub commands and directory names
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,29}$`)
+
+// Manifest describes an extension, it is read from the extension.json file at the root of the extension.
+type Manifest struct {
+	// The unique name of the extension, also used as the azd sub command that groups the extension commands
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+	// The path to the extension executable, relative to the extension directory
+	Executable string `json:"executable"`
+	// The commands contributed by the extension
+	Commands []CommandManifest `json:"commands,omitempty"`
+	// The lifecycle events the extension is notified of
+	Events []string `json:"events,omitempty"`
+}
+
+// CommandManifest describes a command contributed by an extension
+type CommandManifest struct {
+	Name  string `json:"name"`
+	Short string `json:"short,omitempty"`
+}
+
+// HasCommand returns true when the extension contributes the specified command
+func (m *Manifest) HasCommand(name string) bool {
+	for _, command := range m.Commands {
+		if command.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasEvent returns true when the extension subscribes to the specified lifecycle event
+func (m *Manifest) HasEvent(event string) bool {
+	for _, subscribed := range m.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate ensures the manifest is well formed
+func (m *Manifest) Validate() error {
+	if !namePattern.MatchString(m.Name) {
+		return fmt.Errorf(
+			"extension name '%s' is invalid (it should start with a letter and contain only lowercase "+
+				"alphanumeric characters and hyphens)",
+			m.Name,
+		)
+	}
+
+	if m.Version == "" {
+		return fmt.Errorf("extension '%s' is missing a version", m.Name)
+	}
+
+	if m.Executable == "" {
+		return fmt.Errorf("extension '%s' is missing an executable", m.Name)
+	}
+
+	executable := filepath.Clean(m.Executable)
+	if filepath.IsAbs(executable) || executable == ".." || strings.HasPrefix(executable, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("extension '%s' executable must be a path within the extension directory", m.Name)
+	}
+
+	seen := map[string]struct{}{}
+	for _, command := range m.Commands {
+		if !namePattern.MatchString(command.Name) {
+			return fmt.Errorf("extension '%s' command name '%s' is invalid", m.Name, command.Name)
+		}
+
+		if _, has := seen[command.Name]; has {
+			return fmt.Errorf("extension '%s' declares command '%s' more than once", m.Name, command.Name)
+		}
+		seen[command.Name] = struct{}{}
+	}
+
+	for _, event := range m.Events {
+		if _, has := knownEvents[event]; !has {
+			return fmt.Errorf("extension '%s' subscribes to unknown event '%s'", m.Name, event)
+		}
+	}
+
+	return nil
+}
+
+// LoadManifest reads and validates the manifest within the specified extension directory
+func LoadManifest(directory string) (*Manifest, error) {
+	manifestPath := filepath.Join(directory, ManifestFileName)
+
+	manifestJson, err := os.ReadFile(manifestPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no %s found in '%s'", ManifestFileName, directory)
+	} else if err != nil {
+		return nil, fmt.Errorf("reading extension manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(manifestJson, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing extension manifest '%s': %w", manifestPath, err)
+	}
+
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
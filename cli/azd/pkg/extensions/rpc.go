@@ -0,0 +1,193 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package extensions
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// The JSON-RPC protocol version spoken between azd and extensions
+const jsonRpcVersion = "2.0"
+
+// The methods azd invokes on an extension
+const (
+	// Runs a command contributed by the extension, params are CommandParams
+	MethodRunCommand = "command/run"
+	// Notifies the extension of a lifecycle event, params are EventParams
+	MethodRaiseEvent = "event/raise"
+)
+
+// The notifications an extension can send to azd while handling a request
+const (
+	// Writes a message to the azd console, params are MessageParams
+	NotificationMessage = "console/message"
+)
+
+// CommandParams are the params of the command/run request
+type CommandParams struct {
+	Command         string   `json:"command"`
+	Args            []string `json:"args"`
+	ProjectDir      string   `json:"projectDir"`
+	EnvironmentName string   `json:"environmentName,omitempty"`
+}
+
+// EventParams are the params of the event/raise request
+type EventParams struct {
+	Event           string `json:"event"`
+	Command         string `json:"command"`
+	ProjectDir      string `json:"projectDir"`
+	EnvironmentName string `json:"environmentName,omitempty"`
+}
+
+// MessageParams are the params of the console/message notification
+type MessageParams struct {
+	Message string `json:"message"`
+}
+
+type rpcRequest struct {
+	JsonRpc string `json:"jsonrpc"`
+	Id      *int   `json:"id,omitempty"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+// rpcMessage is any message sent by an extension, either a response or a notification
+type rpcMessage struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      *int            `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RpcError       `json:"error,omitempty"`
+}
+
+// RpcError is the error returned by an extension that failed to handle a request
+type RpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RpcError) Error() string {
+	return fmt.Sprintf("%s (code: %d)", e.Message, e.Code)
+}
+
+// encodeRequest serializes a request as a single newline terminated JSON document
+func encodeRequest(id int, method string, params any) ([]byte, error) {
+	requestJson, err := json.Marshal(rpcRequest{
+		JsonRpc: jsonRpcVersion,
+		Id:      &id,
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("serializing %s request: %w", method, err)
+	}
+
+	return append(requestJson, '\n'), nil
+}
+
+// parseMessage parses a single line of extension output.
+// Returns false when the line is not a JSON-RPC message.
+func parseMessage(line string) (*rpcMessage, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "{") {
+		return nil, false
+	}
+
+	var message rpcMessage
+	if err := json.Unmarshal([]byte(line), &message); err != nil || message.JsonRpc != jsonRpcVersion {
+		return nil, false
+	}
+
+	return &message, true
+}
+
+// findResponse finds the response to the request with the specified id within the extension output
+func findResponse(output string, id int) (*rpcMessage, error) {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), 1024*1024)
+
+	for scanner.Scan() {
+		message, ok := parseMessage(scanner.Text())
+		if ok && message.Method == "" && message.Id != nil && *message.Id == id {
+			return message, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading extension output: %w", err)
+	}
+
+	return nil, fmt.Errorf("extension did not respond to request %d", id)
+}
+
+// messageWriter is an io.Writer that receives the extension output as it is written,
+// forwarding console messages and any plain text output to the inner writer.
+type messageWriter struct {
+	mu     sync.Mutex
+	writer io.Writer
+	buffer bytes.Buffer
+}
+
+func newMessageWriter(writer io.Writer) *messageWriter {
+	return &messageWriter{writer: writer}
+}
+
+// Write implements io.Writer
+func (w *messageWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buffer.Write(p)
+
+	for {
+		line, err := w.buffer.ReadString('\n')
+		if err != nil {
+			// Incomplete line, wait for the remainder
+			w.buffer.Reset()
+			w.buffer.WriteString(line)
+			break
+		}
+
+		w.handleLine(line)
+	}
+
+	return len(p), nil
+}
+
+// Flush forwards any incomplete line remaining after the extension exits
+func (w *messageWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buffer.Len() > 0 {
+		w.handleLine(w.buffer.String())
+		w.buffer.Reset()
+	}
+}
+
+func (w *messageWriter) handleLine(line string) {
+	message, ok := parseMessage(line)
+	if !ok {
+		fmt.Fprint(w.writer, line)
+		return
+	}
+
+	if message.Method != NotificationMessage {
+		return
+	}
+
+	var params MessageParams
+	if err := json.Unmarshal(message.Params, &params); err != nil {
+		return
+	}
+
+	fmt.Fprintln(w.writer, params.Message)
+}
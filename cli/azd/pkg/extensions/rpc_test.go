@@ -0,0 +1,43 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package extensions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MessageWriter(t *testing.T) {
+	output := &bytes.Buffer{}
+	writer := newMessageWriter(output)
+
+	// Messages may be split across writes
+	_, err := writer.Write([]byte(`{"jsonrpc":"2.0","method":"console/message",`))
+	require.NoError(t, err)
+	_, err = writer.Write([]byte(`"params":{"message":"first"}}` + "\n" + "plain text\n"))
+	require.NoError(t, err)
+
+	// Responses are not written to the console
+	_, err = writer.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}` + "\n" + "trailing"))
+	require.NoError(t, err)
+	writer.Flush()
+
+	require.Equal(t, "first\nplain text\ntrailing", output.String())
+}
+
+func Test_FindResponse(t *testing.T) {
+	output := "starting\n" +
+		`{"jsonrpc":"2.0","method":"console/message","params":{"message":"working"}}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"result":{}}` + "\n" +
+		`{"jsonrpc":"2.0","id":1,"result":{"ok":true}}` + "\n"
+
+	response, err := findResponse(output, 1)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"ok":true}`, string(response.Result))
+
+	_, err = findResponse("starting\n", 1)
+	require.Error(t, err)
+}
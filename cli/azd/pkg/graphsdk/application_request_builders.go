@@ -93,6 +93,49 @@ func (c *ApplicationItemRequestBuilder) Get(ctx context.Context) (*Application,
 	return httputil.ReadRawResponse[Application](res)
 }
 
+// Updates the notes and tags of the Microsoft Graph Application for the specified application identifier
+func (c *ApplicationItemRequestBuilder) Update(ctx context.Context, update *ApplicationUpdateRequest) error {
+	req, err := runtime.NewRequest(ctx, http.MethodPatch, fmt.Sprintf("%s/applications/%s", c.client.host, c.id))
+	if err != nil {
+		return fmt.Errorf("failed creating request: %w", err)
+	}
+
+	err = SetHttpRequestBody(req, update)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.client.pipeline.Do(req)
+	if err != nil {
+		return httputil.HandleRequestError(res, err)
+	}
+
+	if !runtime.HasStatusCode(res, http.StatusNoContent) {
+		return runtime.NewResponseError(res)
+	}
+
+	return nil
+}
+
+// Deletes the Microsoft Graph Application for the specified application identifier
+func (c *ApplicationItemRequestBuilder) Delete(ctx context.Context) error {
+	req, err := runtime.NewRequest(ctx, http.MethodDelete, fmt.Sprintf("%s/applications/%s", c.client.host, c.id))
+	if err != nil {
+		return fmt.Errorf("failed creating request: %w", err)
+	}
+
+	res, err := c.client.pipeline.Do(req)
+	if err != nil {
+		return httputil.HandleRequestError(res, err)
+	}
+
+	if !runtime.HasStatusCode(res, http.StatusNoContent) {
+		return runtime.NewResponseError(res)
+	}
+
+	return nil
+}
+
 func (c *ApplicationItemRequestBuilder) RemovePassword(ctx context.Context, keyId string) error {
 	req, err := runtime.NewRequest(
 		ctx,
@@ -152,3 +195,15 @@ func (c *ApplicationItemRequestBuilder) AddPassword(ctx context.Context) (*Appli
 
 	return httputil.ReadRawResponse[ApplicationPasswordCredential](res)
 }
+
+// Gets the federated identity credentials of the application
+func (c *ApplicationItemRequestBuilder) FederatedIdentityCredentials() *FederatedIdentityCredentialListRequestBuilder {
+	return NewFederatedIdentityCredentialListRequestBuilder(c.client, c.id)
+}
+
+// Gets the federated identity credential of the application with the specified identifier
+func (c *ApplicationItemRequestBuilder) FederatedIdentityCredentialById(
+	id string,
+) *FederatedIdentityCredentialItemRequestBuilder {
+	return NewFederatedIdentityCredentialItemRequestBuilder(c.client, c.id, id)
+}
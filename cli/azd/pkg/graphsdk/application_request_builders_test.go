@@ -122,6 +122,56 @@ func TestCreateApplication(t *testing.T) {
 	})
 }
 
+func TestUpdateApplication(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterApplicationUpdateMock(mockContext, http.StatusNoContent, "1")
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		err = client.ApplicationById("1").Update(*mockContext.Context, &graphsdk.ApplicationUpdateRequest{
+			Tags: []string{"azd-pipeline"},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterApplicationUpdateMock(mockContext, http.StatusNotFound, "bad-id")
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		err = client.ApplicationById("bad-id").Update(*mockContext.Context, &graphsdk.ApplicationUpdateRequest{})
+		require.Error(t, err)
+	})
+}
+
+func TestDeleteApplication(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterApplicationDeleteMock(mockContext, http.StatusNoContent, "1")
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		err = client.ApplicationById("1").Delete(*mockContext.Context)
+		require.NoError(t, err)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterApplicationDeleteMock(mockContext, http.StatusNotFound, "bad-id")
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		err = client.ApplicationById("bad-id").Delete(*mockContext.Context)
+		require.Error(t, err)
+	})
+}
+
 func TestApplicationAddPassword(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		app := graphsdk.Application{
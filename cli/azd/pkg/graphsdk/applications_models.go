@@ -8,6 +8,8 @@ type Application struct {
 	AppId               *string                          `json:"appId"`
 	DisplayName         string                           `json:"displayName"`
 	Description         *string                          `json:"description"`
+	Notes               *string                          `json:"notes,omitempty"`
+	Tags                []string                         `json:"tags,omitempty"`
 	PasswordCredentials []*ApplicationPasswordCredential `json:"passwordCredentials"`
 }
 
@@ -15,6 +17,12 @@ type ApplicationCreateRequest struct {
 	Application
 }
 
+// The properties of an application updated by a PATCH request, the other properties are left unchanged
+type ApplicationUpdateRequest struct {
+	Notes *string  `json:"notes,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
 // A list of applications returned from the Microsoft Graph.
 type ApplicationListResponse struct {
 	Value []Application `json:"value"`
@@ -41,3 +49,19 @@ type ApplicationRemovePasswordRequest struct {
 type ApplicationAddPasswordResponse struct {
 	ApplicationPasswordCredential
 }
+
+// A federated identity credential of an application, trusting the tokens of an external identity provider, i.e. GitHub
+// Actions, issued to the subject
+type FederatedIdentityCredential struct {
+	Id          *string  `json:"id,omitempty"`
+	Name        string   `json:"name"`
+	Issuer      string   `json:"issuer"`
+	Subject     string   `json:"subject"`
+	Description *string  `json:"description,omitempty"`
+	Audiences   []string `json:"audiences"`
+}
+
+// A list of federated identity credentials returned from the Microsoft Graph.
+type FederatedIdentityCredentialListResponse struct {
+	Value []FederatedIdentityCredential `json:"value"`
+}
@@ -0,0 +1,48 @@
+package graphsdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/sethvargo/go-retry"
+)
+
+// Creates the backoff used while waiting for a new entity to be visible.
+// Replication of new entities across Microsoft Graph usually takes a few seconds and in rare cases up to a minute.
+var NewConsistencyBackoff = func() retry.Backoff {
+	return retry.WithMaxDuration(
+		2*time.Minute,
+		retry.WithCappedDuration(10*time.Second, retry.NewExponential(time.Second)),
+	)
+}
+
+// WaitUntilVisible polls the entity with get until Microsoft Graph stops returning not found.
+// New applications and service principals aren't immediately visible to subsequent Graph and ARM requests, waiting for
+// them to be readable after their creation avoids failures of the requests depending on them.
+func WaitUntilVisible[T any](ctx context.Context, get func(ctx context.Context) (*T, error)) (*T, error) {
+	var entity *T
+
+	err := retry.Do(ctx, NewConsistencyBackoff(), func(ctx context.Context) error {
+		result, err := get(ctx)
+		if err != nil {
+			var responseErr *azcore.ResponseError
+			if errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusNotFound {
+				return retry.RetryableError(err)
+			}
+
+			return err
+		}
+
+		entity = result
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entity, nil
+}
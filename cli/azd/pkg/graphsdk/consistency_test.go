@@ -0,0 +1,99 @@
+package graphsdk_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	graphsdk_mocks "github.com/azure/azure-dev/cli/azd/test/mocks/graphsdk"
+	"github.com/sethvargo/go-retry"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitUntilVisible(t *testing.T) {
+	newConsistencyBackoff := graphsdk.NewConsistencyBackoff
+	t.Cleanup(func() { graphsdk.NewConsistencyBackoff = newConsistencyBackoff })
+	graphsdk.NewConsistencyBackoff = func() retry.Backoff {
+		return retry.WithMaxRetries(3, retry.NewConstant(time.Millisecond))
+	}
+
+	expected := graphsdk.Application{
+		Id:          convert.RefOf("1"),
+		DisplayName: "App 1",
+	}
+
+	// Responds with the status codes in order, then with the application
+	registerApplicationMock := func(mockContext *mocks.MockContext, statusCodes ...int) *int {
+		requests := 0
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && strings.Contains(request.URL.Path, "/applications/1")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			requests++
+			if requests <= len(statusCodes) {
+				return mocks.CreateEmptyHttpResponse(request, statusCodes[requests-1])
+			}
+
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, expected)
+		})
+
+		return &requests
+	}
+
+	t.Run("Visible", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		requests := registerApplicationMock(mockContext)
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		app, err := graphsdk.WaitUntilVisible(*mockContext.Context, client.ApplicationById("1").Get)
+		require.NoError(t, err)
+		require.Equal(t, expected, *app)
+		require.Equal(t, 1, *requests)
+	})
+
+	t.Run("EventuallyVisible", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		requests := registerApplicationMock(mockContext, http.StatusNotFound, http.StatusNotFound)
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		app, err := graphsdk.WaitUntilVisible(*mockContext.Context, client.ApplicationById("1").Get)
+		require.NoError(t, err)
+		require.Equal(t, expected, *app)
+		require.Equal(t, 3, *requests)
+	})
+
+	t.Run("NeverVisible", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		requests := registerApplicationMock(
+			mockContext, http.StatusNotFound, http.StatusNotFound, http.StatusNotFound, http.StatusNotFound)
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		app, err := graphsdk.WaitUntilVisible(*mockContext.Context, client.ApplicationById("1").Get)
+		require.Error(t, err)
+		require.Nil(t, app)
+		require.Equal(t, 4, *requests)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		requests := registerApplicationMock(mockContext, http.StatusUnauthorized)
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		app, err := graphsdk.WaitUntilVisible(*mockContext.Context, client.ApplicationById("1").Get)
+		require.Error(t, err)
+		require.Nil(t, app)
+		require.Equal(t, 1, *requests)
+	})
+}
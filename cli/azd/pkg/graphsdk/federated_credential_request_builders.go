@@ -0,0 +1,155 @@
+package graphsdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+type FederatedIdentityCredentialListRequestBuilder struct {
+	client        *GraphClient
+	applicationId string
+}
+
+func NewFederatedIdentityCredentialListRequestBuilder(
+	client *GraphClient,
+	applicationId string,
+) *FederatedIdentityCredentialListRequestBuilder {
+	return &FederatedIdentityCredentialListRequestBuilder{
+		client:        client,
+		applicationId: applicationId,
+	}
+}
+
+// Gets the federated identity credentials of the application
+func (c *FederatedIdentityCredentialListRequestBuilder) Get(
+	ctx context.Context,
+) (*FederatedIdentityCredentialListResponse, error) {
+	req, err := runtime.NewRequest(ctx, http.MethodGet, c.url())
+	if err != nil {
+		return nil, fmt.Errorf("failed creating request: %w", err)
+	}
+
+	res, err := c.client.pipeline.Do(req)
+	if err != nil {
+		return nil, httputil.HandleRequestError(res, err)
+	}
+
+	if !runtime.HasStatusCode(res, http.StatusOK) {
+		return nil, runtime.NewResponseError(res)
+	}
+
+	return httputil.ReadRawResponse[FederatedIdentityCredentialListResponse](res)
+}
+
+// Adds the federated identity credential to the application
+func (c *FederatedIdentityCredentialListRequestBuilder) Post(
+	ctx context.Context,
+	credential *FederatedIdentityCredential,
+) (*FederatedIdentityCredential, error) {
+	req, err := runtime.NewRequest(ctx, http.MethodPost, c.url())
+	if err != nil {
+		return nil, fmt.Errorf("failed creating request: %w", err)
+	}
+
+	err = SetHttpRequestBody(req, credential)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.client.pipeline.Do(req)
+	if err != nil {
+		return nil, httputil.HandleRequestError(res, err)
+	}
+
+	if !runtime.HasStatusCode(res, http.StatusCreated) {
+		return nil, runtime.NewResponseError(res)
+	}
+
+	return httputil.ReadRawResponse[FederatedIdentityCredential](res)
+}
+
+func (c *FederatedIdentityCredentialListRequestBuilder) url() string {
+	return fmt.Sprintf("%s/applications/%s/federatedIdentityCredentials", c.client.host, c.applicationId)
+}
+
+type FederatedIdentityCredentialItemRequestBuilder struct {
+	client        *GraphClient
+	applicationId string
+	id            string
+}
+
+func NewFederatedIdentityCredentialItemRequestBuilder(
+	client *GraphClient,
+	applicationId string,
+	id string,
+) *FederatedIdentityCredentialItemRequestBuilder {
+	return &FederatedIdentityCredentialItemRequestBuilder{
+		client:        client,
+		applicationId: applicationId,
+		id:            id,
+	}
+}
+
+// Updates the issuer, subject, description and audiences of the federated identity credential, its name can't change
+func (c *FederatedIdentityCredentialItemRequestBuilder) Update(
+	ctx context.Context,
+	credential *FederatedIdentityCredential,
+) error {
+	req, err := runtime.NewRequest(
+		ctx,
+		http.MethodPatch,
+		fmt.Sprintf("%s/applications/%s/federatedIdentityCredentials/%s", c.client.host, c.applicationId, c.id),
+	)
+	if err != nil {
+		return fmt.Errorf("failed creating request: %w", err)
+	}
+
+	// The name and id of the credential are read-only
+	err = SetHttpRequestBody(req, FederatedIdentityCredential{
+		Issuer:      credential.Issuer,
+		Subject:     credential.Subject,
+		Description: credential.Description,
+		Audiences:   credential.Audiences,
+	})
+	if err != nil {
+		return err
+	}
+
+	res, err := c.client.pipeline.Do(req)
+	if err != nil {
+		return httputil.HandleRequestError(res, err)
+	}
+
+	if !runtime.HasStatusCode(res, http.StatusNoContent) {
+		return runtime.NewResponseError(res)
+	}
+
+	return nil
+}
+
+// Deletes the federated identity credential from the application
+func (c *FederatedIdentityCredentialItemRequestBuilder) Delete(ctx context.Context) error {
+	req, err := runtime.NewRequest(
+		ctx,
+		http.MethodDelete,
+		fmt.Sprintf("%s/applications/%s/federatedIdentityCredentials/%s", c.client.host, c.applicationId, c.id),
+	)
+	if err != nil {
+		return fmt.Errorf("failed creating request: %w", err)
+	}
+
+	res, err := c.client.pipeline.Do(req)
+	if err != nil {
+		return httputil.HandleRequestError(res, err)
+	}
+
+	if !runtime.HasStatusCode(res, http.StatusNoContent) {
+		return runtime.NewResponseError(res)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package graphsdk_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	graphsdk_mocks "github.com/azure/azure-dev/cli/azd/test/mocks/graphsdk"
+	"github.com/stretchr/testify/require"
+)
+
+var federatedCredential = graphsdk.FederatedIdentityCredential{
+	Id:        convert.RefOf("credential-1"),
+	Name:      "main",
+	Issuer:    "https://token.actions.githubusercontent.com",
+	Subject:   "repo:owner/repo:ref:refs/heads/main",
+	Audiences: []string{"api://AzureADTokenExchange"},
+}
+
+func TestGetFederatedIdentityCredentialList(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		expected := []graphsdk.FederatedIdentityCredential{federatedCredential}
+
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterFederatedIdentityCredentialListMock(mockContext, http.StatusOK, "app-1", expected)
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		credentials, err := client.ApplicationById("app-1").FederatedIdentityCredentials().Get(*mockContext.Context)
+		require.NoError(t, err)
+		require.NotNil(t, credentials)
+		require.Equal(t, expected, credentials.Value)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterFederatedIdentityCredentialListMock(mockContext, http.StatusNotFound, "bad-id", nil)
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		res, err := client.ApplicationById("bad-id").FederatedIdentityCredentials().Get(*mockContext.Context)
+		require.Error(t, err)
+		require.Nil(t, res)
+	})
+}
+
+func TestCreateFederatedIdentityCredential(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterFederatedIdentityCredentialCreateMock(
+			mockContext, http.StatusCreated, "app-1", &federatedCredential)
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		actual, err := client.ApplicationById("app-1").
+			FederatedIdentityCredentials().
+			Post(*mockContext.Context, &federatedCredential)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		require.Equal(t, federatedCredential, *actual)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterFederatedIdentityCredentialCreateMock(mockContext, http.StatusBadRequest, "app-1", nil)
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		actual, err := client.ApplicationById("app-1").
+			FederatedIdentityCredentials().
+			Post(*mockContext.Context, &federatedCredential)
+		require.Error(t, err)
+		require.Nil(t, actual)
+	})
+}
+
+func TestUpdateFederatedIdentityCredential(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterFederatedIdentityCredentialUpdateMock(
+			mockContext, http.StatusNoContent, "app-1", *federatedCredential.Id)
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		err = client.ApplicationById("app-1").
+			FederatedIdentityCredentialById(*federatedCredential.Id).
+			Update(*mockContext.Context, &federatedCredential)
+		require.NoError(t, err)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterFederatedIdentityCredentialUpdateMock(
+			mockContext, http.StatusNotFound, "app-1", *federatedCredential.Id)
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		err = client.ApplicationById("app-1").
+			FederatedIdentityCredentialById(*federatedCredential.Id).
+			Update(*mockContext.Context, &federatedCredential)
+		require.Error(t, err)
+	})
+}
+
+func TestDeleteFederatedIdentityCredential(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterFederatedIdentityCredentialDeleteMock(
+			mockContext, http.StatusNoContent, "app-1", *federatedCredential.Id)
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		err = client.ApplicationById("app-1").
+			FederatedIdentityCredentialById(*federatedCredential.Id).
+			Delete(*mockContext.Context)
+		require.NoError(t, err)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterFederatedIdentityCredentialDeleteMock(
+			mockContext, http.StatusNotFound, "app-1", *federatedCredential.Id)
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		err = client.ApplicationById("app-1").
+			FederatedIdentityCredentialById(*federatedCredential.Id).
+			Delete(*mockContext.Context)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,75 @@
+package graphsdk_test
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/identity"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	graphsdk_mocks "github.com/azure/azure-dev/cli/azd/test/mocks/graphsdk"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	servicePrincipals := []graphsdk.ServicePrincipal{
+		{
+			Id:          convert.RefOf("1"),
+			AppId:       "app-1",
+			DisplayName: "SPN 1",
+		},
+	}
+
+	credential := graphsdk.ApplicationPasswordCredential{
+		KeyId:      convert.RefOf("key1"),
+		SecretText: convert.RefOf("super-secret"),
+	}
+
+	recordingPath := filepath.Join(t.TempDir(), "recording.json")
+
+	// Record traffic against a mocked "live" endpoint
+	liveContext := mocks.NewMockContext(context.Background())
+	graphsdk_mocks.RegisterServicePrincipalListMock(liveContext, http.StatusOK, servicePrincipals)
+	graphsdk_mocks.RegisterApplicationAddPasswordMock(liveContext, http.StatusOK, "app-1", &credential)
+
+	recorder := graphsdk_mocks.NewRecorder(liveContext.HttpClient)
+	recordingClient, err := graphsdk.NewGraphClient(
+		identity.GetCredentials(*liveContext.Context),
+		azsdk.NewClientOptionsBuilder().WithTransport(recorder).BuildCoreClientOptions(),
+	)
+	require.NoError(t, err)
+
+	_, err = recordingClient.ServicePrincipals().Get(*liveContext.Context)
+	require.NoError(t, err)
+
+	recordedCredential, err := recordingClient.ApplicationById("app-1").AddPassword(*liveContext.Context)
+	require.NoError(t, err)
+	// The caller still receives the original unsanitized response
+	require.Equal(t, *credential.SecretText, *recordedCredential.SecretText)
+
+	require.Len(t, recorder.Recording().Interactions, 2)
+	require.NoError(t, recorder.Save(recordingPath))
+
+	// Replay the saved traffic in a new mock context
+	recording, err := graphsdk_mocks.LoadRecording(recordingPath)
+	require.NoError(t, err)
+
+	replayContext := mocks.NewMockContext(context.Background())
+	graphsdk_mocks.RegisterRecording(replayContext, recording)
+
+	client, err := graphsdk_mocks.CreateGraphClient(replayContext)
+	require.NoError(t, err)
+
+	actualList, err := client.ServicePrincipals().Get(*replayContext.Context)
+	require.NoError(t, err)
+	require.Equal(t, servicePrincipals, actualList.Value)
+
+	actualCredential, err := client.ApplicationById("app-1").AddPassword(*replayContext.Context)
+	require.NoError(t, err)
+	require.Equal(t, *credential.KeyId, *actualCredential.KeyId)
+	require.Equal(t, graphsdk_mocks.SanitizedValue, *actualCredential.SecretText)
+}
@@ -2,13 +2,14 @@ package graphsdk
 
 // A Microsoft Graph Service Principal entity.
 type ServicePrincipal struct {
-	Id                     *string `json:"id"`
-	DisplayName            string  `json:"displayName"`
-	AppId                  string  `json:"appId"`
-	AppOwnerOrganizationId *string `json:"appOwnerOrganizationId"`
-	AppDisplayName         *string `json:"appDisplayName"`
-	Description            *string `json:"appDescription"`
-	Type                   *string `json:"servicePrincipalType"`
+	Id                     *string                          `json:"id"`
+	DisplayName            string                           `json:"displayName"`
+	AppId                  string                           `json:"appId"`
+	AppOwnerOrganizationId *string                          `json:"appOwnerOrganizationId"`
+	AppDisplayName         *string                          `json:"appDisplayName"`
+	Description            *string                          `json:"appDescription"`
+	Type                   *string                          `json:"servicePrincipalType"`
+	PasswordCredentials    []*ApplicationPasswordCredential `json:"passwordCredentials,omitempty"`
 }
 
 type ServicePrincipalCreateRequest struct {
@@ -20,6 +21,14 @@ type ServicePrincipalListResponse struct {
 	Value []ServicePrincipal `json:"value"`
 }
 
+type ServicePrincipalAddPasswordRequest struct {
+	PasswordCredential ApplicationPasswordCredential `json:"passwordCredential"`
+}
+
+type ServicePrincipalRemovePasswordRequest struct {
+	KeyId string `json:"keyId"`
+}
+
 type ServicePrincipalListRequestBuilder struct {
 	*EntityListRequestBuilder[ServicePrincipalListRequestBuilder]
 }
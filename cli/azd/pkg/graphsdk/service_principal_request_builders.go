@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 )
 
@@ -94,3 +95,69 @@ func (b *ServicePrincipalItemRequestBuilder) Get(ctx context.Context) (*ServiceP
 
 	return httputil.ReadRawResponse[ServicePrincipal](res)
 }
+
+// Adds a new password credential to the Microsoft Graph Service Principal
+func (b *ServicePrincipalItemRequestBuilder) AddPassword(ctx context.Context) (*ApplicationPasswordCredential, error) {
+	req, err := runtime.NewRequest(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("%s/servicePrincipals/%s/addPassword", b.client.host, b.id),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating request: %w", err)
+	}
+
+	addPasswordRequest := ServicePrincipalAddPasswordRequest{
+		PasswordCredential: ApplicationPasswordCredential{
+			DisplayName: convert.RefOf("Azure Developer CLI"),
+		},
+	}
+
+	err = SetHttpRequestBody(req, addPasswordRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := b.client.pipeline.Do(req)
+	if err != nil {
+		return nil, httputil.HandleRequestError(res, err)
+	}
+
+	if !runtime.HasStatusCode(res, http.StatusOK) {
+		return nil, runtime.NewResponseError(res)
+	}
+
+	return httputil.ReadRawResponse[ApplicationPasswordCredential](res)
+}
+
+// Removes the password credential with the specified key identifier from the Microsoft Graph Service Principal
+func (b *ServicePrincipalItemRequestBuilder) RemovePassword(ctx context.Context, keyId string) error {
+	req, err := runtime.NewRequest(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("%s/servicePrincipals/%s/removePassword", b.client.host, b.id),
+	)
+	if err != nil {
+		return fmt.Errorf("failed creating request: %w", err)
+	}
+
+	removePasswordRequest := ServicePrincipalRemovePasswordRequest{
+		KeyId: keyId,
+	}
+
+	err = SetHttpRequestBody(req, removePasswordRequest)
+	if err != nil {
+		return err
+	}
+
+	res, err := b.client.pipeline.Do(req)
+	if err != nil {
+		return httputil.HandleRequestError(res, err)
+	}
+
+	if !runtime.HasStatusCode(res, http.StatusNoContent) {
+		return runtime.NewResponseError(res)
+	}
+
+	return nil
+}
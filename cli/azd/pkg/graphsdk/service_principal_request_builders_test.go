@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
@@ -119,3 +120,66 @@ func TestCreateServicePrincipal(t *testing.T) {
 		require.Nil(t, res)
 	})
 }
+
+func TestServicePrincipalAddPassword(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		endDate := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+		mockCredential := graphsdk.ApplicationPasswordCredential{
+			KeyId:       convert.RefOf("key1"),
+			DisplayName: convert.RefOf("Name"),
+			SecretText:  convert.RefOf("foobar"),
+			Hint:        convert.RefOf("foo"),
+			EndDateTime: &endDate,
+		}
+
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterServicePrincipalAddPasswordMock(mockContext, http.StatusOK, "spn-1", &mockCredential)
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		actual, err := client.ServicePrincipalById("spn-1").AddPassword(*mockContext.Context)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		require.Equal(t, *mockCredential.KeyId, *actual.KeyId)
+		require.Equal(t, *mockCredential.SecretText, *actual.SecretText)
+		require.Equal(t, *mockCredential.Hint, *actual.Hint)
+		require.True(t, endDate.Equal(*actual.EndDateTime))
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterServicePrincipalAddPasswordMock(mockContext, http.StatusNotFound, "bad-spn-id", nil)
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		actual, err := client.ServicePrincipalById("bad-spn-id").AddPassword(*mockContext.Context)
+		require.Error(t, err)
+		require.Nil(t, actual)
+	})
+}
+
+func TestServicePrincipalRemovePassword(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterServicePrincipalRemovePasswordMock(mockContext, http.StatusNoContent, "spn-1")
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		err = client.ServicePrincipalById("spn-1").RemovePassword(*mockContext.Context, "key1")
+		require.NoError(t, err)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterServicePrincipalRemovePasswordMock(mockContext, http.StatusNotFound, "spn-1")
+
+		client, err := graphsdk_mocks.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		err = client.ServicePrincipalById("spn-1").RemovePassword(*mockContext.Context, "bad-key-id")
+		require.Error(t, err)
+	})
+}
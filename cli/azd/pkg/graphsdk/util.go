@@ -10,6 +10,8 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/events"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
 )
 
 // Creates a new Azure HTTP pipeline used for Graph SDK clients
@@ -24,6 +26,7 @@ func NewPipeline(
 
 	authPolicy := runtime.NewBearerTokenPolicy(credential, scopes, nil)
 	pipelineOptions := runtime.PipelineOptions{
+		PerCall:  []policy.Policy{azsdk.NewTracingPolicy(events.GraphRequestEventName)},
 		PerRetry: []policy.Policy{authPolicy},
 	}
 
@@ -0,0 +1,190 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package httputil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+)
+
+// The outbound clients of azd whose policy is configurable
+const (
+	// The clients of Azure Resource Manager and of the Azure data plane services
+	ArmClient = "arm"
+	// The client of Microsoft Graph
+	GraphClient = "graph"
+	// The client of Azure DevOps
+	AzdoClient = "azdo"
+	// The GitHub CLI, which sends the requests to GitHub
+	GitHubClient = "github"
+)
+
+// ClientPolicy is the timeout and retry policy of an outbound client, as configured by the user
+type ClientPolicy struct {
+	// The time allowed for each attempt of a request, zero when not configured
+	Timeout time.Duration
+	// The number of times a failed request is retried, nil when not configured. Only the clients of the Azure SDK,
+	// arm and graph, retry requests.
+	MaxRetries *int
+}
+
+// ClientPolicies are the policies of the outbound clients configured by the user, by client. The policy of a client
+// that isn't configured is the zero policy.
+type ClientPolicies map[string]ClientPolicy
+
+// retryingClients are the clients retrying their failed requests, the clients of the Azure SDK
+var retryingClients = map[string]bool{ArmClient: true, GraphClient: true}
+
+// NewClientPolicies reads the policies of all the clients from the user config
+func NewClientPolicies(userConfig config.Config) (ClientPolicies, error) {
+	policies := ClientPolicies{}
+
+	for _, client := range []string{ArmClient, GraphClient, AzdoClient, GitHubClient} {
+		policy, err := clientPolicyFromConfig(userConfig, client)
+		if err != nil {
+			return nil, err
+		}
+
+		policies[client] = policy
+	}
+
+	return policies, nil
+}
+
+// LoadClientPolicies loads the policies of the clients from the user config once, for all the clients created by a
+// command. The client defaults are kept when the config can't be loaded.
+func LoadClientPolicies(configManager config.Manager) ClientPolicies {
+	configFilePath, err := config.GetUserConfigFilePath()
+	if err != nil {
+		log.Printf("loading the client policies: %v", err)
+		return ClientPolicies{}
+	}
+
+	userConfig, err := configManager.Load(configFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return ClientPolicies{}
+	} else if err != nil {
+		log.Printf("loading the client policies: %v", err)
+		return ClientPolicies{}
+	}
+
+	policies, err := NewClientPolicies(userConfig)
+	if err != nil {
+		log.Printf("loading the client policies: %v", err)
+		return ClientPolicies{}
+	}
+
+	return policies
+}
+
+const clientPoliciesContextKey contextKey = "clientpolicies"
+
+// WithClientPolicies sets the policies of the clients created with the context
+func WithClientPolicies(ctx context.Context, policies ClientPolicies) context.Context {
+	return context.WithValue(ctx, clientPoliciesContextKey, policies)
+}
+
+// GetClientPolicy returns the policy of client set in the context, the zero policy when none is set
+func GetClientPolicy(ctx context.Context, client string) ClientPolicy {
+	policies, _ := ctx.Value(clientPoliciesContextKey).(ClientPolicies)
+	return policies[client]
+}
+
+// clientPolicyFromConfig reads the policy of client from http.timeout and http.maxRetries, which apply to all clients,
+// overridden by http.clients.<client>.timeout and http.clients.<client>.maxRetries. The maxRetries of a client that
+// doesn't retry its requests is rejected, http.maxRetries doesn't apply to it.
+func clientPolicyFromConfig(userConfig config.Config, client string) (ClientPolicy, error) {
+	policy := ClientPolicy{}
+	clientPrefix := fmt.Sprintf("http.clients.%s", client)
+
+	for _, prefix := range []string{"http", clientPrefix} {
+		if value, has := userConfig.Get(prefix + ".timeout"); has {
+			timeout, err := parseTimeout(value)
+			if err != nil {
+				return ClientPolicy{}, fmt.Errorf("invalid %s.timeout: %w", prefix, err)
+			}
+
+			policy.Timeout = timeout
+		}
+
+		value, has := userConfig.Get(prefix + ".maxRetries")
+		if !has {
+			continue
+		}
+
+		if !retryingClients[client] {
+			if prefix == clientPrefix {
+				return ClientPolicy{}, fmt.Errorf(
+					"%s.maxRetries isn't supported, only the arm and graph clients retry their requests", prefix)
+			}
+
+			continue
+		}
+
+		maxRetries, err := parseMaxRetries(value)
+		if err != nil {
+			return ClientPolicy{}, fmt.Errorf("invalid %s.maxRetries: %w", prefix, err)
+		}
+
+		policy.MaxRetries = &maxRetries
+	}
+
+	return policy, nil
+}
+
+// parseTimeout parses a duration, i.e. "90s" or "2m", or a number of seconds
+func parseTimeout(value any) (time.Duration, error) {
+	var timeout time.Duration
+
+	switch v := value.(type) {
+	case float64:
+		timeout = time.Duration(v * float64(time.Second))
+	case string:
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+			timeout = time.Duration(seconds * float64(time.Second))
+		} else if timeout, err = time.ParseDuration(v); err != nil {
+			return 0, fmt.Errorf("'%s' isn't a duration, i.e. 90s or 2m", v)
+		}
+	default:
+		return 0, fmt.Errorf("'%v' isn't a duration, i.e. 90s or 2m", v)
+	}
+
+	if timeout <= 0 {
+		return 0, fmt.Errorf("'%v' isn't a positive duration", value)
+	}
+
+	return timeout, nil
+}
+
+func parseMaxRetries(value any) (int, error) {
+	var maxRetries int
+
+	switch v := value.(type) {
+	case float64:
+		maxRetries = int(v)
+		if float64(maxRetries) != v {
+			return 0, fmt.Errorf("'%v' isn't a whole number", v)
+		}
+	case string:
+		var err error
+		if maxRetries, err = strconv.Atoi(v); err != nil {
+			return 0, fmt.Errorf("'%s' isn't a whole number", v)
+		}
+	default:
+		return 0, fmt.Errorf("'%v' isn't a whole number", v)
+	}
+
+	if maxRetries < 0 {
+		return 0, fmt.Errorf("'%v' is negative", value)
+	}
+
+	return maxRetries, nil
+}
@@ -0,0 +1,100 @@
+package httputil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientPolicyFromConfig(t *testing.T) {
+	three := 3
+	zero := 0
+
+	tests := []struct {
+		name     string
+		config   map[string]any
+		expected ClientPolicy
+	}{
+		{"NotConfigured", map[string]any{}, ClientPolicy{}},
+		{
+			"Global",
+			map[string]any{"http": map[string]any{"timeout": "2m", "maxRetries": "3"}},
+			ClientPolicy{Timeout: 2 * time.Minute, MaxRetries: &three},
+		},
+		{
+			"Seconds",
+			map[string]any{"http": map[string]any{"timeout": "90", "maxRetries": float64(0)}},
+			ClientPolicy{Timeout: 90 * time.Second, MaxRetries: &zero},
+		},
+		{
+			"ClientOverridesGlobal",
+			map[string]any{"http": map[string]any{
+				"timeout":    "30s",
+				"maxRetries": "0",
+				"clients": map[string]any{
+					"arm":  map[string]any{"timeout": float64(120), "maxRetries": "3"},
+					"azdo": map[string]any{"timeout": "10s"},
+				},
+			}},
+			ClientPolicy{Timeout: 2 * time.Minute, MaxRetries: &three},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy, err := clientPolicyFromConfig(config.NewConfig(test.config), ArmClient)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, policy)
+		})
+	}
+
+	t.Run("ClientWithoutRetries", func(t *testing.T) {
+		userConfig := config.NewConfig(map[string]any{"http": map[string]any{"timeout": "30s", "maxRetries": "3"}})
+
+		// http.maxRetries only applies to the clients retrying their requests
+		policy, err := clientPolicyFromConfig(userConfig, AzdoClient)
+		require.NoError(t, err)
+		require.Equal(t, ClientPolicy{Timeout: 30 * time.Second}, policy)
+
+		for _, client := range []string{AzdoClient, GitHubClient} {
+			userConfig := config.NewConfig(map[string]any{"http": map[string]any{
+				"clients": map[string]any{client: map[string]any{"maxRetries": "3"}},
+			}})
+
+			_, err := clientPolicyFromConfig(userConfig, client)
+			require.ErrorContains(t, err, "maxRetries isn't supported")
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, http := range []map[string]any{
+			{"timeout": "soon"},
+			{"timeout": "-5s"},
+			{"maxRetries": "many"},
+			{"maxRetries": float64(1.5)},
+			{"maxRetries": "-1"},
+		} {
+			_, err := clientPolicyFromConfig(config.NewConfig(map[string]any{"http": http}), ArmClient)
+			require.Error(t, err, http)
+		}
+	})
+}
+
+func TestNewClientPolicies(t *testing.T) {
+	userConfig := config.NewConfig(map[string]any{"http": map[string]any{
+		"timeout": "30s",
+		"clients": map[string]any{"github": map[string]any{"timeout": "2m"}},
+	}})
+
+	policies, err := NewClientPolicies(userConfig)
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Second, policies[ArmClient].Timeout)
+	require.Equal(t, 2*time.Minute, policies[GitHubClient].Timeout)
+
+	ctx := WithClientPolicies(context.Background(), policies)
+	require.Equal(t, 30*time.Second, GetClientPolicy(ctx, AzdoClient).Timeout)
+	require.Equal(t, ClientPolicy{}, GetClientPolicy(context.Background(), AzdoClient))
+}
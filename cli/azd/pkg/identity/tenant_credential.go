@@ -0,0 +1,106 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// TenantCredential is an Azure CLI credential requesting its tokens from the tenant selected with SetTenant, or from
+// the default tenant of the Azure CLI when no tenant is selected.
+type TenantCredential struct {
+	mu          sync.Mutex
+	tenantId    string
+	credentials map[string]azcore.TokenCredential
+	// newCredential creates the credential of a tenant, the empty tenant being the default tenant
+	newCredential func(tenantId string) (azcore.TokenCredential, error)
+}
+
+var (
+	defaultCredential     *TenantCredential
+	defaultCredentialOnce sync.Once
+)
+
+// DefaultCredential returns the credential shared by the ARM and Graph clients of the command, so selecting a tenant
+// applies to all of them.
+func DefaultCredential() *TenantCredential {
+	defaultCredentialOnce.Do(func() {
+		defaultCredential = NewTenantCredential(func(tenantId string) (azcore.TokenCredential, error) {
+			return azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{TenantID: tenantId})
+		})
+	})
+
+	return defaultCredential
+}
+
+// Creates a new TenantCredential creating the credential of each tenant with newCredential
+func NewTenantCredential(newCredential func(tenantId string) (azcore.TokenCredential, error)) *TenantCredential {
+	return &TenantCredential{
+		credentials:   map[string]azcore.TokenCredential{},
+		newCredential: newCredential,
+	}
+}
+
+// SetTenant selects the tenant the tokens are requested from, the empty tenant selecting the default tenant
+func (c *TenantCredential) SetTenant(tenantId string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tenantId = tenantId
+}
+
+// TenantId returns the selected tenant, or an empty string when the default tenant is used
+func (c *TenantCredential) TenantId() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.tenantId
+}
+
+// GetToken requests a token from the selected tenant
+func (c *TenantCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	credential, err := c.tenantCredential()
+	if err != nil {
+		return azcore.AccessToken{}, err
+	}
+
+	return credential.GetToken(ctx, options)
+}
+
+func (c *TenantCredential) tenantCredential() (azcore.TokenCredential, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if credential, has := c.credentials[c.tenantId]; has {
+		return credential, nil
+	}
+
+	credential, err := c.newCredential(c.tenantId)
+	if err != nil {
+		return nil, fmt.Errorf("creating credential for tenant '%s': %w", c.tenantId, err)
+	}
+
+	c.credentials[c.tenantId] = credential
+	return credential, nil
+}
+
+// SetTenant selects the tenant of the credentials of the context, when they support switching tenants
+func SetTenant(ctx context.Context, tenantId string) {
+	if credential, ok := ctx.Value(credentialsContextKey).(*TenantCredential); ok {
+		credential.SetTenant(tenantId)
+	}
+}
+
+// GetTenant returns the tenant selected for the credentials of the context, or an empty string when the default
+// tenant is used
+func GetTenant(ctx context.Context) string {
+	if credential, ok := ctx.Value(credentialsContextKey).(*TenantCredential); ok {
+		return credential.TenantId()
+	}
+
+	return ""
+}
@@ -0,0 +1,48 @@
+package identity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantTokenCredential struct {
+	tenantId string
+}
+
+func (c *tenantTokenCredential) GetToken(
+	ctx context.Context,
+	options policy.TokenRequestOptions,
+) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token-" + c.tenantId}, nil
+}
+
+func TestTenantCredential(t *testing.T) {
+	created := []string{}
+	credential := NewTenantCredential(func(tenantId string) (azcore.TokenCredential, error) {
+		created = append(created, tenantId)
+		return &tenantTokenCredential{tenantId: tenantId}, nil
+	})
+	ctx := WithCredentials(context.Background(), credential)
+
+	token, err := credential.GetToken(ctx, policy.TokenRequestOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "token-", token.Token)
+	require.Equal(t, "", GetTenant(ctx))
+
+	SetTenant(ctx, "TENANT_ID")
+	require.Equal(t, "TENANT_ID", GetTenant(ctx))
+
+	token, err = credential.GetToken(ctx, policy.TokenRequestOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "token-TENANT_ID", token.Token)
+
+	// The credential of each tenant is only created once
+	SetTenant(ctx, "")
+	_, err = credential.GetToken(ctx, policy.TokenRequestOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"", "TENANT_ID"}, created)
+}
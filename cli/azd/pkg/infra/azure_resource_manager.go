@@ -7,14 +7,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
 	"github.com/azure/azure-dev/cli/azd/pkg/azureutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 )
 
+// EnvironmentExpiresOnTag is the tag of the resource groups of an environment with a TTL, its value is the time, in
+// RFC 3339 format, the environment expires on. The cleanup pipeline deletes the resource groups once it's past.
+const EnvironmentExpiresOnTag = "azd-env-expires-on"
+
 type AzureResourceManager struct {
 	azCli azcli.AzCli
 }
@@ -122,6 +128,137 @@ func (rm *AzureResourceManager) GetResourceGroupsForDeployment(
 	return keys, nil
 }
 
+// GetEnvironmentResourceGroups returns the sorted names of the resource groups of an environment: the resource groups
+// of the subscription level deployment of the environment, and the resource groups tagged with the name of the
+// environment. The deployment is found by the deployment name of the environment, resolve it first when the name may not
+// be stored, see provisioning.ResolveDeploymentName.
+func (rm *AzureResourceManager) GetEnvironmentResourceGroups(
+	ctx context.Context,
+	env *environment.Environment,
+) ([]string, error) {
+	resourceGroups := map[string]struct{}{}
+
+	deploymentGroups, err := rm.GetResourceGroupsForDeployment(ctx, env.GetSubscriptionId(), env.GetDeploymentName())
+	if err != nil && !errors.Is(err, azcli.ErrDeploymentNotFound) {
+		return nil, err
+	}
+
+	for _, name := range deploymentGroups {
+		resourceGroups[name] = struct{}{}
+	}
+
+	taggedGroups, err := rm.GetResourceGroupsForEnvironment(ctx, env)
+	var notFoundError *azureutil.ResourceNotFoundError
+	if err != nil && !errors.As(err, &notFoundError) {
+		return nil, fmt.Errorf("getting resource groups for environment: %s: %w", env.GetEnvName(), err)
+	}
+
+	for _, group := range taggedGroups {
+		resourceGroups[group.Name] = struct{}{}
+	}
+
+	names := make([]string, 0, len(resourceGroups))
+	for name := range resourceGroups {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// TagEnvironmentExpiry tags the resource groups of an environment with the time the environment expires on, or removes
+// the tag when the environment doesn't expire. Returns the names of the tagged resource groups.
+func (rm *AzureResourceManager) TagEnvironmentExpiry(
+	ctx context.Context,
+	env *environment.Environment,
+) ([]string, error) {
+	resourceGroups, err := rm.GetEnvironmentResourceGroups(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+
+	var value *string
+	if _, ok := env.GetExpiresOn(); ok {
+		expiresOn := env.Values[environment.ExpiresOnEnvVarName]
+		value = &expiresOn
+	}
+
+	for _, resourceGroup := range resourceGroups {
+		err := rm.azCli.UpdateResourceGroupTags(
+			ctx, env.GetSubscriptionId(), resourceGroup, map[string]*string{EnvironmentExpiresOnTag: value})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resourceGroups, nil
+}
+
+// GetEnvironmentResources returns the resources of the resource groups of an environment, keyed by the name of their
+// resource group.
+func (rm *AzureResourceManager) GetEnvironmentResources(
+	ctx context.Context,
+	env *environment.Environment,
+) (map[string][]azcli.AzCliResource, error) {
+	resourceGroups, err := rm.GetEnvironmentResourceGroups(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := map[string][]azcli.AzCliResource{}
+	for _, resourceGroup := range resourceGroups {
+		groupResources, err := rm.azCli.ListResourceGroupResources(ctx, env.GetSubscriptionId(), resourceGroup, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing resources of resource group '%s': %w", resourceGroup, err)
+		}
+
+		resources[resourceGroup] = groupResources
+	}
+
+	return resources, nil
+}
+
+// GetEnvironmentLocks returns the management locks applying to the resources of an environment: the locks of the
+// subscription, and the locks of the resource groups of the environment and of their resources, sorted by id.
+func (rm *AzureResourceManager) GetEnvironmentLocks(
+	ctx context.Context,
+	env *environment.Environment,
+) ([]azsdk.ManagementLock, error) {
+	resourceGroups, err := rm.GetEnvironmentResourceGroups(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+
+	locks, err := rm.azCli.ListManagementLocks(ctx, env.GetSubscriptionId())
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptionScope := fmt.Sprintf("/subscriptions/%s", env.GetSubscriptionId())
+	environmentLocks := []azsdk.ManagementLock{}
+	for _, lock := range locks {
+		scope := strings.ToLower(lock.Scope())
+		applies := scope == strings.ToLower(subscriptionScope)
+
+		for _, resourceGroup := range resourceGroups {
+			groupScope := strings.ToLower(fmt.Sprintf("%s/resourceGroups/%s", subscriptionScope, resourceGroup))
+			if scope == groupScope || strings.HasPrefix(scope, groupScope+"/") {
+				applies = true
+			}
+		}
+
+		if applies {
+			environmentLocks = append(environmentLocks, lock)
+		}
+	}
+
+	sort.Slice(environmentLocks, func(i, j int) bool {
+		return environmentLocks[i].Id < environmentLocks[j].Id
+	})
+
+	return environmentLocks, nil
+}
+
 // GetResourceGroupsForEnvironment gets all resources groups for a given environment
 func (rm *AzureResourceManager) GetResourceGroupsForEnvironment(
 	ctx context.Context,
@@ -434,3 +434,102 @@ func TestFindResourceGroupForEnvironment(t *testing.T) {
 		})
 	}
 }
+
+func TestGetEnvironmentResources(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	env := environment.EphemeralWithValues("test-env", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+	})
+
+	// The subscription deployment depends on rg-deployed
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet &&
+			strings.HasSuffix(request.URL.Path, "/providers/Microsoft.Resources/deployments/test-env")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armresources.DeploymentExtended{
+			Properties: &armresources.DeploymentPropertiesExtended{
+				Dependencies: []*armresources.Dependency{
+					{
+						DependsOn: []*armresources.BasicDependency{
+							{
+								ResourceName: convert.RefOf("rg-deployed"),
+								ResourceType: convert.RefOf(string(AzureResourceTypeResourceGroup)),
+							},
+						},
+					},
+				},
+			},
+		})
+	})
+
+	// rg-deployed and rg-tagged are tagged with the environment name
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/resourcegroups")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		groups := []*armresources.ResourceGroup{}
+		for _, name := range []string{"rg-tagged", "rg-deployed"} {
+			groups = append(groups, &armresources.ResourceGroup{
+				ID:       convert.RefOf(name),
+				Name:     convert.RefOf(name),
+				Type:     convert.RefOf(string(AzureResourceTypeResourceGroup)),
+				Location: convert.RefOf("eastus2"),
+			})
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armresources.ResourceGroupListResult{
+			Value: groups,
+		})
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/resources")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		name := "app-" + strings.Split(request.URL.Path, "/")[4]
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armresources.ResourceListResult{
+			Value: []*armresources.GenericResourceExpanded{
+				{
+					ID:       convert.RefOf(name),
+					Name:     convert.RefOf(name),
+					Type:     convert.RefOf(string(AzureResourceTypeWebSite)),
+					Location: convert.RefOf("eastus2"),
+				},
+			},
+		})
+	})
+
+	arm := NewAzureResourceManager(*mockContext.Context)
+
+	groups, err := arm.GetEnvironmentResourceGroups(*mockContext.Context, env)
+	require.NoError(t, err)
+	require.Equal(t, []string{"rg-deployed", "rg-tagged"}, groups)
+
+	resources, err := arm.GetEnvironmentResources(*mockContext.Context, env)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+	require.Equal(t, "app-rg-deployed", resources["rg-deployed"][0].Name)
+	require.Equal(t, "app-rg-tagged", resources["rg-tagged"][0].Name)
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet &&
+			strings.HasSuffix(request.URL.Path, "/providers/Microsoft.Authorization/locks")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		locks := []map[string]any{}
+		for _, id := range []string{
+			"/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-tagged-other/providers/Microsoft.Authorization/locks/other",
+			"/subscriptions/SUBSCRIPTION_ID/resourceGroups/RG-DEPLOYED/providers/Microsoft.Web/sites/app-rg-deployed" +
+				"/providers/Microsoft.Authorization/locks/app",
+			"/subscriptions/SUBSCRIPTION_ID/providers/Microsoft.Authorization/locks/subscription",
+		} {
+			locks = append(locks, map[string]any{"id": id, "properties": map[string]any{"level": "CanNotDelete"}})
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{"value": locks})
+	})
+
+	locks, err := arm.GetEnvironmentLocks(*mockContext.Context, env)
+	require.NoError(t, err)
+	require.Len(t, locks, 2)
+	require.Equal(t, "/subscriptions/SUBSCRIPTION_ID", locks[0].Scope())
+	require.Equal(t, "/subscriptions/SUBSCRIPTION_ID/resourceGroups/RG-DEPLOYED/providers/Microsoft.Web/sites/app-rg-deployed",
+		locks[1].Scope())
+}
@@ -20,11 +20,14 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/cmdsubst"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
@@ -40,6 +43,10 @@ import (
 	"github.com/sethvargo/go-retry"
 )
 
+// The number of resource groups deleted at the same time by Destroy, the deletion of a resource group takes minutes
+// and is mostly spent waiting on Azure
+const resourceGroupDeleteConcurrency = 4
+
 type BicepTemplate struct {
 	Schema         string                          `json:"$schema"`
 	ContentVersion string                          `json:"contentVersion"`
@@ -157,12 +164,20 @@ func (p *BicepProvider) Plan(
 				}
 			}
 
+			if err := p.applyParameterOverrides(deployment); err != nil {
+				asyncContext.SetError(err)
+				return
+			}
+
 			updated, err := p.ensureParameters(ctx, deployment)
 			if err != nil {
 				asyncContext.SetError(err)
 				return
 			}
 
+			// The overrides are only written to the parameters file of the deployment
+			updated = updated || len(p.options.Parameters) > 0
+
 			if updated {
 				if err := p.updateParametersFile(ctx, deployment, parameterFilePath); err != nil {
 					asyncContext.SetError(fmt.Errorf("updating deployment parameters: %w", err))
@@ -198,33 +213,7 @@ func (p *BicepProvider) Deploy(
 			}()
 
 			// Report incremental progress
-			go func() {
-				resourceManager := infra.NewAzureResourceManager(ctx)
-				progressDisplay := NewProvisioningProgressDisplay(resourceManager, p.console, scope)
-				// Make initial delay shorter to be more responsive in displaying initial progress
-				initialDelay := 3 * time.Second
-				regularDelay := 10 * time.Second
-				timer := time.NewTimer(initialDelay)
-
-				for {
-					select {
-					case <-done:
-						timer.Stop()
-						return
-					case <-timer.C:
-						progressReport, err := progressDisplay.ReportProgress(ctx)
-						if err != nil {
-							// We don't want to fail the whole deployment if a progress reporting error occurs
-							log.Printf("error while reporting progress: %s", err.Error())
-							continue
-						}
-
-						asyncContext.SetProgress(progressReport)
-
-						timer.Reset(regularDelay)
-					}
-				}
-			}()
+			go p.reportDeployProgress(ctx, asyncContext, scope, done)
 
 			// Start the deployment
 			bicepDeploymentData := pd.Details.(BicepDeploymentDetails)
@@ -250,6 +239,206 @@ func (p *BicepProvider) Deploy(
 		})
 }
 
+// The interval the state of a deployment in progress is checked at while attached to it
+var attachPollInterval = 10 * time.Second
+
+// Attach waits for the deployment of the scope, started by another azd process, to complete
+func (p *BicepProvider) Attach(
+	ctx context.Context,
+	pd *DeploymentPlan,
+	scope infra.Scope,
+) *async.InteractiveTaskWithProgress[*DeployResult, *DeployProgress] {
+	return async.RunInteractiveTaskWithProgress(
+		func(asyncContext *async.InteractiveTaskContextWithProgress[*DeployResult, *DeployProgress]) {
+			done := make(chan bool)
+
+			// Ensure the done marker channel is sent in all conditions
+			defer func() {
+				done <- true
+			}()
+
+			go p.reportDeployProgress(ctx, asyncContext, scope, done)
+
+			armDeployment, err := p.waitForDeployment(ctx, scope)
+			if err != nil {
+				asyncContext.SetError(err)
+				return
+			}
+
+			deployment := pd.Deployment
+			deployment.Outputs = p.createOutputParameters(
+				&pd.Deployment,
+				azcli.CreateDeploymentOutput(armDeployment.Properties.Outputs),
+			)
+
+			asyncContext.SetResult(&DeployResult{
+				Deployment: &deployment,
+			})
+		})
+}
+
+// Polls the deployment of the scope until it completes, returns an error when it doesn't succeed
+func (p *BicepProvider) waitForDeployment(
+	ctx context.Context,
+	scope infra.Scope,
+) (*armresources.DeploymentExtended, error) {
+	for {
+		deployment, err := scope.GetDeployment(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("getting deployment %s: %w", scope.Name(), err)
+		}
+
+		if !IsDeploymentInProgress(deployment) {
+			state := armresources.ProvisioningStateNotSpecified
+			if deployment.Properties != nil && deployment.Properties.ProvisioningState != nil {
+				state = *deployment.Properties.ProvisioningState
+			}
+
+			if state != armresources.ProvisioningStateSucceeded {
+				return nil, fmt.Errorf("deployment %s completed with state %s", scope.Name(), state)
+			}
+
+			return deployment, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(attachPollInterval):
+		}
+	}
+}
+
+// FailedOperations returns the failed operations on the resources of the deployment of the scope. The operations on the
+// nested deployments are skipped, they only fail because of the operations on their resources.
+func (p *BicepProvider) FailedOperations(
+	ctx context.Context,
+	scope infra.Scope,
+) ([]*armresources.DeploymentOperation, error) {
+	operations, err := infra.NewAzureResourceManager(ctx).GetDeploymentResourceOperations(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := []*armresources.DeploymentOperation{}
+	for _, operation := range operations {
+		if operation.Properties == nil || operation.Properties.ProvisioningState == nil ||
+			*operation.Properties.ProvisioningState != string(armresources.ProvisioningStateFailed) {
+			continue
+		}
+
+		target := operation.Properties.TargetResource
+		if target != nil && target.ResourceType != nil &&
+			*target.ResourceType == string(infra.AzureResourceTypeDeployment) {
+			continue
+		}
+
+		failed = append(failed, operation)
+	}
+
+	return failed, nil
+}
+
+// Reports the progress of the operations of the deployment of the scope, until done is signaled
+func (p *BicepProvider) reportDeployProgress(
+	ctx context.Context,
+	asyncContext *async.InteractiveTaskContextWithProgress[*DeployResult, *DeployProgress],
+	scope infra.Scope,
+	done <-chan bool,
+) {
+	resourceManager := infra.NewAzureResourceManager(ctx)
+	progressDisplay := NewProvisioningProgressDisplay(resourceManager, p.console, scope)
+	// Make initial delay shorter to be more responsive in displaying initial progress
+	initialDelay := 3 * time.Second
+	regularDelay := 10 * time.Second
+	timer := time.NewTimer(initialDelay)
+
+	for {
+		select {
+		case <-done:
+			timer.Stop()
+			return
+		case <-timer.C:
+			progressReport, err := progressDisplay.ReportProgress(ctx)
+			if err != nil {
+				// We don't want to fail the whole deployment if a progress reporting error occurs
+				log.Printf("error while reporting progress: %s", err.Error())
+				continue
+			}
+
+			asyncContext.SetProgress(progressReport)
+
+			timer.Reset(regularDelay)
+		}
+	}
+}
+
+// CheckPolicies evaluates the resources the deployment creates or modifies, as predicted by an ARM what-if
+// operation, against the Azure Policies assigned to the subscription, and returns the resources the policies deny.
+// Only the deployments to a subscription are checked.
+func (p *BicepProvider) CheckPolicies(
+	ctx context.Context,
+	location string,
+	pd *DeploymentPlan,
+	scope infra.Scope,
+) ([]PolicyViolation, error) {
+	if _, ok := scope.(*infra.SubscriptionScope); !ok {
+		return nil, nil
+	}
+
+	bicepDeploymentData := pd.Details.(BicepDeploymentDetails)
+	changes, err := p.azCli.WhatIfDeployToSubscription(
+		ctx,
+		scope.SubscriptionId(),
+		scope.Name(),
+		bicepDeploymentData.Template,
+		bicepDeploymentData.ParameterFilePath,
+		location,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := []PolicyViolation{}
+	for _, change := range changes {
+		if change.ChangeType == nil || change.ResourceID == nil ||
+			(*change.ChangeType != armresources.ChangeTypeCreate && *change.ChangeType != armresources.ChangeTypeModify) {
+			continue
+		}
+
+		resource, ok := change.After.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		apiVersion, _ := resource["apiVersion"].(string)
+		evaluations, err := p.azCli.CheckPolicyRestrictions(ctx, scope.SubscriptionId(), resource, apiVersion)
+		if err != nil {
+			return nil, fmt.Errorf("evaluating policies of resource '%s': %w", *change.ResourceID, err)
+		}
+
+		for _, evaluation := range evaluations {
+			if evaluation.EvaluationResult != azsdk.PolicyEvaluationResultNonCompliant ||
+				!strings.EqualFold(evaluation.PolicyInfo.PolicyDefinitionEffect, "deny") {
+				continue
+			}
+
+			assignment := evaluation.PolicyInfo.PolicyAssignmentDisplayName
+			if assignment == "" {
+				assignment = evaluation.PolicyInfo.PolicyAssignmentId
+			}
+
+			violations = append(violations, PolicyViolation{
+				ResourceId:       *change.ResourceID,
+				PolicyAssignment: assignment,
+				Reason:           evaluation.EvaluationDetails.Reason,
+			})
+		}
+	}
+
+	return violations, nil
+}
+
 type itemToPurge struct {
 	resourceType string
 	count        int
@@ -265,6 +454,12 @@ func (p *BicepProvider) Destroy(
 	return async.RunInteractiveTaskWithProgress(
 		func(asyncContext *async.InteractiveTaskContextWithProgress[*DestroyResult, *DestroyProgress]) {
 			asyncContext.SetProgress(&DestroyProgress{Message: "Fetching resource groups", Timestamp: time.Now()})
+			// The resource groups and the deployment to delete are found from the name of the deployment
+			if _, err := ResolveDeploymentName(ctx, p.azCli, p.env); err != nil {
+				asyncContext.SetError(err)
+				return
+			}
+
 			resourceGroups, err := p.getResourceGroups(ctx)
 			if err != nil {
 				asyncContext.SetError(err)
@@ -297,11 +492,32 @@ func (p *BicepProvider) Destroy(
 				return
 			}
 
-			if err := p.destroyResourceGroups(ctx, asyncContext, options, groupedResources, len(allResources)); err != nil {
+			skippedGroups, err := p.destroyResourceGroups(ctx, asyncContext, options, groupedResources, len(allResources))
+			if err != nil && len(skippedGroups) > 0 {
+				asyncContext.SetError(fmt.Errorf(
+					"destroying resource groups, skipped the locked resource groups %s: %w",
+					strings.Join(skippedGroups, ", "),
+					err,
+				))
+				return
+			} else if err != nil {
 				asyncContext.SetError(fmt.Errorf("destroying resource groups: %w", err))
 				return
 			}
 
+			// The key vaults and app configurations of the locked resource groups still exist and can't be purged
+			if len(skippedGroups) > 0 {
+				skippedResources := map[string]bool{}
+				for _, resourceGroup := range skippedGroups {
+					for _, resource := range groupedResources[resourceGroup] {
+						skippedResources[resource.Name] = true
+					}
+				}
+
+				keyVaults = filterSlice(keyVaults, func(v *azcli.AzCliKeyVault) bool { return !skippedResources[v.Name] })
+				appConfigs = filterSlice(appConfigs, func(c *azcli.AzCliAppConfig) bool { return !skippedResources[c.Name] })
+			}
+
 			keyVaultsPurge := itemToPurge{
 				resourceType: "Key Vaults",
 				count:        len(keyVaults),
@@ -323,14 +539,18 @@ func (p *BicepProvider) Destroy(
 				return
 			}
 
-			if err := p.deleteDeployment(ctx, asyncContext); err != nil {
-				asyncContext.SetError(fmt.Errorf("deleting subscription deployment: %w", err))
-				return
+			// The deployment is kept while resource groups remain, so running the command again finds them
+			if len(skippedGroups) == 0 {
+				if err := p.deleteDeployment(ctx, asyncContext); err != nil {
+					asyncContext.SetError(fmt.Errorf("deleting subscription deployment: %w", err))
+					return
+				}
 			}
 
 			destroyResult := DestroyResult{
-				Resources: allResources,
-				Outputs:   deployment.Outputs,
+				Resources:             allResources,
+				Outputs:               deployment.Outputs,
+				SkippedResourceGroups: skippedGroups,
 			}
 
 			asyncContext.SetResult(&destroyResult)
@@ -339,7 +559,7 @@ func (p *BicepProvider) Destroy(
 
 func (p *BicepProvider) getResourceGroups(ctx context.Context) ([]string, error) {
 	resourceManager := infra.NewAzureResourceManager(ctx)
-	resourceGroups, err := resourceManager.GetResourceGroupsForDeployment(ctx, p.env.GetSubscriptionId(), p.env.GetEnvName())
+	resourceGroups, err := resourceManager.GetEnvironmentResourceGroups(ctx, p.env)
 	if err != nil {
 		return []string{}, err
 	}
@@ -365,14 +585,16 @@ func (p *BicepProvider) getAllResources(
 	return allResources, nil
 }
 
-// Deletes the azure resources within the deployment
+// Deletes the resource groups of the deployment, up to resourceGroupDeleteConcurrency at a time. The resource groups
+// that can't be deleted because of a lock are skipped and returned, sorted by name. The first failure stops the
+// deletion of the other resource groups, it's returned with the resource groups skipped so far.
 func (p *BicepProvider) destroyResourceGroups(
 	ctx context.Context,
 	asyncContext *async.InteractiveTaskContextWithProgress[*DestroyResult, *DestroyProgress],
 	options DestroyOptions,
 	groupedResources map[string][]azcli.AzCliResource,
 	resourceCount int,
-) error {
+) ([]string, error) {
 	if !options.Force() {
 		err := asyncContext.Interact(func() error {
 			confirmDestroy, err := p.console.Confirm(ctx, input.ConsoleOptions{
@@ -395,33 +617,119 @@ func (p *BicepProvider) destroyResourceGroups(
 		})
 
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mutex     sync.Mutex
+		waitGroup sync.WaitGroup
+		deleted   int
+		skipped   []string
+		firstErr  error
+	)
+
+	total := len(groupedResources)
+	start := time.Now()
+	semaphore := make(chan struct{}, resourceGroupDeleteConcurrency)
+
 	for resourceGroup := range groupedResources {
-		message := fmt.Sprintf(
-			"%s resource group %s",
-			output.WithErrorFormat("Deleting"),
-			output.WithHighLightFormat(resourceGroup),
-		)
-		asyncContext.SetProgress(&DestroyProgress{Message: message, Timestamp: time.Now()})
+		resourceGroup := resourceGroup
 
-		if err := p.azCli.DeleteResourceGroup(ctx, p.env.GetSubscriptionId(), resourceGroup); err != nil {
-			return err
-		}
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
 
-		p.console.Message(
-			ctx,
-			fmt.Sprintf(
-				"%s resource group %s",
-				output.WithErrorFormat("Deleted"),
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				return
+			}
+
+			mutex.Lock()
+			if firstErr != nil {
+				mutex.Unlock()
+				return
+			}
+
+			message := fmt.Sprintf(
+				"%s resource group %s (%d/%d done%s)",
+				output.WithErrorFormat("Deleting"),
 				output.WithHighLightFormat(resourceGroup),
-			),
-		)
+				deleted+len(skipped),
+				total,
+				remainingTime(start, deleted+len(skipped), total),
+			)
+			mutex.Unlock()
+			asyncContext.SetProgress(&DestroyProgress{Message: message, Timestamp: time.Now()})
+
+			err := p.azCli.DeleteResourceGroup(ctx, p.env.GetSubscriptionId(), resourceGroup)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			switch {
+			case errors.Is(err, azcli.ErrResourceGroupLocked):
+				skipped = append(skipped, resourceGroup)
+				p.console.Message(
+					ctx,
+					fmt.Sprintf(
+						"%s resource group %s, it has a delete lock",
+						output.WithWarningFormat("Skipped"),
+						output.WithHighLightFormat(resourceGroup),
+					),
+				)
+			case err != nil:
+				// The deletions canceled by the first failure fail too, only the first failure is returned
+				if firstErr == nil {
+					firstErr = fmt.Errorf("resource group %s: %w", resourceGroup, err)
+					cancel()
+				}
+			default:
+				deleted++
+				p.console.Message(
+					ctx,
+					fmt.Sprintf(
+						"%s resource group %s",
+						output.WithErrorFormat("Deleted"),
+						output.WithHighLightFormat(resourceGroup),
+					),
+				)
+			}
+		}()
 	}
 
-	return nil
+	waitGroup.Wait()
+
+	sort.Strings(skipped)
+	return skipped, firstErr
+}
+
+// remainingTime formats the estimated time left to delete all the resource groups, based on the average time taken by
+// the groups deleted so far
+func remainingTime(start time.Time, done int, total int) string {
+	if done == 0 || done >= total {
+		return ""
+	}
+
+	remaining := time.Since(start) / time.Duration(done) * time.Duration(total-done)
+	return fmt.Sprintf(", about %s remaining", remaining.Round(time.Second))
+}
+
+// filterSlice returns the items of the slice for which keep returns true
+func filterSlice[T any](items []T, keep func(T) bool) []T {
+	filtered := []T{}
+	for _, item := range items {
+		if keep(item) {
+			filtered = append(filtered, item)
+		}
+	}
+
+	return filtered
 }
 
 func (p *BicepProvider) purgeItems(
@@ -654,7 +962,7 @@ func (p *BicepProvider) deleteDeployment(
 ) error {
 	asyncContext.SetProgress(&DestroyProgress{Message: "Deleting deployment", Timestamp: time.Now()})
 
-	deploymentName := p.env.GetEnvName()
+	deploymentName := p.env.GetDeploymentName()
 
 	if err := p.azCli.DeleteSubscriptionDeployment(ctx, p.env.GetSubscriptionId(), deploymentName); err != nil {
 		return err
@@ -698,13 +1006,13 @@ func (p *BicepProvider) updateParametersFile(ctx context.Context, deployment *De
 
 func (p *BicepProvider) mapBicepTypeToInterfaceType(s string) ParameterType {
 	switch s {
-	case "String", "string":
+	case "String", "string", "SecureString", "securestring", "secureString":
 		return ParameterTypeString
 	case "Bool", "bool":
 		return ParameterTypeBoolean
 	case "Int", "int":
 		return ParameterTypeNumber
-	case "Object", "object":
+	case "Object", "object", "SecureObject", "secureobject", "secureObject":
 		return ParameterTypeObject
 	case "Array", "array":
 		return ParameterTypeArray
@@ -713,6 +1021,11 @@ func (p *BicepProvider) mapBicepTypeToInterfaceType(s string) ParameterType {
 	}
 }
 
+// Secure outputs are declared with the @secure() decorator, ARM doesn't return their values
+func isSecureBicepType(s string) bool {
+	return strings.EqualFold(s, "securestring") || strings.EqualFold(s, "secureobject")
+}
+
 // Creates a normalized view of the azure output parameters and resolves inconsistencies in the output parameter name
 // casings.
 func (p *BicepProvider) createOutputParameters(
@@ -737,8 +1050,9 @@ func (p *BicepProvider) createOutputParameters(
 		}
 
 		outputParams[paramName] = OutputParameter{
-			Type:  p.mapBicepTypeToInterfaceType(azureParam.Type),
-			Value: azureParam.Value,
+			Type:   p.mapBicepTypeToInterfaceType(azureParam.Type),
+			Value:  azureParam.Value,
+			Secure: isSecureBicepType(azureParam.Type),
 		}
 	}
 
@@ -746,28 +1060,47 @@ func (p *BicepProvider) createOutputParameters(
 }
 
 // createParametersFile will read the parameters file template for environment/module specified by Options,
-// do environment and command substitutions, and write out the result into a temporary file.
+// do environment and command substitutions, and write out the result into a temporary file. A .bicepparam file of the
+// module is compiled instead of reading the JSON parameters file.
 //
 // The caller of the method is responsible for deleting the file when it is no longer necessary.
 func (p *BicepProvider) createParametersFile(
 	ctx context.Context,
 	asyncContext *async.InteractiveTaskContextWithProgress[*DeploymentPlan, *DeploymentPlanningProgress],
 ) (*BicepTemplate, string, error) {
-	parametersTemplateFilePath := p.parametersTemplateFilePath()
-	log.Printf("Reading parameters template file from: %s", parametersTemplateFilePath)
-	parametersBytes, err := os.ReadFile(parametersTemplateFilePath)
-	if err != nil {
-		return nil, "", fmt.Errorf("reading parameter file template: %w", err)
-	}
+	var replaced string
+	var err error
+
+	bicepParamFilePath := p.bicepParamFilePath()
+	if _, statErr := os.Stat(bicepParamFilePath); statErr == nil {
+		if _, err := os.Stat(p.parametersTemplateFilePath()); err == nil {
+			return nil, "", fmt.Errorf(
+				"both %s and %s exist, delete one of the parameters files of the %s module",
+				filepath.Base(bicepParamFilePath), filepath.Base(p.parametersTemplateFilePath()), p.options.Module)
+		}
 
-	replaced, err := envsubst.Eval(string(parametersBytes), func(name string) string {
-		if val, has := p.env.Values[name]; has {
-			return val
+		log.Printf("Compiling parameters file from: %s", bicepParamFilePath)
+		replaced, err = p.bicepCli.BuildParams(ctx, bicepParamFilePath, p.bicepParamEnv())
+		if err != nil {
+			return nil, "", fmt.Errorf("compiling bicepparam file: %w", err)
+		}
+	} else {
+		parametersTemplateFilePath := p.parametersTemplateFilePath()
+		log.Printf("Reading parameters template file from: %s", parametersTemplateFilePath)
+		parametersBytes, err := os.ReadFile(parametersTemplateFilePath)
+		if err != nil {
+			return nil, "", fmt.Errorf("reading parameter file template: %w", err)
+		}
+
+		replaced, err = envsubst.Eval(string(parametersBytes), func(name string) string {
+			if val, has := p.env.Values[name]; has {
+				return val
+			}
+			return os.Getenv(name)
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("substituting environment variables inside parameter file: %w", err)
 		}
-		return os.Getenv(name)
-	})
-	if err != nil {
-		return nil, "", fmt.Errorf("substituting environment variables inside parameter file: %w", err)
 	}
 
 	if cmdsubst.ContainsCommandInvocation(replaced, cmdsubst.SecretOrRandomPasswordCommandName) {
@@ -806,6 +1139,13 @@ func (p *BicepProvider) createDeployment(ctx context.Context, modulePath string)
 		return nil, nil, fmt.Errorf("failed to compile bicep template: %w", err)
 	}
 
+	if len(p.options.ServiceModules) > 0 {
+		compiled, err = p.composeServiceModules(ctx, compiled)
+		if err != nil {
+			return nil, nil, fmt.Errorf("composing the modules of the services: %w", err)
+		}
+	}
+
 	// Fetch the parameters from the template and ensure we have a value for each one, otherwise
 	// prompt.
 	var bicepTemplate BicepTemplate
@@ -894,6 +1234,23 @@ func (p *BicepProvider) parametersTemplateFilePath() string {
 	return filepath.Join(p.projectPath, infraPath, parametersFilename)
 }
 
+// Gets the path to the bicepparam file of the module, which is used instead of the parameters file when it exists
+func (p *BicepProvider) bicepParamFilePath() string {
+	return strings.TrimSuffix(p.modulePath(), ".bicep") + ".bicepparam"
+}
+
+// The environment a bicepparam file is compiled in, the values of the azd environment take precedence over the
+// environment of azd, like the substitutions of the parameters file
+func (p *BicepProvider) bicepParamEnv() []string {
+	env := make([]string, 0, len(p.env.Values))
+	for key, value := range p.env.Values {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	sort.Strings(env)
+	return env
+}
+
 // Gets the folder path to the specified module
 func (p *BicepProvider) modulePath() string {
 	infraPath := p.options.Path
@@ -905,6 +1262,42 @@ func (p *BicepProvider) modulePath() string {
 	return filepath.Join(p.projectPath, infraPath, moduleFilename)
 }
 
+// Sets the values of the parameters overridden with `--parameter` and `--parameters-file`
+func (p *BicepProvider) applyParameterOverrides(deployment *Deployment) error {
+	for key, value := range p.options.Parameters {
+		param, has := deployment.Parameters[key]
+		if !has {
+			return fmt.Errorf("the parameter '%s' isn't a parameter of the %s module", key, p.options.Module)
+		}
+
+		converted, err := ConvertParameterValue(key, bicepParameterType(param.Type), value)
+		if err != nil {
+			return err
+		}
+
+		param.Value = converted
+		deployment.Parameters[key] = param
+	}
+
+	return nil
+}
+
+// Maps the type of a bicep input parameter, including the secure types, to the interface type
+func bicepParameterType(s string) ParameterType {
+	switch strings.ToLower(s) {
+	case "bool":
+		return ParameterTypeBoolean
+	case "int":
+		return ParameterTypeNumber
+	case "object", "secureobject":
+		return ParameterTypeObject
+	case "array":
+		return ParameterTypeArray
+	default:
+		return ParameterTypeString
+	}
+}
+
 // Ensures the provisioning parameters are valid and prompts the user for input as needed
 func (p *BicepProvider) ensureParameters(ctx context.Context, deployment *Deployment) (bool, error) {
 	if len(deployment.Parameters) == 0 {
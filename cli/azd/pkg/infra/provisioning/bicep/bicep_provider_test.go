@@ -7,17 +7,21 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appconfiguration/armappconfiguration"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
@@ -26,6 +30,7 @@ import (
 	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	execmock "github.com/azure/azure-dev/cli/azd/test/mocks/exec"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/httputil"
@@ -75,6 +80,128 @@ func TestBicepPlan(t *testing.T) {
 	)
 }
 
+func TestBicepPlanParameterOverrides(t *testing.T) {
+	t.Run("Override", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		prepareGenericMocks(mockContext.CommandRunner)
+		preparePlanningMocks(mockContext)
+		infraProvider := createBicepProvider(*mockContext.Context)
+		infraProvider.options.Parameters = ParameterOverrides{"location": "eastus"}
+
+		deploymentPlan, err := awaitPlan(infraProvider.Plan(*mockContext.Context))
+		require.NoError(t, err)
+		require.Equal(t, "eastus", deploymentPlan.Deployment.Parameters["location"].Value)
+
+		details := deploymentPlan.Details.(BicepDeploymentDetails)
+		parametersBytes, err := os.ReadFile(details.ParameterFilePath)
+		require.NoError(t, err)
+		require.Contains(t, string(parametersBytes), "eastus")
+	})
+
+	t.Run("UnknownParameter", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		prepareGenericMocks(mockContext.CommandRunner)
+		preparePlanningMocks(mockContext)
+		infraProvider := createBicepProvider(*mockContext.Context)
+		infraProvider.options.Parameters = ParameterOverrides{"unknown": "value"}
+
+		_, err := awaitPlan(infraProvider.Plan(*mockContext.Context))
+		require.ErrorContains(t, err, "unknown")
+	})
+}
+
+func TestBicepPlanBicepParam(t *testing.T) {
+	// Creates a provider for a project whose main module has a bicepparam file
+	newProvider := func(t *testing.T, ctx context.Context) *BicepProvider {
+		projectDir := t.TempDir()
+		infraDir := filepath.Join(projectDir, "infra")
+		require.NoError(t, os.MkdirAll(infraDir, osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(filepath.Join(infraDir, "main.bicep"), []byte(""), osutil.PermissionFile))
+		require.NoError(t, os.WriteFile(
+			filepath.Join(infraDir, "main.bicepparam"),
+			[]byte("using './main.bicep'\nparam location = readEnvironmentVariable('AZURE_LOCATION')\n"),
+			osutil.PermissionFile))
+
+		env := environment.EphemeralWithValues("test-env", map[string]string{
+			environment.LocationEnvVarName:       "westus2",
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		})
+
+		return NewBicepProvider(ctx, env, projectDir, Options{Module: "main"})
+	}
+
+	t.Run("Compiled", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		prepareGenericMocks(mockContext.CommandRunner)
+		preparePlanningMocks(mockContext)
+
+		var buildParamsEnv []string
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "az bicep build-params")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			buildParamsEnv = args.Env
+			return exec.NewRunResult(0, `{
+				"parametersJson": "{\"parameters\": {\"location\": {\"value\": \"westus2\"}}}"
+			}`, ""), nil
+		})
+
+		infraProvider := newProvider(t, *mockContext.Context)
+		deploymentPlan, err := awaitPlan(infraProvider.Plan(*mockContext.Context))
+		require.NoError(t, err)
+		require.Equal(t, "westus2", deploymentPlan.Deployment.Parameters["location"].Value)
+		require.Contains(t, buildParamsEnv, "AZURE_LOCATION=westus2")
+	})
+
+	t.Run("BothParametersFiles", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		prepareGenericMocks(mockContext.CommandRunner)
+		preparePlanningMocks(mockContext)
+
+		infraProvider := newProvider(t, *mockContext.Context)
+		require.NoError(t, os.WriteFile(
+			filepath.Join(infraProvider.projectPath, "infra", "main.parameters.json"),
+			[]byte(testArmParametersFile),
+			osutil.PermissionFile))
+
+		_, err := awaitPlan(infraProvider.Plan(*mockContext.Context))
+		require.ErrorContains(t, err, "both main.bicepparam and main.parameters.json exist")
+	})
+}
+
+// Awaits the planning task, ignoring its progress
+func awaitPlan(
+	planningTask *async.InteractiveTaskWithProgress[*DeploymentPlan, *DeploymentPlanningProgress],
+) (*DeploymentPlan, error) {
+	go func() {
+		for range planningTask.Progress() {
+		}
+	}()
+
+	go func() {
+		for range planningTask.Interactive() {
+		}
+	}()
+
+	return planningTask.Await()
+}
+
+func TestBicepSecureOutputs(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	prepareGenericMocks(mockContext.CommandRunner)
+	infraProvider := createBicepProvider(*mockContext.Context)
+
+	outputs := infraProvider.createOutputParameters(&Deployment{}, map[string]azcli.AzCliDeploymentOutput{
+		"WEBSITE_URL": {Type: "String", Value: "http://myapp.azurewebsites.net"},
+		"DB_PASSWORD": {Type: "SecureString"},
+		"SETTINGS":    {Type: "SecureObject"},
+	})
+
+	require.Equal(t, OutputParameter{Type: ParameterTypeString, Value: "http://myapp.azurewebsites.net"},
+		outputs["WEBSITE_URL"])
+	require.Equal(t, OutputParameter{Type: ParameterTypeString, Secure: true}, outputs["DB_PASSWORD"])
+	require.Equal(t, OutputParameter{Type: ParameterTypeObject, Secure: true}, outputs["SETTINGS"])
+}
+
 func TestBicepState(t *testing.T) {
 	progressLog := []string{}
 	interactiveLog := []bool{}
@@ -176,6 +303,169 @@ func TestBicepDeploy(t *testing.T) {
 	require.Equal(t, deployResult.Deployment.Outputs["WEBSITE_URL"].Value, expectedWebsiteUrl)
 }
 
+func TestBicepAttach(t *testing.T) {
+	pollInterval := attachPollInterval
+	attachPollInterval = time.Millisecond
+	defer func() { attachPollInterval = pollInterval }()
+
+	newDeployment := func(state armresources.ProvisioningState) []byte {
+		deployment, _ := json.Marshal(armresources.DeploymentExtended{
+			ID:   convert.RefOf("DEPLOYMENT_ID"),
+			Name: convert.RefOf("DEPLOYMENT_NAME"),
+			Properties: &armresources.DeploymentPropertiesExtended{
+				ProvisioningState: convert.RefOf(state),
+				Outputs: map[string]interface{}{
+					"WEBSITE_URL": map[string]interface{}{"value": "http://myapp.azurewebsites.net", "type": "string"},
+				},
+			},
+		})
+		return deployment
+	}
+
+	runAttach := func(states ...armresources.ProvisioningState) (*DeployResult, error) {
+		mockContext := mocks.NewMockContext(context.Background())
+		prepareGenericMocks(mockContext.CommandRunner)
+		prepareDeployMocks(mockContext.CommandRunner)
+
+		gets := 0
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && strings.Contains(
+				request.URL.Path,
+				"/SUBSCRIPTION_ID/providers/Microsoft.Resources/deployments/",
+			)
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			state := states[len(states)-1]
+			if gets < len(states) {
+				state = states[gets]
+			}
+			gets++
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBuffer(newDeployment(state))),
+			}, nil
+		})
+
+		infraProvider := createBicepProvider(*mockContext.Context)
+		scope := infra.NewSubscriptionScope(
+			*mockContext.Context,
+			infraProvider.env.Values["AZURE_LOCATION"],
+			infraProvider.env.GetSubscriptionId(),
+			"test-env-0123abcd-1664627400",
+		)
+
+		attachTask := infraProvider.Attach(*mockContext.Context, &DeploymentPlan{}, scope)
+		go func() {
+			for range attachTask.Progress() {
+			}
+		}()
+		go func() {
+			for range attachTask.Interactive() {
+			}
+		}()
+
+		return attachTask.Await()
+	}
+
+	t.Run("Succeeded", func(t *testing.T) {
+		deployResult, err := runAttach(armresources.ProvisioningStateRunning, armresources.ProvisioningStateSucceeded)
+		require.NoError(t, err)
+		require.Equal(t, "http://myapp.azurewebsites.net", deployResult.Deployment.Outputs["WEBSITE_URL"].Value)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		_, err := runAttach(armresources.ProvisioningStateRunning, armresources.ProvisioningStateFailed)
+		require.ErrorContains(t, err, "Failed")
+	})
+}
+
+func TestBicepCheckPolicies(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	prepareGenericMocks(mockContext.CommandRunner)
+
+	// The deployment creates a storage account in westus and doesn't change the resource group
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.HasSuffix(
+			request.URL.Path,
+			"/subscriptions/SUBSCRIPTION_ID/providers/Microsoft.Resources/deployments/test-env/whatIf",
+		)
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armresources.WhatIfOperationResult{
+			Status: to.Ptr("Succeeded"),
+			Properties: &armresources.WhatIfOperationProperties{
+				Changes: []*armresources.WhatIfChange{
+					{
+						ChangeType: to.Ptr(armresources.ChangeTypeNoChange),
+						ResourceID: to.Ptr("/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-test-env"),
+					},
+					{
+						ChangeType: to.Ptr(armresources.ChangeTypeCreate),
+						ResourceID: to.Ptr("/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-test-env/providers/" +
+							"Microsoft.Storage/storageAccounts/st123"),
+						After: map[string]any{
+							"apiVersion": "2022-05-01",
+							"type":       "Microsoft.Storage/storageAccounts",
+							"location":   "westus",
+						},
+					},
+				},
+			},
+		})
+	})
+
+	// The allowed locations policy denies the resources in westus
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost &&
+			strings.HasSuffix(request.URL.Path, "/providers/Microsoft.PolicyInsights/checkPolicyRestrictions")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(request.Body)
+		require.Contains(t, string(body), `"apiVersion":"2022-05-01"`)
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+			"contentEvaluationResult": map[string]any{
+				"policyEvaluations": []map[string]any{
+					{
+						"policyInfo": map[string]any{
+							"policyAssignmentId":          "/providers/Microsoft.Authorization/policyAssignments/locations",
+							"policyAssignmentDisplayName": "Allowed locations",
+							"policyDefinitionEffect":      "Deny",
+						},
+						"evaluationResult":  "NonCompliant",
+						"evaluationDetails": map[string]any{"reason": "westus is not an allowed location"},
+					},
+					{
+						"policyInfo":       map[string]any{"policyDefinitionEffect": "Audit"},
+						"evaluationResult": "NonCompliant",
+					},
+				},
+			},
+		})
+	})
+
+	infraProvider := createBicepProvider(*mockContext.Context)
+	parametersPath := path.Join(t.TempDir(), "params.json")
+	require.NoError(t, os.WriteFile(parametersPath, []byte(testArmParametersFile), osutil.PermissionFile))
+
+	deploymentPlan := DeploymentPlan{
+		Details: BicepDeploymentDetails{
+			ParameterFilePath: parametersPath,
+			Template:          to.Ptr(azure.ArmTemplate("{}")),
+		},
+	}
+
+	scope := infra.NewSubscriptionScope(*mockContext.Context, "westus", "SUBSCRIPTION_ID", "test-env")
+	violations, err := infraProvider.CheckPolicies(*mockContext.Context, "westus", &deploymentPlan, scope)
+	require.NoError(t, err)
+	require.Equal(t, []PolicyViolation{
+		{
+			ResourceId: "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-test-env/providers/" +
+				"Microsoft.Storage/storageAccounts/st123",
+			PolicyAssignment: "Allowed locations",
+			Reason:           "westus is not an allowed location",
+		},
+	}, violations)
+}
+
 func TestBicepDestroy(t *testing.T) {
 	t.Run("Interactive", func(t *testing.T) {
 		mockContext := mocks.NewMockContext(context.Background())
@@ -299,6 +589,108 @@ func TestBicepDestroy(t *testing.T) {
 		require.Contains(t, progressLog[4], "Deleting resource group")
 		require.Contains(t, progressLog[5], "Deleting deployment")
 	})
+
+	t.Run("LockedResourceGroup", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		prepareGenericMocks(mockContext.CommandRunner)
+		preparePlanningMocks(mockContext)
+		prepareDeployShowMocks(mockContext.HttpClient)
+		prepareDestroyMocks(mockContext)
+
+		// The delete lock of the resource group rejects its deletion
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodDelete &&
+				strings.Contains(request.URL.Path, "subscriptions/SUBSCRIPTION_ID/resourcegroups/RESOURCE_GROUP")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return &http.Response{
+				Request:    request,
+				Header:     http.Header{},
+				StatusCode: http.StatusConflict,
+				Body: io.NopCloser(bytes.NewBufferString(
+					`{"error":{"code":"ScopeLocked","message":"The scope is locked."}}`,
+				)),
+			}, nil
+		})
+
+		infraProvider := createBicepProvider(*mockContext.Context)
+		deployment := Deployment{}
+
+		destroyOptions := NewDestroyOptions(true, true)
+		destroyTask := infraProvider.Destroy(*mockContext.Context, &deployment, destroyOptions)
+
+		go func() {
+			for range destroyTask.Progress() {
+			}
+		}()
+
+		destroyResult, err := destroyTask.Await()
+
+		require.Nil(t, err)
+		require.NotNil(t, destroyResult)
+		require.Equal(t, []string{"RESOURCE_GROUP"}, destroyResult.SkippedResourceGroups)
+
+		// The resources of the locked group are not purged and the deployment is kept
+		consoleOutput := mockContext.Console.Output()
+		require.Len(t, consoleOutput, 1)
+		require.Contains(t, consoleOutput[0], "Skipped resource group")
+	})
+}
+
+func TestBicepDestroyResourceGroupsFailure(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	prepareGenericMocks(mockContext.CommandRunner)
+
+	deleteRequest := func(resourceGroup string) func(request *http.Request) bool {
+		return func(request *http.Request) bool {
+			return request.Method == http.MethodDelete && strings.HasSuffix(request.URL.Path, "/resourcegroups/"+resourceGroup)
+		}
+	}
+	errorResponse := func(request *http.Request, statusCode int, code string) *http.Response {
+		return &http.Response{
+			Request:    request,
+			Header:     http.Header{},
+			StatusCode: statusCode,
+			Body:       io.NopCloser(bytes.NewBufferString(fmt.Sprintf(`{"error":{"code":"%s","message":"%s"}}`, code, code))),
+		}
+	}
+
+	lockedRejected := make(chan struct{})
+	mockContext.HttpClient.When(deleteRequest("LOCKED")).RespondFn(func(request *http.Request) (*http.Response, error) {
+		defer close(lockedRejected)
+		return errorResponse(request, http.StatusConflict, "ScopeLocked"), nil
+	})
+	// The failure happens once the locked resource group is skipped
+	mockContext.HttpClient.When(deleteRequest("FAILED")).RespondFn(func(request *http.Request) (*http.Response, error) {
+		<-lockedRejected
+		return errorResponse(request, http.StatusBadRequest, "BadRequest"), nil
+	})
+	// The deletion in progress only completes when it's canceled
+	mockContext.HttpClient.When(deleteRequest("PENDING")).RespondFn(func(request *http.Request) (*http.Response, error) {
+		<-request.Context().Done()
+		return nil, request.Context().Err()
+	})
+
+	infraProvider := createBicepProvider(*mockContext.Context)
+	groupedResources := map[string][]azcli.AzCliResource{"LOCKED": nil, "FAILED": nil, "PENDING": nil}
+
+	var skipped []string
+	var err error
+	task := async.RunInteractiveTaskWithProgress(
+		func(asyncContext *async.InteractiveTaskContextWithProgress[*DestroyResult, *DestroyProgress]) {
+			skipped, err = infraProvider.destroyResourceGroups(
+				*mockContext.Context, asyncContext, NewDestroyOptions(true, true), groupedResources, 0)
+		},
+	)
+
+	go func() {
+		for range task.Progress() {
+		}
+	}()
+
+	_, _ = task.Await()
+
+	require.ErrorContains(t, err, "resource group FAILED")
+	require.Equal(t, []string{"LOCKED"}, skipped)
 }
 
 func createBicepProvider(ctx context.Context) *BicepProvider {
@@ -469,6 +861,22 @@ func prepareDestroyMocks(mockContext *mocks.MockContext) {
 		}, nil
 	})
 
+	// Get list of resource groups tagged with the environment name
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/resourcegroups")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armresources.ResourceGroupListResult{
+			Value: []*armresources.ResourceGroup{
+				{
+					ID:       convert.RefOf("RESOURCE_GROUP"),
+					Name:     convert.RefOf("RESOURCE_GROUP"),
+					Type:     convert.RefOf(string(infra.AzureResourceTypeResourceGroup)),
+					Location: convert.RefOf("eastus2"),
+				},
+			},
+		})
+	})
+
 	// Get Key Vault
 	mockContext.HttpClient.When(func(request *http.Request) bool {
 		return request.Method == http.MethodGet && strings.Contains(request.URL.Path, "/vaults/kv-123")
@@ -0,0 +1,129 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/cmdsubst"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+)
+
+// The variables of the environment set by azd, by the lowercase names of the parameters they are usually passed to
+var wellKnownParameterVariables = map[string]string{
+	"environmentname":   environment.EnvNameEnvVarName,
+	"location":          environment.LocationEnvVarName,
+	"principalid":       environment.PrincipalIdEnvVarName,
+	"subscriptionid":    environment.SubscriptionIdEnvVarName,
+	"tenantid":          environment.TenantIdEnvVarName,
+	"resourcegroupname": environment.ResourceGroupEnvVarName,
+}
+
+// Splits a camel case name before each upper case letter following a lower case letter or a digit
+var camelCaseBoundaryRegex = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
+// ParameterVariableName returns the environment variable passed to the parameter of a template, i.e. AZURE_LOCATION
+// for location and AZURE_SQL_ADMIN_LOGIN for sqlAdminLogin
+func ParameterVariableName(name string) string {
+	if variable, has := wellKnownParameterVariables[strings.ToLower(name)]; has {
+		return variable
+	}
+
+	snakeCase := camelCaseBoundaryRegex.ReplaceAllString(name, "${1}_${2}")
+	snakeCase = strings.ToUpper(strings.ReplaceAll(snakeCase, "-", "_"))
+	if strings.HasPrefix(snakeCase, "AZURE_") {
+		return snakeCase
+	}
+
+	return "AZURE_" + snakeCase
+}
+
+// ParameterValueOption is a value offered for a parameter of a generated parameters file
+type ParameterValueOption struct {
+	// The description of the value displayed to the user
+	Description string
+	// The expression written to the parameters file, nil when the parameter is omitted and keeps its default value
+	Value *string
+}
+
+// ParameterValueOptions returns the values offered for a parameter of a template, the first option is the default.
+// A secure string is offered a password generated once and stored in the key vault of the environment, any parameter is
+// offered its environment variable, and a parameter with a default value is offered to keep it.
+func ParameterValueOptions(name string, param InputParameter) []ParameterValueOption {
+	options := []ParameterValueOption{}
+
+	if strings.EqualFold(param.Type, "securestring") {
+		value := fmt.Sprintf(
+			"$(%s ${%s} %s)", cmdsubst.SecretOrRandomPasswordCommandName, environment.KeyVaultNameEnvVarName, name)
+		options = append(options, ParameterValueOption{
+			Description: fmt.Sprintf("A generated password, stored in the key vault of the environment: %s", value),
+			Value:       &value,
+		})
+	}
+
+	variable := fmt.Sprintf("${%s}", ParameterVariableName(name))
+	options = append(options, ParameterValueOption{
+		Description: fmt.Sprintf("The value of an environment variable: %s", variable),
+		Value:       &variable,
+	})
+
+	if param.DefaultValue != nil {
+		options = append(options, ParameterValueOption{
+			Description: "The default value of the template",
+		})
+	}
+
+	return options
+}
+
+// NewParametersFile returns a deployment parameters file with the expressions of the parameters by name
+func NewParametersFile(values map[string]string) ([]byte, error) {
+	// Unlike BicepTemplate, the parameters file has no outputs and its parameters only have values
+	parametersFile := struct {
+		Schema         string                       `json:"$schema"`
+		ContentVersion string                       `json:"contentVersion"`
+		Parameters     map[string]map[string]string `json:"parameters"`
+	}{
+		Schema:         "https://schema.management.azure.com/schemas/2019-04-01/deploymentParameters.json#",
+		ContentVersion: "1.0.0.0",
+		Parameters:     make(map[string]map[string]string, len(values)),
+	}
+
+	for name, value := range values {
+		parametersFile.Parameters[name] = map[string]string{"value": value}
+	}
+
+	content, err := json.MarshalIndent(parametersFile, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling parameters: %w", err)
+	}
+
+	return append(content, '\n'), nil
+}
+
+// TemplateParameters compiles the module of the provider and returns its parameters, including the parameters of the
+// modules of the services
+func (p *BicepProvider) TemplateParameters(ctx context.Context) (map[string]InputParameter, error) {
+	deployment, _, err := p.createDeployment(ctx, p.modulePath())
+	if err != nil {
+		return nil, err
+	}
+
+	return deployment.Parameters, nil
+}
+
+// ParametersFilePath returns the path to the parameters file of the module of the provider
+func (p *BicepProvider) ParametersFilePath() string {
+	return p.parametersTemplateFilePath()
+}
+
+// BicepParamFilePath returns the path to the bicepparam file of the module of the provider
+func (p *BicepProvider) BicepParamFilePath() string {
+	return p.bicepParamFilePath()
+}
@@ -0,0 +1,60 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParameterVariableName(t *testing.T) {
+	require.Equal(t, "AZURE_ENV_NAME", ParameterVariableName("environmentName"))
+	require.Equal(t, "AZURE_LOCATION", ParameterVariableName("location"))
+	require.Equal(t, "AZURE_PRINCIPAL_ID", ParameterVariableName("principalId"))
+	require.Equal(t, "AZURE_SQL_ADMIN_LOGIN", ParameterVariableName("sqlAdminLogin"))
+	require.Equal(t, "AZURE_API2_NAME", ParameterVariableName("api2Name"))
+	require.Equal(t, "AZURE_APP_NAME", ParameterVariableName("azureAppName"))
+	require.Equal(t, "AZURE_APP_NAME", ParameterVariableName("app-name"))
+}
+
+func TestParameterValueOptions(t *testing.T) {
+	t.Run("String", func(t *testing.T) {
+		options := ParameterValueOptions("location", InputParameter{Type: "string"})
+		require.Len(t, options, 1)
+		require.Equal(t, "${AZURE_LOCATION}", *options[0].Value)
+	})
+
+	t.Run("SecureString", func(t *testing.T) {
+		options := ParameterValueOptions("sqlAdminPassword", InputParameter{Type: "secureString"})
+		require.Len(t, options, 2)
+		require.Equal(t, "$(secretOrRandomPassword ${AZURE_KEY_VAULT_NAME} sqlAdminPassword)", *options[0].Value)
+		require.Equal(t, "${AZURE_SQL_ADMIN_PASSWORD}", *options[1].Value)
+	})
+
+	t.Run("DefaultValue", func(t *testing.T) {
+		options := ParameterValueOptions("sku", InputParameter{Type: "string", DefaultValue: "B1"})
+		require.Len(t, options, 2)
+		require.Equal(t, "${AZURE_SKU}", *options[0].Value)
+		require.Nil(t, options[1].Value)
+	})
+}
+
+func TestNewParametersFile(t *testing.T) {
+	content, err := NewParametersFile(map[string]string{
+		"environmentName": "${AZURE_ENV_NAME}",
+		"location":        "${AZURE_LOCATION}",
+	})
+	require.NoError(t, err)
+
+	var parametersFile BicepTemplate
+	require.NoError(t, json.Unmarshal(content, &parametersFile))
+	require.Equal(t, "1.0.0.0", parametersFile.ContentVersion)
+	require.Equal(t, map[string]BicepInputParameter{
+		"environmentName": {Value: "${AZURE_ENV_NAME}"},
+		"location":        {Value: "${AZURE_LOCATION}"},
+	}, parametersFile.Parameters)
+}
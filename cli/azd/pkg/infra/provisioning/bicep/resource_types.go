@@ -0,0 +1,59 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// resourceDeclarationRegexp matches the declarations of resources with a fully qualified type, i.e.
+// resource web 'Microsoft.Web/sites@2022-03-01' = {
+var resourceDeclarationRegexp = regexp.MustCompile(
+	`(?m)^\s*resource\s+\w+\s+'([A-Za-z0-9]+(?:\.[A-Za-z0-9]+)+/[A-Za-z0-9/]+)@[^']*'(\s+existing)?`,
+)
+
+// ResourceTypes returns the sorted types of the resources the Bicep files of the directory, and of its
+// subdirectories, deploy. The resources referenced with the existing keyword aren't deployed and are ignored.
+func ResourceTypes(infraPath string) ([]string, error) {
+	types := map[string]struct{}{}
+
+	err := filepath.WalkDir(infraPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || filepath.Ext(path) != ".bicep" {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		for _, match := range resourceDeclarationRegexp.FindAllStringSubmatch(string(content), -1) {
+			if strings.TrimSpace(match[2]) == "" {
+				types[match[1]] = struct{}{}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resourceTypes := make([]string, 0, len(types))
+	for resourceType := range types {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+
+	sort.Strings(resourceTypes)
+	return resourceTypes, nil
+}
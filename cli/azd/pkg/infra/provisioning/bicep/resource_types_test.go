@@ -0,0 +1,58 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceTypes(t *testing.T) {
+	infraPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(infraPath, "core"), osutil.PermissionDirectory))
+
+	main := `targetScope = 'subscription'
+
+resource rg 'Microsoft.Resources/resourceGroups@2021-04-01' = {
+  name: 'rg-test'
+  location: location
+}
+
+resource vault 'Microsoft.KeyVault/vaults@2022-07-01' existing = {
+  name: 'kv-shared'
+}
+`
+	web := `resource web 'Microsoft.Web/sites@2022-03-01' = {
+  name: name
+  location: location
+
+  resource config 'config' = {
+    name: 'web'
+  }
+}
+
+resource plan 'Microsoft.Web/serverfarms@2022-03-01' = {
+  name: planName
+}
+
+resource other 'Microsoft.Web/sites@2021-02-01' = {
+  name: otherName
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(infraPath, "main.bicep"), []byte(main), osutil.PermissionFile))
+	require.NoError(t, os.WriteFile(filepath.Join(infraPath, "core", "web.bicep"), []byte(web), osutil.PermissionFile))
+	require.NoError(t, os.WriteFile(filepath.Join(infraPath, "main.parameters.json"), []byte("{}"), osutil.PermissionFile))
+
+	resourceTypes, err := ResourceTypes(infraPath)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"Microsoft.Resources/resourceGroups",
+		"Microsoft.Web/serverfarms",
+		"Microsoft.Web/sites",
+	}, resourceTypes)
+}
@@ -0,0 +1,341 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+)
+
+// The api version of the nested deployments of the service modules
+const serviceDeploymentApiVersion = "2022-09-01"
+
+// The scopes of the templates a service module can be composed with
+const (
+	subscriptionTemplateScope  = "subscription"
+	resourceGroupTemplateScope = "resourceGroup"
+)
+
+// ServiceOutputName returns the name of the root output exposing an output of the module of a service, outputs are
+// namespaced per service as SERVICE_<SERVICE>_<OUTPUT>
+func ServiceOutputName(serviceName string, outputName string) string {
+	return strings.ToUpper(fmt.Sprintf("SERVICE_%s_%s", strings.ReplaceAll(serviceName, "-", "_"), outputName))
+}
+
+// Compiles the bicep modules of the services and composes them into the compiled root template
+func (p *BicepProvider) composeServiceModules(ctx context.Context, compiled string) (string, error) {
+	modules := make(map[string]string, len(p.options.ServiceModules))
+	for serviceName, module := range p.options.ServiceModules {
+		moduleCompiled, err := p.bicepCli.Build(ctx, p.serviceModulePath(module))
+		if err != nil {
+			return "", fmt.Errorf("failed to compile the bicep module of service '%s': %w", serviceName, err)
+		}
+
+		modules[serviceName] = moduleCompiled
+	}
+
+	return composeServiceModules(compiled, modules)
+}
+
+// Gets the path to the bicep module of a service, relative to the root infra folder, the extension is optional
+func (p *BicepProvider) serviceModulePath(module string) string {
+	infraPath := p.options.Path
+	if strings.TrimSpace(infraPath) == "" {
+		infraPath = "infra"
+	}
+
+	if filepath.Ext(module) == "" {
+		module = fmt.Sprintf("%s.bicep", module)
+	}
+
+	return filepath.Join(p.projectPath, infraPath, module)
+}
+
+// composeServiceModules adds a nested deployment of the compiled module of each service to the compiled root template.
+// The parameters of a module receive the root parameter, or else the root output, of the same name, and its outputs are
+// exposed as root outputs named by ServiceOutputName.
+func composeServiceModules(compiled string, modules map[string]string) (string, error) {
+	var root map[string]any
+	if err := json.Unmarshal([]byte(compiled), &root); err != nil {
+		return "", fmt.Errorf("error un-marshaling arm template from json: %w", err)
+	}
+
+	rootScope, err := templateScope(root)
+	if err != nil {
+		return "", err
+	}
+
+	rootParameters := jsonObject(root, "parameters")
+	rootOutputs := jsonObject(root, "outputs")
+	root["outputs"] = rootOutputs
+
+	serviceNames := make([]string, 0, len(modules))
+	for serviceName := range modules {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+
+	for _, serviceName := range serviceNames {
+		var module map[string]any
+		if err := json.Unmarshal([]byte(modules[serviceName]), &module); err != nil {
+			return "", fmt.Errorf("error un-marshaling arm template of service '%s' from json: %w", serviceName, err)
+		}
+
+		moduleScope, err := templateScope(module)
+		if err != nil {
+			return "", fmt.Errorf("composing the module of service '%s': %w", serviceName, err)
+		}
+
+		deployment := serviceDeployment{
+			serviceName: serviceName,
+			rootScope:   rootScope,
+			moduleScope: moduleScope,
+		}
+
+		properties := map[string]any{
+			"expressionEvaluationOptions": map[string]any{"scope": "inner"},
+			"mode":                        "Incremental",
+			"template":                    module,
+		}
+
+		resource := map[string]any{
+			"type":       "Microsoft.Resources/deployments",
+			"apiVersion": serviceDeploymentApiVersion,
+			"name":       fmt.Sprintf("[%s]", deployment.nameExpression()),
+			"properties": properties,
+		}
+
+		switch {
+		case rootScope == resourceGroupTemplateScope && moduleScope == subscriptionTemplateScope:
+			return "", fmt.Errorf(
+				"the module of service '%s' targets a subscription, it can't be composed into a resource group deployment",
+				serviceName,
+			)
+		case rootScope == subscriptionTemplateScope && moduleScope == resourceGroupTemplateScope:
+			// The resource group of the environment is the one output by the root template, by convention
+			resourceGroup, has := findOutput(rootOutputs, environment.ResourceGroupEnvVarName)
+			if !has {
+				return "", fmt.Errorf(
+					"the module of service '%s' targets a resource group, the root template must output %s",
+					serviceName,
+					environment.ResourceGroupEnvVarName,
+				)
+			}
+
+			deployment.resourceGroupExpression = expressionOf(resourceGroup)
+			resource["resourceGroup"] = resourceGroup
+			resource["location"] = "[deployment().location]"
+		case rootScope == subscriptionTemplateScope:
+			resource["location"] = "[deployment().location]"
+		}
+
+		parameters := map[string]any{}
+		for name, value := range jsonObject(module, "parameters") {
+			param, _ := value.(map[string]any)
+
+			if _, has := rootParameters[name]; has {
+				parameters[name] = map[string]any{"value": fmt.Sprintf("[parameters('%s')]", name)}
+			} else if output, has := findOutput(rootOutputs, name); has {
+				// The expression of the output is evaluated in the scope of the root template
+				parameters[name] = map[string]any{"value": output}
+			} else if _, has := param["defaultValue"]; !has {
+				return "", fmt.Errorf(
+					"the parameter '%s' of the module of service '%s' has no value, declare it as a parameter "+
+						"or an output of the root module, or give it a default value",
+					name,
+					serviceName,
+				)
+			}
+		}
+		properties["parameters"] = parameters
+
+		if err := addServiceDeployment(root, deployment, resource); err != nil {
+			return "", err
+		}
+
+		for name, value := range jsonObject(module, "outputs") {
+			output, _ := value.(map[string]any)
+			outputType, _ := output["type"].(string)
+
+			// ARM doesn't return the values of secure outputs to the parent deployment
+			if isSecureBicepType(outputType) {
+				log.Printf("skipping secure output '%s' of the module of service '%s'", name, serviceName)
+				continue
+			}
+
+			outputName := ServiceOutputName(serviceName, name)
+			if _, has := findOutput(rootOutputs, outputName); has {
+				return "", fmt.Errorf(
+					"the output '%s' of the module of service '%s' conflicts with the output '%s' of the root module",
+					name,
+					serviceName,
+					outputName,
+				)
+			}
+
+			rootOutputs[outputName] = map[string]any{
+				"type":  outputType,
+				"value": fmt.Sprintf("[%s.outputs['%s'].value]", deployment.referenceExpression(root), name),
+			}
+		}
+	}
+
+	composed, err := json.Marshal(root)
+	if err != nil {
+		return "", fmt.Errorf("marshaling composed arm template: %w", err)
+	}
+
+	return string(composed), nil
+}
+
+// serviceDeployment is the nested deployment of the module of a service in the root template
+type serviceDeployment struct {
+	serviceName string
+	rootScope   string
+	moduleScope string
+	// The expression of the resource group the module is deployed to, for the modules of a subscription deployment
+	// targeting a resource group
+	resourceGroupExpression string
+}
+
+// The symbolic name of the nested deployment, in the templates declaring their resources by symbolic name
+func (d serviceDeployment) symbolicName() string {
+	return fmt.Sprintf("service_%s", strings.ReplaceAll(d.serviceName, "-", "_"))
+}
+
+// The name of the nested deployment is unique per deployment of the root template
+func (d serviceDeployment) nameExpression() string {
+	return fmt.Sprintf("format('{0}-{1}', '%s', uniqueString(deployment().name))", d.serviceName)
+}
+
+// The expression referencing the nested deployment, to read its outputs
+func (d serviceDeployment) referenceExpression(root map[string]any) string {
+	if _, symbolic := root["resources"].(map[string]any); symbolic {
+		return fmt.Sprintf("reference('%s')", d.symbolicName())
+	}
+
+	var id string
+	switch {
+	case d.rootScope == resourceGroupTemplateScope:
+		id = fmt.Sprintf("resourceId('Microsoft.Resources/deployments', %s)", d.nameExpression())
+	case d.moduleScope == resourceGroupTemplateScope:
+		id = fmt.Sprintf(
+			"extensionResourceId(format('/subscriptions/{0}/resourceGroups/{1}', subscription().subscriptionId, %s), "+
+				"'Microsoft.Resources/deployments', %s)",
+			d.resourceGroupExpression,
+			d.nameExpression(),
+		)
+	default:
+		id = fmt.Sprintf("subscriptionResourceId('Microsoft.Resources/deployments', %s)", d.nameExpression())
+	}
+
+	return fmt.Sprintf("reference(%s, '%s')", id, serviceDeploymentApiVersion)
+}
+
+// Adds the nested deployment to the resources of the root template, depending on the resource groups it creates
+func addServiceDeployment(root map[string]any, deployment serviceDeployment, resource map[string]any) error {
+	dependsOn := []any{}
+
+	switch resources := root["resources"].(type) {
+	case map[string]any:
+		for name, value := range resources {
+			if isResourceGroup(value) {
+				dependsOn = append(dependsOn, name)
+			}
+		}
+		sort.Slice(dependsOn, func(i, j int) bool { return dependsOn[i].(string) < dependsOn[j].(string) })
+
+		if _, has := resources[deployment.symbolicName()]; has {
+			return fmt.Errorf(
+				"the module of service '%s' conflicts with the resource '%s' of the root module",
+				deployment.serviceName,
+				deployment.symbolicName(),
+			)
+		}
+
+		resource["dependsOn"] = dependsOn
+		resources[deployment.symbolicName()] = resource
+	case nil:
+		resource["dependsOn"] = dependsOn
+		root["resources"] = []any{resource}
+	case []any:
+		for _, value := range resources {
+			if isResourceGroup(value) {
+				name := value.(map[string]any)["name"]
+				dependsOn = append(dependsOn, fmt.Sprintf(
+					"[subscriptionResourceId('Microsoft.Resources/resourceGroups', %s)]", expressionOf(name)))
+			}
+		}
+
+		resource["dependsOn"] = dependsOn
+		root["resources"] = append(resources, resource)
+	default:
+		return fmt.Errorf("unexpected resources in the root template: %T", resources)
+	}
+
+	return nil
+}
+
+// Returns the scope of a compiled template, from its schema
+func templateScope(template map[string]any) (string, error) {
+	schema, _ := template["$schema"].(string)
+
+	switch {
+	case strings.Contains(schema, "subscriptionDeploymentTemplate.json"):
+		return subscriptionTemplateScope, nil
+	case strings.Contains(schema, "/deploymentTemplate.json"):
+		return resourceGroupTemplateScope, nil
+	default:
+		return "", fmt.Errorf("unsupported template schema '%s', expected a subscription or resource group template", schema)
+	}
+}
+
+// Returns the value of the output of the given name, output names are case-insensitive
+func findOutput(outputs map[string]any, name string) (any, bool) {
+	for key, value := range outputs {
+		if strings.EqualFold(key, name) {
+			output, _ := value.(map[string]any)
+			value, has := output["value"]
+			return value, has
+		}
+	}
+
+	return nil, false
+}
+
+func isResourceGroup(resource any) bool {
+	value, _ := resource.(map[string]any)
+	resourceType, _ := value["type"].(string)
+	return strings.EqualFold(resourceType, "Microsoft.Resources/resourceGroups")
+}
+
+// Returns the object property of a template, or an empty object
+func jsonObject(template map[string]any, property string) map[string]any {
+	if value, ok := template[property].(map[string]any); ok {
+		return value
+	}
+
+	return map[string]any{}
+}
+
+// Converts a template value, an expression in brackets or a literal, to an expression to embed in another expression
+func expressionOf(value any) string {
+	if s, ok := value.(string); ok {
+		if strings.HasPrefix(s, "[") && !strings.HasPrefix(s, "[[") && strings.HasSuffix(s, "]") {
+			return s[1 : len(s)-1]
+		}
+
+		return fmt.Sprintf("'%s'", strings.ReplaceAll(s, "'", "''"))
+	}
+
+	encoded, _ := json.Marshal(value)
+	return fmt.Sprintf("json('%s')", strings.ReplaceAll(string(encoded), "'", "''"))
+}
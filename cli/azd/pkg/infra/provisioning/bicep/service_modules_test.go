@@ -0,0 +1,124 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bicep
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSubscriptionTemplate = `{
+	"$schema": "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#",
+	"contentVersion": "1.0.0.0",
+	"parameters": {
+		"environmentName": {"type": "string"},
+		"location": {"type": "string"}
+	},
+	"resources": [
+		{
+			"type": "Microsoft.Resources/resourceGroups",
+			"apiVersion": "2021-04-01",
+			"name": "[format('rg-{0}', parameters('environmentName'))]",
+			"location": "[parameters('location')]"
+		}
+	],
+	"outputs": {
+		"AZURE_RESOURCE_GROUP": {"type": "string", "value": "[format('rg-{0}', parameters('environmentName'))]"},
+		"registryName": {"type": "string", "value": "registry"}
+	}
+}`
+
+const testServiceTemplate = `{
+	"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+	"contentVersion": "1.0.0.0",
+	"parameters": {
+		"location": {"type": "string"},
+		"registryName": {"type": "string"},
+		"sku": {"type": "string", "defaultValue": "B1"}
+	},
+	"resources": [],
+	"outputs": {
+		"uri": {"type": "string", "value": "https://api.azurewebsites.net"},
+		"key": {"type": "securestring", "value": "secret"}
+	}
+}`
+
+func TestServiceOutputName(t *testing.T) {
+	require.Equal(t, "SERVICE_WEB_API_URI", ServiceOutputName("web-api", "uri"))
+}
+
+func TestComposeServiceModules(t *testing.T) {
+	t.Run("ResourceGroupModule", func(t *testing.T) {
+		composed, err := composeServiceModules(testSubscriptionTemplate, map[string]string{"api": testServiceTemplate})
+		require.NoError(t, err)
+
+		var template map[string]any
+		require.NoError(t, json.Unmarshal([]byte(composed), &template))
+
+		resources := template["resources"].([]any)
+		require.Len(t, resources, 2)
+
+		deployment := resources[1].(map[string]any)
+		require.Equal(t, "Microsoft.Resources/deployments", deployment["type"])
+		require.Equal(t, "[format('{0}-{1}', 'api', uniqueString(deployment().name))]", deployment["name"])
+		require.Equal(t, "[format('rg-{0}', parameters('environmentName'))]", deployment["resourceGroup"])
+		require.Equal(t, []any{
+			"[subscriptionResourceId('Microsoft.Resources/resourceGroups', " +
+				"format('rg-{0}', parameters('environmentName')))]",
+		}, deployment["dependsOn"])
+
+		properties := deployment["properties"].(map[string]any)
+		require.Equal(t, map[string]any{
+			"location":     map[string]any{"value": "[parameters('location')]"},
+			"registryName": map[string]any{"value": "registry"},
+		}, properties["parameters"])
+
+		outputs := template["outputs"].(map[string]any)
+		require.Contains(t, outputs, "SERVICE_API_URI")
+		require.NotContains(t, outputs, "SERVICE_API_KEY")
+		require.Equal(t,
+			"[reference(extensionResourceId(format('/subscriptions/{0}/resourceGroups/{1}', subscription().subscriptionId, "+
+				"format('rg-{0}', parameters('environmentName'))), 'Microsoft.Resources/deployments', "+
+				"format('{0}-{1}', 'api', uniqueString(deployment().name))), '2022-09-01').outputs['uri'].value]",
+			outputs["SERVICE_API_URI"].(map[string]any)["value"],
+		)
+	})
+
+	t.Run("MissingParameter", func(t *testing.T) {
+		module := `{
+			"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+			"parameters": {"imageName": {"type": "string"}}
+		}`
+
+		_, err := composeServiceModules(testSubscriptionTemplate, map[string]string{"api": module})
+		require.ErrorContains(t, err, "imageName")
+	})
+
+	t.Run("SubscriptionModuleInResourceGroupTemplate", func(t *testing.T) {
+		root := `{"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#"}`
+		module := `{
+			"$schema": "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#"
+		}`
+
+		_, err := composeServiceModules(root, map[string]string{"api": module})
+		require.ErrorContains(t, err, "targets a subscription")
+	})
+
+	t.Run("OutputConflict", func(t *testing.T) {
+		root := `{
+			"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+			"outputs": {"SERVICE_API_URI": {"type": "string", "value": "uri"}}
+		}`
+
+		module := `{
+			"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+			"outputs": {"uri": {"type": "string", "value": "uri"}}
+		}`
+
+		_, err := composeServiceModules(root, map[string]string{"api": module})
+		require.ErrorContains(t, err, "conflicts")
+	})
+}
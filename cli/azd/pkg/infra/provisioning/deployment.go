@@ -27,6 +27,9 @@ type InputParameter struct {
 type OutputParameter struct {
 	Type  ParameterType
 	Value interface{}
+	// Secure outputs are secureString and secureObject outputs of ARM, and sensitive outputs of terraform. Their
+	// values are stored in the key vault of the environment instead of the environment file.
+	Secure bool
 }
 
 // State represents the "current state" of the infrastructure, which is the result of the most recent deployment. For ARM
@@ -0,0 +1,161 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// The names of ARM deployments are limited to 64 characters
+const maxDeploymentNameLength = 64
+
+// The length of the content hash in the name of a deployment
+const deploymentHashLength = 8
+
+// DeploymentEnvNameTag is the tag of the deployments of an environment, its value is the name of the environment
+const DeploymentEnvNameTag = "azd-env-name"
+
+// NewDeploymentName returns the name of a deployment of the environment, <env>-<hash>-<timestamp>. The hash of the
+// content of the deployment identifies deployments of the same parameters, the timestamp makes the name unique. The name
+// of the environment is truncated to fit the name in the limits of ARM.
+func NewDeploymentName(envName string, contentHash string, now time.Time) string {
+	return fmt.Sprintf("%s-%s-%d", deploymentNamePrefix(envName), contentHash, now.Unix())
+}
+
+// DeploymentContentHash returns the hash of the parameters and outputs of the planned deployment
+func DeploymentContentHash(plan *DeploymentPlan) string {
+	content, err := json.Marshal(plan.Deployment)
+	if err != nil {
+		// The deployment is unique by its timestamp anyway
+		log.Printf("hashing deployment: %v", err)
+	}
+
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])[:deploymentHashLength]
+}
+
+// ParseDeploymentName returns the content hash of a deployment of the environment named by NewDeploymentName, false
+// when the deployment isn't named after the environment that way.
+func ParseDeploymentName(envName string, name string) (string, bool) {
+	prefix := deploymentNamePrefix(envName) + "-"
+	if !strings.HasPrefix(name, prefix) {
+		return "", false
+	}
+
+	contentHash, timestamp, has := strings.Cut(strings.TrimPrefix(name, prefix), "-")
+	if !has || len(contentHash) != deploymentHashLength {
+		return "", false
+	}
+
+	if _, err := strconv.ParseInt(timestamp, 10, 64); err != nil {
+		return "", false
+	}
+
+	return contentHash, true
+}
+
+func deploymentNamePrefix(envName string) string {
+	// <prefix>-<hash>-<10 digits timestamp>
+	maxPrefixLength := maxDeploymentNameLength - deploymentHashLength - 12
+	if len(envName) > maxPrefixLength {
+		return envName[:maxPrefixLength]
+	}
+
+	return envName
+}
+
+// IsEnvironmentDeployment returns true when the deployment is a deployment of the environment: tagged with the name of
+// the environment, or, when the deployment isn't tagged, named by NewDeploymentName or after the environment as the
+// deployments made before they were tagged and named uniquely.
+func IsEnvironmentDeployment(envName string, deployment *armresources.DeploymentExtended) bool {
+	if tag, has := deployment.Tags[DeploymentEnvNameTag]; has && tag != nil {
+		return *tag == envName
+	}
+
+	if deployment.Name == nil {
+		return false
+	}
+
+	_, named := ParseDeploymentName(envName, *deployment.Name)
+	return named || *deployment.Name == envName
+}
+
+// IsDeploymentInProgress returns true when the ARM deployment hasn't completed yet
+func IsDeploymentInProgress(deployment *armresources.DeploymentExtended) bool {
+	if deployment.Properties == nil || deployment.Properties.ProvisioningState == nil {
+		return false
+	}
+
+	switch *deployment.Properties.ProvisioningState {
+	case armresources.ProvisioningStateAccepted,
+		armresources.ProvisioningStateCreating,
+		armresources.ProvisioningStateCreated,
+		armresources.ProvisioningStateReady,
+		armresources.ProvisioningStateRunning,
+		armresources.ProvisioningStateUpdating:
+		return true
+	default:
+		return false
+	}
+}
+
+// LatestSucceededDeployment returns the most recent succeeded deployment of the environment among the deployments of a
+// subscription, or nil when the environment has none.
+func LatestSucceededDeployment(
+	envName string,
+	deployments []*armresources.DeploymentExtended,
+) *armresources.DeploymentExtended {
+	var latest *armresources.DeploymentExtended
+	for _, deployment := range deployments {
+		if deployment.Name == nil || deployment.Properties == nil || deployment.Properties.ProvisioningState == nil ||
+			*deployment.Properties.ProvisioningState != armresources.ProvisioningStateSucceeded {
+			continue
+		}
+
+		if !IsEnvironmentDeployment(envName, deployment) {
+			continue
+		}
+
+		if latest == nil || deploymentTimestamp(deployment).After(deploymentTimestamp(latest)) {
+			latest = deployment
+		}
+	}
+
+	return latest
+}
+
+// ResolveDeploymentName returns the name of the last deployment of the environment and sets it in the environment. The
+// name isn't stored when the environment wasn't provisioned from this machine, i.e. a fresh clone or a CI run, then it's
+// the name of the latest succeeded deployment of the environment in its subscription. Falls back to the name of the
+// environment, the name of the deployments made before they were named uniquely.
+func ResolveDeploymentName(ctx context.Context, azCli azcli.AzCli, env *environment.Environment) (string, error) {
+	if name := env.Values[environment.DeploymentNameEnvVarName]; name != "" {
+		return name, nil
+	}
+
+	deployments, err := azCli.ListSubscriptionDeployments(ctx, env.GetSubscriptionId())
+	if err != nil {
+		return "", fmt.Errorf("listing deployments: %w", err)
+	}
+
+	latest := LatestSucceededDeployment(env.GetEnvName(), deployments)
+	if latest == nil {
+		return env.GetEnvName(), nil
+	}
+
+	env.SetDeploymentName(*latest.Name)
+	return *latest.Name, nil
+}
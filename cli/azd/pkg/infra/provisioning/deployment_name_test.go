@@ -0,0 +1,195 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeploymentName(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 30, 0, 0, time.UTC)
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		name := NewDeploymentName("dev", "0123abcd", now)
+		require.Equal(t, "dev-0123abcd-1664627400", name)
+
+		contentHash, ok := ParseDeploymentName("dev", name)
+		require.True(t, ok)
+		require.Equal(t, "0123abcd", contentHash)
+	})
+
+	t.Run("LongEnvironmentName", func(t *testing.T) {
+		envName := strings.Repeat("a", 64)
+		name := NewDeploymentName(envName, "0123abcd", now)
+		require.Len(t, name, maxDeploymentNameLength)
+
+		contentHash, ok := ParseDeploymentName(envName, name)
+		require.True(t, ok)
+		require.Equal(t, "0123abcd", contentHash)
+	})
+
+	t.Run("NotNamedAfterEnvironment", func(t *testing.T) {
+		_, ok := ParseDeploymentName("dev", "dev")
+		require.False(t, ok)
+
+		_, ok = ParseDeploymentName("dev", "prod-0123abcd-1664627400")
+		require.False(t, ok)
+
+		_, ok = ParseDeploymentName("dev", "dev-0123abcd-now")
+		require.False(t, ok)
+	})
+}
+
+func TestDeploymentContentHash(t *testing.T) {
+	plan := &DeploymentPlan{
+		Deployment: Deployment{
+			Parameters: map[string]InputParameter{
+				"location": {Type: "string", Value: "eastus"},
+			},
+		},
+	}
+
+	contentHash := DeploymentContentHash(plan)
+	require.Len(t, contentHash, deploymentHashLength)
+	require.Equal(t, contentHash, DeploymentContentHash(plan))
+
+	plan.Deployment.Parameters["location"] = InputParameter{Type: "string", Value: "westus"}
+	require.NotEqual(t, contentHash, DeploymentContentHash(plan))
+}
+
+func TestIsEnvironmentDeployment(t *testing.T) {
+	newDeployment := func(name string, tags map[string]*string) *armresources.DeploymentExtended {
+		return &armresources.DeploymentExtended{Name: convert.RefOf(name), Tags: tags}
+	}
+	tagged := func(envName string) map[string]*string {
+		return map[string]*string{DeploymentEnvNameTag: convert.RefOf(envName)}
+	}
+
+	require.True(t, IsEnvironmentDeployment("dev", newDeployment("dev-0123abcd-1664627400", nil)))
+	require.True(t, IsEnvironmentDeployment("dev", newDeployment("dev", nil)))
+	require.False(t, IsEnvironmentDeployment("dev", newDeployment("prod-0123abcd-1664627400", nil)))
+
+	// The tag wins over the name, the truncated names of environments may collide
+	require.True(t, IsEnvironmentDeployment("dev", newDeployment("pipeline-run", tagged("dev"))))
+	require.False(t, IsEnvironmentDeployment("dev", newDeployment("dev-0123abcd-1664627400", tagged("dev-eu"))))
+}
+
+func TestIsDeploymentInProgress(t *testing.T) {
+	newDeployment := func(state armresources.ProvisioningState) *armresources.DeploymentExtended {
+		return &armresources.DeploymentExtended{
+			Properties: &armresources.DeploymentPropertiesExtended{ProvisioningState: convert.RefOf(state)},
+		}
+	}
+
+	require.True(t, IsDeploymentInProgress(newDeployment(armresources.ProvisioningStateRunning)))
+	require.True(t, IsDeploymentInProgress(newDeployment(armresources.ProvisioningStateAccepted)))
+	require.False(t, IsDeploymentInProgress(newDeployment(armresources.ProvisioningStateSucceeded)))
+	require.False(t, IsDeploymentInProgress(newDeployment(armresources.ProvisioningStateFailed)))
+	require.False(t, IsDeploymentInProgress(&armresources.DeploymentExtended{}))
+}
+
+func TestLatestSucceededDeployment(t *testing.T) {
+	newDeployment := func(
+		name string, state armresources.ProvisioningState, timestamp time.Time) *armresources.DeploymentExtended {
+		return &armresources.DeploymentExtended{
+			Name: convert.RefOf(name),
+			Properties: &armresources.DeploymentPropertiesExtended{
+				ProvisioningState: convert.RefOf(state),
+				Timestamp:         convert.RefOf(timestamp),
+			},
+		}
+	}
+
+	now := time.Date(2022, 10, 1, 12, 30, 0, 0, time.UTC)
+	deployments := []*armresources.DeploymentExtended{
+		newDeployment("dev", armresources.ProvisioningStateSucceeded, now.Add(-2*time.Hour)),
+		newDeployment("dev-0123abcd-1664627400", armresources.ProvisioningStateSucceeded, now.Add(-time.Hour)),
+		newDeployment("dev-4567ef01-1664631000", armresources.ProvisioningStateFailed, now),
+		newDeployment("prod-0123abcd-1664627400", armresources.ProvisioningStateSucceeded, now),
+	}
+
+	latest := LatestSucceededDeployment("dev", deployments)
+	require.NotNil(t, latest)
+	require.Equal(t, "dev-0123abcd-1664627400", *latest.Name)
+
+	require.Nil(t, LatestSucceededDeployment("test", deployments))
+}
+
+func TestResolveDeploymentName(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 30, 0, 0, time.UTC)
+	deployments := armresources.DeploymentListResult{
+		Value: []*armresources.DeploymentExtended{
+			{
+				Name: convert.RefOf("dev-0123abcd-1664627400"),
+				Properties: &armresources.DeploymentPropertiesExtended{
+					ProvisioningState: convert.RefOf(armresources.ProvisioningStateSucceeded),
+					Timestamp:         convert.RefOf(now),
+				},
+			},
+		},
+	}
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.Contains(
+			request.URL.Path,
+			"/subscriptions/SUBSCRIPTION_ID/providers/Microsoft.Resources/deployments",
+		)
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		body, _ := json.Marshal(deployments)
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBuffer(body)),
+		}, nil
+	})
+	azCli := azcli.GetAzCli(*mockContext.Context)
+
+	t.Run("Stored", func(t *testing.T) {
+		env := environment.EphemeralWithValues("dev", map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+			environment.DeploymentNameEnvVarName: "dev-4567ef01-1664631000",
+		})
+
+		name, err := ResolveDeploymentName(*mockContext.Context, azCli, env)
+		require.NoError(t, err)
+		require.Equal(t, "dev-4567ef01-1664631000", name)
+	})
+
+	t.Run("NotStored", func(t *testing.T) {
+		// An environment refreshed on another machine than the one it was provisioned from
+		env := environment.EphemeralWithValues("dev", map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		})
+
+		name, err := ResolveDeploymentName(*mockContext.Context, azCli, env)
+		require.NoError(t, err)
+		require.Equal(t, "dev-0123abcd-1664627400", name)
+		require.Equal(t, "dev-0123abcd-1664627400", env.GetDeploymentName())
+	})
+
+	t.Run("NoDeployment", func(t *testing.T) {
+		env := environment.EphemeralWithValues("test", map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		})
+
+		name, err := ResolveDeploymentName(*mockContext.Context, azCli, env)
+		require.NoError(t, err)
+		require.Equal(t, "test", name)
+	})
+}
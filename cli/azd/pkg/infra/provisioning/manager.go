@@ -5,18 +5,27 @@ package provisioning
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"strings"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/events"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/fields"
 	"github.com/azure/azure-dev/cli/azd/pkg/azureutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/eventlog"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/spin"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // Manages the orchestration of infrastructure provisioning
@@ -46,6 +55,7 @@ func (m *Manager) State(ctx context.Context, scope infra.Scope) (*StateResult, e
 
 	err := m.runAction(
 		ctx,
+		"state",
 		"Retrieving Infrastructure State",
 		m.interactive,
 		func(ctx context.Context, spinner *spin.Spinner) error {
@@ -85,19 +95,120 @@ func (m *Manager) Deploy(ctx context.Context, plan *DeploymentPlan, scope infra.
 		return nil, err
 	}
 
+	if checker, ok := m.provider.(PolicyChecker); ok {
+		if err := m.checkPolicies(ctx, checker, location, plan, scope); err != nil {
+			return nil, err
+		}
+	}
+
 	// Apply the infrastructure deployment
-	deployResult, err := m.deploy(ctx, location, plan, scope)
+	deployResult, err := m.deployWithRetries(ctx, location, plan, scope)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := UpdateEnvironment(m.env, deployResult.Deployment.Outputs); err != nil {
-		return nil, fmt.Errorf("updating environment with deployment outputs: %w", err)
+	if err := m.updateEnvironment(ctx, deployResult.Deployment.Outputs); err != nil {
+		return nil, err
+	}
+
+	return deployResult, nil
+}
+
+// Attach waits for the deployment of the scope, started by another azd process and still in progress, to complete
+// instead of starting a duplicate deployment, then updates the environment with its outputs.
+func (m *Manager) Attach(ctx context.Context, plan *DeploymentPlan, scope infra.Scope) (*DeployResult, error) {
+	attacher, ok := m.provider.(DeploymentAttacher)
+	if !ok {
+		return nil, fmt.Errorf("the %s provider can't attach to a deployment in progress", m.provider.Name())
+	}
+
+	var deployResult *DeployResult
+
+	err := m.runAction(
+		ctx,
+		"attach",
+		"Waiting for the deployment in progress",
+		m.interactive,
+		func(ctx context.Context, spinner *spin.Spinner) error {
+			attachTask := attacher.Attach(ctx, plan, scope)
+
+			go func() {
+				for progress := range attachTask.Progress() {
+					m.updateSpinnerTitle(spinner, progress.Message)
+				}
+			}()
+
+			go m.monitorInteraction(spinner, attachTask.Interactive())
+
+			result, err := attachTask.Await()
+			if err != nil {
+				return err
+			}
+
+			deployResult = result
+
+			return nil
+		},
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("error waiting for the deployment in progress: %w", err)
+	}
+
+	m.console.Message(ctx, output.WithSuccessFormat("\nAzure resource provisioning completed successfully"))
+
+	if err := m.updateEnvironment(ctx, deployResult.Deployment.Outputs); err != nil {
+		return nil, err
 	}
 
 	return deployResult, nil
 }
 
+// FindDeploymentInProgress returns the name of the most recent deployment of the environment in progress in its
+// subscription, whichever machine or pipeline started it, or an empty string when no deployment of the environment is in
+// progress.
+func (m *Manager) FindDeploymentInProgress(ctx context.Context) (string, error) {
+	deployments, err := m.azCli.ListSubscriptionDeployments(ctx, m.env.GetSubscriptionId())
+	if err != nil {
+		return "", fmt.Errorf("finding the deployment of the environment in progress: %w", err)
+	}
+
+	var latest *armresources.DeploymentExtended
+	for _, deployment := range deployments {
+		if deployment.Name == nil || !IsDeploymentInProgress(deployment) ||
+			!IsEnvironmentDeployment(m.env.GetEnvName(), deployment) {
+			continue
+		}
+
+		if latest == nil || deploymentTimestamp(deployment).After(deploymentTimestamp(latest)) {
+			latest = deployment
+		}
+	}
+
+	if latest == nil {
+		return "", nil
+	}
+
+	return *latest.Name, nil
+}
+
+func deploymentTimestamp(deployment *armresources.DeploymentExtended) time.Time {
+	if deployment.Properties == nil || deployment.Properties.Timestamp == nil {
+		return time.Time{}
+	}
+
+	return *deployment.Properties.Timestamp
+}
+
+// Writes the outputs of the deployment to the environment, and stores the secure outputs in its key vault
+func (m *Manager) updateEnvironment(ctx context.Context, outputs map[string]OutputParameter) error {
+	if err := UpdateEnvironment(m.env, outputs); err != nil {
+		return fmt.Errorf("updating environment with deployment outputs: %w", err)
+	}
+
+	return StoreSecureOutputs(ctx, m.azCli, m.env, outputs)
+}
+
 // Destroys the Azure infrastructure for the specified project
 func (m *Manager) Destroy(ctx context.Context, deployment *Deployment, options DestroyOptions) (*DestroyResult, error) {
 	// Call provisioning provider to destroy the infrastructure
@@ -107,9 +218,11 @@ func (m *Manager) Destroy(ctx context.Context, deployment *Deployment, options D
 	}
 
 	// Remove any outputs from the template from the environment since destroying the infrastructure
-	// invalidated them all.
-	for outputName := range destroyResult.Outputs {
-		delete(m.env.Values, outputName)
+	// invalidated them all. They are kept when resource groups were skipped, the infrastructure still exists.
+	if len(destroyResult.SkippedResourceGroups) == 0 {
+		for outputName := range destroyResult.Outputs {
+			delete(m.env.Values, outputName)
+		}
 	}
 
 	// Update environment files to remove invalid infrastructure parameters
@@ -126,6 +239,7 @@ func (m *Manager) plan(ctx context.Context) (*DeploymentPlan, error) {
 
 	err := m.runAction(
 		ctx,
+		"plan",
 		"Planning infrastructure provisioning",
 		m.interactive,
 		func(ctx context.Context, spinner *spin.Spinner) error {
@@ -159,6 +273,78 @@ func (m *Manager) plan(ctx context.Context) (*DeploymentPlan, error) {
 	return deploymentPlan, nil
 }
 
+// Warns about the resources of the deployment denied by Azure Policy, and confirms the deployment should proceed
+// anyway when running interactively. Failing to evaluate the policies doesn't prevent the deployment.
+func (m *Manager) checkPolicies(
+	ctx context.Context,
+	checker PolicyChecker,
+	location string,
+	plan *DeploymentPlan,
+	scope infra.Scope,
+) error {
+	var violations []PolicyViolation
+
+	err := m.runAction(
+		ctx,
+		"policy",
+		"Checking Azure Policy compliance",
+		m.interactive,
+		func(ctx context.Context, spinner *spin.Spinner) error {
+			result, err := checker.CheckPolicies(ctx, location, plan, scope)
+			if err != nil {
+				return err
+			}
+
+			violations = result
+			return nil
+		},
+	)
+
+	if err != nil {
+		log.Printf("failed checking Azure Policy compliance: %v", err)
+		return nil
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	warning := fmt.Sprintf(
+		"WARNING: %d resources will be denied by the Azure Policies assigned to the subscription:", len(violations))
+	m.console.Message(ctx, output.WithWarningFormat(warning))
+	for _, violation := range violations {
+		message := fmt.Sprintf("  - %s, denied by %s", violation.ResourceId, violation.PolicyAssignment)
+		if violation.Reason != "" {
+			message += fmt.Sprintf(": %s", violation.Reason)
+		}
+
+		m.console.Message(ctx, message)
+		warning += "\n" + message
+	}
+
+	if annotation := output.Annotation(output.AnnotationWarning, warning); annotation != "" {
+		m.console.Message(ctx, annotation)
+	}
+
+	if !m.interactive {
+		return nil
+	}
+
+	proceed, err := m.console.Confirm(ctx, input.ConsoleOptions{
+		Message:      "Do you want to provision anyway?",
+		DefaultValue: false,
+	})
+	if err != nil {
+		return fmt.Errorf("prompting to provision: %w", err)
+	}
+
+	if !proceed {
+		return errors.New("provisioning canceled, the deployment would be denied by Azure Policy")
+	}
+
+	return nil
+}
+
 // Applies the specified infrastructure provisioning and orchestrates the interactive terminal operations
 func (m *Manager) deploy(
 	ctx context.Context,
@@ -170,6 +356,7 @@ func (m *Manager) deploy(
 
 	err := m.runAction(
 		ctx,
+		"deploy",
 		"Provisioning Azure resources",
 		m.interactive,
 		func(ctx context.Context, spinner *spin.Spinner) error {
@@ -203,12 +390,71 @@ func (m *Manager) deploy(
 	return deployResult, nil
 }
 
+// deployWithRetries deploys the plan, and runs the deployment again when it fails on transient errors, such as
+// conflicting operations or throttling, waiting longer before each attempt. When the deployment still fails, or fails on
+// other errors, the failed operations are listed and the user chooses whether to run the deployment again, i.e. after
+// fixing the cause of the failures. The deployment is incremental, only the failed steps are deployed again.
+func (m *Manager) deployWithRetries(
+	ctx context.Context,
+	location string,
+	plan *DeploymentPlan,
+	scope infra.Scope,
+) (*DeployResult, error) {
+	retrier, canRetry := m.provider.(DeploymentRetrier)
+
+	for attempt := 1; ; attempt++ {
+		deployResult, err := m.deploy(ctx, location, plan, scope)
+		if err == nil || !canRetry || ctx.Err() != nil {
+			return deployResult, err
+		}
+
+		failed, operationsErr := retrier.FailedOperations(ctx, scope)
+		if operationsErr != nil {
+			log.Printf("failed getting the failed operations of the deployment, not retrying: %v", operationsErr)
+			return nil, err
+		}
+
+		if len(failed) == 0 {
+			return nil, err
+		}
+
+		if attempt <= maxTransientRetries && AreTransientFailures(failed) {
+			delay := transientRetryDelay * time.Duration(1<<(attempt-1))
+			m.console.Message(ctx, output.WithWarningFormat(
+				"The deployment failed on transient errors, deploying the failed steps again in %s (retry %d of %d):\n%s",
+				delay, attempt, maxTransientRetries, failedOperationsMessage(failed)))
+
+			select {
+			case <-ctx.Done():
+				return nil, err
+			case <-time.After(delay):
+			}
+
+			continue
+		}
+
+		if !m.interactive {
+			return nil, err
+		}
+
+		m.console.Message(ctx, fmt.Sprintf("The deployment failed:\n%s", failedOperationsMessage(failed)))
+		retry, confirmErr := m.console.Confirm(ctx, input.ConsoleOptions{
+			Message:      "Retry the failed deployment steps?",
+			DefaultValue: false,
+		})
+		if confirmErr != nil || !retry {
+			return nil, err
+		}
+	}
+}
+
 // Destroys the specified infrastructure provisioning and orchestrates the interactive terminal operations
 func (m *Manager) destroy(ctx context.Context, deployment *Deployment, options DestroyOptions) (*DestroyResult, error) {
 	var destroyResult *DestroyResult
 
 	err := m.runAction(
 		ctx,
+		"destroy",
 		"Destroying Azure resources",
 		m.interactive,
 		func(ctx context.Context, spinner *spin.Spinner) error {
@@ -264,6 +510,7 @@ func (m *Manager) ensureLocation(ctx context.Context, deployment *Deployment) (s
 			ctx,
 			m.env,
 			"Please select an Azure location to use to store deployment metadata:",
+			nil,
 		)
 		if err != nil {
 			return "", fmt.Errorf("prompting for deployment metadata region: %w", err)
@@ -277,14 +524,27 @@ func (m *Manager) ensureLocation(ctx context.Context, deployment *Deployment) (s
 
 func (m *Manager) runAction(
 	ctx context.Context,
+	operation string,
 	title string,
 	interactive bool,
 	action func(ctx context.Context, spinner *spin.Spinner) error,
-) error {
+) (err error) {
+	// Trace each provisioning operation so the time spent within the provider can be measured
+	ctx, span := telemetry.GetTracer().Start(ctx, events.ProvisionEventPrefix+operation)
+	span.SetAttributes(fields.ProvisionProviderKey.String(m.provider.Name()))
+	completeStep := eventlog.FromContext(ctx).StartStep("provision/" + operation)
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, "UnknownError")
+		}
+		span.End()
+		completeStep(err)
+	}()
+
 	var spinner *spin.Spinner
 
 	if interactive && (m.formatter == nil || m.formatter.Kind() != output.JsonFormat) {
-		spinner, ctx = spin.GetOrCreateSpinner(ctx, m.console.Handles().Stdout, title)
+		spinner, ctx = input.GetOrCreateSpinner(ctx, m.console, title)
 		defer spinner.Stop()
 		defer m.console.SetWriter(nil)
 
@@ -4,7 +4,11 @@
 package provisioning_test
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"net/http"
 	"strings"
 	"testing"
 
@@ -124,3 +128,74 @@ func TestManagerDestroyWithNegativeConfirmation(t *testing.T) {
 	require.NotNil(t, err)
 	require.Contains(t, mockContext.Console.Output(), "Are you sure you want to destroy?")
 }
+
+func TestManagerFindDeploymentInProgress(t *testing.T) {
+	env := environment.EphemeralWithValues("test-env", map[string]string{
+		"AZURE_LOCATION":        "eastus2",
+		"AZURE_SUBSCRIPTION_ID": "SUBSCRIPTION_ID",
+	})
+	options := Options{Provider: "test"}
+	interactive := false
+
+	mockDeploymentsList := func(mockContext *mocks.MockContext, deployments string) {
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && strings.HasSuffix(
+				request.URL.Path,
+				"/subscriptions/SUBSCRIPTION_ID/providers/Microsoft.Resources/deployments/",
+			)
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return &http.Response{
+				Request:    request,
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(bytes.NewBufferString(fmt.Sprintf(`{"value": [%s]}`, deployments))),
+			}, nil
+		})
+	}
+
+	t.Run("InProgress", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockDeploymentsList(mockContext, `
+			{"name": "test-env-0123abcd-1700000000",
+			 "properties": {"provisioningState": "Running", "timestamp": "2023-11-14T22:13:20Z"}},
+			{"name": "test-env-4567cdef-1700000100",
+			 "properties": {"provisioningState": "Running", "timestamp": "2023-11-14T22:15:00Z"}},
+			{"name": "test-env-89abcdef-1700000200",
+			 "properties": {"provisioningState": "Succeeded", "timestamp": "2023-11-14T22:16:40Z"}},
+			{"name": "other-env-0123abcd-1700000300",
+			 "properties": {"provisioningState": "Running", "timestamp": "2023-11-14T22:18:20Z"}}`)
+
+		mgr, _ := NewManager(*mockContext.Context, env, "", options, interactive)
+
+		deploymentName, err := mgr.FindDeploymentInProgress(*mockContext.Context)
+		require.NoError(t, err)
+		require.Equal(t, "test-env-4567cdef-1700000100", deploymentName)
+	})
+
+	t.Run("Tagged", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockDeploymentsList(mockContext, `
+			{"name": "test-env-0123abcd-1700000000", "tags": {"azd-env-name": "test-env-eu"},
+			 "properties": {"provisioningState": "Running", "timestamp": "2023-11-14T22:15:00Z"}},
+			{"name": "pipeline-deployment", "tags": {"azd-env-name": "test-env"},
+			 "properties": {"provisioningState": "Running", "timestamp": "2023-11-14T22:13:20Z"}}`)
+
+		mgr, _ := NewManager(*mockContext.Context, env, "", options, interactive)
+
+		deploymentName, err := mgr.FindDeploymentInProgress(*mockContext.Context)
+		require.NoError(t, err)
+		require.Equal(t, "pipeline-deployment", deploymentName)
+	})
+
+	t.Run("NoneInProgress", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockDeploymentsList(mockContext, `
+			{"name": "test-env", "properties": {"provisioningState": "Failed"}}`)
+
+		mgr, _ := NewManager(*mockContext.Context, env, "", options, interactive)
+
+		deploymentName, err := mgr.FindDeploymentInProgress(*mockContext.Context)
+		require.NoError(t, err)
+		require.Empty(t, deploymentName)
+	})
+}
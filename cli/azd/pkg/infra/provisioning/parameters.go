@@ -0,0 +1,123 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ParameterOverrides are values of deployment parameters set on the command line, merged over the values resolved from
+// the parameters file of the module. The values set with `--parameter` are strings, converted to the type of the
+// parameter by the provider.
+type ParameterOverrides map[string]any
+
+// NewParameterOverrides reads the parameters of the file at filePath, when set, then the name=value pairs of values,
+// which take precedence over the file.
+//
+// The file is a JSON object of parameter names to values, or a deployment parameters file like main.parameters.json.
+func NewParameterOverrides(values []string, filePath string) (ParameterOverrides, error) {
+	overrides := ParameterOverrides{}
+
+	if filePath != "" {
+		fileOverrides, err := readParametersFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		for name, value := range fileOverrides {
+			overrides[name] = value
+		}
+	}
+
+	for _, pair := range values {
+		name, value, has := strings.Cut(pair, "=")
+		if !has || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid parameter '%s', parameters are set as <name>=<value>", pair)
+		}
+
+		overrides[strings.TrimSpace(name)] = value
+	}
+
+	return overrides, nil
+}
+
+func readParametersFile(filePath string) (map[string]any, error) {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading parameters file: %w", err)
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(contents, &values); err != nil {
+		return nil, fmt.Errorf("parsing parameters file %s: %w", filePath, err)
+	}
+
+	_, hasSchema := values["$schema"]
+	_, hasContentVersion := values["contentVersion"]
+	rawParameters, hasParameters := values["parameters"]
+
+	// A deployment parameters file holds the value of each parameter in its "value" property
+	if hasParameters && (hasSchema || hasContentVersion) {
+		var parameters map[string]struct {
+			Value any `json:"value"`
+		}
+		if err := json.Unmarshal(rawParameters, &parameters); err != nil {
+			return nil, fmt.Errorf("parsing parameters of parameters file %s: %w", filePath, err)
+		}
+
+		result := make(map[string]any, len(parameters))
+		for name, parameter := range parameters {
+			result[name] = parameter.Value
+		}
+
+		return result, nil
+	}
+
+	result := make(map[string]any, len(values))
+	for name, value := range values {
+		var parsed any
+		if err := json.Unmarshal(value, &parsed); err != nil {
+			return nil, fmt.Errorf("parsing parameter '%s' of parameters file %s: %w", name, filePath, err)
+		}
+
+		result[name] = parsed
+	}
+
+	return result, nil
+}
+
+// ConvertParameterValue converts a value set with `--parameter` to the type of the parameter. Values of other types,
+// read from a parameters file, are returned as is.
+func ConvertParameterValue(name string, paramType ParameterType, value any) (any, error) {
+	text, isString := value.(string)
+	if !isString || paramType == ParameterTypeString {
+		return value, nil
+	}
+
+	var converted any
+	if err := json.Unmarshal([]byte(text), &converted); err != nil {
+		return nil, fmt.Errorf("invalid value '%s' for %s parameter '%s': %w", text, paramType, name, err)
+	}
+
+	valid := false
+	switch converted.(type) {
+	case float64:
+		valid = paramType == ParameterTypeNumber
+	case bool:
+		valid = paramType == ParameterTypeBoolean
+	case map[string]any:
+		valid = paramType == ParameterTypeObject
+	case []any:
+		valid = paramType == ParameterTypeArray
+	}
+
+	if !valid {
+		return nil, fmt.Errorf("invalid value '%s' for %s parameter '%s'", text, paramType, name)
+	}
+
+	return converted, nil
+}
@@ -0,0 +1,91 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewParameterOverrides(t *testing.T) {
+	t.Run("Values", func(t *testing.T) {
+		overrides, err := NewParameterOverrides([]string{"location=westus", "tags={\"a\":\"b=c\"}"}, "")
+		require.NoError(t, err)
+		require.Equal(t, ParameterOverrides{"location": "westus", "tags": "{\"a\":\"b=c\"}"}, overrides)
+	})
+
+	t.Run("InvalidValue", func(t *testing.T) {
+		_, err := NewParameterOverrides([]string{"location"}, "")
+		require.Error(t, err)
+
+		_, err = NewParameterOverrides([]string{"=westus"}, "")
+		require.Error(t, err)
+	})
+
+	t.Run("File", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "overrides.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"location": "eastus", "count": 2, "name": "app"}`), 0600))
+
+		overrides, err := NewParameterOverrides([]string{"location=westus"}, path)
+		require.NoError(t, err)
+		require.Equal(t, ParameterOverrides{"location": "westus", "count": float64(2), "name": "app"}, overrides)
+	})
+
+	t.Run("DeploymentParametersFile", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "main.parameters.json")
+		contents := `{
+			"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentParameters.json#",
+			"contentVersion": "1.0.0.0",
+			"parameters": {
+				"location": { "value": "eastus" },
+				"enabled": { "value": true }
+			}
+		}`
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+
+		overrides, err := NewParameterOverrides(nil, path)
+		require.NoError(t, err)
+		require.Equal(t, ParameterOverrides{"location": "eastus", "enabled": true}, overrides)
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		_, err := NewParameterOverrides(nil, filepath.Join(t.TempDir(), "missing.json"))
+		require.Error(t, err)
+	})
+}
+
+func TestConvertParameterValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		paramType ParameterType
+		value     any
+		expected  any
+	}{
+		{"String", ParameterTypeString, "3", "3"},
+		{"Number", ParameterTypeNumber, "3", float64(3)},
+		{"Boolean", ParameterTypeBoolean, "true", true},
+		{"Object", ParameterTypeObject, `{"a": 1}`, map[string]any{"a": float64(1)}},
+		{"Array", ParameterTypeArray, `["a"]`, []any{"a"}},
+		{"FromFile", ParameterTypeNumber, float64(3), float64(3)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := ConvertParameterValue("param", test.paramType, test.value)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, actual)
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := ConvertParameterValue("param", ParameterTypeNumber, "three")
+		require.Error(t, err)
+
+		_, err = ConvertParameterValue("param", ParameterTypeBoolean, "3")
+		require.Error(t, err)
+	})
+}
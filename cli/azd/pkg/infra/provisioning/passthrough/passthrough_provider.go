@@ -0,0 +1,330 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package passthrough contains an implementation of provider.Provider for infrastructure provisioned by another tool.
+// This provider is registered for use when this package is imported, and can be imported for side effects only to
+// register the provider, e.g.:
+//
+// require(
+//
+//	_ "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning/passthrough"
+//
+// )
+package passthrough
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// PassthroughProvider imports the outputs of infrastructure provisioned by another tool into the environment, either
+// from an existing deployment or from an outputs file, without deploying anything. Projects that only deploy their
+// services still get the environment values their services and hooks depend on.
+type PassthroughProvider struct {
+	env         *environment.Environment
+	projectPath string
+	options     PassthroughOptions
+	azCli       azcli.AzCli
+}
+
+// Name gets the name of the infra provider
+func (p *PassthroughProvider) Name() string {
+	return "Passthrough"
+}
+
+func (p *PassthroughProvider) RequiredExternalTools() []tools.ExternalTool {
+	if p.options.Deployment == "" {
+		return []tools.ExternalTool{}
+	}
+
+	return []tools.ExternalTool{p.azCli}
+}
+
+func (p *PassthroughProvider) State(
+	ctx context.Context,
+	scope infra.Scope,
+) *async.InteractiveTaskWithProgress[*StateResult, *StateProgress] {
+	return async.RunInteractiveTaskWithProgress(
+		func(asyncContext *async.InteractiveTaskContextWithProgress[*StateResult, *StateProgress]) {
+			asyncContext.SetProgress(&StateProgress{Message: p.importMessage(), Timestamp: time.Now()})
+
+			state, err := p.state(ctx)
+			if err != nil {
+				asyncContext.SetError(err)
+				return
+			}
+
+			asyncContext.SetResult(&StateResult{State: state})
+		})
+}
+
+// Plans nothing, the infrastructure is provisioned by another tool
+func (p *PassthroughProvider) Plan(
+	ctx context.Context,
+) *async.InteractiveTaskWithProgress[*DeploymentPlan, *DeploymentPlanningProgress] {
+	return async.RunInteractiveTaskWithProgress(
+		func(asyncContext *async.InteractiveTaskContextWithProgress[*DeploymentPlan, *DeploymentPlanningProgress]) {
+			asyncContext.SetProgress(&DeploymentPlanningProgress{
+				Message:   "Skipping planning, the infrastructure is provisioned by another tool",
+				Timestamp: time.Now(),
+			})
+
+			params := make(map[string]InputParameter)
+			if location := p.env.GetLocation(); location != "" {
+				params["location"] = InputParameter{Value: location}
+			}
+
+			asyncContext.SetResult(&DeploymentPlan{
+				Deployment: Deployment{
+					Parameters: params,
+					Outputs:    make(map[string]OutputParameter),
+				},
+			})
+		})
+}
+
+// Imports the outputs of the infrastructure instead of deploying it, the scope is ignored
+func (p *PassthroughProvider) Deploy(
+	ctx context.Context,
+	plan *DeploymentPlan,
+	scope infra.Scope,
+) *async.InteractiveTaskWithProgress[*DeployResult, *DeployProgress] {
+	return async.RunInteractiveTaskWithProgress(
+		func(asyncContext *async.InteractiveTaskContextWithProgress[*DeployResult, *DeployProgress]) {
+			asyncContext.SetProgress(&DeployProgress{Message: p.importMessage(), Timestamp: time.Now()})
+
+			state, err := p.state(ctx)
+			if err != nil {
+				asyncContext.SetError(err)
+				return
+			}
+
+			asyncContext.SetResult(&DeployResult{
+				Deployment: &Deployment{
+					Parameters: plan.Deployment.Parameters,
+					Outputs:    state.Outputs,
+				},
+			})
+		})
+}
+
+// Fails, the infrastructure is deleted by the tool that provisioned it
+func (p *PassthroughProvider) Destroy(
+	ctx context.Context,
+	deployment *Deployment,
+	options DestroyOptions,
+) *async.InteractiveTaskWithProgress[*DestroyResult, *DestroyProgress] {
+	return async.RunInteractiveTaskWithProgress(
+		func(asyncContext *async.InteractiveTaskContextWithProgress[*DestroyResult, *DestroyProgress]) {
+			asyncContext.SetError(errors.New(
+				"the infrastructure is provisioned by another tool with the passthrough provider, delete it with that tool"))
+		})
+}
+
+func (p *PassthroughProvider) importMessage() string {
+	if p.options.Deployment != "" {
+		return fmt.Sprintf("Importing the outputs of deployment %s", p.options.Deployment)
+	}
+
+	return fmt.Sprintf("Reading the outputs of %s", p.options.OutputsFile)
+}
+
+// state returns the outputs of the existing deployment, along with its resources, or the outputs of the outputs file
+func (p *PassthroughProvider) state(ctx context.Context) (*State, error) {
+	if p.options.Deployment == "" {
+		outputs, err := readOutputsFile(filepath.Join(p.projectPath, p.options.OutputsFile))
+		if err != nil {
+			return nil, err
+		}
+
+		return &State{Outputs: outputs, Resources: []Resource{}}, nil
+	}
+
+	var scope infra.Scope
+	if p.options.ResourceGroup != "" {
+		scope = infra.NewResourceGroupScope(
+			ctx, p.env.GetSubscriptionId(), p.options.ResourceGroup, p.options.Deployment)
+	} else {
+		scope = infra.NewSubscriptionScope(ctx, p.env.GetLocation(), p.env.GetSubscriptionId(), p.options.Deployment)
+	}
+
+	armDeployment, err := scope.GetDeployment(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving deployment %s: %w", p.options.Deployment, err)
+	}
+
+	state := &State{Outputs: map[string]OutputParameter{}, Resources: []Resource{}}
+	if armDeployment.Properties == nil {
+		return state, nil
+	}
+
+	for _, res := range armDeployment.Properties.OutputResources {
+		state.Resources = append(state.Resources, Resource{Id: *res.ID})
+	}
+
+	if outputs, ok := armDeployment.Properties.Outputs.(map[string]interface{}); ok {
+		state.Outputs, err = parseOutputs(outputs)
+		if err != nil {
+			return nil, fmt.Errorf("reading the outputs of deployment %s: %w", p.options.Deployment, err)
+		}
+	}
+
+	return state, nil
+}
+
+// readOutputsFile reads the outputs of a JSON file, either written by `az deployment show --query properties.outputs` or
+// `terraform output -json`, i.e. {"API_URL": {"type": "String", "value": "https://..."}}, or plain values, i.e.
+// {"API_URL": "https://..."}
+func readOutputsFile(path string) (map[string]OutputParameter, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading outputs file: %w", err)
+	}
+
+	var outputs map[string]interface{}
+	if err := json.Unmarshal(content, &outputs); err != nil {
+		return nil, fmt.Errorf("parsing outputs file %s: %w", path, err)
+	}
+
+	result, err := parseOutputs(outputs)
+	if err != nil {
+		return nil, fmt.Errorf("parsing outputs file %s: %w", path, err)
+	}
+
+	return result, nil
+}
+
+// parseOutputs converts the outputs of ARM or terraform, or plain values, to output parameters. Secure outputs of ARM
+// have no value, while sensitive outputs of terraform do.
+func parseOutputs(outputs map[string]interface{}) (map[string]OutputParameter, error) {
+	result := make(map[string]OutputParameter, len(outputs))
+
+	for name, output := range outputs {
+		// Secure outputs of ARM only have a type
+		declared, ok := output.(map[string]interface{})
+		_, hasValue := declared["value"]
+		_, hasType := declared["type"]
+		if !ok || (!hasValue && !hasType) {
+			result[name] = OutputParameter{Type: valueType(output), Value: output}
+			continue
+		}
+
+		// The type of complex terraform outputs is an array, i.e. ["list", "string"]
+		typeName, _ := declared["type"].(string)
+		if types, ok := declared["type"].([]interface{}); ok && len(types) > 0 {
+			typeName, _ = types[0].(string)
+		}
+
+		sensitive, _ := declared["sensitive"].(bool)
+		typeName = strings.ToLower(typeName)
+
+		parameterType, err := outputType(typeName, declared["value"])
+		if err != nil {
+			return nil, fmt.Errorf("output %s: %w", name, err)
+		}
+
+		result[name] = OutputParameter{
+			Type:   parameterType,
+			Value:  declared["value"],
+			Secure: sensitive || typeName == "securestring" || typeName == "secureobject",
+		}
+	}
+
+	return result, nil
+}
+
+// outputType maps the type of an ARM or terraform output to the interface type, or infers it from the value when the
+// output has no type
+func outputType(typeName string, value interface{}) (ParameterType, error) {
+	switch typeName {
+	case "":
+		return valueType(value), nil
+	case "string", "securestring":
+		return ParameterTypeString, nil
+	case "bool":
+		return ParameterTypeBoolean, nil
+	case "int", "number":
+		return ParameterTypeNumber, nil
+	case "object", "secureobject", "map":
+		return ParameterTypeObject, nil
+	case "array", "list", "tuple", "set":
+		return ParameterTypeArray, nil
+	default:
+		return "", fmt.Errorf("unexpected output type '%s'", typeName)
+	}
+}
+
+func valueType(value interface{}) ParameterType {
+	switch value.(type) {
+	case bool:
+		return ParameterTypeBoolean
+	case float64:
+		return ParameterTypeNumber
+	case map[string]interface{}:
+		return ParameterTypeObject
+	case []interface{}:
+		return ParameterTypeArray
+	default:
+		return ParameterTypeString
+	}
+}
+
+// NewPassthroughProvider creates a new instance of a Passthrough Infra provider, importing either an existing deployment
+// or an outputs file
+func NewPassthroughProvider(
+	ctx context.Context,
+	env *environment.Environment,
+	projectPath string,
+	infraOptions Options,
+) (*PassthroughProvider, error) {
+	options := infraOptions.Passthrough
+
+	switch {
+	case options.Deployment == "" && options.OutputsFile == "":
+		return nil, errors.New(
+			"the passthrough provider imports an existing deployment or an outputs file, set infra.passthrough.deployment " +
+				"or infra.passthrough.outputsFile in azure.yaml")
+	case options.Deployment != "" && options.OutputsFile != "":
+		return nil, errors.New("infra.passthrough.deployment and infra.passthrough.outputsFile can't both be set")
+	case options.ResourceGroup != "" && options.Deployment == "":
+		return nil, errors.New("infra.passthrough.resourceGroup is only set along with infra.passthrough.deployment")
+	}
+
+	return &PassthroughProvider{
+		env:         env,
+		projectPath: projectPath,
+		options:     options,
+		azCli:       azcli.GetAzCli(ctx),
+	}, nil
+}
+
+func init() {
+	err := RegisterProvider(
+		Passthrough,
+		func(ctx context.Context, env *environment.Environment, projectPath string, options Options) (Provider, error) {
+			provider, err := NewPassthroughProvider(ctx, env, projectPath, options)
+			if err != nil {
+				return nil, err
+			}
+
+			return provider, nil
+		},
+	)
+
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package passthrough
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPassthroughProviderOutputsFile(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	projectPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "outputs.json"), []byte(`{
+		"API_URL": {"type": "String", "value": "https://api.contoso.com"},
+		"REPLICAS": {"type": "Int", "value": 3},
+		"DB_PASSWORD": {"sensitive": true, "type": "string", "value": "secret"},
+		"ADMIN_KEY": {"type": "SecureString"},
+		"REGIONS": {"sensitive": false, "type": ["list", "string"], "value": ["westus2", "eastus"]},
+		"WEB_URL": "https://www.contoso.com"
+	}`), 0600))
+
+	env := environment.EphemeralWithValues("test-env", map[string]string{
+		environment.LocationEnvVarName: "westus2",
+	})
+	provider, err := NewPassthroughProvider(*mockContext.Context, env, projectPath, Options{
+		Provider:    Passthrough,
+		Passthrough: PassthroughOptions{OutputsFile: "outputs.json"},
+	})
+	require.NoError(t, err)
+	require.Empty(t, provider.RequiredExternalTools())
+
+	plan, err := await(provider.Plan(*mockContext.Context))
+	require.NoError(t, err)
+	require.Equal(t, "westus2", plan.Deployment.Parameters["location"].Value)
+
+	result, err := await(provider.Deploy(*mockContext.Context, plan, nil))
+	require.NoError(t, err)
+	require.Equal(t, map[string]OutputParameter{
+		"API_URL":     {Type: ParameterTypeString, Value: "https://api.contoso.com"},
+		"REPLICAS":    {Type: ParameterTypeNumber, Value: float64(3)},
+		"DB_PASSWORD": {Type: ParameterTypeString, Value: "secret", Secure: true},
+		"ADMIN_KEY":   {Type: ParameterTypeString, Secure: true},
+		"REGIONS":     {Type: ParameterTypeArray, Value: []interface{}{"westus2", "eastus"}},
+		"WEB_URL":     {Type: ParameterTypeString, Value: "https://www.contoso.com"},
+	}, result.Deployment.Outputs)
+
+	_, err = await(provider.Destroy(*mockContext.Context, result.Deployment, DestroyOptions{}))
+	require.Error(t, err)
+}
+
+func TestPassthroughProviderInvalidOutputsFile(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	projectPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "outputs.json"), []byte(`{
+		"API_URL": {"type": "Uri", "value": "https://api.contoso.com"}
+	}`), 0600))
+
+	provider, err := NewPassthroughProvider(
+		*mockContext.Context, environment.Ephemeral(), projectPath, Options{
+			Passthrough: PassthroughOptions{OutputsFile: "outputs.json"},
+		})
+	require.NoError(t, err)
+
+	_, err = await(provider.State(*mockContext.Context, nil))
+	require.ErrorContains(t, err, "unexpected output type 'uri'")
+}
+
+func TestNewPassthroughProviderOptions(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	tests := []struct {
+		name    string
+		options PassthroughOptions
+		valid   bool
+	}{
+		{"Deployment", PassthroughOptions{Deployment: "infra"}, true},
+		{"ResourceGroupDeployment", PassthroughOptions{Deployment: "infra", ResourceGroup: "rg-infra"}, true},
+		{"OutputsFile", PassthroughOptions{OutputsFile: "outputs.json"}, true},
+		{"None", PassthroughOptions{}, false},
+		{"Both", PassthroughOptions{Deployment: "infra", OutputsFile: "outputs.json"}, false},
+		{"ResourceGroupOnly", PassthroughOptions{ResourceGroup: "rg-infra", OutputsFile: "outputs.json"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewPassthroughProvider(
+				*mockContext.Context, environment.Ephemeral(), t.TempDir(), Options{Passthrough: test.options})
+			if test.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func await[R comparable, P comparable](task *async.InteractiveTaskWithProgress[R, P]) (R, error) {
+	go func() {
+		for range task.Progress() {
+		}
+	}()
+
+	return task.Await()
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
@@ -34,21 +35,80 @@ const (
 	Terraform ProviderKind = "terraform"
 	Pulumi    ProviderKind = "pulumi"
 	Test      ProviderKind = "test"
+	// Passthrough doesn't deploy, it imports the outputs of infrastructure provisioned by another tool
+	Passthrough ProviderKind = "passthrough"
 )
 
 type Options struct {
 	Provider ProviderKind `yaml:"provider"`
 	Path     string       `yaml:"path"`
 	Module   string       `yaml:"module"`
+	// The values of the parameters set with `--parameter` and `--parameters-file`, not read from azure.yaml
+	Parameters ParameterOverrides `yaml:"-"`
+	// The infrastructure modules of the services composed into the root deployment, by service name, set from the
+	// `infraModule` of the services in azure.yaml
+	ServiceModules map[string]string `yaml:"-"`
+	// The infrastructure imported by the passthrough provider
+	Passthrough PassthroughOptions `yaml:"passthrough,omitempty"`
+}
+
+// PassthroughOptions names the infrastructure provisioned by another tool, i.e. a pipeline owned by another team, which
+// the passthrough provider imports the outputs of instead of deploying it. Either Deployment or OutputsFile is set.
+type PassthroughOptions struct {
+	// The name of an existing deployment of the subscription of the environment, or of ResourceGroup when set
+	Deployment    string `yaml:"deployment,omitempty"`
+	ResourceGroup string `yaml:"resourceGroup,omitempty"`
+	// The path of a JSON file of outputs, relative to the project, i.e. written by `terraform output -json`
+	OutputsFile string `yaml:"outputsFile,omitempty"`
 }
 
 type DeploymentPlan struct {
 	Deployment Deployment
 
+	// The addresses of the resources the deployment deletes, for the providers able to plan deletions.
+	DeletedResources []string
+
 	// Additional information about deployment, provider-specific.
 	Details interface{}
 }
 
+// PolicyViolation is a resource of a deployment denied by an Azure Policy assigned to the target subscription
+type PolicyViolation struct {
+	ResourceId string
+	// The display name of the policy assignment, or its id when it has no display name
+	PolicyAssignment string
+	Reason           string
+}
+
+// PolicyChecker is implemented by the providers able to evaluate a deployment against the Azure Policies assigned to
+// the target scope before deploying it
+type PolicyChecker interface {
+	CheckPolicies(
+		ctx context.Context,
+		location string,
+		plan *DeploymentPlan,
+		scope infra.Scope,
+	) ([]PolicyViolation, error)
+}
+
+// DeploymentAttacher is implemented by the providers able to wait for a deployment in progress, started by another azd
+// process, instead of starting a duplicate deployment
+type DeploymentAttacher interface {
+	Attach(
+		ctx context.Context,
+		plan *DeploymentPlan,
+		scope infra.Scope,
+	) *async.InteractiveTaskWithProgress[*DeployResult, *DeployProgress]
+}
+
+// DeploymentRetrier is implemented by the providers able to run a deployment again after some of its operations failed,
+// the resources already deployed are left unchanged by the incremental deployment
+type DeploymentRetrier interface {
+	// FailedOperations returns the failed operations on the resources of the deployment of the scope, including the
+	// resources of its nested deployments
+	FailedOperations(ctx context.Context, scope infra.Scope) ([]*armresources.DeploymentOperation, error)
+}
+
 type DeploymentPlanningProgress struct {
 	Message   string
 	Timestamp time.Time
@@ -61,6 +121,8 @@ type DeployResult struct {
 type DestroyResult struct {
 	Resources []azcli.AzCliResource
 	Outputs   map[string]OutputParameter
+	// The resource groups that were not deleted because of a lock
+	SkippedResourceGroups []string
 }
 
 type DeployProgress struct {
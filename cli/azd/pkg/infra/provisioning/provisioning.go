@@ -4,20 +4,38 @@
 package provisioning
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/drone/envsubst"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
+// SecureOutputMask replaces the values of the secure outputs displayed to the user
+const SecureOutputMask = "*****"
+
+// UpdateEnvironment writes the values of the outputs to the environment. The values of secure outputs are written by
+// StoreSecureOutputs.
 func UpdateEnvironment(env *environment.Environment, outputs map[string]OutputParameter) error {
 	if len(outputs) > 0 {
 		for key, param := range outputs {
+			if param.Secure {
+				continue
+			}
+
 			env.Values[key] = fmt.Sprintf("%v", param.Value)
 		}
 
@@ -29,6 +47,92 @@ func UpdateEnvironment(env *environment.Environment, outputs map[string]OutputPa
 	return nil
 }
 
+// StoreSecureOutputs stores the values of the secure outputs as secrets of the key vault of the environment, named after
+// the outputs with '_' replaced by '-', and removes their values from the environment once stored. When the environment
+// has no key vault, the values are written to the environment like the other outputs, with a warning. ARM doesn't
+// return the values of secure outputs, only the sensitive outputs of terraform have a value.
+func StoreSecureOutputs(
+	ctx context.Context,
+	azCli azcli.AzCli,
+	env *environment.Environment,
+	outputs map[string]OutputParameter,
+) error {
+	secrets := map[string]string{}
+	for key, param := range outputs {
+		if !param.Secure || param.Value == nil {
+			continue
+		}
+
+		value, isString := param.Value.(string)
+		if !isString {
+			encoded, err := json.Marshal(param.Value)
+			if err != nil {
+				return fmt.Errorf("encoding secure output %s: %w", key, err)
+			}
+
+			value = string(encoded)
+		}
+
+		secrets[key] = value
+	}
+
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	console := input.GetConsole(ctx)
+	names := maps.Keys(secrets)
+	slices.Sort(names)
+
+	endpoint := env.GetKeyVaultEndpoint()
+	if endpoint == "" {
+		// The services of the project may read the values from the environment
+		for _, key := range names {
+			env.Values[key] = secrets[key]
+		}
+
+		if err := env.Save(); err != nil {
+			return fmt.Errorf("writing environment: %w", err)
+		}
+
+		console.Message(ctx, output.WithWarningFormat(
+			"The secure outputs %s are written in plaintext to the environment, it has no key vault. Set %s as an "+
+				"output of your infrastructure to store them as secrets.",
+			strings.Join(names, ", "),
+			environment.KeyVaultEndpointEnvVarName,
+		))
+		return nil
+	}
+
+	for _, key := range names {
+		secretName := secureOutputSecretName(key)
+		if err := azCli.SetKeyVaultSecret(ctx, endpoint, secretName, secrets[key]); err != nil {
+			return fmt.Errorf("storing secure output %s: %w", key, err)
+		}
+
+		log.Printf("stored secure output %s as secret %s of %s", key, secretName, endpoint)
+	}
+
+	// Removes the plaintext values written before the environment had a key vault, or by previous versions of azd
+	for _, key := range names {
+		delete(env.Values, key)
+	}
+
+	if err := env.Save(); err != nil {
+		return fmt.Errorf("writing environment: %w", err)
+	}
+
+	console.Message(ctx, fmt.Sprintf(
+		"Stored the secure outputs %s as secrets of key vault %s.", strings.Join(names, ", "), endpoint))
+	return nil
+}
+
+// secureOutputSecretName returns the name of the key vault secret a secure output is stored as, key vault only accepts
+// letters, digits and '-' in the names of secrets.
+func secureOutputSecretName(outputName string) string {
+	return strings.ReplaceAll(outputName, "_", "-")
+}
+
 // Copies the an input parameters file templateFilePath to inputFilePath after replacing environment variable references in
 // the contents```
 func CreateInputParametersFile(templateFilePath string, inputFilePath string, envValues map[string]string) error {
@@ -89,9 +193,15 @@ func NewEnvRefreshResultFromState(state *State) contracts.EnvRefreshResult {
 	}
 
 	for k, v := range state.Outputs {
+		value := v.Value
+		if v.Secure {
+			value = SecureOutputMask
+		}
+
 		result.Outputs[k] = contracts.EnvRefreshOutputParameter{
-			Type:  mapType(v.Type),
-			Value: v.Value,
+			Type:   mapType(v.Type),
+			Value:  value,
+			Secure: v.Secure,
 		}
 	}
 
@@ -103,3 +213,24 @@ func NewEnvRefreshResultFromState(state *State) contracts.EnvRefreshResult {
 
 	return result
 }
+
+// NewProvisionTimingFromResourceTimings creates a ProvisionTiming from the timings of the provisioned resources
+func NewProvisionTimingFromResourceTimings(timings []infra.ResourceTiming) *contracts.ProvisionTiming {
+	result := &contracts.ProvisionTiming{
+		TotalSeconds: infra.TotalDuration(timings).Seconds(),
+		Resources:    make([]contracts.ProvisionResourceTiming, len(timings)),
+	}
+
+	for idx, timing := range timings {
+		result.Resources[idx] = contracts.ProvisionResourceTiming{
+			Id:              timing.Id,
+			Name:            timing.Name,
+			Type:            string(timing.Type),
+			StartTime:       timing.StartTime,
+			DurationSeconds: timing.Duration.Seconds(),
+			Slowest:         timing.Slowest,
+		}
+	}
+
+	return result
+}
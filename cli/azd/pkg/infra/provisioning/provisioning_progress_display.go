@@ -66,8 +66,9 @@ func (display *ProvisioningProgressDisplay) ReportProgress(ctx context.Context)
 			output.WithLinkFormat("https://portal.azure.com/#blade/HubsExtension/DeploymentDetailsBlade/overview/id/%s\n"),
 			url.PathEscape(display.scope.DeploymentUrl()),
 		)
-		display.console.Message(
+		display.console.MessageAt(
 			ctx,
+			input.VerbosityNormal,
 			fmt.Sprintf(
 				"%s\n\nYou can view detailed progress in the Azure Portal:\n%s",
 				deploymentStartedDisplayMessage,
@@ -141,8 +142,9 @@ func (display *ProvisioningProgressDisplay) logNewlyCreatedResources(
 		// Don't log resource types for Azure resources that we do not have a translation of the resource type for.
 		// This will be improved on in a future iteration.
 		if resourceTypeDisplayName != "" {
-			display.console.Message(
+			display.console.MessageAt(
 				ctx,
+				input.VerbosityNormal,
 				formatCreatedResourceLog(resourceTypeDisplayName, *newResource.Properties.TargetResource.ResourceName),
 			)
 			resourceTypeName = resourceTypeDisplayName
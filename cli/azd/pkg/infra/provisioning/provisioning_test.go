@@ -0,0 +1,96 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateEnvironmentSecureOutputs(t *testing.T) {
+	env := environment.EphemeralWithValues("test-env", map[string]string{
+		"DB_PASSWORD": "written-by-a-previous-version",
+	})
+
+	err := UpdateEnvironment(env, map[string]OutputParameter{
+		"WEBSITE_URL": {Type: ParameterTypeString, Value: "https://app.azurewebsites.net"},
+		"DB_PASSWORD": {Type: ParameterTypeString, Value: "password", Secure: true},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "https://app.azurewebsites.net", env.Values["WEBSITE_URL"])
+	// The value is only removed once it's stored in the key vault
+	require.Equal(t, "written-by-a-previous-version", env.Values["DB_PASSWORD"])
+}
+
+func TestStoreSecureOutputsWithoutKeyVault(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	env := environment.EphemeralWithValues("test-env", nil)
+	azCli := azcli.GetAzCli(*mockContext.Context)
+
+	err := StoreSecureOutputs(*mockContext.Context, azCli, env, map[string]OutputParameter{
+		"DB_PASSWORD": {Type: ParameterTypeString, Value: "password", Secure: true},
+	})
+	require.NoError(t, err)
+
+	// The value is kept in the environment for the services reading it
+	require.Equal(t, "password", env.Values["DB_PASSWORD"])
+	require.Len(t, mockContext.Console.Output(), 1)
+	require.Contains(t, mockContext.Console.Output()[0], "DB_PASSWORD")
+	require.Contains(t, mockContext.Console.Output()[0], environment.KeyVaultEndpointEnvVarName)
+}
+
+func TestStoreSecureOutputsFailure(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return strings.Contains(request.URL.Host, "vault")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateEmptyHttpResponse(request, http.StatusForbidden)
+	})
+
+	env := environment.EphemeralWithValues("test-env", map[string]string{
+		environment.KeyVaultEndpointEnvVarName: "https://kv-test.vault.azure.net/",
+		"DB_PASSWORD":                          "written-by-a-previous-version",
+	})
+	azCli := azcli.GetAzCli(*mockContext.Context)
+
+	err := StoreSecureOutputs(*mockContext.Context, azCli, env, map[string]OutputParameter{
+		"DB_PASSWORD": {Type: ParameterTypeString, Value: "password", Secure: true},
+	})
+	require.ErrorContains(t, err, "storing secure output DB_PASSWORD")
+
+	// The plaintext value isn't removed since the secret isn't stored
+	require.Equal(t, "written-by-a-previous-version", env.Values["DB_PASSWORD"])
+}
+
+func TestSecureOutputSecretName(t *testing.T) {
+	require.Equal(t, "DB-PASSWORD", secureOutputSecretName("DB_PASSWORD"))
+}
+
+func TestNewEnvRefreshResultFromStateSecureOutputs(t *testing.T) {
+	result := NewEnvRefreshResultFromState(&State{
+		Outputs: map[string]OutputParameter{
+			"WEBSITE_URL": {Type: ParameterTypeString, Value: "https://app.azurewebsites.net"},
+			"DB_PASSWORD": {Type: ParameterTypeString, Value: "password", Secure: true},
+		},
+	})
+
+	require.Equal(t, contracts.EnvRefreshOutputParameter{
+		Type:  contracts.EnvRefreshOutputTypeString,
+		Value: "https://app.azurewebsites.net",
+	}, result.Outputs["WEBSITE_URL"])
+	require.Equal(t, contracts.EnvRefreshOutputParameter{
+		Type:   contracts.EnvRefreshOutputTypeString,
+		Value:  SecureOutputMask,
+		Secure: true,
+	}, result.Outputs["DB_PASSWORD"])
+}
@@ -0,0 +1,104 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+// The number of times a deployment failing on transient errors is run again before the user is asked
+const maxTransientRetries = 3
+
+// The delay before the first retry of a deployment failing on transient errors, doubled for each retry
+var transientRetryDelay = 15 * time.Second
+
+// The status codes of the operations failing on transient errors, the error code of the operation is checked first
+var transientStatusCodes = map[string]bool{
+	"Conflict":            true,
+	"TooManyRequests":     true,
+	"RequestTimeout":      true,
+	"InternalServerError": true,
+	"BadGateway":          true,
+	"ServiceUnavailable":  true,
+	"GatewayTimeout":      true,
+}
+
+// The error codes of the operations failing on transient errors
+var transientErrorCodes = map[string]bool{
+	"AnotherOperationInProgress": true,
+	"Conflict":                   true,
+	"RetryableError":             true,
+	"TooManyRequests":            true,
+	"OperationTimedOut":          true,
+	"ServerTimeout":              true,
+	"RequestTimeout":             true,
+	"InternalServerError":        true,
+	"ServiceUnavailable":         true,
+	"GatewayTimeout":             true,
+}
+
+// IsTransientFailure returns true when the deployment operation failed on an error that may not happen again, such as a
+// conflicting operation on the resource, throttling or a timeout
+func IsTransientFailure(operation *armresources.DeploymentOperation) bool {
+	if operation.Properties == nil {
+		return false
+	}
+
+	if code := operationErrorCode(operation); code != "" {
+		return transientErrorCodes[code]
+	}
+
+	return operation.Properties.StatusCode != nil && transientStatusCodes[*operation.Properties.StatusCode]
+}
+
+// AreTransientFailures returns true when all the failed operations failed on transient errors
+func AreTransientFailures(operations []*armresources.DeploymentOperation) bool {
+	for _, operation := range operations {
+		if !IsTransientFailure(operation) {
+			return false
+		}
+	}
+
+	return len(operations) > 0
+}
+
+func operationErrorCode(operation *armresources.DeploymentOperation) string {
+	message := operation.Properties.StatusMessage
+	if message == nil || message.Error == nil || message.Error.Code == nil {
+		return ""
+	}
+
+	return *message.Error.Code
+}
+
+// failedOperationsMessage lists the failed operations with their resource and error
+func failedOperationsMessage(operations []*armresources.DeploymentOperation) string {
+	lines := make([]string, 0, len(operations))
+	for _, operation := range operations {
+		resource := "unknown resource"
+		if target := operation.Properties.TargetResource; target != nil && target.ResourceName != nil {
+			resource = *target.ResourceName
+			if target.ResourceType != nil {
+				resource = fmt.Sprintf("%s (%s)", resource, *target.ResourceType)
+			}
+		}
+
+		reason := operationErrorCode(operation)
+		if reason == "" && operation.Properties.StatusCode != nil {
+			reason = *operation.Properties.StatusCode
+		}
+		if message := operation.Properties.StatusMessage; message != nil && message.Error != nil &&
+			message.Error.Message != nil {
+			reason = fmt.Sprintf("%s: %s", reason, *message.Error.Message)
+		}
+
+		lines = append(lines, fmt.Sprintf("  - %s: %s", resource, reason))
+	}
+
+	return strings.Join(lines, "\n")
+}
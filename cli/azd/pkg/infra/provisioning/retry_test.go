@@ -0,0 +1,200 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func failedOperation(resourceName string, statusCode string, errorCode string) *armresources.DeploymentOperation {
+	operation := &armresources.DeploymentOperation{
+		Properties: &armresources.DeploymentOperationProperties{
+			ProvisioningState: convert.RefOf("Failed"),
+			StatusCode:        convert.RefOf(statusCode),
+			TargetResource: &armresources.TargetResource{
+				ResourceName: convert.RefOf(resourceName),
+				ResourceType: convert.RefOf("Microsoft.Web/sites"),
+			},
+		},
+	}
+
+	if errorCode != "" {
+		operation.Properties.StatusMessage = &armresources.StatusMessage{
+			Error: &armresources.ErrorResponse{
+				Code:    convert.RefOf(errorCode),
+				Message: convert.RefOf("the operation failed"),
+			},
+		}
+	}
+
+	return operation
+}
+
+func TestIsTransientFailure(t *testing.T) {
+	require.True(t, IsTransientFailure(failedOperation("web", "Conflict", "AnotherOperationInProgress")))
+	require.True(t, IsTransientFailure(failedOperation("web", "TooManyRequests", "")))
+	require.True(t, IsTransientFailure(failedOperation("web", "InternalServerError", "InternalServerError")))
+	// The error code wins over the status code
+	require.False(t, IsTransientFailure(failedOperation("web", "Conflict", "StorageAccountAlreadyTaken")))
+	require.False(t, IsTransientFailure(failedOperation("web", "BadRequest", "InvalidTemplate")))
+	require.False(t, IsTransientFailure(&armresources.DeploymentOperation{}))
+
+	require.True(t, AreTransientFailures([]*armresources.DeploymentOperation{
+		failedOperation("web", "Conflict", "Conflict"),
+		failedOperation("api", "GatewayTimeout", ""),
+	}))
+	require.False(t, AreTransientFailures([]*armresources.DeploymentOperation{
+		failedOperation("web", "Conflict", "Conflict"),
+		failedOperation("api", "BadRequest", "InvalidTemplate"),
+	}))
+	require.False(t, AreTransientFailures(nil))
+}
+
+func TestFailedOperationsMessage(t *testing.T) {
+	message := failedOperationsMessage([]*armresources.DeploymentOperation{
+		failedOperation("web", "Conflict", "AnotherOperationInProgress"),
+		failedOperation("api", "GatewayTimeout", ""),
+	})
+
+	require.Equal(t,
+		"  - web (Microsoft.Web/sites): AnotherOperationInProgress: the operation failed\n"+
+			"  - api (Microsoft.Web/sites): GatewayTimeout",
+		message)
+}
+
+// flakyProvider fails its first deployments with the failed operations
+type flakyProvider struct {
+	failures  int
+	deployed  int
+	operation *armresources.DeploymentOperation
+}
+
+func (p *flakyProvider) Name() string {
+	return "flaky"
+}
+
+func (p *flakyProvider) RequiredExternalTools() []tools.ExternalTool {
+	return nil
+}
+
+func (p *flakyProvider) State(
+	ctx context.Context,
+	scope infra.Scope,
+) *async.InteractiveTaskWithProgress[*StateResult, *StateProgress] {
+	return async.RunInteractiveTaskWithProgress(
+		func(asyncContext *async.InteractiveTaskContextWithProgress[*StateResult, *StateProgress]) {
+			asyncContext.SetResult(&StateResult{})
+		})
+}
+
+func (p *flakyProvider) Plan(
+	ctx context.Context,
+) *async.InteractiveTaskWithProgress[*DeploymentPlan, *DeploymentPlanningProgress] {
+	return async.RunInteractiveTaskWithProgress(
+		func(asyncContext *async.InteractiveTaskContextWithProgress[*DeploymentPlan, *DeploymentPlanningProgress]) {
+			asyncContext.SetResult(&DeploymentPlan{})
+		})
+}
+
+func (p *flakyProvider) Deploy(
+	ctx context.Context,
+	plan *DeploymentPlan,
+	scope infra.Scope,
+) *async.InteractiveTaskWithProgress[*DeployResult, *DeployProgress] {
+	return async.RunInteractiveTaskWithProgress(
+		func(asyncContext *async.InteractiveTaskContextWithProgress[*DeployResult, *DeployProgress]) {
+			p.deployed++
+			if p.deployed <= p.failures {
+				asyncContext.SetError(errors.New("deployment failed"))
+				return
+			}
+
+			asyncContext.SetResult(&DeployResult{Deployment: &plan.Deployment})
+		})
+}
+
+func (p *flakyProvider) Destroy(
+	ctx context.Context,
+	deployment *Deployment,
+	options DestroyOptions,
+) *async.InteractiveTaskWithProgress[*DestroyResult, *DestroyProgress] {
+	return async.RunInteractiveTaskWithProgress(
+		func(asyncContext *async.InteractiveTaskContextWithProgress[*DestroyResult, *DestroyProgress]) {
+			asyncContext.SetResult(&DestroyResult{})
+		})
+}
+
+func (p *flakyProvider) FailedOperations(
+	ctx context.Context,
+	scope infra.Scope,
+) ([]*armresources.DeploymentOperation, error) {
+	return []*armresources.DeploymentOperation{p.operation}, nil
+}
+
+func TestManagerDeployWithRetries(t *testing.T) {
+	delay := transientRetryDelay
+	transientRetryDelay = 0
+	defer func() { transientRetryDelay = delay }()
+
+	deploy := func(mockContext *mocks.MockContext, provider *flakyProvider, interactive bool) error {
+		manager := &Manager{
+			env:         environment.Ephemeral(),
+			provider:    provider,
+			console:     mockContext.Console,
+			interactive: interactive,
+		}
+		scope := infra.NewSubscriptionScope(*mockContext.Context, "eastus2", "SUBSCRIPTION_ID", "DEPLOYMENT_NAME")
+
+		_, err := manager.deployWithRetries(*mockContext.Context, "eastus2", &DeploymentPlan{}, scope)
+		return err
+	}
+
+	t.Run("TransientFailures", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		provider := &flakyProvider{failures: 2, operation: failedOperation("web", "Conflict", "Conflict")}
+
+		require.NoError(t, deploy(mockContext, provider, false))
+		require.Equal(t, 3, provider.deployed)
+	})
+
+	t.Run("TooManyTransientFailures", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		provider := &flakyProvider{failures: 10, operation: failedOperation("web", "Conflict", "Conflict")}
+
+		require.Error(t, deploy(mockContext, provider, false))
+		require.Equal(t, maxTransientRetries+1, provider.deployed)
+	})
+
+	t.Run("OtherFailure", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		provider := &flakyProvider{failures: 1, operation: failedOperation("web", "BadRequest", "InvalidTemplate")}
+
+		require.Error(t, deploy(mockContext, provider, false))
+		require.Equal(t, 1, provider.deployed)
+	})
+
+	t.Run("RetryConfirmed", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.Console.WhenConfirm(func(options input.ConsoleOptions) bool {
+			return strings.Contains(options.Message, "Retry the failed deployment steps?")
+		}).Respond(true)
+		provider := &flakyProvider{failures: 1, operation: failedOperation("web", "BadRequest", "InvalidTemplate")}
+
+		require.NoError(t, deploy(mockContext, provider, true))
+		require.Equal(t, 2, provider.deployed)
+	})
+}
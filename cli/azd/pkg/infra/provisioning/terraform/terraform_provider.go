@@ -26,6 +26,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/terraform"
 )
@@ -128,6 +129,11 @@ func (t *TerraformProvider) Plan(
 				return
 			}
 
+			if err := t.applyParameterOverrides(); err != nil {
+				asyncContext.SetError(fmt.Errorf("overriding parameters: %w", err))
+				return
+			}
+
 			t.console.Message(ctx, "Validating terraform template...")
 			validated, err := t.cli.Validate(ctx, modulePath)
 			if err != nil {
@@ -158,6 +164,12 @@ func (t *TerraformProvider) Plan(
 				return
 			}
 
+			deletedResources, err := t.plannedDeletions(ctx, modulePath)
+			if err != nil {
+				asyncContext.SetError(fmt.Errorf("reading terraform plan: %w", err))
+				return
+			}
+
 			deploymentDetails := TerraformDeploymentDetails{
 				ParameterFilePath: t.parametersFilePath(),
 				PlanFilePath:      t.planFilePath(),
@@ -167,8 +179,9 @@ func (t *TerraformProvider) Plan(
 			}
 
 			result := DeploymentPlan{
-				Deployment: *deployment,
-				Details:    deploymentDetails,
+				Deployment:       *deployment,
+				DeletedResources: deletedResources,
+				Details:          deploymentDetails,
 			}
 
 			asyncContext.SetResult(&result)
@@ -364,6 +377,39 @@ func (t *TerraformProvider) createDestroyArgs(isRemoteBackendConfig bool, autoAp
 	return args
 }
 
+// Sets the values of the parameters overridden with `--parameter` and `--parameters-file` in the parameters file of the
+// environment. Terraform converts the string values of number and bool variables.
+func (t *TerraformProvider) applyParameterOverrides() error {
+	if len(t.options.Parameters) == 0 {
+		return nil
+	}
+
+	parametersBytes, err := os.ReadFile(t.parametersFilePath())
+	if err != nil {
+		return fmt.Errorf("reading parameters file: %w", err)
+	}
+
+	parameters := map[string]any{}
+	if err := json.Unmarshal(parametersBytes, &parameters); err != nil {
+		return fmt.Errorf("error unmarshalling template parameters: %w", err)
+	}
+
+	for key, value := range t.options.Parameters {
+		parameters[key] = value
+	}
+
+	parametersBytes, err = json.MarshalIndent(parameters, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling parameters: %w", err)
+	}
+
+	if err := os.WriteFile(t.parametersFilePath(), parametersBytes, osutil.PermissionFile); err != nil {
+		return fmt.Errorf("writing parameters file: %w", err)
+	}
+
+	return nil
+}
+
 // Checks if the parameters file already exists and creates if as needed.
 func (t *TerraformProvider) ensureParametersFile() error {
 	if _, err := os.Stat(t.parametersFilePath()); err != nil {
@@ -461,8 +507,9 @@ func (t *TerraformProvider) convertOutputs(outputMap map[string]terraformOutput)
 	outputParameters := make(map[string]OutputParameter)
 	for k, v := range outputMap {
 		outputParameters[k] = OutputParameter{
-			Type:  t.mapTerraformTypeToInterfaceType(v.Type),
-			Value: v.Value,
+			Type:   t.mapTerraformTypeToInterfaceType(v.Type),
+			Value:  v.Value,
+			Secure: v.Sensitive,
 		}
 	}
 	return outputParameters
@@ -492,6 +539,31 @@ func (t *TerraformProvider) showCurrentState(
 	return &showOutput, nil
 }
 
+// plannedDeletions returns the addresses of the resources the plan deletes, including the resources the plan replaces
+func (t *TerraformProvider) plannedDeletions(ctx context.Context, modulePath string) ([]string, error) {
+	runResult, err := t.cli.Show(ctx, modulePath, t.planFilePath())
+	if err != nil {
+		return nil, fmt.Errorf("showing plan failed: %s, err:%w", runResult, err)
+	}
+
+	var planOutput terraformPlanOutput
+	if err := json.Unmarshal([]byte(runResult), &planOutput); err != nil {
+		return nil, err
+	}
+
+	deletions := []string{}
+	for _, change := range planOutput.ResourceChanges {
+		for _, action := range change.Change.Actions {
+			if action == "delete" {
+				deletions = append(deletions, change.Address)
+				break
+			}
+		}
+	}
+
+	return deletions, nil
+}
+
 // Creates the deployment object from the specified module path
 func (t *TerraformProvider) createDeployment(ctx context.Context, modulePath string) (*Deployment, error) {
 	templateParameters := make(map[string]InputParameter)
@@ -704,6 +776,21 @@ type terraformShowOutput struct {
 	Values        terraformValues `json:"values"`
 }
 
+// terraformPlanOutput is a model type for the output of `terraform show -json` for a plan file.
+// see https://www.terraform.io/internals/json-format#plan-representation for more information on the shape
+// of the JSON data.
+type terraformPlanOutput struct {
+	ResourceChanges []terraformResourceChange `json:"resource_changes"`
+}
+
+// terraformResourceChange is a model type for the `change-representation` of a resource of a plan.
+type terraformResourceChange struct {
+	Address string `json:"address"`
+	Change  struct {
+		Actions []string `json:"actions"`
+	} `json:"change"`
+}
+
 // terraformValues is a model type for the `values-representation` object in a JSON output from terraform.
 // see https://www.terraform.io/internals/json-format#values-representation for more information on the shape
 // of the JSON data.
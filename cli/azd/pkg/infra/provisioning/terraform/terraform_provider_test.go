@@ -49,6 +49,7 @@ func TestTerraformPlan(t *testing.T) {
 
 	require.Nil(t, err)
 	require.NotNil(t, deploymentPlan.Deployment)
+	require.Equal(t, []string{"azurerm_storage_account.old"}, deploymentPlan.DeletedResources)
 
 	consoleLog := mockContext.Console.Output()
 
@@ -258,6 +259,13 @@ func preparePlanningMocks(commandRunner *execmock.MockCommandRunner) {
 		Stdout: "To perform exactly these actions, run the following command to apply:terraform apply",
 		Stderr: "",
 	})
+
+	commandRunner.When(func(args exec.RunArgs, command string) bool {
+		return args.Cmd == "terraform" && strings.Contains(command, "show")
+	}).Respond(exec.RunResult{
+		Stdout: terraformPlanShowMockOutput,
+		Stderr: "",
+	})
 }
 
 func prepareDeployMocks(commandRunner *execmock.MockCommandRunner) {
@@ -288,6 +296,9 @@ func prepareDeployMocks(commandRunner *execmock.MockCommandRunner) {
 //go:embed testdata/terraform_show_mock.json
 var terraformShowMockOutput string
 
+//go:embed testdata/terraform_plan_show_mock.json
+var terraformPlanShowMockOutput string
+
 func prepareShowMocks(commandRunner *execmock.MockCommandRunner) {
 	commandRunner.When(func(args exec.RunArgs, command string) bool {
 		return args.Cmd == "terraform" && strings.Contains(command, "show")
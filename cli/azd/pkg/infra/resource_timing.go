@@ -0,0 +1,142 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package infra
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+// The number of resources flagged as the slowest to provision
+const slowestResourceCount = 3
+
+// ResourceTiming is the time taken to provision a resource, from its deployment operation
+type ResourceTiming struct {
+	Id        string
+	Name      string
+	Type      AzureResourceType
+	StartTime time.Time
+	Duration  time.Duration
+	// true when the resource is one of the slowest resources to provision
+	Slowest bool
+}
+
+// NewResourceTimings returns the time taken to provision each resource of the deployment operations, slowest first.
+// Operations without timings and nested deployments, whose time is the time of the resources they contain,
+// are skipped.
+func NewResourceTimings(operations []*armresources.DeploymentOperation) []ResourceTiming {
+	timings := map[string]ResourceTiming{}
+
+	for _, operation := range operations {
+		properties := operation.Properties
+		if properties == nil || properties.TargetResource == nil || properties.TargetResource.ID == nil ||
+			properties.TargetResource.ResourceType == nil || properties.Duration == nil || properties.Timestamp == nil {
+			continue
+		}
+
+		resourceType := AzureResourceType(*properties.TargetResource.ResourceType)
+		if resourceType == AzureResourceTypeDeployment {
+			continue
+		}
+
+		duration, err := parseIsoDuration(*properties.Duration)
+		if err != nil {
+			log.Printf("skipping timing of resource '%s': %v", *properties.TargetResource.ID, err)
+			continue
+		}
+
+		// A resource can be the target of several operations, the longest one is kept
+		id := *properties.TargetResource.ID
+		if existing, has := timings[id]; has && existing.Duration >= duration {
+			continue
+		}
+
+		name := id
+		if properties.TargetResource.ResourceName != nil {
+			name = *properties.TargetResource.ResourceName
+		}
+
+		timings[id] = ResourceTiming{
+			Id:        id,
+			Name:      name,
+			Type:      resourceType,
+			StartTime: properties.Timestamp.Add(-duration),
+			Duration:  duration,
+		}
+	}
+
+	result := make([]ResourceTiming, 0, len(timings))
+	for _, timing := range timings {
+		result = append(result, timing)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Duration == result[j].Duration {
+			return result[i].Name < result[j].Name
+		}
+
+		return result[i].Duration > result[j].Duration
+	})
+
+	for i := range result {
+		result[i].Slowest = i < slowestResourceCount
+	}
+
+	return result
+}
+
+// TotalDuration returns the time from the start of the first resource to the end of the last resource
+func TotalDuration(timings []ResourceTiming) time.Duration {
+	if len(timings) == 0 {
+		return 0
+	}
+
+	start := timings[0].StartTime
+	end := timings[0].StartTime.Add(timings[0].Duration)
+	for _, timing := range timings[1:] {
+		if timing.StartTime.Before(start) {
+			start = timing.StartTime
+		}
+
+		if timingEnd := timing.StartTime.Add(timing.Duration); timingEnd.After(end) {
+			end = timingEnd
+		}
+	}
+
+	return end.Sub(start)
+}
+
+// isoDurationRegexp matches the ISO 8601 durations returned by Azure Resource Manager, i.e. PT1M30.5S
+var isoDurationRegexp = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+func parseIsoDuration(value string) (time.Duration, error) {
+	matches := isoDurationRegexp.FindStringSubmatch(value)
+	if matches == nil {
+		return 0, fmt.Errorf("invalid duration '%s'", value)
+	}
+
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+	var duration time.Duration
+	for i, unit := range units {
+		if matches[i+1] == "" {
+			continue
+		}
+
+		amount, err := strconv.ParseFloat(matches[i+1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration '%s': %w", value, err)
+		}
+
+		duration += time.Duration(amount * float64(unit))
+	}
+
+	return duration, nil
+}
@@ -0,0 +1,78 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package infra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewResourceTimings(t *testing.T) {
+	end := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	operation := func(resourceType AzureResourceType, name string, duration string) *armresources.DeploymentOperation {
+		return &armresources.DeploymentOperation{
+			Properties: &armresources.DeploymentOperationProperties{
+				Duration:  convert.RefOf(duration),
+				Timestamp: convert.RefOf(end),
+				TargetResource: &armresources.TargetResource{
+					ID:           convert.RefOf("/resources/" + name),
+					ResourceName: convert.RefOf(name),
+					ResourceType: convert.RefOf(string(resourceType)),
+				},
+			},
+		}
+	}
+
+	operations := []*armresources.DeploymentOperation{
+		operation(AzureResourceTypeResourceGroup, "rg", "PT1.5S"),
+		operation(AzureResourceTypeDeployment, "resources", "PT10M"),
+		operation(AzureResourceTypeWebSite, "web", "PT2M30S"),
+		operation(AzureResourceTypeStorageAccount, "storage", "PT25S"),
+		operation(AzureResourceTypeKeyVault, "vault", "PT45S"),
+		// Operations without timings are skipped
+		{Properties: &armresources.DeploymentOperationProperties{}},
+	}
+
+	timings := NewResourceTimings(operations)
+	require.Len(t, timings, 4)
+
+	names := []string{}
+	for _, timing := range timings {
+		names = append(names, timing.Name)
+	}
+	require.Equal(t, []string{"web", "vault", "storage", "rg"}, names)
+
+	require.Equal(t, 150*time.Second, timings[0].Duration)
+	require.Equal(t, end.Add(-150*time.Second), timings[0].StartTime)
+	require.True(t, timings[0].Slowest)
+	require.True(t, timings[2].Slowest)
+	require.False(t, timings[3].Slowest)
+
+	require.Equal(t, 150*time.Second, TotalDuration(timings))
+	require.Equal(t, time.Duration(0), TotalDuration(nil))
+}
+
+func TestParseIsoDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"PT0S":       0,
+		"PT1.5S":     1500 * time.Millisecond,
+		"PT1M30S":    90 * time.Second,
+		"PT2H":       2 * time.Hour,
+		"P1DT1H1M1S": 25*time.Hour + time.Minute + time.Second,
+	}
+
+	for value, expected := range tests {
+		duration, err := parseIsoDuration(value)
+		require.NoError(t, err)
+		require.Equal(t, expected, duration, value)
+	}
+
+	_, err := parseIsoDuration("1m30s")
+	require.Error(t, err)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 )
 
@@ -83,6 +84,7 @@ type SubscriptionScope struct {
 	name           string
 	subscriptionId string
 	location       string
+	tags           map[string]*string
 }
 
 // Gets the deployment name
@@ -107,7 +109,7 @@ func (s *SubscriptionScope) Location() string {
 
 // Deploy a given template with a set of parameters.
 func (s *SubscriptionScope) Deploy(ctx context.Context, template *azure.ArmTemplate, parametersPath string) error {
-	_, err := s.azCli.DeployToSubscription(ctx, s.subscriptionId, s.name, template, parametersPath, s.location)
+	_, err := s.azCli.DeployToSubscription(ctx, s.subscriptionId, s.name, template, parametersPath, s.location, s.tags)
 	return err
 }
 
@@ -129,3 +131,26 @@ func NewSubscriptionScope(ctx context.Context, location string, subscriptionId s
 		location:       location,
 	}
 }
+
+// NewTaggedSubscriptionScope returns the scope of a deployment to the subscription created with the given tags, i.e. the
+// name of the environment it deploys
+func NewTaggedSubscriptionScope(
+	ctx context.Context,
+	location string,
+	subscriptionId string,
+	deploymentName string,
+	tags map[string]string,
+) Scope {
+	deploymentTags := map[string]*string{}
+	for key, value := range tags {
+		deploymentTags[key] = convert.RefOf(value)
+	}
+
+	return &SubscriptionScope{
+		azCli:          azcli.GetAzCli(ctx),
+		name:           deploymentName,
+		subscriptionId: subscriptionId,
+		location:       location,
+		tags:           deploymentTags,
+	}
+}
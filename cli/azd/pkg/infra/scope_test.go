@@ -140,6 +140,37 @@ func TestScopeDeploy(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("TaggedSubscriptionScope", func(t *testing.T) {
+		var deployment armresources.Deployment
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPut && strings.Contains(
+				request.URL.Path,
+				"/subscriptions/SUBSCRIPTION_ID/providers/Microsoft.Resources/deployments/DEPLOYMENT_NAME",
+			)
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			if err := json.NewDecoder(request.Body).Decode(&deployment); err != nil {
+				return nil, err
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusCreated,
+				Body:       io.NopCloser(bytes.NewBuffer([]byte(testArmResponse))),
+				Request: &http.Request{
+					Method: http.MethodGet,
+				},
+			}, nil
+		})
+
+		scope := NewTaggedSubscriptionScope(
+			*mockContext.Context, "eastus2", "SUBSCRIPTION_ID", "DEPLOYMENT_NAME", map[string]string{"azd-env-name": "dev"})
+
+		armTemplate := azure.ArmTemplate(testArmTemplate)
+		err := scope.Deploy(*mockContext.Context, &armTemplate, parametersPath)
+		require.NoError(t, err)
+		require.Equal(t, "dev", *deployment.Tags["azd-env-name"])
+	})
+
 	t.Run("ResourceGroupScopeSuccess", func(t *testing.T) {
 		mockContext := mocks.NewMockContext(context.Background())
 		mockContext.HttpClient.When(func(request *http.Request) bool {
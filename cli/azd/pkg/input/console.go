@@ -20,8 +20,16 @@ import (
 )
 
 type Console interface {
-	// Prints out a message to the underlying console write
+	// Prints out a message to the underlying console write, at every verbosity
 	Message(ctx context.Context, message string)
+	// Prints out a message to the underlying console write when the verbosity of the console is at least verbosity
+	MessageAt(ctx context.Context, verbosity Verbosity, message string)
+	// Prints out the rows under the headers, with aligned columns
+	Table(ctx context.Context, headers []string, rows [][]string)
+	// Prints out a value per line after its key, with aligned values
+	KeyValues(ctx context.Context, values []KeyValue)
+	// Gets the verbosity of the console
+	Verbosity() Verbosity
 	// Prompts the user for a single value
 	Prompt(ctx context.Context, options ConsoleOptions) (string, error)
 	// Prompts the user to select from a set of values
@@ -42,6 +50,11 @@ type AskerConsole struct {
 	// the writer which output is written to.
 	writer    io.Writer
 	formatter output.Formatter
+	verbosity Verbosity
+	// when false, tables and key values are printed as plain text, without colors or truncation
+	isTerminal bool
+	// when true, the urls printed are rendered as hyperlinks, the terminal supporting them
+	hyperlinks bool
 }
 
 type ConsoleOptions struct {
@@ -78,12 +91,56 @@ func (c *AskerConsole) Message(ctx context.Context, message string) {
 		}
 		fmt.Fprintln(c.writer, string(jsonMessage))
 	} else if c.formatter == nil || c.formatter.Kind() == output.NoneFormat {
-		fmt.Fprintln(c.writer, message)
+		fmt.Fprintln(c.writer, c.withHyperlinks(message))
 	} else {
 		log.Println(message)
 	}
 }
 
+// Prints out a message to the underlying console write when the verbosity of the console is at least verbosity
+func (c *AskerConsole) MessageAt(ctx context.Context, verbosity Verbosity, message string) {
+	if c.verbosity >= verbosity {
+		c.Message(ctx, message)
+	}
+}
+
+// Prints out the rows under the headers, with aligned columns. In a terminal, the headers are highlighted and the last
+// column is truncated to the width of the terminal, otherwise the table is printed as plain text.
+func (c *AskerConsole) Table(ctx context.Context, headers []string, rows [][]string) {
+	c.writeRendered(ctx, renderTable(headers, rows, c.isTerminal, terminalWidth(c.handles.Stdout)))
+}
+
+// Prints out a value per line after its key, with aligned values. In a terminal, the keys are highlighted, otherwise
+// the values are printed as plain text.
+func (c *AskerConsole) KeyValues(ctx context.Context, values []KeyValue) {
+	c.writeRendered(ctx, renderKeyValues(values, c.isTerminal))
+}
+
+// writeRendered prints out rendered text. The tables of commands formatting their output as a table are part of the
+// output, other rendered text is a message.
+func (c *AskerConsole) writeRendered(ctx context.Context, rendered string) {
+	if c.formatter != nil && c.formatter.Kind() == output.TableFormat {
+		fmt.Fprint(c.writer, c.withHyperlinks(rendered))
+		return
+	}
+
+	c.Message(ctx, strings.TrimSuffix(rendered, "\n"))
+}
+
+// withHyperlinks makes the urls of the text hyperlinks, when the terminal supports them
+func (c *AskerConsole) withHyperlinks(text string) string {
+	if !c.hyperlinks {
+		return text
+	}
+
+	return withHyperlinks(text)
+}
+
+// Gets the verbosity of the console
+func (c *AskerConsole) Verbosity() Verbosity {
+	return c.verbosity
+}
+
 // jsonObjectForMessage creates a json object representing a message. Any ANSI control sequences from the message are
 // removed. A trailing newline is added to the message.
 func (c *AskerConsole) eventForMessage(message string) contracts.EventEnvelope {
@@ -170,8 +227,15 @@ func (c *AskerConsole) Handles() ConsoleHandles {
 	return c.handles
 }
 
-// Creates a new console with the specified writer, handles and formatter.
-func NewConsole(noPrompt bool, isTerminal bool, w io.Writer, handles ConsoleHandles, formatter output.Formatter) Console {
+// Creates a new console with the specified writer, handles, formatter and verbosity.
+func NewConsole(
+	noPrompt bool,
+	isTerminal bool,
+	w io.Writer,
+	handles ConsoleHandles,
+	formatter output.Formatter,
+	verbosity Verbosity,
+) Console {
 	asker := NewAsker(noPrompt, isTerminal, handles.Stdout, handles.Stdin)
 
 	return &AskerConsole{
@@ -180,6 +244,9 @@ func NewConsole(noPrompt bool, isTerminal bool, w io.Writer, handles ConsoleHand
 		defaultWriter: w,
 		writer:        w,
 		formatter:     formatter,
+		verbosity:     verbosity,
+		isTerminal:    isTerminal,
+		hyperlinks:    hyperlinksEnabled(isTerminal),
 	}
 }
 
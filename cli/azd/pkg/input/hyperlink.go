@@ -0,0 +1,70 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package input
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The urls within the text printed by the console. Escape sequences end a url, as the color of a link ends its url.
+var urlRegex = regexp.MustCompile(`https?://[^\s\x1b"'<>]+`)
+
+// The trailing characters of a match which end the sentence rather than the url
+const urlTrailingPunctuation = ".,;:!?)]"
+
+// supportsHyperlinks returns true when the terminal the console writes to renders OSC 8 hyperlinks. Terminals which
+// don't support them may print the escape sequences, so hyperlinks are only emitted for the terminals known to support
+// them. FORCE_HYPERLINK=1 or FORCE_HYPERLINK=0 overrides the detection.
+func supportsHyperlinks(getenv func(string) string) bool {
+	if force := getenv("FORCE_HYPERLINK"); force != "" {
+		enabled, err := strconv.ParseBool(force)
+		return err == nil && enabled
+	}
+
+	if getenv("TERM") == "dumb" || getenv("CI") != "" {
+		return false
+	}
+
+	// Windows Terminal
+	if getenv("WT_SESSION") != "" {
+		return true
+	}
+
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "vscode", "WezTerm", "Hyper":
+		return true
+	}
+
+	if getenv("KONSOLE_VERSION") != "" || getenv("DOMTERM") != "" {
+		return true
+	}
+
+	// GNOME Terminal and the other terminals based on VTE support hyperlinks since VTE 0.50
+	if vte, err := strconv.Atoi(getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+
+	return false
+}
+
+// hyperlink returns the text, rendered by the terminal as a link to the url
+func hyperlink(url string, text string) string {
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// withHyperlinks returns the text with each of its urls made a hyperlink to itself
+func withHyperlinks(text string) string {
+	return urlRegex.ReplaceAllStringFunc(text, func(match string) string {
+		url := strings.TrimRight(match, urlTrailingPunctuation)
+		return hyperlink(url, url) + match[len(url):]
+	})
+}
+
+// hyperlinksEnabled returns true when the console renders the urls it prints as hyperlinks
+func hyperlinksEnabled(isTerminal bool) bool {
+	return isTerminal && supportsHyperlinks(os.Getenv)
+}
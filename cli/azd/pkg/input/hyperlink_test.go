@@ -0,0 +1,53 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package input
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupportsHyperlinks(t *testing.T) {
+	tests := map[string]struct {
+		env      map[string]string
+		expected bool
+	}{
+		"Unknown":            {env: map[string]string{"TERM": "xterm-256color"}, expected: false},
+		"WindowsTerminal":    {env: map[string]string{"WT_SESSION": "id"}, expected: true},
+		"VsCode":             {env: map[string]string{"TERM_PROGRAM": "vscode"}, expected: true},
+		"Vte":                {env: map[string]string{"VTE_VERSION": "6800"}, expected: true},
+		"OldVte":             {env: map[string]string{"VTE_VERSION": "4600"}, expected: false},
+		"Ci":                 {env: map[string]string{"TERM_PROGRAM": "vscode", "CI": "true"}, expected: false},
+		"Forced":             {env: map[string]string{"FORCE_HYPERLINK": "1"}, expected: true},
+		"ForcedOff":          {env: map[string]string{"FORCE_HYPERLINK": "0", "WT_SESSION": "id"}, expected: false},
+		"DumbTerminal":       {env: map[string]string{"TERM": "dumb", "WT_SESSION": "id"}, expected: false},
+		"InvalidForcedValue": {env: map[string]string{"FORCE_HYPERLINK": "maybe", "WT_SESSION": "id"}, expected: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.expected, supportsHyperlinks(func(key string) string {
+				return test.env[key]
+			}))
+		})
+	}
+}
+
+func TestWithHyperlinks(t *testing.T) {
+	url := "https://github.com/contoso/todo"
+
+	require.Equal(t, "no links here", withHyperlinks("no links here"))
+	require.Equal(t,
+		"Repository: "+hyperlink(url, url)+".",
+		withHyperlinks("Repository: "+url+"."))
+	require.Equal(t,
+		"("+hyperlink(url, url)+")",
+		withHyperlinks("("+url+")"))
+
+	// The color of a link ends with its url
+	colored := output.WithLinkFormat(url)
+	require.Contains(t, withHyperlinks(colored), hyperlink(url, url))
+}
@@ -0,0 +1,184 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package input
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/mattn/go-colorable"
+	"golang.org/x/term"
+)
+
+// KeyValue is a labeled value displayed by Console.KeyValues
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// The spaces between the columns of a table
+const tableColumnSeparator = "  "
+
+// The smallest width a truncated column of a table is shrunk to
+const minTruncatedColumnWidth = 10
+
+// renderTable renders the rows under the headers, with the columns aligned. When styled, the headers are highlighted and
+// the last column is truncated to fit the rows in width columns, when width is set. Otherwise the text is plain, without
+// colors, and rows are never truncated so the output can be processed by other tools.
+func renderTable(headers []string, rows [][]string, styled bool, width int) string {
+	columns := len(headers)
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+
+	cell := func(row []string, index int) string {
+		if index >= len(row) {
+			return ""
+		}
+
+		if !styled {
+			return stripAnsi(row[index])
+		}
+
+		return row[index]
+	}
+
+	widths := make([]int, columns)
+	for _, row := range append([][]string{headers}, rows...) {
+		for index := 0; index < columns; index++ {
+			if cellWidth := visibleWidth(cell(row, index)); cellWidth > widths[index] {
+				widths[index] = cellWidth
+			}
+		}
+	}
+
+	// Only the last column is truncated, the columns before it identify the rows
+	lastWidth := widths[columns-1]
+	if styled && width > 0 {
+		fixed := 0
+		for _, columnWidth := range widths[:columns-1] {
+			fixed += columnWidth + len(tableColumnSeparator)
+		}
+
+		if fixed+lastWidth > width {
+			lastWidth = width - fixed
+			if lastWidth < minTruncatedColumnWidth {
+				lastWidth = minTruncatedColumnWidth
+			}
+		}
+	}
+
+	var builder strings.Builder
+	writeRow := func(row []string, header bool) {
+		cells := make([]string, columns)
+		for index := 0; index < columns; index++ {
+			value := cell(row, index)
+			if index == columns-1 {
+				cells[index] = truncate(value, lastWidth)
+			} else {
+				cells[index] = value + strings.Repeat(" ", widths[index]-visibleWidth(value))
+			}
+
+			if header && styled {
+				cells[index] = output.WithHighLightFormat(cells[index])
+			}
+		}
+
+		builder.WriteString(strings.TrimRight(strings.Join(cells, tableColumnSeparator), " "))
+		builder.WriteString("\n")
+	}
+
+	writeRow(headers, true)
+	for _, row := range rows {
+		writeRow(row, false)
+	}
+
+	return builder.String()
+}
+
+// renderKeyValues renders a value per line, after its key, with the values aligned. When styled, the keys are
+// highlighted, otherwise the text is plain, without colors.
+func renderKeyValues(values []KeyValue, styled bool) string {
+	keyWidth := 0
+	for _, kv := range values {
+		if width := visibleWidth(kv.Key) + 1; width > keyWidth {
+			keyWidth = width
+		}
+	}
+
+	var builder strings.Builder
+	for _, kv := range values {
+		key := kv.Key + ":"
+		value := kv.Value
+		if !styled {
+			key = stripAnsi(key)
+			value = stripAnsi(value)
+		}
+
+		padding := strings.Repeat(" ", keyWidth-visibleWidth(key))
+		if styled {
+			key = output.WithHighLightFormat(key)
+		}
+
+		builder.WriteString(strings.TrimRight(fmt.Sprintf("  %s%s %s", key, padding, value), " "))
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// truncate shortens the text to width visible characters, ending it with an ellipsis. Styled text isn't truncated, its
+// escape sequences would be cut.
+func truncate(text string, width int) string {
+	if visibleWidth(text) <= width || stripAnsi(text) != text || width < 1 {
+		return text
+	}
+
+	runes := []rune(text)
+	return string(runes[:width-1]) + "…"
+}
+
+// visibleWidth returns the number of characters of the text displayed by a terminal, without its escape sequences
+func visibleWidth(text string) int {
+	return utf8.RuneCountInString(stripAnsi(text))
+}
+
+// stripAnsi removes the ANSI escape sequences, i.e. colors, from the text
+func stripAnsi(text string) string {
+	if !strings.Contains(text, "\x1b") {
+		return text
+	}
+
+	var buf bytes.Buffer
+
+	// We do not expect the io.Copy to fail since none of these sub-calls will ever return an error (other than
+	// EOF when we hit the end of the string)
+	if _, err := io.Copy(colorable.NewNonColorable(&buf), strings.NewReader(text)); err != nil {
+		panic(fmt.Sprintf("stripAnsi: did not expect error from io.Copy but got: %v", err))
+	}
+
+	return buf.String()
+}
+
+// terminalWidth returns the width of the terminal the writer writes to, 0 when it isn't a terminal
+func terminalWidth(writer io.Writer) int {
+	file, ok := writer.(*os.File)
+	if !ok {
+		return 0
+	}
+
+	width, _, err := term.GetSize(int(file.Fd()))
+	if err != nil {
+		return 0
+	}
+
+	return width
+}
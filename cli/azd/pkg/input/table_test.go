@@ -0,0 +1,62 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package input
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTable(t *testing.T) {
+	headers := []string{"NAME", "DEFAULT", "PATH"}
+	rows := [][]string{
+		{"dev", "true", "/src/api"},
+		{"production", "false", "/src/web/a/very/long/path"},
+	}
+
+	t.Run("Plain", func(t *testing.T) {
+		rendered := renderTable(headers, rows, false, 20)
+		require.Equal(t,
+			"NAME        DEFAULT  PATH\n"+
+				"dev         true     /src/api\n"+
+				"production  false    /src/web/a/very/long/path\n",
+			rendered)
+	})
+
+	t.Run("PlainStripsColors", func(t *testing.T) {
+		rendered := renderTable([]string{"NAME"}, [][]string{{output.WithSuccessFormat("dev")}}, false, 0)
+		require.Equal(t, "NAME\ndev\n", rendered)
+	})
+
+	t.Run("StyledTruncatesLastColumn", func(t *testing.T) {
+		rendered := renderTable(headers, rows, true, 40)
+		require.Contains(t, rendered, "production  false    /src/web/a/very/lo…\n")
+		require.Contains(t, rendered, "dev         true     /src/api\n")
+	})
+
+	t.Run("MissingCells", func(t *testing.T) {
+		rendered := renderTable([]string{"A", "B"}, [][]string{{"1"}, {"22", "3"}}, false, 0)
+		require.Equal(t, "A   B\n1\n22  3\n", rendered)
+	})
+}
+
+func TestRenderKeyValues(t *testing.T) {
+	values := []KeyValue{
+		{Key: "Provider", Value: "GitHub"},
+		{Key: "Service principal", Value: output.WithLinkFormat("az-dev")},
+	}
+
+	rendered := renderKeyValues(values, false)
+	require.Equal(t,
+		"  Provider:          GitHub\n"+
+			"  Service principal: az-dev\n",
+		rendered)
+}
+
+func TestTruncate(t *testing.T) {
+	require.Equal(t, "short", truncate("short", 10))
+	require.Equal(t, "a long te…", truncate("a long text", 10))
+}
@@ -0,0 +1,58 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package input
+
+import (
+	"context"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/spin"
+)
+
+// Verbosity controls how much of the progress of a command the console shows
+type Verbosity int
+
+const (
+	// Only the messages of the command are shown, without the spinner and the lines reporting each step
+	VerbosityQuiet Verbosity = -1
+	// The spinner and the lines reporting each step are shown, i.e. a line per provisioned resource
+	VerbosityNormal Verbosity = 0
+	// The output of the tools launched by the command, i.e. az and bicep, is shown as well
+	VerbosityVerbose Verbosity = 1
+)
+
+// NewVerbosity returns the verbosity selected with the `--quiet` and `--verbose` flags
+func NewVerbosity(quiet bool, verbose bool) Verbosity {
+	switch {
+	case quiet:
+		return VerbosityQuiet
+	case verbose:
+		return VerbosityVerbose
+	default:
+		return VerbosityNormal
+	}
+}
+
+// NewSpinner creates a spinner writing to the stdout of the console. The spinner of a quiet console isn't rendered,
+// only the messages written through it are.
+func NewSpinner(console Console, title string) *spin.Spinner {
+	if console.Verbosity() == VerbosityQuiet {
+		return spin.NewHiddenSpinner(console.Handles().Stdout)
+	}
+
+	return spin.NewSpinner(console.Handles().Stdout, title)
+}
+
+// GetOrCreateSpinner gets the spinner of the context, otherwise creates a new spinner for the console.
+// Returns a new context when a new spinner is created.
+func GetOrCreateSpinner(ctx context.Context, console Console, title string) (*spin.Spinner, context.Context) {
+	spinner := spin.GetSpinner(ctx)
+	if spinner == nil {
+		spinner = NewSpinner(console, title)
+		ctx = spin.WithSpinner(ctx, spinner)
+	}
+
+	spinner.Title(title)
+
+	return spinner, ctx
+}
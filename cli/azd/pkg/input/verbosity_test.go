@@ -0,0 +1,57 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package input
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVerbosity(t *testing.T) {
+	require.Equal(t, VerbosityNormal, NewVerbosity(false, false))
+	require.Equal(t, VerbosityQuiet, NewVerbosity(true, false))
+	require.Equal(t, VerbosityVerbose, NewVerbosity(false, true))
+}
+
+func TestMessageAt(t *testing.T) {
+	tests := []struct {
+		verbosity Verbosity
+		expected  string
+	}{
+		{VerbosityQuiet, "message\n"},
+		{VerbosityNormal, "message\nprogress\n"},
+		{VerbosityVerbose, "message\nprogress\ndetail\n"},
+	}
+
+	for _, test := range tests {
+		stdout := &bytes.Buffer{}
+		console := NewConsole(
+			true, false, stdout, ConsoleHandles{Stdout: stdout}, &output.NoneFormatter{}, test.verbosity)
+
+		console.Message(context.Background(), "message")
+		console.MessageAt(context.Background(), VerbosityNormal, "progress")
+		console.MessageAt(context.Background(), VerbosityVerbose, "detail")
+
+		require.Equal(t, test.expected, stdout.String())
+	}
+}
+
+func TestNewSpinnerQuiet(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	console := NewConsole(true, false, stdout, ConsoleHandles{Stdout: stdout}, &output.NoneFormatter{}, VerbosityQuiet)
+
+	spinner := NewSpinner(console, "Deploying")
+	err := spinner.Run(func() error {
+		spinner.Println("Deployed service api\n")
+		return nil
+	})
+
+	// Only the messages written through the spinner are shown
+	require.NoError(t, err)
+	require.Equal(t, "Deployed service api\n", stdout.String())
+}
@@ -0,0 +1,55 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/mattn/go-colorable"
+)
+
+// AnnotationLevel is the severity of an annotation of a CI run
+type AnnotationLevel string
+
+const (
+	AnnotationError   AnnotationLevel = "error"
+	AnnotationWarning AnnotationLevel = "warning"
+)
+
+// escapes the data of a GitHub Actions workflow command
+var gitHubActionsEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// escapes the data of an Azure Pipelines logging command
+var azurePipelinesEscaper = strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A")
+
+// Annotation returns the logging command surfacing the message as an annotation in the UI of the run when azd runs in
+// GitHub Actions or Azure Pipelines, or an empty string otherwise.
+func Annotation(level AnnotationLevel, message string) string {
+	message = strings.TrimSpace(withoutColors(message))
+
+	switch internal.DetectCIProvider() {
+	case internal.CIProviderGitHubActions:
+		// https://docs.github.com/actions/using-workflows/workflow-commands-for-github-actions
+		return fmt.Sprintf("::%s::%s", level, gitHubActionsEscaper.Replace(message))
+	case internal.CIProviderAzurePipelines:
+		// https://learn.microsoft.com/azure/devops/pipelines/scripts/logging-commands
+		return fmt.Sprintf("##vso[task.logissue type=%s]%s", level, azurePipelinesEscaper.Replace(message))
+	default:
+		return ""
+	}
+}
+
+// withoutColors removes the ANSI control sequences from the text
+func withoutColors(text string) string {
+	var buf bytes.Buffer
+	if _, err := io.Copy(colorable.NewNonColorable(&buf), strings.NewReader(text)); err != nil {
+		return text
+	}
+
+	return buf.String()
+}
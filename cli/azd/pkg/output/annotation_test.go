@@ -0,0 +1,51 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotation(t *testing.T) {
+	tests := []struct {
+		name           string
+		gitHubActions  string
+		tfBuild        string
+		level          AnnotationLevel
+		message        string
+		expectedOutput string
+	}{
+		{
+			name:           "local",
+			level:          AnnotationError,
+			message:        "deployment failed",
+			expectedOutput: "",
+		},
+		{
+			name:           "github actions",
+			gitHubActions:  "true",
+			level:          AnnotationError,
+			message:        "deployment failed:\n100% of the resources\n",
+			expectedOutput: "::error::deployment failed:%0A100%25 of the resources",
+		},
+		{
+			name:           "azure pipelines",
+			tfBuild:        "True",
+			level:          AnnotationWarning,
+			message:        "\x1b[33mWARNING: 100% of the resources\r\nare locked\x1b[0m",
+			expectedOutput: "##vso[task.logissue type=warning]WARNING: 100%AZP25 of the resources%0D%0Aare locked",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("GITHUB_ACTIONS", test.gitHubActions)
+			t.Setenv("TF_BUILD", test.tfBuild)
+
+			require.Equal(t, test.expectedOutput, Annotation(test.level, test.message))
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// AppConfigurationOptions configures syncing the values of the environment with an Azure App Configuration store.
+// The values are labeled with the name of the environment, so the environments of a project can share a store.
+type AppConfigurationOptions struct {
+	// The endpoint of the store, defaults to the AZURE_APP_CONFIGURATION_ENDPOINT value of the environment
+	Endpoint string `yaml:"endpoint"`
+	// The keys of the environment values pushed to the store after provisioning, and pulled from the store
+	Keys []string `yaml:"keys"`
+}
+
+// StoreEndpoint returns the endpoint of the App Configuration store the environment is synced with
+func (o *AppConfigurationOptions) StoreEndpoint(env *environment.Environment) (string, error) {
+	if o.Endpoint != "" {
+		return o.Endpoint, nil
+	}
+
+	if endpoint := env.Values[environment.AppConfigurationEndpointEnvVarName]; endpoint != "" {
+		return endpoint, nil
+	}
+
+	return "", fmt.Errorf(
+		"could not determine the App Configuration endpoint, ensure %s is set as an output of your infrastructure "+
+			"or set appConfiguration.endpoint in your project",
+		environment.AppConfigurationEndpointEnvVarName,
+	)
+}
+
+// PushAppConfiguration pushes the configured keys of the environment to the App Configuration store, with the name of
+// the environment as label, and returns the number of values pushed. Keys without a value are skipped.
+func PushAppConfiguration(
+	ctx context.Context,
+	azCli azcli.AzCli,
+	options *AppConfigurationOptions,
+	env *environment.Environment,
+) (int, error) {
+	if options == nil || len(options.Keys) == 0 {
+		return 0, nil
+	}
+
+	endpoint, err := options.StoreEndpoint(env)
+	if err != nil {
+		return 0, err
+	}
+
+	pushed := 0
+	for _, key := range options.Keys {
+		value, has := env.Values[key]
+		if !has {
+			log.Printf("skipping pushing key '%s' to app configuration: the environment has no value", key)
+			continue
+		}
+
+		if err := azCli.SetAppConfigKeyValue(ctx, endpoint, key, env.GetEnvName(), value); err != nil {
+			return pushed, err
+		}
+
+		pushed++
+	}
+
+	return pushed, nil
+}
+
+// PullAppConfiguration pulls the configured keys labeled with the name of the environment from the App Configuration
+// store into the environment, and returns the number of values pulled. The environment isn't saved.
+func PullAppConfiguration(
+	ctx context.Context,
+	azCli azcli.AzCli,
+	options *AppConfigurationOptions,
+	env *environment.Environment,
+) (int, error) {
+	if options == nil || len(options.Keys) == 0 {
+		return 0, errors.New("no keys to sync, set appConfiguration.keys in your project")
+	}
+
+	endpoint, err := options.StoreEndpoint(env)
+	if err != nil {
+		return 0, err
+	}
+
+	keyValues, err := azCli.ListAppConfigKeyValues(ctx, endpoint, env.GetEnvName())
+	if err != nil {
+		return 0, err
+	}
+
+	keys := map[string]bool{}
+	for _, key := range options.Keys {
+		keys[key] = true
+	}
+
+	pulled := 0
+	for _, keyValue := range keyValues {
+		if keys[keyValue.Key] {
+			env.Values[keyValue.Key] = keyValue.Value
+			pulled++
+		}
+	}
+
+	return pulled, nil
+}
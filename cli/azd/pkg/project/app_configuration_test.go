@@ -0,0 +1,47 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppConfigurationStoreEndpoint(t *testing.T) {
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		environment.AppConfigurationEndpointEnvVarName: "https://fromenv.azconfig.io",
+	})
+
+	t.Run("FromOptions", func(t *testing.T) {
+		options := &AppConfigurationOptions{Endpoint: "https://mystore.azconfig.io"}
+		endpoint, err := options.StoreEndpoint(env)
+		require.NoError(t, err)
+		require.Equal(t, "https://mystore.azconfig.io", endpoint)
+	})
+
+	t.Run("FromEnvironment", func(t *testing.T) {
+		endpoint, err := (&AppConfigurationOptions{}).StoreEndpoint(env)
+		require.NoError(t, err)
+		require.Equal(t, "https://fromenv.azconfig.io", endpoint)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		_, err := (&AppConfigurationOptions{}).StoreEndpoint(environment.Ephemeral())
+		require.Error(t, err)
+	})
+}
+
+func TestPushAppConfigurationNotConfigured(t *testing.T) {
+	pushed, err := PushAppConfiguration(context.Background(), nil, nil, environment.Ephemeral())
+	require.NoError(t, err)
+	require.Equal(t, 0, pushed)
+}
+
+func TestPullAppConfigurationNotConfigured(t *testing.T) {
+	_, err := PullAppConfiguration(context.Background(), nil, &AppConfigurationOptions{}, environment.Ephemeral())
+	require.Error(t, err)
+}
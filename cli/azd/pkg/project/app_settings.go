@@ -0,0 +1,224 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// AppSettingsDiff is the change applied to the application settings of an App Service or Azure Functions application
+// by a deployment. Only the names of the settings are listed, their values may be secrets.
+type AppSettingsDiff struct {
+	Added   []string `json:"added,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// IsEmpty returns true when the deployment left the application settings as they were
+func (d *AppSettingsDiff) IsEmpty() bool {
+	return d == nil || len(d.Added)+len(d.Changed)+len(d.Removed) == 0
+}
+
+// String describes the diff, i.e. "added API_URL; changed LOG_LEVEL, MODE; removed DEBUG"
+func (d *AppSettingsDiff) String() string {
+	if d.IsEmpty() {
+		return "no changes"
+	}
+
+	var parts []string
+	for _, change := range []struct {
+		verb  string
+		names []string
+	}{{"added", d.Added}, {"changed", d.Changed}, {"removed", d.Removed}} {
+		if len(change.names) > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s", change.verb, strings.Join(change.names, ", ")))
+		}
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// diffAppSettings compares the desired settings with the current settings of the application. Only the settings
+// azd managed before are removed when they're no longer desired, the settings managed outside of azd are left as is.
+func diffAppSettings(current map[string]string, desired map[string]string, previouslyManaged []string) AppSettingsDiff {
+	diff := AppSettingsDiff{}
+
+	for name, value := range desired {
+		currentValue, has := current[name]
+		switch {
+		case !has:
+			diff.Added = append(diff.Added, name)
+		case currentValue != value:
+			diff.Changed = append(diff.Changed, name)
+		}
+	}
+
+	for _, name := range previouslyManaged {
+		if _, desired := desired[name]; desired {
+			continue
+		}
+
+		if _, has := current[name]; has {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Changed)
+	sort.Strings(diff.Removed)
+	return diff
+}
+
+// desiredAppSettings returns the settings of the service declared in azure.yaml, along with the key vault references
+// of the secrets exposed to the service
+func desiredAppSettings(config *ServiceConfig, env *environment.Environment) (map[string]string, error) {
+	references, err := secretReferences(config, env)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := map[string]string{}
+	for name, value := range config.AppSettings {
+		settings[name] = value
+	}
+
+	for name, uri := range references {
+		if _, has := settings[name]; has {
+			return nil, fmt.Errorf(
+				"service %s sets %s both as an app setting and as a secret, remove one of them", config.Name, name)
+		}
+
+		settings[name] = fmt.Sprintf("@Microsoft.KeyVault(SecretUri=%s)", uri)
+	}
+
+	return settings, nil
+}
+
+// managedAppSettingsEnvVarName returns the name of the key the names of the application settings azd manages for the
+// service are stored with in the environment, i.e. SERVICE_API_MANAGED_APP_SETTINGS
+func managedAppSettingsEnvVarName(serviceName string) string {
+	return fmt.Sprintf("SERVICE_%s_MANAGED_APP_SETTINGS", strings.ToUpper(serviceName))
+}
+
+// managedAppSettings returns the names of the application settings azd set for the service by its previous deployment
+func managedAppSettings(config *ServiceConfig, env *environment.Environment) []string {
+	value := env.Values[managedAppSettingsEnvVarName(config.Name)]
+	if value == "" {
+		return nil
+	}
+
+	return strings.Split(value, ",")
+}
+
+// ApplyAppSettings applies the app settings and the secret references of the service to its App Service or Azure
+// Functions application, outside of a deployment, i.e. once the environment values they reference changed. Nothing is
+// applied to the services hosted elsewhere.
+func ApplyAppSettings(
+	ctx context.Context,
+	cli azcli.AzCli,
+	svc *Service,
+	env *environment.Environment,
+) (*AppSettingsDiff, error) {
+	host := ServiceTargetKind(svc.Config.Host)
+	if host != "" && host != AppServiceTarget && host != AzureFunctionTarget {
+		return nil, nil
+	}
+
+	progress := make(chan string)
+	go func() {
+		for message := range progress {
+			log.Printf("applying the app settings of service %s: %s", svc.Config.Name, message)
+		}
+	}()
+	defer close(progress)
+
+	return applyAppServiceSettings(ctx, cli, svc.Config, env, svc.Scope, progress)
+}
+
+// applyAppServiceSettings applies the app settings and the secret references of the service to the application
+// settings of the App Service or Azure Functions application. The settings are only updated when they differ from the
+// desired ones, and the settings managed outside of azd are kept. The identity of the application must be granted
+// access to the secrets of the key vault.
+// https://learn.microsoft.com/azure/app-service/app-service-key-vault-references
+func applyAppServiceSettings(
+	ctx context.Context,
+	cli azcli.AzCli,
+	config *ServiceConfig,
+	env *environment.Environment,
+	scope *environment.DeploymentScope,
+	progress chan<- string,
+) (*AppSettingsDiff, error) {
+	desired, err := desiredAppSettings(config, env)
+	if err != nil {
+		return nil, err
+	}
+
+	previouslyManaged := managedAppSettings(config, env)
+	if len(desired) == 0 && len(previouslyManaged) == 0 {
+		return nil, nil
+	}
+
+	progress <- "Comparing application settings"
+	current, err := cli.GetAppServiceAppSettings(
+		ctx,
+		env.GetSubscriptionId(),
+		scope.ResourceGroupName(),
+		scope.ResourceName(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("getting application settings: %w", err)
+	}
+
+	diff := diffAppSettings(current, desired, previouslyManaged)
+	if !diff.IsEmpty() {
+		log.Printf("updating the application settings of %s: %s", scope.ResourceName(), diff.String())
+
+		progress <- "Updating application settings"
+		changed := map[string]string{}
+		for _, name := range append(diff.Added, diff.Changed...) {
+			changed[name] = desired[name]
+		}
+
+		err = cli.UpdateAppServiceAppSettings(
+			ctx,
+			env.GetSubscriptionId(),
+			scope.ResourceGroupName(),
+			scope.ResourceName(),
+			changed,
+			diff.Removed,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("updating application settings: %w", err)
+		}
+	}
+
+	// Remember the settings azd manages, to remove them once they're no longer declared
+	managed := make([]string, 0, len(desired))
+	for name := range desired {
+		managed = append(managed, name)
+	}
+	sort.Strings(managed)
+
+	key := managedAppSettingsEnvVarName(config.Name)
+	if strings.Join(managed, ",") != env.Values[key] {
+		if len(managed) == 0 {
+			delete(env.Values, key)
+		} else {
+			env.Values[key] = strings.Join(managed, ",")
+		}
+
+		if err := env.Save(); err != nil {
+			return nil, fmt.Errorf("saving managed application settings to environment: %w", err)
+		}
+	}
+
+	return &diff, nil
+}
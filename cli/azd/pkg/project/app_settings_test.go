@@ -0,0 +1,76 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffAppSettings(t *testing.T) {
+	current := map[string]string{
+		"API_URL":   "https://api.contoso.com",
+		"LOG_LEVEL": "info",
+		"DEBUG":     "true",
+		"PORTAL":    "unmanaged",
+	}
+	desired := map[string]string{
+		"API_URL":   "https://api.contoso.com",
+		"LOG_LEVEL": "debug",
+		"MODE":      "production",
+	}
+
+	diff := diffAppSettings(current, desired, []string{"API_URL", "DEBUG", "GONE"})
+	require.Equal(t, AppSettingsDiff{
+		Added:   []string{"MODE"},
+		Changed: []string{"LOG_LEVEL"},
+		Removed: []string{"DEBUG"},
+	}, diff)
+	require.Equal(t, "added MODE; changed LOG_LEVEL; removed DEBUG", diff.String())
+
+	diff = diffAppSettings(current, map[string]string{"API_URL": "https://api.contoso.com"}, nil)
+	require.True(t, diff.IsEmpty())
+	require.Equal(t, "no changes", diff.String())
+}
+
+func TestDesiredAppSettings(t *testing.T) {
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		environment.KeyVaultEndpointEnvVarName: "https://myvault.vault.azure.net/",
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		settings, err := desiredAppSettings(&ServiceConfig{
+			Name:        "api",
+			AppSettings: map[string]string{"MODE": "production"},
+			Secrets:     map[string]string{"DB_PASSWORD": "db-password"},
+		}, env)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"MODE":        "production",
+			"DB_PASSWORD": "@Microsoft.KeyVault(SecretUri=https://myvault.vault.azure.net/secrets/db-password)",
+		}, settings)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		_, err := desiredAppSettings(&ServiceConfig{
+			Name:        "api",
+			AppSettings: map[string]string{"DB_PASSWORD": "plain"},
+			Secrets:     map[string]string{"DB_PASSWORD": "db-password"},
+		}, env)
+		require.ErrorContains(t, err, "DB_PASSWORD")
+	})
+}
+
+func TestManagedAppSettings(t *testing.T) {
+	config := &ServiceConfig{Name: "api"}
+	require.Equal(t, "SERVICE_API_MANAGED_APP_SETTINGS", managedAppSettingsEnvVarName("api"))
+	require.Empty(t, managedAppSettings(config, environment.Ephemeral()))
+
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		"SERVICE_API_MANAGED_APP_SETTINGS": "API_URL,MODE",
+	})
+	require.Equal(t, []string{"API_URL", "MODE"}, managedAppSettings(config, env))
+}
@@ -0,0 +1,182 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// BudgetOptions configures the cost budget of the environment, created after provisioning. The budget counts the costs
+// of the resource groups of the environment and alerts its contacts when the cost reaches the thresholds.
+type BudgetOptions struct {
+	// The amount of the budget, in the currency of the billing account of the subscription
+	Amount float64 `yaml:"amount"`
+	// The period the amount applies to: Monthly, Quarterly or Annually, defaults to Monthly
+	TimeGrain string `yaml:"timeGrain,omitempty"`
+	// The percentages of the amount the actual cost is alerted at, defaults to 80 and 100
+	Thresholds []float64 `yaml:"thresholds,omitempty"`
+	// The email addresses alerted
+	ContactEmails []string `yaml:"contactEmails,omitempty"`
+	// The resource ids of the action groups alerted
+	ActionGroups []string `yaml:"actionGroups,omitempty"`
+}
+
+var defaultBudgetThresholds = []float64{80, 100}
+
+var budgetTimeGrains = []string{"Monthly", "Quarterly", "Annually"}
+
+// Validate returns an error when the budget can't be created from the options
+func (o *BudgetOptions) Validate() error {
+	if o.Amount <= 0 {
+		return errors.New("budget.amount must be greater than 0")
+	}
+
+	if o.TimeGrain != "" && !containsFold(budgetTimeGrains, o.TimeGrain) {
+		return fmt.Errorf(
+			"invalid budget.timeGrain '%s', supported values are %s", o.TimeGrain, strings.Join(budgetTimeGrains, ", "))
+	}
+
+	for _, threshold := range o.Thresholds {
+		// The thresholds of the notifications of a budget are percentages up to 1000
+		if threshold <= 0 || threshold > 1000 {
+			return fmt.Errorf("invalid budget threshold %g, thresholds are percentages between 0 and 1000", threshold)
+		}
+	}
+
+	if len(o.ContactEmails) == 0 && len(o.ActionGroups) == 0 {
+		return errors.New("the budget has no contacts to alert, set budget.contactEmails or budget.actionGroups")
+	}
+
+	return nil
+}
+
+// ThresholdPercentages returns the percentages of the amount the cost is alerted at
+func (o *BudgetOptions) ThresholdPercentages() []float64 {
+	if len(o.Thresholds) == 0 {
+		return defaultBudgetThresholds
+	}
+
+	return o.Thresholds
+}
+
+// The characters not allowed in the name of a budget
+var invalidBudgetNameCharsRegex = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// BudgetName returns the name of the budget of the environment
+func BudgetName(envName string) string {
+	name := "azd-" + invalidBudgetNameCharsRegex.ReplaceAllString(envName, "-")
+
+	// The names of budgets are up to 63 characters
+	if len(name) > 63 {
+		name = name[:63]
+	}
+
+	return name
+}
+
+// SetBudget creates or updates the budget of the environment, counting the costs of its resource groups, and returns
+// the name of the budget. Returns an empty name when no budget is configured.
+func SetBudget(
+	ctx context.Context,
+	azCli azcli.AzCli,
+	options *BudgetOptions,
+	env *environment.Environment,
+	resourceGroups []string,
+) (string, error) {
+	if options == nil {
+		return "", nil
+	}
+
+	if err := options.Validate(); err != nil {
+		return "", err
+	}
+
+	// Without resource groups, the budget would count the costs of the whole subscription
+	if len(resourceGroups) == 0 {
+		return "", fmt.Errorf("the environment %s has no resource groups to set the budget on", env.GetEnvName())
+	}
+
+	name := BudgetName(env.GetEnvName())
+	existing, err := azCli.GetBudget(ctx, env.GetSubscriptionId(), name)
+	if err != nil {
+		return "", err
+	}
+
+	budget := newBudget(options, resourceGroups, time.Now())
+
+	// The start date of a budget can't be moved once the budget exists
+	if existing != nil {
+		budget.ETag = existing.ETag
+		budget.Properties.TimePeriod = existing.Properties.TimePeriod
+	}
+
+	if _, err := azCli.CreateOrUpdateBudget(ctx, env.GetSubscriptionId(), name, budget); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// newBudget returns the budget of the options for the resource groups, starting on the first day of the current month
+func newBudget(options *BudgetOptions, resourceGroups []string, now time.Time) azsdk.Budget {
+	timeGrain := "Monthly"
+	for _, grain := range budgetTimeGrains {
+		if strings.EqualFold(grain, options.TimeGrain) {
+			timeGrain = grain
+		}
+	}
+
+	now = now.UTC()
+	startDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	notifications := map[string]azsdk.BudgetNotification{}
+	for _, threshold := range options.ThresholdPercentages() {
+		notifications[fmt.Sprintf("actual_GreaterThan_%g_Percent", threshold)] = azsdk.BudgetNotification{
+			Enabled:       true,
+			Operator:      "GreaterThan",
+			Threshold:     threshold,
+			ThresholdType: "Actual",
+			ContactEmails: options.ContactEmails,
+			ContactGroups: options.ActionGroups,
+		}
+	}
+
+	return azsdk.Budget{
+		Properties: azsdk.BudgetProperties{
+			Category:  "Cost",
+			Amount:    options.Amount,
+			TimeGrain: timeGrain,
+			TimePeriod: azsdk.BudgetTimePeriod{
+				StartDate: startDate.Format(time.RFC3339),
+			},
+			Filter: &azsdk.BudgetFilter{
+				Dimensions: &azsdk.BudgetComparisonExpression{
+					Name:     "ResourceGroupName",
+					Operator: "In",
+					Values:   resourceGroups,
+				},
+			},
+			Notifications: notifications,
+		},
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,72 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudgetOptionsValidate(t *testing.T) {
+	valid := BudgetOptions{Amount: 100, ContactEmails: []string{"team@contoso.com"}}
+	require.NoError(t, valid.Validate())
+
+	tests := map[string]BudgetOptions{
+		"NoAmount":         {ContactEmails: []string{"team@contoso.com"}},
+		"InvalidTimeGrain": {Amount: 100, TimeGrain: "Weekly", ContactEmails: []string{"team@contoso.com"}},
+		"InvalidThreshold": {Amount: 100, Thresholds: []float64{1500}, ContactEmails: []string{"team@contoso.com"}},
+		"NoContacts":       {Amount: 100},
+	}
+
+	for name, options := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Error(t, options.Validate())
+		})
+	}
+}
+
+func TestBudgetName(t *testing.T) {
+	require.Equal(t, "azd-dev", BudgetName("dev"))
+	require.Equal(t, "azd-my-env-1-", BudgetName("my.env(1)"))
+}
+
+func TestNewBudget(t *testing.T) {
+	options := &BudgetOptions{
+		Amount:        250,
+		TimeGrain:     "quarterly",
+		Thresholds:    []float64{50, 90.5},
+		ContactEmails: []string{"team@contoso.com"},
+		ActionGroups:  []string{"/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg/providers/microsoft.insights/actionGroups/ag"},
+	}
+
+	budget := newBudget(options, []string{"rg-dev"}, time.Date(2026, 10, 15, 23, 0, 0, 0, time.UTC))
+	require.Equal(t, "Cost", budget.Properties.Category)
+	require.Equal(t, 250.0, budget.Properties.Amount)
+	require.Equal(t, "Quarterly", budget.Properties.TimeGrain)
+	require.Equal(t, "2026-10-01T00:00:00Z", budget.Properties.TimePeriod.StartDate)
+	require.Equal(t, "ResourceGroupName", budget.Properties.Filter.Dimensions.Name)
+	require.Equal(t, []string{"rg-dev"}, budget.Properties.Filter.Dimensions.Values)
+
+	require.Len(t, budget.Properties.Notifications, 2)
+	notification := budget.Properties.Notifications["actual_GreaterThan_90.5_Percent"]
+	require.Equal(t, 90.5, notification.Threshold)
+	require.Equal(t, options.ContactEmails, notification.ContactEmails)
+	require.Equal(t, options.ActionGroups, notification.ContactGroups)
+}
+
+func TestSetBudgetNotConfigured(t *testing.T) {
+	name, err := SetBudget(context.Background(), nil, nil, environment.Ephemeral(), nil)
+	require.NoError(t, err)
+	require.Empty(t, name)
+}
+
+func TestSetBudgetWithoutResourceGroups(t *testing.T) {
+	options := &BudgetOptions{Amount: 100, ContactEmails: []string{"team@contoso.com"}}
+	_, err := SetBudget(context.Background(), nil, options, environment.Ephemeral(), nil)
+	require.Error(t, err)
+}
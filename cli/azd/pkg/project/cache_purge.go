@@ -0,0 +1,68 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+)
+
+// The paths purged when the options don't set any, all the content of the endpoint
+var defaultCachePurgePaths = []string{"/*"}
+
+// The types of the endpoints whose cache can be purged: CDN endpoints, Front Door Standard/Premium endpoints and
+// Front Door (classic) profiles
+var cachePurgeEndpointTypes = []string{
+	"Microsoft.Cdn/profiles/endpoints",
+	"Microsoft.Cdn/profiles/afdEndpoints",
+	"Microsoft.Network/frontDoors",
+}
+
+// CachePurgeOptions purges the content cached by the Azure Front Door or CDN endpoint fronting a service once the
+// service is deployed, so the new content is served right away
+type CachePurgeOptions struct {
+	// The resource id of the endpoint, typically an output of the infrastructure, i.e. ${AZURE_FRONT_DOOR_ENDPOINT_ID}
+	Endpoint string `yaml:"endpoint"`
+	// The paths purged, i.e. /index.html or /images/*, all the content of the endpoint when empty
+	Paths []string `yaml:"paths,omitempty"`
+}
+
+// Validate returns an error when the options don't reference an endpoint whose cache can be purged
+func (o *CachePurgeOptions) Validate() error {
+	if strings.TrimSpace(o.Endpoint) == "" {
+		return errors.New(
+			"cachePurge is missing the resource id of the endpoint, set it from an output of the infrastructure")
+	}
+
+	resourceId, err := arm.ParseResourceID(o.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid cachePurge endpoint '%s': %w", o.Endpoint, err)
+	}
+
+	if !containsFold(cachePurgeEndpointTypes, resourceId.ResourceType.String()) {
+		return fmt.Errorf(
+			"invalid cachePurge endpoint '%s', supported resource types are %s",
+			o.Endpoint, strings.Join(cachePurgeEndpointTypes, ", "))
+	}
+
+	for _, path := range o.Paths {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("invalid cachePurge path '%s', the paths start with /", path)
+		}
+	}
+
+	return nil
+}
+
+// ContentPaths returns the paths purged from the cache of the endpoint
+func (o *CachePurgeOptions) ContentPaths() []string {
+	if len(o.Paths) == 0 {
+		return defaultCachePurgePaths
+	}
+
+	return o.Paths
+}
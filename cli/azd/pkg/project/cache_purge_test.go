@@ -0,0 +1,74 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCachePurge(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  web:
+    project: src/web
+    host: staticwebapp
+    cachePurge:
+      endpoint: ${AZURE_FRONT_DOOR_ENDPOINT_ID}
+      paths:
+        - /index.html
+`
+	endpointId := "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev/providers/Microsoft.Cdn/profiles/afd-dev" +
+		"/afdEndpoints/web-dev"
+	env := environment.EphemeralWithValues("test", map[string]string{
+		"AZURE_FRONT_DOOR_ENDPOINT_ID": endpointId,
+	})
+
+	projectConfig, err := ParseProjectConfig(testProj, env)
+	require.NoError(t, err)
+
+	cachePurge := projectConfig.Services["web"].CachePurge
+	require.NoError(t, cachePurge.Validate())
+	require.Equal(t, endpointId, cachePurge.Endpoint)
+	require.Equal(t, []string{"/index.html"}, cachePurge.ContentPaths())
+}
+
+func TestCachePurgeOptionsValidate(t *testing.T) {
+	const profile = "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev/providers/Microsoft.Cdn/profiles/cdn-dev"
+
+	tests := []struct {
+		name        string
+		options     CachePurgeOptions
+		expectError bool
+	}{
+		{name: "CdnEndpoint", options: CachePurgeOptions{Endpoint: profile + "/endpoints/web"}},
+		{
+			name: "FrontDoorClassic",
+			options: CachePurgeOptions{
+				Endpoint: "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev/providers/Microsoft.Network/frontDoors/fd",
+			},
+		},
+		{name: "MissingEndpoint", options: CachePurgeOptions{}, expectError: true},
+		{name: "InvalidResourceId", options: CachePurgeOptions{Endpoint: "web-dev"}, expectError: true},
+		{name: "UnsupportedResourceType", options: CachePurgeOptions{Endpoint: profile}, expectError: true},
+		{
+			name:        "RelativePath",
+			options:     CachePurgeOptions{Endpoint: profile + "/endpoints/web", Paths: []string{"images/*"}},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.options.Validate()
+			if test.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, []string{"/*"}, test.options.ContentPaths())
+		})
+	}
+}
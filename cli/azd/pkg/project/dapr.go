@@ -0,0 +1,67 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// The version of a Dapr component when its configuration doesn't set one
+const defaultDaprComponentVersion = "v1"
+
+// DaprOptions are the Dapr options of a service hosted in Container Apps
+type DaprOptions struct {
+	// The Dapr components created or updated in the Container Apps environment of the service when it's deployed
+	Components []DaprComponentConfig `yaml:"components"`
+}
+
+// DaprComponentConfig is a Dapr component, whose metadata is typically populated from the outputs of the
+// infrastructure, i.e. ${SERVICEBUS_NAMESPACE}
+type DaprComponentConfig struct {
+	Name string `yaml:"name"`
+	// The type of the component, i.e. pubsub.azure.servicebus
+	Type    string `yaml:"type"`
+	Version string `yaml:"version"`
+	// The metadata of the component
+	Metadata map[string]string `yaml:"metadata"`
+	// The sensitive metadata of the component, i.e. connection strings, stored as secrets of the component
+	SecretMetadata map[string]string `yaml:"secretMetadata"`
+	// The Dapr app ids of the applications using the component, all the applications of the environment when empty
+	Scopes []string `yaml:"scopes"`
+}
+
+// daprComponent validates the configuration of the component and converts it to the component set in Azure
+func (c DaprComponentConfig) daprComponent() (azcli.DaprComponent, error) {
+	if c.Name == "" {
+		return azcli.DaprComponent{}, errors.New("dapr component is missing a name")
+	}
+
+	if c.Type == "" {
+		return azcli.DaprComponent{}, fmt.Errorf("dapr component %s is missing a type", c.Name)
+	}
+
+	for name := range c.SecretMetadata {
+		if _, has := c.Metadata[name]; has {
+			return azcli.DaprComponent{}, fmt.Errorf(
+				"dapr component %s sets %s in both metadata and secretMetadata", c.Name, name)
+		}
+	}
+
+	version := c.Version
+	if version == "" {
+		version = defaultDaprComponentVersion
+	}
+
+	return azcli.DaprComponent{
+		Name:           c.Name,
+		ComponentType:  c.Type,
+		Version:        version,
+		Metadata:       c.Metadata,
+		SecretMetadata: c.SecretMetadata,
+		Scopes:         c.Scopes,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDaprComponents(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  api:
+    project: src/api
+    host: containerapp
+    dapr:
+      components:
+        - name: pubsub
+          type: pubsub.azure.servicebus
+          metadata:
+            namespaceName: ${SERVICEBUS_NAMESPACE}
+          secretMetadata:
+            connectionString: ${SERVICEBUS_CONNECTION_STRING}
+          scopes:
+            - api
+`
+	env := environment.EphemeralWithValues("test", map[string]string{
+		"SERVICEBUS_NAMESPACE":         "sb-test",
+		"SERVICEBUS_CONNECTION_STRING": "Endpoint=sb://sb-test",
+	})
+
+	projectConfig, err := ParseProjectConfig(testProj, env)
+	require.NoError(t, err)
+
+	component, err := projectConfig.Services["api"].Dapr.Components[0].daprComponent()
+	require.NoError(t, err)
+	require.Equal(t, azcli.DaprComponent{
+		Name:           "pubsub",
+		ComponentType:  "pubsub.azure.servicebus",
+		Version:        "v1",
+		Metadata:       map[string]string{"namespaceName": "sb-test"},
+		SecretMetadata: map[string]string{"connectionString": "Endpoint=sb://sb-test"},
+		Scopes:         []string{"api"},
+	}, component)
+}
+
+func TestDaprComponentValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		component DaprComponentConfig
+		err       string
+	}{
+		{"MissingName", DaprComponentConfig{Type: "state.redis"}, "dapr component is missing a name"},
+		{"MissingType", DaprComponentConfig{Name: "state"}, "dapr component state is missing a type"},
+		{
+			"MetadataAndSecret",
+			DaprComponentConfig{
+				Name:           "state",
+				Type:           "state.redis",
+				Metadata:       map[string]string{"redisPassword": "value"},
+				SecretMetadata: map[string]string{"redisPassword": "value"},
+			},
+			"dapr component state sets redisPassword in both metadata and secretMetadata",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.component.daprComponent()
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
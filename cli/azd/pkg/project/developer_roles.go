@@ -0,0 +1,84 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// developerRole is the data plane role granted to the developer on the resources of a type
+type developerRole struct {
+	// The name of the built-in Azure RBAC role, or the name of the role displayed for Cosmos DB
+	roleName string
+	// The id of the built-in SQL role definition of Cosmos DB accounts, the data plane roles of Cosmos DB aren't
+	// Azure RBAC roles
+	cosmosRoleDefinitionId string
+}
+
+// The data plane roles granted to the developer, by resource type. The roles let the services read and write the data
+// of the resources when they run locally with the credential of the developer.
+var developerRoles = map[string]developerRole{
+	"microsoft.storage/storageaccounts": {roleName: "Storage Blob Data Contributor"},
+	// Only effective on the key vaults using the Azure RBAC permission model, not on those using access policies
+	"microsoft.keyvault/vaults": {roleName: "Key Vault Secrets User"},
+	"microsoft.documentdb/databaseaccounts": {
+		roleName:               "Cosmos DB Built-in Data Contributor",
+		cosmosRoleDefinitionId: azsdk.CosmosDataContributorRoleId,
+	},
+}
+
+// DeveloperRoleAssignment is a data plane role assigned to the developer on a resource of the environment
+type DeveloperRoleAssignment struct {
+	ResourceName string
+	RoleName     string
+}
+
+// AssignDeveloperRoles assigns the signed-in developer the data plane roles of the storage accounts, key vaults and
+// Cosmos DB accounts of the resource groups of the environment, so the services can run locally right after
+// provisioning. The roles already assigned are assigned again without error.
+func AssignDeveloperRoles(
+	ctx context.Context,
+	azCli azcli.AzCli,
+	env *environment.Environment,
+	resourceGroups []string,
+) ([]DeveloperRoleAssignment, error) {
+	assignments := []DeveloperRoleAssignment{}
+
+	for _, resourceGroup := range resourceGroups {
+		resources, err := azCli.ListResourceGroupResources(ctx, env.GetSubscriptionId(), resourceGroup, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing the resources of resource group %s: %w", resourceGroup, err)
+		}
+
+		for _, resource := range resources {
+			role, has := developerRoles[strings.ToLower(resource.Type)]
+			if !has {
+				continue
+			}
+
+			if role.cosmosRoleDefinitionId != "" {
+				err = azCli.AssignCosmosRoleToSignedInUser(ctx, resource.Id, role.cosmosRoleDefinitionId)
+			} else {
+				err = azCli.AssignRoleToSignedInUser(ctx, env.GetSubscriptionId(), resource.Id, role.roleName)
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("assigning the %s role on %s: %w", role.roleName, resource.Name, err)
+			}
+
+			assignments = append(assignments, DeveloperRoleAssignment{
+				ResourceName: resource.Name,
+				RoleName:     role.roleName,
+			})
+		}
+	}
+
+	return assignments, nil
+}
@@ -0,0 +1,101 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	graphsdk_mocks "github.com/azure/azure-dev/cli/azd/test/mocks/graphsdk"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssignDeveloperRoles(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	graphsdk_mocks.RegisterMeGetMock(mockContext, http.StatusOK, &graphsdk.UserProfile{Id: "USER_ID"})
+	graphsdk_mocks.RegisterRoleDefinitionListMock(mockContext, http.StatusOK, []*armauthorization.RoleDefinition{
+		{ID: convert.RefOf("ROLE_ID"), Name: convert.RefOf("ROLE_NAME")},
+	})
+
+	groupId := "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-dev/providers/"
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/resourceGroups/rg-dev/resources")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		resources := []*armresources.GenericResourceExpanded{}
+		for name, resourceType := range map[string]string{
+			"st":     "Microsoft.Storage/storageAccounts",
+			"kv":     "Microsoft.KeyVault/vaults",
+			"cosmos": "Microsoft.DocumentDB/databaseAccounts",
+			"app":    "Microsoft.Web/sites",
+		} {
+			resources = append(resources, &armresources.GenericResourceExpanded{
+				ID:       convert.RefOf(groupId + resourceType + "/" + name),
+				Name:     convert.RefOf(name),
+				Type:     convert.RefOf(resourceType),
+				Location: convert.RefOf("eastus2"),
+			})
+		}
+
+		return mocks.CreateHttpResponseWithBody(
+			request, http.StatusOK, armresources.ResourceListResult{Value: resources})
+	})
+
+	scopes := []string{}
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut &&
+			strings.Contains(request.URL.Path, "/providers/Microsoft.Authorization/roleAssignments/")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		scopes = append(scopes, strings.Split(request.URL.Path, "/providers/Microsoft.Authorization/")[0])
+		return mocks.CreateHttpResponseWithBody(request, http.StatusCreated, armauthorization.RoleAssignment{
+			ID: convert.RefOf("ASSIGNMENT_ID"),
+		})
+	})
+
+	var cosmosAssignment azsdk.CosmosSqlRoleAssignment
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && strings.Contains(request.URL.Path, "/sqlRoleAssignments/")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		if err := json.NewDecoder(request.Body).Decode(&cosmosAssignment); err != nil {
+			return nil, err
+		}
+
+		return mocks.CreateEmptyHttpResponse(request, http.StatusAccepted)
+	})
+
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+	})
+
+	assignments, err := AssignDeveloperRoles(
+		*mockContext.Context, azcli.GetAzCli(*mockContext.Context), env, []string{"rg-dev"})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []DeveloperRoleAssignment{
+		{ResourceName: "st", RoleName: "Storage Blob Data Contributor"},
+		{ResourceName: "kv", RoleName: "Key Vault Secrets User"},
+		{ResourceName: "cosmos", RoleName: "Cosmos DB Built-in Data Contributor"},
+	}, assignments)
+
+	require.ElementsMatch(t, []string{
+		groupId + "Microsoft.Storage/storageAccounts/st",
+		groupId + "Microsoft.KeyVault/vaults/kv",
+	}, scopes)
+
+	cosmosId := groupId + "Microsoft.DocumentDB/databaseAccounts/cosmos"
+	require.Equal(t, "USER_ID", cosmosAssignment.Properties.PrincipalId)
+	require.Equal(t, cosmosId, cosmosAssignment.Properties.Scope)
+	require.Equal(t,
+		cosmosId+"/sqlRoleDefinitions/"+azsdk.CosmosDataContributorRoleId,
+		cosmosAssignment.Properties.RoleDefinitionId)
+}
@@ -17,6 +17,8 @@ type DockerProjectOptions struct {
 	Path     string `json:"path"`
 	Context  string `json:"context"`
 	Platform string `json:"platform"`
+	// How the container registry is authenticated with when pushing the image, see containerregistry.AuthMode
+	RegistryAuth string `json:"registryAuth" yaml:"registryAuth"`
 }
 
 type dockerProject struct {
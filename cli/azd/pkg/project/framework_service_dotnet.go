@@ -65,6 +65,11 @@ func (dp *dotnetProject) setUserSecretsFromOutputs(ctx context.Context, args Ser
 	}
 
 	for key, val := range bicepOutput {
+		// ARM doesn't return the values of secure outputs
+		if val.Secure && val.Value == nil {
+			continue
+		}
+
 		if err := dp.dotnetCli.SetSecret(ctx, normalizeDotNetSecret(key), fmt.Sprint(val.Value), dp.config.Path()); err != nil {
 			return err
 		}
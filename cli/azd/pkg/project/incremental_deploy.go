@@ -0,0 +1,187 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/rzip"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// deployManifest records the files of the latest deployment of a service to an App Service, for the next deployment
+// to only upload the files changed since
+type deployManifest struct {
+	// The resource id of the App Service deployed to
+	ResourceId string `json:"resourceId"`
+	// The id of the deployment, which changes when the application is deployed from elsewhere
+	DeploymentId string `json:"deploymentId"`
+	// The SHA-256 hash of each file, by its path relative to the package
+	Files map[string]string `json:"files"`
+}
+
+// newDeployManifest hashes the files of the package deployed to the App Service
+func newDeployManifest(resourceId string, files []rzip.File) (*deployManifest, error) {
+	manifest := &deployManifest{
+		ResourceId: resourceId,
+		Files:      map[string]string{},
+	}
+
+	for _, file := range files {
+		hash, err := hashFile(file.Path)
+		if err != nil {
+			return nil, fmt.Errorf("hashing %s: %w", file.Name, err)
+		}
+
+		manifest.Files[file.Name] = hash
+	}
+
+	return manifest, nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// deployManifestPath returns the path of the manifest of the latest deployment of the service, stored with its
+// environment, i.e. .azure/dev/deploy/web.json
+func deployManifestPath(azdCtx *azdcontext.AzdContext, env *environment.Environment, serviceName string) string {
+	return filepath.Join(azdCtx.EnvironmentDirectory(), env.GetEnvName(), "deploy", serviceName+".json")
+}
+
+// loadDeployManifest reads the manifest of the latest deployment, nil when the service wasn't deployed from here
+func loadDeployManifest(path string) (*deployManifest, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var manifest deployManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("reading deploy manifest %s: %w", path, err)
+	}
+
+	return &manifest, nil
+}
+
+func (m *deployManifest) save(path string) error {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, osutil.PermissionFile)
+}
+
+// changedFiles returns the files added or changed since the previous deployment. It returns false when files were
+// removed since, an incremental deployment keeping them on the App Service.
+func (m *deployManifest) changedFiles(previous *deployManifest, files []rzip.File) ([]rzip.File, bool) {
+	for name := range previous.Files {
+		if _, has := m.Files[name]; !has {
+			return nil, false
+		}
+	}
+
+	changed := []rzip.File{}
+	for _, file := range files {
+		if previous.Files[file.Name] != m.Files[file.Name] {
+			changed = append(changed, file)
+		}
+	}
+
+	return changed, true
+}
+
+// supportsIncrementalDeploy returns true when the App Service runs from its files as deployed, so the files of an
+// incremental deployment are added to the files of the previous deployments. The application can't run from a
+// package nor be built by the deployment, which only sees the changed files.
+func supportsIncrementalDeploy(settings map[string]string) bool {
+	if value := settings["WEBSITE_RUN_FROM_PACKAGE"]; value != "" && value != "0" {
+		return false
+	}
+
+	switch strings.ToLower(settings["SCM_DO_BUILD_DURING_DEPLOYMENT"]) {
+	case "true", "1":
+		return false
+	}
+
+	return true
+}
+
+// incrementalDeployFiles returns the files changed since the previous deployment of the service to the App Service.
+// It returns false when the whole package must be deployed: when the service wasn't deployed from here, when files
+// were removed, when the App Service doesn't support incremental deployments or when it was deployed from elsewhere.
+func incrementalDeployFiles(
+	ctx context.Context,
+	cli azcli.AzCli,
+	env *environment.Environment,
+	scope *environment.DeploymentScope,
+	previous *deployManifest,
+	manifest *deployManifest,
+	files []rzip.File,
+) ([]rzip.File, bool, error) {
+	if previous == nil || !strings.EqualFold(previous.ResourceId, manifest.ResourceId) {
+		return nil, false, nil
+	}
+
+	changed, ok := manifest.changedFiles(previous, files)
+	if !ok {
+		log.Printf("files were removed since the previous deployment of %s, deploying the whole package", scope.ResourceName())
+		return nil, false, nil
+	}
+
+	settings, err := cli.GetAppServiceAppSettings(
+		ctx, env.GetSubscriptionId(), scope.ResourceGroupName(), scope.ResourceName())
+	if err != nil {
+		return nil, false, fmt.Errorf("getting application settings: %w", err)
+	}
+
+	if !supportsIncrementalDeploy(settings) {
+		log.Printf("%s doesn't support incremental deployments, deploying the whole package", scope.ResourceName())
+		return nil, false, nil
+	}
+
+	deploymentId, err := cli.GetAppServiceLatestDeploymentId(
+		ctx, env.GetSubscriptionId(), scope.ResourceGroupName(), scope.ResourceName())
+	if err != nil {
+		return nil, false, err
+	}
+
+	if deploymentId != previous.DeploymentId {
+		log.Printf("%s was deployed from elsewhere since, deploying the whole package", scope.ResourceName())
+		return nil, false, nil
+	}
+
+	return changed, true, nil
+}
@@ -0,0 +1,104 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/rzip"
+	"github.com/stretchr/testify/require"
+)
+
+const testWebsiteId = "/subscriptions/SUB/resourceGroups/RG/providers/Microsoft.Web/sites/web"
+
+func writeTestPackage(t *testing.T, contents map[string]string) []rzip.File {
+	source := t.TempDir()
+	for name, content := range contents {
+		require.NoError(t, os.WriteFile(filepath.Join(source, name), []byte(content), 0600))
+	}
+
+	files, err := rzip.Files(source, nil)
+	require.NoError(t, err)
+	return files
+}
+
+func TestDeployManifestChangedFiles(t *testing.T) {
+	previousFiles := writeTestPackage(t, map[string]string{"index.html": "v1", "app.js": "v1"})
+	previous, err := newDeployManifest(testWebsiteId, previousFiles)
+	require.NoError(t, err)
+
+	files := writeTestPackage(t, map[string]string{"index.html": "v1", "app.js": "v2", "new.css": "v1"})
+	manifest, err := newDeployManifest(testWebsiteId, files)
+	require.NoError(t, err)
+
+	changed, ok := manifest.changedFiles(previous, files)
+	require.True(t, ok)
+	var names []string
+	for _, file := range changed {
+		names = append(names, file.Name)
+	}
+	require.ElementsMatch(t, []string{"app.js", "new.css"}, names)
+
+	unchanged, ok := previous.changedFiles(previous, previousFiles)
+	require.True(t, ok)
+	require.Empty(t, unchanged)
+
+	removed := writeTestPackage(t, map[string]string{"index.html": "v1"})
+	removedManifest, err := newDeployManifest(testWebsiteId, removed)
+	require.NoError(t, err)
+	_, ok = removedManifest.changedFiles(previous, removed)
+	require.False(t, ok)
+}
+
+func TestDeployManifestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dev", "deploy", "web.json")
+
+	manifest, err := loadDeployManifest(path)
+	require.NoError(t, err)
+	require.Nil(t, manifest)
+
+	files := writeTestPackage(t, map[string]string{"index.html": "v1"})
+	manifest, err = newDeployManifest(testWebsiteId, files)
+	require.NoError(t, err)
+	manifest.DeploymentId = "ID"
+	require.NoError(t, manifest.save(path))
+
+	loaded, err := loadDeployManifest(path)
+	require.NoError(t, err)
+	require.Equal(t, manifest, loaded)
+}
+
+func TestSupportsIncrementalDeploy(t *testing.T) {
+	require.True(t, supportsIncrementalDeploy(map[string]string{}))
+	require.True(t, supportsIncrementalDeploy(map[string]string{"WEBSITE_RUN_FROM_PACKAGE": "0"}))
+	require.False(t, supportsIncrementalDeploy(map[string]string{"WEBSITE_RUN_FROM_PACKAGE": "1"}))
+	require.False(t, supportsIncrementalDeploy(map[string]string{"SCM_DO_BUILD_DURING_DEPLOYMENT": "True"}))
+}
+
+func TestIncrementalDeployFilesFullDeploy(t *testing.T) {
+	env := environment.Ephemeral()
+	scope := environment.NewDeploymentScope("SUB", "RG", "web")
+	files := writeTestPackage(t, map[string]string{"index.html": "v1"})
+	manifest, err := newDeployManifest(testWebsiteId, files)
+	require.NoError(t, err)
+
+	// The App Service isn't queried when the previous deployment rules out an incremental deployment
+	_, ok, err := incrementalDeployFiles(context.Background(), nil, env, scope, nil, manifest, files)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	other := &deployManifest{ResourceId: testWebsiteId + "-other", Files: manifest.Files}
+	_, ok, err = incrementalDeployFiles(context.Background(), nil, env, scope, other, manifest, files)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	removed := &deployManifest{ResourceId: testWebsiteId, Files: map[string]string{"index.html": "", "old.js": ""}}
+	_, ok, err = incrementalDeployFiles(context.Background(), nil, env, scope, removed, manifest, files)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
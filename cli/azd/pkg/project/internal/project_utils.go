@@ -6,20 +6,32 @@ package internal
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/rzip"
 )
 
-// CreateDeployableZip creates a zip file of a folder, recursively.
+// CreateDeployableZip creates a zip file of a folder, recursively, leaving out the files matching the exclude patterns.
 // Returns the path to the created zip file or an error if it fails.
-func CreateDeployableZip(appName string, path string) (string, error) {
-	// TODO: should probably avoid picking up files that weren't meant to be published (ie, local .env files, etc..)
+func CreateDeployableZip(appName string, path string, exclude []string) (string, error) {
+	files, err := rzip.Files(path, exclude)
+	if err != nil {
+		return "", fmt.Errorf("failed when listing the files to deploy %s: %w", appName, err)
+	}
+
+	return CreateDeployableZipOf(appName, files)
+}
+
+// CreateDeployableZipOf creates a zip file of the files of a folder, i.e. the files changed since the last deployment.
+// Returns the path to the created zip file or an error if it fails.
+func CreateDeployableZipOf(appName string, files []rzip.File) (string, error) {
 	zipFile, err := os.CreateTemp("", "azddeploy*.zip")
 	if err != nil {
 		return "", fmt.Errorf("failed when creating zip package to deploy %s: %w", appName, err)
 	}
 
-	if err := rzip.CreateFromDirectory(path, zipFile); err != nil {
+	if err := rzip.Write(files, zipFile); err != nil {
 		// if we fail here just do our best to close things out and cleanup
 		zipFile.Close()
 		os.Remove(zipFile.Name())
@@ -34,3 +46,13 @@ func CreateDeployableZip(appName string, path string) (string, error) {
 
 	return zipFile.Name(), nil
 }
+
+// IsZipFile returns true when the path is an existing zip file, which is deployed as is.
+func IsZipFile(path string) bool {
+	if !strings.EqualFold(filepath.Ext(path), ".zip") {
+		return false
+	}
+
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
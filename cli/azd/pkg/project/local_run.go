@@ -0,0 +1,101 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// The compose files of a service started with docker compose by azd run, in the order they're looked for
+var composeFileNames = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// LocalRunCommand returns the command line starting the service locally, run by `azd run` in the directory of the
+// service: the run command of the service, docker compose when the service has a compose file, or else the usual
+// command of its host or language.
+func (sc *ServiceConfig) LocalRunCommand() (string, error) {
+	if strings.TrimSpace(sc.Run) != "" {
+		return sc.Run, nil
+	}
+
+	for _, name := range composeFileNames {
+		if _, err := os.Stat(filepath.Join(sc.Path(), name)); err == nil {
+			return "docker compose up", nil
+		}
+	}
+
+	if ServiceTargetKind(sc.Host) == AzureFunctionTarget {
+		return "func start", nil
+	}
+
+	switch sc.Language {
+	case "", "dotnet", "csharp", "fsharp":
+		return "dotnet run", nil
+	case "js", "ts":
+		return "npm start", nil
+	default:
+		return "", fmt.Errorf(
+			"service '%s' has no default command to run it locally, set its run command in azure.yaml", sc.Name)
+	}
+}
+
+// LocalRunEnv returns the environment variables of the service started locally, emulating its wiring in Azure: the
+// values of the environment, including the outputs of the infrastructure, along with the app settings of the service
+// and the values of the secrets it references, read from the key vault of the environment.
+func LocalRunEnv(
+	ctx context.Context,
+	cli azcli.AzCli,
+	config *ServiceConfig,
+	env *environment.Environment,
+) ([]string, error) {
+	values := map[string]string{}
+	for name, value := range env.Values {
+		values[name] = value
+	}
+
+	for name, value := range config.AppSettings {
+		values[name] = value
+	}
+
+	if len(config.Secrets) > 0 {
+		endpoint := env.GetKeyVaultEndpoint()
+		if endpoint == "" {
+			return nil, fmt.Errorf(
+				"service %s references secrets but the environment has no key vault, ensure %s is set as an output of "+
+					"your infrastructure",
+				config.Name,
+				environment.KeyVaultEndpointEnvVarName,
+			)
+		}
+
+		for setting, secretName := range config.Secrets {
+			secret, err := cli.GetKeyVaultSecret(ctx, endpoint, secretName)
+			if err != nil {
+				return nil, fmt.Errorf("reading secret '%s' of service %s: %w", secretName, config.Name, err)
+			}
+
+			values[setting] = secret.Value
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	vars := make([]string, 0, len(names))
+	for _, name := range names {
+		vars = append(vars, fmt.Sprintf("%s=%s", name, values[name]))
+	}
+
+	return vars, nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalRunCommand(t *testing.T) {
+	newConfig := func(language string, host string) *ServiceConfig {
+		return &ServiceConfig{
+			Name:         "api",
+			Language:     language,
+			Host:         host,
+			RelativePath: "api",
+			Project:      &ProjectConfig{Path: t.TempDir()},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		config   *ServiceConfig
+		expected string
+	}{
+		{"DotNet", newConfig("csharp", "appservice"), "dotnet run"},
+		{"Npm", newConfig("ts", "containerapp"), "npm start"},
+		{"Functions", newConfig("js", "function"), "func start"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			command, err := test.config.LocalRunCommand()
+			require.NoError(t, err)
+			require.Equal(t, test.expected, command)
+		})
+	}
+
+	t.Run("RunCommand", func(t *testing.T) {
+		config := newConfig("py", "appservice")
+		_, err := config.LocalRunCommand()
+		require.Error(t, err)
+
+		config.Run = "python -m flask run"
+		command, err := config.LocalRunCommand()
+		require.NoError(t, err)
+		require.Equal(t, "python -m flask run", command)
+	})
+
+	t.Run("Compose", func(t *testing.T) {
+		config := newConfig("py", "containerapp")
+		require.NoError(t, os.MkdirAll(config.Path(), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(config.Path(), "compose.yaml"), []byte("services: {}"), 0600))
+
+		command, err := config.LocalRunCommand()
+		require.NoError(t, err)
+		require.Equal(t, "docker compose up", command)
+	})
+}
+
+func TestLocalRunEnv(t *testing.T) {
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		"AZURE_LOCATION": "eastus2",
+		"LOG_LEVEL":      "info",
+	})
+	config := &ServiceConfig{
+		Name:        "api",
+		AppSettings: map[string]string{"LOG_LEVEL": "debug"},
+	}
+
+	vars, err := LocalRunEnv(context.Background(), nil, config, env)
+	require.NoError(t, err)
+	require.Equal(t, []string{"AZURE_ENV_NAME=dev", "AZURE_LOCATION=eastus2", "LOG_LEVEL=debug"}, vars)
+
+	config.Secrets = map[string]string{"DB_PASSWORD": "db-password"}
+	_, err = LocalRunEnv(context.Background(), nil, config, env)
+	require.ErrorContains(t, err, environment.KeyVaultEndpointEnvVarName)
+}
@@ -0,0 +1,182 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// NotificationOptions configures a notification sink, posted a summary of the run after provisioning or deploying
+type NotificationOptions struct {
+	// The kind of the sink: webhook, teams or slack
+	Type string `yaml:"type"`
+	// The URL the summary is posted to, environment values can be referenced as ${NAME}
+	Url string `yaml:"url"`
+	// The operations notified: provision, deploy or up, defaults to all of them
+	Events []string `yaml:"events,omitempty"`
+}
+
+var notificationTypes = []string{"webhook", "teams", "slack"}
+
+var notificationEvents = []string{"provision", "deploy", "up"}
+
+// Validate returns an error when the summary can't be posted to the sink of the options
+func (o *NotificationOptions) Validate() error {
+	if !containsFold(notificationTypes, o.Type) {
+		return fmt.Errorf(
+			"invalid notification type '%s', supported values are %s", o.Type, strings.Join(notificationTypes, ", "))
+	}
+
+	if !strings.HasPrefix(o.Url, "https://") && !strings.HasPrefix(o.Url, "http://") {
+		return fmt.Errorf("invalid notification url '%s', the url must be an http or https url", o.Url)
+	}
+
+	for _, event := range o.Events {
+		if !containsFold(notificationEvents, event) {
+			return fmt.Errorf(
+				"invalid notification event '%s', supported values are %s", event, strings.Join(notificationEvents, ", "))
+		}
+	}
+
+	return nil
+}
+
+// Notifies returns true when the sink is notified of the operation
+func (o *NotificationOptions) Notifies(operation string) bool {
+	return len(o.Events) == 0 || containsFold(o.Events, operation)
+}
+
+// NotificationSummary is the summary of a provision or deploy run posted to the notification sinks
+type NotificationSummary struct {
+	Project     string `json:"project"`
+	Environment string `json:"environment"`
+	Operation   string `json:"operation"`
+	// succeeded or failed
+	Status string `json:"status"`
+	// The duration of the run, in seconds
+	Duration float64 `json:"durationSeconds"`
+	// The number of resources of the environment after the run, -1 when unknown
+	ResourceCount int    `json:"resourceCount"`
+	Error         string `json:"error,omitempty"`
+}
+
+// NewNotificationSummary returns the summary of an operation that ran for the duration and failed with err, when set
+func NewNotificationSummary(
+	projectName string,
+	envName string,
+	operation string,
+	duration time.Duration,
+	resourceCount int,
+	err error,
+) NotificationSummary {
+	summary := NotificationSummary{
+		Project:       projectName,
+		Environment:   envName,
+		Operation:     operation,
+		Status:        "succeeded",
+		Duration:      duration.Round(time.Second).Seconds(),
+		ResourceCount: resourceCount,
+	}
+
+	if err != nil {
+		summary.Status = "failed"
+		summary.Error = err.Error()
+	}
+
+	return summary
+}
+
+// Text returns the summary as a single line of text
+func (s NotificationSummary) Text() string {
+	text := fmt.Sprintf(
+		"azd %s of %s (environment %s) %s in %s",
+		s.Operation, s.Project, s.Environment, s.Status, time.Duration(s.Duration)*time.Second)
+
+	if s.ResourceCount >= 0 {
+		text += fmt.Sprintf(", %d resources", s.ResourceCount)
+	}
+
+	if s.Error != "" {
+		text += ": " + s.Error
+	}
+
+	return text
+}
+
+// notificationPayload returns the body of the request posting the summary to the sink of the type
+func notificationPayload(notificationType string, summary NotificationSummary) any {
+	switch strings.ToLower(notificationType) {
+	case "slack":
+		return map[string]string{"text": summary.Text()}
+	case "teams":
+		themeColor := "2EB886"
+		if summary.Error != "" {
+			themeColor = "D40E0D"
+		}
+
+		facts := []map[string]string{
+			{"name": "Project", "value": summary.Project},
+			{"name": "Environment", "value": summary.Environment},
+			{"name": "Status", "value": summary.Status},
+			{"name": "Duration", "value": (time.Duration(summary.Duration) * time.Second).String()},
+		}
+		if summary.ResourceCount >= 0 {
+			facts = append(facts, map[string]string{"name": "Resources", "value": fmt.Sprint(summary.ResourceCount)})
+		}
+		if summary.Error != "" {
+			facts = append(facts, map[string]string{"name": "Error", "value": summary.Error})
+		}
+
+		return map[string]any{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    summary.Text(),
+			"themeColor": themeColor,
+			"title":      fmt.Sprintf("azd %s %s", summary.Operation, summary.Status),
+			"sections":   []map[string]any{{"facts": facts}},
+		}
+	default:
+		return summary
+	}
+}
+
+// SendNotification posts the summary to the sink of the options
+func SendNotification(ctx context.Context, options NotificationOptions, summary NotificationSummary) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(notificationPayload(options.Type, summary))
+	if err != nil {
+		return fmt.Errorf("marshalling notification: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, options.Url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating notification request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := httputil.GetHttpClient(ctx).Do(request)
+	if err != nil {
+		return fmt.Errorf("posting notification: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		responseBody, _ := io.ReadAll(response.Body)
+		return fmt.Errorf("posting notification: unexpected status %d: %s", response.StatusCode, string(responseBody))
+	}
+
+	return nil
+}
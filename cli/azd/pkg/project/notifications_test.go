@@ -0,0 +1,105 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationOptionsValidate(t *testing.T) {
+	valid := NotificationOptions{Type: "Teams", Url: "https://contoso.webhook.office.com/webhook", Events: []string{"up"}}
+	require.NoError(t, valid.Validate())
+
+	tests := map[string]NotificationOptions{
+		"InvalidType":  {Type: "email", Url: "https://contoso.com"},
+		"InvalidUrl":   {Type: "webhook", Url: "contoso.com"},
+		"InvalidEvent": {Type: "slack", Url: "https://hooks.slack.com/services/x", Events: []string{"destroy"}},
+	}
+
+	for name, options := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Error(t, options.Validate())
+		})
+	}
+}
+
+func TestNotificationOptionsNotifies(t *testing.T) {
+	all := NotificationOptions{Type: "webhook", Url: "https://contoso.com"}
+	require.True(t, all.Notifies("provision"))
+	require.True(t, all.Notifies("up"))
+
+	deploy := NotificationOptions{Type: "webhook", Url: "https://contoso.com", Events: []string{"deploy"}}
+	require.True(t, deploy.Notifies("deploy"))
+	require.False(t, deploy.Notifies("provision"))
+}
+
+func TestNotificationSummaryText(t *testing.T) {
+	summary := NewNotificationSummary("todo", "dev", "provision", 95*time.Second, 12, nil)
+	require.Equal(t, "azd provision of todo (environment dev) succeeded in 1m35s, 12 resources", summary.Text())
+
+	summary = NewNotificationSummary("todo", "dev", "deploy", 3*time.Second, -1, errors.New("deploying api"))
+	require.Equal(t, "azd deploy of todo (environment dev) failed in 3s: deploying api", summary.Text())
+}
+
+func TestSendNotification(t *testing.T) {
+	summary := NewNotificationSummary("todo", "dev", "up", time.Minute, 5, errors.New("provisioning failed"))
+
+	send := func(t *testing.T, notificationType string, statusCode int) (map[string]any, error) {
+		mockContext := mocks.NewMockContext(context.Background())
+		var payload map[string]any
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPost && request.URL.Host == "hooks.contoso.com"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			body, err := io.ReadAll(request.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(body, &payload))
+
+			return mocks.CreateEmptyHttpResponse(request, statusCode)
+		})
+
+		err := SendNotification(
+			*mockContext.Context,
+			NotificationOptions{Type: notificationType, Url: "https://hooks.contoso.com/notify"},
+			summary,
+		)
+		return payload, err
+	}
+
+	t.Run("Webhook", func(t *testing.T) {
+		payload, err := send(t, "webhook", http.StatusOK)
+		require.NoError(t, err)
+		require.Equal(t, "failed", payload["status"])
+		require.Equal(t, "dev", payload["environment"])
+		require.Equal(t, float64(60), payload["durationSeconds"])
+		require.Equal(t, float64(5), payload["resourceCount"])
+	})
+
+	t.Run("Slack", func(t *testing.T) {
+		payload, err := send(t, "slack", http.StatusOK)
+		require.NoError(t, err)
+		require.Equal(t, summary.Text(), payload["text"])
+	})
+
+	t.Run("Teams", func(t *testing.T) {
+		payload, err := send(t, "teams", http.StatusOK)
+		require.NoError(t, err)
+		require.Equal(t, "MessageCard", payload["@type"])
+		require.Equal(t, "D40E0D", payload["themeColor"])
+		require.Equal(t, "azd up failed", payload["title"])
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		_, err := send(t, "webhook", http.StatusBadRequest)
+		require.Error(t, err)
+	})
+}
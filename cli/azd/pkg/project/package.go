@@ -0,0 +1,114 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/rzip"
+)
+
+// The name of the manifest written to the output directory of `azd package`
+const PackageManifestFileName = "azd-package.json"
+
+// PackageManifest describes the packages built by `azd package`
+type PackageManifest struct {
+	Timestamp time.Time        `json:"timestamp"`
+	Services  []ServicePackage `json:"services"`
+}
+
+// ServicePackage is the package built for a service. Services hosted on Container Apps are packaged as a
+// container image, other services are packaged as a zip file within the output directory.
+type ServicePackage struct {
+	Name     string `json:"name"`
+	Host     string `json:"host"`
+	Language string `json:"language"`
+	// The path of the zip file, relative to the output directory
+	Package string `json:"package,omitempty"`
+	// The id of the container image
+	Image string `json:"image,omitempty"`
+}
+
+// PackageService builds the service with its framework service and writes the resulting package to the
+// output directory. The package can then be deployed, possibly on another machine, with `azd deploy`.
+func PackageService(
+	ctx context.Context,
+	config *ServiceConfig,
+	framework FrameworkService,
+	outputDir string,
+	progress chan<- string,
+) (*ServicePackage, error) {
+	log.Printf("packing service %s", config.Name)
+
+	artifact, err := framework.Package(ctx, progress)
+	if err != nil {
+		return nil, fmt.Errorf("packaging service %s: %w", config.Name, err)
+	}
+
+	servicePackage := &ServicePackage{
+		Name:     config.Name,
+		Host:     config.Host,
+		Language: config.Language,
+	}
+
+	if ServiceTargetKind(config.Host) == ContainerAppTarget {
+		servicePackage.Image = artifact
+		return servicePackage, nil
+	}
+
+	if err := os.MkdirAll(outputDir, osutil.PermissionDirectory); err != nil {
+		return nil, fmt.Errorf("creating output directory: %w", err)
+	}
+
+	servicePackage.Package = fmt.Sprintf("%s.zip", config.Name)
+	zipPath := filepath.Join(outputDir, servicePackage.Package)
+
+	progress <- "Compressing deployment artifacts"
+	if err := createZip(artifact, zipPath, config.Exclude); err != nil {
+		return nil, fmt.Errorf("compressing package for service %s: %w", config.Name, err)
+	}
+
+	log.Printf("packaged service %s to %s", config.Name, zipPath)
+	return servicePackage, nil
+}
+
+func createZip(source string, zipPath string, exclude []string) error {
+	zipFile, err := os.Create(zipPath)
+	if err != nil {
+		return err
+	}
+
+	if err := rzip.CreateFromDirectory(source, zipFile, exclude); err != nil {
+		zipFile.Close()
+		os.Remove(zipPath)
+		return err
+	}
+
+	return zipFile.Close()
+}
+
+// Save writes the manifest to the output directory
+func (m *PackageManifest) Save(outputDir string) error {
+	manifestJson, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serializing package manifest: %w", err)
+	}
+
+	if err := os.MkdirAll(outputDir, osutil.PermissionDirectory); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(outputDir, PackageManifestFileName), manifestJson, osutil.PermissionFile); err != nil {
+		return fmt.Errorf("writing package manifest: %w", err)
+	}
+
+	return nil
+}
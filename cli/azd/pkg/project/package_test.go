@@ -0,0 +1,91 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageService(t *testing.T) {
+	drainProgress := func() chan string {
+		progress := make(chan string)
+		go func() {
+			for range progress {
+			}
+		}()
+
+		return progress
+	}
+
+	t.Run("Zip", func(t *testing.T) {
+		publishDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(publishDir, "index.js"), []byte("//"), osutil.PermissionFile))
+		outputDir := filepath.Join(t.TempDir(), "packages")
+
+		config := &ServiceConfig{Name: "api", Host: string(AppServiceTarget), Language: "js"}
+		progress := drainProgress()
+		servicePackage, err := PackageService(
+			context.Background(),
+			config,
+			&mockFrameworkService{packagePath: publishDir},
+			outputDir,
+			progress,
+		)
+		close(progress)
+		require.NoError(t, err)
+		require.Equal(t, "api.zip", servicePackage.Package)
+		require.Empty(t, servicePackage.Image)
+
+		reader, err := zip.OpenReader(filepath.Join(outputDir, servicePackage.Package))
+		require.NoError(t, err)
+		defer reader.Close()
+		require.Len(t, reader.File, 1)
+		require.Equal(t, "index.js", reader.File[0].Name)
+	})
+
+	t.Run("ContainerImage", func(t *testing.T) {
+		outputDir := filepath.Join(t.TempDir(), "packages")
+
+		config := &ServiceConfig{Name: "web", Host: string(ContainerAppTarget), Language: "js"}
+		progress := drainProgress()
+		servicePackage, err := PackageService(
+			context.Background(),
+			config,
+			&mockFrameworkService{packagePath: "sha256:IMAGE_ID"},
+			outputDir,
+			progress,
+		)
+		close(progress)
+		require.NoError(t, err)
+		require.Equal(t, "sha256:IMAGE_ID", servicePackage.Image)
+		require.Empty(t, servicePackage.Package)
+
+		// Nothing is written to the output directory for container images
+		_, err = os.Stat(outputDir)
+		require.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestPackageManifestSave(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "packages")
+	manifest := PackageManifest{
+		Services: []ServicePackage{{Name: "api", Host: string(AppServiceTarget), Package: "api.zip"}},
+	}
+	require.NoError(t, manifest.Save(outputDir))
+
+	manifestJson, err := os.ReadFile(filepath.Join(outputDir, PackageManifestFileName))
+	require.NoError(t, err)
+
+	var loaded PackageManifest
+	require.NoError(t, json.Unmarshal(manifestJson, &loaded))
+	require.Equal(t, manifest.Services, loaded.Services)
+}
@@ -22,13 +22,17 @@ import (
 // When changing project structure, make sure to update the JSON schema file for azure.yaml (<workspace
 // root>/schemas/vN.M/azure.yaml.json).
 type ProjectConfig struct {
-	Name              string                    `yaml:"name"`
-	ResourceGroupName string                    `yaml:"resourceGroup,omitempty"`
-	Path              string                    `yaml:",omitempty"`
-	Metadata          *ProjectMetadata          `yaml:"metadata,omitempty"`
-	Services          map[string]*ServiceConfig `yaml:",omitempty"`
-	Infra             provisioning.Options      `yaml:"infra"`
-	Pipeline          PipelineOptions           `yaml:"pipeline"`
+	Name                 string                    `yaml:"name"`
+	ResourceGroupName    string                    `yaml:"resourceGroup,omitempty"`
+	Path                 string                    `yaml:",omitempty"`
+	Metadata             *ProjectMetadata          `yaml:"metadata,omitempty"`
+	Services             map[string]*ServiceConfig `yaml:",omitempty"`
+	Infra                provisioning.Options      `yaml:"infra"`
+	Pipeline             PipelineOptions           `yaml:"pipeline"`
+	AppConfiguration     *AppConfigurationOptions  `yaml:"appConfiguration,omitempty"`
+	Budget               *BudgetOptions            `yaml:"budget,omitempty"`
+	Notifications        []NotificationOptions     `yaml:"notifications,omitempty"`
+	AssignDeveloperRoles bool                      `yaml:"assignDeveloperRoles,omitempty"`
 
 	handlers map[Event][]ProjectLifecycleEventHandlerFn
 }
@@ -36,6 +40,17 @@ type ProjectConfig struct {
 // options supported in azure.yaml
 type PipelineOptions struct {
 	Provider string `yaml:"provider"`
+	// The stages of a multi-stage pipeline, each deploying an environment into its own subscription
+	Stages []PipelineStageOptions `yaml:"stages,omitempty"`
+	// Allows overriding the environment name and location of the pipeline when queuing a run, supported by Azure DevOps
+	AllowOverride bool `yaml:"allowOverride,omitempty"`
+}
+
+// PipelineStageOptions is a stage of a multi-stage pipeline
+type PipelineStageOptions struct {
+	Name string `yaml:"name"`
+	// The azd environment deployed by the stage, defaults to the name of the stage
+	Environment string `yaml:"environment,omitempty"`
 }
 
 // Project lifecycle events
@@ -74,6 +89,19 @@ type ProjectMetadata struct {
 	// in every template that we ship.
 	// ex: todo-python-mongo@version
 	Template string
+	// Source is the repository and commit the project was initialized from, recorded by `azd init --template` and
+	// updated by `azd template update`
+	Source *TemplateSource `yaml:"source,omitempty"`
+}
+
+// TemplateSource pins the version of the template a project was initialized from
+type TemplateSource struct {
+	// The URL of the template repository
+	Repository string `yaml:"repository"`
+	// The branch or tag of the template, the default branch of the repository when empty
+	Branch string `yaml:"branch,omitempty"`
+	// The commit of the template the project is up to date with
+	Commit string `yaml:"commit"`
 }
 
 // HasService checks if the project contains a service with a given name.
@@ -269,9 +297,34 @@ func ParseProjectConfig(yamlContent string, env *environment.Environment) (*Proj
 		}
 	}
 
+	if err := projectFile.composeServiceModules(); err != nil {
+		return nil, err
+	}
+
 	return &projectFile, nil
 }
 
+// composeServiceModules sets the infrastructure modules of the services to compose into the root deployment
+func (p *ProjectConfig) composeServiceModules() error {
+	for key, svc := range p.Services {
+		if strings.TrimSpace(svc.InfraModule) == "" {
+			continue
+		}
+
+		if p.Infra.Provider != "" && p.Infra.Provider != provisioning.Bicep {
+			return fmt.Errorf(
+				"the infraModule of service '%s' is only supported by the bicep provisioning provider", key)
+		}
+
+		if p.Infra.ServiceModules == nil {
+			p.Infra.ServiceModules = map[string]string{}
+		}
+		p.Infra.ServiceModules[key] = svc.InfraModule
+	}
+
+	return nil
+}
+
 func (p *ProjectConfig) Initialize(ctx context.Context, env *environment.Environment) error {
 	var allTools []tools.ExternalTool
 	for _, svc := range p.Services {
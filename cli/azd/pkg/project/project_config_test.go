@@ -48,6 +48,33 @@ services:
 	}
 }
 
+func TestProjectConfigServiceInfraModules(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  web:
+    project: src/web
+    language: js
+    host: appservice
+    infraModule: app/web
+  api:
+    project: src/api
+    language: js
+    host: appservice
+`
+
+	e := environment.EphemeralWithValues("test-env", nil)
+
+	projectConfig, err := ParseProjectConfig(testProj, e)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"web": "app/web"}, projectConfig.Infra.ServiceModules)
+
+	_, err = ParseProjectConfig(testProj+`infra:
+  provider: terraform
+`, e)
+	require.Error(t, err)
+}
+
 func TestProjectConfigHasService(t *testing.T) {
 	const testProj = `
 name: test-proj
@@ -8,10 +8,15 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/events"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/fields"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/eventlog"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"go.opentelemetry.io/otel/codes"
 )
 
 type Service struct {
@@ -42,9 +47,30 @@ func (svc *Service) RequiredExternalTools() []tools.ExternalTool {
 	return requiredTools
 }
 
+// Deploy packages the service and deploys the package to the service target
 func (svc *Service) Deploy(
 	ctx context.Context,
 	azdCtx *azdcontext.AzdContext,
+) (<-chan *ServiceDeploymentChannelResponse, <-chan string) {
+	return svc.deploy(ctx, azdCtx, "")
+}
+
+// DeployPackage deploys a package built previously to the service target, without building the service.
+// The package is a zip file or a directory for App Service and Azure Functions targets,
+// and a container image reference for Container Apps targets.
+func (svc *Service) DeployPackage(
+	ctx context.Context,
+	azdCtx *azdcontext.AzdContext,
+	packagePath string,
+) (<-chan *ServiceDeploymentChannelResponse, <-chan string) {
+	return svc.deploy(ctx, azdCtx, packagePath)
+}
+
+// deploy deploys the package to the service target, packaging the service first when no package is specified
+func (svc *Service) deploy(
+	ctx context.Context,
+	azdCtx *azdcontext.AzdContext,
+	packagePath string,
 ) (<-chan *ServiceDeploymentChannelResponse, <-chan string) {
 	result := make(chan *ServiceDeploymentChannelResponse, 1)
 	progress := make(chan string)
@@ -53,16 +79,35 @@ func (svc *Service) Deploy(
 		defer close(result)
 		defer close(progress)
 
-		log.Printf("packing service %s", svc.Config.Name)
-
-		progress <- "Preparing packaging"
-		artifact, err := svc.Framework.Package(ctx, progress)
-		if err != nil {
-			result <- &ServiceDeploymentChannelResponse{
-				Error: fmt.Errorf("packaging service %s: %w", svc.Config.Name, err),
+		ctx, span := telemetry.GetTracer().Start(ctx, events.DeployServiceEventName)
+		span.SetAttributes(
+			fields.DeployServiceHostKey.String(svc.Config.Host),
+			fields.DeployServiceLanguageKey.String(svc.Config.Language),
+		)
+		defer span.End()
+
+		completeStep := eventlog.FromContext(ctx).StartStep("deploy/" + svc.Config.Name)
+
+		artifact := packagePath
+		if artifact == "" {
+			log.Printf("packing service %s", svc.Config.Name)
+
+			progress <- "Preparing packaging"
+			packaged, err := svc.Framework.Package(ctx, progress)
+			if err != nil {
+				span.SetStatus(codes.Error, "UnknownError")
+				err = fmt.Errorf("packaging service %s: %w", svc.Config.Name, err)
+				completeStep(err)
+				result <- &ServiceDeploymentChannelResponse{
+					Error: err,
+				}
+
+				return
 			}
 
-			return
+			artifact = packaged
+		} else {
+			log.Printf("using package %s for service %s", artifact, svc.Config.Name)
 		}
 
 		log.Printf("deploying service %s", svc.Config.Name)
@@ -70,14 +115,34 @@ func (svc *Service) Deploy(
 		progress <- "Preparing for deployment"
 		res, err := svc.Target.Deploy(ctx, azdCtx, artifact, progress)
 		if err != nil {
+			span.SetStatus(codes.Error, "UnknownError")
+			err = fmt.Errorf("deploying service %s package: %w", svc.Config.Name, err)
+			completeStep(err)
 			result <- &ServiceDeploymentChannelResponse{
-				Error: fmt.Errorf("deploying service %s package: %w", svc.Config.Name, err),
+				Error: err,
 			}
 
 			return
 		}
 
+		if svc.Config.CachePurge != nil {
+			progress <- "Purging the cache of the endpoint"
+			err := azcli.GetAzCli(ctx).PurgeEndpointContent(
+				ctx, svc.Config.CachePurge.Endpoint, svc.Config.CachePurge.ContentPaths())
+			if err != nil {
+				span.SetStatus(codes.Error, "UnknownError")
+				err = fmt.Errorf("service %s is deployed but its cache isn't purged: %w", svc.Config.Name, err)
+				completeStep(err)
+				result <- &ServiceDeploymentChannelResponse{
+					Error: err,
+				}
+
+				return
+			}
+		}
+
 		log.Printf("deployed service %s", svc.Config.Name)
+		completeStep(nil, res.TargetResourceId)
 		progress <- "Deployment completed"
 
 		result <- &ServiceDeploymentChannelResponse{
@@ -7,9 +7,12 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/containerregistry"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/rzip"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/swa"
@@ -32,10 +35,30 @@ type ServiceConfig struct {
 	OutputPath string `yaml:"dist"`
 	// The infrastructure module path relative to the root infra folder to use for this project
 	Module string `yaml:"module"`
+	// The bicep module provisioning the resources of the service, relative to the root infra folder, composed into the
+	// root deployment. Its outputs are namespaced as SERVICE_<NAME>_<OUTPUT>
+	InfraModule string `yaml:"infraModule"`
 	// The optional docker options
 	Docker DockerProjectOptions `yaml:"docker"`
 	// The infrastructure provisioning configuration
 	Infra provisioning.Options `yaml:"infra"`
+	// The key vault secrets exposed to the service, by the name of the setting they are exposed as
+	Secrets map[string]string `yaml:"secrets"`
+	// The Dapr options of the service, only supported by Container Apps
+	Dapr DaprOptions `yaml:"dapr"`
+	// The Front Door or CDN endpoint whose cache is purged once the service is deployed
+	CachePurge *CachePurgeOptions `yaml:"cachePurge,omitempty"`
+	// The command starting the service locally with azd run, i.e. python -m flask run. Defaults to docker compose
+	// when the service has a compose file, or to the usual command of its host or language.
+	Run string `yaml:"run,omitempty"`
+	// The dev tunnel exposing the service started locally by azd run, for the resources in Azure to call it back
+	Tunnel *TunnelOptions `yaml:"tunnel,omitempty"`
+	// The patterns of the files and directories left out of the zip package of the service, i.e. *.map or tests.
+	// Patterns containing a slash match the paths relative to the output of the service.
+	Exclude []string `yaml:"exclude,omitempty"`
+	// The application settings of the service, only supported by App Service and Azure Functions. The values
+	// typically reference the environment, i.e. ${API_URL}.
+	AppSettings map[string]string `yaml:"appSettings,omitempty"`
 
 	handlers map[Event][]ServiceLifecycleEventHandlerFn
 }
@@ -90,11 +113,45 @@ func (sc *ServiceConfig) GetServiceTarget(
 
 	azCli := azcli.GetAzCli(ctx)
 
+	if len(sc.Dapr.Components) > 0 && sc.Host != string(ContainerAppTarget) {
+		return nil, fmt.Errorf("dapr components of service '%s' are only supported by the containerapp host", sc.Name)
+	}
+
+	if len(sc.AppSettings) > 0 &&
+		sc.Host != "" && sc.Host != string(AppServiceTarget) && sc.Host != string(AzureFunctionTarget) {
+		return nil, fmt.Errorf(
+			"app settings of service '%s' are only supported by the appservice and function hosts", sc.Name)
+	}
+
+	for _, component := range sc.Dapr.Components {
+		if _, err := component.daprComponent(); err != nil {
+			return nil, fmt.Errorf("service '%s': %w", sc.Name, err)
+		}
+	}
+
+	if err := rzip.ValidateExclude(sc.Exclude); err != nil {
+		return nil, fmt.Errorf("service '%s': %w", sc.Name, err)
+	}
+
+	if sc.CachePurge != nil {
+		if err := sc.CachePurge.Validate(); err != nil {
+			return nil, fmt.Errorf("service '%s': %w", sc.Name, err)
+		}
+	}
+
 	switch sc.Host {
 	case "", string(AppServiceTarget):
 		target = NewAppServiceTarget(sc, env, scope, azCli)
 	case string(ContainerAppTarget):
-		target = NewContainerAppTarget(sc, env, scope, azCli, docker.NewDocker(ctx), input.GetConsole(ctx))
+		target = NewContainerAppTarget(
+			sc,
+			env,
+			scope,
+			azCli,
+			docker.NewDocker(ctx),
+			containerregistry.NewRegistryAuth(azCli, httputil.GetHttpClient(ctx)),
+			input.GetConsole(ctx),
+		)
 	case string(AzureFunctionTarget):
 		target = NewFunctionAppTarget(sc, env, scope, azCli)
 	case string(StaticWebAppTarget):
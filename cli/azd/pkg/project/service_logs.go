@@ -0,0 +1,133 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// LogLevel is the severity of a log line, in increasing order of severity
+type LogLevel int
+
+const (
+	LogLevelTrace LogLevel = iota
+	LogLevelDebug
+	LogLevelInfo
+	LogLevelWarning
+	LogLevelError
+)
+
+var logLevelNames = map[string]LogLevel{
+	"trace":   LogLevelTrace,
+	"debug":   LogLevelDebug,
+	"info":    LogLevelInfo,
+	"warning": LogLevelWarning,
+	"error":   LogLevelError,
+}
+
+// ParseLogLevel parses the name of a log level, i.e. "warning"
+func ParseLogLevel(name string) (LogLevel, error) {
+	level, has := logLevelNames[strings.ToLower(name)]
+	if !has {
+		return 0, fmt.Errorf(
+			"unsupported log level '%s', the supported levels are trace, debug, info, warning and error", name)
+	}
+
+	return level, nil
+}
+
+// The number of previous lines streamed before the new lines, when the service host supports it
+const logTailLines = 300
+
+// LogStreamOptions filters the streamed log lines
+type LogStreamOptions struct {
+	// The minimum level of the lines, lines whose level can't be determined are always streamed
+	Level LogLevel
+	// When not zero, only the lines logged within this duration are streamed
+	Since time.Duration
+}
+
+// logLevelRegexp matches the words the common logging libraries and the Azure hosts label log lines with
+var logLevelRegexp = regexp.MustCompile(
+	`(?i)\b(trace|trce|verbose|debug|dbug|info|information|warn|warning|error|fail|fatal|critical|crit)\b`,
+)
+
+// logTimestampRegexp matches the timestamp lines start with, i.e. 2022-10-01T12:00:00.123Z
+var logTimestampRegexp = regexp.MustCompile(
+	`^\s*(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?)`,
+)
+
+var logTimestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+}
+
+func lineLevel(line string) (LogLevel, bool) {
+	match := logLevelRegexp.FindString(line)
+	switch strings.ToLower(match) {
+	case "trace", "trce", "verbose":
+		return LogLevelTrace, true
+	case "debug", "dbug":
+		return LogLevelDebug, true
+	case "info", "information":
+		return LogLevelInfo, true
+	case "warn", "warning":
+		return LogLevelWarning, true
+	case "error", "fail", "fatal", "critical", "crit":
+		return LogLevelError, true
+	default:
+		return 0, false
+	}
+}
+
+func lineTimestamp(line string) (time.Time, bool) {
+	matches := logTimestampRegexp.FindStringSubmatch(line)
+	if matches == nil {
+		return time.Time{}, false
+	}
+
+	for _, layout := range logTimestampLayouts {
+		// Timestamps without a time zone are in UTC, as logged by the Azure hosts
+		if timestamp, err := time.Parse(layout, matches[1]); err == nil {
+			return timestamp, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// copyLogs copies the log lines matching the options from the reader to the writer, until the reader is exhausted.
+// Lines with neither a level nor a timestamp, such as the lines of a stack trace, follow the line before them.
+func copyLogs(reader io.Reader, writer io.Writer, options LogStreamOptions, now time.Time) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), 1024*1024)
+
+	keep := true
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		level, hasLevel := lineLevel(line)
+		timestamp, hasTimestamp := lineTimestamp(line)
+
+		if hasLevel || hasTimestamp {
+			keep = (!hasLevel || level >= options.Level) &&
+				(!hasTimestamp || options.Since == 0 || !timestamp.Before(now.Add(-options.Since)))
+		}
+
+		if keep {
+			if _, err := fmt.Fprintln(writer, line); err != nil {
+				return err
+			}
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,64 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyLogs(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	logs := strings.Join([]string{
+		"2022-10-01T11:00:00Z info: Application started",
+		"2022-10-01T11:58:00Z warn: Slow request",
+		"2022-10-01T11:59:00Z fail: Request failed",
+		"   at Handler.Process()",
+		"2022-10-01T11:59:30.5Z dbug: Retrying",
+		"Listening on port 8080",
+	}, "\n")
+
+	t.Run("All", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := copyLogs(strings.NewReader(logs), buf, LogStreamOptions{}, now)
+		require.NoError(t, err)
+		require.Equal(t, logs+"\n", buf.String())
+	})
+
+	t.Run("Level", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := copyLogs(strings.NewReader(logs), buf, LogStreamOptions{Level: LogLevelWarning}, now)
+		require.NoError(t, err)
+		require.Equal(t, strings.Join([]string{
+			"2022-10-01T11:58:00Z warn: Slow request",
+			"2022-10-01T11:59:00Z fail: Request failed",
+			"   at Handler.Process()",
+		}, "\n")+"\n", buf.String())
+	})
+
+	t.Run("Since", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := copyLogs(strings.NewReader(logs), buf, LogStreamOptions{Since: 90 * time.Second}, now)
+		require.NoError(t, err)
+		require.Equal(t, strings.Join([]string{
+			"2022-10-01T11:59:00Z fail: Request failed",
+			"   at Handler.Process()",
+			"2022-10-01T11:59:30.5Z dbug: Retrying",
+			"Listening on port 8080",
+		}, "\n")+"\n", buf.String())
+	})
+}
+
+func TestParseLogLevel(t *testing.T) {
+	level, err := ParseLogLevel("Warning")
+	require.NoError(t, err)
+	require.Equal(t, LogLevelWarning, level)
+
+	_, err = ParseLogLevel("verbose")
+	require.Error(t, err)
+}
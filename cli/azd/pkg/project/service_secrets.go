@@ -0,0 +1,42 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+)
+
+// secretReferences returns the URIs of the key vault secrets exposed to the service, by the name of the setting they
+// are exposed as. The URIs don't include a version, so the latest version of each secret is always used.
+func secretReferences(config *ServiceConfig, env *environment.Environment) (map[string]string, error) {
+	if len(config.Secrets) == 0 {
+		return nil, nil
+	}
+
+	endpoint := env.GetKeyVaultEndpoint()
+	if endpoint == "" {
+		return nil, fmt.Errorf(
+			"service %s references secrets but the environment has no key vault, ensure %s is set as an output of "+
+				"your infrastructure",
+			config.Name,
+			environment.KeyVaultEndpointEnvVarName,
+		)
+	}
+
+	references := map[string]string{}
+	for setting, secret := range config.Secrets {
+		references[setting] = fmt.Sprintf("%s/secrets/%s", endpoint, secret)
+	}
+
+	return references, nil
+}
+
+// secretEnvVarName returns the name of the key the URI of a key vault secret exposed to the service is stored with in
+// the environment, for the infrastructure of the service to reference it, i.e. SERVICE_API_SECRET_DB_PASSWORD
+func secretEnvVarName(serviceName string, setting string) string {
+	return fmt.Sprintf("SERVICE_%s_SECRET_%s", strings.ToUpper(serviceName), strings.ToUpper(setting))
+}
@@ -0,0 +1,47 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretReferences(t *testing.T) {
+	config := &ServiceConfig{
+		Name: "api",
+		Secrets: map[string]string{
+			"DB_PASSWORD": "db-password",
+		},
+	}
+
+	t.Run("NoKeyVault", func(t *testing.T) {
+		_, err := secretReferences(config, environment.Ephemeral())
+		require.Error(t, err)
+	})
+
+	t.Run("NoSecrets", func(t *testing.T) {
+		references, err := secretReferences(&ServiceConfig{Name: "web"}, environment.Ephemeral())
+		require.NoError(t, err)
+		require.Empty(t, references)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		env := environment.EphemeralWithValues("dev", map[string]string{
+			environment.KeyVaultEndpointEnvVarName: "https://myvault.vault.azure.net/",
+		})
+
+		references, err := secretReferences(config, env)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"DB_PASSWORD": "https://myvault.vault.azure.net/secrets/db-password",
+		}, references)
+	})
+}
+
+func TestSecretEnvVarName(t *testing.T) {
+	require.Equal(t, "SERVICE_API_SECRET_DB_PASSWORD", secretEnvVarName("api", "db_password"))
+}
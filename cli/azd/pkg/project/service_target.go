@@ -6,7 +6,9 @@ package project
 import (
 	"context"
 	"encoding/json"
+	"io"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 )
@@ -26,6 +28,8 @@ type ServiceDeploymentResult struct {
 	Kind             ServiceTargetKind `json:"kind"`
 	Details          interface{}       `json:"details"`
 	Endpoints        []string          `json:"endpoints"`
+	// The change applied to the application settings, for App Service and Azure Functions applications
+	AppSettings *AppSettingsDiff `json:"appSettings,omitempty"`
 }
 
 type ServiceTarget interface {
@@ -43,6 +47,18 @@ type ServiceTarget interface {
 	Endpoints(ctx context.Context) ([]string, error)
 }
 
+// LogStreamer is implemented by the service targets whose logs can be streamed
+type LogStreamer interface {
+	// StreamLogs writes the log lines of the target resource matching the options, until the context is cancelled
+	StreamLogs(ctx context.Context, options LogStreamOptions, writer io.Writer) error
+}
+
+// RemoteShell is implemented by the service targets that can open an interactive session in the service's runtime
+type RemoteShell interface {
+	// OpenSession opens a session running the command or, when the command is empty, the runtime's default shell
+	OpenSession(ctx context.Context, command string) (azsdk.RemoteSession, error)
+}
+
 func NewServiceDeploymentResult(
 	relatedResourceId string,
 	kind ServiceTargetKind,
@@ -72,3 +88,11 @@ var _ ServiceTarget = &appServiceTarget{}
 var _ ServiceTarget = &containerAppTarget{}
 var _ ServiceTarget = &functionAppTarget{}
 var _ ServiceTarget = &staticWebAppTarget{}
+
+var _ LogStreamer = &appServiceTarget{}
+var _ LogStreamer = &containerAppTarget{}
+var _ LogStreamer = &functionAppTarget{}
+
+var _ RemoteShell = &appServiceTarget{}
+var _ RemoteShell = &containerAppTarget{}
+var _ RemoteShell = &functionAppTarget{}
@@ -6,12 +6,17 @@ package project
 import (
 	"context"
 	"fmt"
+	"io"
+	"log"
 	"os"
+	"time"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/project/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/rzip"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 )
@@ -29,49 +34,144 @@ func (st *appServiceTarget) RequiredExternalTools() []tools.ExternalTool {
 
 func (st *appServiceTarget) Deploy(
 	ctx context.Context,
-	_ *azdcontext.AzdContext,
+	azdCtx *azdcontext.AzdContext,
 	path string,
 	progress chan<- string,
 ) (ServiceDeploymentResult, error) {
-	progress <- "Compressing deployment artifacts"
-
-	zipFilePath, err := internal.CreateDeployableZip(st.config.Name, path)
+	appSettings, err := applyAppServiceSettings(ctx, st.cli, st.config, st.env, st.scope, progress)
 	if err != nil {
 		return ServiceDeploymentResult{}, err
 	}
 
+	resourceId := azure.WebsiteRID(st.env.GetSubscriptionId(), st.scope.ResourceGroupName(), st.scope.ResourceName())
+
+	// A package that is already a zip file, such as one built by a previous CI stage, is deployed as is
+	zipFilePath := path
+	var manifest *deployManifest
+	incremental := false
+	if !internal.IsZipFile(path) {
+		progress <- "Compressing deployment artifacts"
+
+		files, err := rzip.Files(path, st.config.Exclude)
+		if err != nil {
+			return ServiceDeploymentResult{}, fmt.Errorf("listing the files of service %s: %w", st.config.Name, err)
+		}
+
+		// Repeated deployments from the same environment only upload the changed files, when the App Service allows
+		if azdCtx != nil {
+			manifest, err = newDeployManifest(resourceId, files)
+			if err != nil {
+				return ServiceDeploymentResult{}, err
+			}
+
+			previous, err := loadDeployManifest(deployManifestPath(azdCtx, st.env, st.config.Name))
+			if err != nil {
+				log.Printf("ignoring the previous deploy manifest: %v", err)
+			}
+
+			changed, ok, err := incrementalDeployFiles(ctx, st.cli, st.env, st.scope, previous, manifest, files)
+			if err != nil {
+				return ServiceDeploymentResult{}, err
+			}
+
+			if ok && len(changed) == 0 {
+				progress <- "Skipping the deployment package, no files changed"
+				return st.deploymentResult(ctx, resourceId, "Unchanged", appSettings, progress)
+			}
+
+			if ok {
+				log.Printf("deploying %d changed files out of %d", len(changed), len(files))
+				files = changed
+				incremental = true
+			}
+		}
+
+		createdZipPath, err := internal.CreateDeployableZipOf(st.config.Name, files)
+		if err != nil {
+			return ServiceDeploymentResult{}, err
+		}
+
+		zipFilePath = createdZipPath
+		defer os.Remove(zipFilePath)
+	}
+
 	zipFile, err := os.Open(zipFilePath)
 	if err != nil {
 		return ServiceDeploymentResult{}, fmt.Errorf("failed reading deployment zip file: %w", err)
 	}
 
-	defer os.Remove(zipFilePath)
 	defer zipFile.Close()
 
-	progress <- "Publishing deployment package"
-	res, err := st.cli.DeployAppServiceZip(
-		ctx,
-		st.env.GetSubscriptionId(),
-		st.scope.ResourceGroupName(),
-		st.scope.ResourceName(),
-		zipFile,
-	)
+	var res *string
+	if incremental {
+		progress <- "Publishing changed files"
+		res, err = st.cli.PublishAppServiceZip(
+			ctx,
+			st.env.GetSubscriptionId(),
+			st.scope.ResourceGroupName(),
+			st.scope.ResourceName(),
+			zipFile,
+			false,
+		)
+	} else {
+		progress <- "Publishing deployment package"
+		res, err = st.cli.DeployAppServiceZip(
+			ctx,
+			st.env.GetSubscriptionId(),
+			st.scope.ResourceGroupName(),
+			st.scope.ResourceName(),
+			zipFile,
+		)
+	}
 	if err != nil {
 		return ServiceDeploymentResult{}, fmt.Errorf("deploying service %s: %w", st.config.Name, err)
 	}
 
+	if manifest != nil {
+		st.saveDeployManifest(ctx, azdCtx, manifest)
+	}
+
+	return st.deploymentResult(ctx, resourceId, *res, appSettings, progress)
+}
+
+// saveDeployManifest records the files deployed along with the id of the deployment, for the next deployment to only
+// upload the changed files. The next deployment uploads the whole package when the manifest can't be saved.
+func (st *appServiceTarget) saveDeployManifest(
+	ctx context.Context,
+	azdCtx *azdcontext.AzdContext,
+	manifest *deployManifest,
+) {
+	path := deployManifestPath(azdCtx, st.env, st.config.Name)
+
+	deploymentId, err := st.cli.GetAppServiceLatestDeploymentId(
+		ctx, st.env.GetSubscriptionId(), st.scope.ResourceGroupName(), st.scope.ResourceName())
+	if err != nil {
+		log.Printf("removing the deploy manifest of service %s: %v", st.config.Name, err)
+		os.Remove(path)
+		return
+	}
+
+	manifest.DeploymentId = deploymentId
+	if err := manifest.save(path); err != nil {
+		log.Printf("failed saving the deploy manifest of service %s: %v", st.config.Name, err)
+	}
+}
+
+func (st *appServiceTarget) deploymentResult(
+	ctx context.Context,
+	resourceId string,
+	rawResult string,
+	appSettings *AppSettingsDiff,
+	progress chan<- string,
+) (ServiceDeploymentResult, error) {
 	progress <- "Fetching endpoints for app service"
 	endpoints, err := st.Endpoints(ctx)
 	if err != nil {
 		return ServiceDeploymentResult{}, err
 	}
 
-	sdr := NewServiceDeploymentResult(
-		azure.WebsiteRID(st.env.GetSubscriptionId(), st.scope.ResourceGroupName(), st.scope.ResourceName()),
-		AppServiceTarget,
-		*res,
-		endpoints,
-	)
+	sdr := NewServiceDeploymentResult(resourceId, AppServiceTarget, rawResult, endpoints)
+	sdr.AppSettings = appSettings
 	return sdr, nil
 }
 
@@ -94,6 +194,20 @@ func (st *appServiceTarget) Endpoints(ctx context.Context) ([]string, error) {
 	return endpoints, nil
 }
 
+func (st *appServiceTarget) StreamLogs(ctx context.Context, options LogStreamOptions, writer io.Writer) error {
+	stream, err := st.cli.StreamAppServiceLogs(ctx, st.env.GetSubscriptionId(), st.scope.ResourceName())
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	return copyLogs(stream, writer, options, time.Now())
+}
+
+func (st *appServiceTarget) OpenSession(ctx context.Context, command string) (azsdk.RemoteSession, error) {
+	return st.cli.OpenAppServiceSession(ctx, st.env.GetSubscriptionId(), st.scope.ResourceName(), command)
+}
+
 func NewAppServiceTarget(
 	config *ServiceConfig,
 	env *environment.Environment,
@@ -6,12 +6,15 @@ package project
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"strings"
 	"time"
 
 	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/containerregistry"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
@@ -23,12 +26,13 @@ import (
 )
 
 type containerAppTarget struct {
-	config  *ServiceConfig
-	env     *environment.Environment
-	scope   *environment.DeploymentScope
-	cli     azcli.AzCli
-	docker  *docker.Docker
-	console input.Console
+	config       *ServiceConfig
+	env          *environment.Environment
+	scope        *environment.DeploymentScope
+	cli          azcli.AzCli
+	docker       *docker.Docker
+	registryAuth *containerregistry.RegistryAuth
+	console      input.Console
 }
 
 func (at *containerAppTarget) RequiredExternalTools() []tools.ExternalTool {
@@ -49,7 +53,12 @@ func (at *containerAppTarget) Deploy(
 		at.config.Infra.Module = at.config.Name
 	}
 
-	// Login to container registry.
+	secrets, err := secretReferences(at.config, at.env)
+	if err != nil {
+		return ServiceDeploymentResult{}, err
+	}
+
+	// The container registry the image is pushed to.
 	loginServer, has := at.env.Values[environment.ContainerRegistryEndpointEnvVarName]
 	if !has {
 		return ServiceDeploymentResult{}, fmt.Errorf(
@@ -58,34 +67,48 @@ func (at *containerAppTarget) Deploy(
 		)
 	}
 
-	log.Printf("logging into registry %s", loginServer)
+	var fullTag string
+	if strings.HasPrefix(path, loginServer+"/") {
+		// The image was already pushed to the registry, for example by a previous CI stage
+		log.Printf("image %s is already in registry %s", path, loginServer)
+		fullTag = path
+	} else {
+		fullTag = fmt.Sprintf(
+			"%s/%s/%s:azdev-deploy-%d",
+			loginServer,
+			at.scope.ResourceName(),
+			at.scope.ResourceName(),
+			time.Now().Unix(),
+		)
 
-	progress <- "Logging into container registry"
-	if err := at.cli.LoginAcr(ctx, at.env.GetSubscriptionId(), loginServer); err != nil {
-		return ServiceDeploymentResult{}, fmt.Errorf("logging into registry '%s': %w", loginServer, err)
-	}
+		// Tag image.
+		log.Printf("tagging image %s as %s", path, fullTag)
+		progress <- "Tagging image"
+		if err := at.docker.Tag(ctx, at.config.Path(), path, fullTag); err != nil {
+			return ServiceDeploymentResult{}, fmt.Errorf("tagging image: %w", err)
+		}
 
-	fullTag := fmt.Sprintf(
-		"%s/%s/%s:azdev-deploy-%d",
-		loginServer,
-		at.scope.ResourceName(),
-		at.scope.ResourceName(),
-		time.Now().Unix(),
-	)
+		authMode, err := containerregistry.ParseAuthMode(at.config.Docker.RegistryAuth)
+		if err != nil {
+			return ServiceDeploymentResult{}, err
+		}
 
-	// Tag image.
-	log.Printf("tagging image %s as %s", path, fullTag)
-	progress <- "Tagging image"
-	if err := at.docker.Tag(ctx, at.config.Path(), path, fullTag); err != nil {
-		return ServiceDeploymentResult{}, fmt.Errorf("tagging image: %w", err)
-	}
+		log.Printf("getting credentials for registry %s", loginServer)
 
-	log.Printf("pushing %s to registry", fullTag)
+		progress <- "Logging into container registry"
+		credentials, err := at.registryAuth.Credentials(ctx, at.env.GetSubscriptionId(), loginServer, authMode)
+		if err != nil {
+			return ServiceDeploymentResult{}, fmt.Errorf("logging into registry '%s': %w", loginServer, err)
+		}
+
+		log.Printf("pushing %s to registry", fullTag)
 
-	// Push image.
-	progress <- "Pushing container image"
-	if err := at.docker.Push(ctx, at.config.Path(), fullTag); err != nil {
-		return ServiceDeploymentResult{}, fmt.Errorf("pushing image: %w", err)
+		// Push image.
+		progress <- "Pushing container image"
+		if err := at.docker.Push(ctx, at.config.Path(), fullTag, credentials); err != nil {
+			err = at.registryAuth.PushAccessError(ctx, at.env.GetSubscriptionId(), loginServer, authMode, err)
+			return ServiceDeploymentResult{}, fmt.Errorf("pushing image: %w", err)
+		}
 	}
 
 	log.Printf("writing image name to environment")
@@ -93,6 +116,11 @@ func (at *containerAppTarget) Deploy(
 	// Save the name of the image we pushed into the environment with a well known key.
 	at.env.Values[fmt.Sprintf("SERVICE_%s_IMAGE_NAME", strings.ToUpper(at.config.Name))] = fullTag
 
+	// Save the URIs of the secrets exposed to the service, for its container app to reference them from the key vault
+	for setting, uri := range secrets {
+		at.env.Values[secretEnvVarName(at.config.Name, setting)] = uri
+	}
+
 	if err := at.env.Save(); err != nil {
 		return ServiceDeploymentResult{}, fmt.Errorf("saving image name to environment: %w", err)
 	}
@@ -131,6 +159,13 @@ func (at *containerAppTarget) Deploy(
 		}
 	}
 
+	if len(at.config.Dapr.Components) > 0 {
+		progress <- "Updating Dapr components"
+		if err := at.setDaprComponents(ctx); err != nil {
+			return ServiceDeploymentResult{}, err
+		}
+	}
+
 	progress <- "Fetching endpoints for container app service"
 	endpoints, err := at.Endpoints(ctx)
 	if err != nil {
@@ -149,6 +184,29 @@ func (at *containerAppTarget) Deploy(
 	}, nil
 }
 
+// setDaprComponents creates or updates the Dapr components of the service in the environment of its container app
+func (at *containerAppTarget) setDaprComponents(ctx context.Context) error {
+	for _, config := range at.config.Dapr.Components {
+		component, err := config.daprComponent()
+		if err != nil {
+			return err
+		}
+
+		log.Printf("setting dapr component %s", component.Name)
+		if err := at.cli.SetContainerAppDaprComponent(
+			ctx,
+			at.env.GetSubscriptionId(),
+			at.scope.ResourceGroupName(),
+			at.scope.ResourceName(),
+			component,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (at *containerAppTarget) Endpoints(ctx context.Context) ([]string, error) {
 	if containerAppProperties, err := at.cli.GetContainerAppProperties(
 		ctx, at.env.GetSubscriptionId(),
@@ -166,20 +224,48 @@ func (at *containerAppTarget) Endpoints(ctx context.Context) ([]string, error) {
 	}
 }
 
+func (at *containerAppTarget) StreamLogs(ctx context.Context, options LogStreamOptions, writer io.Writer) error {
+	stream, err := at.cli.StreamContainerAppLogs(
+		ctx,
+		at.env.GetSubscriptionId(),
+		at.scope.ResourceGroupName(),
+		at.scope.ResourceName(),
+		logTailLines,
+	)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	return copyLogs(stream, writer, options, time.Now())
+}
+
+func (at *containerAppTarget) OpenSession(ctx context.Context, command string) (azsdk.RemoteSession, error) {
+	return at.cli.OpenContainerAppSession(
+		ctx,
+		at.env.GetSubscriptionId(),
+		at.scope.ResourceGroupName(),
+		at.scope.ResourceName(),
+		command,
+	)
+}
+
 func NewContainerAppTarget(
 	config *ServiceConfig,
 	env *environment.Environment,
 	scope *environment.DeploymentScope,
 	azCli azcli.AzCli,
 	docker *docker.Docker,
+	registryAuth *containerregistry.RegistryAuth,
 	console input.Console,
 ) ServiceTarget {
 	return &containerAppTarget{
-		config:  config,
-		env:     env,
-		scope:   scope,
-		cli:     azCli,
-		docker:  docker,
-		console: console,
+		config:       config,
+		env:          env,
+		scope:        scope,
+		cli:          azCli,
+		docker:       docker,
+		registryAuth: registryAuth,
+		console:      console,
 	}
 }
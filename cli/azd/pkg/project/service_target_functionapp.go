@@ -6,8 +6,11 @@ package project
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"time"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
@@ -35,11 +38,18 @@ func (f *functionAppTarget) Deploy(
 	path string,
 	progress chan<- string,
 ) (ServiceDeploymentResult, error) {
-	progress <- "Compressing deployment artifacts"
+	// A package that is already a zip file, such as one built by a previous CI stage, is deployed as is
+	zipFilePath := path
+	if !internal.IsZipFile(path) {
+		progress <- "Compressing deployment artifacts"
 
-	zipFilePath, err := internal.CreateDeployableZip(f.config.Name, path)
-	if err != nil {
-		return ServiceDeploymentResult{}, err
+		createdZipPath, err := internal.CreateDeployableZip(f.config.Name, path, f.config.Exclude)
+		if err != nil {
+			return ServiceDeploymentResult{}, err
+		}
+
+		zipFilePath = createdZipPath
+		defer os.Remove(zipFilePath)
 	}
 
 	zipFile, err := os.Open(zipFilePath)
@@ -47,9 +57,13 @@ func (f *functionAppTarget) Deploy(
 		return ServiceDeploymentResult{}, fmt.Errorf("failed reading deployment zip file: %w", err)
 	}
 
-	defer os.Remove(zipFilePath)
 	defer zipFile.Close()
 
+	appSettings, err := applyAppServiceSettings(ctx, f.cli, f.config, f.env, f.scope, progress)
+	if err != nil {
+		return ServiceDeploymentResult{}, err
+	}
+
 	progress <- "Publishing deployment package"
 	res, err := f.cli.DeployFunctionAppUsingZipFile(
 		ctx,
@@ -74,6 +88,7 @@ func (f *functionAppTarget) Deploy(
 		*res,
 		endpoints,
 	)
+	sdr.AppSettings = appSettings
 	return sdr, nil
 }
 
@@ -96,6 +111,21 @@ func (f *functionAppTarget) Endpoints(ctx context.Context) ([]string, error) {
 	}
 }
 
+func (f *functionAppTarget) StreamLogs(ctx context.Context, options LogStreamOptions, writer io.Writer) error {
+	// Function apps stream their logs like any App Service application
+	stream, err := f.cli.StreamAppServiceLogs(ctx, f.env.GetSubscriptionId(), f.scope.ResourceName())
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	return copyLogs(stream, writer, options, time.Now())
+}
+
+func (f *functionAppTarget) OpenSession(ctx context.Context, command string) (azsdk.RemoteSession, error) {
+	return f.cli.OpenAppServiceSession(ctx, f.env.GetSubscriptionId(), f.scope.ResourceName(), command)
+}
+
 func NewFunctionAppTarget(
 	config *ServiceConfig,
 	env *environment.Environment,
@@ -32,6 +32,9 @@ services:
 )
 
 type mockFrameworkService struct {
+	packaged bool
+	// The path returned by Package
+	packagePath string
 }
 
 func (st *mockFrameworkService) RequiredExternalTools() []tools.ExternalTool {
@@ -39,8 +42,9 @@ func (st *mockFrameworkService) RequiredExternalTools() []tools.ExternalTool {
 }
 
 func (st *mockFrameworkService) Package(ctx context.Context, progress chan<- string) (string, error) {
+	st.packaged = true
 	progress <- "mock package progress"
-	return "", nil
+	return st.packagePath, nil
 }
 
 func (st *mockFrameworkService) InstallDependencies(ctx context.Context) error {
@@ -52,6 +56,7 @@ func (st *mockFrameworkService) Initialize(ctx context.Context) error {
 }
 
 type mockServiceTarget struct {
+	deployedPath string
 }
 
 func (st *mockServiceTarget) RequiredExternalTools() []tools.ExternalTool {
@@ -61,9 +66,10 @@ func (st *mockServiceTarget) RequiredExternalTools() []tools.ExternalTool {
 func (st *mockServiceTarget) Deploy(
 	_ context.Context,
 	_ *azdcontext.AzdContext,
-	_ string,
+	path string,
 	progress chan<- string,
 ) (ServiceDeploymentResult, error) {
+	st.deployedPath = path
 	progress <- "mock deploy progress"
 	return ServiceDeploymentResult{
 		TargetResourceId: "target-resource-id",
@@ -115,6 +121,42 @@ func TestDeployProgressMessages(t *testing.T) {
 	require.Equal(t, deployResponse.Result.Endpoints, mockEndpoints)
 }
 
+func TestDeployPackage(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	env := environment.Ephemeral()
+	env.SetSubscriptionId("SUBSCRIPTION_ID")
+
+	projectConfig, _ := ParseProjectConfig(projectYaml, env)
+	project, _ := projectConfig.GetProject(mockContext.Context, env)
+	azdContext, _ := azdcontext.NewAzdContext()
+
+	mockFramework := &mockFrameworkService{}
+	mockTarget := &mockServiceTarget{}
+
+	service := Service{
+		Project:   project,
+		Config:    project.Config.Services["api"],
+		Framework: mockFramework,
+		Target:    mockTarget,
+		Scope:     deploymentScope,
+	}
+
+	result, progress := service.DeployPackage(*mockContext.Context, azdContext, "/artifacts/api.zip")
+	go func() {
+		for range progress {
+		}
+	}()
+
+	deployResponse := <-result
+	require.NotNil(t, deployResponse)
+	require.NoError(t, deployResponse.Error)
+
+	// The prebuilt package is deployed without packaging the service
+	require.False(t, mockFramework.packaged)
+	require.Equal(t, "/artifacts/api.zip", mockTarget.deployedPath)
+}
+
 func arrayContains(arr []string, value string) bool {
 	for _, v := range arr {
 		if v == value {
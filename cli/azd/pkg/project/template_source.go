@@ -0,0 +1,77 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"gopkg.in/yaml.v3"
+)
+
+// SaveTemplateSource records the template source in the `metadata` of the project file at projectPath. The file is
+// edited in place, keeping its other contents and comments.
+func SaveTemplateSource(projectPath string, source TemplateSource) error {
+	contents, err := os.ReadFile(projectPath)
+	if err != nil {
+		return fmt.Errorf("reading project file: %w", err)
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(contents, &document); err != nil {
+		return fmt.Errorf("parsing project file: %w", err)
+	}
+
+	if document.Kind != yaml.DocumentNode || len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("the project file %s isn't a yaml mapping", projectPath)
+	}
+
+	var sourceNode yaml.Node
+	if err := sourceNode.Encode(source); err != nil {
+		return fmt.Errorf("encoding template source: %w", err)
+	}
+
+	metadata := mappingValue(document.Content[0], "metadata")
+	setMappingValue(metadata, "source", &sourceNode)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return fmt.Errorf("marshaling project file to yaml: %w", err)
+	}
+
+	if err := os.WriteFile(projectPath, buf.Bytes(), osutil.PermissionFile); err != nil {
+		return fmt.Errorf("writing project file: %w", err)
+	}
+
+	return nil
+}
+
+// Returns the mapping value of the given key, adding an empty mapping when the key is missing
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key && mapping.Content[i+1].Kind == yaml.MappingNode {
+			return mapping.Content[i+1]
+		}
+	}
+
+	value := &yaml.Node{Kind: yaml.MappingNode}
+	setMappingValue(mapping, key, value)
+	return value
+}
+
+// Sets the value of the given key of a mapping, replacing its existing value
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
@@ -0,0 +1,52 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveTemplateSource(t *testing.T) {
+	const testProj = `# yaml-language-server: $schema=https://example.com/azure.yaml.json
+
+name: test-proj
+metadata:
+  template: test-proj-template@0.0.1
+services:
+  web:
+    # The web frontend
+    project: src/web
+    language: js
+    host: appservice
+`
+
+	projectPath := filepath.Join(t.TempDir(), "azure.yaml")
+	require.NoError(t, os.WriteFile(projectPath, []byte(testProj), 0600))
+
+	source := TemplateSource{
+		Repository: "https://github.com/Azure-Samples/todo-nodejs-mongo",
+		Commit:     "0123456789abcdef0123456789abcdef01234567",
+	}
+	require.NoError(t, SaveTemplateSource(projectPath, source))
+
+	// Saving again replaces the source
+	source.Commit = "fedcba9876543210fedcba9876543210fedcba98"
+	require.NoError(t, SaveTemplateSource(projectPath, source))
+
+	contents, err := os.ReadFile(projectPath)
+	require.NoError(t, err)
+	require.Contains(t, string(contents), "# yaml-language-server")
+	require.Contains(t, string(contents), "# The web frontend")
+
+	projectConfig, err := ParseProjectConfig(string(contents), environment.EphemeralWithValues("test-env", nil))
+	require.NoError(t, err)
+	require.Equal(t, "test-proj-template@0.0.1", projectConfig.Metadata.Template)
+	require.Equal(t, &source, projectConfig.Metadata.Source)
+	require.Len(t, projectConfig.Services, 1)
+}
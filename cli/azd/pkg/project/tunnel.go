@@ -0,0 +1,33 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TunnelOptions exposes the service started locally by azd run on the public URL of a dev tunnel, so the resources
+// provisioned in Azure can call it back, i.e. webhooks and Event Grid subscriptions
+type TunnelOptions struct {
+	// The local port the service listens on
+	Port int `yaml:"port"`
+	// Allows the clients that can't sign in to dev tunnels to connect, which webhooks require
+	AllowAnonymous bool `yaml:"allowAnonymous,omitempty"`
+}
+
+// Validate returns an error when the options don't name a local port
+func (o *TunnelOptions) Validate() error {
+	if o.Port < 1 || o.Port > 65535 {
+		return fmt.Errorf("invalid tunnel port %d, set the local port the service listens on", o.Port)
+	}
+
+	return nil
+}
+
+// TunnelUrlEnvVarName returns the name of the key the URL of the dev tunnel of the service is stored with in the
+// environment, for azure.yaml and the infrastructure to reference it, i.e. SERVICE_API_TUNNEL_URL
+func TunnelUrlEnvVarName(serviceName string) string {
+	return fmt.Sprintf("SERVICE_%s_TUNNEL_URL", strings.ToUpper(serviceName))
+}
@@ -0,0 +1,53 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTunnelOptionsValidate(t *testing.T) {
+	require.NoError(t, (&TunnelOptions{Port: 8080}).Validate())
+	require.NoError(t, (&TunnelOptions{Port: 65535, AllowAnonymous: true}).Validate())
+	require.Error(t, (&TunnelOptions{}).Validate())
+	require.Error(t, (&TunnelOptions{Port: 70000}).Validate())
+}
+
+func TestTunnelUrlEnvVarName(t *testing.T) {
+	require.Equal(t, "SERVICE_API_TUNNEL_URL", TunnelUrlEnvVarName("api"))
+}
+
+func TestTunnelUrlReferencedByAppSettings(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: js
+    host: appservice
+    tunnel:
+      port: 3000
+      allowAnonymous: true
+  web:
+    project: src/web
+    language: js
+    host: appservice
+    appSettings:
+      API_CALLBACK_URL: ${SERVICE_API_TUNNEL_URL}/callback
+`
+	e := environment.EphemeralWithValues("test-env", map[string]string{
+		"SERVICE_API_TUNNEL_URL": "https://x1y2z3-3000.usw2.devtunnels.ms",
+	})
+
+	projectConfig, err := ParseProjectConfig(testProj, e)
+	require.NoError(t, err)
+	require.Equal(t, &TunnelOptions{Port: 3000, AllowAnonymous: true}, projectConfig.Services["api"].Tunnel)
+	require.Equal(t,
+		"https://x1y2z3-3000.usw2.devtunnels.ms/callback",
+		projectConfig.Services["web"].AppSettings["API_CALLBACK_URL"])
+}
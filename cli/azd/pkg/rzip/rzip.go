@@ -5,20 +5,60 @@ package rzip
 
 import (
 	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"hash/crc32"
 	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
 )
 
-func CreateFromDirectory(source string, buf *os.File) error {
-	w := zip.NewWriter(buf)
-	err := filepath.WalkDir(source, func(path string, info fs.DirEntry, err error) error {
+// Files larger than this are compressed while writing the zip file, instead of in memory ahead of time
+const maxParallelFileSize = 8 * 1024 * 1024
+
+// File is a file of a directory, added to a zip file
+type File struct {
+	// The path of the file relative to the directory, with forward slashes, i.e. static/index.html
+	Name string
+	// The path of the file on disk
+	Path string
+	Info fs.FileInfo
+}
+
+// Files lists the files of the source directory, recursively, leaving out the files and directories matching any of
+// the exclude patterns. A pattern containing a slash, i.e. src/*.test.js, matches the paths relative to the source
+// directory, other patterns, i.e. node_modules or *.map, match the name of the files and directories at any depth.
+func Files(source string, exclude []string) ([]File, error) {
+	var files []File
+	err := filepath.WalkDir(source, func(filePath string, info fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
+		name := strings.Replace(
+			strings.TrimPrefix(
+				strings.TrimPrefix(filePath, source),
+				string(filepath.Separator)), "\\", "/", -1)
+
+		if name != "" {
+			excluded, err := Excluded(name, exclude)
+			if err != nil {
+				return err
+			}
+
+			if excluded {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+
 		if info.IsDir() {
 			return nil
 		}
@@ -27,32 +67,189 @@ func CreateFromDirectory(source string, buf *os.File) error {
 			return err
 		}
 
-		header := &zip.FileHeader{
-			Name: strings.Replace(
-				strings.TrimPrefix(
-					strings.TrimPrefix(path, source),
-					string(filepath.Separator)), "\\", "/", -1),
-			Modified: fileInfo.ModTime(),
-			Method:   zip.Deflate,
+		files = append(files, File{Name: name, Path: filePath, Info: fileInfo})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// Excluded returns true when the relative path, with forward slashes, matches any of the exclude patterns
+func Excluded(name string, exclude []string) (bool, error) {
+	for _, pattern := range exclude {
+		pattern = strings.Trim(strings.Replace(pattern, "\\", "/", -1), "/")
+		if pattern == "" {
+			continue
 		}
 
-		f, err := w.CreateHeader(header)
+		subject := name
+		if !strings.Contains(pattern, "/") {
+			subject = path.Base(name)
+		}
+
+		matched, err := path.Match(pattern, subject)
 		if err != nil {
-			return err
+			return false, fmt.Errorf("invalid exclude pattern '%s': %w", pattern, err)
+		}
+
+		if matched {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ValidateExclude returns an error when any of the exclude patterns is malformed
+func ValidateExclude(exclude []string) error {
+	_, err := Excluded("", exclude)
+	return err
+}
+
+func CreateFromDirectory(source string, buf *os.File, exclude []string) error {
+	files, err := Files(source, exclude)
+	if err != nil {
+		return err
+	}
+
+	return Write(files, buf)
+}
+
+// the content of a file compressed ahead of time, written to the zip file as is
+type compressedFile struct {
+	content []byte
+	crc32   uint32
+	size    int64
+	err     error
+}
+
+// Write writes a zip file of the files to w. The files are compressed in parallel, then written in order.
+func Write(files []File, w io.Writer) error {
+	zipWriter := zip.NewWriter(w)
+	workers := runtime.NumCPU()
+
+	results := make([]chan compressedFile, len(files))
+	for i := range results {
+		results[i] = make(chan compressedFile, 1)
+	}
+
+	// bounds the compressed files held in memory, waiting for their turn to be written
+	pending := make(chan struct{}, workers*2)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		jobs := make(chan int)
+		defer close(jobs)
+
+		for n := 0; n < workers; n++ {
+			go func() {
+				for i := range jobs {
+					results[i] <- compress(files[i])
+				}
+			}()
 		}
-		in, err := os.Open(path)
+
+		for i := range files {
+			select {
+			case pending <- struct{}{}:
+			case <-done:
+				return
+			}
+
+			jobs <- i
+		}
+	}()
+
+	for i, file := range files {
+		result := <-results[i]
+		<-pending
+		if result.err != nil {
+			return result.err
+		}
+
+		if result.content == nil {
+			if err := writeFile(zipWriter, file); err != nil {
+				return err
+			}
+			continue
+		}
+
+		header := &zip.FileHeader{
+			Name:               file.Name,
+			Modified:           file.Info.ModTime(),
+			Method:             zip.Deflate,
+			CRC32:              result.crc32,
+			CompressedSize64:   uint64(len(result.content)),
+			UncompressedSize64: uint64(result.size),
+		}
+
+		f, err := zipWriter.CreateRaw(header)
 		if err != nil {
 			return err
 		}
-		_, err = io.Copy(f, in)
-		if err != nil {
+
+		if _, err := f.Write(result.content); err != nil {
 			return err
 		}
-		return nil
-	})
+	}
+
+	return zipWriter.Close()
+}
+
+// compress compresses the content of the file in memory. The large files are left for writeFile to compress.
+func compress(file File) compressedFile {
+	if file.Info.Size() > maxParallelFileSize {
+		return compressedFile{}
+	}
+
+	in, err := os.Open(file.Path)
+	if err != nil {
+		return compressedFile{err: err}
+	}
+	defer in.Close()
+
+	var content bytes.Buffer
+	checksum := crc32.NewIEEE()
+	compressor, err := flate.NewWriter(&content, flate.DefaultCompression)
+	if err != nil {
+		return compressedFile{err: err}
+	}
+
+	size, err := io.Copy(io.MultiWriter(compressor, checksum), in)
+	if err != nil {
+		return compressedFile{err: err}
+	}
+
+	if err := compressor.Close(); err != nil {
+		return compressedFile{err: err}
+	}
+
+	return compressedFile{content: content.Bytes(), crc32: checksum.Sum32(), size: size}
+}
+
+// writeFile compresses the file while writing it to the zip file
+func writeFile(zipWriter *zip.Writer, file File) error {
+	header := &zip.FileHeader{
+		Name:     file.Name,
+		Modified: file.Info.ModTime(),
+		Method:   zip.Deflate,
+	}
+
+	f, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(file.Path)
 	if err != nil {
 		return err
 	}
+	defer in.Close()
 
-	return w.Close()
+	_, err = io.Copy(f, in)
+	return err
 }
@@ -0,0 +1,75 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package rzip
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateFromDirectory(t *testing.T) {
+	source := t.TempDir()
+	contents := map[string]string{
+		"index.html":                     "<html></html>",
+		"empty.txt":                      "",
+		"static/app.js":                  strings.Repeat("console.log('hello');\n", 1000),
+		"static/app.js.map":              "{}",
+		"node_modules/left-pad/index.js": "module.exports = {}",
+		"src/app.test.js":                "test()",
+		"src/app.js":                     "app()",
+	}
+	for name, content := range contents {
+		path := filepath.Join(source, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	zipFile, err := os.Create(filepath.Join(t.TempDir(), "app.zip"))
+	require.NoError(t, err)
+	defer zipFile.Close()
+
+	err = CreateFromDirectory(source, zipFile, []string{"node_modules", "*.map", "/src/*.test.js"})
+	require.NoError(t, err)
+	require.NoError(t, zipFile.Close())
+
+	reader, err := zip.OpenReader(zipFile.Name())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	actual := map[string]string{}
+	for _, file := range reader.File {
+		f, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(f)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		actual[file.Name] = string(content)
+	}
+
+	require.Equal(t, map[string]string{
+		"index.html":    contents["index.html"],
+		"empty.txt":     "",
+		"static/app.js": contents["static/app.js"],
+		"src/app.js":    contents["src/app.js"],
+	}, actual)
+}
+
+func TestExcluded(t *testing.T) {
+	excluded, err := Excluded("a/b/.env", []string{".env"})
+	require.NoError(t, err)
+	require.True(t, excluded)
+
+	excluded, err = Excluded("a/b/.env", []string{"b/.env"})
+	require.NoError(t, err)
+	require.False(t, excluded)
+
+	require.Error(t, ValidateExclude([]string{"*.map", "[a"}))
+	require.NoError(t, ValidateExclude([]string{"*.map", "src/*.test.js"}))
+}
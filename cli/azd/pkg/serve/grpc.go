@@ -0,0 +1,193 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// GrpcServiceName is the name of the gRPC service of the server. Each method of the server is a server streaming
+// method of the service, named after the method in Pascal case, i.e. "env/list" is "/azd.Serve/EnvList".
+//
+// The request of a method is a google.protobuf.Struct holding its params. The responses are google.protobuf.Struct
+// messages, {"progress": message} while the method runs and {"result": result} once it completes.
+const GrpcServiceName = "azd.Serve"
+
+// The metadata key of the token of the server, as "Bearer <token>"
+const authorizationMetadataKey = "authorization"
+
+// GrpcMethodName returns the name of the gRPC method of a method of the server
+func GrpcMethodName(method string) string {
+	var name strings.Builder
+	for _, part := range strings.FieldsFunc(method, func(r rune) bool { return r == '/' || r == '-' || r == '_' }) {
+		name.WriteString(strings.ToUpper(part[:1]))
+		name.WriteString(part[1:])
+	}
+
+	return name.String()
+}
+
+// ServeGrpc serves the methods of the server over gRPC to the connections accepted from listener until ctx is canceled.
+// Each call must carry the token of the server in its "authorization" metadata.
+func (s *Server) ServeGrpc(ctx context.Context, listener net.Listener) error {
+	grpcServer := grpc.NewServer(grpc.StreamInterceptor(s.authorizeStream))
+	grpcServer.RegisterService(s.grpcServiceDesc(), s)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	defer wg.Wait()
+
+	stopped := make(chan struct{})
+	defer close(stopped)
+
+	go func() {
+		defer wg.Done()
+
+		select {
+		case <-ctx.Done():
+			// Operations in progress are canceled
+			grpcServer.Stop()
+		case <-stopped:
+		}
+	}()
+
+	if err := grpcServer.Serve(listener); err != nil {
+		return fmt.Errorf("serving: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) grpcServiceDesc() *grpc.ServiceDesc {
+	desc := &grpc.ServiceDesc{
+		ServiceName: GrpcServiceName,
+		HandlerType: (*any)(nil),
+	}
+
+	for method, handler := range s.handlers {
+		handler := handler
+		desc.Streams = append(desc.Streams, grpc.StreamDesc{
+			StreamName:    GrpcMethodName(method),
+			ServerStreams: true,
+			Handler: func(_ any, stream grpc.ServerStream) error {
+				return serveGrpcStream(stream, handler)
+			},
+		})
+	}
+
+	return desc
+}
+
+// authorizeStream rejects the calls without the token of the server
+func (s *Server) authorizeStream(
+	srv any,
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	for _, value := range md.Get(authorizationMetadataKey) {
+		if token, has := cutPrefix(value, "Bearer "); has && s.authorized(token) {
+			return handler(srv, stream)
+		}
+	}
+
+	return status.Error(codes.Unauthenticated, "unauthorized")
+}
+
+func serveGrpcStream(stream grpc.ServerStream, handler Handler) error {
+	var request structpb.Struct
+	if err := stream.RecvMsg(&request); err != nil {
+		return err
+	}
+
+	params, err := protojson.Marshal(&request)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid params: %v", err)
+	}
+
+	// Progress is reported from the goroutines of the handler, a stream doesn't support concurrent sends
+	var sendMu sync.Mutex
+	send := func(response *structpb.Struct) error {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+
+		return stream.SendMsg(response)
+	}
+
+	ctx := stream.Context()
+	result, err := handler(ctx, params, func(message string) {
+		response := &structpb.Struct{Fields: map[string]*structpb.Value{"progress": structpb.NewStringValue(message)}}
+		// A failed send means the call is canceled, which cancels the handler
+		_ = send(response)
+	})
+	if err != nil {
+		return grpcError(ctx, err)
+	}
+
+	value, err := toStructValue(result)
+	if err != nil {
+		return status.Errorf(codes.Internal, "encoding result: %v", err)
+	}
+
+	return send(&structpb.Struct{Fields: map[string]*structpb.Value{"result": value}})
+}
+
+// toStructValue converts the result of a handler to a google.protobuf.Value through its JSON encoding
+func toStructValue(result any) (*structpb.Value, error) {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	var value structpb.Value
+	if err := protojson.Unmarshal(encoded, &value); err != nil {
+		return nil, err
+	}
+
+	return &value, nil
+}
+
+// grpcError returns the status of the error of a handler
+func grpcError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+
+	var rpcErr *Error
+	if !errors.As(err, &rpcErr) {
+		rpcErr = &Error{Code: OperationFailedCode, Message: err.Error()}
+	}
+
+	switch rpcErr.Code {
+	case InvalidParamsCode:
+		return status.Error(codes.InvalidArgument, rpcErr.Message)
+	case OperationFailedCode:
+		return status.Error(codes.Aborted, rpcErr.Message)
+	default:
+		return status.Error(codes.Unknown, rpcErr.Message)
+	}
+}
+
+// cutPrefix is strings.CutPrefix, which requires go 1.20
+func cutPrefix(s string, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return s, false
+	}
+
+	return s[len(prefix):], true
+}
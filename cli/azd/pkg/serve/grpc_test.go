@@ -0,0 +1,161 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// newGrpcTestClient connects a gRPC client to server
+func newGrpcTestClient(t *testing.T, server *Server) *grpc.ClientConn {
+	listener := bufconn.Listen(1024 * 1024)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = server.ServeGrpc(ctx, listener)
+	}()
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+		<-done
+	})
+
+	return conn
+}
+
+// callGrpc calls a method of the server with params and returns its responses
+func callGrpc(
+	ctx context.Context,
+	conn *grpc.ClientConn,
+	token string,
+	method string,
+	params *structpb.Struct,
+) ([]*structpb.Struct, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, authorizationMetadataKey, "Bearer "+token)
+	stream, err := conn.NewStream(
+		ctx, &grpc.StreamDesc{ServerStreams: true}, "/"+GrpcServiceName+"/"+GrpcMethodName(method))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stream.SendMsg(params); err != nil {
+		return nil, err
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	var responses []*structpb.Struct
+	for {
+		response := &structpb.Struct{}
+		if err := stream.RecvMsg(response); err == io.EOF {
+			return responses, nil
+		} else if err != nil {
+			return responses, err
+		}
+
+		responses = append(responses, response)
+	}
+}
+
+func TestGrpcMethodName(t *testing.T) {
+	require.Equal(t, "EnvList", GrpcMethodName("env/list"))
+	require.Equal(t, "Provision", GrpcMethodName("provision"))
+	require.Equal(t, "PipelineConfig", GrpcMethodName("pipeline/config"))
+}
+
+func TestServeGrpc(t *testing.T) {
+	server := newTestServer()
+	server.Handle("env/list", func(ctx context.Context, params json.RawMessage, progress func(string)) (any, error) {
+		progress("listing")
+		return []map[string]any{{"name": "dev", "isDefault": true}}, nil
+	})
+	conn := newGrpcTestClient(t, server)
+
+	t.Run("Result", func(t *testing.T) {
+		responses, err := callGrpc(context.Background(), conn, testToken, "env/list", &structpb.Struct{})
+		require.NoError(t, err)
+		require.Len(t, responses, 2)
+		require.Equal(t, map[string]any{"progress": "listing"}, responses[0].AsMap())
+		require.Equal(
+			t,
+			map[string]any{"result": []any{map[string]any{"name": "dev", "isDefault": true}}},
+			responses[1].AsMap(),
+		)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		invalidParams, err := structpb.NewStruct(map[string]any{"value": 42})
+		require.NoError(t, err)
+
+		tests := []struct {
+			method string
+			params *structpb.Struct
+			code   codes.Code
+		}{
+			{"fail", &structpb.Struct{}, codes.Aborted},
+			{"missing", &structpb.Struct{}, codes.Unimplemented},
+			{"echo", invalidParams, codes.InvalidArgument},
+		}
+
+		for _, test := range tests {
+			_, err := callGrpc(context.Background(), conn, testToken, test.method, test.params)
+			require.Equal(t, test.code, status.Code(err), test.method)
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ctx = metadata.AppendToOutgoingContext(ctx, authorizationMetadataKey, "Bearer "+testToken)
+		stream, err := conn.NewStream(
+			ctx, &grpc.StreamDesc{ServerStreams: true}, "/"+GrpcServiceName+"/"+GrpcMethodName("wait"))
+		require.NoError(t, err)
+		require.NoError(t, stream.SendMsg(&structpb.Struct{}))
+		require.NoError(t, stream.CloseSend())
+
+		// Once the progress is received, the request is in progress
+		progress := &structpb.Struct{}
+		require.NoError(t, stream.RecvMsg(progress))
+		require.Equal(t, "waiting", progress.AsMap()["progress"])
+
+		cancel()
+		require.Equal(t, codes.Canceled, status.Code(stream.RecvMsg(&structpb.Struct{})))
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		for _, token := range []string{"", "OTHER"} {
+			_, err := callGrpc(context.Background(), conn, token, "env/list", &structpb.Struct{})
+			require.Equal(t, codes.Unauthenticated, status.Code(err))
+		}
+	})
+}
@@ -0,0 +1,322 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package serve implements the JSON-RPC 2.0 server of `azd serve`, exposing azd operations to editor integrations and
+// GUI frontends over a local connection.
+//
+// Messages are JSON objects, one per line. The first request of a connection must be "authenticate" with the token of
+// the server. The progress of a request is streamed as "progress" notifications carrying the id of the request, and a
+// request in progress is canceled with the "cancel" notification.
+//
+// The same operations are served over gRPC by ServeGrpc.
+package serve
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+)
+
+// The JSON-RPC 2.0 error codes
+const (
+	ParseErrorCode      = -32700
+	InvalidRequestCode  = -32600
+	MethodNotFoundCode  = -32601
+	InvalidParamsCode   = -32602
+	OperationFailedCode = -32000
+	UnauthorizedCode    = -32001
+)
+
+// The maximum size of a message, requests are small but the limit must allow for large parameters
+const maxMessageSize = 1024 * 1024
+
+// Request is a JSON-RPC request, or a notification when it has no id
+type Request struct {
+	JsonRpc string           `json:"jsonrpc"`
+	Id      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+// Response is the JSON-RPC response to a request
+type Response struct {
+	JsonRpc string           `json:"jsonrpc"`
+	Id      *json.RawMessage `json:"id"`
+	Result  any              `json:"result,omitempty"`
+	Error   *Error           `json:"error,omitempty"`
+}
+
+// Notification is a message sent by the server without a response
+type Notification struct {
+	JsonRpc string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+// Error is the error of a failed request
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NewInvalidParamsError returns the error of a request with invalid parameters
+func NewInvalidParamsError(err error) *Error {
+	return &Error{Code: InvalidParamsCode, Message: fmt.Sprintf("invalid params: %v", err)}
+}
+
+// ProgressParams are the params of the "progress" notifications of a request
+type ProgressParams struct {
+	Id      *json.RawMessage `json:"id"`
+	Message string           `json:"message"`
+}
+
+// AuthenticateParams are the params of the "authenticate" request
+type AuthenticateParams struct {
+	Token string `json:"token"`
+}
+
+// CancelParams are the params of the "cancel" notification
+type CancelParams struct {
+	Id *json.RawMessage `json:"id"`
+}
+
+// Handler handles the requests of a method. The progress of the request is reported with progress. An error other than
+// an *Error fails the request with OperationFailedCode.
+type Handler func(ctx context.Context, params json.RawMessage, progress func(message string)) (any, error)
+
+// Server dispatches the requests of its connections to the handlers of their methods
+type Server struct {
+	handlers map[string]Handler
+	// The token clients authenticate with, the operations act on the Azure resources of the signed in user
+	token string
+}
+
+// NewServer creates a server without any method, serving the clients authenticated with token
+func NewServer(token string) *Server {
+	return &Server{
+		handlers: map[string]Handler{},
+		token:    token,
+	}
+}
+
+// NewToken returns a random token for the clients of a server to authenticate with
+func NewToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating token: %w", err)
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// authorized returns whether token is the token of the server
+func (s *Server) authorized(token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// Handle registers the handler of a method
+func (s *Server) Handle(method string, handler Handler) {
+	s.handlers[method] = handler
+}
+
+// Serve accepts connections from listener until ctx is canceled
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("accepting connection: %w", err)
+		}
+
+		go s.ServeConn(ctx, conn)
+	}
+}
+
+// ServeConn serves the requests of a connection until it is closed or ctx is canceled. The requests are handled
+// concurrently.
+func (s *Server) ServeConn(ctx context.Context, conn io.ReadWriteCloser) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c := &connection{
+		server:   s,
+		conn:     conn,
+		encoder:  json.NewEncoder(conn),
+		requests: map[string]context.CancelFunc{},
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+
+	authenticated := false
+	var wg sync.WaitGroup
+	for scanner.Scan() {
+		var request Request
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			// The connection isn't a JSON-RPC client, i.e. an HTTP request sent by a browser to the local port
+			c.send(Response{JsonRpc: "2.0", Error: &Error{Code: ParseErrorCode, Message: "parse error"}})
+			break
+		}
+
+		if !authenticated {
+			if !c.authenticate(request) {
+				break
+			}
+
+			authenticated = true
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.dispatch(ctx, request)
+		}()
+	}
+
+	if err := scanner.Err(); err != nil && !errors.Is(err, net.ErrClosed) {
+		log.Printf("reading requests: %v", err)
+	}
+
+	// Requests in progress are canceled when their client disconnects
+	cancel()
+	wg.Wait()
+}
+
+type connection struct {
+	server  *Server
+	conn    io.ReadWriteCloser
+	encoder *json.Encoder
+	// Serializes the messages written to the connection
+	writeMu sync.Mutex
+	// The requests in progress, by id, to cancel them
+	requestsMu sync.Mutex
+	requests   map[string]context.CancelFunc
+}
+
+// authenticate replies to the first request of the connection, which must be "authenticate" with the token of the
+// server
+func (c *connection) authenticate(request Request) bool {
+	var params AuthenticateParams
+	if request.Method != "authenticate" || request.Id == nil ||
+		json.Unmarshal(request.Params, &params) != nil || !c.server.authorized(params.Token) {
+		c.reply(request, nil, &Error{Code: UnauthorizedCode, Message: "unauthorized"})
+		return false
+	}
+
+	c.reply(request, true, nil)
+	return true
+}
+
+func (c *connection) dispatch(ctx context.Context, request Request) {
+	if request.Method == "cancel" {
+		var params CancelParams
+		if err := json.Unmarshal(request.Params, &params); err == nil && params.Id != nil {
+			c.requestsMu.Lock()
+			if cancel, has := c.requests[string(*params.Id)]; has {
+				cancel()
+			}
+			c.requestsMu.Unlock()
+		}
+
+		return
+	}
+
+	if request.JsonRpc != "2.0" || request.Method == "" {
+		c.reply(request, nil, &Error{Code: InvalidRequestCode, Message: "invalid request"})
+		return
+	}
+
+	handler, has := c.server.handlers[request.Method]
+	if !has {
+		c.reply(request, nil, &Error{Code: MethodNotFoundCode, Message: fmt.Sprintf("method '%s' not found", request.Method)})
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if request.Id != nil {
+		c.requestsMu.Lock()
+		c.requests[string(*request.Id)] = cancel
+		c.requestsMu.Unlock()
+
+		defer func() {
+			c.requestsMu.Lock()
+			delete(c.requests, string(*request.Id))
+			c.requestsMu.Unlock()
+		}()
+	}
+
+	result, err := handler(ctx, request.Params, func(message string) {
+		if request.Id != nil {
+			c.send(Notification{
+				JsonRpc: "2.0",
+				Method:  "progress",
+				Params:  ProgressParams{Id: request.Id, Message: message},
+			})
+		}
+	})
+
+	c.reply(request, result, err)
+}
+
+// Replies to a request, notifications have no response
+func (c *connection) reply(request Request, result any, err error) {
+	if request.Id == nil {
+		if err != nil {
+			log.Printf("notification '%s' failed: %v", request.Method, err)
+		}
+
+		return
+	}
+
+	response := Response{JsonRpc: "2.0", Id: request.Id}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: OperationFailedCode, Message: err.Error()}
+		}
+
+		response.Error = rpcErr
+	} else {
+		response.Result = result
+	}
+
+	c.send(response)
+}
+
+func (c *connection) send(message any) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if err := c.encoder.Encode(message); err != nil {
+		log.Printf("writing message: %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package serve
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testClient struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+}
+
+const testToken = "TOKEN"
+
+// newTestClient connects a client to server, authenticated with token
+func newTestClient(t *testing.T, server *Server, token string) *testClient {
+	serverConn, clientConn := net.Pipe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		server.ServeConn(ctx, serverConn)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		clientConn.Close()
+		<-done
+	})
+
+	client := &testClient{conn: clientConn, scanner: bufio.NewScanner(clientConn)}
+	client.send(t, fmt.Sprintf(`{"jsonrpc":"2.0","id":0,"method":"authenticate","params":{"token":"%s"}}`, token))
+	return client
+}
+
+// newAuthenticatedTestClient connects a client authenticated with the token of the server
+func newAuthenticatedTestClient(t *testing.T, server *Server) *testClient {
+	client := newTestClient(t, server, testToken)
+	require.Equal(t, true, client.receive(t)["result"])
+	return client
+}
+
+func (c *testClient) send(t *testing.T, message string) {
+	_, err := fmt.Fprintln(c.conn, message)
+	require.NoError(t, err)
+}
+
+func (c *testClient) receive(t *testing.T) map[string]any {
+	require.True(t, c.scanner.Scan(), "the connection was closed")
+
+	var message map[string]any
+	require.NoError(t, json.Unmarshal(c.scanner.Bytes(), &message))
+	return message
+}
+
+func newTestServer() *Server {
+	server := NewServer(testToken)
+	server.Handle("echo", func(ctx context.Context, params json.RawMessage, progress func(string)) (any, error) {
+		var value string
+		if err := json.Unmarshal(params, &value); err != nil {
+			return nil, NewInvalidParamsError(err)
+		}
+
+		progress("echoing")
+		return value, nil
+	})
+	server.Handle("fail", func(ctx context.Context, params json.RawMessage, progress func(string)) (any, error) {
+		return nil, errors.New("operation failed")
+	})
+	server.Handle("wait", func(ctx context.Context, params json.RawMessage, progress func(string)) (any, error) {
+		progress("waiting")
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	return server
+}
+
+func TestServer(t *testing.T) {
+	server := newTestServer()
+
+	t.Run("Result", func(t *testing.T) {
+		client := newAuthenticatedTestClient(t, server)
+		client.send(t, `{"jsonrpc":"2.0","id":1,"method":"echo","params":"hello"}`)
+
+		progress := client.receive(t)
+		require.Equal(t, "progress", progress["method"])
+		require.Equal(t, map[string]any{"id": float64(1), "message": "echoing"}, progress["params"])
+
+		response := client.receive(t)
+		require.Equal(t, float64(1), response["id"])
+		require.Equal(t, "hello", response["result"])
+		require.Nil(t, response["error"])
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		client := newAuthenticatedTestClient(t, server)
+
+		tests := []struct {
+			request string
+			code    int
+		}{
+			{`{"jsonrpc":"2.0","id":"a","method":"fail"}`, OperationFailedCode},
+			{`{"jsonrpc":"2.0","id":"a","method":"missing"}`, MethodNotFoundCode},
+			{`{"jsonrpc":"2.0","id":"a","method":"echo","params":42}`, InvalidParamsCode},
+			{`{"jsonrpc":"1.0","id":"a","method":"echo"}`, InvalidRequestCode},
+		}
+
+		for _, test := range tests {
+			client.send(t, test.request)
+
+			response := client.receive(t)
+			require.Equal(t, "a", response["id"])
+			require.Equal(t, float64(test.code), response["error"].(map[string]any)["code"], test.request)
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		client := newAuthenticatedTestClient(t, server)
+		client.send(t, `{"jsonrpc":"2.0","id":7,"method":"wait"}`)
+
+		// Once the progress is received, the request is in progress
+		require.Equal(t, "progress", client.receive(t)["method"])
+		client.send(t, `{"jsonrpc":"2.0","method":"cancel","params":{"id":7}}`)
+
+		response := client.receive(t)
+		require.Equal(t, float64(7), response["id"])
+		require.Equal(t, float64(OperationFailedCode), response["error"].(map[string]any)["code"])
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		for _, token := range []string{"", "OTHER"} {
+			client := newTestClient(t, server, token)
+
+			response := client.receive(t)
+			require.Equal(t, float64(UnauthorizedCode), response["error"].(map[string]any)["code"])
+
+			// The connection is closed
+			require.False(t, client.scanner.Scan())
+		}
+	})
+
+	t.Run("NotAuthenticated", func(t *testing.T) {
+		serverConn, clientConn := net.Pipe()
+		go server.ServeConn(context.Background(), serverConn)
+		defer clientConn.Close()
+
+		client := &testClient{conn: clientConn, scanner: bufio.NewScanner(clientConn)}
+		client.send(t, `{"jsonrpc":"2.0","id":1,"method":"echo","params":"hello"}`)
+
+		response := client.receive(t)
+		require.Equal(t, float64(UnauthorizedCode), response["error"].(map[string]any)["code"])
+		require.False(t, client.scanner.Scan())
+	})
+
+	t.Run("ParseError", func(t *testing.T) {
+		client := newAuthenticatedTestClient(t, server)
+		client.send(t, "GET / HTTP/1.1")
+
+		response := client.receive(t)
+		require.Equal(t, float64(ParseErrorCode), response["error"].(map[string]any)["code"])
+
+		// The connection is closed
+		require.False(t, client.scanner.Scan())
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/theckman/yacspin"
 )
 
@@ -93,6 +94,11 @@ func NewSpinner(writer io.Writer, title string) *Spinner {
 		// The current LogMessage functionality depends on the StopMessage being empty.
 	}
 
+	// The animation would garble the logs of a CI run, the progress is written as plain lines instead
+	if internal.IsNonInteractiveCI() {
+		config.TerminalMode = yacspin.ForceNoTTYMode | yacspin.ForceDumbTerminalMode
+	}
+
 	spinner, _ := yacspin.New(config)
 
 	return &Spinner{
@@ -101,6 +107,22 @@ func NewSpinner(writer io.Writer, title string) *Spinner {
 	}
 }
 
+// NewHiddenSpinner creates a spinner that isn't rendered, the messages written through the spinner are written to
+// writer as plain lines
+func NewHiddenSpinner(writer io.Writer) *Spinner {
+	spinner, _ := yacspin.New(yacspin.Config{
+		Frequency:    time.Millisecond * 500,
+		CharSet:      yacspin.CharSets[9],
+		Writer:       io.Discard,
+		TerminalMode: yacspin.ForceNoTTYMode | yacspin.ForceDumbTerminalMode,
+	})
+
+	return &Spinner{
+		writer:  writer,
+		spinner: spinner,
+	}
+}
+
 type contextKey string
 
 const (
@@ -0,0 +1,133 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package templates
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+)
+
+// The name of the file describing a cached template, next to the folder of its files
+const cachedTemplateFileName = "template.json"
+
+// CachedTemplate is a version of a template downloaded to the template cache
+type CachedTemplate struct {
+	Repository string    `json:"repository"`
+	Branch     string    `json:"branch,omitempty"`
+	Commit     string    `json:"commit"`
+	CachedAt   time.Time `json:"cachedAt"`
+	// The path to the files of the template
+	Path string `json:"-"`
+}
+
+// TemplateCache keeps the last downloaded version of each template, for `azd init` to work without network access
+type TemplateCache struct {
+	path string
+}
+
+// NewTemplateCache creates a template cache stored in the given folder
+func NewTemplateCache(path string) *TemplateCache {
+	return &TemplateCache{
+		path: path,
+	}
+}
+
+// DefaultTemplateCachePath returns the folder of the template cache, in the azd user config directory
+func DefaultTemplateCachePath() (string, error) {
+	configDir, err := config.GetUserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "templates"), nil
+}
+
+// Get returns the cached version of the given branch of a template repository, or nil when it isn't cached
+func (c *TemplateCache) Get(repository string, branch string) (*CachedTemplate, error) {
+	entryPath := c.entryPath(repository, branch)
+
+	contents, err := os.ReadFile(filepath.Join(entryPath, cachedTemplateFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading cached template: %w", err)
+	}
+
+	var cached CachedTemplate
+	if err := json.Unmarshal(contents, &cached); err != nil {
+		return nil, fmt.Errorf("reading cached template: %w", err)
+	}
+
+	cached.Path = filepath.Join(entryPath, "files")
+	return &cached, nil
+}
+
+// Add downloads a version of a template into the cache with fetch, replacing the version cached before
+func (c *TemplateCache) Add(
+	repository string,
+	branch string,
+	commit string,
+	fetch func(target string) error,
+) (*CachedTemplate, error) {
+	if err := os.MkdirAll(c.path, osutil.PermissionDirectory); err != nil {
+		return nil, fmt.Errorf("creating template cache: %w", err)
+	}
+
+	// The template is downloaded next to the cached version, which is only replaced once the download succeeds
+	stagingPath, err := os.MkdirTemp(c.path, "download")
+	if err != nil {
+		return nil, fmt.Errorf("creating temp folder: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(stagingPath)
+	}()
+
+	if err := fetch(filepath.Join(stagingPath, "files")); err != nil {
+		return nil, err
+	}
+
+	cached := CachedTemplate{
+		Repository: repository,
+		Branch:     branch,
+		Commit:     commit,
+		CachedAt:   time.Now().UTC(),
+	}
+
+	contents, err := json.MarshalIndent(cached, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling cached template: %w", err)
+	}
+
+	if err := os.WriteFile(
+		filepath.Join(stagingPath, cachedTemplateFileName), contents, osutil.PermissionFile); err != nil {
+		return nil, fmt.Errorf("writing cached template: %w", err)
+	}
+
+	entryPath := c.entryPath(repository, branch)
+	if err := os.RemoveAll(entryPath); err != nil {
+		return nil, fmt.Errorf("removing the previously cached template: %w", err)
+	}
+
+	if err := os.Rename(stagingPath, entryPath); err != nil {
+		return nil, fmt.Errorf("caching template: %w", err)
+	}
+
+	cached.Path = filepath.Join(entryPath, "files")
+	return &cached, nil
+}
+
+// Each branch of a template repository is cached in its own folder
+func (c *TemplateCache) entryPath(repository string, branch string) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s#%s", repository, branch)))
+	return filepath.Join(c.path, hex.EncodeToString(hash[:])[:16])
+}
@@ -0,0 +1,57 @@
+package templates
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateCache(t *testing.T) {
+	cache := NewTemplateCache(t.TempDir())
+	const repository = "https://github.com/Azure-Samples/todo-nodejs-mongo"
+
+	cached, err := cache.Get(repository, "")
+	require.NoError(t, err)
+	require.Nil(t, cached)
+
+	fetch := func(contents string) func(target string) error {
+		return func(target string) error {
+			writeFiles(t, target, map[string]string{"azure.yaml": contents})
+			return nil
+		}
+	}
+
+	_, err = cache.Add(repository, "", "commit1", fetch("v1"))
+	require.NoError(t, err)
+
+	// A new version replaces the cached version
+	_, err = cache.Add(repository, "", "commit2", fetch("v2"))
+	require.NoError(t, err)
+
+	cached, err = cache.Get(repository, "")
+	require.NoError(t, err)
+	require.Equal(t, repository, cached.Repository)
+	require.Equal(t, "commit2", cached.Commit)
+
+	contents, err := os.ReadFile(filepath.Join(cached.Path, "azure.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "v2", string(contents))
+
+	// A failed download keeps the cached version
+	_, err = cache.Add(repository, "", "commit3", func(target string) error {
+		return errors.New("network unavailable")
+	})
+	require.Error(t, err)
+
+	cached, err = cache.Get(repository, "")
+	require.NoError(t, err)
+	require.Equal(t, "commit2", cached.Commit)
+
+	// Each branch is cached separately
+	cached, err = cache.Get(repository, "dev")
+	require.NoError(t, err)
+	require.Nil(t, cached)
+}
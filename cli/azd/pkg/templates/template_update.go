@@ -0,0 +1,153 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TemplateFileStatus describes how a file of the project relates to the upstream changes of its template
+type TemplateFileStatus string
+
+const (
+	// The file was added upstream and doesn't exist in the project
+	TemplateFileAdded TemplateFileStatus = "added"
+	// The file was changed upstream and is unchanged in the project
+	TemplateFileUpdated TemplateFileStatus = "updated"
+	// The file was deleted upstream and is unchanged in the project
+	TemplateFileDeleted TemplateFileStatus = "deleted"
+	// The file was changed upstream and in the project, the changes have to be merged
+	TemplateFileConflict TemplateFileStatus = "conflict"
+)
+
+// TemplateFileChange is an upstream change of a file of the template
+type TemplateFileChange struct {
+	// The path of the file relative to the project directory
+	Path   string
+	Status TemplateFileStatus
+}
+
+// DiffTemplate compares the files under the given relative paths of the pinned version of a template (basePath), its
+// latest version (latestPath) and the project (projectPath). Returns the files changed upstream, sorted by path.
+func DiffTemplate(basePath string, latestPath string, projectPath string, paths []string) ([]TemplateFileChange, error) {
+	files := map[string]struct{}{}
+	for _, root := range []string{basePath, latestPath} {
+		for _, path := range paths {
+			if err := listFiles(root, path, files); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var changes []TemplateFileChange
+	for file := range files {
+		base, err := readOptionalFile(filepath.Join(basePath, file))
+		if err != nil {
+			return nil, err
+		}
+
+		latest, err := readOptionalFile(filepath.Join(latestPath, file))
+		if err != nil {
+			return nil, err
+		}
+
+		local, err := readOptionalFile(filepath.Join(projectPath, file))
+		if err != nil {
+			return nil, err
+		}
+
+		status, changed := templateFileStatus(base, latest, local)
+		if changed {
+			changes = append(changes, TemplateFileChange{Path: file, Status: status})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// Returns the status of a file from its contents in each version, nil when the file doesn't exist in the version
+func templateFileStatus(base []byte, latest []byte, local []byte) (TemplateFileStatus, bool) {
+	switch {
+	case sameContents(base, latest) || sameContents(local, latest):
+		// Unchanged upstream, or the project already has the upstream changes
+		return "", false
+	case base == nil && local == nil:
+		return TemplateFileAdded, true
+	case latest == nil && sameContents(local, base):
+		return TemplateFileDeleted, true
+	case local != nil && sameContents(local, base):
+		return TemplateFileUpdated, true
+	default:
+		return TemplateFileConflict, true
+	}
+}
+
+func sameContents(a []byte, b []byte) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return bytes.Equal(a, b)
+}
+
+// Adds the files under root/path to files, by their path relative to root
+func listFiles(root string, path string, files map[string]struct{}) error {
+	err := filepath.WalkDir(filepath.Join(root, path), func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		relative, err := filepath.Rel(root, file)
+		if err != nil {
+			return fmt.Errorf("computing relative path: %w", err)
+		}
+
+		files[relative] = struct{}{}
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("enumerating template files: %w", err)
+	}
+
+	return nil
+}
+
+// Reads a file, returns nil when the file doesn't exist
+func readOptionalFile(path string) ([]byte, error) {
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	// Empty files exist
+	if contents == nil {
+		contents = []byte{}
+	}
+
+	return contents, nil
+}
+
+// TemplateUpdatePaths returns the paths of the project updated from the template: its infrastructure and pipelines
+func TemplateUpdatePaths(infraPath string) []string {
+	if strings.TrimSpace(infraPath) == "" {
+		infraPath = "infra"
+	}
+
+	return []string{infraPath, ".github", ".azdo"}
+}
@@ -0,0 +1,57 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTemplate(t *testing.T) {
+	base := t.TempDir()
+	latest := t.TempDir()
+	project := t.TempDir()
+
+	writeFiles(t, base, map[string]string{
+		"infra/main.bicep":           "base",
+		"infra/app.bicep":            "base",
+		"infra/removed.bicep":        "base",
+		"infra/customized.bicep":     "base",
+		".github/workflows/azd.yaml": "base",
+		"src/index.js":               "base",
+	})
+	writeFiles(t, latest, map[string]string{
+		"infra/main.bicep":           "latest",
+		"infra/app.bicep":            "base",
+		"infra/added.bicep":          "latest",
+		"infra/customized.bicep":     "latest",
+		".github/workflows/azd.yaml": "latest",
+		"src/index.js":               "latest",
+	})
+	writeFiles(t, project, map[string]string{
+		"infra/main.bicep":           "base",
+		"infra/app.bicep":            "local",
+		"infra/removed.bicep":        "base",
+		"infra/customized.bicep":     "local",
+		".github/workflows/azd.yaml": "latest",
+		"src/index.js":               "base",
+	})
+
+	changes, err := DiffTemplate(base, latest, project, TemplateUpdatePaths(""))
+	require.NoError(t, err)
+	require.Equal(t, []TemplateFileChange{
+		{Path: filepath.Join("infra", "added.bicep"), Status: TemplateFileAdded},
+		{Path: filepath.Join("infra", "customized.bicep"), Status: TemplateFileConflict},
+		{Path: filepath.Join("infra", "main.bicep"), Status: TemplateFileUpdated},
+		{Path: filepath.Join("infra", "removed.bicep"), Status: TemplateFileDeleted},
+	}, changes)
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	for path, contents := range files {
+		fullPath := filepath.Join(root, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0700))
+		require.NoError(t, os.WriteFile(fullPath, []byte(contents), 0600))
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 )
 
 var (
@@ -27,6 +28,13 @@ type AzCliSubscriptionInfo struct {
 	IsDefault bool   `json:"isDefault"`
 }
 
+// AzCliTenant is a tenant the signed-in account has access to
+type AzCliTenant struct {
+	Id            string `json:"id"`
+	DisplayName   string `json:"displayName"`
+	DefaultDomain string `json:"defaultDomain"`
+}
+
 type AzCliLocation struct {
 	// The human friendly name of the location (e.g. "West US 2")
 	DisplayName string `json:"displayName"`
@@ -35,6 +43,8 @@ type AzCliLocation struct {
 	// The human friendly name of the location, prefixed with a
 	// region name (e.g "(US) West US 2")
 	RegionalDisplayName string `json:"regionalDisplayName"`
+	// The human friendly name of the location paired with this location for disaster recovery, if any
+	PairedRegionDisplayName string `json:"pairedRegionDisplayName,omitempty"`
 }
 
 // AzCliAccessToken represents the value returned by `az account get-access-token`
@@ -75,6 +85,42 @@ func (cli *azCli) ListAccounts(ctx context.Context) ([]*AzCliSubscriptionInfo, e
 	return subscriptions, nil
 }
 
+func (cli *azCli) ListTenants(ctx context.Context) ([]AzCliTenant, error) {
+	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
+	client, err := armsubscriptions.NewTenantsClient(cli.credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating Tenants client: %w", err)
+	}
+
+	tenants := []AzCliTenant{}
+	pager := client.NewListPager(nil)
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed getting next page of tenants: %w", err)
+		}
+
+		for _, tenant := range page.TenantListResult.Value {
+			if tenant.TenantID == nil {
+				continue
+			}
+
+			tenants = append(tenants, AzCliTenant{
+				Id:            *tenant.TenantID,
+				DisplayName:   convert.ToValueWithDefault(tenant.DisplayName, *tenant.TenantID),
+				DefaultDomain: convert.ToValueWithDefault(tenant.DefaultDomain, ""),
+			})
+		}
+	}
+
+	sort.Slice(tenants, func(i, j int) bool {
+		return tenants[i].DisplayName < tenants[j].DisplayName
+	})
+
+	return tenants, nil
+}
+
 func (cli *azCli) GetDefaultAccount(ctx context.Context) (*AzCliSubscriptionInfo, error) {
 	result, err := cli.runAzCommand(
 		ctx,
@@ -120,6 +166,9 @@ func (cli *azCli) ListAccountLocations(ctx context.Context, subscriptionId strin
 	}
 
 	locations := []AzCliLocation{}
+	// The paired regions are referenced by name, the names are resolved once all the locations are listed
+	pairedRegions := map[string]string{}
+	displayNames := map[string]string{}
 	pager := client.NewListLocationsPager(subscriptionId, nil)
 
 	for pager.More() {
@@ -129,11 +178,17 @@ func (cli *azCli) ListAccountLocations(ctx context.Context, subscriptionId strin
 		}
 
 		for _, location := range page.LocationListResult.Value {
+			displayNames[*location.Name] = *location.DisplayName
+
 			// Ignore non-physical locations
 			if *location.Metadata.RegionType != "Physical" {
 				continue
 			}
 
+			if len(location.Metadata.PairedRegion) > 0 && location.Metadata.PairedRegion[0].Name != nil {
+				pairedRegions[*location.Name] = *location.Metadata.PairedRegion[0].Name
+			}
+
 			locations = append(locations, AzCliLocation{
 				Name:                *location.Name,
 				DisplayName:         *location.DisplayName,
@@ -142,6 +197,12 @@ func (cli *azCli) ListAccountLocations(ctx context.Context, subscriptionId strin
 		}
 	}
 
+	for index, location := range locations {
+		if pairedRegion, has := pairedRegions[location.Name]; has {
+			locations[index].PairedRegionDisplayName = displayNames[pairedRegion]
+		}
+	}
+
 	sort.Slice(locations, func(i, j int) bool {
 		return locations[i].RegionalDisplayName < locations[j].RegionalDisplayName
 	})
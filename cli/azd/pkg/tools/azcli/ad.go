@@ -6,14 +6,16 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
-	"github.com/azure/azure-dev/cli/azd/pkg/identity"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 	"github.com/google/uuid"
 	"github.com/sethvargo/go-retry"
 )
@@ -21,7 +23,7 @@ import (
 // Required model structure for Azure Credentials tools
 type AzureCredentials struct {
 	ClientId                   string `json:"clientId"`
-	ClientSecret               string `json:"clientSecret"`
+	ClientSecret               string `json:"clientSecret,omitempty"`
 	SubscriptionId             string `json:"subscriptionId"`
 	TenantId                   string `json:"tenantId"`
 	ResourceManagerEndpointUrl string `json:"resourceManagerEndpointUrl"`
@@ -76,14 +78,157 @@ func (cli *azCli) CreateOrUpdateServicePrincipal(
 		return nil, fmt.Errorf("failed applying role assignment: %w", err)
 	}
 
-	azureCreds := AzureCredentials{
+	return marshalCredentials(AzureCredentials{
 		ClientId:                   *application.AppId,
 		ClientSecret:               *credential.SecretText,
 		SubscriptionId:             subscriptionId,
 		TenantId:                   *servicePrincipal.AppOwnerOrganizationId,
 		ResourceManagerEndpointUrl: "https://management.azure.com/",
+	})
+}
+
+func (cli *azCli) CreateOrUpdateFederatedServicePrincipal(
+	ctx context.Context,
+	subscriptionId string,
+	applicationName string,
+	roleName string,
+) (json.RawMessage, error) {
+	graphClient, err := cli.createGraphClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	application, err := ensureApplication(ctx, graphClient, applicationName)
+	if err != nil {
+		return nil, err
+	}
+
+	servicePrincipal, err := ensureServicePrincipal(ctx, graphClient, application)
+	if err != nil {
+		return nil, err
+	}
+
+	// The pipeline signs in with its tokens, a client secret left by a previous configuration isn't needed anymore
+	for _, credential := range application.PasswordCredentials {
+		if err := graphClient.ApplicationById(*application.Id).RemovePassword(ctx, *credential.KeyId); err != nil {
+			return nil, fmt.Errorf("failed removing credentials for KeyId '%s' : %w", *credential.KeyId, err)
+		}
+	}
+
+	err = cli.ensureRoleAssignments(ctx, subscriptionId, roleName, servicePrincipal)
+	if err != nil {
+		return nil, fmt.Errorf("failed applying role assignment: %w", err)
 	}
 
+	return marshalCredentials(AzureCredentials{
+		ClientId:                   *application.AppId,
+		SubscriptionId:             subscriptionId,
+		TenantId:                   *servicePrincipal.AppOwnerOrganizationId,
+		ResourceManagerEndpointUrl: "https://management.azure.com/",
+	})
+}
+
+func (cli *azCli) CreateOrUpdateFederatedCredential(
+	ctx context.Context,
+	clientId string,
+	credential graphsdk.FederatedIdentityCredential,
+) error {
+	graphClient, err := cli.createGraphClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	application, err := getApplicationByClientId(ctx, graphClient, clientId)
+	if err != nil {
+		return err
+	}
+
+	existing, err := application.FederatedIdentityCredentials().Get(ctx)
+	if err != nil {
+		return fmt.Errorf("failed retrieving federated credentials of application '%s': %w", clientId, err)
+	}
+
+	// The name of a federated credential is unique within the application, an existing credential is updated in place
+	for _, item := range existing.Value {
+		if item.Name == credential.Name && item.Id != nil {
+			if err := application.FederatedIdentityCredentialById(*item.Id).Update(ctx, &credential); err != nil {
+				return fmt.Errorf("failed updating federated credential '%s': %w", credential.Name, err)
+			}
+
+			return nil
+		}
+	}
+
+	if _, err := application.FederatedIdentityCredentials().Post(ctx, &credential); err != nil {
+		return fmt.Errorf("failed creating federated credential '%s': %w", credential.Name, err)
+	}
+
+	return nil
+}
+
+func (cli *azCli) ListFederatedCredentials(
+	ctx context.Context,
+	clientId string,
+) ([]graphsdk.FederatedIdentityCredential, error) {
+	graphClient, err := cli.createGraphClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	application, err := getApplicationByClientId(ctx, graphClient, clientId)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := application.FederatedIdentityCredentials().Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving federated credentials of application '%s': %w", clientId, err)
+	}
+
+	return credentials.Value, nil
+}
+
+func (cli *azCli) DeleteFederatedCredential(ctx context.Context, clientId string, credentialId string) error {
+	graphClient, err := cli.createGraphClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	application, err := getApplicationByClientId(ctx, graphClient, clientId)
+	if err != nil {
+		return err
+	}
+
+	if err := application.FederatedIdentityCredentialById(credentialId).Delete(ctx); err != nil {
+		return fmt.Errorf("failed deleting federated credential '%s': %w", credentialId, err)
+	}
+
+	return nil
+}
+
+// Gets the request builder of the application with the specified client id, fails when the application doesn't exist
+func getApplicationByClientId(
+	ctx context.Context,
+	client *graphsdk.GraphClient,
+	clientId string,
+) (*graphsdk.ApplicationItemRequestBuilder, error) {
+	matchingItems, err := client.
+		Applications().
+		Filter(fmt.Sprintf("appId eq '%s'", clientId)).
+		Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving application '%s': %w", clientId, err)
+	}
+
+	if len(matchingItems.Value) == 0 {
+		return nil, fmt.Errorf("application with client id '%s' was not found", clientId)
+	}
+
+	return client.ApplicationById(*matchingItems.Value[0].Id), nil
+}
+
+// marshalCredentials returns the credentials in the `AZURE_CREDENTIALS` format
+func marshalCredentials(azureCreds AzureCredentials) (json.RawMessage, error) {
 	credentialsJson, err := json.Marshal(azureCreds)
 	if err != nil {
 		return nil, fmt.Errorf("failed marshalling Azure credentials to JSON: %w", err)
@@ -97,15 +242,212 @@ func (cli *azCli) CreateOrUpdateServicePrincipal(
 	return rawMessage, nil
 }
 
-// Gets or creates an application with the specified name
-func ensureApplication(
+func (cli *azCli) AssignManagedIdentityRole(
+	ctx context.Context,
+	subscriptionId string,
+	clientId string,
+	roleName string,
+) error {
+	graphClient, err := cli.createGraphClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The service principal of a managed identity is created with the identity, azd only looks it up
+	matchingItems, err := graphClient.
+		ServicePrincipals().
+		Filter(fmt.Sprintf("appId eq '%s'", clientId)).
+		Get(ctx)
+	if err != nil {
+		return fmt.Errorf("failed retrieving the service principal of managed identity '%s': %w", clientId, err)
+	}
+
+	if len(matchingItems.Value) == 0 {
+		return fmt.Errorf("managed identity with client id '%s' was not found", clientId)
+	}
+
+	err = cli.ensureRoleAssignments(ctx, subscriptionId, roleName, &matchingItems.Value[0])
+	if err != nil {
+		return fmt.Errorf("failed applying role assignment: %w", err)
+	}
+
+	return nil
+}
+
+// The tag of the applications of the service principals created for pipelines, their other tags describe the pipeline
+const pipelinePrincipalTag = "azd-pipeline"
+
+const (
+	principalProjectTagPrefix     = "azd-project:"
+	principalEnvironmentTagPrefix = "azd-env:"
+	principalRepositoryTagPrefix  = "azd-repo:"
+)
+
+// ServicePrincipalMetadata describes the pipeline signing in with a service principal, saved in the tags and notes of
+// the application of the principal
+type ServicePrincipalMetadata struct {
+	Project       string `json:"project"`
+	Environment   string `json:"environment"`
+	RepositoryUrl string `json:"repositoryUrl"`
+}
+
+// PipelineServicePrincipal is a service principal created for a pipeline, with the metadata of its application
+type PipelineServicePrincipal struct {
+	Name  string `json:"name"`
+	AppId string `json:"appId"`
+	ServicePrincipalMetadata
+}
+
+func (cli *azCli) UpdateServicePrincipalMetadata(
+	ctx context.Context,
+	applicationName string,
+	metadata ServicePrincipalMetadata,
+) error {
+	graphClient, err := cli.createGraphClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	application, err := getApplication(ctx, graphClient, applicationName)
+	if err != nil {
+		return err
+	}
+
+	if application == nil {
+		return fmt.Errorf("application '%s' was not found", applicationName)
+	}
+
+	// The tags added by the user are kept, the tags describing the pipeline are replaced
+	tags := []string{pipelinePrincipalTag}
+	for _, tag := range application.Tags {
+		if tag != pipelinePrincipalTag && !strings.HasPrefix(tag, "azd-") {
+			tags = append(tags, tag)
+		}
+	}
+	tags = append(tags,
+		principalProjectTagPrefix+metadata.Project,
+		principalEnvironmentTagPrefix+metadata.Environment,
+		principalRepositoryTagPrefix+metadata.RepositoryUrl,
+	)
+
+	notes := fmt.Sprintf(
+		"Created by azd pipeline config for the pipeline of %s, deploying the environment %s of the project %s.",
+		metadata.RepositoryUrl, metadata.Environment, metadata.Project)
+
+	err = graphClient.ApplicationById(*application.Id).Update(ctx, &graphsdk.ApplicationUpdateRequest{
+		Notes: &notes,
+		Tags:  tags,
+	})
+	if err != nil {
+		return fmt.Errorf("failed updating application '%s': %w", applicationName, err)
+	}
+
+	return nil
+}
+
+func (cli *azCli) ListPipelineServicePrincipals(ctx context.Context) ([]PipelineServicePrincipal, error) {
+	graphClient, err := cli.createGraphClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matchingItems, err := graphClient.
+		Applications().
+		Filter(fmt.Sprintf("tags/any(t:t eq '%s')", pipelinePrincipalTag)).
+		Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving application list, %w", err)
+	}
+
+	principals := make([]PipelineServicePrincipal, 0, len(matchingItems.Value))
+	for _, application := range matchingItems.Value {
+		principal := PipelineServicePrincipal{Name: application.DisplayName}
+		if application.AppId != nil {
+			principal.AppId = *application.AppId
+		}
+
+		for _, tag := range application.Tags {
+			switch {
+			case strings.HasPrefix(tag, principalProjectTagPrefix):
+				principal.Project = strings.TrimPrefix(tag, principalProjectTagPrefix)
+			case strings.HasPrefix(tag, principalEnvironmentTagPrefix):
+				principal.Environment = strings.TrimPrefix(tag, principalEnvironmentTagPrefix)
+			case strings.HasPrefix(tag, principalRepositoryTagPrefix):
+				principal.RepositoryUrl = strings.TrimPrefix(tag, principalRepositoryTagPrefix)
+			}
+		}
+
+		principals = append(principals, principal)
+	}
+
+	return principals, nil
+}
+
+func (cli *azCli) ServicePrincipalExists(ctx context.Context, applicationName string) (bool, error) {
+	graphClient, err := cli.createGraphClient(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	application, err := getApplication(ctx, graphClient, applicationName)
+	if err != nil {
+		return false, err
+	}
+
+	return application != nil, nil
+}
+
+func (cli *azCli) DeleteServicePrincipal(ctx context.Context, applicationName string) error {
+	graphClient, err := cli.createGraphClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	application, err := getApplication(ctx, graphClient, applicationName)
+	if err != nil {
+		return err
+	}
+
+	if application == nil {
+		return nil
+	}
+
+	// Deleting the application also deletes the service principal created from it
+	if err := graphClient.ApplicationById(*application.Id).Delete(ctx); err != nil {
+		return fmt.Errorf("failed deleting application '%s': %w", applicationName, err)
+	}
+
+	return nil
+}
+
+func (cli *azCli) DeleteServicePrincipalByClientId(ctx context.Context, clientId string) error {
+	graphClient, err := cli.createGraphClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	application, err := getApplicationByClientId(ctx, graphClient, clientId)
+	if err != nil {
+		return err
+	}
+
+	// Deleting the application also deletes the service principal created from it
+	if err := application.Delete(ctx); err != nil {
+		return fmt.Errorf("failed deleting application '%s': %w", clientId, err)
+	}
+
+	return nil
+}
+
+// Gets the application with the specified name, returns nil when the application doesn't exist
+func getApplication(
 	ctx context.Context,
 	client *graphsdk.GraphClient,
 	applicationName string,
 ) (*graphsdk.Application, error) {
 	matchingItems, err := client.
 		Applications().
-		Filter(fmt.Sprintf("startswith(displayName, '%s')", applicationName)).
+		Filter(fmt.Sprintf("displayName eq '%s'", applicationName)).
 		Get(ctx)
 
 	if err != nil {
@@ -116,8 +458,28 @@ func ensureApplication(
 		return nil, fmt.Errorf("more than 1 application with same name '%s'", applicationName)
 	}
 
-	if len(matchingItems.Value) == 1 {
-		return &matchingItems.Value[0], nil
+	if len(matchingItems.Value) == 0 {
+		return nil, nil
+	}
+
+	return &matchingItems.Value[0], nil
+}
+
+// Gets or creates an application with the specified name
+func ensureApplication(
+	ctx context.Context,
+	client *graphsdk.GraphClient,
+	applicationName string,
+) (*graphsdk.Application, error) {
+	// The name is matched exactly, the principals of the stages of a pipeline are named after the principal of the
+	// pipeline, i.e. azd-app-dev and azd-app-dev-prod
+	application, err := getApplication(ctx, client, applicationName)
+	if err != nil {
+		return nil, err
+	}
+
+	if application != nil {
+		return application, nil
 	}
 
 	// Existing application doesn't exist - create a new one
@@ -132,6 +494,12 @@ func ensureApplication(
 		return nil, fmt.Errorf("failed creating application '%s': %w", applicationName, err)
 	}
 
+	// The new application isn't immediately visible to the requests adding its credentials and service principal
+	newApp, err = graphsdk.WaitUntilVisible(ctx, client.ApplicationById(*newApp.Id).Get)
+	if err != nil {
+		return nil, fmt.Errorf("failed waiting for application '%s' to be available: %w", applicationName, err)
+	}
+
 	return newApp, nil
 }
 
@@ -170,6 +538,13 @@ func ensureServicePrincipal(
 		return nil, fmt.Errorf("failed creating service principal '%s': %w", application.DisplayName, err)
 	}
 
+	// The new service principal isn't immediately visible to the role assignments granting it access
+	newSpn, err = graphsdk.WaitUntilVisible(ctx, client.ServicePrincipalById(*newSpn.Id).Get)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed waiting for service principal '%s' to be available: %w", application.DisplayName, err)
+	}
+
 	return newSpn, nil
 }
 
@@ -205,6 +580,77 @@ func resetCredentials(
 	return credential, nil
 }
 
+func (cli *azCli) AssignRoleToSignedInUser(
+	ctx context.Context,
+	subscriptionId string,
+	scope string,
+	roleName string,
+) error {
+	userId, err := cli.GetSignedInUserId(ctx)
+	if err != nil {
+		return err
+	}
+
+	roleDefinition, err := cli.getRoleDefinition(ctx, scope, roleName)
+	if err != nil {
+		return err
+	}
+
+	roleAssignmentsClient, err := cli.createRoleAssignmentsClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	_, err = roleAssignmentsClient.Create(ctx, scope, uuid.New().String(), armauthorization.RoleAssignmentCreateParameters{
+		Properties: &armauthorization.RoleAssignmentProperties{
+			PrincipalID:      userId,
+			RoleDefinitionID: roleDefinition.ID,
+		},
+	}, nil)
+	if err != nil {
+		// If the response is a 409 conflict then the role has already been assigned.
+		var responseError *azcore.ResponseError
+		if errors.As(err, &responseError) && responseError.StatusCode == http.StatusConflict {
+			return nil
+		}
+
+		return fmt.Errorf("failed assigning role '%s' to the signed-in user: %w", roleName, err)
+	}
+
+	return nil
+}
+
+func (cli *azCli) AssignCosmosRoleToSignedInUser(ctx context.Context, accountId string, roleDefinitionId string) error {
+	userId, err := cli.GetSignedInUserId(ctx)
+	if err != nil {
+		return err
+	}
+
+	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
+	client, err := azsdk.NewCosmosRoleAssignmentClient(cli.credential, options)
+	if err != nil {
+		return fmt.Errorf("creating cosmos role assignment client: %w", err)
+	}
+
+	// An account has one assignment of a role to a principal, the id of the assignment is derived from both so
+	// assigning the role again replaces the assignment instead of conflicting with it
+	roleDefinition := fmt.Sprintf("%s/sqlRoleDefinitions/%s", accountId, roleDefinitionId)
+	assignmentId := uuid.NewSHA1(uuid.NameSpaceURL, []byte(strings.ToLower(roleDefinition+"/"+*userId))).String()
+
+	err = client.CreateOrUpdateSqlRoleAssignment(ctx, accountId, assignmentId, azsdk.CosmosSqlRoleAssignment{
+		Properties: azsdk.CosmosSqlRoleAssignmentProperties{
+			RoleDefinitionId: roleDefinition,
+			Scope:            accountId,
+			PrincipalId:      *userId,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed assigning cosmos role '%s' to the signed-in user: %w", roleDefinitionId, err)
+	}
+
+	return nil
+}
+
 // Applies the Azure selected RBAC role assignments to the specified service principal
 func (cli *azCli) ensureRoleAssignments(
 	ctx context.Context,
@@ -308,11 +754,12 @@ func (cli *azCli) getRoleDefinition(
 	return roleDefinitions[0], nil
 }
 
-// Creates a graph users client using credentials from the Go context.
+// Creates a graph users client using the credential of the signed-in account, like the other clients.
 func (cli *azCli) createGraphClient(ctx context.Context) (*graphsdk.GraphClient, error) {
-	cred := identity.GetCredentials(ctx)
-	options := cli.createDefaultClientOptionsBuilder(ctx).BuildCoreClientOptions()
-	client, err := graphsdk.NewGraphClient(cred, options)
+	options := cli.createDefaultClientOptionsBuilder(ctx).
+		WithClientPolicy(cli.clientPolicies[httputil.GraphClient]).
+		BuildCoreClientOptions()
+	client, err := graphsdk.NewGraphClient(cli.credential, options)
 	if err != nil {
 		return nil, fmt.Errorf("creating Graph Users client: %w", err)
 	}
@@ -320,11 +767,10 @@ func (cli *azCli) createGraphClient(ctx context.Context) (*graphsdk.GraphClient,
 	return client, nil
 }
 
-// Creates a graph users client using credentials from the Go context.
+// Creates a role definitions client using the credential of the signed-in account.
 func (cli *azCli) createRoleDefinitionsClient(ctx context.Context) (*armauthorization.RoleDefinitionsClient, error) {
-	cred := identity.GetCredentials(ctx)
 	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
-	client, err := armauthorization.NewRoleDefinitionsClient(cred, options)
+	client, err := armauthorization.NewRoleDefinitionsClient(cli.credential, options)
 	if err != nil {
 		return nil, fmt.Errorf("creating ARM Role Definitions client: %w", err)
 	}
@@ -332,14 +778,13 @@ func (cli *azCli) createRoleDefinitionsClient(ctx context.Context) (*armauthoriz
 	return client, nil
 }
 
-// Creates a graph users client using credentials from the Go context.
+// Creates a role assignments client using the credential of the signed-in account.
 func (cli *azCli) createRoleAssignmentsClient(
 	ctx context.Context,
 	subscriptionId string,
 ) (*armauthorization.RoleAssignmentsClient, error) {
-	cred := identity.GetCredentials(ctx)
 	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
-	client, err := armauthorization.NewRoleAssignmentsClient(subscriptionId, cred, options)
+	client, err := armauthorization.NewRoleAssignmentsClient(subscriptionId, cli.credential, options)
 	if err != nil {
 		return nil, fmt.Errorf("creating ARM Role Assignments client: %w", err)
 	}
@@ -96,6 +96,8 @@ func Test_CreateOrUpdateServicePrincipal(t *testing.T) {
 		graphsdk_mocks.RegisterServicePrincipalListMock(mockContext, http.StatusOK, []graphsdk.ServicePrincipal{})
 		graphsdk_mocks.RegisterApplicationCreateMock(mockContext, http.StatusCreated, &newApplication)
 		graphsdk_mocks.RegisterServicePrincipalCreateMock(mockContext, http.StatusCreated, &servicePrincipal)
+		graphsdk_mocks.RegisterApplicationItemMock(mockContext, http.StatusOK, *newApplication.Id, &newApplication)
+		graphsdk_mocks.RegisterServicePrincipalItemMock(mockContext, http.StatusOK, *servicePrincipal.Id, &servicePrincipal)
 		graphsdk_mocks.RegisterApplicationAddPasswordMock(mockContext, http.StatusOK, *newApplication.Id, credential)
 		graphsdk_mocks.RegisterRoleDefinitionListMock(mockContext, http.StatusOK, roleDefinitions)
 		graphsdk_mocks.RegisterRoleAssignmentMock(mockContext, http.StatusCreated)
@@ -174,6 +176,8 @@ func Test_CreateOrUpdateServicePrincipal(t *testing.T) {
 		graphsdk_mocks.RegisterServicePrincipalListMock(mockContext, http.StatusOK, []graphsdk.ServicePrincipal{})
 		graphsdk_mocks.RegisterApplicationCreateMock(mockContext, http.StatusCreated, &newApplication)
 		graphsdk_mocks.RegisterServicePrincipalCreateMock(mockContext, http.StatusCreated, &servicePrincipal)
+		graphsdk_mocks.RegisterApplicationItemMock(mockContext, http.StatusOK, *newApplication.Id, &newApplication)
+		graphsdk_mocks.RegisterServicePrincipalItemMock(mockContext, http.StatusOK, *servicePrincipal.Id, &servicePrincipal)
 		graphsdk_mocks.RegisterApplicationAddPasswordMock(mockContext, http.StatusOK, *newApplication.Id, credential)
 		// Note how retrieval of matching role assignments is empty
 		graphsdk_mocks.RegisterRoleDefinitionListMock(mockContext, http.StatusOK, []*armauthorization.RoleDefinition{})
@@ -208,6 +212,318 @@ func Test_CreateOrUpdateServicePrincipal(t *testing.T) {
 	})
 }
 
+func Test_AssignManagedIdentityRole(t *testing.T) {
+	identity := graphsdk.ServicePrincipal{
+		Id:          convert.RefOf("IDENTITY_ID"),
+		AppId:       "IDENTITY_CLIENT_ID",
+		DisplayName: "agent-identity",
+	}
+	roleDefinitions := []*armauthorization.RoleDefinition{
+		{
+			ID:   convert.RefOf("ROLE_ID"),
+			Name: convert.RefOf("Contributor"),
+			Type: convert.RefOf("ROLE_TYPE"),
+		},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterServicePrincipalListMock(mockContext, http.StatusOK, []graphsdk.ServicePrincipal{identity})
+		graphsdk_mocks.RegisterRoleDefinitionListMock(mockContext, http.StatusOK, roleDefinitions)
+		graphsdk_mocks.RegisterRoleAssignmentMock(mockContext, http.StatusCreated)
+
+		azCli := GetAzCli(*mockContext.Context)
+		err := azCli.AssignManagedIdentityRole(
+			*mockContext.Context, "SUBSCRIPTION_ID", identity.AppId, "Contributor")
+		require.NoError(t, err)
+	})
+
+	t.Run("IdentityNotFound", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterServicePrincipalListMock(mockContext, http.StatusOK, []graphsdk.ServicePrincipal{})
+
+		azCli := GetAzCli(*mockContext.Context)
+		err := azCli.AssignManagedIdentityRole(
+			*mockContext.Context, "SUBSCRIPTION_ID", identity.AppId, "Contributor")
+		require.ErrorContains(t, err, "managed identity with client id 'IDENTITY_CLIENT_ID' was not found")
+	})
+}
+
+func Test_AssignRoleToSignedInUser(t *testing.T) {
+	userProfile := graphsdk.UserProfile{Id: "USER_ID", DisplayName: "John Doe"}
+	roleDefinitions := []*armauthorization.RoleDefinition{
+		{
+			ID:   convert.RefOf("ROLE_ID"),
+			Name: convert.RefOf("AcrPush"),
+			Type: convert.RefOf("ROLE_TYPE"),
+		},
+	}
+	scope := "/subscriptions/SUBSCRIPTION_ID/resourceGroups/RESOURCE_GROUP" +
+		"/providers/Microsoft.ContainerRegistry/registries/myregistry"
+
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		registerGetMeGraphMock(mockContext, http.StatusOK, &userProfile)
+		graphsdk_mocks.RegisterRoleDefinitionListMock(mockContext, http.StatusOK, roleDefinitions)
+
+		var assignment armauthorization.RoleAssignmentCreateParameters
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPut &&
+				strings.HasPrefix(request.URL.Path, scope+"/providers/Microsoft.Authorization/roleAssignments/")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			if err := json.NewDecoder(request.Body).Decode(&assignment); err != nil {
+				return nil, err
+			}
+
+			return mocks.CreateHttpResponseWithBody(request, http.StatusCreated, armauthorization.RoleAssignment{
+				ID: convert.RefOf("ASSIGNMENT_ID"),
+			})
+		})
+
+		azCli := GetAzCli(*mockContext.Context)
+		err := azCli.AssignRoleToSignedInUser(*mockContext.Context, "SUBSCRIPTION_ID", scope, "AcrPush")
+		require.NoError(t, err)
+		require.Equal(t, "USER_ID", *assignment.Properties.PrincipalID)
+		require.Equal(t, "ROLE_ID", *assignment.Properties.RoleDefinitionID)
+	})
+
+	t.Run("AlreadyAssigned", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		registerGetMeGraphMock(mockContext, http.StatusOK, &userProfile)
+		graphsdk_mocks.RegisterRoleDefinitionListMock(mockContext, http.StatusOK, roleDefinitions)
+		graphsdk_mocks.RegisterRoleAssignmentMock(mockContext, http.StatusConflict)
+
+		azCli := GetAzCli(*mockContext.Context)
+		err := azCli.AssignRoleToSignedInUser(*mockContext.Context, "SUBSCRIPTION_ID", scope, "AcrPush")
+		require.NoError(t, err)
+	})
+
+	t.Run("RoleNotFound", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		registerGetMeGraphMock(mockContext, http.StatusOK, &userProfile)
+		graphsdk_mocks.RegisterRoleDefinitionListMock(mockContext, http.StatusOK, []*armauthorization.RoleDefinition{})
+
+		azCli := GetAzCli(*mockContext.Context)
+		err := azCli.AssignRoleToSignedInUser(*mockContext.Context, "SUBSCRIPTION_ID", scope, "AcrPush")
+		require.Error(t, err)
+	})
+}
+
+func Test_CreateOrUpdateFederatedServicePrincipal(t *testing.T) {
+	credential := &graphsdk.ApplicationPasswordCredential{
+		KeyId: convert.RefOf("KEY_ID"),
+	}
+	existingApplication := graphsdk.Application{
+		Id:                  convert.RefOf("UNIQUE_ID"),
+		AppId:               &expectedServicePrincipalCredential.ClientId,
+		DisplayName:         "MY_APP",
+		PasswordCredentials: []*graphsdk.ApplicationPasswordCredential{credential},
+	}
+	servicePrincipal := graphsdk.ServicePrincipal{
+		Id:                     convert.RefOf("SPN_ID"),
+		AppId:                  expectedServicePrincipalCredential.ClientId,
+		DisplayName:            "MY_APP",
+		AppOwnerOrganizationId: &expectedServicePrincipalCredential.TenantId,
+	}
+	roleDefinitions := []*armauthorization.RoleDefinition{
+		{
+			ID:   convert.RefOf("ROLE_ID"),
+			Name: convert.RefOf("Contributor"),
+			Type: convert.RefOf("ROLE_TYPE"),
+		},
+	}
+
+	mockContext := mocks.NewMockContext(context.Background())
+	graphsdk_mocks.RegisterApplicationListMock(mockContext, http.StatusOK, []graphsdk.Application{existingApplication})
+	graphsdk_mocks.RegisterServicePrincipalListMock(
+		mockContext,
+		http.StatusOK,
+		[]graphsdk.ServicePrincipal{servicePrincipal},
+	)
+	removedPassword := false
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/removePassword")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		removedPassword = true
+		return mocks.CreateEmptyHttpResponse(request, http.StatusNoContent)
+	})
+	graphsdk_mocks.RegisterRoleDefinitionListMock(mockContext, http.StatusOK, roleDefinitions)
+	graphsdk_mocks.RegisterRoleAssignmentMock(mockContext, http.StatusCreated)
+
+	azCli := GetAzCli(*mockContext.Context)
+	rawMessage, err := azCli.CreateOrUpdateFederatedServicePrincipal(
+		*mockContext.Context,
+		expectedServicePrincipalCredential.SubscriptionId,
+		"APPLICATION_NAME",
+		"Contributor",
+	)
+	require.NoError(t, err)
+	require.True(t, removedPassword)
+	require.NotContains(t, string(rawMessage), "clientSecret")
+
+	var actual AzureCredentials
+	require.NoError(t, json.Unmarshal(rawMessage, &actual))
+	require.Equal(t, expectedServicePrincipalCredential.ClientId, actual.ClientId)
+	require.Equal(t, expectedServicePrincipalCredential.TenantId, actual.TenantId)
+	require.Equal(t, expectedServicePrincipalCredential.SubscriptionId, actual.SubscriptionId)
+}
+
+func Test_CreateOrUpdateFederatedCredential(t *testing.T) {
+	application := graphsdk.Application{
+		Id:          convert.RefOf("UNIQUE_ID"),
+		AppId:       convert.RefOf("CLIENT_ID"),
+		DisplayName: "APPLICATION_NAME",
+	}
+	credential := graphsdk.FederatedIdentityCredential{
+		Name:      "main",
+		Issuer:    "https://token.actions.githubusercontent.com",
+		Subject:   "repo:owner/repo:ref:refs/heads/main",
+		Audiences: []string{"api://AzureADTokenExchange"},
+	}
+
+	t.Run("NewCredential", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterApplicationListMock(mockContext, http.StatusOK, []graphsdk.Application{application})
+		graphsdk_mocks.RegisterFederatedIdentityCredentialListMock(
+			mockContext, http.StatusOK, *application.Id, []graphsdk.FederatedIdentityCredential{})
+		graphsdk_mocks.RegisterFederatedIdentityCredentialCreateMock(
+			mockContext, http.StatusCreated, *application.Id, &credential)
+
+		azCli := GetAzCli(*mockContext.Context)
+		err := azCli.CreateOrUpdateFederatedCredential(*mockContext.Context, *application.AppId, credential)
+		require.NoError(t, err)
+	})
+
+	t.Run("ExistingCredential", func(t *testing.T) {
+		existing := credential
+		existing.Id = convert.RefOf("CREDENTIAL_ID")
+		existing.Subject = "repo:owner/repo:ref:refs/heads/dev"
+
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterApplicationListMock(mockContext, http.StatusOK, []graphsdk.Application{application})
+		graphsdk_mocks.RegisterFederatedIdentityCredentialListMock(
+			mockContext, http.StatusOK, *application.Id, []graphsdk.FederatedIdentityCredential{existing})
+		graphsdk_mocks.RegisterFederatedIdentityCredentialUpdateMock(
+			mockContext, http.StatusNoContent, *application.Id, *existing.Id)
+
+		azCli := GetAzCli(*mockContext.Context)
+		err := azCli.CreateOrUpdateFederatedCredential(*mockContext.Context, *application.AppId, credential)
+		require.NoError(t, err)
+	})
+
+	t.Run("ApplicationNotFound", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterApplicationListMock(mockContext, http.StatusOK, []graphsdk.Application{})
+
+		azCli := GetAzCli(*mockContext.Context)
+		err := azCli.CreateOrUpdateFederatedCredential(*mockContext.Context, *application.AppId, credential)
+		require.ErrorContains(t, err, "application with client id 'CLIENT_ID' was not found")
+	})
+}
+
+func Test_DeleteFederatedCredential(t *testing.T) {
+	application := graphsdk.Application{
+		Id:          convert.RefOf("UNIQUE_ID"),
+		AppId:       convert.RefOf("CLIENT_ID"),
+		DisplayName: "APPLICATION_NAME",
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterApplicationListMock(mockContext, http.StatusOK, []graphsdk.Application{application})
+		graphsdk_mocks.RegisterFederatedIdentityCredentialDeleteMock(
+			mockContext, http.StatusNoContent, *application.Id, "CREDENTIAL_ID")
+
+		azCli := GetAzCli(*mockContext.Context)
+		err := azCli.DeleteFederatedCredential(*mockContext.Context, *application.AppId, "CREDENTIAL_ID")
+		require.NoError(t, err)
+	})
+
+	t.Run("ErrorDeletingCredential", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterApplicationListMock(mockContext, http.StatusOK, []graphsdk.Application{application})
+		graphsdk_mocks.RegisterFederatedIdentityCredentialDeleteMock(
+			mockContext, http.StatusNotFound, *application.Id, "CREDENTIAL_ID")
+
+		azCli := GetAzCli(*mockContext.Context)
+		err := azCli.DeleteFederatedCredential(*mockContext.Context, *application.AppId, "CREDENTIAL_ID")
+		require.ErrorContains(t, err, "failed deleting federated credential 'CREDENTIAL_ID'")
+	})
+}
+
+func Test_DeleteServicePrincipal(t *testing.T) {
+	application := graphsdk.Application{
+		Id:          convert.RefOf("UNIQUE_ID"),
+		AppId:       &expectedServicePrincipalCredential.ClientId,
+		DisplayName: "APPLICATION_NAME",
+	}
+
+	t.Run("ExistingApplication", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterApplicationListMock(mockContext, http.StatusOK, []graphsdk.Application{application})
+		graphsdk_mocks.RegisterApplicationDeleteMock(mockContext, http.StatusNoContent, *application.Id)
+
+		azCli := GetAzCli(*mockContext.Context)
+		exists, err := azCli.ServicePrincipalExists(*mockContext.Context, "APPLICATION_NAME")
+		require.NoError(t, err)
+		require.True(t, exists)
+
+		err = azCli.DeleteServicePrincipal(*mockContext.Context, "APPLICATION_NAME")
+		require.NoError(t, err)
+	})
+
+	t.Run("NoApplication", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterApplicationListMock(mockContext, http.StatusOK, []graphsdk.Application{})
+
+		azCli := GetAzCli(*mockContext.Context)
+		exists, err := azCli.ServicePrincipalExists(*mockContext.Context, "APPLICATION_NAME")
+		require.NoError(t, err)
+		require.False(t, exists)
+
+		// Nothing to delete
+		err = azCli.DeleteServicePrincipal(*mockContext.Context, "APPLICATION_NAME")
+		require.NoError(t, err)
+	})
+
+	t.Run("ErrorDeletingApplication", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterApplicationListMock(mockContext, http.StatusOK, []graphsdk.Application{application})
+		graphsdk_mocks.RegisterApplicationDeleteMock(mockContext, http.StatusForbidden, *application.Id)
+
+		azCli := GetAzCli(*mockContext.Context)
+		err := azCli.DeleteServicePrincipal(*mockContext.Context, "APPLICATION_NAME")
+		require.Error(t, err)
+	})
+}
+
+func Test_DeleteServicePrincipalByClientId(t *testing.T) {
+	application := graphsdk.Application{
+		Id:          convert.RefOf("UNIQUE_ID"),
+		AppId:       convert.RefOf("CLIENT_ID"),
+		DisplayName: "APPLICATION_NAME",
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterApplicationListMock(mockContext, http.StatusOK, []graphsdk.Application{application})
+		graphsdk_mocks.RegisterApplicationDeleteMock(mockContext, http.StatusNoContent, *application.Id)
+
+		azCli := GetAzCli(*mockContext.Context)
+		err := azCli.DeleteServicePrincipalByClientId(*mockContext.Context, *application.AppId)
+		require.NoError(t, err)
+	})
+
+	t.Run("NoApplication", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterApplicationListMock(mockContext, http.StatusOK, []graphsdk.Application{})
+
+		azCli := GetAzCli(*mockContext.Context)
+		err := azCli.DeleteServicePrincipalByClientId(*mockContext.Context, *application.AppId)
+		require.ErrorContains(t, err, "application with client id 'CLIENT_ID' was not found")
+	})
+}
+
 func assertAzureCredentials(t *testing.T, message json.RawMessage) {
 	jsonBytes, err := message.MarshalJSON()
 	require.NoError(t, err)
@@ -229,3 +545,79 @@ func registerGetMeGraphMock(mockContext *mocks.MockContext, statusCode int, user
 		return mocks.CreateHttpResponseWithBody(request, statusCode, userProfile)
 	})
 }
+
+func Test_UpdateServicePrincipalMetadata(t *testing.T) {
+	application := graphsdk.Application{
+		Id:          convert.RefOf("UNIQUE_ID"),
+		AppId:       &expectedServicePrincipalCredential.ClientId,
+		DisplayName: "azd-app-dev",
+		Tags:        []string{"team:web", "azd-env:old"},
+	}
+	metadata := ServicePrincipalMetadata{
+		Project:       "app",
+		Environment:   "dev",
+		RepositoryUrl: "https://github.com/owner/repo",
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterApplicationListMock(mockContext, http.StatusOK, []graphsdk.Application{application})
+
+		var update graphsdk.ApplicationUpdateRequest
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPatch && strings.Contains(request.URL.Path, "/applications/UNIQUE_ID")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			if err := json.NewDecoder(request.Body).Decode(&update); err != nil {
+				return nil, err
+			}
+
+			return mocks.CreateEmptyHttpResponse(request, http.StatusNoContent)
+		})
+
+		azCli := GetAzCli(*mockContext.Context)
+		err := azCli.UpdateServicePrincipalMetadata(*mockContext.Context, "azd-app-dev", metadata)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"azd-pipeline", "team:web", "azd-project:app", "azd-env:dev", "azd-repo:https://github.com/owner/repo",
+		}, update.Tags)
+		require.Contains(t, *update.Notes, "https://github.com/owner/repo")
+	})
+
+	t.Run("ApplicationNotFound", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		graphsdk_mocks.RegisterApplicationListMock(mockContext, http.StatusOK, []graphsdk.Application{})
+
+		azCli := GetAzCli(*mockContext.Context)
+		err := azCli.UpdateServicePrincipalMetadata(*mockContext.Context, "azd-app-dev", metadata)
+		require.ErrorContains(t, err, "application 'azd-app-dev' was not found")
+	})
+}
+
+func Test_ListPipelineServicePrincipals(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	graphsdk_mocks.RegisterApplicationListMock(mockContext, http.StatusOK, []graphsdk.Application{
+		{
+			Id:          convert.RefOf("UNIQUE_ID"),
+			AppId:       convert.RefOf("CLIENT_ID"),
+			DisplayName: "azd-app-dev",
+			Tags: []string{
+				"azd-pipeline", "azd-project:app", "azd-env:dev", "azd-repo:https://github.com/owner/repo",
+			},
+		},
+	})
+
+	azCli := GetAzCli(*mockContext.Context)
+	principals, err := azCli.ListPipelineServicePrincipals(*mockContext.Context)
+	require.NoError(t, err)
+	require.Equal(t, []PipelineServicePrincipal{
+		{
+			Name:  "azd-app-dev",
+			AppId: "CLIENT_ID",
+			ServicePrincipalMetadata: ServicePrincipalMetadata{
+				Project:       "app",
+				Environment:   "dev",
+				RepositoryUrl: "https://github.com/owner/repo",
+			},
+		},
+	}, principals)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appconfiguration/armappconfiguration"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 )
 
@@ -70,6 +71,43 @@ func (cli *azCli) PurgeAppConfig(ctx context.Context, subscriptionId string, con
 	return nil
 }
 
+func (cli *azCli) SetAppConfigKeyValue(
+	ctx context.Context,
+	endpoint string,
+	key string,
+	label string,
+	value string,
+) error {
+	client, err := cli.createAppConfigDataClient(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+
+	if err := client.SetKeyValue(ctx, key, label, value); err != nil {
+		return fmt.Errorf("setting app configuration key '%s': %w", key, err)
+	}
+
+	return nil
+}
+
+func (cli *azCli) ListAppConfigKeyValues(
+	ctx context.Context,
+	endpoint string,
+	label string,
+) ([]azsdk.AppConfigKeyValue, error) {
+	client, err := cli.createAppConfigDataClient(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	keyValues, err := client.ListKeyValues(ctx, label)
+	if err != nil {
+		return nil, fmt.Errorf("listing app configuration keys: %w", err)
+	}
+
+	return keyValues, nil
+}
+
 // Creates a AppConfig client for ARM control plane operations
 func (cli *azCli) createAppConfigClient(
 	ctx context.Context,
@@ -83,3 +121,14 @@ func (cli *azCli) createAppConfigClient(
 
 	return appConfigStoresClient, nil
 }
+
+// Creates a AppConfig client for data plane operations, reading and writing the key-values of the store
+func (cli *azCli) createAppConfigDataClient(ctx context.Context, endpoint string) (*azsdk.AppConfigClient, error) {
+	options := cli.createDefaultClientOptionsBuilder(ctx).BuildCoreClientOptions()
+	client, err := azsdk.NewAppConfigClient(endpoint, cli.credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating App Configuration client: %w", err)
+	}
+
+	return client, nil
+}
@@ -21,10 +21,12 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
 	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 	"github.com/azure/azure-dev/cli/azd/pkg/identity"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/internal"
 	"github.com/blang/semver/v4"
 )
@@ -36,6 +38,8 @@ var (
 	ErrDeploymentNotFound       = errors.New("deployment not found")
 	ErrNoConfigurationValue     = errors.New("no value configured")
 	ErrAzCliSecretNotFound      = errors.New("secret not found")
+	ErrResourceGroupLocked      = errors.New("resource group is locked")
+	ErrResourceGroupNotFound    = errors.New("resource group not found")
 )
 
 const (
@@ -58,9 +62,15 @@ type AzCli interface {
 	// the interactive browser login flow happens. In the case of a device code login, the message is written to the
 	// `deviceCodeWriter`.
 	Login(ctx context.Context, useDeviceCode bool, deviceCodeWriter io.Writer) error
-	LoginAcr(ctx context.Context, subscriptionId string, loginServer string) error
+	GetContainerRegistryAdminCredentials(
+		ctx context.Context,
+		subscriptionId string,
+		loginServer string,
+	) (*docker.RegistryCredentials, error)
 	GetContainerRegistries(ctx context.Context, subscriptionId string) ([]*armcontainerregistry.Registry, error)
 	ListAccounts(ctx context.Context) ([]*AzCliSubscriptionInfo, error)
+	// ListTenants lists the tenants the signed-in account has access to.
+	ListTenants(ctx context.Context) ([]AzCliTenant, error)
 	GetDefaultAccount(ctx context.Context) (*AzCliSubscriptionInfo, error)
 	GetAccount(ctx context.Context, subscriptionId string) (*AzCliSubscriptionInfo, error)
 	GetCliConfigValue(ctx context.Context, name string) (AzCliConfigValue, error)
@@ -75,7 +85,11 @@ type AzCli interface {
 		resourceGroupName string,
 		deploymentName string,
 	) (*armresources.DeploymentExtended, error)
+	// ListSubscriptionDeployments lists the deployments at the scope of the subscription
+	ListSubscriptionDeployments(ctx context.Context, subscriptionId string) ([]*armresources.DeploymentExtended, error)
 	GetResource(ctx context.Context, subscriptionId string, resourceId string) (AzCliResourceExtended, error)
+	// GetResourceTypeLocations returns the display names of the locations where the resource type is available
+	GetResourceTypeLocations(ctx context.Context, subscriptionId string, resourceType string) ([]string, error)
 	GetKeyVault(
 		ctx context.Context,
 		subscriptionId string,
@@ -83,10 +97,47 @@ type AzCli interface {
 		vaultName string,
 	) (*AzCliKeyVault, error)
 	GetKeyVaultSecret(ctx context.Context, vaultName string, secretName string) (*AzCliKeyVaultSecret, error)
+	SetKeyVaultSecret(ctx context.Context, vaultName string, secretName string, value string) error
+	ListKeyVaultSecrets(ctx context.Context, vaultName string) ([]string, error)
 	PurgeKeyVault(ctx context.Context, subscriptionId string, vaultName string, location string) error
 	GetAppConfig(
 		ctx context.Context, subscriptionId string, resourceGroupName string, configName string) (*AzCliAppConfig, error)
 	PurgeAppConfig(ctx context.Context, subscriptionId string, configName string, location string) error
+	SetAppConfigKeyValue(ctx context.Context, endpoint string, key string, label string, value string) error
+	ListAppConfigKeyValues(ctx context.Context, endpoint string, label string) ([]azsdk.AppConfigKeyValue, error)
+	// CheckPolicyRestrictions evaluates the content of a resource against the policies assigned to the subscription
+	CheckPolicyRestrictions(
+		ctx context.Context,
+		subscriptionId string,
+		resourceContent any,
+		apiVersion string,
+	) ([]azsdk.PolicyEvaluation, error)
+	// ListManagementLocks returns the locks of the subscription, its resource groups and its resources
+	ListManagementLocks(ctx context.Context, subscriptionId string) ([]azsdk.ManagementLock, error)
+	DeleteManagementLock(ctx context.Context, subscriptionId string, lockId string) error
+	// CanDeleteManagementLocks returns true when the signed-in principal is allowed to delete the locks of the scope
+	CanDeleteManagementLocks(ctx context.Context, subscriptionId string, scope string) (bool, error)
+	// GetBudget returns the budget of the subscription with the name, or nil when the subscription has no such budget
+	GetBudget(ctx context.Context, subscriptionId string, name string) (*azsdk.Budget, error)
+	CreateOrUpdateBudget(
+		ctx context.Context, subscriptionId string, name string, budget azsdk.Budget) (*azsdk.Budget, error)
+	// PurgeEndpointContent purges the paths from the cache of the Front Door or CDN endpoint, by its resource id
+	PurgeEndpointContent(ctx context.Context, endpointId string, contentPaths []string) error
+	// GetAppServiceAppSettings returns the application settings of the App Service or Azure Functions application
+	GetAppServiceAppSettings(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroup string,
+		appName string,
+	) (map[string]string, error)
+	UpdateAppServiceAppSettings(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroup string,
+		appName string,
+		settings map[string]string,
+		removed []string,
+	) error
 	DeployAppServiceZip(
 		ctx context.Context,
 		subscriptionId string,
@@ -94,6 +145,24 @@ type AzCli interface {
 		appName string,
 		deployZipFile io.Reader,
 	) (*string, error)
+	// PublishAppServiceZip deploys the zip file to the App Service with OneDeploy. Unless clean is set, the files of
+	// the application missing from the zip file are kept.
+	PublishAppServiceZip(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroup string,
+		appName string,
+		deployZipFile io.Reader,
+		clean bool,
+	) (*string, error)
+	// GetAppServiceLatestDeploymentId returns the id of the latest deployment of the App Service, empty when the
+	// application was never deployed
+	GetAppServiceLatestDeploymentId(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroup string,
+		appName string,
+	) (string, error)
 	DeployFunctionAppUsingZipFile(
 		ctx context.Context,
 		subscriptionID string,
@@ -107,11 +176,18 @@ type AzCli interface {
 		resourceGroup string,
 		funcName string,
 	) (*AzCliFunctionAppProperties, error)
+	// DeployToSubscription deploys the template to the subscription, the deployment is created with the given tags
 	DeployToSubscription(
 		ctx context.Context, subscriptionId, deploymentName string,
 		armTemplate *azure.ArmTemplate,
-		parametersPath, location string) (
+		parametersPath, location string, tags map[string]*string) (
 		AzCliDeploymentResult, error)
+	// WhatIfDeployToSubscription returns the changes the deployment of the template to the subscription would make
+	WhatIfDeployToSubscription(
+		ctx context.Context, subscriptionId, deploymentName string,
+		armTemplate *azure.ArmTemplate,
+		parametersPath, location string) (
+		[]*armresources.WhatIfChange, error)
 	DeployToResourceGroup(
 		ctx context.Context,
 		subscriptionId,
@@ -122,6 +198,15 @@ type AzCli interface {
 	) (AzCliDeploymentResult, error)
 	DeleteSubscriptionDeployment(ctx context.Context, subscriptionId string, deploymentName string) error
 	DeleteResourceGroup(ctx context.Context, subscriptionId string, resourceGroupName string) error
+	// GetResourceGroupTags returns the tags of the resource group, ErrResourceGroupNotFound when it doesn't exist
+	GetResourceGroupTags(ctx context.Context, subscriptionId string, resourceGroupName string) (map[string]string, error)
+	// UpdateResourceGroupTags sets the tags of the resource group, keeping its other tags. A nil value removes the tag.
+	UpdateResourceGroupTags(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		tags map[string]*string,
+	) error
 	ListResourceGroup(
 		ctx context.Context,
 		subscriptionId string,
@@ -156,6 +241,42 @@ type AzCli interface {
 		applicationName string,
 		roleToAssign string,
 	) (json.RawMessage, error)
+	// CreateOrUpdateFederatedServicePrincipal is like CreateOrUpdateServicePrincipal, without a client secret: the
+	// credentials returned have no secret and the existing secrets of the principal are removed. The pipeline signs in
+	// with the tokens of its provider, trusted by the federated credentials of the principal.
+	CreateOrUpdateFederatedServicePrincipal(
+		ctx context.Context,
+		subscriptionId string,
+		applicationName string,
+		roleToAssign string,
+	) (json.RawMessage, error)
+	// CreateOrUpdateFederatedCredential adds the federated credential to the application with the given client id, or
+	// updates the credential of the application with the same name.
+	CreateOrUpdateFederatedCredential(
+		ctx context.Context,
+		clientId string,
+		credential graphsdk.FederatedIdentityCredential,
+	) error
+	// ListFederatedCredentials lists the federated credentials of the application with the given client id.
+	ListFederatedCredentials(ctx context.Context, clientId string) ([]graphsdk.FederatedIdentityCredential, error)
+	// DeleteFederatedCredential deletes the federated credential with the given id from the application with the given
+	// client id.
+	DeleteFederatedCredential(ctx context.Context, clientId string, credentialId string) error
+	// AssignManagedIdentityRole assigns a given role in the subscription to the managed identity with the given client
+	// id, i.e. the identity of a self-hosted pipeline agent. The identity has no credential to reset.
+	AssignManagedIdentityRole(ctx context.Context, subscriptionId string, clientId string, roleToAssign string) error
+	// ServicePrincipalExists returns true when an application with the given name exists.
+	ServicePrincipalExists(ctx context.Context, applicationName string) (bool, error)
+	// DeleteServicePrincipal deletes the application with the given name, along with its service principal.
+	DeleteServicePrincipal(ctx context.Context, applicationName string) error
+	// DeleteServicePrincipalByClientId deletes the application with the given client id, along with its service
+	// principal.
+	DeleteServicePrincipalByClientId(ctx context.Context, clientId string) error
+	// UpdateServicePrincipalMetadata tags the application with the given name as the principal of a pipeline, with
+	// the project, environment and repository of the pipeline, and describes them in the notes of the application.
+	UpdateServicePrincipalMetadata(ctx context.Context, applicationName string, metadata ServicePrincipalMetadata) error
+	// ListPipelineServicePrincipals lists the principals tagged as principals of pipelines, with their metadata.
+	ListPipelineServicePrincipals(ctx context.Context) ([]PipelineServicePrincipal, error)
 	GetAppServiceProperties(
 		ctx context.Context,
 		subscriptionId string,
@@ -168,6 +289,40 @@ type AzCli interface {
 		resourceGroupName string,
 		applicationName string,
 	) (*AzCliContainerAppProperties, error)
+	// SetContainerAppDaprComponent creates or updates a Dapr component of the Container Apps environment of an
+	// application
+	SetContainerAppDaprComponent(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		applicationName string,
+		component DaprComponent,
+	) error
+	// StreamAppServiceLogs streams the logs of an App Service or Azure Functions application
+	StreamAppServiceLogs(ctx context.Context, subscriptionId string, appName string) (io.ReadCloser, error)
+	// StreamContainerAppLogs streams the logs of a Container Apps application, starting with the previous tail lines
+	StreamContainerAppLogs(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroup string,
+		appName string,
+		tailLines int,
+	) (io.ReadCloser, error)
+	// OpenAppServiceSession opens an SSH session in the container of an App Service or Azure Functions application
+	OpenAppServiceSession(
+		ctx context.Context,
+		subscriptionId string,
+		appName string,
+		command string,
+	) (azsdk.RemoteSession, error)
+	// OpenContainerAppSession opens a session in a container of a Container Apps application
+	OpenContainerAppSession(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroup string,
+		appName string,
+		command string,
+	) (azsdk.RemoteSession, error)
 	GetStaticWebAppProperties(
 		ctx context.Context,
 		subscriptionID string,
@@ -185,6 +340,13 @@ type AzCli interface {
 
 	GetSignedInUserId(ctx context.Context) (*string, error)
 
+	// AssignRoleToSignedInUser assigns the built-in role to the signed-in user on the scope, i.e. to grant the data
+	// plane access a deployment requires. An existing assignment of the role isn't an error.
+	AssignRoleToSignedInUser(ctx context.Context, subscriptionId string, scope string, roleName string) error
+	// AssignCosmosRoleToSignedInUser assigns the SQL role definition of the Cosmos DB account to the signed-in user, the
+	// data plane roles of Cosmos DB aren't Azure RBAC roles. An existing assignment of the role isn't an error.
+	AssignCosmosRoleToSignedInUser(ctx context.Context, accountId string, roleDefinitionId string) error
+
 	GetAccessToken(ctx context.Context) (*AzCliAccessToken, error)
 }
 
@@ -323,6 +485,8 @@ type NewAzCliArgs struct {
 	// CommandRunner allows us to stub out the command execution for testing
 	CommandRunner exec.CommandRunner
 	HttpClient    httputil.HttpClient
+	// The timeout and retry policies of the clients configured by the user
+	ClientPolicies httputil.ClientPolicies
 }
 
 func NewAzCli(credential azcore.TokenCredential, args NewAzCliArgs) AzCli {
@@ -335,6 +499,7 @@ func NewAzCli(credential azcore.TokenCredential, args NewAzCliArgs) AzCli {
 		enableTelemetry: args.EnableTelemetry,
 		commandRunner:   args.CommandRunner,
 		httpClient:      args.HttpClient,
+		clientPolicies:  args.ClientPolicies,
 		credential:      credential,
 	}
 }
@@ -350,6 +515,9 @@ type azCli struct {
 	// Allows us to mock the Http Requests from the go modules
 	httpClient httputil.HttpClient
 
+	// The policies of the arm and graph clients configured by the user
+	clientPolicies httputil.ClientPolicies
+
 	credential azcore.TokenCredential
 }
 
@@ -518,7 +686,8 @@ func (cli *azCli) runAzCommandWithArgs(ctx context.Context, args exec.RunArgs) (
 func (cli *azCli) createDefaultClientOptionsBuilder(ctx context.Context) *azsdk.ClientOptionsBuilder {
 	return azsdk.NewClientOptionsBuilder().
 		WithTransport(httputil.GetHttpClient(ctx)).
-		WithPerCallPolicy(azsdk.NewUserAgentPolicy(cli.UserAgent()))
+		WithPerCallPolicy(azsdk.NewUserAgentPolicy(cli.UserAgent())).
+		WithClientPolicy(cli.clientPolicies[httputil.ArmClient])
 }
 
 // Azure Active Directory codes can be referenced via https://login.microsoftonline.com/error?code=<ERROR_CODE>,
@@ -128,6 +128,34 @@ func Test_DeployFunctionAppUsingZipFile(t *testing.T) {
 	})
 }
 
+func Test_DeployFunctionAppUsingZipFileAccessDenied(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azCli := GetAzCli(*mockContext.Context)
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.Contains(request.URL.Path, "/api/zipdeploy")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateEmptyHttpResponse(request, http.StatusForbidden)
+	})
+
+	res, err := azCli.DeployFunctionAppUsingZipFile(
+		*mockContext.Context,
+		"SUBSCRIPTION_ID",
+		"RESOURCE_GROUP",
+		"FUNC_APP_NAME",
+		bytes.NewBuffer([]byte{}),
+	)
+
+	require.Nil(t, res)
+	var accessErr *DataPlaneAccessError
+	require.ErrorAs(t, err, &accessErr)
+	require.Equal(t, WebsiteContributorRole, accessErr.RoleName)
+	require.Equal(t,
+		"/subscriptions/SUBSCRIPTION_ID/resourceGroups/RESOURCE_GROUP/providers/Microsoft.Web/sites/FUNC_APP_NAME",
+		accessErr.ResourceId)
+	require.Equal(t, "FUNC_APP_NAME", accessErr.ResourceName())
+}
+
 func registerConflictMocks(mockContext *mocks.MockContext, ran *bool) {
 	// Original call to start the deployment operation
 	mockContext.HttpClient.When(func(request *http.Request) bool {
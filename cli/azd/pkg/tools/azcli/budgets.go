@@ -0,0 +1,51 @@
+package azcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+)
+
+func (cli *azCli) GetBudget(ctx context.Context, subscriptionId string, name string) (*azsdk.Budget, error) {
+	client, err := cli.createBudgetClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	budget, err := client.GetBudget(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("getting budget '%s': %w", name, err)
+	}
+
+	return budget, nil
+}
+
+func (cli *azCli) CreateOrUpdateBudget(
+	ctx context.Context,
+	subscriptionId string,
+	name string,
+	budget azsdk.Budget,
+) (*azsdk.Budget, error) {
+	client, err := cli.createBudgetClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.CreateOrUpdateBudget(ctx, name, budget)
+	if err != nil {
+		return nil, fmt.Errorf("setting budget '%s': %w", name, err)
+	}
+
+	return result, nil
+}
+
+func (cli *azCli) createBudgetClient(ctx context.Context, subscriptionId string) (*azsdk.BudgetClient, error) {
+	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
+	client, err := azsdk.NewBudgetClient(subscriptionId, cli.credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating budget client: %w", err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,22 @@
+package azcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+)
+
+func (cli *azCli) PurgeEndpointContent(ctx context.Context, endpointId string, contentPaths []string) error {
+	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
+	client, err := azsdk.NewCachePurgeClient(cli.credential, options)
+	if err != nil {
+		return fmt.Errorf("creating cache purge client: %w", err)
+	}
+
+	if err := client.PurgeContent(ctx, endpointId, contentPaths); err != nil {
+		return fmt.Errorf("purging the cache of '%s': %w", endpointId, err)
+	}
+
+	return nil
+}
@@ -3,7 +3,10 @@ package azcli
 import (
 	"context"
 	"fmt"
+	"sort"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers"
 )
 
@@ -11,6 +14,18 @@ type AzCliContainerAppProperties struct {
 	HostNames []string
 }
 
+// DaprComponent is a Dapr component of a Container Apps environment
+type DaprComponent struct {
+	Name          string
+	ComponentType string
+	Version       string
+	Metadata      map[string]string
+	// Metadata stored as secrets of the component, the metadata references the secret of the same name
+	SecretMetadata map[string]string
+	// The Dapr app ids of the applications using the component, all applications of the environment when empty
+	Scopes []string
+}
+
 func (cli *azCli) GetContainerAppProperties(
 	ctx context.Context,
 	subscriptionId, resourceGroup, appName string,
@@ -42,3 +57,102 @@ func (cli *azCli) createContainerAppsClient(
 
 	return client, nil
 }
+
+// SetContainerAppDaprComponent creates or updates a Dapr component of the Container Apps environment of an application
+func (cli *azCli) SetContainerAppDaprComponent(
+	ctx context.Context,
+	subscriptionId, resourceGroup, appName string,
+	component DaprComponent,
+) error {
+	client, err := cli.createContainerAppsClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	containerApp, err := client.Get(ctx, resourceGroup, appName, nil)
+	if err != nil {
+		return fmt.Errorf("failed retrieving container app properties: %w", err)
+	}
+
+	if containerApp.Properties.ManagedEnvironmentID == nil {
+		return fmt.Errorf("container app %s has no environment", appName)
+	}
+
+	environmentId, err := arm.ParseResourceID(*containerApp.Properties.ManagedEnvironmentID)
+	if err != nil {
+		return fmt.Errorf("parsing the environment id of container app %s: %w", appName, err)
+	}
+
+	componentsClient, err := cli.createDaprComponentsClient(ctx, environmentId.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	_, err = componentsClient.CreateOrUpdate(
+		ctx,
+		environmentId.ResourceGroupName,
+		environmentId.Name,
+		component.Name,
+		armappcontainers.DaprComponent{Properties: daprComponentProperties(component)},
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("setting dapr component %s: %w", component.Name, err)
+	}
+
+	return nil
+}
+
+func daprComponentProperties(component DaprComponent) *armappcontainers.DaprComponentProperties {
+	properties := &armappcontainers.DaprComponentProperties{
+		ComponentType: to.Ptr(component.ComponentType),
+		Version:       to.Ptr(component.Version),
+		Metadata:      []*armappcontainers.DaprMetadata{},
+		Secrets:       []*armappcontainers.Secret{},
+		Scopes:        to.SliceOfPtrs(component.Scopes...),
+	}
+
+	// Sorted for the component to only change when its values do
+	for _, name := range sortedKeys(component.Metadata) {
+		properties.Metadata = append(properties.Metadata, &armappcontainers.DaprMetadata{
+			Name:  to.Ptr(name),
+			Value: to.Ptr(component.Metadata[name]),
+		})
+	}
+
+	for _, name := range sortedKeys(component.SecretMetadata) {
+		properties.Secrets = append(properties.Secrets, &armappcontainers.Secret{
+			Name:  to.Ptr(name),
+			Value: to.Ptr(component.SecretMetadata[name]),
+		})
+		properties.Metadata = append(properties.Metadata, &armappcontainers.DaprMetadata{
+			Name:      to.Ptr(name),
+			SecretRef: to.Ptr(name),
+		})
+	}
+
+	return properties
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func (cli *azCli) createDaprComponentsClient(
+	ctx context.Context,
+	subscriptionId string,
+) (*armappcontainers.DaprComponentsClient, error) {
+	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
+	client, err := armappcontainers.NewDaprComponentsClient(subscriptionId, cli.credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating Dapr Components client: %w", err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,34 @@
+package azcli
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDaprComponentProperties(t *testing.T) {
+	properties := daprComponentProperties(DaprComponent{
+		Name:           "pubsub",
+		ComponentType:  "pubsub.azure.servicebus",
+		Version:        "v1",
+		Metadata:       map[string]string{"namespaceName": "sb-test", "consumerID": "api"},
+		SecretMetadata: map[string]string{"connectionString": "Endpoint=sb://sb-test"},
+		Scopes:         []string{"api"},
+	})
+
+	require.Equal(t, &armappcontainers.DaprComponentProperties{
+		ComponentType: to.Ptr("pubsub.azure.servicebus"),
+		Version:       to.Ptr("v1"),
+		Metadata: []*armappcontainers.DaprMetadata{
+			{Name: to.Ptr("consumerID"), Value: to.Ptr("api")},
+			{Name: to.Ptr("namespaceName"), Value: to.Ptr("sb-test")},
+			{Name: to.Ptr("connectionString"), SecretRef: to.Ptr("connectionString")},
+		},
+		Secrets: []*armappcontainers.Secret{
+			{Name: to.Ptr("connectionString"), Value: to.Ptr("Endpoint=sb://sb-test")},
+		},
+		Scopes: []*string{to.Ptr("api")},
+	}, properties)
+}
@@ -35,10 +35,16 @@ func (cli *azCli) GetContainerRegistries(
 	return results, nil
 }
 
-func (cli *azCli) LoginAcr(ctx context.Context, subscriptionId string, loginServer string) error {
+// GetContainerRegistryAdminCredentials returns the admin user credentials of the registry.
+// The admin user must be enabled on the registry.
+func (cli *azCli) GetContainerRegistryAdminCredentials(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+) (*docker.RegistryCredentials, error) {
 	client, err := cli.createRegistriesClient(ctx, subscriptionId)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	parts := strings.Split(loginServer, ".")
@@ -47,25 +53,24 @@ func (cli *azCli) LoginAcr(ctx context.Context, subscriptionId string, loginServ
 	// Find the registry and resource group
 	_, resourceGroup, err := cli.findContainerRegistryByName(ctx, subscriptionId, registryName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Retrieve the registry credentials
 	credResponse, err := client.ListCredentials(ctx, resourceGroup, registryName, nil)
 	if err != nil {
-		return fmt.Errorf("getting container registry credentials: %w", err)
+		return nil, fmt.Errorf("getting container registry credentials: %w", err)
 	}
 
-	username := *credResponse.Username
-
-	// Login to docker with ACR credentials to allow push operations
-	dockerCli := docker.NewDocker(ctx)
-	err = dockerCli.Login(ctx, loginServer, username, *credResponse.Passwords[0].Value)
-	if err != nil {
-		return fmt.Errorf("failed logging into docker for username '%s' and server %s: %w", loginServer, username, err)
+	if credResponse.Username == nil || len(credResponse.Passwords) == 0 {
+		return nil, fmt.Errorf("the admin user of container registry '%s' is not enabled", registryName)
 	}
 
-	return nil
+	return &docker.RegistryCredentials{
+		LoginServer: loginServer,
+		Username:    *credResponse.Username,
+		Password:    *credResponse.Passwords[0].Value,
+	}, nil
 }
 
 func (cli *azCli) findContainerRegistryByName(
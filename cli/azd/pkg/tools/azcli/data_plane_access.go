@@ -0,0 +1,66 @@
+package azcli
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+)
+
+// The built-in roles granting the data plane access required to deploy services
+const (
+	// Grants deploying to the SCM site of App Service and Azure Functions applications
+	WebsiteContributorRole = "Website Contributor"
+	// Grants pushing images to a container registry
+	AcrPushRole = "AcrPush"
+)
+
+// DataPlaneAccessError is returned when the signed-in account is denied access to the data plane of a resource, such
+// as the SCM site of an App Service or a container registry, because it lacks a role granting the access. The role can
+// be assigned to the account with AssignRoleToSignedInUser.
+type DataPlaneAccessError struct {
+	// The id of the resource the account was denied access to, the scope the role is assigned at
+	ResourceId string
+	// The built-in role granting the access
+	RoleName string
+	Err      error
+}
+
+func (e *DataPlaneAccessError) Error() string {
+	return fmt.Sprintf(
+		"the signed-in account lacks the %s role on %s: %v\nAssign the role to your account with: "+
+			"az role assignment create --role \"%s\" --assignee <your account> --scope %s",
+		e.RoleName, e.ResourceName(), e.Err, e.RoleName, e.ResourceId)
+}
+
+func (e *DataPlaneAccessError) Unwrap() error {
+	return e.Err
+}
+
+// ResourceName returns the name of the resource the account was denied access to
+func (e *DataPlaneAccessError) ResourceName() string {
+	if resourceId, err := arm.ParseResourceID(e.ResourceId); err == nil {
+		return resourceId.Name
+	}
+
+	return e.ResourceId
+}
+
+// appServiceAccessError converts the 401 and 403 responses of the SCM site of the application to a DataPlaneAccessError,
+// other errors are returned as is
+func appServiceAccessError(subscriptionId string, resourceGroup string, appName string, err error) error {
+	var responseError *azcore.ResponseError
+	if !errors.As(err, &responseError) ||
+		(responseError.StatusCode != http.StatusUnauthorized && responseError.StatusCode != http.StatusForbidden) {
+		return err
+	}
+
+	return &DataPlaneAccessError{
+		ResourceId: azure.WebsiteRID(subscriptionId, resourceGroup, appName),
+		RoleName:   WebsiteContributorRole,
+		Err:        err,
+	}
+}
@@ -61,6 +61,29 @@ func (cli *azCli) GetResourceGroupDeployment(
 	return &deployment.DeploymentExtended, nil
 }
 
+func (cli *azCli) ListSubscriptionDeployments(
+	ctx context.Context,
+	subscriptionId string,
+) ([]*armresources.DeploymentExtended, error) {
+	deploymentClient, err := cli.createDeploymentsClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, fmt.Errorf("creating deployments client: %w", err)
+	}
+
+	result := []*armresources.DeploymentExtended{}
+	pager := deploymentClient.NewListAtSubscriptionScopePager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing deployments of subscription: %w", err)
+		}
+
+		result = append(result, page.Value...)
+	}
+
+	return result, nil
+}
+
 func (cli *azCli) createDeploymentsClient(
 	ctx context.Context,
 	subscriptionId string,
@@ -76,7 +99,7 @@ func (cli *azCli) createDeploymentsClient(
 
 func (cli *azCli) DeployToSubscription(
 	ctx context.Context, subscriptionId, deploymentName string,
-	armTemplate *azure.ArmTemplate, parametersFile, location string) (
+	armTemplate *azure.ArmTemplate, parametersFile, location string, tags map[string]*string) (
 	AzCliDeploymentResult, error) {
 	deploymentClient, err := cli.createDeploymentsClient(ctx, subscriptionId)
 	if err != nil {
@@ -101,6 +124,7 @@ func (cli *azCli) DeployToSubscription(
 				Mode:       to.Ptr(armresources.DeploymentModeIncremental),
 			},
 			Location: to.Ptr(location),
+			Tags:     tags,
 		}, nil)
 	if err != nil {
 		return AzCliDeploymentResult{}, fmt.Errorf("starting deployment to subscription: %w", err)
@@ -119,6 +143,50 @@ func (cli *azCli) DeployToSubscription(
 	}, nil
 }
 
+func (cli *azCli) WhatIfDeployToSubscription(
+	ctx context.Context, subscriptionId, deploymentName string,
+	armTemplate *azure.ArmTemplate, parametersFile, location string) (
+	[]*armresources.WhatIfChange, error) {
+	deploymentClient, err := cli.createDeploymentsClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, fmt.Errorf("creating deployments client: %w", err)
+	}
+
+	templateJsonAsMap, err := readFromString([]byte(*armTemplate))
+	if err != nil {
+		return nil, fmt.Errorf("reading template file: %w", err)
+	}
+	parametersFileJsonAsMap, err := readJson(parametersFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading parameters file: %w", err)
+	}
+
+	whatIfOperation, err := deploymentClient.BeginWhatIfAtSubscriptionScope(
+		ctx, deploymentName,
+		armresources.DeploymentWhatIf{
+			Properties: &armresources.DeploymentWhatIfProperties{
+				Template:   templateJsonAsMap,
+				Parameters: parametersFileJsonAsMap["parameters"],
+				Mode:       to.Ptr(armresources.DeploymentModeIncremental),
+			},
+			Location: to.Ptr(location),
+		}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("starting what-if deployment to subscription: %w", err)
+	}
+
+	whatIfResult, err := whatIfOperation.PollUntilDone(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("running what-if deployment to subscription: %w", err)
+	}
+
+	if whatIfResult.Properties == nil {
+		return []*armresources.WhatIfChange{}, nil
+	}
+
+	return whatIfResult.Properties.Changes, nil
+}
+
 func (cli *azCli) DeployToResourceGroup(
 	ctx context.Context, subscriptionId, resourceGroup, deploymentName string,
 	armTemplate *azure.ArmTemplate, parametersFile string) (
@@ -47,7 +47,7 @@ func (cli *azCli) DeployFunctionAppUsingZipFile(
 
 	response, err := client.Deploy(ctx, appName, deployZipFile)
 	if err != nil {
-		return nil, err
+		return nil, appServiceAccessError(subscriptionId, resourceGroup, appName, err)
 	}
 
 	return convert.RefOf(response.StatusText), nil
@@ -60,27 +60,19 @@ func (cli *azCli) GetKeyVault(
 }
 
 func (cli *azCli) GetKeyVaultSecret(ctx context.Context, vaultName string, secretName string) (*AzCliKeyVaultSecret, error) {
-	vaultUrl := vaultName
-	if !strings.Contains(strings.ToLower(vaultName), "https://") {
-		vaultUrl = fmt.Sprintf("https://%s.vault.azure.net", vaultName)
-	}
-
-	client, err := cli.createSecretsDataClient(ctx, vaultUrl)
+	client, err := cli.createSecretsDataClient(ctx, keyVaultUrl(vaultName))
 	if err != nil {
-		return nil, nil
+		return nil, err
 	}
 
 	response, err := client.GetSecret(ctx, secretName, "", nil)
 	if err != nil {
 		var httpErr *azcore.ResponseError
-
-		if errors.As(err, &httpErr) {
-			if httpErr.StatusCode == http.StatusNotFound {
-				return nil, ErrAzCliSecretNotFound
-			}
-		} else {
-			return nil, fmt.Errorf("getting key vault secret: %w", err)
+		if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+			return nil, ErrAzCliSecretNotFound
 		}
+
+		return nil, fmt.Errorf("getting key vault secret: %w", err)
 	}
 
 	return &AzCliKeyVaultSecret{
@@ -90,6 +82,44 @@ func (cli *azCli) GetKeyVaultSecret(ctx context.Context, vaultName string, secre
 	}, nil
 }
 
+func (cli *azCli) SetKeyVaultSecret(ctx context.Context, vaultName string, secretName string, value string) error {
+	client, err := cli.createSecretsDataClient(ctx, keyVaultUrl(vaultName))
+	if err != nil {
+		return err
+	}
+
+	_, err = client.SetSecret(ctx, secretName, azsecrets.SetSecretParameters{Value: &value}, nil)
+	if err != nil {
+		return fmt.Errorf("setting key vault secret: %w", err)
+	}
+
+	return nil
+}
+
+func (cli *azCli) ListKeyVaultSecrets(ctx context.Context, vaultName string) ([]string, error) {
+	client, err := cli.createSecretsDataClient(ctx, keyVaultUrl(vaultName))
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	pager := client.NewListSecretsPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing key vault secrets: %w", err)
+		}
+
+		for _, secret := range page.Value {
+			if secret.ID != nil {
+				names = append(names, secret.ID.Name())
+			}
+		}
+	}
+
+	return names, nil
+}
+
 func (cli *azCli) PurgeKeyVault(ctx context.Context, subscriptionId string, vaultName string, location string) error {
 	client, err := cli.createKeyVaultClient(ctx, subscriptionId)
 	if err != nil {
@@ -131,3 +161,12 @@ func (cli *azCli) createSecretsDataClient(ctx context.Context, vaultUrl string)
 
 	return azsecrets.NewClient(vaultUrl, cli.credential, options), nil
 }
+
+// keyVaultUrl returns the URL of the key vault, which can be specified by its name or its URL
+func keyVaultUrl(vaultName string) string {
+	if strings.Contains(strings.ToLower(vaultName), "https://") {
+		return vaultName
+	}
+
+	return fmt.Sprintf("https://%s.vault.azure.net", vaultName)
+}
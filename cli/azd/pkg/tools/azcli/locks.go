@@ -0,0 +1,62 @@
+package azcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+)
+
+func (cli *azCli) ListManagementLocks(ctx context.Context, subscriptionId string) ([]azsdk.ManagementLock, error) {
+	client, err := cli.createManagementLockClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	locks, err := client.ListLocks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing management locks: %w", err)
+	}
+
+	return locks, nil
+}
+
+func (cli *azCli) DeleteManagementLock(ctx context.Context, subscriptionId string, lockId string) error {
+	client, err := cli.createManagementLockClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	if err := client.DeleteLock(ctx, lockId); err != nil {
+		return fmt.Errorf("deleting management lock '%s': %w", lockId, err)
+	}
+
+	return nil
+}
+
+func (cli *azCli) CanDeleteManagementLocks(ctx context.Context, subscriptionId string, scope string) (bool, error) {
+	client, err := cli.createManagementLockClient(ctx, subscriptionId)
+	if err != nil {
+		return false, err
+	}
+
+	allowed, err := client.CanDeleteLocks(ctx, scope)
+	if err != nil {
+		return false, fmt.Errorf("getting permissions on '%s': %w", scope, err)
+	}
+
+	return allowed, nil
+}
+
+func (cli *azCli) createManagementLockClient(
+	ctx context.Context,
+	subscriptionId string,
+) (*azsdk.ManagementLockClient, error) {
+	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
+	client, err := azsdk.NewManagementLockClient(subscriptionId, cli.credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating management lock client: %w", err)
+	}
+
+	return client, nil
+}
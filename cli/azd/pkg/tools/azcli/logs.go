@@ -0,0 +1,57 @@
+package azcli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+)
+
+func (cli *azCli) StreamAppServiceLogs(
+	ctx context.Context,
+	subscriptionId string,
+	appName string,
+) (io.ReadCloser, error) {
+	client, err := cli.createLogStreamClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := client.StreamAppServiceLogs(ctx, appName)
+	if err != nil {
+		return nil, fmt.Errorf("streaming logs of app '%s': %w", appName, err)
+	}
+
+	return stream, nil
+}
+
+func (cli *azCli) StreamContainerAppLogs(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	tailLines int,
+) (io.ReadCloser, error) {
+	client, err := cli.createLogStreamClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := client.StreamContainerAppLogs(ctx, resourceGroup, appName, tailLines)
+	if err != nil {
+		return nil, fmt.Errorf("streaming logs of container app '%s': %w", appName, err)
+	}
+
+	return stream, nil
+}
+
+func (cli *azCli) createLogStreamClient(ctx context.Context, subscriptionId string) (*azsdk.LogStreamClient, error) {
+	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
+	client, err := azsdk.NewLogStreamClient(subscriptionId, cli.credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating log stream client: %w", err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,40 @@
+package azcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+)
+
+func (cli *azCli) CheckPolicyRestrictions(
+	ctx context.Context,
+	subscriptionId string,
+	resourceContent any,
+	apiVersion string,
+) ([]azsdk.PolicyEvaluation, error) {
+	client, err := cli.createPolicyInsightsClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluations, err := client.CheckPolicyRestrictions(ctx, resourceContent, apiVersion)
+	if err != nil {
+		return nil, fmt.Errorf("checking policy restrictions: %w", err)
+	}
+
+	return evaluations, nil
+}
+
+func (cli *azCli) createPolicyInsightsClient(
+	ctx context.Context,
+	subscriptionId string,
+) (*azsdk.PolicyInsightsClient, error) {
+	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
+	client, err := azsdk.NewPolicyInsightsClient(subscriptionId, cli.credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating policy insights client: %w", err)
+	}
+
+	return client, nil
+}
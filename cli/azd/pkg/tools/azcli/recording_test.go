@@ -0,0 +1,42 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/identity"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/recording"
+	"github.com/stretchr/testify/require"
+)
+
+// The subscription the recordings are replayed with, the subscription recorded from is replaced with it
+const recordedSubscriptionId = "00000000-0000-0000-0000-000000000000"
+
+// TestListResourceGroupRecorded replays the listing of the resource groups tagged with an environment name. To record
+// it again, run it with AZD_TEST_RECORD=1 and the AZURE_SUBSCRIPTION_ID of a subscription having a resource group
+// tagged with azd-env-name=azd-recording, signed in with the Azure CLI.
+func TestListResourceGroupRecorded(t *testing.T) {
+	subscriptionId := recordedSubscriptionId
+	if os.Getenv(recording.RecordModeEnvVar) != "" {
+		subscriptionId = os.Getenv("AZURE_SUBSCRIPTION_ID")
+	}
+
+	mockContext := mocks.NewMockContext(context.Background())
+	recording.Start(t, mockContext, filepath.Join("testdata", "recordings", t.Name()+".json"), map[string]string{
+		recordedSubscriptionId: subscriptionId,
+	})
+
+	azCli := NewAzCli(identity.GetCredentials(*mockContext.Context), NewAzCliArgs{CommandRunner: mockContext.CommandRunner})
+	groups, err := azCli.ListResourceGroup(*mockContext.Context, subscriptionId, &ListResourceGroupOptions{
+		TagFilter: &Filter{Key: "azd-env-name", Value: "azd-recording"},
+	})
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	require.Equal(t, "rg-azd-recording", groups[0].Name)
+}
@@ -0,0 +1,60 @@
+package azcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+)
+
+func (cli *azCli) OpenAppServiceSession(
+	ctx context.Context,
+	subscriptionId string,
+	appName string,
+	command string,
+) (azsdk.RemoteSession, error) {
+	client, err := cli.createRemoteSessionClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := client.OpenAppServiceSession(ctx, appName, command)
+	if err != nil {
+		return nil, fmt.Errorf("opening session in app '%s': %w", appName, err)
+	}
+
+	return session, nil
+}
+
+func (cli *azCli) OpenContainerAppSession(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	command string,
+) (azsdk.RemoteSession, error) {
+	client, err := cli.createRemoteSessionClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := client.OpenContainerAppSession(ctx, resourceGroup, appName, command)
+	if err != nil {
+		return nil, fmt.Errorf("opening session in container app '%s': %w", appName, err)
+	}
+
+	return session, nil
+}
+
+func (cli *azCli) createRemoteSessionClient(
+	ctx context.Context,
+	subscriptionId string,
+) (*azsdk.RemoteSessionClient, error) {
+	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
+	client, err := azsdk.NewRemoteSessionClient(subscriptionId, cli.credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating remote session client: %w", err)
+	}
+
+	return client, nil
+}
@@ -2,8 +2,12 @@ package azcli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 )
 
@@ -117,6 +121,72 @@ func (cli *azCli) ListResourceGroup(
 	return groups, nil
 }
 
+func (cli *azCli) GetResourceGroupTags(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+) (map[string]string, error) {
+	client, err := cli.createResourceGroupClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := client.Get(ctx, resourceGroupName, nil)
+	if err != nil {
+		var errDetails *azcore.ResponseError
+		if errors.As(err, &errDetails) && errDetails.StatusCode == http.StatusNotFound {
+			return nil, ErrResourceGroupNotFound
+		}
+		return nil, fmt.Errorf("getting resource group '%s': %w", resourceGroupName, err)
+	}
+
+	tags := map[string]string{}
+	for key, value := range group.Tags {
+		if value != nil {
+			tags[key] = *value
+		}
+	}
+
+	return tags, nil
+}
+
+func (cli *azCli) UpdateResourceGroupTags(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	tags map[string]*string,
+) error {
+	client, err := cli.createResourceGroupClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	group, err := client.Get(ctx, resourceGroupName, nil)
+	if err != nil {
+		return fmt.Errorf("getting resource group '%s': %w", resourceGroupName, err)
+	}
+
+	// The tags of the patch replace the tags of the resource group
+	updated := map[string]*string{}
+	for key, value := range group.Tags {
+		updated[key] = value
+	}
+	for key, value := range tags {
+		if value == nil {
+			delete(updated, key)
+		} else {
+			updated[key] = value
+		}
+	}
+
+	_, err = client.Update(ctx, resourceGroupName, armresources.ResourceGroupPatchable{Tags: updated}, nil)
+	if err != nil {
+		return fmt.Errorf("updating the tags of resource group '%s': %w", resourceGroupName, err)
+	}
+
+	return nil
+}
+
 func (cli *azCli) DeleteResourceGroup(ctx context.Context, subscriptionId string, resourceGroupName string) error {
 	client, err := cli.createResourceGroupClient(ctx, subscriptionId)
 	if err != nil {
@@ -124,18 +194,66 @@ func (cli *azCli) DeleteResourceGroup(ctx context.Context, subscriptionId string
 	}
 
 	poller, err := client.BeginDelete(ctx, resourceGroupName, nil)
-	if err != nil {
+	if isScopeLocked(err) {
+		return fmt.Errorf("%w: %s", ErrResourceGroupLocked, err)
+	} else if err != nil {
 		return fmt.Errorf("beginning resource group deletion: %w", err)
 	}
 
 	_, err = poller.PollUntilDone(ctx, nil)
-	if err != nil {
+	if isScopeLocked(err) {
+		return fmt.Errorf("%w: %s", ErrResourceGroupLocked, err)
+	} else if err != nil {
 		return fmt.Errorf("deleting resource group: %w", err)
 	}
 
 	return nil
 }
 
+// isScopeLocked returns true when the operation failed because of a lock on the resource or one of its parents
+func isScopeLocked(err error) bool {
+	var responseErr *azcore.ResponseError
+	return errors.As(err, &responseErr) && responseErr.ErrorCode == "ScopeLocked"
+}
+
+// GetResourceTypeLocations returns the display names of the locations where the resource type, i.e.
+// Microsoft.Web/sites, is available. An empty list means the resource type isn't bound to locations.
+func (cli *azCli) GetResourceTypeLocations(
+	ctx context.Context,
+	subscriptionId string,
+	resourceType string,
+) ([]string, error) {
+	namespace, typeName, found := strings.Cut(resourceType, "/")
+	if !found {
+		return nil, fmt.Errorf("invalid resource type '%s'", resourceType)
+	}
+
+	client, err := cli.createProvidersClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := client.Get(ctx, namespace, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting resource provider '%s': %w", namespace, err)
+	}
+
+	for _, providerType := range provider.ResourceTypes {
+		if providerType.ResourceType == nil || !strings.EqualFold(*providerType.ResourceType, typeName) {
+			continue
+		}
+
+		locations := make([]string, 0, len(providerType.Locations))
+		for _, location := range providerType.Locations {
+			locations = append(locations, *location)
+		}
+
+		return locations, nil
+	}
+
+	return nil, fmt.Errorf("resource type '%s' not found", resourceType)
+}
+
 func (cli *azCli) createResourcesClient(ctx context.Context, subscriptionId string) (*armresources.Client, error) {
 	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
 	client, err := armresources.NewClient(subscriptionId, cli.credential, options)
@@ -158,3 +276,16 @@ func (cli *azCli) createResourceGroupClient(
 
 	return client, nil
 }
+
+func (cli *azCli) createProvidersClient(
+	ctx context.Context,
+	subscriptionId string,
+) (*armresources.ProvidersClient, error) {
+	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
+	client, err := armresources.NewProvidersClient(subscriptionId, cli.credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating Providers client: %w", err)
+	}
+
+	return client, nil
+}
@@ -48,13 +48,125 @@ func (cli *azCli) DeployAppServiceZip(
 	}
 
 	response, err := client.Deploy(ctx, appName, deployZipFile)
+	if err != nil {
+		return nil, appServiceAccessError(subscriptionId, resourceGroup, appName, err)
+	}
+
+	return convert.RefOf(response.StatusText), nil
+}
+
+func (cli *azCli) PublishAppServiceZip(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	deployZipFile io.Reader,
+	clean bool,
+) (*string, error) {
+	client, err := cli.createZipDeployClient(ctx, subscriptionId)
 	if err != nil {
 		return nil, err
 	}
 
+	response, err := client.Publish(ctx, appName, deployZipFile, clean)
+	if err != nil {
+		return nil, appServiceAccessError(subscriptionId, resourceGroup, appName, err)
+	}
+
 	return convert.RefOf(response.StatusText), nil
 }
 
+func (cli *azCli) GetAppServiceLatestDeploymentId(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+) (string, error) {
+	client, err := cli.createZipDeployClient(ctx, subscriptionId)
+	if err != nil {
+		return "", err
+	}
+
+	status, err := client.GetLatestDeployment(ctx, appName)
+	if err != nil {
+		return "", fmt.Errorf("getting latest deployment: %w", err)
+	}
+
+	if status == nil {
+		return "", nil
+	}
+
+	return status.Id, nil
+}
+
+func (cli *azCli) GetAppServiceAppSettings(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+) (map[string]string, error) {
+	client, err := cli.createWebAppsClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := client.ListApplicationSettings(ctx, resourceGroup, appName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing application settings: %w", err)
+	}
+
+	settings := map[string]string{}
+	for name, value := range existing.Properties {
+		settings[name] = convert.ToValueWithDefault(value, "")
+	}
+
+	return settings, nil
+}
+
+// UpdateAppServiceAppSettings adds the settings to the application settings of the App Service or Azure Functions
+// application, replacing the settings with the same names, and deletes the removed settings. The other settings are
+// kept.
+func (cli *azCli) UpdateAppServiceAppSettings(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	settings map[string]string,
+	removed []string,
+) error {
+	client, err := cli.createWebAppsClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	existing, err := client.ListApplicationSettings(ctx, resourceGroup, appName, nil)
+	if err != nil {
+		return fmt.Errorf("listing application settings: %w", err)
+	}
+
+	properties := existing.Properties
+	if properties == nil {
+		properties = map[string]*string{}
+	}
+
+	for name, value := range settings {
+		properties[name] = convert.RefOf(value)
+	}
+
+	for _, name := range removed {
+		delete(properties, name)
+	}
+
+	_, err = client.UpdateApplicationSettings(ctx, resourceGroup, appName, armappservice.StringDictionary{
+		Properties: properties,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("updating application settings: %w", err)
+	}
+
+	return nil
+}
+
 func (cli *azCli) createWebAppsClient(ctx context.Context, subscriptionId string) (*armappservice.WebAppsClient, error) {
 	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
 	client, err := armappservice.NewWebAppsClient(subscriptionId, cli.credential, options)
@@ -10,6 +10,8 @@ import (
 	"log"
 	"regexp"
 
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/events"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
@@ -19,6 +21,9 @@ import (
 type BicepCli interface {
 	tools.ExternalTool
 	Build(ctx context.Context, file string) (string, error)
+	// BuildParams compiles a .bicepparam file into a deployment parameters file. env is set in the environment of the
+	// compilation, where the readEnvironmentVariable function of the file reads its values.
+	BuildParams(ctx context.Context, file string, env []string) (string, error)
 }
 
 func NewBicepCli(ctx context.Context) BicepCli {
@@ -93,6 +98,9 @@ func (cli *bicepCli) CheckInstalled(ctx context.Context) (bool, error) {
 }
 
 func (cli *bicepCli) Build(ctx context.Context, file string) (string, error) {
+	ctx, span := telemetry.GetTracer().Start(ctx, events.BicepBuildEventName)
+	defer span.End()
+
 	sniffCliVersion := func() (string, error) {
 		verRes, err := cli.runCommand(ctx, "version", "--out", "json")
 		if err != nil {
@@ -132,6 +140,31 @@ func (cli *bicepCli) Build(ctx context.Context, file string) (string, error) {
 	return buildRes.Stdout, nil
 }
 
+func (cli *bicepCli) BuildParams(ctx context.Context, file string, env []string) (string, error) {
+	ctx, span := telemetry.GetTracer().Start(ctx, events.BicepBuildEventName)
+	defer span.End()
+
+	runArgs := exec.NewRunArgs("az", "bicep", "build-params", "--file", file, "--stdout").WithEnv(env)
+	buildRes, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return "", fmt.Errorf(
+			"failed running az bicep build-params: %s (%w)",
+			buildRes.String(),
+			err,
+		)
+	}
+
+	// Recent versions of bicep print the compiled parameters file within an object, along with the template
+	var compiled struct {
+		ParametersJson *string `json:"parametersJson"`
+	}
+	if err := json.Unmarshal([]byte(buildRes.Stdout), &compiled); err == nil && compiled.ParametersJson != nil {
+		return *compiled.ParametersJson, nil
+	}
+
+	return buildRes.Stdout, nil
+}
+
 func (cli *bicepCli) runCommand(ctx context.Context, args ...string) (exec.RunResult, error) {
 	runArgs := exec.NewRunArgs("az", args...)
 	return cli.commandRunner.Run(ctx, runArgs)
@@ -0,0 +1,174 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package devtunnel
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// The time the tunnel is given to be ready, the CLI signs in and creates the tunnel first
+const hostTimeout = 30 * time.Second
+
+// The number of the last lines of the output of the CLI included in the errors
+const errorOutputLines = 10
+
+func NewDevTunnelCli() DevTunnelCli {
+	return &devTunnelCli{}
+}
+
+// DevTunnelCli hosts dev tunnels, exposing local ports on public URLs
+// https://learn.microsoft.com/azure/developer/dev-tunnels/overview
+type DevTunnelCli interface {
+	tools.ExternalTool
+
+	// Host hosts a temporary tunnel forwarding to the local port, until the context is canceled or the tunnel is
+	// closed. Anonymous clients, such as webhooks, can only connect when allowAnonymous is set.
+	Host(ctx context.Context, port int, allowAnonymous bool) (*Tunnel, error)
+}
+
+// Tunnel is a dev tunnel hosted by the CLI
+type Tunnel struct {
+	// The public URL forwarding to the local port, i.e. https://x1y2z3-8080.usw2.devtunnels.ms
+	Url string
+
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+// Close stops hosting the tunnel
+func (t *Tunnel) Close() {
+	select {
+	case <-t.done:
+	default:
+		// fails when the CLI already exited
+		_ = t.cmd.Process.Kill()
+		<-t.done
+	}
+}
+
+type devTunnelCli struct {
+}
+
+func (cli *devTunnelCli) Host(ctx context.Context, port int, allowAnonymous bool) (*Tunnel, error) {
+	args := []string{"host", "--port-numbers", strconv.Itoa(port)}
+	if allowAnonymous {
+		args = append(args, "--allow-anonymous")
+	}
+
+	cmd := exec.CommandContext(ctx, "devtunnel", args...)
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting devtunnel: %w", err)
+	}
+
+	tunnel := &Tunnel{cmd: cmd, done: make(chan struct{})}
+	urls := make(chan string, 1)
+	scanned := make(chan struct{})
+	var lastLines []string
+
+	go func() {
+		defer close(scanned)
+		scanner := bufio.NewScanner(reader)
+		found := false
+		for scanner.Scan() {
+			line := scanner.Text()
+			log.Printf("devtunnel: %s", line)
+
+			if !found {
+				lastLines = append(lastLines, line)
+				if len(lastLines) > errorOutputLines {
+					lastLines = lastLines[1:]
+				}
+
+				if url, ok := parseTunnelUrl(line, port); ok {
+					found = true
+					urls <- url
+				}
+			}
+		}
+	}()
+
+	exited := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		writer.Close()
+		exited <- err
+		close(tunnel.done)
+	}()
+
+	select {
+	case url := <-urls:
+		tunnel.Url = url
+		return tunnel, nil
+	case err := <-exited:
+		<-scanned
+		if err == nil {
+			err = errors.New("devtunnel exited")
+		}
+		return nil, fmt.Errorf(
+			"hosting dev tunnel for port %d: %s: %w", port, strings.Join(lastLines, "\n"), err)
+	case <-time.After(hostTimeout):
+		tunnel.Close()
+		return nil, fmt.Errorf("hosting dev tunnel for port %d: timed out waiting for the tunnel URL", port)
+	case <-ctx.Done():
+		tunnel.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// parseTunnelUrl returns the URL of the tunnel from the line of the output of the CLI listing its URLs, i.e.
+// "Connect via browser: https://x1y2z3.usw2.devtunnels.ms:8080, https://x1y2z3-8080.usw2.devtunnels.ms". The URL
+// naming the port in its host name is preferred, it doesn't need the port to be allowed by the callers.
+func parseTunnelUrl(line string, port int) (string, bool) {
+	const marker = "Connect via browser:"
+	i := strings.Index(line, marker)
+	if i < 0 {
+		return "", false
+	}
+
+	var urls []string
+	for _, field := range strings.Split(line[i+len(marker):], ",") {
+		if url := strings.TrimSpace(field); strings.HasPrefix(url, "https://") {
+			urls = append(urls, url)
+		}
+	}
+
+	if len(urls) == 0 {
+		return "", false
+	}
+
+	for _, url := range urls {
+		if strings.Contains(url, fmt.Sprintf("-%d.", port)) {
+			return url, true
+		}
+	}
+
+	return urls[0], true
+}
+
+func (cli *devTunnelCli) CheckInstalled(_ context.Context) (bool, error) {
+	return tools.ToolInPath("devtunnel")
+}
+
+func (cli *devTunnelCli) Name() string {
+	return "Dev tunnels CLI"
+}
+
+func (cli *devTunnelCli) InstallUrl() string {
+	return "https://learn.microsoft.com/azure/developer/dev-tunnels/get-started"
+}
@@ -0,0 +1,24 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package devtunnel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTunnelUrl(t *testing.T) {
+	url, ok := parseTunnelUrl(
+		"Connect via browser: https://x1y2z3.usw2.devtunnels.ms:8080, https://x1y2z3-8080.usw2.devtunnels.ms", 8080)
+	require.True(t, ok)
+	require.Equal(t, "https://x1y2z3-8080.usw2.devtunnels.ms", url)
+
+	url, ok = parseTunnelUrl("Connect via browser: https://x1y2z3.usw2.devtunnels.ms:8080", 8080)
+	require.True(t, ok)
+	require.Equal(t, "https://x1y2z3.usw2.devtunnels.ms:8080", url)
+
+	_, ok = parseTunnelUrl("Hosting port: 8080", 8080)
+	require.False(t, ok)
+}
@@ -2,12 +2,19 @@ package docker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/events"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/blang/semver/v4"
@@ -23,14 +30,26 @@ type Docker struct {
 	commandRunner exec.CommandRunner
 }
 
-func (d *Docker) Login(ctx context.Context, loginServer string, username string, password string) error {
-	_, err := d.executeCommand(ctx, ".", "login",
-		"--username", username,
-		"--password", password,
-		loginServer)
+// RegistryCredentials are the credentials docker logs into a container registry with
+type RegistryCredentials struct {
+	LoginServer string
+	Username    string
+	Password    string
+}
 
-	if err != nil {
-		return fmt.Errorf("failed logging into docker: %w", err)
+// login logs into the registry within the docker configuration directory of the environment
+func (d *Docker) login(ctx context.Context, env []string, credentials *RegistryCredentials) error {
+	runArgs := exec.NewRunArgs("docker", "login",
+		"--username", credentials.Username,
+		"--password-stdin",
+		credentials.LoginServer).
+		WithEnv(env).
+		WithEnrichError(true)
+	runArgs.Stdin = strings.NewReader(credentials.Password)
+
+	if _, err := d.commandRunner.Run(ctx, runArgs); err != nil {
+		return fmt.Errorf("failed logging into docker for username '%s' and server %s: %w",
+			credentials.Username, credentials.LoginServer, err)
 	}
 
 	return nil
@@ -47,6 +66,9 @@ func (d *Docker) Build(
 	platform string,
 	buildContext string,
 ) (string, error) {
+	ctx, span := telemetry.GetTracer().Start(ctx, events.DockerBuildEventName)
+	defer span.End()
+
 	if strings.TrimSpace(platform) == "" {
 		platform = "amd64"
 	}
@@ -68,8 +90,38 @@ func (d *Docker) Tag(ctx context.Context, cwd string, imageName string, tag stri
 	return nil
 }
 
-func (d *Docker) Push(ctx context.Context, cwd string, tag string) error {
-	res, err := d.executeCommand(ctx, cwd, "push", tag)
+// Push pushes the image to its registry. The push runs within a temporary docker configuration, logged into the
+// registry with the specified credentials or anonymously when no credentials are specified, so that it neither
+// depends on nor changes the registries the user is logged into. The temporary configuration keeps the current
+// context and the credential helpers of the user, so the image is pushed from the docker engine it was built with.
+func (d *Docker) Push(ctx context.Context, cwd string, tag string, credentials *RegistryCredentials) error {
+	ctx, span := telemetry.GetTracer().Start(ctx, events.DockerPushEventName)
+	defer span.End()
+
+	configDir, err := os.MkdirTemp("", "azd-docker-config")
+	if err != nil {
+		return fmt.Errorf("creating docker configuration: %w", err)
+	}
+	defer os.RemoveAll(configDir)
+
+	if err := initPushConfig(configDir); err != nil {
+		return fmt.Errorf("creating docker configuration: %w", err)
+	}
+
+	env := []string{fmt.Sprintf("DOCKER_CONFIG=%s", configDir)}
+
+	if credentials != nil {
+		if err := d.login(ctx, env, credentials); err != nil {
+			return err
+		}
+	}
+
+	runArgs := exec.NewRunArgs("docker", "push", tag).
+		WithCwd(cwd).
+		WithEnv(env).
+		WithEnrichError(true)
+
+	res, err := d.commandRunner.Run(ctx, runArgs)
 	if err != nil {
 		return fmt.Errorf("pushing image: %s: %w", res.String(), err)
 	}
@@ -77,6 +129,107 @@ func (d *Docker) Push(ctx context.Context, cwd string, tag string) error {
 	return nil
 }
 
+// The settings of the docker configuration of the user kept by the configuration of a push
+var pushConfigKeys = []string{"currentContext", "credHelpers"}
+
+// userConfigDir returns the docker configuration directory of the user
+func userConfigDir() (string, error) {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".docker"), nil
+}
+
+// initPushConfig writes the docker configuration of a push to configDir with the current context and the credential
+// helpers of the user configuration. The contexts are copied along, docker finds them in the configuration directory.
+func initPushConfig(configDir string) error {
+	userDir, err := userConfigDir()
+	if err != nil {
+		return err
+	}
+
+	contents, err := os.ReadFile(filepath.Join(userDir, "config.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	userConfig := map[string]json.RawMessage{}
+	if err := json.Unmarshal(contents, &userConfig); err != nil {
+		return fmt.Errorf("reading %s: %w", filepath.Join(userDir, "config.json"), err)
+	}
+
+	pushConfig := map[string]json.RawMessage{}
+	for _, key := range pushConfigKeys {
+		if value, has := userConfig[key]; has {
+			pushConfig[key] = value
+		}
+	}
+
+	if len(pushConfig) == 0 {
+		return nil
+	}
+
+	contents, err = json.Marshal(pushConfig)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), contents, 0600); err != nil {
+		return err
+	}
+
+	if _, has := pushConfig["currentContext"]; !has {
+		return nil
+	}
+
+	return copyDir(filepath.Join(userDir, "contexts"), filepath.Join(configDir, "contexts"))
+}
+
+// copyDir copies the files of the directory src to dst, keeping their permissions. Nothing is copied when src doesn't
+// exist.
+func copyDir(src string, dst string) error {
+	err := filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(target, contents, info.Mode().Perm())
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
 func (d *Docker) versionInfo() tools.VersionInfo {
 	return tools.VersionInfo{
 		MinimumVersion: semver.Version{
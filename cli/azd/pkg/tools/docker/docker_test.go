@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -214,6 +217,7 @@ func Test_DockerTag(t *testing.T) {
 func Test_DockerPush(t *testing.T) {
 	cwd := "."
 	tag := "customTag"
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
 
 	t.Run("NoError", func(t *testing.T) {
 		ran := false
@@ -233,6 +237,10 @@ func Test_DockerPush(t *testing.T) {
 				tag,
 			}, args.Args)
 
+			// The push doesn't use the docker configuration of the user
+			require.Len(t, args.Env, 1)
+			require.True(t, strings.HasPrefix(args.Env[0], "DOCKER_CONFIG="))
+
 			return exec.RunResult{
 				Stdout:   "Docker build output",
 				Stderr:   "",
@@ -240,7 +248,7 @@ func Test_DockerPush(t *testing.T) {
 			}, nil
 		})
 
-		err := docker.Push(context.Background(), cwd, tag)
+		err := docker.Push(context.Background(), cwd, tag, nil)
 
 		require.Equal(t, true, ran)
 		require.Nil(t, err)
@@ -273,7 +281,7 @@ func Test_DockerPush(t *testing.T) {
 			}, errors.New(customErrorMessage)
 		})
 
-		err := docker.Push(context.Background(), cwd, tag)
+		err := docker.Push(context.Background(), cwd, tag, nil)
 
 		require.Equal(t, true, ran)
 		require.NotNil(t, err)
@@ -285,11 +293,53 @@ func Test_DockerPush(t *testing.T) {
 	})
 }
 
+func Test_initPushConfig(t *testing.T) {
+	userDir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", userDir)
+
+	t.Run("NoUserConfig", func(t *testing.T) {
+		configDir := t.TempDir()
+		require.NoError(t, initPushConfig(configDir))
+		require.NoFileExists(t, filepath.Join(configDir, "config.json"))
+	})
+
+	t.Run("KeepsContextAndCredentialHelpers", func(t *testing.T) {
+		userConfig := `{
+			"auths": {"USER_REGISTRY": {"auth": "USER_AUTH"}},
+			"credsStore": "desktop",
+			"currentContext": "remote",
+			"credHelpers": {"LOGIN_SERVER": "acr"}
+		}`
+		require.NoError(t, os.WriteFile(filepath.Join(userDir, "config.json"), []byte(userConfig), 0600))
+		metaDir := filepath.Join(userDir, "contexts", "meta", "CONTEXT_ID")
+		require.NoError(t, os.MkdirAll(metaDir, 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(metaDir, "meta.json"), []byte(`{"Name":"remote"}`), 0600))
+
+		configDir := t.TempDir()
+		require.NoError(t, initPushConfig(configDir))
+
+		contents, err := os.ReadFile(filepath.Join(configDir, "config.json"))
+		require.NoError(t, err)
+		require.JSONEq(t, `{"currentContext": "remote", "credHelpers": {"LOGIN_SERVER": "acr"}}`, string(contents))
+
+		meta, err := os.ReadFile(filepath.Join(configDir, "contexts", "meta", "CONTEXT_ID", "meta.json"))
+		require.NoError(t, err)
+		require.Equal(t, `{"Name":"remote"}`, string(meta))
+	})
+}
+
 func Test_DockerLogin(t *testing.T) {
 	cwd := "."
+	tag := "LOGIN_SERVER/customTag"
+	credentials := &RegistryCredentials{
+		LoginServer: "LOGIN_SERVER",
+		Username:    "USERNAME",
+		Password:    "PASSWORD",
+	}
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
 
 	t.Run("NoError", func(t *testing.T) {
-		ran := false
+		loginEnv := []string{}
 
 		mockContext := mocks.NewMockContext(context.Background())
 		docker := NewDocker(*mockContext.Context)
@@ -297,32 +347,44 @@ func Test_DockerLogin(t *testing.T) {
 		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
 			return strings.Contains(command, "docker login")
 		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
-			ran = true
+			loginEnv = args.Env
 
 			require.Equal(t, "docker", args.Cmd)
-			require.Equal(t, cwd, args.Cwd)
 			require.Equal(t, []string{
 				"login",
 				"--username", "USERNAME",
-				"--password", "PASSWORD",
+				"--password-stdin",
 				"LOGIN_SERVER",
 			}, args.Args)
 
+			password, err := io.ReadAll(args.Stdin)
+			require.NoError(t, err)
+			require.Equal(t, "PASSWORD", string(password))
+
 			return exec.RunResult{
-				Stdout:   "Docker build output",
+				Stdout:   "Login Succeeded",
 				Stderr:   "",
 				ExitCode: 0,
 			}, nil
 		})
 
-		err := docker.Login(context.Background(), "LOGIN_SERVER", "USERNAME", "PASSWORD")
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker push")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			// The image is pushed with the docker configuration logged into
+			require.Equal(t, loginEnv, args.Env)
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+		err := docker.Push(context.Background(), cwd, tag, credentials)
 
-		require.Equal(t, true, ran)
 		require.Nil(t, err)
+		require.Len(t, loginEnv, 1)
 	})
 
 	t.Run("WithError", func(t *testing.T) {
 		ran := false
+		pushed := false
 		stdErr := "failed logging into docker"
 		customErrorMessage := "example error message"
 
@@ -334,15 +396,6 @@ func Test_DockerLogin(t *testing.T) {
 		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
 			ran = true
 
-			require.Equal(t, "docker", args.Cmd)
-			require.Equal(t, cwd, args.Cwd)
-			require.Equal(t, []string{
-				"login",
-				"--username", "USERNAME",
-				"--password", "PASSWORD",
-				"LOGIN_SERVER",
-			}, args.Args)
-
 			return exec.RunResult{
 				Stdout:   "",
 				Stderr:   stdErr,
@@ -350,11 +403,26 @@ func Test_DockerLogin(t *testing.T) {
 			}, errors.New(customErrorMessage)
 		})
 
-		err := docker.Login(context.Background(), "LOGIN_SERVER", "USERNAME", "PASSWORD")
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker push")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			pushed = true
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+		err := docker.Push(context.Background(), cwd, tag, credentials)
 
 		require.Equal(t, true, ran)
+		require.Equal(t, false, pushed)
 		require.NotNil(t, err)
-		require.Equal(t, fmt.Sprintf("%s: %s", stdErr, customErrorMessage), err.Error())
+		require.Equal(
+			t,
+			fmt.Sprintf(
+				"failed logging into docker for username 'USERNAME' and server LOGIN_SERVER: %s",
+				customErrorMessage,
+			),
+			err.Error(),
+		)
 	})
 }
 
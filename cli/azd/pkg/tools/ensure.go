@@ -6,6 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"log"
+
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/events"
 )
 
 // missingToolErrors wraps a set of errors discovered when
@@ -32,6 +35,9 @@ func (m *missingToolErrors) Error() string {
 // EnsureInstalled checks that all tools are installed, returning an
 // error if one or more tools are not.
 func EnsureInstalled(ctx context.Context, tools ...ExternalTool) error {
+	ctx, span := telemetry.GetTracer().Start(ctx, events.ToolsCheckEventName)
+	defer span.End()
+
 	var allErrors []error
 	errorsEncountered := map[string]struct{}{}
 
@@ -13,6 +13,7 @@ import (
 	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/blang/semver/v4"
 )
@@ -21,17 +22,39 @@ type GitCli interface {
 	tools.ExternalTool
 	GetRemoteUrl(ctx context.Context, string, remoteName string) (string, error)
 	FetchCode(ctx context.Context, repositoryPath string, branch string, target string) error
+	// FetchCommit fetches the code of a repository at the given commit, without its history
+	FetchCommit(ctx context.Context, repositoryPath string, commit string, target string) error
+	// GetRemoteCommit returns the commit the given branch or tag of a remote repository points to, or its default branch
+	// when no branch is given
+	GetRemoteCommit(ctx context.Context, repositoryPath string, branch string) (string, error)
+	// MergeFile merges the changes from base to other into current, returns the merged contents and whether they contain
+	// conflicts
+	MergeFile(ctx context.Context, current string, base string, other string) (string, bool, error)
 	InitRepo(ctx context.Context, repositoryPath string) error
 	AddRemote(ctx context.Context, repositoryPath string, remoteName string, remoteUrl string) error
 	UpdateRemote(ctx context.Context, repositoryPath string, remoteName string, remoteUrl string) error
 	GetCurrentBranch(ctx context.Context, repositoryPath string) (string, error)
+	// ListRemoteBranches returns the names of the branches of the remote of the repository
+	ListRemoteBranches(ctx context.Context, repositoryPath string, remoteName string) ([]string, error)
 	AddFile(ctx context.Context, repositoryPath string, filespec string) error
 	Commit(ctx context.Context, repositoryPath string, message string) error
 	PushUpstream(ctx context.Context, repositoryPath string, origin string, branch string) error
 	IsUntrackedFile(ctx context.Context, repositoryPath string, filePath string) (bool, error)
+	// ListTrackedFiles returns the paths of the files of the repository matching the pathspecs which are tracked or
+	// staged to be committed
+	ListTrackedFiles(ctx context.Context, repositoryPath string, pathspecs []string) ([]string, error)
 	SetCredentialStore(ctx context.Context, repositoryPath string) error
 }
 
+// ErrAuthenticationFailed is returned when git fails to authenticate to a remote, git is run without prompting for
+// credentials so the failure can be surfaced through the prompts of azd
+var ErrAuthenticationFailed = errors.New("git failed to authenticate to the remote")
+
+// The errors git reports when it has no valid credentials for a remote and may not prompt for them
+var gitAuthenticationFailureRegex = regexp.MustCompile(
+	`(?i)(authentication failed|terminal prompts disabled|could not read (username|password)|` +
+		`invalid username or password|permission denied \(publickey\))`)
+
 type gitCli struct {
 	commandRunner exec.CommandRunner
 }
@@ -95,7 +118,7 @@ func (cli *gitCli) FetchCode(ctx context.Context, repositoryPath string, branch
 	args = append(args, target)
 
 	runArgs := exec.NewRunArgs("git", args...)
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	res, err := cli.run(ctx, runArgs)
 	if err != nil {
 		return fmt.Errorf("failed to clone repository %s, %s: %w", repositoryPath, res.String(), err)
 	}
@@ -107,6 +130,75 @@ func (cli *gitCli) FetchCode(ctx context.Context, repositoryPath string, branch
 	return nil
 }
 
+func (cli *gitCli) FetchCommit(ctx context.Context, repositoryPath string, commit string, target string) error {
+	if err := os.MkdirAll(target, osutil.PermissionDirectory); err != nil {
+		return fmt.Errorf("creating directory %s: %w", target, err)
+	}
+
+	for _, args := range [][]string{
+		{"-C", target, "init", "--quiet"},
+		{"-C", target, "fetch", "--quiet", "--depth", "1", repositoryPath, commit},
+		{"-C", target, "checkout", "--quiet", "FETCH_HEAD"},
+	} {
+		res, err := cli.run(ctx, exec.NewRunArgs("git", args...))
+		if err != nil {
+			return fmt.Errorf("failed to fetch commit %s of repository %s, %s: %w", commit, repositoryPath, res.String(), err)
+		}
+	}
+
+	if err := os.RemoveAll(filepath.Join(target, ".git")); err != nil {
+		return fmt.Errorf("removing .git folder after fetch: %w", err)
+	}
+
+	return nil
+}
+
+func (cli *gitCli) GetRemoteCommit(ctx context.Context, repositoryPath string, branch string) (string, error) {
+	ref := branch
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	res, err := cli.run(ctx, exec.NewRunArgs("git", "ls-remote", repositoryPath, ref))
+	if err != nil {
+		return "", fmt.Errorf("failed to get the commit of %s in repository %s, %s: %w", ref, repositoryPath, res.String(), err)
+	}
+
+	var commit string
+	for _, line := range strings.Split(strings.TrimSpace(res.Stdout), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		// Annotated tags are listed with the commit they point to, suffixed by ^{}
+		if strings.HasSuffix(fields[1], "^{}") {
+			return fields[0], nil
+		}
+
+		if commit == "" {
+			commit = fields[0]
+		}
+	}
+
+	if commit == "" {
+		return "", fmt.Errorf("%s not found in repository %s", ref, repositoryPath)
+	}
+
+	return commit, nil
+}
+
+func (cli *gitCli) MergeFile(ctx context.Context, current string, base string, other string) (string, bool, error) {
+	res, err := cli.run(ctx, exec.NewRunArgs("git", "merge-file", "-p", current, base, other))
+
+	// The exit code is the number of conflicts when the merge succeeds
+	if err != nil && (res.ExitCode <= 0 || res.ExitCode > 127) {
+		return "", false, fmt.Errorf("failed to merge %s: %s: %w", current, res.String(), err)
+	}
+
+	return res.Stdout, res.ExitCode > 0, nil
+}
+
 var noSuchRemoteRegex = regexp.MustCompile("(fatal|error): No such remote")
 var notGitRepositoryRegex = regexp.MustCompile("(fatal|error): not a git repository")
 var ErrNoSuchRemote = errors.New("no such remote")
@@ -115,7 +207,7 @@ var gitUntrackedFileRegex = regexp.MustCompile("untracked files present|new file
 
 func (cli *gitCli) GetRemoteUrl(ctx context.Context, repositoryPath string, remoteName string) (string, error) {
 	runArgs := exec.NewRunArgs("git", "-C", repositoryPath, "remote", "get-url", remoteName)
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	res, err := cli.run(ctx, runArgs)
 	if noSuchRemoteRegex.MatchString(res.Stderr) {
 		return "", ErrNoSuchRemote
 	} else if notGitRepositoryRegex.MatchString(res.Stderr) {
@@ -129,7 +221,7 @@ func (cli *gitCli) GetRemoteUrl(ctx context.Context, repositoryPath string, remo
 
 func (cli *gitCli) GetCurrentBranch(ctx context.Context, repositoryPath string) (string, error) {
 	runArgs := exec.NewRunArgs("git", "-C", repositoryPath, "branch", "--show-current")
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	res, err := cli.run(ctx, runArgs)
 	if notGitRepositoryRegex.MatchString(res.Stderr) {
 		return "", ErrNotRepository
 	} else if err != nil {
@@ -139,16 +231,36 @@ func (cli *gitCli) GetCurrentBranch(ctx context.Context, repositoryPath string)
 	return strings.TrimSpace(res.Stdout), nil
 }
 
+func (cli *gitCli) ListRemoteBranches(ctx context.Context, repositoryPath string, remoteName string) ([]string, error) {
+	runArgs := exec.NewRunArgs("git", "-C", repositoryPath, "ls-remote", "--heads", remoteName)
+	res, err := cli.run(ctx, runArgs)
+	if notGitRepositoryRegex.MatchString(res.Stderr) {
+		return nil, ErrNotRepository
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to list the branches of remote %s: %s: %w", remoteName, res.String(), err)
+	}
+
+	branches := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(res.Stdout), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.HasPrefix(fields[1], "refs/heads/") {
+			branches = append(branches, strings.TrimPrefix(fields[1], "refs/heads/"))
+		}
+	}
+
+	return branches, nil
+}
+
 func (cli *gitCli) InitRepo(ctx context.Context, repositoryPath string) error {
 	runArgs := exec.NewRunArgs("git", "-C", repositoryPath, "init")
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	res, err := cli.run(ctx, runArgs)
 	if err != nil {
 		return fmt.Errorf("failed to init repository: %s: %w", res.String(), err)
 	}
 
 	// Set initial branch to main
 	runArgs = exec.NewRunArgs("git", "-C", repositoryPath, "checkout", "-b", "main")
-	res, err = cli.commandRunner.Run(ctx, runArgs)
+	res, err = cli.run(ctx, runArgs)
 	if err != nil {
 		return fmt.Errorf("failed to create main branch: %s: %w", res.String(), err)
 	}
@@ -158,7 +270,7 @@ func (cli *gitCli) InitRepo(ctx context.Context, repositoryPath string) error {
 
 func (cli *gitCli) SetCredentialStore(ctx context.Context, repositoryPath string) error {
 	runArgs := exec.NewRunArgs("git", "-C", repositoryPath, "config", "credential.helper", "store")
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	res, err := cli.run(ctx, runArgs)
 	if err != nil {
 		return fmt.Errorf("failed to set credential store repository: %s: %w", res.String(), err)
 	}
@@ -168,7 +280,7 @@ func (cli *gitCli) SetCredentialStore(ctx context.Context, repositoryPath string
 
 func (cli *gitCli) AddRemote(ctx context.Context, repositoryPath string, remoteName string, remoteUrl string) error {
 	runArgs := exec.NewRunArgs("git", "-C", repositoryPath, "remote", "add", remoteName, remoteUrl)
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	res, err := cli.run(ctx, runArgs)
 	if err != nil {
 		return fmt.Errorf("failed to add remote: %s: %w", res.String(), err)
 	}
@@ -178,7 +290,7 @@ func (cli *gitCli) AddRemote(ctx context.Context, repositoryPath string, remoteN
 
 func (cli *gitCli) UpdateRemote(ctx context.Context, repositoryPath string, remoteName string, remoteUrl string) error {
 	runArgs := exec.NewRunArgs("git", "-C", repositoryPath, "remote", "set-url", remoteName, remoteUrl)
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	res, err := cli.run(ctx, runArgs)
 	if err != nil {
 		return fmt.Errorf("failed to add remote: %s: %w", res.String(), err)
 	}
@@ -188,7 +300,7 @@ func (cli *gitCli) UpdateRemote(ctx context.Context, repositoryPath string, remo
 
 func (cli *gitCli) AddFile(ctx context.Context, repositoryPath string, filespec string) error {
 	runArgs := exec.NewRunArgs("git", "-C", repositoryPath, "add", filespec)
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	res, err := cli.run(ctx, runArgs)
 	if err != nil {
 		return fmt.Errorf("failed to add files: %s: %w", res.String(), err)
 	}
@@ -198,7 +310,7 @@ func (cli *gitCli) AddFile(ctx context.Context, repositoryPath string, filespec
 
 func (cli *gitCli) Commit(ctx context.Context, repositoryPath string, message string) error {
 	runArgs := exec.NewRunArgs("git", "-C", repositoryPath, "commit", "--allow-empty", "-m", message)
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	res, err := cli.run(ctx, runArgs)
 	if err != nil {
 		return fmt.Errorf("failed to commit: %s: %w", res.String(), err)
 	}
@@ -207,11 +319,9 @@ func (cli *gitCli) Commit(ctx context.Context, repositoryPath string, message st
 }
 
 func (cli *gitCli) PushUpstream(ctx context.Context, repositoryPath string, origin string, branch string) error {
-	runArgs := exec.
-		NewRunArgs("git", "-C", repositoryPath, "push", "--set-upstream", origin, branch).
-		WithInteractive(true)
+	runArgs := exec.NewRunArgs("git", "-C", repositoryPath, "push", "--set-upstream", origin, branch)
 
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	res, err := cli.run(ctx, runArgs)
 
 	if err != nil {
 		return fmt.Errorf("failed to push: %s: %w", res.String(), err)
@@ -222,7 +332,7 @@ func (cli *gitCli) PushUpstream(ctx context.Context, repositoryPath string, orig
 
 func (cli *gitCli) IsUntrackedFile(ctx context.Context, repositoryPath string, filePath string) (bool, error) {
 	runArgs := exec.NewRunArgs("git", "-C", repositoryPath, "status", filePath)
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	res, err := cli.run(ctx, runArgs)
 	if err != nil {
 		return false, fmt.Errorf("failed to check status file: %s: %w", res.String(), err)
 	}
@@ -233,3 +343,35 @@ func (cli *gitCli) IsUntrackedFile(ctx context.Context, repositoryPath string, f
 
 	return false, nil
 }
+
+func (cli *gitCli) ListTrackedFiles(ctx context.Context, repositoryPath string, pathspecs []string) ([]string, error) {
+	args := append([]string{"-C", repositoryPath, "ls-files", "--cached", "--"}, pathspecs...)
+	res, err := cli.run(ctx, exec.NewRunArgs("git", args...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracked files: %s: %w", res.String(), err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(res.Stdout, "\n") {
+		if file := strings.TrimSpace(line); file != "" {
+			files = append(files, file)
+		}
+	}
+
+	return files, nil
+}
+
+// run runs a git command with long paths enabled, since the temp folders used by the pipeline providers exceed the path
+// length limit of Windows, and without prompting for credentials, since the credential managers of Windows open GUI
+// dialogs. Authentication failures are returned as ErrAuthenticationFailed.
+func (cli *gitCli) run(ctx context.Context, runArgs exec.RunArgs) (exec.RunResult, error) {
+	runArgs.Args = append([]string{"-c", "core.longpaths=true"}, runArgs.Args...)
+	runArgs.Env = append(runArgs.Env, "GIT_TERMINAL_PROMPT=0", "GCM_INTERACTIVE=never")
+
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil && gitAuthenticationFailureRegex.MatchString(res.Stderr) {
+		return res, fmt.Errorf("%w: %v", ErrAuthenticationFailed, err)
+	}
+
+	return res, err
+}
@@ -0,0 +1,85 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package git
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitCliRun(t *testing.T) {
+	t.Run("LongPathsWithoutPrompts", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+
+		var runArgs exec.RunArgs
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "branch --show-current")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			runArgs = args
+			return exec.NewRunResult(0, "main\n", ""), nil
+		})
+
+		branch, err := NewGitCli(*mockContext.Context).GetCurrentBranch(*mockContext.Context, "repo")
+		require.NoError(t, err)
+		require.Equal(t, "main", branch)
+
+		require.Equal(t, []string{"-c", "core.longpaths=true", "-C", "repo", "branch", "--show-current"}, runArgs.Args)
+		require.Contains(t, runArgs.Env, "GIT_TERMINAL_PROMPT=0")
+		require.Contains(t, runArgs.Env, "GCM_INTERACTIVE=never")
+	})
+
+	t.Run("AuthenticationFailure", func(t *testing.T) {
+		tests := []string{
+			"fatal: could not read Username for 'https://github.com': terminal prompts disabled",
+			"remote: Invalid username or password.\nfatal: Authentication failed for 'https://github.com/o/r.git/'",
+			"git@github.com: Permission denied (publickey).",
+		}
+
+		for _, stderr := range tests {
+			mockContext := mocks.NewMockContext(context.Background())
+			mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+				return strings.Contains(command, " push ")
+			}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+				return exec.NewRunResult(128, "", stderr), errors.New("exit status 128")
+			})
+
+			err := NewGitCli(*mockContext.Context).PushUpstream(*mockContext.Context, "repo", "origin", "main")
+			require.ErrorIs(t, err, ErrAuthenticationFailed, stderr)
+		}
+	})
+
+	t.Run("OtherFailure", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, " push ")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			return exec.NewRunResult(1, "", "error: failed to push some refs"), errors.New("exit status 1")
+		})
+
+		err := NewGitCli(*mockContext.Context).PushUpstream(*mockContext.Context, "repo", "origin", "main")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrAuthenticationFailed)
+	})
+
+	t.Run("ListRemoteBranches", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "ls-remote --heads origin")
+		}).Respond(exec.NewRunResult(
+			0,
+			"abc123\trefs/heads/main\ndef456\trefs/heads/feature/login\n",
+			"",
+		))
+
+		branches, err := NewGitCli(*mockContext.Context).ListRemoteBranches(*mockContext.Context, "repo", "origin")
+		require.NoError(t, err)
+		require.Equal(t, []string{"main", "feature/login"}, branches)
+	})
+}
@@ -0,0 +1,199 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package git
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+)
+
+// The name of the file listing the files git ignores
+const GitIgnoreFileName = ".gitignore"
+
+// The names of the files holding the local settings and secrets of an application, which must not be committed
+var secretFileNames = []string{".env", "local.settings.json"}
+
+// The folders skipped when looking for secret files, they hold dependencies rather than the files of the project
+var secretFileSkippedDirs = []string{".git", azdcontext.EnvironmentDirectoryName, "node_modules", ".venv", "bin", "obj"}
+
+// MissingIgnoreEntries returns the entries missing from the .gitignore of the project, which are the environment
+// directory of azd and the names of the secret files found in the project. The first return value reports whether the
+// environment directory is missing, since azd adds it without confirmation.
+func MissingIgnoreEntries(projectPath string) (bool, []string, error) {
+	patterns, err := readIgnorePatterns(filepath.Join(projectPath, GitIgnoreFileName))
+	if err != nil {
+		return false, nil, err
+	}
+
+	missingEnvDir := !ignores(patterns, azdcontext.EnvironmentDirectoryName)
+
+	found, err := findSecretFiles(projectPath)
+	if err != nil {
+		return false, nil, err
+	}
+
+	var missing []string
+	for _, name := range secretFileNames {
+		if found[name] && !ignores(patterns, name) {
+			missing = append(missing, name)
+		}
+	}
+
+	return missingEnvDir, missing, nil
+}
+
+// AddIgnoreEntries appends the entries to the .gitignore of the project, creating it when it doesn't exist
+func AddIgnoreEntries(projectPath string, entries []string) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	file, err := os.OpenFile(
+		filepath.Join(projectPath, GitIgnoreFileName), os.O_APPEND|os.O_WRONLY|os.O_CREATE, osutil.PermissionFile)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", GitIgnoreFileName, err)
+	}
+	defer file.Close()
+
+	newLine := osutil.GetNewLineSeparator()
+	if _, err := file.WriteString(newLine + strings.Join(entries, newLine) + newLine); err != nil {
+		return fmt.Errorf("writing %s: %w", GitIgnoreFileName, err)
+	}
+
+	return nil
+}
+
+// EnsureIgnoreEntries adds the entries missing from the .gitignore of the project and returns them. The environment
+// directory is added directly, the secret files are added when confirm, called with their names, returns true.
+func EnsureIgnoreEntries(projectPath string, confirm func(entries []string) (bool, error)) ([]string, error) {
+	missingEnvDir, missing, err := MissingIgnoreEntries(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var added []string
+	if missingEnvDir {
+		added = append(added, azdcontext.EnvironmentDirectoryName)
+	}
+
+	if len(missing) > 0 {
+		confirmed, err := confirm(missing)
+		if err != nil {
+			return nil, err
+		}
+
+		if confirmed {
+			added = append(added, missing...)
+		}
+	}
+
+	if err := AddIgnoreEntries(projectPath, added); err != nil {
+		return nil, err
+	}
+
+	return added, nil
+}
+
+// TrackedSecretFiles returns the environment files and secret files tracked by the repository, including the files
+// staged to be committed
+func TrackedSecretFiles(ctx context.Context, cli GitCli, repositoryPath string) ([]string, error) {
+	pathspecs := []string{azdcontext.EnvironmentDirectoryName}
+	for _, name := range secretFileNames {
+		pathspecs = append(pathspecs, ":(glob)**/"+name)
+	}
+
+	return cli.ListTrackedFiles(ctx, repositoryPath, pathspecs)
+}
+
+// readIgnorePatterns returns the patterns of the .gitignore file, without the comments, negations and blank lines.
+// A missing file has no patterns.
+func readIgnorePatterns(gitignorePath string) ([]string, error) {
+	file, err := os.Open(gitignorePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", GitIgnoreFileName, err)
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "**/")
+		line = strings.TrimPrefix(line, "/")
+		line = strings.TrimSuffix(line, "/")
+		patterns = append(patterns, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", GitIgnoreFileName, err)
+	}
+
+	return patterns, nil
+}
+
+// ignores returns true when one of the patterns matches the name
+func ignores(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findSecretFiles returns the names of the secret files found in the project
+func findSecretFiles(projectPath string) (map[string]bool, error) {
+	found := map[string]bool{}
+	err := filepath.WalkDir(projectPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if filePath != projectPath && containsName(secretFileSkippedDirs, entry.Name()) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if containsName(secretFileNames, entry.Name()) {
+			found[entry.Name()] = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("looking for secret files: %w", err)
+	}
+
+	return found, nil
+}
+
+func containsName(names []string, name string) bool {
+	for _, candidate := range names {
+		if candidate == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,70 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissingIgnoreEntries(t *testing.T) {
+	projectDir := t.TempDir()
+	writeFile := func(name string, contents string) {
+		path := filepath.Join(projectDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(path, []byte(contents), osutil.PermissionFile))
+	}
+
+	missingEnvDir, missing, err := MissingIgnoreEntries(projectDir)
+	require.NoError(t, err)
+	require.True(t, missingEnvDir)
+	require.Empty(t, missing)
+
+	writeFile("src/api/.env", "")
+	writeFile("src/func/local.settings.json", "")
+	writeFile("node_modules/pkg/.env", "")
+
+	missingEnvDir, missing, err = MissingIgnoreEntries(projectDir)
+	require.NoError(t, err)
+	require.True(t, missingEnvDir)
+	require.Equal(t, []string{".env", "local.settings.json"}, missing)
+
+	writeFile(".gitignore", "# azd\n/.azure/\n**/.env\n")
+
+	missingEnvDir, missing, err = MissingIgnoreEntries(projectDir)
+	require.NoError(t, err)
+	require.False(t, missingEnvDir)
+	require.Equal(t, []string{"local.settings.json"}, missing)
+}
+
+func TestEnsureIgnoreEntries(t *testing.T) {
+	projectDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".env"), nil, osutil.PermissionFile))
+
+	t.Run("Declined", func(t *testing.T) {
+		added, err := EnsureIgnoreEntries(projectDir, func(entries []string) (bool, error) {
+			require.Equal(t, []string{".env"}, entries)
+			return false, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{".azure"}, added)
+	})
+
+	t.Run("Confirmed", func(t *testing.T) {
+		added, err := EnsureIgnoreEntries(projectDir, func(entries []string) (bool, error) {
+			return true, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{".env"}, added)
+
+		missingEnvDir, missing, err := MissingIgnoreEntries(projectDir)
+		require.NoError(t, err)
+		require.False(t, missingEnvDir)
+		require.Empty(t, missing)
+	})
+}
@@ -8,10 +8,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/blang/semver/v4"
 )
@@ -20,6 +23,10 @@ type GitHubCli interface {
 	tools.ExternalTool
 	CheckAuth(ctx context.Context, hostname string) (bool, error)
 	SetSecret(ctx context.Context, repo string, name string, value string) error
+	CreateEnvironment(ctx context.Context, repo string, name string) error
+	SetEnvironmentSecret(ctx context.Context, repo string, environment string, name string, value string) error
+	SetVariable(ctx context.Context, repo string, name string, value string) error
+	SetEnvironmentVariable(ctx context.Context, repo string, environment string, name string, value string) error
 	Login(ctx context.Context, hostname string) error
 	ListRepositories(ctx context.Context) ([]GhCliRepository, error)
 	ViewRepository(ctx context.Context, name string) (GhCliRepository, error)
@@ -31,6 +38,7 @@ type GitHubCli interface {
 func NewGitHubCli(ctx context.Context) GitHubCli {
 	return &ghCli{
 		commandRunner: exec.GetCommandRunner(ctx),
+		timeout:       httputil.GetClientPolicy(ctx, httputil.GitHubClient).Timeout,
 	}
 }
 
@@ -43,6 +51,27 @@ var (
 
 type ghCli struct {
 	commandRunner exec.CommandRunner
+	// The time allowed for a gh command sending requests to GitHub, no limit when zero
+	timeout time.Duration
+}
+
+// run runs a gh command, canceling it when it takes longer than the configured timeout. Interactive commands wait for
+// the user and are never canceled.
+func (cli *ghCli) run(ctx context.Context, runArgs exec.RunArgs) (exec.RunResult, error) {
+	if cli.timeout > 0 && !runArgs.Interactive {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cli.timeout)
+		defer cancel()
+	}
+
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return res, fmt.Errorf(
+			"gh didn't complete within %s, the timeout is configured with 'azd config set http.clients.%s.timeout': %w",
+			cli.timeout, httputil.GitHubClient, err)
+	}
+
+	return res, err
 }
 
 func (cli *ghCli) versionInfo() tools.VersionInfo {
@@ -86,7 +115,7 @@ func (cli *ghCli) InstallUrl() string {
 
 func (cli *ghCli) CheckAuth(ctx context.Context, hostname string) (bool, error) {
 	runArgs := exec.NewRunArgs("gh", "auth", "status", "--hostname", hostname)
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	res, err := cli.run(ctx, runArgs)
 	if res.ExitCode == 0 {
 		return true, nil
 	} else if isGhCliNotLoggedInMessageRegex.MatchString(res.Stderr) {
@@ -105,7 +134,7 @@ func (cli *ghCli) Login(ctx context.Context, hostname string) error {
 		NewRunArgs("gh", "auth", "login", "--hostname", hostname).
 		WithInteractive(true)
 
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	res, err := cli.run(ctx, runArgs)
 
 	if err != nil {
 		return fmt.Errorf("failed running gh auth login %s: %w", res.String(), err)
@@ -116,7 +145,38 @@ func (cli *ghCli) Login(ctx context.Context, hostname string) error {
 
 func (cli *ghCli) SetSecret(ctx context.Context, repoSlug string, name string, value string) error {
 	runArgs := exec.NewRunArgs("gh", "-R", repoSlug, "secret", "set", name, "--body", value)
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	res, err := cli.run(ctx, runArgs)
+	if isGhCliNotLoggedInMessageRegex.MatchString(res.Stderr) {
+		return ErrGitHubCliNotLoggedIn
+	} else if err != nil {
+		return fmt.Errorf("failed running gh secret set %s: %w", res.String(), err)
+	}
+	return nil
+}
+
+// CreateEnvironment creates the deployment environment of the repository, or does nothing when it exists
+func (cli *ghCli) CreateEnvironment(ctx context.Context, repoSlug string, name string) error {
+	runArgs := exec.NewRunArgs(
+		"gh", "api", "--method", "PUT", fmt.Sprintf("/repos/%s/environments/%s", repoSlug, url.PathEscape(name)))
+	res, err := cli.run(ctx, runArgs)
+	if isGhCliNotLoggedInMessageRegex.MatchString(res.Stderr) {
+		return ErrGitHubCliNotLoggedIn
+	} else if err != nil {
+		return fmt.Errorf("failed creating environment %s: %s: %w", name, res.String(), err)
+	}
+	return nil
+}
+
+// SetEnvironmentSecret sets the secret of the deployment environment of the repository
+func (cli *ghCli) SetEnvironmentSecret(
+	ctx context.Context,
+	repoSlug string,
+	environment string,
+	name string,
+	value string,
+) error {
+	runArgs := exec.NewRunArgs("gh", "-R", repoSlug, "secret", "set", name, "--env", environment, "--body", value)
+	res, err := cli.run(ctx, runArgs)
 	if isGhCliNotLoggedInMessageRegex.MatchString(res.Stderr) {
 		return ErrGitHubCliNotLoggedIn
 	} else if err != nil {
@@ -125,6 +185,46 @@ func (cli *ghCli) SetSecret(ctx context.Context, repoSlug string, name string, v
 	return nil
 }
 
+// SetVariable sets the GitHub Actions variable of the repository, creating it when it doesn't exist
+func (cli *ghCli) SetVariable(ctx context.Context, repoSlug string, name string, value string) error {
+	return cli.setVariable(ctx, fmt.Sprintf("/repos/%s/actions/variables", repoSlug), name, value)
+}
+
+// SetEnvironmentVariable sets the variable of the deployment environment of the repository, creating it when it
+// doesn't exist
+func (cli *ghCli) SetEnvironmentVariable(
+	ctx context.Context,
+	repoSlug string,
+	environment string,
+	name string,
+	value string,
+) error {
+	return cli.setVariable(
+		ctx, fmt.Sprintf("/repos/%s/environments/%s/variables", repoSlug, url.PathEscape(environment)), name, value)
+}
+
+// setVariable sets the variable with the variables API at path. `gh variable set` requires a more recent gh than the
+// minimum version, the API is called instead: the variable is updated, or created when the update doesn't find it.
+func (cli *ghCli) setVariable(ctx context.Context, path string, name string, value string) error {
+	runArgs := exec.NewRunArgs(
+		"gh", "api", "--method", "PATCH", fmt.Sprintf("%s/%s", path, name), "-f", "name="+name, "-f", "value="+value)
+	res, err := cli.run(ctx, runArgs)
+	if isGhCliNotLoggedInMessageRegex.MatchString(res.Stderr) {
+		return ErrGitHubCliNotLoggedIn
+	} else if err == nil {
+		return nil
+	} else if !strings.Contains(res.Stderr, "HTTP 404") {
+		return fmt.Errorf("failed setting variable %s: %s: %w", name, res.String(), err)
+	}
+
+	runArgs = exec.NewRunArgs("gh", "api", "--method", "POST", path, "-f", "name="+name, "-f", "value="+value)
+	res, err = cli.run(ctx, runArgs)
+	if err != nil {
+		return fmt.Errorf("failed creating variable %s: %s: %w", name, res.String(), err)
+	}
+	return nil
+}
+
 type GhCliRepository struct {
 	// The slug for a repository (formatted as "<owner>/<name>")
 	NameWithOwner string
@@ -132,11 +232,29 @@ type GhCliRepository struct {
 	HttpsUrl string `json:"url"`
 	// The Url for the SSH endpoint for the repository
 	SshUrl string
+	// Indicates if the repository is a fork of another repository
+	IsFork bool
+	// The repository this repository was forked from, nil when the repository isn't a fork
+	Parent *GhCliRepositoryParent
+}
+
+type GhCliRepositoryParent struct {
+	// The name of the repository
+	Name string
+	// The account owning the repository
+	Owner struct {
+		Login string
+	}
+}
+
+// The slug for the repository (formatted as "<owner>/<name>")
+func (parent *GhCliRepositoryParent) NameWithOwner() string {
+	return parent.Owner.Login + "/" + parent.Name
 }
 
 func (cli *ghCli) ListRepositories(ctx context.Context) ([]GhCliRepository, error) {
 	runArgs := exec.NewRunArgs("gh", "repo", "list", "--no-archived", "--json", "nameWithOwner,url,sshUrl")
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	res, err := cli.run(ctx, runArgs)
 	if isGhCliNotLoggedInMessageRegex.MatchString(res.Stderr) {
 		return nil, ErrGitHubCliNotLoggedIn
 	} else if err != nil {
@@ -153,12 +271,12 @@ func (cli *ghCli) ListRepositories(ctx context.Context) ([]GhCliRepository, erro
 }
 
 func (cli *ghCli) ViewRepository(ctx context.Context, name string) (GhCliRepository, error) {
-	runArgs := exec.NewRunArgs("gh", "repo", "view", name, "--json", "nameWithOwner,url,sshUrl")
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	runArgs := exec.NewRunArgs("gh", "repo", "view", name, "--json", "nameWithOwner,url,sshUrl,isFork,parent")
+	res, err := cli.run(ctx, runArgs)
 	if isGhCliNotLoggedInMessageRegex.MatchString(res.Stderr) {
 		return GhCliRepository{}, ErrGitHubCliNotLoggedIn
 	} else if err != nil {
-		return GhCliRepository{}, fmt.Errorf("failed running gh repo list %s: %w", res.String(), err)
+		return GhCliRepository{}, fmt.Errorf("failed running gh repo view %s: %w", res.String(), err)
 	}
 
 	var repo GhCliRepository
@@ -172,7 +290,7 @@ func (cli *ghCli) ViewRepository(ctx context.Context, name string) (GhCliReposit
 
 func (cli *ghCli) CreatePrivateRepository(ctx context.Context, name string) error {
 	runArgs := exec.NewRunArgs("gh", "repo", "create", name, "--private")
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	res, err := cli.run(ctx, runArgs)
 	if isGhCliNotLoggedInMessageRegex.MatchString(res.Stderr) {
 		return ErrGitHubCliNotLoggedIn
 	} else if repositoryNameInUseRegex.MatchString(res.Stderr) {
@@ -191,7 +309,7 @@ const (
 
 func (cli *ghCli) GetGitProtocolType(ctx context.Context) (string, error) {
 	runArgs := exec.NewRunArgs("gh", "config", "get", "git_protocol")
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	res, err := cli.run(ctx, runArgs)
 	if isGhCliNotLoggedInMessageRegex.MatchString(res.Stderr) {
 		return "", ErrGitHubCliNotLoggedIn
 	} else if err != nil {
@@ -209,7 +327,7 @@ type GitHubActionsResponse struct {
 // return true if there is at least one workflow in the repo.
 func (cli *ghCli) GitHubActionsExists(ctx context.Context, repoSlug string) (bool, error) {
 	runArgs := exec.NewRunArgs("gh", "api", "/repos/"+repoSlug+"/actions/workflows")
-	res, err := cli.commandRunner.Run(ctx, runArgs)
+	res, err := cli.run(ctx, runArgs)
 	if err != nil {
 		return false, fmt.Errorf("getting github actions %s: %w", res.String(), err)
 	}
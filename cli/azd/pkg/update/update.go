@@ -0,0 +1,371 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package update downloads the releases of azd and replaces the running binary with them, for `azd upgrade`.
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/blang/semver/v4"
+)
+
+// Channel is the release channel azd is upgraded from
+type Channel string
+
+const (
+	// The released versions of azd
+	ChannelStable Channel = "stable"
+	// The builds of the main branch, published every day
+	ChannelDaily Channel = "daily"
+)
+
+// The path of the release channel in the azd user config
+const ChannelConfigPath = "updates.channel"
+
+// The location the releases of azd are published to, one folder per channel
+const DefaultBaseUrl = "https://azure-dev.azureedge.net/azd/standalone/release"
+
+// The feed returning the version of the latest stable release
+const latestVersionUrl = "https://aka.ms/azure-dev/versions/cli/latest"
+
+// ErrChecksumMismatch is returned when the downloaded archive doesn't match the checksum published with it
+var ErrChecksumMismatch = errors.New("the checksum of the downloaded archive doesn't match the published checksum")
+
+// ErrInvalidSignature is returned when the published checksum isn't signed by the key of the releases of azd
+var ErrInvalidSignature = errors.New("the published checksum isn't signed by the release key of azd")
+
+// ErrNoReleaseKey is returned by the builds of azd that don't have the key of the releases, which can't verify them
+var ErrNoReleaseKey = errors.New(
+	"this build of azd can't verify the signature of the releases, install the latest version from " +
+		"https://aka.ms/azure-dev/install instead")
+
+// ReleasePublicKey is the base64 encoded Ed25519 public key the checksums of the releases are signed for, set by the
+// release pipeline with -ldflags. The checksums are signed by the pipeline, not by the storage serving the releases,
+// so a release replaced in the storage isn't trusted.
+var ReleasePublicKey = ""
+
+// ParseChannel returns the channel of the name, stable or daily
+func ParseChannel(name string) (Channel, error) {
+	switch channel := Channel(strings.ToLower(strings.TrimSpace(name))); channel {
+	case ChannelStable, ChannelDaily:
+		return channel, nil
+	default:
+		return "", fmt.Errorf("invalid channel '%s', supported values are %s and %s", name, ChannelStable, ChannelDaily)
+	}
+}
+
+// ChannelFromConfig returns the channel set in the azd user config, stable when none is set
+func ChannelFromConfig(userConfig config.Config) Channel {
+	if value, ok := userConfig.Get(ChannelConfigPath); ok {
+		if channel, err := ParseChannel(fmt.Sprint(value)); err == nil {
+			return channel
+		}
+	}
+
+	return ChannelStable
+}
+
+// Updater fetches the releases of a channel
+type Updater struct {
+	httpClient httputil.HttpClient
+	baseUrl    string
+	goos       string
+	goarch     string
+	publicKey  string
+}
+
+// NewUpdater creates an updater fetching the releases built for the platform azd runs on
+func NewUpdater(httpClient httputil.HttpClient) *Updater {
+	return &Updater{
+		httpClient: httpClient,
+		baseUrl:    DefaultBaseUrl,
+		goos:       runtime.GOOS,
+		goarch:     runtime.GOARCH,
+		publicKey:  ReleasePublicKey,
+	}
+}
+
+// LatestVersion returns the version of the latest stable release. The daily builds have no version feed, nil is
+// returned for them.
+func (u *Updater) LatestVersion(ctx context.Context, channel Channel) (*semver.Version, error) {
+	if channel != ChannelStable {
+		return nil, nil
+	}
+
+	body, err := u.get(ctx, latestVersionUrl)
+	if err != nil {
+		return nil, fmt.Errorf("fetching the latest version: %w", err)
+	}
+
+	version, err := semver.Parse(strings.TrimSpace(string(body)))
+	if err != nil {
+		return nil, fmt.Errorf("parsing the latest version '%s': %w", strings.TrimSpace(string(body)), err)
+	}
+
+	return &version, nil
+}
+
+// ArchiveUrl returns the url of the archive of the latest release of the channel for the platform
+func (u *Updater) ArchiveUrl(channel Channel) string {
+	folder := "latest"
+	if channel == ChannelDaily {
+		folder = "daily"
+	}
+
+	return fmt.Sprintf("%s/%s/%s", u.baseUrl, folder, u.archiveName())
+}
+
+// Download downloads the latest release of the channel, verifies the signature of its checksum and the checksum of
+// the archive, then extracts its binary to dir. Returns the path of the extracted binary.
+func (u *Updater) Download(ctx context.Context, channel Channel, dir string) (string, error) {
+	publicKey, err := parsePublicKey(u.publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	archiveUrl := u.ArchiveUrl(channel)
+	archive, err := u.get(ctx, archiveUrl)
+	if err != nil {
+		return "", fmt.Errorf("downloading %s: %w", archiveUrl, err)
+	}
+
+	checksum, err := u.get(ctx, archiveUrl+".sha256")
+	if err != nil {
+		return "", fmt.Errorf("downloading the checksum of %s: %w", archiveUrl, err)
+	}
+
+	signature, err := u.get(ctx, archiveUrl+".sha256.sig")
+	if err != nil {
+		return "", fmt.Errorf("downloading the signature of the checksum of %s: %w", archiveUrl, err)
+	}
+
+	if err := verifySignature(publicKey, checksum, string(signature)); err != nil {
+		return "", err
+	}
+
+	if err := verifyChecksum(archive, string(checksum)); err != nil {
+		return "", err
+	}
+
+	binary, err := u.extractBinary(archive)
+	if err != nil {
+		return "", fmt.Errorf("extracting %s: %w", u.archiveName(), err)
+	}
+
+	binaryPath := filepath.Join(dir, u.binaryName())
+	if err := os.WriteFile(binaryPath, binary, 0755); err != nil {
+		return "", fmt.Errorf("writing %s: %w", binaryPath, err)
+	}
+
+	return binaryPath, nil
+}
+
+// Replace replaces the executable with the binary. The binary is first copied next to the executable, then renamed
+// over it, so the executable is never left partially written. Windows doesn't allow replacing a running executable,
+// it's moved aside to a .old file, removed by the next upgrade.
+func Replace(binaryPath string, executablePath string) error {
+	binary, err := os.ReadFile(binaryPath)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", binaryPath, err)
+	}
+
+	info, err := os.Stat(executablePath)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", executablePath, err)
+	}
+
+	// The staged copy is in the folder of the executable, so renaming it over the executable is atomic
+	stagedPath := executablePath + ".new"
+	if err := os.WriteFile(stagedPath, binary, info.Mode().Perm()|0700); err != nil {
+		return fmt.Errorf("writing %s: %w", stagedPath, err)
+	}
+
+	if runtime.GOOS == "windows" {
+		oldPath := executablePath + ".old"
+		_ = os.Remove(oldPath)
+		if err := os.Rename(executablePath, oldPath); err != nil {
+			_ = os.Remove(stagedPath)
+			return fmt.Errorf("moving %s aside: %w", executablePath, err)
+		}
+
+		if err := os.Rename(stagedPath, executablePath); err != nil {
+			// Restore the running version, the upgrade failed
+			_ = os.Rename(oldPath, executablePath)
+			_ = os.Remove(stagedPath)
+			return fmt.Errorf("replacing %s: %w", executablePath, err)
+		}
+
+		return nil
+	}
+
+	if err := os.Rename(stagedPath, executablePath); err != nil {
+		_ = os.Remove(stagedPath)
+		return fmt.Errorf("replacing %s: %w", executablePath, err)
+	}
+
+	return nil
+}
+
+// archiveName returns the name of the archive of the platform, as published by the release pipeline
+func (u *Updater) archiveName() string {
+	extension := "zip"
+	if u.goos == "linux" {
+		extension = "tar.gz"
+	}
+
+	return fmt.Sprintf("azd-%s-%s.%s", u.goos, u.archiveArch(), extension)
+}
+
+// binaryName returns the name of the binary within the archive of the platform
+func (u *Updater) binaryName() string {
+	name := fmt.Sprintf("azd-%s-%s", u.goos, u.archiveArch())
+	if u.goos == "windows" {
+		name += ".exe"
+	}
+
+	return name
+}
+
+// archiveArch returns the architecture of the archive of the platform. Only amd64 builds are published, Apple
+// silicon runs them through Rosetta.
+func (u *Updater) archiveArch() string {
+	if u.goos == "darwin" {
+		return "amd64"
+	}
+
+	return u.goarch
+}
+
+// extractBinary returns the contents of the binary of the archive
+func (u *Updater) extractBinary(archive []byte) ([]byte, error) {
+	name := u.binaryName()
+
+	if strings.HasSuffix(u.archiveName(), ".zip") {
+		reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range reader.File {
+			if file.Name != name {
+				continue
+			}
+
+			contents, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer contents.Close()
+
+			return io.ReadAll(contents)
+		}
+
+		return nil, fmt.Errorf("%s not found in the archive", name)
+	}
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+
+	reader := tar.NewReader(gzipReader)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s not found in the archive", name)
+		} else if err != nil {
+			return nil, err
+		}
+
+		if header.Name == name {
+			return io.ReadAll(reader)
+		}
+	}
+}
+
+// parsePublicKey returns the Ed25519 public key of the base64 encoded key, ErrNoReleaseKey when it isn't a key
+func parsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, ErrNoReleaseKey
+	}
+
+	return ed25519.PublicKey(key), nil
+}
+
+// verifySignature returns ErrInvalidSignature when the base64 encoded signature isn't the Ed25519 signature of the
+// checksum file by the key
+func verifySignature(publicKey ed25519.PublicKey, checksumFile []byte, signatureFile string) error {
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signatureFile))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	if !ed25519.Verify(publicKey, checksumFile, signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// verifyChecksum returns ErrChecksumMismatch when the SHA-256 of the archive isn't the checksum. The checksum file may
+// list the name of the archive after the checksum, as written by sha256sum.
+func verifyChecksum(archive []byte, checksumFile string) error {
+	fields := strings.Fields(checksumFile)
+	if len(fields) == 0 {
+		return fmt.Errorf("%w: the published checksum is empty", ErrChecksumMismatch)
+	}
+
+	sum := sha256.Sum256(archive)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), fields[0]) {
+		return ErrChecksumMismatch
+	}
+
+	return nil
+}
+
+func (u *Updater) get(ctx context.Context, url string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("User-Agent", internal.MakeUserAgentString(""))
+
+	response, err := u.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+
+	return body, nil
+}
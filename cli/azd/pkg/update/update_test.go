@@ -0,0 +1,172 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package update
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChannel(t *testing.T) {
+	channel, err := ParseChannel(" Daily ")
+	require.NoError(t, err)
+	require.Equal(t, ChannelDaily, channel)
+
+	_, err = ParseChannel("nightly")
+	require.Error(t, err)
+}
+
+func TestChannelFromConfig(t *testing.T) {
+	require.Equal(t, ChannelStable, ChannelFromConfig(config.NewConfig(nil)))
+
+	userConfig := config.NewConfig(nil)
+	require.NoError(t, userConfig.Set(ChannelConfigPath, "daily"))
+	require.Equal(t, ChannelDaily, ChannelFromConfig(userConfig))
+}
+
+func TestArchiveUrl(t *testing.T) {
+	tests := map[string]struct {
+		goos     string
+		goarch   string
+		channel  Channel
+		expected string
+	}{
+		"Linux":        {"linux", "amd64", ChannelStable, DefaultBaseUrl + "/latest/azd-linux-amd64.tar.gz"},
+		"Windows":      {"windows", "amd64", ChannelDaily, DefaultBaseUrl + "/daily/azd-windows-amd64.zip"},
+		"AppleSilicon": {"darwin", "arm64", ChannelStable, DefaultBaseUrl + "/latest/azd-darwin-amd64.zip"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			updater := &Updater{baseUrl: DefaultBaseUrl, goos: test.goos, goarch: test.goarch}
+			require.Equal(t, test.expected, updater.ArchiveUrl(test.channel))
+		})
+	}
+}
+
+func TestDownload(t *testing.T) {
+	binary := []byte("azd binary")
+	archive := createTarGz(t, "azd-linux-amd64", binary)
+	sum := sha256.Sum256(archive)
+	checksum := hex.EncodeToString(sum[:]) + "  azd-linux-amd64.tar.gz\n"
+
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	setup := func(checksum string, signingKey ed25519.PrivateKey) (*mocks.MockContext, *Updater) {
+		signature := base64.StdEncoding.EncodeToString(ed25519.Sign(signingKey, []byte(checksum)))
+
+		mockContext := mocks.NewMockContext(context.Background())
+		for url, body := range map[string][]byte{
+			"/latest/azd-linux-amd64.tar.gz":            archive,
+			"/latest/azd-linux-amd64.tar.gz.sha256":     []byte(checksum),
+			"/latest/azd-linux-amd64.tar.gz.sha256.sig": []byte(signature),
+		} {
+			url, body := DefaultBaseUrl+url, body
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return request.URL.String() == url
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				return rawResponse(request, body), nil
+			})
+		}
+
+		updater := NewUpdater(mockContext.HttpClient)
+		updater.goos = "linux"
+		updater.goarch = "amd64"
+		updater.publicKey = base64.StdEncoding.EncodeToString(publicKey)
+
+		return mockContext, updater
+	}
+
+	t.Run("Verified", func(t *testing.T) {
+		mockContext, updater := setup(checksum, privateKey)
+
+		binaryPath, err := updater.Download(*mockContext.Context, ChannelStable, t.TempDir())
+		require.NoError(t, err)
+
+		contents, err := os.ReadFile(binaryPath)
+		require.NoError(t, err)
+		require.Equal(t, binary, contents)
+	})
+
+	t.Run("ChecksumMismatch", func(t *testing.T) {
+		mockContext, updater := setup("0000", privateKey)
+
+		_, err := updater.Download(*mockContext.Context, ChannelStable, t.TempDir())
+		require.ErrorIs(t, err, ErrChecksumMismatch)
+	})
+
+	t.Run("InvalidSignature", func(t *testing.T) {
+		// The checksum matches the archive, but isn't signed with the release key
+		mockContext, updater := setup(checksum, otherKey)
+
+		_, err := updater.Download(*mockContext.Context, ChannelStable, t.TempDir())
+		require.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("NoReleaseKey", func(t *testing.T) {
+		mockContext, updater := setup(checksum, privateKey)
+		updater.publicKey = ""
+
+		_, err := updater.Download(*mockContext.Context, ChannelStable, t.TempDir())
+		require.ErrorIs(t, err, ErrNoReleaseKey)
+	})
+}
+
+func TestReplace(t *testing.T) {
+	dir := t.TempDir()
+	executablePath := filepath.Join(dir, "azd")
+	binaryPath := filepath.Join(dir, "download", "azd-linux-amd64")
+
+	require.NoError(t, os.WriteFile(executablePath, []byte("old"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Dir(binaryPath), 0755))
+	require.NoError(t, os.WriteFile(binaryPath, []byte("new"), 0755))
+
+	require.NoError(t, Replace(binaryPath, executablePath))
+
+	contents, err := os.ReadFile(executablePath)
+	require.NoError(t, err)
+	require.Equal(t, "new", string(contents))
+	require.NoFileExists(t, executablePath+".new")
+}
+
+func createTarGz(t *testing.T, name string, contents []byte) []byte {
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(contents))}))
+	_, err := tarWriter.Write(contents)
+	require.NoError(t, err)
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+
+	return buf.Bytes()
+}
+
+func rawResponse(request *http.Request, body []byte) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Request:    request,
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}
+}
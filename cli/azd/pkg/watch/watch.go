@@ -0,0 +1,128 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package watch detects the changes to the files of a directory, by comparing snapshots of the directory.
+package watch
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/rzip"
+)
+
+// the state of a file compared between snapshots
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watcher detects the files of a directory added, modified or removed since its last snapshot
+type Watcher struct {
+	root     string
+	ignore   []string
+	snapshot map[string]fileState
+}
+
+// NewWatcher snapshots the files of the root directory, recursively, leaving out the files and directories matching
+// any of the ignore patterns. The patterns follow the exclude patterns of the zip packages, see rzip.Files.
+func NewWatcher(root string, ignore []string) (*Watcher, error) {
+	if err := rzip.ValidateExclude(ignore); err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		root:   root,
+		ignore: ignore,
+	}
+
+	if err := w.Reset(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Reset snapshots the files of the directory, the changes are then detected from this snapshot
+func (w *Watcher) Reset() error {
+	snapshot, err := w.take()
+	if err != nil {
+		return err
+	}
+
+	w.snapshot = snapshot
+	return nil
+}
+
+// Changes returns the paths, relative to the root directory, of the files added, modified or removed since the last
+// snapshot, and snapshots the directory again
+func (w *Watcher) Changes() ([]string, error) {
+	snapshot, err := w.take()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []string
+	for name, state := range snapshot {
+		if previous, has := w.snapshot[name]; !has || previous != state {
+			changes = append(changes, name)
+		}
+	}
+
+	for name := range w.snapshot {
+		if _, has := snapshot[name]; !has {
+			changes = append(changes, name)
+		}
+	}
+
+	sort.Strings(changes)
+	w.snapshot = snapshot
+	return changes, nil
+}
+
+func (w *Watcher) take() (map[string]fileState, error) {
+	snapshot := map[string]fileState{}
+	err := filepath.WalkDir(w.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(w.root, path)
+		if err != nil {
+			return err
+		}
+
+		name := strings.Replace(rel, "\\", "/", -1)
+		if name == "." {
+			return nil
+		}
+
+		// the patterns are validated when the watcher is created
+		if excluded, _ := rzip.Excluded(name, w.ignore); excluded {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		snapshot[name] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcherChanges(t *testing.T) {
+	root := t.TempDir()
+	write := func(name string, content string) {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	write("app.py", "v1")
+	write("static/index.html", "v1")
+	write("node_modules/lib/index.js", "v1")
+
+	watcher, err := NewWatcher(root, []string{"node_modules", "*.log"})
+	require.NoError(t, err)
+
+	changes, err := watcher.Changes()
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	write("app.py", "v2")
+	write("static/app.js", "v1")
+	write("node_modules/lib/index.js", "v2")
+	write("debug.log", "v1")
+	require.NoError(t, os.Remove(filepath.Join(root, "static", "index.html")))
+
+	changes, err = watcher.Changes()
+	require.NoError(t, err)
+	require.Equal(t, []string{"app.py", "static/app.js", "static/index.html"}, changes)
+
+	// The changes are reported once
+	changes, err = watcher.Changes()
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(root, "app.py"), later, later))
+	changes, err = watcher.Changes()
+	require.NoError(t, err)
+	require.Equal(t, []string{"app.py"}, changes)
+}
+
+func TestNewWatcherInvalidPattern(t *testing.T) {
+	_, err := NewWatcher(t.TempDir(), []string{"[a"})
+	require.Error(t, err)
+}
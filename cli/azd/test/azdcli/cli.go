@@ -11,6 +11,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
@@ -48,7 +49,13 @@ func (cli *CLI) RunCommandWithStdIn(ctx context.Context, stdin string, args ...s
 		cmd.Stdin = strings.NewReader(stdin)
 	}
 
-	cmd.Env = cli.Env
+	env := cli.Env
+	if env == nil {
+		env = os.Environ()
+	}
+
+	// The tests answer the prompts through stdin, including when they run in CI
+	cmd.Env = append(append([]string{}, env...), "AZD_FORCE_INTERACTIVE=true")
 
 	// we run a background goroutine to report a heartbeat in the logs while the command
 	// is still running. This makes it easy to see what's still in progress if we hit a timeout.
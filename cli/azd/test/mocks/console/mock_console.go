@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 )
@@ -45,6 +46,33 @@ func (c *MockConsole) Message(ctx context.Context, message string) {
 	c.log = append(c.log, message)
 }
 
+// Prints a message to the console when the verbosity of the console is at least verbosity
+func (c *MockConsole) MessageAt(ctx context.Context, verbosity input.Verbosity, message string) {
+	if c.Verbosity() >= verbosity {
+		c.Message(ctx, message)
+	}
+}
+
+// Prints the rows of a table to the console, a message per row with the cells separated by tabs
+func (c *MockConsole) Table(ctx context.Context, headers []string, rows [][]string) {
+	c.Message(ctx, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		c.Message(ctx, strings.Join(row, "\t"))
+	}
+}
+
+// Prints key values to the console, a message per value as "<key>: <value>"
+func (c *MockConsole) KeyValues(ctx context.Context, values []input.KeyValue) {
+	for _, kv := range values {
+		c.Message(ctx, fmt.Sprintf("%s: %s", kv.Key, kv.Value))
+	}
+}
+
+// Gets the verbosity of the console
+func (c *MockConsole) Verbosity() input.Verbosity {
+	return input.VerbosityNormal
+}
+
 // Prints a confirmation message to the console for the user to confirm
 func (c *MockConsole) Confirm(ctx context.Context, options input.ConsoleOptions) (bool, error) {
 	c.log = append(c.log, options.Message)
@@ -72,6 +72,32 @@ func RegisterApplicationCreateMock(mockContext *mocks.MockContext, statusCode in
 	})
 }
 
+func RegisterApplicationUpdateMock(
+	mockContext *mocks.MockContext,
+	statusCode int,
+	appId string,
+) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPatch &&
+			strings.Contains(request.URL.Path, fmt.Sprintf("/applications/%s", appId))
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateEmptyHttpResponse(request, statusCode)
+	})
+}
+
+func RegisterApplicationDeleteMock(
+	mockContext *mocks.MockContext,
+	statusCode int,
+	appId string,
+) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodDelete &&
+			strings.Contains(request.URL.Path, fmt.Sprintf("/applications/%s", appId))
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateEmptyHttpResponse(request, statusCode)
+	})
+}
+
 func RegisterApplicationAddPasswordMock(
 	mockContext *mocks.MockContext,
 	statusCode int,
@@ -103,6 +129,77 @@ func RegisterApplicationRemovePasswordMock(
 	})
 }
 
+func RegisterFederatedIdentityCredentialListMock(
+	mockContext *mocks.MockContext,
+	statusCode int,
+	appId string,
+	credentials []graphsdk.FederatedIdentityCredential,
+) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet &&
+			strings.Contains(request.URL.Path, fmt.Sprintf("/applications/%s/federatedIdentityCredentials", appId))
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		if credentials == nil {
+			return mocks.CreateEmptyHttpResponse(request, statusCode)
+		}
+
+		return mocks.CreateHttpResponseWithBody(
+			request, statusCode, graphsdk.FederatedIdentityCredentialListResponse{Value: credentials})
+	})
+}
+
+func RegisterFederatedIdentityCredentialCreateMock(
+	mockContext *mocks.MockContext,
+	statusCode int,
+	appId string,
+	credential *graphsdk.FederatedIdentityCredential,
+) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost &&
+			strings.Contains(request.URL.Path, fmt.Sprintf("/applications/%s/federatedIdentityCredentials", appId))
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		if credential == nil {
+			return mocks.CreateEmptyHttpResponse(request, statusCode)
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, statusCode, credential)
+	})
+}
+
+func RegisterFederatedIdentityCredentialUpdateMock(
+	mockContext *mocks.MockContext,
+	statusCode int,
+	appId string,
+	credentialId string,
+) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPatch &&
+			strings.Contains(
+				request.URL.Path,
+				fmt.Sprintf("/applications/%s/federatedIdentityCredentials/%s", appId, credentialId),
+			)
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateEmptyHttpResponse(request, statusCode)
+	})
+}
+
+func RegisterFederatedIdentityCredentialDeleteMock(
+	mockContext *mocks.MockContext,
+	statusCode int,
+	appId string,
+	credentialId string,
+) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodDelete &&
+			strings.Contains(
+				request.URL.Path,
+				fmt.Sprintf("/applications/%s/federatedIdentityCredentials/%s", appId, credentialId),
+			)
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateEmptyHttpResponse(request, statusCode)
+	})
+}
+
 func RegisterServicePrincipalListMock(
 	mockContext *mocks.MockContext,
 	statusCode int,
@@ -157,6 +254,37 @@ func RegisterServicePrincipalCreateMock(
 	})
 }
 
+func RegisterServicePrincipalAddPasswordMock(
+	mockContext *mocks.MockContext,
+	statusCode int,
+	spnId string,
+	credential *graphsdk.ApplicationPasswordCredential,
+) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost &&
+			strings.Contains(request.URL.Path, fmt.Sprintf("/servicePrincipals/%s/addPassword", spnId))
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		if credential == nil {
+			return mocks.CreateEmptyHttpResponse(request, statusCode)
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, statusCode, credential)
+	})
+}
+
+func RegisterServicePrincipalRemovePasswordMock(
+	mockContext *mocks.MockContext,
+	statusCode int,
+	spnId string,
+) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost &&
+			strings.Contains(request.URL.Path, fmt.Sprintf("/servicePrincipals/%s/removePassword", spnId))
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, statusCode, map[string]any{})
+	})
+}
+
 func RegisterMeGetMock(mockContext *mocks.MockContext, statusCode int, userProfile *graphsdk.UserProfile) {
 	mockContext.HttpClient.When(func(request *http.Request) bool {
 		return request.Method == http.MethodGet && strings.Contains(request.URL.Path, "/me")
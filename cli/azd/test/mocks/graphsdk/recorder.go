@@ -0,0 +1,38 @@
+package graphsdk
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/recording"
+)
+
+// The value written in place of sensitive values within recorded responses
+const SanitizedValue = recording.SanitizedValue
+
+// A single HTTP request / response pair captured from the Microsoft Graph
+type RecordedInteraction = recording.Interaction
+
+// A collection of HTTP interactions captured from the Microsoft Graph
+type Recording = recording.Recording
+
+// Recorder is a transport that forwards requests to an inner transport and captures
+// sanitized copies of the responses so they can be saved & replayed later within unit tests.
+type Recorder = recording.Recorder
+
+// Creates a new recorder that forwards requests to the specified transport.
+// When transport is nil the default HTTP client is used.
+func NewRecorder(transport policy.Transporter) *Recorder {
+	return recording.NewRecorder(transport)
+}
+
+// Loads a recording previously saved with Recorder.Save
+func LoadRecording(path string) (*Recording, error) {
+	return recording.LoadRecording(path)
+}
+
+// Registers mocks that replay the interactions within the recording.
+// Interactions with the same method, path & query are replayed in the order they were recorded.
+// Once exhausted, the last matching interaction continues to be returned.
+func RegisterRecording(mockContext *mocks.MockContext, saved *Recording) {
+	recording.RegisterRecording(mockContext, saved)
+}
@@ -0,0 +1,394 @@
+// Package recording records sanitized HTTP traffic of the Azure clients used by azd, the Microsoft Graph, ARM and
+// Azure DevOps clients, and replays it within unit tests, so the flows calling the services can run without live
+// credentials.
+package recording
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/identity"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+)
+
+// The environment variable that, when set, sends the requests of the tests to the live services and records them
+// instead of replaying the saved recordings
+const RecordModeEnvVar = "AZD_TEST_RECORD"
+
+// The value written in place of sensitive values within recorded responses
+const SanitizedValue = "SANITIZED"
+
+// JSON property names (case insensitive) whose values are sanitized before a response is recorded
+var SanitizedFields = []string{
+	"secretText",
+	"password",
+	"clientSecret",
+	"accessToken",
+	"refreshToken",
+	"key",
+	"primaryKey",
+	"secondaryKey",
+	"connectionString",
+	"serviceprincipalkey",
+}
+
+// The response headers that are recorded, the other headers aren't needed by the clients to replay a response
+var RecordedHeaders = []string{
+	"Content-Type",
+	"Location",
+	"Azure-AsyncOperation",
+	"X-Ms-Continuationtoken",
+}
+
+// A single HTTP request / response pair captured from a service
+type Interaction struct {
+	Method     string              `json:"method"`
+	Path       string              `json:"path"`
+	Query      string              `json:"query,omitempty"`
+	StatusCode int                 `json:"statusCode"`
+	Header     map[string][]string `json:"header,omitempty"`
+	Body       json.RawMessage     `json:"body,omitempty"`
+}
+
+// A collection of HTTP interactions captured from the services
+type Recording struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Recorder is a transport that forwards requests to an inner transport and captures
+// sanitized copies of the responses so they can be saved & replayed later within unit tests.
+type Recorder struct {
+	transport    policy.Transporter
+	recording    *Recording
+	replacements []string
+	mu           sync.Mutex
+}
+
+// Creates a new recorder that forwards requests to the specified transport.
+// When transport is nil a client of the default HTTP transport is used.
+func NewRecorder(transport policy.Transporter) *Recorder {
+	if transport == nil {
+		// The default transport is captured, the clients of Azure DevOps are recorded by replacing it
+		transport = &http.Client{Transport: http.DefaultTransport}
+	}
+
+	return &Recorder{
+		transport: transport,
+		recording: &Recording{
+			Interactions: []*Interaction{},
+		},
+	}
+}
+
+// Replace replaces the value with the replacement in the paths, headers and bodies of the recorded responses, i.e.
+// to record a fake subscription id or organization name in place of the real one
+func (r *Recorder) Replace(value string, replacement string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.replacements = append(r.replacements, value, replacement)
+}
+
+// Do implements the policy.Transporter interface
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	res, err := r.transport.Do(req)
+	if err != nil {
+		return res, err
+	}
+
+	var body []byte
+	if res.Body != nil {
+		body, err = io.ReadAll(res.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading response body: %w", err)
+		}
+		res.Body.Close()
+
+		// Restore the body so the caller can still consume the original response
+		res.Body = io.NopCloser(bytes.NewBuffer(body))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	replacer := strings.NewReplacer(r.replacements...)
+	sanitizedBody, err := sanitizeBody([]byte(replacer.Replace(string(body))))
+	if err != nil {
+		return nil, err
+	}
+
+	var header map[string][]string
+	for _, name := range RecordedHeaders {
+		if values := res.Header.Values(name); len(values) > 0 {
+			if header == nil {
+				header = map[string][]string{}
+			}
+
+			for _, value := range values {
+				header[name] = append(header[name], replacer.Replace(value))
+			}
+		}
+	}
+
+	r.recording.Interactions = append(r.recording.Interactions, &Interaction{
+		Method:     req.Method,
+		Path:       replacer.Replace(req.URL.Path),
+		Query:      replacer.Replace(req.URL.RawQuery),
+		StatusCode: res.StatusCode,
+		Header:     header,
+		Body:       sanitizedBody,
+	})
+
+	return res, nil
+}
+
+// RoundTrip implements the http.RoundTripper interface, to record the clients using the default HTTP transport
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r.Do(req)
+}
+
+// Gets the recording of the interactions captured so far
+func (r *Recorder) Recording() *Recording {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.recording
+}
+
+// Saves the recording of the interactions captured so far to the specified file path
+func (r *Recorder) Save(path string) error {
+	recordingJson, err := json.MarshalIndent(r.Recording(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed serializing recording: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed creating recording folder: %w", err)
+	}
+
+	if err := os.WriteFile(path, recordingJson, 0600); err != nil {
+		return fmt.Errorf("failed writing recording: %w", err)
+	}
+
+	return nil
+}
+
+// Loads a recording previously saved with Recorder.Save
+func LoadRecording(path string) (*Recording, error) {
+	recordingJson, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading recording: %w", err)
+	}
+
+	var recording Recording
+	if err := json.Unmarshal(recordingJson, &recording); err != nil {
+		return nil, fmt.Errorf("failed deserializing recording: %w", err)
+	}
+
+	return &recording, nil
+}
+
+// Registers mocks that replay the interactions within the recording.
+// Interactions with the same method, path & query are replayed in the order they were recorded.
+// Once exhausted, the last matching interaction continues to be returned.
+func RegisterRecording(mockContext *mocks.MockContext, recording *Recording) {
+	var mu sync.Mutex
+	pending := map[string][]*Interaction{}
+
+	for _, interaction := range recording.Interactions {
+		key := interactionKey(interaction.Method, interaction.Path, interaction.Query)
+		pending[key] = append(pending[key], interaction)
+	}
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		_, has := pending[interactionKey(request.Method, request.URL.Path, request.URL.RawQuery)]
+		return has
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		key := interactionKey(request.Method, request.URL.Path, request.URL.RawQuery)
+		interactions := pending[key]
+		interaction := interactions[0]
+
+		if len(interactions) > 1 {
+			pending[key] = interactions[1:]
+		}
+
+		var response *http.Response
+		var err error
+		if len(interaction.Body) == 0 {
+			response, err = mocks.CreateEmptyHttpResponse(request, interaction.StatusCode)
+		} else {
+			response, err = mocks.CreateHttpResponseWithBody(request, interaction.StatusCode, interaction.Body)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for name, values := range interaction.Header {
+			for _, value := range values {
+				response.Header.Add(name, value)
+			}
+		}
+
+		return response, nil
+	})
+}
+
+// Start records or replays the HTTP traffic of the test and returns the transport the clients of the test send their
+// requests with. The Azure DevOps clients don't accept a transport, the default HTTP transport is replaced with the
+// returned transport until the test completes.
+//
+// When AZD_TEST_RECORD is set, the requests are sent to the live services, signed in with the Azure CLI, and the
+// sanitized responses are saved to recordingPath once the test succeeds, with the values of replacements replaced by
+// their keys. The HTTP client and the credentials of the context of mockContext are replaced for the ARM clients.
+// Otherwise the recording saved at recordingPath is replayed by the mock HTTP client of mockContext.
+func Start(
+	t *testing.T,
+	mockContext *mocks.MockContext,
+	recordingPath string,
+	replacements map[string]string,
+) policy.Transporter {
+	if os.Getenv(RecordModeEnvVar) != "" {
+		recorder := NewRecorder(nil)
+		for replacement, value := range replacements {
+			if value != "" {
+				recorder.Replace(value, replacement)
+			}
+		}
+
+		credential, err := azidentity.NewAzureCLICredential(nil)
+		if err != nil {
+			t.Fatalf("creating the Azure CLI credential: %v", err)
+		}
+
+		ctx := httputil.WithHttpClient(*mockContext.Context, recorder)
+		ctx = identity.WithCredentials(ctx, credential)
+		*mockContext.Context = ctx
+
+		UseDefaultTransport(t, recorder)
+		t.Cleanup(func() {
+			if t.Failed() {
+				return
+			}
+
+			if err := recorder.Save(recordingPath); err != nil {
+				t.Errorf("saving recording: %v", err)
+			}
+		})
+
+		return recorder
+	}
+
+	recording, err := LoadRecording(recordingPath)
+	if err != nil {
+		t.Fatalf("%v, run the test with %s=1 to record it", err, RecordModeEnvVar)
+	}
+
+	RegisterRecording(mockContext, recording)
+	UseDefaultTransport(t, mockContext.HttpClient)
+
+	return mockContext.HttpClient
+}
+
+// UseDefaultTransport replaces the default HTTP transport with the transport until the test completes. The clients
+// of Azure DevOps create their HTTP clients with the default transport, it's the only way to intercept their requests.
+func UseDefaultTransport(t *testing.T, transport policy.Transporter) {
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = roundTripper{transport: transport}
+	t.Cleanup(func() {
+		http.DefaultTransport = defaultTransport
+	})
+}
+
+// roundTripper sends the requests of an HTTP client with a transporter
+type roundTripper struct {
+	transport policy.Transporter
+}
+
+func (r roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r.transport.Do(req)
+}
+
+// interactionKey returns the key matching a request to its interactions. The query is normalized, sorted by parameter,
+// since the clients don't guarantee the order of the parameters.
+func interactionKey(method string, path string, rawQuery string) string {
+	key := fmt.Sprintf("%s %s", strings.ToUpper(method), strings.TrimSuffix(path, "/"))
+
+	if query, err := url.ParseQuery(rawQuery); err == nil {
+		rawQuery = query.Encode()
+	}
+
+	if rawQuery != "" {
+		key += "?" + rawQuery
+	}
+
+	return key
+}
+
+// Replaces the values of any sensitive JSON properties within the body
+func sanitizeBody(body []byte) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, nil
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		// Non JSON responses are not expected from the services and are not recorded
+		return nil, nil
+	}
+
+	sanitized, err := json.Marshal(sanitizeValue(value))
+	if err != nil {
+		return nil, fmt.Errorf("failed serializing sanitized body: %w", err)
+	}
+
+	return sanitized, nil
+}
+
+func sanitizeValue(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, child := range typed {
+			if isSanitizedField(key) && child != nil {
+				typed[key] = SanitizedValue
+			} else {
+				typed[key] = sanitizeValue(child)
+			}
+		}
+		return typed
+	case []any:
+		for i, child := range typed {
+			typed[i] = sanitizeValue(child)
+		}
+		return typed
+	default:
+		return value
+	}
+}
+
+func isSanitizedField(name string) bool {
+	for _, field := range SanitizedFields {
+		if strings.EqualFold(field, name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,39 @@
+package recording
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterRecordingMatchesQuery(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	RegisterRecording(mockContext, &Recording{
+		Interactions: []*Interaction{
+			{Method: "GET", Path: "/projects", Query: "api-version=1.0&continuationToken=1", StatusCode: 200,
+				Body: []byte(`"page 2"`)},
+			{Method: "GET", Path: "/projects", Query: "api-version=1.0", StatusCode: 200, Body: []byte(`"page 1"`)},
+		},
+	})
+
+	get := func(url string) string {
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+
+		response, err := mockContext.HttpClient.Do(request)
+		require.NoError(t, err)
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	// The interactions are matched by query whatever the order they were recorded in, or the order of the parameters
+	require.Equal(t, `"page 1"`, get("https://dev.azure.com/projects?api-version=1.0"))
+	require.Equal(t, `"page 2"`, get("https://dev.azure.com/projects?continuationToken=1&api-version=1.0"))
+}